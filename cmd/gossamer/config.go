@@ -14,6 +14,7 @@
 	"github.com/ChainSafe/gossamer/chain/gssmr"
 	"github.com/ChainSafe/gossamer/dot"
 	ctoml "github.com/ChainSafe/gossamer/dot/config/toml"
+	"github.com/ChainSafe/gossamer/dot/core"
 	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/dot/state/pruner"
 	"github.com/ChainSafe/gossamer/dot/types"
@@ -22,6 +23,7 @@
 	"github.com/ChainSafe/gossamer/lib/genesis"
 	"github.com/ChainSafe/gossamer/lib/runtime/life"
 	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/transaction"
 	"github.com/ChainSafe/gossamer/lib/utils"
 	"github.com/urfave/cli"
 )
@@ -580,6 +582,17 @@ func setDotCoreConfig(ctx *cli.Context, tomlCfg ctoml.CoreConfig, cfg *dot.CoreC
 	cfg.BabeAuthority = tomlCfg.Roles == types.AuthorityRole
 	cfg.GrandpaAuthority = tomlCfg.Roles == types.AuthorityRole
 	cfg.GrandpaInterval = time.Second * time.Duration(tomlCfg.GrandpaInterval)
+	cfg.TransactionBanDuration = time.Second * time.Duration(tomlCfg.TxBanSeconds)
+	cfg.TransactionLimits = transaction.Limits{
+		MaxCount:     tomlCfg.TxPoolMaxCount,
+		MaxBytes:     tomlCfg.TxPoolMaxBytes,
+		MaxPerSender: tomlCfg.TxMaxPerSender,
+	}
+	cfg.TransactionIndexRetention = tomlCfg.TxIndexRetention
+	cfg.RuntimePoolSize = tomlCfg.RuntimePoolSize
+	cfg.RuntimeCache = tomlCfg.RuntimeCache
+	cfg.KeyChangesIndex = tomlCfg.KeyChangesIndex
+	cfg.WarpSync = tomlCfg.WarpSync
 
 	cfg.BABELead = tomlCfg.BABELead
 	if ctx.IsSet(BABELeadFlag.Name) {
@@ -622,7 +635,12 @@ func setDotCoreConfig(ctx *cli.Context, tomlCfg ctoml.CoreConfig, cfg *dot.CoreC
 		cfg.GrandpaAuthority = false
 	}
 
-	switch tomlCfg.WasmInterpreter {
+	wasmInterpreter := tomlCfg.WasmInterpreter
+	if interpreter := ctx.GlobalString(WasmInterpreterFlag.Name); interpreter != "" {
+		wasmInterpreter = interpreter
+	}
+
+	switch wasmInterpreter {
 	case wasmer.Name:
 		cfg.WasmInterpreter = wasmer.Name
 	case life.Name:
@@ -634,9 +652,24 @@ func setDotCoreConfig(ctx *cli.Context, tomlCfg ctoml.CoreConfig, cfg *dot.CoreC
 		logger.Warn("invalid wasm interpreter set in config, defaulting to " + gssmr.DefaultWasmInterpreter)
 	}
 
+	if tomlCfg.OffchainWorker != "" {
+		cfg.OffchainWorker = core.OffchainWorkerMode(tomlCfg.OffchainWorker)
+	}
+
+	if mode := ctx.GlobalString(OffchainWorkerFlag.Name); mode != "" {
+		cfg.OffchainWorker = core.OffchainWorkerMode(mode)
+	}
+
+	if !cfg.OffchainWorker.IsValid() {
+		logger.Warnf("invalid offchain worker mode %q, defaulting to %s",
+			cfg.OffchainWorker, core.OffchainWorkerWhenValidating)
+		cfg.OffchainWorker = core.OffchainWorkerWhenValidating
+	}
+
 	logger.Debugf(
-		"core configuration: babe-authority=%t, grandpa-authority=%t wasm-interpreter=%s grandpa-interval=%s",
-		cfg.BabeAuthority, cfg.GrandpaAuthority, cfg.WasmInterpreter, cfg.GrandpaInterval)
+		"core configuration: babe-authority=%t, grandpa-authority=%t wasm-interpreter=%s grandpa-interval=%s "+
+			"offchain-worker=%s",
+		cfg.BabeAuthority, cfg.GrandpaAuthority, cfg.WasmInterpreter, cfg.GrandpaInterval, cfg.OffchainWorker)
 }
 
 // setDotNetworkConfig sets dot.NetworkConfig using flag values from the cli context
@@ -649,6 +682,15 @@ func setDotNetworkConfig(ctx *cli.Context, tomlCfg ctoml.NetworkConfig, cfg *dot
 	cfg.MinPeers = tomlCfg.MinPeers
 	cfg.MaxPeers = tomlCfg.MaxPeers
 	cfg.PersistentPeers = tomlCfg.PersistentPeers
+	cfg.ReservedOnly = tomlCfg.ReservedOnly
+	cfg.TransactionsInPeers = tomlCfg.TransactionsInPeers
+	cfg.TransactionsOutPeers = tomlCfg.TransactionsOutPeers
+	cfg.TransactionsReservedPeers = tomlCfg.TransactionsReservedPeers
+	cfg.TransactionsReservedOnly = tomlCfg.TransactionsReservedOnly
+	cfg.GrandpaInPeers = tomlCfg.GrandpaInPeers
+	cfg.GrandpaOutPeers = tomlCfg.GrandpaOutPeers
+	cfg.GrandpaReservedPeers = tomlCfg.GrandpaReservedPeers
+	cfg.GrandpaReservedOnly = tomlCfg.GrandpaReservedOnly
 	cfg.DiscoveryInterval = time.Second * time.Duration(tomlCfg.DiscoveryInterval)
 
 	// check --port flag and update node configuration
@@ -691,6 +733,16 @@ func setDotNetworkConfig(ctx *cli.Context, tomlCfg ctoml.NetworkConfig, cfg *dot
 		cfg.PublicDNS = pubdns
 	}
 
+	// check --reserved-nodes flag and update node configuration
+	if reservedNodes := ctx.GlobalString(ReservedNodesFlag.Name); reservedNodes != "" {
+		cfg.PersistentPeers = strings.Split(reservedNodes, ",")
+	}
+
+	// check --reserved-only flag and update node configuration
+	if reservedOnly := ctx.GlobalBool(ReservedOnlyFlag.Name); reservedOnly {
+		cfg.ReservedOnly = true
+	}
+
 	if len(cfg.PersistentPeers) == 0 {
 		cfg.PersistentPeers = []string(nil)
 	}
@@ -698,10 +750,10 @@ func setDotNetworkConfig(ctx *cli.Context, tomlCfg ctoml.NetworkConfig, cfg *dot
 	logger.Debugf(
 		"network configuration: port=%d bootnodes=%s protocol=%s nobootstrap=%t "+
 			"nomdns=%t minpeers=%d maxpeers=%d persistent-peers=%s "+
-			"discovery-interval=%s",
+			"discovery-interval=%s reserved-only=%t",
 		cfg.Port, strings.Join(cfg.Bootnodes, ","), cfg.ProtocolID, cfg.NoBootstrap,
 		cfg.NoMDNS, cfg.MinPeers, cfg.MaxPeers, strings.Join(cfg.PersistentPeers, ","),
-		cfg.DiscoveryInterval,
+		cfg.DiscoveryInterval, cfg.ReservedOnly,
 	)
 }
 
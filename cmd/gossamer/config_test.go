@@ -13,10 +13,12 @@
 	"github.com/ChainSafe/gossamer/chain/gssmr"
 	"github.com/ChainSafe/gossamer/dot"
 	ctoml "github.com/ChainSafe/gossamer/dot/config/toml"
+	"github.com/ChainSafe/gossamer/dot/core"
 	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/lib/genesis"
+	"github.com/ChainSafe/gossamer/lib/runtime/life"
 	"github.com/ChainSafe/gossamer/lib/utils"
 
 	"github.com/stretchr/testify/assert"
@@ -391,6 +393,7 @@ func TestCoreConfigFromFlags(t *testing.T) {
 				GrandpaAuthority: true,
 				WasmInterpreter:  gssmr.DefaultWasmInterpreter,
 				GrandpaInterval:  testCfg.Core.GrandpaInterval,
+				OffchainWorker:   core.OffchainWorkerWhenValidating,
 			},
 		},
 		{
@@ -403,6 +406,33 @@ func TestCoreConfigFromFlags(t *testing.T) {
 				GrandpaAuthority: false,
 				WasmInterpreter:  gssmr.DefaultWasmInterpreter,
 				GrandpaInterval:  testCfg.Core.GrandpaInterval,
+				OffchainWorker:   core.OffchainWorkerWhenValidating,
+			},
+		},
+		{
+			"Test gossamer --offchain-worker",
+			[]string{"config", "roles", "offchain-worker"},
+			[]interface{}{testCfgFile.Name(), "4", "always"},
+			dot.CoreConfig{
+				Roles:            4,
+				BabeAuthority:    true,
+				GrandpaAuthority: true,
+				WasmInterpreter:  gssmr.DefaultWasmInterpreter,
+				GrandpaInterval:  testCfg.Core.GrandpaInterval,
+				OffchainWorker:   core.OffchainWorkerAlways,
+			},
+		},
+		{
+			"Test gossamer --wasm-interpreter",
+			[]string{"config", "roles", "wasm-interpreter"},
+			[]interface{}{testCfgFile.Name(), "4", "life"},
+			dot.CoreConfig{
+				Roles:            4,
+				BabeAuthority:    true,
+				GrandpaAuthority: true,
+				WasmInterpreter:  life.Name,
+				GrandpaInterval:  testCfg.Core.GrandpaInterval,
+				OffchainWorker:   core.OffchainWorkerWhenValidating,
 			},
 		},
 	}
@@ -541,6 +571,23 @@ func TestNetworkConfigFromFlags(t *testing.T) {
 				PublicDNS:         "alice",
 			},
 		},
+		{
+			"Test gossamer --reserved-nodes --reserved-only",
+			[]string{"config", "reserved-nodes", "reserved-only"},
+			[]interface{}{testCfgFile.Name(), "/ip4/127.0.0.1/tcp/7001", "true"},
+			dot.NetworkConfig{
+				Port:              testCfg.Network.Port,
+				Bootnodes:         testCfg.Network.Bootnodes,
+				ProtocolID:        testCfg.Network.ProtocolID,
+				NoBootstrap:       testCfg.Network.NoBootstrap,
+				NoMDNS:            false,
+				DiscoveryInterval: time.Second * 10,
+				MinPeers:          testCfg.Network.MinPeers,
+				MaxPeers:          testCfg.Network.MaxPeers,
+				PersistentPeers:   []string{"/ip4/127.0.0.1/tcp/7001"},
+				ReservedOnly:      true,
+			},
+		},
 	}
 
 	for _, c := range testcases {
@@ -101,6 +101,16 @@ func dotConfigToToml(dcfg *dot.Config) *ctoml.Config {
 		BabeAuthority:    dcfg.Core.BabeAuthority,
 		GrandpaAuthority: dcfg.Core.GrandpaAuthority,
 		GrandpaInterval:  uint32(dcfg.Core.GrandpaInterval / time.Second),
+		TxBanSeconds:     uint32(dcfg.Core.TransactionBanDuration / time.Second),
+		TxPoolMaxCount:   dcfg.Core.TransactionLimits.MaxCount,
+		TxPoolMaxBytes:   dcfg.Core.TransactionLimits.MaxBytes,
+		TxMaxPerSender:   dcfg.Core.TransactionLimits.MaxPerSender,
+		OffchainWorker:   string(dcfg.Core.OffchainWorker),
+		TxIndexRetention: dcfg.Core.TransactionIndexRetention,
+		RuntimePoolSize:  dcfg.Core.RuntimePoolSize,
+		RuntimeCache:     dcfg.Core.RuntimeCache,
+		KeyChangesIndex:  dcfg.Core.KeyChangesIndex,
+		WarpSync:         dcfg.Core.WarpSync,
 	}
 
 	cfg.Network = ctoml.NetworkConfig{
@@ -112,6 +122,17 @@ func dotConfigToToml(dcfg *dot.Config) *ctoml.Config {
 		DiscoveryInterval: int(dcfg.Network.DiscoveryInterval / time.Second),
 		MinPeers:          dcfg.Network.MinPeers,
 		MaxPeers:          dcfg.Network.MaxPeers,
+		PersistentPeers:   dcfg.Network.PersistentPeers,
+		ReservedOnly:      dcfg.Network.ReservedOnly,
+
+		TransactionsInPeers:       dcfg.Network.TransactionsInPeers,
+		TransactionsOutPeers:      dcfg.Network.TransactionsOutPeers,
+		TransactionsReservedPeers: dcfg.Network.TransactionsReservedPeers,
+		TransactionsReservedOnly:  dcfg.Network.TransactionsReservedOnly,
+		GrandpaInPeers:            dcfg.Network.GrandpaInPeers,
+		GrandpaOutPeers:           dcfg.Network.GrandpaOutPeers,
+		GrandpaReservedPeers:      dcfg.Network.GrandpaReservedPeers,
+		GrandpaReservedOnly:       dcfg.Network.GrandpaReservedOnly,
 	}
 
 	cfg.RPC = ctoml.RPCConfig{
@@ -179,6 +179,39 @@
 	}
 )
 
+// TraceBlock-only flags
+var (
+	// TraceBlockHashFlag is the hash of the block to trace
+	TraceBlockHashFlag = cli.StringFlag{
+		Name:  "block",
+		Usage: "Hash of the block to re-execute and trace",
+	}
+	// TraceOutputFlag is the path of the file the trace is written to
+	TraceOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Path to output the JSON trace, it is printed if it isn't set",
+	}
+)
+
+// Replay-only flags
+var (
+	// ReplayFromFlag is the number of the first block to replay
+	ReplayFromFlag = cli.UintFlag{
+		Name:  "from",
+		Usage: "Number of the first block to replay",
+	}
+	// ReplayToFlag is the number of the last block to replay
+	ReplayToFlag = cli.UintFlag{
+		Name:  "to",
+		Usage: "Number of the last block to replay, only the first block is replayed if it isn't set",
+	}
+	// ReplayRuntimeFlag is the path to the runtime overriding the runtime of the replayed blocks
+	ReplayRuntimeFlag = cli.StringFlag{
+		Name:  "runtime",
+		Usage: "Path to a .wasm runtime binary to replay the blocks with, instead of the runtime of their state",
+	}
+)
+
 // BuildSpec-only flags
 var (
 	RawFlag = cli.BoolFlag{
@@ -232,6 +265,16 @@
 		Name:  "pubdns",
 		Usage: "Overrides public DNS used for peer to peer networking",
 	}
+	// ReservedNodesFlag Set the reserved nodes
+	ReservedNodesFlag = cli.StringFlag{
+		Name:  "reserved-nodes",
+		Usage: "Comma separated multiaddrs of the reserved nodes, which the node remains connected to",
+	}
+	// ReservedOnlyFlag Only connects to the reserved nodes
+	ReservedOnlyFlag = cli.BoolFlag{
+		Name:  "reserved-only",
+		Usage: "Only connects to the reserved nodes, and rejects the connections of the other nodes",
+	}
 )
 
 // RPC service configuration flags
@@ -375,6 +418,24 @@
 	}
 )
 
+// offchain worker flags
+var (
+	// OffchainWorkerFlag sets when the offchain workers are run for new best blocks
+	OffchainWorkerFlag = cli.StringFlag{
+		Name:  "offchain-worker",
+		Usage: `Execute offchain workers on every block ("always", "never", "when-validating")`,
+	}
+)
+
+// runtime flags
+var (
+	// WasmInterpreterFlag sets the wasm interpreter executing the runtime
+	WasmInterpreterFlag = cli.StringFlag{
+		Name:  "wasm-interpreter",
+		Usage: `Wasm interpreter executing the runtime ("wasmer", "life")`,
+	}
+)
+
 // BABE flags
 var (
 	BABELeadFlag = cli.BoolFlag{
@@ -425,6 +486,8 @@
 		NoMDNSFlag,
 		PublicIPFlag,
 		PublicDNSFlag,
+		ReservedNodesFlag,
+		ReservedOnlyFlag,
 
 		// rpc flags
 		RPCEnabledFlag,
@@ -450,6 +513,12 @@
 
 		// BABE flags
 		BABELeadFlag,
+
+		// offchain worker flags
+		OffchainWorkerFlag,
+
+		// runtime flags
+		WasmInterpreterFlag,
 	}
 )
 
@@ -499,6 +568,19 @@
 		FirstSlotFlag,
 	}
 
+	// ReplayFlags are the flags that are valid for use with the replay subcommand
+	ReplayFlags = append([]cli.Flag{
+		ReplayFromFlag,
+		ReplayToFlag,
+		ReplayRuntimeFlag,
+	}, GlobalFlags...)
+
+	// TraceBlockFlags are the flags that are valid for use with the trace-block subcommand
+	TraceBlockFlags = append([]cli.Flag{
+		TraceBlockHashFlag,
+		TraceOutputFlag,
+	}, GlobalFlags...)
+
 	PruningFlags = []cli.Flag{
 		ChainFlag,
 		ConfigFlag,
@@ -4,13 +4,16 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/ChainSafe/gossamer/dot"
 	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/utils"
 	"github.com/urfave/cli"
@@ -24,6 +27,8 @@
 	importRuntimeCommandName = "import-runtime"
 	importStateCommandName   = "import-state"
 	pruningStateCommandName  = "prune-state"
+	traceBlockCommandName    = "trace-block"
+	replayCommandName        = "replay"
 )
 
 // app is the cli application
@@ -121,6 +126,34 @@
 
 		The default pruning target is the HEAD-256 state`,
 	}
+
+	// replayCommand defines the "replay" subcommand (ie, `gossamer replay`)
+	replayCommand = cli.Command{
+		Action:    FixFlagOrder(replayAction),
+		Name:      replayCommandName,
+		Usage:     "Re-execute a range of blocks and compare the resulting state roots with their headers",
+		ArgsUsage: "",
+		Flags:     ReplayFlags,
+		Category:  "REPLAY",
+		Description: "The replay command re-executes the blocks of the best chain stored in the node databases " +
+			"on the state of their parent, and reports the blocks whose resulting state root differs " +
+			"from the state root of their header, with the first differing storage key.\n" +
+			"\tUsage: gossamer replay --from 1000 --to 2000\n" +
+			"\tTo replay the blocks with another runtime: gossamer replay --from 1000 --to 2000 --runtime runtime.wasm\n",
+	}
+	// traceBlockCommand defines the "trace-block" subcommand (ie, `gossamer trace-block`)
+	traceBlockCommand = cli.Command{
+		Action:    FixFlagOrder(traceBlockAction),
+		Name:      traceBlockCommandName,
+		Usage:     "Re-execute a block and output the trace of its execution as JSON",
+		ArgsUsage: "",
+		Flags:     TraceBlockFlags,
+		Category:  "TRACE-BLOCK",
+		Description: "The trace-block command re-executes a block stored in the node databases on the state of " +
+			"its parent, and outputs the host function calls made by the runtime, the time spent in each " +
+			"of them and the storage they accessed.\n" +
+			"\tUsage: gossamer trace-block --block <block hash> --output trace.json\n",
+	}
 )
 
 // init initialises the cli application
@@ -139,6 +172,8 @@ func init() {
 		importRuntimeCommand,
 		importStateCommand,
 		pruningCommand,
+		traceBlockCommand,
+		replayCommand,
 	}
 	app.Flags = RootFlags
 }
@@ -196,6 +231,90 @@ func importRuntimeAction(ctx *cli.Context) error {
 	return nil
 }
 
+// traceBlockAction re-executes a block of the node databases and outputs its trace as JSON
+func traceBlockAction(ctx *cli.Context) error {
+	hashStr := ctx.String(TraceBlockHashFlag.Name)
+	if hashStr == "" {
+		return errors.New("must provide argument to --block")
+	}
+
+	hash, err := common.HexToHash(hashStr)
+	if err != nil {
+		return fmt.Errorf("invalid block hash %s: %w", hashStr, err)
+	}
+
+	cfg, err := createDotConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	trace, err := dot.TraceBlock(cfg, hash)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(trace, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	outputPath := ctx.String(TraceOutputFlag.Name)
+	if outputPath == "" {
+		fmt.Println(string(out))
+		return nil
+	}
+
+	return os.WriteFile(outputPath, out, 0600)
+}
+
+// replayAction re-executes a range of blocks of the node databases, and fails
+// if the state of any of them diverges from the state of its header
+func replayAction(ctx *cli.Context) error {
+	from := ctx.Uint(ReplayFromFlag.Name)
+	if from == 0 {
+		return errors.New("must provide a non zero argument to --from")
+	}
+
+	to := ctx.Uint(ReplayToFlag.Name)
+	if to == 0 {
+		to = from
+	}
+
+	var code []byte
+	if runtimePath := ctx.String(ReplayRuntimeFlag.Name); runtimePath != "" {
+		var err error
+		code, err = os.ReadFile(filepath.Clean(runtimePath))
+		if err != nil {
+			return fmt.Errorf("cannot read runtime: %w", err)
+		}
+	}
+
+	cfg, err := createDotConfig(ctx)
+	if err != nil {
+		logger.Errorf("failed to create node configuration: %s", err)
+		return err
+	}
+	cfg.Global.BasePath = utils.ExpandDir(cfg.Global.BasePath)
+
+	divergences, err := dot.Replay(cfg, from, to, code)
+	if err != nil {
+		return err
+	}
+
+	for _, divergence := range divergences {
+		fmt.Println(divergence)
+	}
+
+	if len(divergences) > 0 {
+		return fmt.Errorf("%d of %d replayed blocks diverge", len(divergences), to-from+1)
+	}
+
+	logger.Infof("replayed %d blocks without divergence", to-from+1)
+	return nil
+}
+
 // gossamerAction is the root action for the gossamer command, creates a node
 // configuration, loads the keystore, initialises the node if not initialised,
 // then creates and starts the node and node services
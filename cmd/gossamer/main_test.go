@@ -281,3 +281,17 @@ func TestBuildSpecCommandWithOutput(t *testing.T) {
 	require.Empty(t, outb)
 	require.Empty(t, errb)
 }
+
+func TestReplayAction_invalidArguments(t *testing.T) {
+	ctx, err := newTestContext(t.Name(), []string{"from"}, []interface{}{uint(0)})
+	require.NoError(t, err)
+	err = replayAction(ctx)
+	require.EqualError(t, err, "must provide a non zero argument to --from")
+
+	runtimePath := filepath.Join(t.TempDir(), "runtime.wasm")
+	ctx, err = newTestContext(t.Name(), []string{"from", "runtime"}, []interface{}{uint(1), runtimePath})
+	require.NoError(t, err)
+	err = replayAction(ctx)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.Contains(t, err.Error(), "cannot read runtime")
+}
@@ -12,11 +12,13 @@
 	"github.com/ChainSafe/gossamer/chain/gssmr"
 	"github.com/ChainSafe/gossamer/chain/kusama"
 	"github.com/ChainSafe/gossamer/chain/polkadot"
+	"github.com/ChainSafe/gossamer/dot/core"
 	"github.com/ChainSafe/gossamer/dot/state/pruner"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/internal/pprof"
 	"github.com/ChainSafe/gossamer/lib/genesis"
+	"github.com/ChainSafe/gossamer/lib/transaction"
 )
 
 // TODO: update config to have toml rules and perhaps un-export some fields, since we don't want to expose all
@@ -102,6 +104,18 @@ type NetworkConfig struct {
 	DiscoveryInterval time.Duration
 	PublicIP          string
 	PublicDNS         string
+	ReservedOnly      bool
+
+	// the peer sets of the transactions and GRANDPA protocols, the slots of the
+	// general peer set are used if the in and out peers are zero
+	TransactionsInPeers       int
+	TransactionsOutPeers      int
+	TransactionsReservedPeers []string
+	TransactionsReservedOnly  bool
+	GrandpaInPeers            int
+	GrandpaOutPeers           int
+	GrandpaReservedPeers      []string
+	GrandpaReservedOnly       bool
 }
 
 // CoreConfig is to marshal/unmarshal toml core config vars
@@ -112,6 +126,26 @@ type CoreConfig struct {
 	GrandpaAuthority bool
 	WasmInterpreter  string
 	GrandpaInterval  time.Duration
+	// TransactionBanDuration is how long removed transactions are banned for
+	TransactionBanDuration time.Duration
+	// TransactionLimits are the limits of the transactions held in the pool and queue
+	TransactionLimits transaction.Limits
+	// OffchainWorker determines when the offchain workers are run
+	OffchainWorker core.OffchainWorkerMode
+	// TransactionIndexRetention is the number of finalised blocks the indexed transactions are kept for,
+	// they are kept forever if zero
+	TransactionIndexRetention uint
+	// RuntimePoolSize is the maximum number of instances of a runtime used concurrently,
+	// it is the number of CPUs if zero
+	RuntimePoolSize int
+	// RuntimeCache determines whether the compiled runtimes are cached on disk
+	RuntimeCache bool
+	// KeyChangesIndex determines whether the storage keys modified by the imported blocks are indexed,
+	// to answer key change queries without reading the state of every block
+	KeyChangesIndex bool
+	// WarpSync determines whether the node jumps to the latest finalised block using GRANDPA
+	// finality proofs, and downloads its state, before syncing the blocks following it
+	WarpSync bool
 }
 
 // RPCConfig is to marshal/unmarshal toml RPC config vars
@@ -216,6 +250,7 @@ func GssmrConfig() *Config {
 			BabeAuthority:    gssmr.DefaultBabeAuthority,
 			GrandpaAuthority: gssmr.DefaultGrandpaAuthority,
 			WasmInterpreter:  gssmr.DefaultWasmInterpreter,
+			OffchainWorker:   core.OffchainWorkerWhenValidating,
 			GrandpaInterval:  gssmr.DefaultGrandpaInterval,
 		},
 		Network: NetworkConfig{
@@ -278,6 +313,7 @@ func KusamaConfig() *Config {
 		Core: CoreConfig{
 			Roles:           kusama.DefaultRoles,
 			WasmInterpreter: kusama.DefaultWasmInterpreter,
+			OffchainWorker:  core.OffchainWorkerWhenValidating,
 		},
 		Network: NetworkConfig{
 			Port:        kusama.DefaultNetworkPort,
@@ -336,6 +372,7 @@ func PolkadotConfig() *Config {
 		Core: CoreConfig{
 			Roles:           polkadot.DefaultRoles,
 			WasmInterpreter: polkadot.DefaultWasmInterpreter,
+			OffchainWorker:  core.OffchainWorkerWhenValidating,
 		},
 		Network: NetworkConfig{
 			Port:        polkadot.DefaultNetworkPort,
@@ -396,6 +433,7 @@ func DevConfig() *Config {
 			BabeAuthority:    dev.DefaultBabeAuthority,
 			GrandpaAuthority: dev.DefaultGrandpaAuthority,
 			WasmInterpreter:  dev.DefaultWasmInterpreter,
+			OffchainWorker:   core.OffchainWorkerWhenValidating,
 			BABELead:         dev.DefaultBabeAuthority,
 		},
 		Network: NetworkConfig{
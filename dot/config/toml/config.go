@@ -63,6 +63,16 @@ type NetworkConfig struct {
 	DiscoveryInterval int      `toml:"discovery-interval,omitempty"`
 	PublicIP          string   `toml:"public-ip,omitempty"`
 	PublicDNS         string   `toml:"public-dns,omitempty"`
+	ReservedOnly      bool     `toml:"reserved-only,omitempty"`
+
+	TransactionsInPeers       int      `toml:"transactions-in-peers,omitempty"`
+	TransactionsOutPeers      int      `toml:"transactions-out-peers,omitempty"`
+	TransactionsReservedPeers []string `toml:"transactions-reserved-peers,omitempty"`
+	TransactionsReservedOnly  bool     `toml:"transactions-reserved-only,omitempty"`
+	GrandpaInPeers            int      `toml:"grandpa-in-peers,omitempty"`
+	GrandpaOutPeers           int      `toml:"grandpa-out-peers,omitempty"`
+	GrandpaReservedPeers      []string `toml:"grandpa-reserved-peers,omitempty"`
+	GrandpaReservedOnly       bool     `toml:"grandpa-reserved-only,omitempty"`
 }
 
 // CoreConfig is to marshal/unmarshal toml core config vars
@@ -75,6 +85,16 @@ type CoreConfig struct {
 	WasmInterpreter  string `toml:"wasm-interpreter,omitempty"`
 	GrandpaInterval  uint32 `toml:"grandpa-interval,omitempty"`
 	BABELead         bool   `toml:"babe-lead,omitempty"`
+	TxBanSeconds     uint32 `toml:"tx-ban-seconds,omitempty"`
+	TxPoolMaxCount   int    `toml:"tx-pool-max-count,omitempty"`
+	TxPoolMaxBytes   int    `toml:"tx-pool-max-bytes,omitempty"`
+	TxMaxPerSender   int    `toml:"tx-max-per-sender,omitempty"`
+	OffchainWorker   string `toml:"offchain-worker,omitempty"`
+	TxIndexRetention uint   `toml:"tx-index-retention,omitempty"`
+	RuntimePoolSize  int    `toml:"runtime-pool-size,omitempty"`
+	RuntimeCache     bool   `toml:"runtime-cache,omitempty"`
+	KeyChangesIndex  bool   `toml:"key-changes-index,omitempty"`
+	WarpSync         bool   `toml:"warp-sync,omitempty"`
 }
 
 // RPCConfig is to marshal/unmarshal toml RPC config vars
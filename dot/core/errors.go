@@ -48,6 +48,16 @@
 	ErrNilDigestHandler = errors.New("cannot have nil DigestHandler")
 
 	errNilCodeSubstitutedState = errors.New("cannot have nil CodeSubstitutedStat")
+
+	// ErrInvalidLightRequestBlock is returned when the block of a light client request
+	// is neither a block hash nor a SCALE encoded block number
+	ErrInvalidLightRequestBlock = errors.New("invalid light request block")
+
+	// ErrKeyChangesNotIndexed is returned when the modified keys of a block are not in the key changes index
+	ErrKeyChangesNotIndexed = errors.New("key changes of block are not indexed")
+
+	// ErrNoCHTProof is returned when a header cannot be proven with a canonical hash trie
+	ErrNoCHTProof = errors.New("no CHT proof for header")
 )
 
 // ErrNilChannel is returned if a channel is nil
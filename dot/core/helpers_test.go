@@ -128,6 +128,7 @@ func NewTestService(t *testing.T, cfg *Config) *Service {
 	if cfg.Network == nil {
 		net := NewMockNetwork(ctrl)
 		net.EXPECT().GossipMessage(gomock.AssignableToTypeOf(new(network.TransactionMessage)))
+		net.EXPECT().Peers()
 		net.EXPECT().IsSynced().Return(true)
 		net.EXPECT().ReportPeer(
 			gomock.AssignableToTypeOf(peerset.ReputationChange{}),
@@ -27,9 +27,15 @@ type BlockState interface {
 	BestBlockStateRoot() (common.Hash, error)
 	BestBlock() (*types.Block, error)
 	AddBlock(*types.Block) error
+	AddHeader(*types.Header) error
 	GetAllBlocksAtDepth(hash common.Hash) []common.Hash
 	GetBlockByHash(common.Hash) (*types.Block, error)
 	GetBlockStateRoot(bhash common.Hash) (common.Hash, error)
+	GetHeader(bhash common.Hash) (*types.Header, error)
+	GetHashByNumber(num uint) (common.Hash, error)
+	GetHighestFinalisedHeader() (*types.Header, error)
+	HasJustification(bhash common.Hash) (bool, error)
+	GetJustification(bhash common.Hash) ([]byte, error)
 	GenesisHash() common.Hash
 	GetSlotForBlock(common.Hash) (uint64, error)
 	GetFinalisedHeader(uint64, uint64) (*types.Header, error)
@@ -44,6 +50,9 @@ type BlockState interface {
 	HandleRuntimeChanges(newState *rtstorage.TrieState, in runtime.Instance, bHash common.Hash) error
 	GetRuntime(*common.Hash) (runtime.Instance, error)
 	StoreRuntime(common.Hash, runtime.Instance)
+	StoreIndexedTransactions(block *types.Block, ops []rtstorage.IndexOperation) error
+	StoreKeyChanges(hash common.Hash, changes *rtstorage.KeyChanges) error
+	GetKeyChanges(hash common.Hash) (*rtstorage.KeyChanges, error)
 }
 
 // StorageState interface for storage state methods
@@ -61,16 +70,20 @@ type StorageState interface {
 // TransactionState is the interface for transaction state methods
 type TransactionState interface {
 	Push(vt *transaction.ValidTransaction) (common.Hash, error)
-	AddToPool(vt *transaction.ValidTransaction) common.Hash
+	AddToPool(vt *transaction.ValidTransaction) (common.Hash, error)
 	RemoveExtrinsic(ext types.Extrinsic)
 	RemoveExtrinsicFromPool(ext types.Extrinsic)
 	PendingInPool() []*transaction.ValidTransaction
 	Exists(ext types.Extrinsic) bool
+	IsBanned(hash common.Hash) bool
+	PruneStale(number uint)
+	NotifyStatus(ext types.Extrinsic, notification transaction.StatusNotification)
 }
 
 // Network is the interface for the network service
 type Network interface {
 	GossipMessage(network.NotificationsMessage)
+	Peers() []common.PeerInfo
 	IsSynced() bool
 	ReportPeer(change peerset.ReputationChange, p peer.ID)
 }
@@ -0,0 +1,452 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+var _ network.LightHandler = (*Service)(nil)
+
+// CreateRemoteCallResponse executes the requested runtime call against the state of the requested
+// block, and returns a proof of all the storage read during its execution, which a light client
+// can use to re-execute the call.
+func (s *Service) CreateRemoteCallResponse(req *network.RemoteCallRequest) (*network.RemoteCallResponse, error) {
+	hash, err := s.lightRequestBlockHash(req.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	stateRoot, err := s.blockState.GetBlockStateRoot(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state root for block %s: %w", hash, err)
+	}
+
+	ts, err := s.storageState.TrieState(&stateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get trie state for block %s: %w", hash, err)
+	}
+
+	rt, err := s.blockState.GetRuntime(&hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get runtime for block %s: %w", hash, err)
+	}
+
+	recorder := rtstorage.NewRecordingTrieState(ts)
+	recorder.BeginStorageTransaction()
+	defer recorder.RollbackStorageTransaction()
+
+	rt.SetContextStorage(recorder)
+	_, err = rt.Exec(req.Method, req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot execute %s at block %s: %w", req.Method, hash, err)
+	}
+
+	proof, err := s.generateLightProof(stateRoot, recorder.RecordedKeys(), recorder.RecordedChildKeys())
+	if err != nil {
+		return nil, err
+	}
+
+	return &network.RemoteCallResponse{
+		Proof: proof,
+	}, nil
+}
+
+// CreateRemoteReadResponse returns a proof of the requested keys in the state of the requested block
+func (s *Service) CreateRemoteReadResponse(req *network.RemoteReadRequest) (*network.RemoteReadResponse, error) {
+	hash, err := s.lightRequestBlockHash(req.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	stateRoot, err := s.blockState.GetBlockStateRoot(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state root for block %s: %w", hash, err)
+	}
+
+	proof, err := s.generateLightProof(stateRoot, req.Keys, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &network.RemoteReadResponse{
+		Proof: proof,
+	}, nil
+}
+
+// CreateRemoteReadChildResponse returns a proof of the requested keys in the child trie located at
+// the requested storage key, in the state of the requested block. The proof also contains the proof
+// of the child trie root in the main trie.
+func (s *Service) CreateRemoteReadChildResponse(req *network.RemoteReadChildRequest) (
+	*network.RemoteReadResponse, error) {
+	hash, err := s.lightRequestBlockHash(req.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	stateRoot, err := s.blockState.GetBlockStateRoot(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state root for block %s: %w", hash, err)
+	}
+
+	childKeys := map[string][][]byte{
+		string(req.StorageKey): req.Keys,
+	}
+
+	proof, err := s.generateLightProof(stateRoot, nil, childKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &network.RemoteReadResponse{
+		Proof: proof,
+	}, nil
+}
+
+// chtSize is the number of blocks covered by a canonical hash trie
+const chtSize = 2048
+
+// CreateRemoteHeaderResponse returns the requested header of the canonical chain, along with the SCALE
+// encoded proof of its hash in the canonical hash trie (CHT) of the range of blocks containing it, which
+// maps the number of each block of the range to its hash. Since a light client checks the proof against
+// the CHT root it computed from the headers of the range, only headers of finalised ranges are served.
+func (s *Service) CreateRemoteHeaderResponse(req *network.RemoteHeaderRequest) (*network.RemoteHeaderResponse, error) {
+	hash, err := s.lightRequestBlockHash(req.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := s.blockState.GetHeader(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get header for block %s: %w", hash, err)
+	}
+
+	if header.Number == 0 {
+		return nil, fmt.Errorf("%w: genesis block is not in any CHT", ErrNoCHTProof)
+	}
+
+	first := (header.Number-1)/chtSize*chtSize + 1
+	last := first + chtSize - 1
+
+	finalised, err := s.blockState.GetHighestFinalisedHeader()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get highest finalised header: %w", err)
+	}
+
+	if finalised.Number < last {
+		return nil, fmt.Errorf("%w: CHT of block %d ends at unfinalised block %d",
+			ErrNoCHTProof, header.Number, last)
+	}
+
+	// the cached tries are shared, so they are only read while holding the lock
+	s.chts.Lock()
+	defer s.chts.Unlock()
+
+	cht, err := s.canonicalHashTrie(first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	key := chtKey(header.Number)
+	if !bytes.Equal(cht.Get(key), hash[:]) {
+		return nil, fmt.Errorf("%w: block %s is not in the canonical chain", ErrNoCHTProof, hash)
+	}
+
+	proof, err := cht.GenerateProof([][]byte{key})
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate CHT proof for block %s: %w", hash, err)
+	}
+
+	encodedProof, err := scale.Marshal(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return &network.RemoteHeaderResponse{
+		Header: []*types.Header{header},
+		Proof:  encodedProof,
+	}, nil
+}
+
+// maxCachedCHTs is the number of canonical hash tries kept in memory
+const maxCachedCHTs = 16
+
+// chtCache holds the canonical hash tries of the most recently requested finalised ranges,
+// keyed by the first block number of their range. Since these ranges are finalised, their
+// tries never change.
+type chtCache struct {
+	sync.Mutex
+	tries  map[uint]*trie.Trie
+	firsts []uint // first block numbers of the cached tries, from the oldest to the newest
+}
+
+// canonicalHashTrie returns the trie mapping the number of each block from first to last to its hash,
+// from the cache if it was built already. The chts lock must be held.
+func (s *Service) canonicalHashTrie(first, last uint) (*trie.Trie, error) {
+	if cht, ok := s.chts.tries[first]; ok {
+		return cht, nil
+	}
+
+	cht, err := s.buildCanonicalHashTrie(first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.chts.tries == nil {
+		s.chts.tries = make(map[uint]*trie.Trie, maxCachedCHTs)
+	}
+
+	if len(s.chts.firsts) == maxCachedCHTs {
+		delete(s.chts.tries, s.chts.firsts[0])
+		s.chts.firsts = s.chts.firsts[1:]
+	}
+
+	s.chts.tries[first] = cht
+	s.chts.firsts = append(s.chts.firsts, first)
+	return cht, nil
+}
+
+// buildCanonicalHashTrie builds the trie mapping the number of each block from first to last to its hash
+func (s *Service) buildCanonicalHashTrie(first, last uint) (*trie.Trie, error) {
+	cht := trie.NewEmptyTrie()
+	for number := first; number <= last; number++ {
+		hash, err := s.blockState.GetHashByNumber(number)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get hash of block %d: %w", number, err)
+		}
+
+		cht.Put(chtKey(number), hash.ToBytes())
+	}
+
+	// hashing the trie computes the encoding of its nodes, which the proof is made of
+	if _, err := cht.Hash(); err != nil {
+		return nil, fmt.Errorf("cannot hash CHT: %w", err)
+	}
+
+	return cht, nil
+}
+
+// chtKey returns the key of a block number in a canonical hash trie
+func chtKey(number uint) []byte {
+	return encodeBlockNumber(number)
+}
+
+// encodeBlockNumber returns the SCALE encoding of a block number as a u32, as substrate encodes it
+func encodeBlockNumber(number uint) []byte {
+	enc := make([]byte, 4)
+	binary.LittleEndian.PutUint32(enc, uint32(number))
+	return enc
+}
+
+// maxChangesBlocks is the maximum number of blocks scanned for a remote changes request
+const maxChangesBlocks = 4096
+
+// CreateRemoteChangesResponse returns the blocks between the first and the last requested blocks which
+// modify the requested key, looked up in the key changes index. Since blocks have no changes trie, each
+// of these blocks is returned as a pair of its SCALE encoded number and its hash, and the proof contains
+// the proofs of the key in the state of these blocks, which a light client checks against their headers.
+// If the last block is not set, the range ends at the best block. At most maxChangesBlocks blocks are
+// scanned and the response fits in network.MaxLightResponseSize, the number of the last block scanned
+// being returned so that the light client can request the rest of the range.
+func (s *Service) CreateRemoteChangesResponse(req *network.RemoteChangesRequest) (
+	*network.RemoteChangesResponse, error) {
+	var last common.Hash
+	if req.LastBlock != nil {
+		last = *req.LastBlock
+	} else {
+		last = s.blockState.BestBlockHash()
+	}
+
+	hashes, err := s.blockState.SubChain(*req.FirstBlock, last)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get blocks from %s to %s: %w", *req.FirstBlock, last, err)
+	}
+
+	if len(hashes) > maxChangesBlocks {
+		hashes = hashes[:maxChangesBlocks]
+		last = hashes[len(hashes)-1]
+	}
+
+	lastHeader, err := s.blockState.GetHeader(last)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get header for block %s: %w", last, err)
+	}
+	maxNumber := lastHeader.Number
+
+	var keys [][]byte
+	var childKeys map[string][][]byte
+	if req.StorageKey != nil {
+		childKeys = map[string][][]byte{
+			string(*req.StorageKey): {req.Key},
+		}
+	} else {
+		keys = [][]byte{req.Key}
+	}
+
+	var (
+		roots      []network.Pair
+		proof      [][]byte
+		seen       = make(map[string]struct{})
+		size       int
+		sizeLimit  = int(network.MaxLightResponseSize)
+		pairLength = 4 + common.HashLength
+	)
+	for _, hash := range hashes {
+		changes, err := s.blockState.GetKeyChanges(hash)
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrKeyChangesNotIndexed, hash)
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot get key changes for block %s: %w", hash, err)
+		}
+
+		if req.StorageKey != nil && !changes.HasChildTrie(*req.StorageKey) ||
+			req.StorageKey == nil && !changes.Has(req.Key) {
+			continue
+		}
+
+		header, err := s.blockState.GetHeader(hash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get header for block %s: %w", hash, err)
+		}
+
+		nodes, err := s.lightProofNodes(header.StateRoot, keys, childKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		// only count the nodes which are not already in the proof, since it is deduplicated
+		var newNodes [][]byte
+		blockSize := pairLength
+		for _, n := range nodes {
+			if _, ok := seen[string(n)]; ok {
+				continue
+			}
+			seen[string(n)] = struct{}{}
+			newNodes = append(newNodes, n)
+			blockSize += len(n)
+		}
+
+		if size+blockSize > sizeLimit {
+			// the range ends at the block before this one, which the light client can request again
+			maxNumber = header.Number - 1
+			break
+		}
+
+		proof = append(proof, newNodes...)
+		size += blockSize
+
+		roots = append(roots, network.Pair{
+			First:  encodeBlockNumber(header.Number),
+			Second: hash.ToBytes(),
+		})
+	}
+
+	resp := &network.RemoteChangesResponse{
+		Max:        encodeBlockNumber(maxNumber),
+		Proof:      proof,
+		RootsProof: []byte{},
+	}
+	if len(roots) > 0 {
+		resp.Roots = [][]network.Pair{roots}
+	}
+
+	return resp, nil
+}
+
+// lightRequestBlockHash returns the hash of the block referenced by a light client request.
+// The block is either referenced by its hash or by its number, SCALE encoded as a u32.
+func (s *Service) lightRequestBlockHash(block []byte) (common.Hash, error) {
+	if len(block) == common.HashLength {
+		return common.BytesToHash(block), nil
+	}
+
+	var number uint32
+	err := scale.Unmarshal(block, &number)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("%w: 0x%x", ErrInvalidLightRequestBlock, block)
+	}
+
+	return s.blockState.GetHashByNumber(uint(number))
+}
+
+// generateLightProof returns the SCALE encoded set of trie nodes proving the given keys in the state
+// trie with the given root, and the given keys in the child tries keyed by their child storage key.
+func (s *Service) generateLightProof(stateRoot common.Hash, keys [][]byte,
+	childKeys map[string][][]byte) ([]byte, error) {
+	proof, err := s.lightProofNodes(stateRoot, keys, childKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return scale.Marshal(dedupProofNodes(proof))
+}
+
+// lightProofNodes returns the trie nodes proving the given keys in the state trie with the given root,
+// and the given keys in the child tries keyed by their child storage key. The nodes may contain duplicates.
+func (s *Service) lightProofNodes(stateRoot common.Hash, keys [][]byte,
+	childKeys map[string][][]byte) ([][]byte, error) {
+	// the root of each child trie is stored in the main trie, so it must be proven as well
+	mainKeys := append([][]byte{}, keys...)
+	for keyToChild := range childKeys {
+		mainKeys = append(mainKeys, append(append([]byte{}, trie.ChildStorageKeyPrefix...), keyToChild...))
+	}
+
+	proof, err := s.storageState.GenerateTrieProof(stateRoot, mainKeys)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate proof for state root %s: %w", stateRoot, err)
+	}
+
+	for keyToChild, keys := range childKeys {
+		if len(keys) == 0 {
+			continue
+		}
+
+		childKey := append(append([]byte{}, trie.ChildStorageKeyPrefix...), keyToChild...)
+		childRoot, err := s.storageState.GetStorage(&stateRoot, childKey)
+		if err != nil {
+			return nil, err
+		}
+
+		// the absence of the child trie is proven by the main trie proof
+		if len(childRoot) != common.HashLength {
+			continue
+		}
+
+		childProof, err := s.storageState.GenerateTrieProof(common.BytesToHash(childRoot), keys)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate proof for child trie 0x%x: %w", keyToChild, err)
+		}
+
+		proof = append(proof, childProof...)
+	}
+
+	return proof, nil
+}
+
+func dedupProofNodes(nodes [][]byte) [][]byte {
+	seen := make(map[string]struct{}, len(nodes))
+	deduped := make([][]byte, 0, len(nodes))
+	for _, n := range nodes {
+		if _, ok := seen[string(n)]; ok {
+			continue
+		}
+
+		seen[string(n)] = struct{}{}
+		deduped = append(deduped, n)
+	}
+
+	return deduped
+}
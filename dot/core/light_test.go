@@ -0,0 +1,363 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	mocksruntime "github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Service_CreateRemoteCallResponse(t *testing.T) {
+	t.Parallel()
+	blockHash := common.Hash{1}
+	stateRoot := common.Hash{2}
+
+	ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+	require.NoError(t, err)
+
+	var contextStorage runtime.Storage
+	runtimeMock := new(mocksruntime.Instance)
+	runtimeMock.On("SetContextStorage", mock.Anything).Run(func(args mock.Arguments) {
+		contextStorage = args.Get(0).(runtime.Storage)
+	})
+	runtimeMock.On("Exec", "AccountNonceApi_account_nonce", []byte{1}).Run(func(mock.Arguments) {
+		contextStorage.Get([]byte("nonce"))
+	}).Return([]byte{2}, nil)
+
+	expectedKeys := [][]byte{common.CodeKey, []byte(":heappages"), []byte("nonce")}
+
+	ctrl := gomock.NewController(t)
+	mockBlockState := NewMockBlockState(ctrl)
+	mockBlockState.EXPECT().GetBlockStateRoot(blockHash).Return(stateRoot, nil)
+	mockBlockState.EXPECT().GetRuntime(&blockHash).Return(runtimeMock, nil)
+	mockStorageState := NewMockStorageState(ctrl)
+	mockStorageState.EXPECT().TrieState(&stateRoot).Return(ts, nil)
+	mockStorageState.EXPECT().GenerateTrieProof(stateRoot, expectedKeys).Return([][]byte{{3}, {4}}, nil)
+
+	s := &Service{
+		blockState:   mockBlockState,
+		storageState: mockStorageState,
+	}
+
+	req := &network.RemoteCallRequest{
+		Block:  blockHash[:],
+		Method: "AccountNonceApi_account_nonce",
+		Data:   []byte{1},
+	}
+	resp, err := s.CreateRemoteCallResponse(req)
+	require.NoError(t, err)
+
+	expectedProof, err := scale.Marshal([][]byte{{3}, {4}})
+	require.NoError(t, err)
+	assert.Equal(t, expectedProof, resp.Proof)
+}
+
+func Test_Service_CreateRemoteReadResponse(t *testing.T) {
+	t.Parallel()
+	blockHash := common.Hash{1}
+	stateRoot := common.Hash{2}
+	keys := [][]byte{{5}, {6}}
+
+	ctrl := gomock.NewController(t)
+	mockBlockState := NewMockBlockState(ctrl)
+	mockBlockState.EXPECT().GetBlockStateRoot(blockHash).Return(stateRoot, nil)
+	mockStorageState := NewMockStorageState(ctrl)
+	mockStorageState.EXPECT().GenerateTrieProof(stateRoot, keys).Return([][]byte{{3}, {4}}, nil)
+
+	s := &Service{
+		blockState:   mockBlockState,
+		storageState: mockStorageState,
+	}
+
+	resp, err := s.CreateRemoteReadResponse(&network.RemoteReadRequest{Block: blockHash[:], Keys: keys})
+	require.NoError(t, err)
+
+	expectedProof, err := scale.Marshal([][]byte{{3}, {4}})
+	require.NoError(t, err)
+	assert.Equal(t, expectedProof, resp.Proof)
+}
+
+func Test_Service_CreateRemoteReadChildResponse(t *testing.T) {
+	t.Parallel()
+	blockHash := common.Hash{1}
+	stateRoot := common.Hash{2}
+	childRoot := common.Hash{3}
+	keys := [][]byte{{5}, {6}}
+	childKey := append(append([]byte{}, trie.ChildStorageKeyPrefix...), []byte("child")...)
+
+	ctrl := gomock.NewController(t)
+	mockBlockState := NewMockBlockState(ctrl)
+	mockBlockState.EXPECT().GetBlockStateRoot(blockHash).Return(stateRoot, nil)
+	mockStorageState := NewMockStorageState(ctrl)
+	mockStorageState.EXPECT().GenerateTrieProof(stateRoot, [][]byte{childKey}).Return([][]byte{{7}, {8}}, nil)
+	mockStorageState.EXPECT().GetStorage(&stateRoot, childKey).Return(childRoot[:], nil)
+	mockStorageState.EXPECT().GenerateTrieProof(childRoot, keys).Return([][]byte{{8}, {9}}, nil)
+
+	s := &Service{
+		blockState:   mockBlockState,
+		storageState: mockStorageState,
+	}
+
+	req := &network.RemoteReadChildRequest{
+		Block:      blockHash[:],
+		StorageKey: []byte("child"),
+		Keys:       keys,
+	}
+	resp, err := s.CreateRemoteReadChildResponse(req)
+	require.NoError(t, err)
+
+	expectedProof, err := scale.Marshal([][]byte{{7}, {8}, {9}})
+	require.NoError(t, err)
+	assert.Equal(t, expectedProof, resp.Proof)
+}
+
+func Test_Service_CreateRemoteHeaderResponse(t *testing.T) {
+	t.Parallel()
+	hashOf := func(number uint) common.Hash {
+		return common.Hash{byte(number), byte(number >> 8), 1}
+	}
+	blockHash := hashOf(21)
+	header := &types.Header{Number: 21}
+	// substrate encodes the block number of a request as a fixed width u32
+	encNumber := []byte{21, 0, 0, 0}
+
+	cht := trie.NewEmptyTrie()
+	for number := uint(1); number <= chtSize; number++ {
+		cht.Put(chtKey(number), hashOf(number).ToBytes())
+	}
+	chtRoot := cht.MustHash()
+
+	t.Run("invalid block", func(t *testing.T) {
+		t.Parallel()
+		s := &Service{}
+
+		_, err := s.CreateRemoteHeaderResponse(&network.RemoteHeaderRequest{Block: []byte{}})
+		assert.ErrorIs(t, err, ErrInvalidLightRequestBlock)
+	})
+
+	t.Run("genesis header", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetHeader(blockHash).Return(&types.Header{}, nil)
+		s := &Service{blockState: mockBlockState}
+
+		_, err := s.CreateRemoteHeaderResponse(&network.RemoteHeaderRequest{Block: blockHash[:]})
+		assert.ErrorIs(t, err, ErrNoCHTProof)
+	})
+
+	t.Run("CHT not finalised", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetHeader(blockHash).Return(header, nil)
+		mockBlockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: chtSize - 1}, nil)
+		s := &Service{blockState: mockBlockState}
+
+		_, err := s.CreateRemoteHeaderResponse(&network.RemoteHeaderRequest{Block: blockHash[:]})
+		assert.ErrorIs(t, err, ErrNoCHTProof)
+	})
+
+	t.Run("header not in canonical chain", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetHeader(common.Hash{2}).Return(header, nil)
+		mockBlockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: chtSize}, nil)
+		mockBlockState.EXPECT().GetHashByNumber(gomock.Any()).DoAndReturn(
+			func(number uint) (common.Hash, error) { return hashOf(number), nil }).Times(chtSize)
+		s := &Service{blockState: mockBlockState}
+
+		_, err := s.CreateRemoteHeaderResponse(&network.RemoteHeaderRequest{Block: []byte{2, 31: 0}})
+		assert.ErrorIs(t, err, ErrNoCHTProof)
+	})
+
+	t.Run("header by number with CHT proof", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetHashByNumber(gomock.Any()).DoAndReturn(
+			func(number uint) (common.Hash, error) { return hashOf(number), nil }).Times(chtSize + 1)
+		mockBlockState.EXPECT().GetHeader(blockHash).Return(header, nil)
+		mockBlockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: chtSize + 5}, nil)
+		s := &Service{blockState: mockBlockState}
+
+		resp, err := s.CreateRemoteHeaderResponse(&network.RemoteHeaderRequest{Block: encNumber})
+		require.NoError(t, err)
+		assert.Equal(t, []*types.Header{header}, resp.Header)
+
+		var proof [][]byte
+		err = scale.Unmarshal(resp.Proof, &proof)
+		require.NoError(t, err)
+
+		ok, err := trie.VerifyProof(proof, chtRoot[:], []trie.Pair{{Key: chtKey(21), Value: blockHash[:]}})
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("CHT built once per range", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetHashByNumber(gomock.Any()).DoAndReturn(
+			func(number uint) (common.Hash, error) { return hashOf(number), nil }).Times(chtSize)
+		mockBlockState.EXPECT().GetHeader(blockHash).Return(header, nil).Times(2)
+		mockBlockState.EXPECT().GetHighestFinalisedHeader().Return(&types.Header{Number: chtSize}, nil).Times(2)
+		s := &Service{blockState: mockBlockState}
+
+		for i := 0; i < 2; i++ {
+			resp, err := s.CreateRemoteHeaderResponse(&network.RemoteHeaderRequest{Block: blockHash[:]})
+			require.NoError(t, err)
+
+			var proof [][]byte
+			err = scale.Unmarshal(resp.Proof, &proof)
+			require.NoError(t, err)
+
+			ok, err := trie.VerifyProof(proof, chtRoot[:], []trie.Pair{{Key: chtKey(21), Value: blockHash[:]}})
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+	})
+}
+
+func Test_Service_CreateRemoteChangesResponse(t *testing.T) {
+	t.Parallel()
+	first, second, last := common.Hash{1}, common.Hash{2}, common.Hash{3}
+	key := []byte("key")
+
+	t.Run("block not indexed", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().BestBlockHash().Return(last)
+		mockBlockState.EXPECT().SubChain(first, last).Return([]common.Hash{first, last}, nil)
+		mockBlockState.EXPECT().GetHeader(last).Return(&types.Header{Number: 3}, nil)
+		mockBlockState.EXPECT().GetKeyChanges(first).Return(nil, chaindb.ErrKeyNotFound)
+		s := &Service{blockState: mockBlockState}
+
+		_, err := s.CreateRemoteChangesResponse(&network.RemoteChangesRequest{FirstBlock: &first, Key: key})
+		assert.ErrorIs(t, err, ErrKeyChangesNotIndexed)
+	})
+
+	t.Run("blocks modifying the key", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().SubChain(first, last).Return([]common.Hash{first, second, last}, nil)
+		mockBlockState.EXPECT().GetKeyChanges(first).Return(&rtstorage.KeyChanges{Keys: [][]byte{key}}, nil)
+		mockBlockState.EXPECT().GetKeyChanges(second).Return(&rtstorage.KeyChanges{Keys: [][]byte{{1}}}, nil)
+		mockBlockState.EXPECT().GetKeyChanges(last).Return(&rtstorage.KeyChanges{Keys: [][]byte{key}}, nil)
+		mockBlockState.EXPECT().GetHeader(first).Return(&types.Header{Number: 1, StateRoot: common.Hash{4}}, nil)
+		mockBlockState.EXPECT().GetHeader(last).Return(&types.Header{Number: 3, StateRoot: common.Hash{5}}, nil).Times(2)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().GenerateTrieProof(common.Hash{4}, [][]byte{key}).Return([][]byte{{6}, {7}}, nil)
+		mockStorageState.EXPECT().GenerateTrieProof(common.Hash{5}, [][]byte{key}).Return([][]byte{{6}, {8}}, nil)
+		s := &Service{
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
+		}
+
+		req := &network.RemoteChangesRequest{
+			FirstBlock: &first,
+			LastBlock:  &last,
+			Key:        key,
+		}
+		resp, err := s.CreateRemoteChangesResponse(req)
+		require.NoError(t, err)
+
+		firstNumber := []byte{1, 0, 0, 0}
+		lastNumber := []byte{3, 0, 0, 0}
+
+		expected := &network.RemoteChangesResponse{
+			Max:   lastNumber,
+			Proof: [][]byte{{6}, {7}, {8}},
+			Roots: [][]network.Pair{{
+				{First: firstNumber, Second: first.ToBytes()},
+				{First: lastNumber, Second: last.ToBytes()},
+			}},
+			RootsProof: []byte{},
+		}
+		assert.Equal(t, expected, resp)
+	})
+	t.Run("number of blocks scanned is limited", func(t *testing.T) {
+		t.Parallel()
+		hashes := make([]common.Hash, maxChangesBlocks+1)
+		for i := range hashes {
+			hashes[i] = common.Hash{byte(i), byte(i >> 8), 1}
+		}
+		lastScanned := hashes[maxChangesBlocks-1]
+
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().SubChain(hashes[0], last).Return(hashes, nil)
+		mockBlockState.EXPECT().GetHeader(lastScanned).Return(&types.Header{Number: maxChangesBlocks}, nil)
+		mockBlockState.EXPECT().GetKeyChanges(gomock.Any()).Return(&rtstorage.KeyChanges{}, nil).Times(maxChangesBlocks)
+		s := &Service{blockState: mockBlockState}
+
+		req := &network.RemoteChangesRequest{
+			FirstBlock: &hashes[0],
+			LastBlock:  &last,
+			Key:        key,
+		}
+		resp, err := s.CreateRemoteChangesResponse(req)
+		require.NoError(t, err)
+
+		expected := &network.RemoteChangesResponse{
+			Max:        encodeBlockNumber(maxChangesBlocks),
+			RootsProof: []byte{},
+		}
+		assert.Equal(t, expected, resp)
+	})
+
+	t.Run("response size is limited", func(t *testing.T) {
+		t.Parallel()
+		largeNode := make([]byte, network.MaxLightResponseSize/2)
+		otherLargeNode := append([]byte{1}, largeNode[1:]...)
+
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().SubChain(first, last).Return([]common.Hash{first, second, last}, nil)
+		mockBlockState.EXPECT().GetKeyChanges(first).Return(&rtstorage.KeyChanges{Keys: [][]byte{key}}, nil)
+		mockBlockState.EXPECT().GetKeyChanges(second).Return(&rtstorage.KeyChanges{Keys: [][]byte{key}}, nil)
+		mockBlockState.EXPECT().GetHeader(first).Return(&types.Header{Number: 1, StateRoot: common.Hash{4}}, nil)
+		mockBlockState.EXPECT().GetHeader(second).Return(&types.Header{Number: 2, StateRoot: common.Hash{5}}, nil)
+		mockBlockState.EXPECT().GetHeader(last).Return(&types.Header{Number: 3}, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().GenerateTrieProof(common.Hash{4}, [][]byte{key}).Return([][]byte{largeNode}, nil)
+		mockStorageState.EXPECT().GenerateTrieProof(common.Hash{5}, [][]byte{key}).Return([][]byte{otherLargeNode}, nil)
+		s := &Service{
+			blockState:   mockBlockState,
+			storageState: mockStorageState,
+		}
+
+		req := &network.RemoteChangesRequest{
+			FirstBlock: &first,
+			LastBlock:  &last,
+			Key:        key,
+		}
+		resp, err := s.CreateRemoteChangesResponse(req)
+		require.NoError(t, err)
+
+		expected := &network.RemoteChangesResponse{
+			Max:        encodeBlockNumber(1),
+			Proof:      [][]byte{largeNode},
+			Roots:      [][]network.Pair{{{First: encodeBlockNumber(1), Second: first.ToBytes()}}},
+			RootsProof: []byte{},
+		}
+		assert.Equal(t, expected, resp)
+	})
+}
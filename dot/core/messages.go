@@ -46,7 +46,11 @@ func (s *Service) validateTransaction(peerID peer.ID, head *types.Header, rt run
 	vtx := transaction.NewValidTransaction(tx, validity)
 
 	// push to the transaction queue of BABE session
-	hash := s.transactionState.AddToPool(vtx)
+	hash, err := s.transactionState.AddToPool(vtx)
+	if err != nil {
+		logger.Debugf("failed to add transaction with hash %s to pool: %s", hash, err)
+		return nil, false, nil
+	}
 	logger.Tracef("added transaction with hash %s to pool", hash)
 
 	return validity, true, nil
@@ -80,6 +84,11 @@ func (s *Service) HandleTransactionMessage(peerID peer.ID, msg *network.Transact
 
 	allTxsAreValid := true
 	for _, tx := range txs {
+		if s.transactionState.IsBanned(tx.Hash()) {
+			logger.Debugf("ignoring banned transaction with hash %s", tx.Hash())
+			continue
+		}
+
 		validity, isValidTxn, err := s.validateTransaction(peerID, head, rt, tx)
 		if err != nil {
 			return false, fmt.Errorf("failed validating transaction for peerID %s: %w", peerID, err)
@@ -55,8 +55,9 @@ type mockStorageState struct {
 }
 
 type mockTxnState struct {
-	input *transaction.ValidTransaction
-	hash  common.Hash
+	input  *transaction.ValidTransaction
+	hash   common.Hash
+	banned bool
 }
 
 type mockSetContextStorage struct {
@@ -315,6 +316,36 @@ type args struct {
 			},
 			exp: true,
 		},
+		{
+			name: "banned transaction",
+			mockNetwork: &mockNetwork{
+				IsSynced: true,
+				ReportPeer: &mockReportPeer{
+					change: peerset.ReputationChange{
+						Value:  peerset.GoodTransactionValue,
+						Reason: peerset.GoodTransactionReason,
+					},
+					id: peer.ID("jimbo"),
+				},
+			},
+			mockBlockState: &mockBlockState{
+				bestHeader: &mockBestHeader{
+					header: testEmptyHeader,
+				},
+				getRuntime: &mockGetRuntime{
+					runtime: runtimeMock,
+				},
+			},
+			mockTxnState: &mockTxnState{
+				banned: true,
+			},
+			args: args{
+				peerID: peer.ID("jimbo"),
+				msg: &network.TransactionMessage{
+					Extrinsics: []types.Extrinsic{{1, 2, 3}},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -350,11 +381,16 @@ type args struct {
 					tt.mockStorageState.err)
 				s.storageState = storageState
 			}
-			if tt.mockTxnState != nil {
-				txnState := NewMockTransactionState(ctrl)
-				txnState.EXPECT().AddToPool(tt.mockTxnState.input).Return(tt.mockTxnState.hash)
-				s.transactionState = txnState
+			txnState := NewMockTransactionState(ctrl)
+			if tt.mockTxnState != nil && tt.mockTxnState.banned {
+				txnState.EXPECT().IsBanned(gomock.Any()).Return(true).AnyTimes()
+			} else {
+				txnState.EXPECT().IsBanned(gomock.Any()).Return(false).AnyTimes()
+			}
+			if tt.mockTxnState != nil && tt.mockTxnState.input != nil {
+				txnState.EXPECT().AddToPool(tt.mockTxnState.input).Return(tt.mockTxnState.hash, nil)
 			}
+			s.transactionState = txnState
 			if tt.mockRuntime != nil {
 				rt := tt.mockRuntime.runtime
 				rt.On("SetContextStorage", tt.mockRuntime.setContextStorage.trieState)
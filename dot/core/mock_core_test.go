@@ -55,6 +55,20 @@ func (mr *MockBlockStateMockRecorder) AddBlock(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBlock", reflect.TypeOf((*MockBlockState)(nil).AddBlock), arg0)
 }
 
+// AddHeader mocks base method.
+func (m *MockBlockState) AddHeader(arg0 *types.Header) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddHeader indicates an expected call of AddHeader.
+func (mr *MockBlockStateMockRecorder) AddHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddHeader", reflect.TypeOf((*MockBlockState)(nil).AddHeader), arg0)
+}
+
 // BestBlock mocks base method.
 func (m *MockBlockState) BestBlock() (*types.Block, error) {
 	m.ctrl.T.Helper()
@@ -270,6 +284,51 @@ func (mr *MockBlockStateMockRecorder) GetFinalisedNotifierChannel() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFinalisedNotifierChannel", reflect.TypeOf((*MockBlockState)(nil).GetFinalisedNotifierChannel))
 }
 
+// GetHashByNumber mocks base method.
+func (m *MockBlockState) GetHashByNumber(arg0 uint) (common.Hash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHashByNumber", arg0)
+	ret0, _ := ret[0].(common.Hash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHashByNumber indicates an expected call of GetHashByNumber.
+func (mr *MockBlockStateMockRecorder) GetHashByNumber(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHashByNumber", reflect.TypeOf((*MockBlockState)(nil).GetHashByNumber), arg0)
+}
+
+// GetHeader mocks base method.
+func (m *MockBlockState) GetHeader(arg0 common.Hash) (*types.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeader", arg0)
+	ret0, _ := ret[0].(*types.Header)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeader indicates an expected call of GetHeader.
+func (mr *MockBlockStateMockRecorder) GetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeader", reflect.TypeOf((*MockBlockState)(nil).GetHeader), arg0)
+}
+
+// GetHighestFinalisedHeader mocks base method.
+func (m *MockBlockState) GetHighestFinalisedHeader() (*types.Header, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHighestFinalisedHeader")
+	ret0, _ := ret[0].(*types.Header)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHighestFinalisedHeader indicates an expected call of GetHighestFinalisedHeader.
+func (mr *MockBlockStateMockRecorder) GetHighestFinalisedHeader() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHighestFinalisedHeader", reflect.TypeOf((*MockBlockState)(nil).GetHighestFinalisedHeader))
+}
+
 // GetImportedBlockNotifierChannel mocks base method.
 func (m *MockBlockState) GetImportedBlockNotifierChannel() chan *types.Block {
 	m.ctrl.T.Helper()
@@ -284,6 +343,36 @@ func (mr *MockBlockStateMockRecorder) GetImportedBlockNotifierChannel() *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImportedBlockNotifierChannel", reflect.TypeOf((*MockBlockState)(nil).GetImportedBlockNotifierChannel))
 }
 
+// GetJustification mocks base method.
+func (m *MockBlockState) GetJustification(arg0 common.Hash) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJustification", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJustification indicates an expected call of GetJustification.
+func (mr *MockBlockStateMockRecorder) GetJustification(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJustification", reflect.TypeOf((*MockBlockState)(nil).GetJustification), arg0)
+}
+
+// GetKeyChanges mocks base method.
+func (m *MockBlockState) GetKeyChanges(arg0 common.Hash) (*storage.KeyChanges, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyChanges", arg0)
+	ret0, _ := ret[0].(*storage.KeyChanges)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKeyChanges indicates an expected call of GetKeyChanges.
+func (mr *MockBlockStateMockRecorder) GetKeyChanges(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyChanges", reflect.TypeOf((*MockBlockState)(nil).GetKeyChanges), arg0)
+}
+
 // GetRuntime mocks base method.
 func (m *MockBlockState) GetRuntime(arg0 *common.Hash) (runtime.Instance, error) {
 	m.ctrl.T.Helper()
@@ -328,6 +417,21 @@ func (mr *MockBlockStateMockRecorder) HandleRuntimeChanges(arg0, arg1, arg2 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleRuntimeChanges", reflect.TypeOf((*MockBlockState)(nil).HandleRuntimeChanges), arg0, arg1, arg2)
 }
 
+// HasJustification mocks base method.
+func (m *MockBlockState) HasJustification(arg0 common.Hash) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasJustification", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasJustification indicates an expected call of HasJustification.
+func (mr *MockBlockStateMockRecorder) HasJustification(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasJustification", reflect.TypeOf((*MockBlockState)(nil).HasJustification), arg0)
+}
+
 // HighestCommonAncestor mocks base method.
 func (m *MockBlockState) HighestCommonAncestor(arg0, arg1 common.Hash) (common.Hash, error) {
 	m.ctrl.T.Helper()
@@ -343,6 +447,34 @@ func (mr *MockBlockStateMockRecorder) HighestCommonAncestor(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HighestCommonAncestor", reflect.TypeOf((*MockBlockState)(nil).HighestCommonAncestor), arg0, arg1)
 }
 
+// StoreIndexedTransactions mocks base method.
+func (m *MockBlockState) StoreIndexedTransactions(arg0 *types.Block, arg1 []storage.IndexOperation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreIndexedTransactions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreIndexedTransactions indicates an expected call of StoreIndexedTransactions.
+func (mr *MockBlockStateMockRecorder) StoreIndexedTransactions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreIndexedTransactions", reflect.TypeOf((*MockBlockState)(nil).StoreIndexedTransactions), arg0, arg1)
+}
+
+// StoreKeyChanges mocks base method.
+func (m *MockBlockState) StoreKeyChanges(arg0 common.Hash, arg1 *storage.KeyChanges) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreKeyChanges", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreKeyChanges indicates an expected call of StoreKeyChanges.
+func (mr *MockBlockStateMockRecorder) StoreKeyChanges(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreKeyChanges", reflect.TypeOf((*MockBlockState)(nil).StoreKeyChanges), arg0, arg1)
+}
+
 // StoreRuntime mocks base method.
 func (m *MockBlockState) StoreRuntime(arg0 common.Hash, arg1 runtime.Instance) {
 	m.ctrl.T.Helper()
@@ -545,11 +677,12 @@ func (m *MockTransactionState) EXPECT() *MockTransactionStateMockRecorder {
 }
 
 // AddToPool mocks base method.
-func (m *MockTransactionState) AddToPool(arg0 *transaction.ValidTransaction) common.Hash {
+func (m *MockTransactionState) AddToPool(arg0 *transaction.ValidTransaction) (common.Hash, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "AddToPool", arg0)
 	ret0, _ := ret[0].(common.Hash)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // AddToPool indicates an expected call of AddToPool.
@@ -572,6 +705,32 @@ func (mr *MockTransactionStateMockRecorder) Exists(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockTransactionState)(nil).Exists), arg0)
 }
 
+// IsBanned mocks base method.
+func (m *MockTransactionState) IsBanned(arg0 common.Hash) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBanned", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsBanned indicates an expected call of IsBanned.
+func (mr *MockTransactionStateMockRecorder) IsBanned(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBanned", reflect.TypeOf((*MockTransactionState)(nil).IsBanned), arg0)
+}
+
+// NotifyStatus mocks base method.
+func (m *MockTransactionState) NotifyStatus(arg0 types.Extrinsic, arg1 transaction.StatusNotification) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NotifyStatus", arg0, arg1)
+}
+
+// NotifyStatus indicates an expected call of NotifyStatus.
+func (mr *MockTransactionStateMockRecorder) NotifyStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyStatus", reflect.TypeOf((*MockTransactionState)(nil).NotifyStatus), arg0, arg1)
+}
+
 // PendingInPool mocks base method.
 func (m *MockTransactionState) PendingInPool() []*transaction.ValidTransaction {
 	m.ctrl.T.Helper()
@@ -586,6 +745,18 @@ func (mr *MockTransactionStateMockRecorder) PendingInPool() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingInPool", reflect.TypeOf((*MockTransactionState)(nil).PendingInPool))
 }
 
+// PruneStale mocks base method.
+func (m *MockTransactionState) PruneStale(arg0 uint) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "PruneStale", arg0)
+}
+
+// PruneStale indicates an expected call of PruneStale.
+func (mr *MockTransactionStateMockRecorder) PruneStale(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneStale", reflect.TypeOf((*MockTransactionState)(nil).PruneStale), arg0)
+}
+
 // Push mocks base method.
 func (m *MockTransactionState) Push(arg0 *transaction.ValidTransaction) (common.Hash, error) {
 	m.ctrl.T.Helper()
@@ -674,6 +845,20 @@ func (mr *MockNetworkMockRecorder) IsSynced() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSynced", reflect.TypeOf((*MockNetwork)(nil).IsSynced))
 }
 
+// Peers mocks base method.
+func (m *MockNetwork) Peers() []common.PeerInfo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Peers")
+	ret0, _ := ret[0].([]common.PeerInfo)
+	return ret0
+}
+
+// Peers indicates an expected call of Peers.
+func (mr *MockNetworkMockRecorder) Peers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peers", reflect.TypeOf((*MockNetwork)(nil).Peers))
+}
+
 // ReportPeer mocks base method.
 func (m *MockNetwork) ReportPeer(arg0 peerset.ReputationChange, arg1 peer.ID) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,154 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/transaction"
+)
+
+// maxConcurrentOffchainWorkers is the maximum number of offchain workers running at the same time,
+// the offchain worker of a new best block is skipped when it is reached.
+const maxConcurrentOffchainWorkers = 4
+
+// OffchainWorkerMode determines when the offchain workers are run
+type OffchainWorkerMode string
+
+const (
+	// OffchainWorkerAlways runs the offchain workers for each new best block
+	OffchainWorkerAlways = OffchainWorkerMode("always")
+	// OffchainWorkerNever never runs the offchain workers
+	OffchainWorkerNever = OffchainWorkerMode("never")
+	// OffchainWorkerWhenValidating runs the offchain workers for each new best block,
+	// only if the node is a validator
+	OffchainWorkerWhenValidating = OffchainWorkerMode("when-validating")
+)
+
+// IsValid checks whether the offchain worker mode is valid
+func (m OffchainWorkerMode) IsValid() bool {
+	switch m {
+	case OffchainWorkerAlways, OffchainWorkerNever, OffchainWorkerWhenValidating:
+		return true
+	default:
+		return false
+	}
+}
+
+// enabled returns true if the offchain workers are run for a node with the given validator role
+func (m OffchainWorkerMode) enabled(validator bool) bool {
+	switch m {
+	case OffchainWorkerAlways:
+		return true
+	case OffchainWorkerWhenValidating:
+		return validator
+	default:
+		return false
+	}
+}
+
+// startOffchainWorker runs the offchain worker of the block in the background if it is the best block,
+// the offchain workers are enabled and the concurrency limit is not reached.
+func (s *Service) startOffchainWorker(header *types.Header) {
+	if !s.offchainWorkerMode.IsValid() || s.offchainWorkerMode == OffchainWorkerNever {
+		return
+	}
+
+	hash := header.Hash()
+	if hash != s.blockState.BestBlockHash() {
+		return
+	}
+
+	rt, err := s.blockState.GetRuntime(&hash)
+	if err != nil {
+		logger.Warnf("failed to get runtime for offchain worker of block %s: %s", hash, err)
+		return
+	}
+
+	if !s.offchainWorkerMode.enabled(rt.Validator()) {
+		return
+	}
+
+	select {
+	case s.offchainWorkers <- struct{}{}:
+	default:
+		logger.Debugf("too many offchain workers running, skipping offchain worker of block %s", hash)
+		return
+	}
+
+	go func() {
+		defer func() { <-s.offchainWorkers }()
+
+		err := s.runOffchainWorker(header, rt, wasmer.NewInstance)
+		if errors.Is(err, runtime.ErrExportFunctionNotFound) {
+			logger.Debugf("runtime of block %s has no offchain worker", hash)
+		} else if err != nil {
+			logger.Warnf("failed to run offchain worker of block %s: %s", hash, err)
+		}
+	}()
+}
+
+// pooledInstance is a runtime instance created by a wasmer executor
+type pooledInstance interface {
+	Executor() *wasmer.Executor
+}
+
+// runOffchainWorker runs the offchain worker of the block in a separate runtime instance,
+// so it does not block the runtime used to import blocks. If the runtime of the block was
+// created by an executor, the instance is created from the module it compiled already.
+func (s *Service) runOffchainWorker(header *types.Header, rt runtime.Instance, instance wasmerInstanceFunc) error {
+	state, err := s.storageState.TrieState(&header.StateRoot)
+	if err != nil {
+		return fmt.Errorf("cannot get state of block: %w", err)
+	}
+
+	cfg := &wasmer.Config{
+		Imports: wasmer.ImportsNodeRuntime,
+	}
+
+	cfg.Storage = state
+	cfg.Keystore = rt.Keystore()
+	cfg.NodeStorage = rt.NodeStorage()
+	cfg.Network = rt.NetworkService()
+	cfg.Transaction = &offchainTransactionPool{service: s}
+
+	if rt.Validator() {
+		cfg.Role = 4
+	}
+
+	if pooled, ok := rt.(pooledInstance); ok {
+		cfg.Executor = pooled.Executor()
+	}
+
+	worker, err := instance(state.LoadCode(), cfg)
+	if err != nil {
+		return fmt.Errorf("cannot create runtime instance: %w", err)
+	}
+	defer worker.Stop()
+
+	logger.Debugf("running offchain worker of block %s", header.Hash())
+	return worker.OffchainWorker(header)
+}
+
+// offchainTransactionPool validates the transactions submitted by the offchain workers,
+// before adding them to the transaction pool and broadcasting them.
+type offchainTransactionPool struct {
+	service *Service
+}
+
+// AddToPool validates the extrinsic of the transaction as a local transaction and adds it to the pool,
+// the validity of the transaction given by the offchain worker is ignored.
+func (p *offchainTransactionPool) AddToPool(vt *transaction.ValidTransaction) (common.Hash, error) {
+	err := p.service.handleSubmittedExtrinsic(vt.Extrinsic, types.TxnLocal)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return vt.Extrinsic.Hash(), nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package core
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	mocksruntime "github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/trie"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffchainWorkerMode_enabled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mode      OffchainWorkerMode
+		validator bool
+		exp       bool
+	}{
+		{mode: OffchainWorkerAlways, exp: true},
+		{mode: OffchainWorkerAlways, validator: true, exp: true},
+		{mode: OffchainWorkerNever},
+		{mode: OffchainWorkerNever, validator: true},
+		{mode: OffchainWorkerWhenValidating},
+		{mode: OffchainWorkerWhenValidating, validator: true, exp: true},
+		{mode: OffchainWorkerMode(""), validator: true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.exp, tt.mode.enabled(tt.validator), "mode %q validator %t", tt.mode, tt.validator)
+	}
+}
+
+func Test_Service_startOffchainWorker(t *testing.T) {
+	t.Parallel()
+
+	header := &types.Header{Number: 1}
+
+	t.Run("never", func(t *testing.T) {
+		t.Parallel()
+		s := &Service{offchainWorkerMode: OffchainWorkerNever}
+		s.startOffchainWorker(header)
+	})
+
+	t.Run("not best block", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().BestBlockHash().Return(common.Hash{1})
+
+		s := &Service{
+			blockState:         mockBlockState,
+			offchainWorkerMode: OffchainWorkerAlways,
+		}
+		s.startOffchainWorker(header)
+	})
+
+	t.Run("not validating", func(t *testing.T) {
+		t.Parallel()
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("Validator").Return(false)
+
+		hash := header.Hash()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().BestBlockHash().Return(hash)
+		mockBlockState.EXPECT().GetRuntime(&hash).Return(runtimeMock, nil)
+
+		s := &Service{
+			blockState:         mockBlockState,
+			offchainWorkerMode: OffchainWorkerWhenValidating,
+			offchainWorkers:    make(chan struct{}),
+		}
+		s.startOffchainWorker(header)
+		runtimeMock.AssertExpectations(t)
+	})
+
+	t.Run("concurrency limit reached", func(t *testing.T) {
+		t.Parallel()
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("Validator").Return(true)
+
+		hash := header.Hash()
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().BestBlockHash().Return(hash)
+		mockBlockState.EXPECT().GetRuntime(&hash).Return(runtimeMock, nil)
+
+		// the storage state is not called as the offchain worker is skipped
+		s := &Service{
+			blockState:         mockBlockState,
+			offchainWorkerMode: OffchainWorkerWhenValidating,
+			offchainWorkers:    make(chan struct{}),
+		}
+		s.startOffchainWorker(header)
+	})
+}
+
+func Test_Service_runOffchainWorker(t *testing.T) {
+	t.Parallel()
+
+	header := &types.Header{Number: 1, StateRoot: common.Hash{2}}
+	code := []byte{1, 2, 3}
+
+	t.Run("trie state error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&header.StateRoot).Return(nil, errTestDummyError)
+
+		s := &Service{storageState: mockStorageState}
+		err := s.runOffchainWorker(header, nil, nil)
+		assert.ErrorIs(t, err, errTestDummyError)
+	})
+
+	t.Run("runtime instance config", func(t *testing.T) {
+		t.Parallel()
+		ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+		ts.Set(common.CodeKey, code)
+
+		ks := keystore.NewGlobalKeystore()
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("Keystore").Return(ks)
+		runtimeMock.On("NodeStorage").Return(runtime.NodeStorage{})
+		runtimeMock.On("NetworkService").Return(new(runtime.TestRuntimeNetwork))
+		runtimeMock.On("Validator").Return(true)
+
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&header.StateRoot).Return(ts, nil)
+
+		s := &Service{storageState: mockStorageState}
+		newTestInstance := func(c []byte, cfg *wasmer.Config) (*wasmer.Instance, error) {
+			assert.Equal(t, code, c)
+			assert.Equal(t, ts, cfg.Storage)
+			assert.Equal(t, ks, cfg.Keystore)
+			assert.Equal(t, byte(4), cfg.Role)
+			assert.Equal(t, &offchainTransactionPool{service: s}, cfg.Transaction)
+			return nil, errTestDummyError
+		}
+
+		err = s.runOffchainWorker(header, runtimeMock, newTestInstance)
+		assert.ErrorIs(t, err, errTestDummyError)
+		runtimeMock.AssertExpectations(t)
+	})
+	t.Run("runtime instance created by executor", func(t *testing.T) {
+		t.Parallel()
+		ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+		ts.Set(common.CodeKey, code)
+
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("Keystore").Return(keystore.NewGlobalKeystore())
+		runtimeMock.On("NodeStorage").Return(runtime.NodeStorage{})
+		runtimeMock.On("NetworkService").Return(new(runtime.TestRuntimeNetwork))
+		runtimeMock.On("Validator").Return(false)
+		executor := wasmer.NewExecutor("", 1)
+		rt := &pooledTestInstance{Instance: runtimeMock, executor: executor}
+
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(&header.StateRoot).Return(ts, nil)
+
+		s := &Service{storageState: mockStorageState}
+		newTestInstance := func(c []byte, cfg *wasmer.Config) (*wasmer.Instance, error) {
+			// the instance is created from the module compiled by the executor of the block runtime
+			assert.Same(t, executor, cfg.Executor)
+			return nil, errTestDummyError
+		}
+
+		err = s.runOffchainWorker(header, rt, newTestInstance)
+		assert.ErrorIs(t, err, errTestDummyError)
+	})
+}
+
+type pooledTestInstance struct {
+	*mocksruntime.Instance
+	executor *wasmer.Executor
+}
+
+func (i *pooledTestInstance) Executor() *wasmer.Executor { return i.executor }
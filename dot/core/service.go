@@ -6,8 +6,10 @@
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 
+	"github.com/ChainSafe/chaindb"
 	"github.com/ChainSafe/gossamer/dot/network"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/internal/log"
@@ -56,6 +58,12 @@ type Service struct {
 
 	// Keystore
 	keys *keystore.GlobalKeystore
+
+	offchainWorkerMode OffchainWorkerMode
+	offchainWorkers    chan struct{} // limits the number of concurrent offchain workers
+
+	// canonical hash tries served to light clients
+	chts chtCache
 }
 
 // Config holds the configuration for the core Service.
@@ -73,6 +81,9 @@ type Config struct {
 
 	CodeSubstitutes      map[common.Hash]string
 	CodeSubstitutedState CodeSubstitutedState
+
+	// OffchainWorkerMode determines when the offchain workers are run, they are never run if it is empty
+	OffchainWorkerMode OffchainWorkerMode
 }
 
 // NewService returns a new core service that connects the runtime, BABE
@@ -120,6 +131,8 @@ func NewService(cfg *Config) (*Service, error) {
 		codeSubstitute:       cfg.CodeSubstitutes,
 		codeSubstitutedState: cfg.CodeSubstitutedState,
 		digestHandler:        cfg.DigestHandler,
+		offchainWorkerMode:   cfg.OffchainWorkerMode,
+		offchainWorkers:      make(chan struct{}, maxConcurrentOffchainWorkers),
 	}
 
 	return srv, nil
@@ -160,6 +173,25 @@ func (s *Service) HandleBlockImport(block *types.Block, state *rtstorage.TrieSta
 	return s.handleBlock(block, state)
 }
 
+// HandleHeaderImport handles a header imported by a node syncing headers only, such as a light client.
+// The block is stored without body, as neither the body nor the state of the block is available.
+func (s *Service) HandleHeaderImport(header *types.Header) error {
+	if header == nil {
+		return ErrNilBlockHandlerParameter
+	}
+
+	err := s.blockState.AddHeader(header)
+	if err != nil && !errors.Is(err, blocktree.ErrBlockExists) {
+		return err
+	}
+
+	logger.Debugf("imported header of block %s", header.Hash())
+
+	// handle consensus digests
+	s.digestHandler.HandleDigests(header)
+	return nil
+}
+
 // HandleBlockProduced handles a block that was produced by us
 // It is handled the same as an imported block in terms of state updates; the only difference
 // is we send a BlockAnnounceMessage to our peers.
@@ -213,6 +245,22 @@ func (s *Service) handleBlock(block *types.Block, state *rtstorage.TrieState) er
 		}
 	}
 
+	// store the data indexed by the runtime while executing the block
+	if ops := state.IndexOperations(); len(ops) > 0 {
+		err = s.blockState.StoreIndexedTransactions(block, ops)
+		if err != nil {
+			return fmt.Errorf("failed to store indexed transactions: %w", err)
+		}
+	}
+
+	// index the storage keys modified by the block
+	if changes := state.KeyChanges(); changes != nil && len(changes.Keys) > 0 {
+		err = s.blockState.StoreKeyChanges(block.Header.Hash(), changes)
+		if err != nil {
+			return fmt.Errorf("failed to store key changes: %w", err)
+		}
+	}
+
 	logger.Debugf("imported block %s and stored state trie with root %s",
 		block.Header.Hash(), state.MustRoot())
 
@@ -320,6 +368,7 @@ func (s *Service) handleBlocksAsync() {
 			}
 
 			s.maintainTransactionPool(block)
+			s.startOffchainWorker(&block.Header)
 		case <-s.ctx.Done():
 			return
 		}
@@ -370,7 +419,13 @@ func (s *Service) handleChainReorg(prev, curr common.Hash) error {
 			continue
 		}
 
+		retractedHash := hash
 		for _, ext := range *body {
+			s.transactionState.NotifyStatus(ext, transaction.StatusNotification{
+				Status: transaction.Retracted,
+				Hash:   &retractedHash,
+			})
+
 			logger.Tracef("validating transaction on re-org chain for extrinsic %s", ext)
 			encExt, err := scale.Marshal(ext)
 			if err != nil {
@@ -392,11 +447,16 @@ func (s *Service) handleChainReorg(prev, curr common.Hash) error {
 			txv, err := rt.ValidateTransaction(externalExt)
 			if err != nil {
 				logger.Debugf("failed to validate transaction for extrinsic %s: %s", ext, err)
+				s.transactionState.NotifyStatus(ext, transaction.StatusNotification{Status: transaction.Invalid})
 				continue
 			}
 
 			vtx := transaction.NewValidTransaction(encExt, txv)
-			s.transactionState.AddToPool(vtx)
+			_, err = s.transactionState.AddToPool(vtx)
+			if err != nil {
+				logger.Debugf("failed to re-add transaction for extrinsic %s to pool: %s", ext, err)
+				s.transactionState.NotifyStatus(ext, transaction.StatusNotification{Status: transaction.Dropped})
+			}
 		}
 	}
 
@@ -409,11 +469,19 @@ func (s *Service) handleChainReorg(prev, curr common.Hash) error {
 // See https://github.com/paritytech/substrate/blob/74804b5649eccfb83c90aec87bdca58e5d5c8789/client/transaction-pool/src/lib.rs#L545
 func (s *Service) maintainTransactionPool(block *types.Block) {
 	// remove extrinsics included in a block
+	blockHash := block.Header.Hash()
 	for _, ext := range block.Body {
 		s.transactionState.RemoveExtrinsic(ext)
+		s.transactionState.NotifyStatus(ext, transaction.StatusNotification{
+			Status: transaction.InBlock,
+			Hash:   &blockHash,
+		})
 	}
 
-	// re-validate transactions in the pool and move them to the queue
+	// drop the transactions whose longevity expired
+	s.transactionState.PruneStale(block.Header.Number)
+
+	// re-validate transactions in the pool and move the ready ones to the queue
 	txs := s.transactionState.PendingInPool()
 	for _, tx := range txs {
 		// get the best block corresponding runtime
@@ -426,16 +494,26 @@ func (s *Service) maintainTransactionPool(block *types.Block) {
 		txnValidity, err := rt.ValidateTransaction(tx.Extrinsic)
 		if err != nil {
 			s.transactionState.RemoveExtrinsic(tx.Extrinsic)
+			s.transactionState.NotifyStatus(tx.Extrinsic, transaction.StatusNotification{Status: transaction.Invalid})
 			continue
 		}
 
 		tx = transaction.NewValidTransaction(tx.Extrinsic, txnValidity)
 
-		// Err is only thrown if tx is already in pool, in which case it still gets removed
-		h, _ := s.transactionState.Push(tx)
+		// the tx stays in the pool until the tags it requires are provided by the queue.
+		// If the tx is already in the queue, it gets removed from the pool,
+		// otherwise it is dropped since it cannot replace the transactions of the queue.
+		h, err := s.transactionState.Push(tx)
+		if err != nil {
+			s.transactionState.RemoveExtrinsicFromPool(tx.Extrinsic)
+			if !errors.Is(err, transaction.ErrTransactionExists) {
+				logger.Debugf("dropped transaction %s: %s", h, err)
+				s.transactionState.NotifyStatus(tx.Extrinsic, transaction.StatusNotification{Status: transaction.Dropped})
+			}
+			continue
+		}
 
-		s.transactionState.RemoveExtrinsicFromPool(tx.Extrinsic)
-		logger.Tracef("moved transaction %s to queue", h)
+		logger.Tracef("pushed transaction %s", h)
 	}
 }
 
@@ -470,6 +548,27 @@ func (s *Service) DecodeSessionKeys(enc []byte) ([]byte, error) {
 	return rt.DecodeSessionKeys(enc)
 }
 
+// GenerateSessionKeys executes the runtime GenerateSessionKeys, which generates new session keys
+// and stores them in the keystore, and returns the scale encoded public keys
+func (s *Service) GenerateSessionKeys() ([]byte, error) {
+	ts, err := s.storageState.TrieState(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := s.blockState.GetRuntime(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// generating the keys must not have any side effect on the block state
+	ts.BeginStorageTransaction()
+	defer ts.RollbackStorageTransaction()
+
+	rt.SetContextStorage(ts)
+	return rt.GenerateSessionKeys(nil)
+}
+
 // GetRuntimeVersion gets the current RuntimeVersion
 func (s *Service) GetRuntimeVersion(bhash *common.Hash) (runtime.Version, error) {
 	var stateRootHash *common.Hash
@@ -499,10 +598,20 @@ func (s *Service) GetRuntimeVersion(bhash *common.Hash) (runtime.Version, error)
 
 // HandleSubmittedExtrinsic is used to send a Transaction message containing a Extrinsic @ext
 func (s *Service) HandleSubmittedExtrinsic(ext types.Extrinsic) error {
+	return s.handleSubmittedExtrinsic(ext, types.TxnExternal)
+}
+
+// handleSubmittedExtrinsic validates the extrinsic coming from the given source, then adds it
+// to the transaction pool and broadcasts it
+func (s *Service) handleSubmittedExtrinsic(ext types.Extrinsic, source types.TransactionSource) error {
 	if s.net == nil {
 		return nil
 	}
 
+	if s.transactionState.IsBanned(ext.Hash()) {
+		return transaction.ErrTransactionBanned
+	}
+
 	if s.transactionState.Exists(ext) {
 		return nil
 	}
@@ -519,20 +628,33 @@ func (s *Service) HandleSubmittedExtrinsic(ext types.Extrinsic) error {
 	}
 
 	rt.SetContextStorage(ts)
-	// the transaction source is External
-	externalExt := types.Extrinsic(append([]byte{byte(types.TxnExternal)}, ext...))
-	txv, err := rt.ValidateTransaction(externalExt)
+	sourcedExt := types.Extrinsic(append([]byte{byte(source)}, ext...))
+	txv, err := rt.ValidateTransaction(sourcedExt)
 	if err != nil {
 		return err
 	}
 
 	// add transaction to pool
 	vtx := transaction.NewValidTransaction(ext, txv)
-	s.transactionState.AddToPool(vtx)
+	_, err = s.transactionState.AddToPool(vtx)
+	if err != nil {
+		return err
+	}
 
 	// broadcast transaction
 	msg := &network.TransactionMessage{Extrinsics: []types.Extrinsic{ext}}
 	s.net.GossipMessage(msg)
+
+	peers := s.net.Peers()
+	peerIDs := make([]string, len(peers))
+	for i, p := range peers {
+		peerIDs[i] = p.PeerID
+	}
+
+	s.transactionState.NotifyStatus(ext, transaction.StatusNotification{
+		Status: transaction.Broadcast,
+		Peers:  peerIDs,
+	})
 	return nil
 }
 
@@ -564,8 +686,79 @@ func (s *Service) GetMetadata(bhash *common.Hash) ([]byte, error) {
 	return rt.Metadata()
 }
 
+// Call executes the runtime API function `method` with the SCALE encoded `data` against
+// the state and runtime of the block with the given hash. If no block hash is provided,
+// the best block is used. Storage changes made during the call are discarded.
+func (s *Service) Call(method string, data []byte, bhash *common.Hash) ([]byte, error) {
+	if bhash == nil {
+		bestHash := s.blockState.BestBlockHash()
+		bhash = &bestHash
+	}
+
+	stateRootHash, err := s.storageState.GetStateRootFromBlock(bhash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state root for block %s: %w", bhash, err)
+	}
+
+	ts, err := s.storageState.TrieState(stateRootHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get trie state for block %s: %w", bhash, err)
+	}
+
+	rt, err := s.blockState.GetRuntime(bhash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get runtime for block %s: %w", bhash, err)
+	}
+
+	// the call must not have any side effect on the block state
+	ts.BeginStorageTransaction()
+	defer ts.RollbackStorageTransaction()
+
+	rt.SetContextStorage(ts)
+	ret, err := rt.Exec(method, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the returned slice points into the runtime memory, which may be reused by the next call
+	return append([]byte{}, ret...), nil
+}
+
+// TraceBlock re-executes the block on the state of its parent with tracing enabled,
+// and returns the host function calls and storage accesses made by the runtime.
+func (s *Service) TraceBlock(hash common.Hash) (*runtime.BlockTrace, error) {
+	block, err := s.blockState.GetBlockByHash(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get block %s: %w", hash, err)
+	}
+
+	parentHash := block.Header.ParentHash
+	stateRoot, err := s.blockState.GetBlockStateRoot(parentHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state root for block %s: %w", parentHash, err)
+	}
+
+	ts, err := s.storageState.TrieState(&stateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get trie state for block %s: %w", parentHash, err)
+	}
+
+	rt, err := s.blockState.GetRuntime(&parentHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get runtime for block %s: %w", parentHash, err)
+	}
+
+	trace, err := runtime.TraceBlock(rt, ts, block)
+	if err != nil {
+		return nil, fmt.Errorf("cannot execute block %s: %w", hash, err)
+	}
+
+	return trace, nil
+}
+
 // QueryStorage returns the key-value data by block based on `keys` params
-// on every block starting `from` until `to` block, if `to` is not nil
+// on every block starting `from` until `to` block, if `to` is not nil.
+// The keys are only read again in the blocks modifying them, if the key changes of the blocks are indexed.
 func (s *Service) QueryStorage(from, to common.Hash, keys ...string) (map[common.Hash]QueryKeyValueChanges, error) {
 	if to.IsEmpty() {
 		to = s.blockState.BestBlockHash()
@@ -578,18 +771,67 @@ func (s *Service) QueryStorage(from, to common.Hash, keys ...string) (map[common
 
 	queries := make(map[common.Hash]QueryKeyValueChanges)
 
+	// each block of the sub chain is the parent of the next one
+	var previous QueryKeyValueChanges
 	for _, hash := range blocksToQuery {
-		changes, err := s.tryQueryStorage(hash, keys...)
+		changes, err := s.queryStorageChanges(hash, previous, keys...)
 		if err != nil {
 			return nil, err
 		}
 
 		queries[hash] = changes
+		previous = changes
 	}
 
 	return queries, nil
 }
 
+// queryStorageChanges returns the values of the `keys` in the block's state, given their values in the state
+// of its parent, if any. Only the keys modified by the block are read if its key changes are indexed.
+func (s *Service) queryStorageChanges(block common.Hash, previous QueryKeyValueChanges, keys ...string) (
+	QueryKeyValueChanges, error) {
+	if previous == nil {
+		return s.tryQueryStorage(block, keys...)
+	}
+
+	keyChanges, err := s.blockState.GetKeyChanges(block)
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return s.tryQueryStorage(block, keys...)
+	} else if err != nil {
+		return nil, err
+	}
+
+	changes := make(QueryKeyValueChanges, len(previous))
+	var modified []string
+	for _, k := range keys {
+		keyBytes, err := common.HexToBytes(k)
+		if err != nil {
+			return nil, err
+		}
+
+		if keyChanges.Has(keyBytes) {
+			modified = append(modified, k)
+		} else if value, ok := previous[k]; ok {
+			changes[k] = value
+		}
+	}
+
+	if len(modified) == 0 {
+		return changes, nil
+	}
+
+	values, err := s.tryQueryStorage(block, modified...)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, value := range values {
+		changes[k] = value
+	}
+
+	return changes, nil
+}
+
 // tryQueryStorage will try to get all the `keys` inside the block's current state
 func (s *Service) tryQueryStorage(block common.Hash, keys ...string) (QueryKeyValueChanges, error) {
 	stateRootHash, err := s.storageState.GetStateRootFromBlock(&block)
@@ -391,7 +391,8 @@ func TestMaintainTransactionPool_EmptyBlock(t *testing.T) {
 	hashes := make([]common.Hash, len(txs))
 
 	for i, tx := range txs {
-		h := ts.AddToPool(tx)
+		h, err := ts.AddToPool(tx)
+		require.NoError(t, err)
 		hashes[i] = h
 	}
 
@@ -440,7 +441,8 @@ func TestMaintainTransactionPool_BlockWithExtrinsics(t *testing.T) {
 	hashes := make([]common.Hash, len(txs))
 
 	for i, tx := range txs {
-		h := ts.AddToPool(tx)
+		h, err := ts.AddToPool(tx)
+		require.NoError(t, err)
 		hashes[i] = h
 	}
 
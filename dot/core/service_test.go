@@ -415,6 +415,48 @@ func Test_Service_HandleBlockProduced(t *testing.T) {
 	})
 }
 
+func Test_Service_HandleHeaderImport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil input", func(t *testing.T) {
+		t.Parallel()
+		s := &Service{}
+		err := s.HandleHeaderImport(nil)
+		assert.ErrorIs(t, err, ErrNilBlockHandlerParameter)
+	})
+
+	t.Run("add block error", func(t *testing.T) {
+		t.Parallel()
+		header := &types.Header{Number: 21}
+
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().AddHeader(header).Return(blocktree.ErrParentNotFound)
+
+		s := &Service{blockState: mockBlockState}
+		err := s.HandleHeaderImport(header)
+		assert.ErrorIs(t, err, blocktree.ErrParentNotFound)
+	})
+
+	t.Run("happy path", func(t *testing.T) {
+		t.Parallel()
+		header := &types.Header{Number: 21}
+
+		ctrl := gomock.NewController(t)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().AddHeader(header).Return(blocktree.ErrBlockExists)
+		mockDigestHandler := NewMockDigestHandler(ctrl)
+		mockDigestHandler.EXPECT().HandleDigests(header)
+
+		s := &Service{
+			blockState:    mockBlockState,
+			digestHandler: mockDigestHandler,
+		}
+		err := s.HandleHeaderImport(header)
+		assert.NoError(t, err)
+	})
+}
+
 func Test_Service_maintainTransactionPool(t *testing.T) {
 	t.Parallel()
 	t.Run("Validate Transaction err", func(t *testing.T) {
@@ -439,9 +481,18 @@ func Test_Service_maintainTransactionPool(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		runtimeMock := new(mocksruntime.Instance)
 		runtimeMock.On("ValidateTransaction", types.Extrinsic{21}).Return(nil, errTestDummyError)
+		blockHash := block.Header.Hash()
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().RemoveExtrinsic(types.Extrinsic{21}).Times(2)
+		mockTxnState.EXPECT().NotifyStatus(types.Extrinsic{21}, transaction.StatusNotification{
+			Status: transaction.InBlock,
+			Hash:   &blockHash,
+		})
+		mockTxnState.EXPECT().PruneStale(block.Header.Number)
 		mockTxnState.EXPECT().PendingInPool().Return([]*transaction.ValidTransaction{vt})
+		mockTxnState.EXPECT().NotifyStatus(types.Extrinsic{21}, transaction.StatusNotification{
+			Status: transaction.Invalid,
+		})
 		mockBlockState := NewMockBlockState(ctrl)
 		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
 		s := &Service{
@@ -475,11 +526,16 @@ func Test_Service_maintainTransactionPool(t *testing.T) {
 		runtimeMock := new(mocksruntime.Instance)
 		runtimeMock.On("ValidateTransaction", types.Extrinsic{21}).
 			Return(&transaction.Validity{Propagate: true}, nil)
+		blockHash := block.Header.Hash()
 		mockTxnState := NewMockTransactionState(ctrl)
 		mockTxnState.EXPECT().RemoveExtrinsic(types.Extrinsic{21})
+		mockTxnState.EXPECT().NotifyStatus(types.Extrinsic{21}, transaction.StatusNotification{
+			Status: transaction.InBlock,
+			Hash:   &blockHash,
+		})
+		mockTxnState.EXPECT().PruneStale(block.Header.Number)
 		mockTxnState.EXPECT().PendingInPool().Return([]*transaction.ValidTransaction{vt})
 		mockTxnState.EXPECT().Push(tx).Return(common.Hash{}, nil)
-		mockTxnState.EXPECT().RemoveExtrinsicFromPool(types.Extrinsic{21})
 		mockBlockStateOk := NewMockBlockState(ctrl)
 		mockBlockStateOk.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
 		s := &Service{
@@ -488,6 +544,35 @@ func Test_Service_maintainTransactionPool(t *testing.T) {
 		}
 		s.maintainTransactionPool(&block)
 	})
+
+	t.Run("Push transaction err", func(t *testing.T) {
+		t.Parallel()
+		testHeader := types.NewEmptyHeader()
+		block := types.NewBlock(*testHeader, *types.NewBody(nil))
+
+		vt := transaction.NewValidTransaction(types.Extrinsic{21}, &transaction.Validity{})
+		tx := transaction.NewValidTransaction(types.Extrinsic{21}, &transaction.Validity{Propagate: true})
+
+		ctrl := gomock.NewController(t)
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("ValidateTransaction", types.Extrinsic{21}).
+			Return(&transaction.Validity{Propagate: true}, nil)
+		mockTxnState := NewMockTransactionState(ctrl)
+		mockTxnState.EXPECT().PruneStale(block.Header.Number)
+		mockTxnState.EXPECT().PendingInPool().Return([]*transaction.ValidTransaction{vt})
+		mockTxnState.EXPECT().Push(tx).Return(common.Hash{}, errTestDummyError)
+		mockTxnState.EXPECT().RemoveExtrinsicFromPool(types.Extrinsic{21})
+		mockTxnState.EXPECT().NotifyStatus(types.Extrinsic{21}, transaction.StatusNotification{
+			Status: transaction.Dropped,
+		})
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
+		s := &Service{
+			transactionState: mockTxnState,
+			blockState:       mockBlockState,
+		}
+		s.maintainTransactionPool(&block)
+	})
 }
 
 func Test_Service_handleBlocksAsync(t *testing.T) {
@@ -568,9 +653,18 @@ func Test_Service_handleBlocksAsync(t *testing.T) {
 		mockBlockState.EXPECT().HighestCommonAncestor(common.Hash{}, block.Header.Hash()).
 			Return(common.Hash{}, errTestDummyError)
 		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
+		blockHash := block.Header.Hash()
 		mockTxnStateErr := NewMockTransactionState(ctrl)
 		mockTxnStateErr.EXPECT().RemoveExtrinsic(types.Extrinsic{21}).Times(2)
+		mockTxnStateErr.EXPECT().NotifyStatus(types.Extrinsic{21}, transaction.StatusNotification{
+			Status: transaction.InBlock,
+			Hash:   &blockHash,
+		})
+		mockTxnStateErr.EXPECT().PruneStale(block.Header.Number)
 		mockTxnStateErr.EXPECT().PendingInPool().Return([]*transaction.ValidTransaction{vt})
+		mockTxnStateErr.EXPECT().NotifyStatus(types.Extrinsic{21}, transaction.StatusNotification{
+			Status: transaction.Invalid,
+		})
 		blockAddChan := make(chan *types.Block)
 		go func() {
 			blockAddChan <- &block
@@ -585,3 +679,174 @@ func Test_Service_handleBlocksAsync(t *testing.T) {
 		s.handleBlocksAsync()
 	})
 }
+
+func Test_Service_Call(t *testing.T) {
+	t.Parallel()
+	blockHash := common.Hash{1}
+	stateRoot := common.Hash{2}
+
+	t.Run("state root error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().GetStateRootFromBlock(&blockHash).Return(nil, errTestDummyError)
+		s := &Service{storageState: mockStorageState}
+
+		res, err := s.Call("Core_version", nil, &blockHash)
+		assert.ErrorIs(t, err, errTestDummyError)
+		assert.Nil(t, res)
+	})
+
+	t.Run("trie state error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().GetStateRootFromBlock(&blockHash).Return(&stateRoot, nil)
+		mockStorageState.EXPECT().TrieState(&stateRoot).Return(nil, errTestDummyError)
+		s := &Service{storageState: mockStorageState}
+
+		res, err := s.Call("Core_version", nil, &blockHash)
+		assert.ErrorIs(t, err, errTestDummyError)
+		assert.Nil(t, res)
+	})
+
+	t.Run("runtime exec error", func(t *testing.T) {
+		t.Parallel()
+		ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+
+		ctrl := gomock.NewController(t)
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("SetContextStorage", ts)
+		runtimeMock.On("Exec", "Unknown_method", []byte{1}).Return(nil, runtime.ErrExportFunctionNotFound)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().GetStateRootFromBlock(&blockHash).Return(&stateRoot, nil)
+		mockStorageState.EXPECT().TrieState(&stateRoot).Return(ts, nil)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetRuntime(&blockHash).Return(runtimeMock, nil)
+		s := &Service{
+			storageState: mockStorageState,
+			blockState:   mockBlockState,
+		}
+
+		res, err := s.Call("Unknown_method", []byte{1}, &blockHash)
+		assert.ErrorIs(t, err, runtime.ErrExportFunctionNotFound)
+		assert.Nil(t, res)
+	})
+
+	t.Run("best block ok", func(t *testing.T) {
+		t.Parallel()
+		ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+
+		ctrl := gomock.NewController(t)
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("SetContextStorage", ts)
+		runtimeMock.On("Exec", "AccountNonceApi_account_nonce", []byte{1}).Return([]byte{2}, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().GetStateRootFromBlock(&blockHash).Return(&stateRoot, nil)
+		mockStorageState.EXPECT().TrieState(&stateRoot).Return(ts, nil)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().BestBlockHash().Return(blockHash)
+		mockBlockState.EXPECT().GetRuntime(&blockHash).Return(runtimeMock, nil)
+		s := &Service{
+			storageState: mockStorageState,
+			blockState:   mockBlockState,
+		}
+
+		res, err := s.Call("AccountNonceApi_account_nonce", []byte{1}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{2}, res)
+	})
+}
+
+func Test_Service_GenerateSessionKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("trie state error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(nil, errTestDummyError)
+		s := &Service{storageState: mockStorageState}
+
+		res, err := s.GenerateSessionKeys()
+		assert.ErrorIs(t, err, errTestDummyError)
+		assert.Nil(t, res)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+		ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+
+		ctrl := gomock.NewController(t)
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("SetContextStorage", ts)
+		runtimeMock.On("GenerateSessionKeys", (*[]byte)(nil)).Return([]byte{1, 2}, nil)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(ts, nil)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
+		s := &Service{
+			storageState: mockStorageState,
+			blockState:   mockBlockState,
+		}
+
+		res, err := s.GenerateSessionKeys()
+		require.NoError(t, err)
+		assert.Equal(t, []byte{1, 2}, res)
+	})
+}
+
+func Test_Service_HandleSubmittedExtrinsic(t *testing.T) {
+	t.Parallel()
+
+	ext := types.Extrinsic{1, 2, 3}
+
+	t.Run("banned", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		mockTxState := NewMockTransactionState(ctrl)
+		mockTxState.EXPECT().IsBanned(ext.Hash()).Return(true)
+		s := &Service{
+			net:              NewMockNetwork(ctrl),
+			transactionState: mockTxState,
+		}
+
+		err := s.HandleSubmittedExtrinsic(ext)
+		assert.ErrorIs(t, err, transaction.ErrTransactionBanned)
+	})
+
+	t.Run("pool full", func(t *testing.T) {
+		t.Parallel()
+		ts, err := rtstorage.NewTrieState(trie.NewEmptyTrie())
+		require.NoError(t, err)
+
+		validity := &transaction.Validity{Priority: 1}
+		externalExt := types.Extrinsic(append([]byte{byte(types.TxnExternal)}, ext...))
+		runtimeMock := new(mocksruntime.Instance)
+		runtimeMock.On("SetContextStorage", ts)
+		runtimeMock.On("ValidateTransaction", externalExt).Return(validity, nil)
+
+		ctrl := gomock.NewController(t)
+		mockStorageState := NewMockStorageState(ctrl)
+		mockStorageState.EXPECT().TrieState(nil).Return(ts, nil)
+		mockBlockState := NewMockBlockState(ctrl)
+		mockBlockState.EXPECT().GetRuntime(nil).Return(runtimeMock, nil)
+		mockTxState := NewMockTransactionState(ctrl)
+		mockTxState.EXPECT().IsBanned(ext.Hash()).Return(false)
+		mockTxState.EXPECT().Exists(ext).Return(false)
+		mockTxState.EXPECT().AddToPool(transaction.NewValidTransaction(ext, validity)).
+			Return(ext.Hash(), transaction.ErrPoolFull)
+		s := &Service{
+			net:              NewMockNetwork(ctrl),
+			storageState:     mockStorageState,
+			blockState:       mockBlockState,
+			transactionState: mockTxState,
+		}
+
+		err = s.HandleSubmittedExtrinsic(ext)
+		assert.ErrorIs(t, err, transaction.ErrPoolFull)
+	})
+}
@@ -0,0 +1,190 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package light
+
+import (
+	"sync"
+
+	"github.com/ChainSafe/gossamer/dot/core"
+	"github.com/ChainSafe/gossamer/dot/rpc/modules"
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/trie"
+)
+
+var (
+	_ modules.StorageAPI = (*StorageAPI)(nil)
+	_ modules.CoreAPI    = (*CoreAPI)(nil)
+)
+
+// maxStateRoots is the maximum number of state roots the StorageAPI keeps track of
+const maxStateRoots = 1024
+
+// StorageAPI serves the storage queries of the RPC modules using the light client.
+// The RPC modules query storage by state root, so the StorageAPI keeps track of the
+// block of each state root it returned, forgetting the oldest ones first.
+type StorageAPI struct {
+	client *Client
+
+	sync.RWMutex
+	stateRoots     map[common.Hash]common.Hash
+	stateRootOrder []common.Hash
+}
+
+// NewStorageAPI returns a new StorageAPI
+func NewStorageAPI(client *Client) *StorageAPI {
+	return &StorageAPI{
+		client:     client,
+		stateRoots: make(map[common.Hash]common.Hash),
+	}
+}
+
+// GetStateRootFromBlock returns the state root of the given block
+func (s *StorageAPI) GetStateRootFromBlock(bhash *common.Hash) (*common.Hash, error) {
+	header, err := s.client.header(bhash)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if _, has := s.stateRoots[header.StateRoot]; !has {
+		if len(s.stateRootOrder) >= maxStateRoots {
+			delete(s.stateRoots, s.stateRootOrder[0])
+			s.stateRootOrder = s.stateRootOrder[1:]
+		}
+		s.stateRootOrder = append(s.stateRootOrder, header.StateRoot)
+	}
+	s.stateRoots[header.StateRoot] = header.Hash()
+
+	return &header.StateRoot, nil
+}
+
+// GetStorage returns the value of the given key in the state with the given root,
+// or in the state of the best block if the root is nil.
+func (s *StorageAPI) GetStorage(root *common.Hash, key []byte) ([]byte, error) {
+	bhash, err := s.blockHash(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.GetStorage(bhash, key)
+}
+
+// GetStorageByBlockHash returns the value of the given key in the state of the given block
+func (s *StorageAPI) GetStorageByBlockHash(bhash *common.Hash, key []byte) ([]byte, error) {
+	return s.client.GetStorage(bhash, key)
+}
+
+// GetStorageFromChild returns the value of the given key in the child trie located at the given key,
+// in the state with the given root, or in the state of the best block if the root is nil.
+func (s *StorageAPI) GetStorageFromChild(root *common.Hash, keyToChild, key []byte) ([]byte, error) {
+	bhash, err := s.blockHash(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.GetChildStorage(bhash, keyToChild, key)
+}
+
+// GetStorageChild is not supported, as it requires the entire child trie
+func (*StorageAPI) GetStorageChild(*common.Hash, []byte) (*trie.Trie, error) {
+	return nil, ErrNotSupported
+}
+
+// Entries is not supported, as it requires the entire state
+func (*StorageAPI) Entries(*common.Hash) (map[string][]byte, error) {
+	return nil, ErrNotSupported
+}
+
+// GetKeysWithPrefix is not supported, as the light client protocol cannot prove the keys of a prefix
+func (*StorageAPI) GetKeysWithPrefix(*common.Hash, []byte) ([][]byte, error) {
+	return nil, ErrNotSupported
+}
+
+// RegisterStorageObserver does nothing, as storage changes are not known by the light client
+func (*StorageAPI) RegisterStorageObserver(state.Observer) {}
+
+// UnregisterStorageObserver does nothing, as storage changes are not known by the light client
+func (*StorageAPI) UnregisterStorageObserver(state.Observer) {}
+
+// blockHash returns the hash of the block with the given state root,
+// or nil if the state root is nil, which refers to the best block.
+func (s *StorageAPI) blockHash(root *common.Hash) (*common.Hash, error) {
+	if root == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	bhash, ok := s.stateRoots[*root]
+	if !ok {
+		return nil, ErrUnknownStateRoot
+	}
+
+	return &bhash, nil
+}
+
+// CoreAPI serves the core methods of the RPC modules which depend on the state
+// using the light client, and the others using the core service.
+type CoreAPI struct {
+	modules.CoreAPI
+	client *Client
+}
+
+// NewCoreAPI returns a new CoreAPI
+func NewCoreAPI(coreAPI modules.CoreAPI, client *Client) *CoreAPI {
+	return &CoreAPI{
+		CoreAPI: coreAPI,
+		client:  client,
+	}
+}
+
+// Call executes the given runtime method against the state of the given block
+func (c *CoreAPI) Call(method string, data []byte, bhash *common.Hash) ([]byte, error) {
+	return c.client.Call(method, data, bhash)
+}
+
+// GetRuntimeVersion returns the runtime version of the given block
+func (c *CoreAPI) GetRuntimeVersion(bhash *common.Hash) (runtime.Version, error) {
+	return c.client.GetRuntimeVersion(bhash)
+}
+
+// GetMetadata returns the runtime metadata of the given block
+func (c *CoreAPI) GetMetadata(bhash *common.Hash) ([]byte, error) {
+	return c.client.GetMetadata(bhash)
+}
+
+// DecodeSessionKeys decodes the given session keys using the runtime of the best block
+func (c *CoreAPI) DecodeSessionKeys(enc []byte) ([]byte, error) {
+	return c.client.Call(runtime.DecodeSessionKeys, enc, nil)
+}
+
+// GenerateSessionKeys is not supported, as a light client does not take part in the consensus
+func (*CoreAPI) GenerateSessionKeys() ([]byte, error) {
+	return nil, ErrNotSupported
+}
+
+// GetReadProofAt returns the proof of the given keys in the state of the given block
+func (c *CoreAPI) GetReadProofAt(block common.Hash, keys [][]byte) (common.Hash, [][]byte, error) {
+	if block.IsEmpty() {
+		return c.client.GetReadProof(nil, keys)
+	}
+
+	return c.client.GetReadProof(&block, keys)
+}
+
+// HandleSubmittedExtrinsic is not supported, as extrinsics cannot be validated without the state
+func (*CoreAPI) HandleSubmittedExtrinsic(types.Extrinsic) error {
+	return ErrNotSupported
+}
+
+// QueryStorage is not supported, as it requires the state changes of every block in the range
+func (*CoreAPI) QueryStorage(common.Hash, common.Hash, ...string) (map[common.Hash]core.QueryKeyValueChanges, error) {
+	return nil, ErrNotSupported
+}
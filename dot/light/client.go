@@ -0,0 +1,475 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package light
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/internal/log"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+var logger = log.NewFromGlobal(log.AddContext("pkg", "light"))
+
+// maxRequestAttempts is the maximum number of peers a light client request is sent to
+const maxRequestAttempts = 3
+
+type instanceFunc func(code []byte, cfg *wasmer.Config) (runtime.Instance, error)
+
+// Config is the configuration of the light client
+type Config struct {
+	LogLvl     log.Level
+	BlockState BlockState
+	Network    Network
+}
+
+// Client answers storage queries and runtime calls on behalf of a node which only syncs headers.
+// Proofs are requested from full peers using the light client protocol, and verified against the
+// state roots of the synced headers.
+type Client struct {
+	blockState  BlockState
+	net         Network
+	logLvl      log.Level
+	newInstance instanceFunc
+
+	// the runtime instance is re-used for as long as the runtime code does not change
+	runtimeMu sync.Mutex
+	codeHash  common.Hash
+	instance  runtime.Instance
+}
+
+// NewClient returns a new light client
+func NewClient(cfg *Config) (*Client, error) {
+	if cfg.BlockState == nil {
+		return nil, ErrNilBlockState
+	}
+
+	if cfg.Network == nil {
+		return nil, ErrNilNetwork
+	}
+
+	logger.Patch(log.SetLevel(cfg.LogLvl))
+
+	return &Client{
+		blockState:  cfg.BlockState,
+		net:         cfg.Network,
+		logLvl:      cfg.LogLvl,
+		newInstance: newWasmerInstance,
+	}, nil
+}
+
+func newWasmerInstance(code []byte, cfg *wasmer.Config) (runtime.Instance, error) {
+	instance, err := wasmer.NewInstance(code, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+// Start does nothing, as the light client only sends requests when it is queried
+func (*Client) Start() error {
+	return nil
+}
+
+// Stop stops the runtime instance used to execute runtime calls
+func (c *Client) Stop() error {
+	c.runtimeMu.Lock()
+	defer c.runtimeMu.Unlock()
+
+	if c.instance != nil {
+		c.instance.Stop()
+		c.instance = nil
+	}
+
+	return nil
+}
+
+// GetStorage returns the value of the given key in the state of the given block,
+// or in the state of the best block if the block hash is nil.
+func (c *Client) GetStorage(bhash *common.Hash, key []byte) ([]byte, error) {
+	header, err := c.header(bhash)
+	if err != nil {
+		return nil, err
+	}
+
+	blockHash := header.Hash()
+	req := network.NewLightRequest()
+	req.RemoteReadRequest = &network.RemoteReadRequest{
+		Block: blockHash[:],
+		Keys:  [][]byte{key},
+	}
+
+	var value []byte
+	err = c.request(req, func(resp *network.LightResponse) error {
+		proofTrie, err := loadProofTrie(resp.RemoteReadResponse.Proof, header.StateRoot)
+		if err != nil {
+			return err
+		}
+
+		value, err = getFromProof(proofTrie, key)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read storage at block %s: %w", blockHash, err)
+	}
+
+	return value, nil
+}
+
+// GetChildStorage returns the value of the given key in the child trie located at the given key,
+// in the state of the given block, or in the state of the best block if the block hash is nil.
+func (c *Client) GetChildStorage(bhash *common.Hash, keyToChild, key []byte) ([]byte, error) {
+	header, err := c.header(bhash)
+	if err != nil {
+		return nil, err
+	}
+
+	blockHash := header.Hash()
+	req := network.NewLightRequest()
+	req.RemoteReadChildRequest = &network.RemoteReadChildRequest{
+		Block:      blockHash[:],
+		StorageKey: keyToChild,
+		Keys:       [][]byte{key},
+	}
+
+	var value []byte
+	err = c.request(req, func(resp *network.LightResponse) error {
+		proofTrie, err := loadProofTrie(resp.RemoteReadResponse.Proof, header.StateRoot)
+		if err != nil {
+			return err
+		}
+
+		childKey := append(append([]byte{}, trie.ChildStorageKeyPrefix...), keyToChild...)
+		childRoot, err := getFromProof(proofTrie, childKey)
+		if err != nil {
+			return err
+		}
+
+		if childRoot == nil {
+			// the child trie does not exist
+			value = nil
+			return nil
+		}
+
+		childTrie, err := loadProofTrie(resp.RemoteReadResponse.Proof, common.BytesToHash(childRoot))
+		if err != nil {
+			return err
+		}
+
+		value, err = getFromProof(childTrie, key)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot read child storage at block %s: %w", blockHash, err)
+	}
+
+	return value, nil
+}
+
+// GetReadProof returns the verified proof of the given keys in the state of the given block,
+// or in the state of the best block if the block hash is nil, along with the hash of the block.
+func (c *Client) GetReadProof(bhash *common.Hash, keys [][]byte) (common.Hash, [][]byte, error) {
+	header, err := c.header(bhash)
+	if err != nil {
+		return common.Hash{}, nil, err
+	}
+
+	blockHash := header.Hash()
+	req := network.NewLightRequest()
+	req.RemoteReadRequest = &network.RemoteReadRequest{
+		Block: blockHash[:],
+		Keys:  keys,
+	}
+
+	var proof [][]byte
+	err = c.request(req, func(resp *network.LightResponse) error {
+		nodes, err := decodeProof(resp.RemoteReadResponse.Proof)
+		if err != nil {
+			return err
+		}
+
+		proofTrie, err := trie.LoadFromProof(nodes, header.StateRoot[:])
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidProof, err)
+		}
+
+		for _, key := range keys {
+			if _, err = getFromProof(proofTrie, key); err != nil {
+				return err
+			}
+		}
+
+		proof = nodes
+		return nil
+	})
+	if err != nil {
+		return common.Hash{}, nil, fmt.Errorf("cannot get read proof at block %s: %w", blockHash, err)
+	}
+
+	return blockHash, proof, nil
+}
+
+// Call executes the given runtime method against the state of the given block,
+// or against the state of the best block if the block hash is nil.
+func (c *Client) Call(method string, data []byte, bhash *common.Hash) ([]byte, error) {
+	var ret []byte
+	err := c.execute(bhash, method, data, func(rt runtime.Instance) (err error) {
+		ret, err = rt.Exec(method, data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the returned slice points into the runtime memory, which may be reused by the next call
+	return append([]byte{}, ret...), nil
+}
+
+// GetRuntimeVersion returns the runtime version of the given block,
+// or the runtime version of the best block if the block hash is nil.
+func (c *Client) GetRuntimeVersion(bhash *common.Hash) (runtime.Version, error) {
+	var version runtime.Version
+	err := c.execute(bhash, runtime.CoreVersion, []byte{}, func(rt runtime.Instance) (err error) {
+		version, err = rt.Version()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// GetMetadata returns the runtime metadata of the given block,
+// or the runtime metadata of the best block if the block hash is nil.
+func (c *Client) GetMetadata(bhash *common.Hash) ([]byte, error) {
+	return c.Call(runtime.Metadata, []byte{}, bhash)
+}
+
+// execute requests the proof of the storage read by the given runtime call, and
+// executes the given function with a runtime instance whose storage is the proof trie.
+func (c *Client) execute(bhash *common.Hash, method string, data []byte, fn func(rt runtime.Instance) error) error {
+	header, err := c.header(bhash)
+	if err != nil {
+		return err
+	}
+
+	blockHash := header.Hash()
+	req := network.NewLightRequest()
+	req.RemoteCallRequest = &network.RemoteCallRequest{
+		Block:  blockHash[:],
+		Method: method,
+		Data:   data,
+	}
+
+	err = c.request(req, func(resp *network.LightResponse) error {
+		nodes, err := decodeProof(resp.RemoteCallResponse.Proof)
+		if err != nil {
+			return err
+		}
+
+		proofTrie, err := loadProofTrieFromNodes(nodes, header.StateRoot)
+		if err != nil {
+			return err
+		}
+
+		code, err := getFromProof(proofTrie, common.CodeKey)
+		if err != nil {
+			return err
+		}
+
+		if len(code) == 0 {
+			return fmt.Errorf("%w: runtime code missing from proof", ErrInvalidProof)
+		}
+
+		ts, err := newProofTrieState(proofTrie, nodes)
+		if err != nil {
+			return err
+		}
+
+		c.runtimeMu.Lock()
+		defer c.runtimeMu.Unlock()
+
+		rt, err := c.runtime(code, ts.TrieState)
+		if err != nil {
+			return err
+		}
+
+		rt.SetContextStorage(ts)
+		err = fn(rt)
+
+		// the result of the call cannot be trusted if it read storage the proof does not contain
+		if proofErr := ts.Err(); proofErr != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidProof, proofErr)
+		}
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("cannot execute %s at block %s: %w", method, blockHash, err)
+	}
+
+	return nil
+}
+
+// runtime returns the runtime instance for the given code. It must be called with the runtime lock held.
+func (c *Client) runtime(code []byte, ts *rtstorage.TrieState) (runtime.Instance, error) {
+	codeHash, err := common.Blake2bHash(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.instance != nil && c.codeHash == codeHash {
+		return c.instance, nil
+	}
+
+	cfg := &wasmer.Config{
+		Imports: wasmer.ImportsNodeRuntime,
+	}
+	cfg.Storage = ts
+	cfg.LogLvl = c.logLvl
+	cfg.Keystore = keystore.NewGlobalKeystore()
+	cfg.Role = types.LightClientRole
+	cfg.CodeHash = codeHash
+
+	instance, err := c.newInstance(code, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create runtime instance: %w", err)
+	}
+
+	if c.instance != nil {
+		c.instance.Stop()
+	}
+
+	logger.Debugf("created runtime instance with code hash %s", codeHash)
+	c.instance = instance
+	c.codeHash = codeHash
+	return instance, nil
+}
+
+func (c *Client) header(bhash *common.Hash) (*types.Header, error) {
+	var hash common.Hash
+	if bhash != nil {
+		hash = *bhash
+	} else {
+		hash = c.blockState.BestBlockHash()
+	}
+
+	header, err := c.blockState.GetHeader(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get header for block %s: %w", hash, err)
+	}
+
+	return header, nil
+}
+
+// request sends the request to full peers until one of them sends a response accepted by the
+// given handler. Peers sending a response with an invalid proof are reported.
+func (c *Client) request(req *network.LightRequest, handle func(resp *network.LightResponse) error) error {
+	peers := c.fullPeers()
+	if len(peers) == 0 {
+		return ErrNoPeers
+	}
+
+	if len(peers) > maxRequestAttempts {
+		peers = peers[:maxRequestAttempts]
+	}
+
+	var err error
+	for _, p := range peers {
+		var resp *network.LightResponse
+		resp, err = c.net.DoLightRequest(p, req)
+		if err != nil {
+			logger.Debugf("failed to send light request to peer %s: %s", p, err)
+			continue
+		}
+
+		err = handle(resp)
+		if !errors.Is(err, ErrInvalidProof) {
+			return err
+		}
+
+		logger.Debugf("received invalid proof from peer %s: %s", p, err)
+		c.net.ReportPeer(peerset.ReputationChange{
+			Value:  peerset.BadProofValue,
+			Reason: peerset.BadProofReason,
+		}, p)
+	}
+
+	return err
+}
+
+// fullPeers returns the connected peers which are not light clients
+func (c *Client) fullPeers() []peer.ID {
+	var peers []peer.ID
+	for _, info := range c.net.Peers() {
+		if info.Roles == types.LightClientRole {
+			continue
+		}
+
+		p, err := peer.Decode(info.PeerID)
+		if err != nil {
+			continue
+		}
+
+		peers = append(peers, p)
+	}
+
+	return peers
+}
+
+func decodeProof(encProof []byte) ([][]byte, error) {
+	var nodes [][]byte
+	err := scale.Unmarshal(encProof, &nodes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot decode proof: %s", ErrInvalidProof, err)
+	}
+
+	return nodes, nil
+}
+
+// loadProofTrie decodes the proof and verifies that it forms a trie with the given root
+func loadProofTrie(encProof []byte, root common.Hash) (*trie.Trie, error) {
+	nodes, err := decodeProof(encProof)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadProofTrieFromNodes(nodes, root)
+}
+
+// loadProofTrieFromNodes verifies that the nodes of a proof form a trie with the given root
+func loadProofTrieFromNodes(nodes [][]byte, root common.Hash) (*trie.Trie, error) {
+	proofTrie, err := trie.LoadFromProof(nodes, root[:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidProof, err)
+	}
+
+	return proofTrie, nil
+}
+
+// getFromProof returns the value of the key in the proof trie, or nil if the proof shows that the
+// key is absent. ErrInvalidProof is returned if the proof does not contain the nodes of the key.
+func getFromProof(proofTrie *trie.Trie, key []byte) ([]byte, error) {
+	value, err := proofTrie.GetFromProof(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidProof, err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,343 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package light
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	mocksruntime "github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	"github.com/golang/mock/gomock"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPeerA = "QmYyQSo1c1Ym7orWxLYvCrM2EmxFTANf8wXmmE7DWjhx5N"
+	testPeerB = "QmZ8Tb3vSqyvWqNAZzRLGZQq3Nf1Wn4PaXu7VVKfdKKPHX"
+)
+
+// newTestProof returns the state root of a trie containing the given entries,
+// and the SCALE encoded proof of the given keys.
+func newTestProof(t *testing.T, entries map[string][]byte, keys [][]byte) (common.Hash, []byte) {
+	t.Helper()
+
+	db, err := chaindb.NewBadgerDB(&chaindb.Config{
+		InMemory: true,
+		DataDir:  t.TempDir(),
+	})
+	require.NoError(t, err)
+
+	tr := trie.NewEmptyTrie()
+	for k, v := range entries {
+		tr.Put([]byte(k), v)
+	}
+
+	err = tr.Store(db)
+	require.NoError(t, err)
+
+	root, err := tr.Hash()
+	require.NoError(t, err)
+
+	proof, err := trie.GenerateProof(root[:], keys, db)
+	require.NoError(t, err)
+
+	encProof, err := scale.Marshal(proof)
+	require.NoError(t, err)
+
+	return root, encProof
+}
+
+func mustDecodePeer(t *testing.T, id string) peer.ID {
+	t.Helper()
+	p, err := peer.Decode(id)
+	require.NoError(t, err)
+	return p
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	_, err := NewClient(&Config{Network: NewMockNetwork(ctrl)})
+	assert.ErrorIs(t, err, ErrNilBlockState)
+
+	_, err = NewClient(&Config{BlockState: NewMockBlockState(ctrl)})
+	assert.ErrorIs(t, err, ErrNilNetwork)
+}
+
+func TestClient_GetStorage(t *testing.T) {
+	t.Parallel()
+
+	value := make([]byte, 32)
+	value[0] = 'b'
+	entries := map[string][]byte{
+		"alpha": make([]byte, 32),
+		"bravo": value,
+	}
+	stateRoot, encProof := newTestProof(t, entries, [][]byte{[]byte("bravo")})
+	header := &types.Header{Number: 1, StateRoot: stateRoot}
+	blockHash := header.Hash()
+
+	_, invalidProof := newTestProof(t, map[string][]byte{"bravo": make([]byte, 33)}, [][]byte{[]byte("bravo")})
+
+	peerA := mustDecodePeer(t, testPeerA)
+	peerB := mustDecodePeer(t, testPeerB)
+
+	expectedRequest := network.NewLightRequest()
+	expectedRequest.RemoteReadRequest = &network.RemoteReadRequest{
+		Block: blockHash[:],
+		Keys:  [][]byte{[]byte("bravo")},
+	}
+
+	invalidResponse := network.NewLightResponse()
+	invalidResponse.RemoteReadResponse.Proof = invalidProof
+	validResponse := network.NewLightResponse()
+	validResponse.RemoteReadResponse.Proof = encProof
+
+	ctrl := gomock.NewController(t)
+	blockState := NewMockBlockState(ctrl)
+	blockState.EXPECT().BestBlockHash().Return(blockHash)
+	blockState.EXPECT().GetHeader(blockHash).Return(header, nil)
+
+	net := NewMockNetwork(ctrl)
+	net.EXPECT().Peers().Return([]common.PeerInfo{
+		{PeerID: testPeerA, Roles: types.FullNodeRole},
+		{PeerID: "QmLightPeer", Roles: types.LightClientRole},
+		{PeerID: testPeerB, Roles: types.AuthorityRole},
+	})
+	net.EXPECT().DoLightRequest(peerA, expectedRequest).Return(invalidResponse, nil)
+	net.EXPECT().ReportPeer(peerset.ReputationChange{
+		Value:  peerset.BadProofValue,
+		Reason: peerset.BadProofReason,
+	}, peerA)
+	net.EXPECT().DoLightRequest(peerB, expectedRequest).Return(validResponse, nil)
+
+	client, err := NewClient(&Config{BlockState: blockState, Network: net})
+	require.NoError(t, err)
+
+	storedValue, err := client.GetStorage(nil, []byte("bravo"))
+	require.NoError(t, err)
+	assert.Equal(t, value, storedValue)
+}
+
+func TestClient_GetStorage_IncompleteProof(t *testing.T) {
+	t.Parallel()
+
+	entries := map[string][]byte{
+		"alpha": make([]byte, 32),
+		"bravo": make([]byte, 32),
+	}
+	// the proof of alpha only contains the hash of the node of bravo,
+	// which must not be mistaken for the proof that bravo is absent
+	stateRoot, encProof := newTestProof(t, entries, [][]byte{[]byte("alpha")})
+	header := &types.Header{Number: 1, StateRoot: stateRoot}
+	blockHash := header.Hash()
+
+	peerA := mustDecodePeer(t, testPeerA)
+	response := network.NewLightResponse()
+	response.RemoteReadResponse.Proof = encProof
+
+	ctrl := gomock.NewController(t)
+	blockState := NewMockBlockState(ctrl)
+	blockState.EXPECT().GetHeader(blockHash).Return(header, nil)
+	net := NewMockNetwork(ctrl)
+	net.EXPECT().Peers().Return([]common.PeerInfo{{PeerID: testPeerA}})
+	net.EXPECT().DoLightRequest(peerA, gomock.Any()).Return(response, nil)
+	net.EXPECT().ReportPeer(peerset.ReputationChange{
+		Value:  peerset.BadProofValue,
+		Reason: peerset.BadProofReason,
+	}, peerA)
+
+	client, err := NewClient(&Config{BlockState: blockState, Network: net})
+	require.NoError(t, err)
+
+	_, err = client.GetStorage(&blockHash, []byte("bravo"))
+	assert.ErrorIs(t, err, ErrInvalidProof)
+}
+
+func TestClient_GetStorage_NoPeers(t *testing.T) {
+	t.Parallel()
+
+	header := &types.Header{Number: 1}
+	blockHash := header.Hash()
+
+	ctrl := gomock.NewController(t)
+	blockState := NewMockBlockState(ctrl)
+	blockState.EXPECT().GetHeader(blockHash).Return(header, nil)
+	net := NewMockNetwork(ctrl)
+	net.EXPECT().Peers().Return(nil)
+
+	client, err := NewClient(&Config{BlockState: blockState, Network: net})
+	require.NoError(t, err)
+
+	_, err = client.GetStorage(&blockHash, []byte("bravo"))
+	assert.ErrorIs(t, err, ErrNoPeers)
+}
+
+func TestClient_Call(t *testing.T) {
+	t.Parallel()
+
+	code := make([]byte, 64)
+	copy(code, "runtime code")
+	entries := map[string][]byte{
+		string(common.CodeKey): code,
+		"nonce":                {7},
+	}
+	stateRoot, encProof := newTestProof(t, entries, [][]byte{common.CodeKey, []byte("nonce")})
+	header := &types.Header{Number: 1, StateRoot: stateRoot}
+	blockHash := header.Hash()
+
+	peerA := mustDecodePeer(t, testPeerA)
+	expectedRequest := network.NewLightRequest()
+	expectedRequest.RemoteCallRequest = &network.RemoteCallRequest{
+		Block:  blockHash[:],
+		Method: "AccountNonceApi_account_nonce",
+		Data:   []byte{1},
+	}
+	response := network.NewLightResponse()
+	response.RemoteCallResponse.Proof = encProof
+
+	ctrl := gomock.NewController(t)
+	blockState := NewMockBlockState(ctrl)
+	blockState.EXPECT().GetHeader(blockHash).Return(header, nil).Times(2)
+	net := NewMockNetwork(ctrl)
+	net.EXPECT().Peers().Return([]common.PeerInfo{{PeerID: testPeerA}}).Times(2)
+	net.EXPECT().DoLightRequest(peerA, expectedRequest).Return(response, nil).Times(2)
+
+	var contextStorage runtime.Storage
+	instance := new(mocksruntime.Instance)
+	instance.On("SetContextStorage", mock.Anything).Run(func(args mock.Arguments) {
+		contextStorage = args.Get(0).(runtime.Storage)
+	})
+	// the result of a call points into the memory of the instance
+	memory := make([]byte, 1)
+	instance.On("Exec", "AccountNonceApi_account_nonce", []byte{1}).Return(func(string, []byte) []byte {
+		copy(memory, contextStorage.Get([]byte("nonce")))
+		return memory
+	}, nil)
+
+	client, err := NewClient(&Config{BlockState: blockState, Network: net})
+	require.NoError(t, err)
+
+	instances := 0
+	client.newInstance = func(c []byte, _ *wasmer.Config) (runtime.Instance, error) {
+		assert.Equal(t, code, c)
+		instances++
+		return instance, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		ret, err := client.Call("AccountNonceApi_account_nonce", []byte{1}, &blockHash)
+		require.NoError(t, err)
+
+		// the memory is overwritten by the next call
+		memory[0] = 0
+		assert.Equal(t, []byte{7}, ret)
+	}
+
+	// the runtime instance is re-used as long as the code does not change
+	assert.Equal(t, 1, instances)
+}
+
+func TestClient_Call_IncompleteProof(t *testing.T) {
+	t.Parallel()
+
+	code := make([]byte, 64)
+	copy(code, "runtime code")
+	entries := map[string][]byte{
+		string(common.CodeKey): code,
+		"nonce":                make([]byte, 32),
+	}
+	stateRoot, encProof := newTestProof(t, entries, [][]byte{common.CodeKey})
+	header := &types.Header{Number: 1, StateRoot: stateRoot}
+	blockHash := header.Hash()
+
+	peerA := mustDecodePeer(t, testPeerA)
+	response := network.NewLightResponse()
+	response.RemoteCallResponse.Proof = encProof
+
+	ctrl := gomock.NewController(t)
+	blockState := NewMockBlockState(ctrl)
+	blockState.EXPECT().GetHeader(blockHash).Return(header, nil)
+	net := NewMockNetwork(ctrl)
+	net.EXPECT().Peers().Return([]common.PeerInfo{{PeerID: testPeerA}})
+	net.EXPECT().DoLightRequest(peerA, gomock.Any()).Return(response, nil)
+	net.EXPECT().ReportPeer(peerset.ReputationChange{
+		Value:  peerset.BadProofValue,
+		Reason: peerset.BadProofReason,
+	}, peerA)
+
+	var contextStorage runtime.Storage
+	instance := new(mocksruntime.Instance)
+	instance.On("SetContextStorage", mock.Anything).Run(func(args mock.Arguments) {
+		contextStorage = args.Get(0).(runtime.Storage)
+	})
+	instance.On("Exec", "AccountNonceApi_account_nonce", []byte{1}).Return(func(string, []byte) []byte {
+		return contextStorage.Get([]byte("nonce"))
+	}, nil)
+
+	client, err := NewClient(&Config{BlockState: blockState, Network: net})
+	require.NoError(t, err)
+	client.newInstance = func([]byte, *wasmer.Config) (runtime.Instance, error) {
+		return instance, nil
+	}
+
+	_, err = client.Call("AccountNonceApi_account_nonce", []byte{1}, &blockHash)
+	assert.ErrorIs(t, err, ErrInvalidProof)
+}
+
+func TestStorageAPI_GetStateRootFromBlock(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	blockState := NewMockBlockState(ctrl)
+	client, err := NewClient(&Config{BlockState: blockState, Network: NewMockNetwork(ctrl)})
+	require.NoError(t, err)
+	api := NewStorageAPI(client)
+
+	headers := make([]*types.Header, maxStateRoots+1)
+	for i := range headers {
+		headers[i] = &types.Header{Number: uint(i), StateRoot: common.Hash{byte(i), byte(i >> 8)}}
+		hash := headers[i].Hash()
+		blockState.EXPECT().GetHeader(hash).Return(headers[i], nil)
+
+		root, err := api.GetStateRootFromBlock(&hash)
+		require.NoError(t, err)
+		require.Equal(t, headers[i].StateRoot, *root)
+	}
+
+	// only the oldest state root is forgotten
+	_, err = api.blockHash(&headers[0].StateRoot)
+	assert.ErrorIs(t, err, ErrUnknownStateRoot)
+
+	for _, header := range headers[1:] {
+		bhash, err := api.blockHash(&header.StateRoot)
+		require.NoError(t, err)
+		assert.Equal(t, header.Hash(), *bhash)
+	}
+}
+
+func TestStorageAPI_GetStorage_UnknownStateRoot(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client, err := NewClient(&Config{BlockState: NewMockBlockState(ctrl), Network: NewMockNetwork(ctrl)})
+	require.NoError(t, err)
+
+	_, err = NewStorageAPI(client).GetStorage(&common.Hash{1}, []byte("key"))
+	assert.ErrorIs(t, err, ErrUnknownStateRoot)
+}
@@ -0,0 +1,28 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package light
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNilBlockState is returned when BlockState is nil
+	ErrNilBlockState = errors.New("cannot have nil BlockState")
+
+	// ErrNilNetwork is returned when Network is nil
+	ErrNilNetwork = errors.New("cannot have nil Network")
+
+	// ErrNoPeers is returned when there is no full peer to send a light client request to
+	ErrNoPeers = errors.New("no full peer to send request to")
+
+	// ErrInvalidProof is returned when the proof of a light client response cannot be verified
+	ErrInvalidProof = errors.New("invalid proof")
+
+	// ErrUnknownStateRoot is returned when the block of a state root is not known by the light client
+	ErrUnknownStateRoot = errors.New("unknown state root")
+
+	// ErrNotSupported is returned for queries which cannot be answered in light client mode
+	ErrNotSupported = errors.New("not supported in light client mode")
+)
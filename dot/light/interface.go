@@ -0,0 +1,28 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package light
+
+import (
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+//go:generate mockgen -destination=mock_light_test.go -package $GOPACKAGE . BlockState,Network
+
+// BlockState is the interface for the block state
+type BlockState interface {
+	BestBlockHash() common.Hash
+	GetHeader(hash common.Hash) (*types.Header, error)
+}
+
+// Network is the interface for the network service
+type Network interface {
+	Peers() []common.PeerInfo
+	DoLightRequest(to peer.ID, req *network.LightRequest) (*network.LightResponse, error)
+	ReportPeer(change peerset.ReputationChange, p peer.ID)
+}
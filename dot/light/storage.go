@@ -0,0 +1,142 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package light
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+)
+
+// proofTrieState is the storage of the runtime calls executed against a proof trie.
+// Reads of keys whose nodes are missing from the proof cannot be told apart from reads of
+// absent keys by the runtime, so the first of them is recorded to reject the result of the call.
+type proofTrieState struct {
+	*rtstorage.TrieState
+
+	// nodes of the proof, which also contain the nodes of the child tries read
+	nodes [][]byte
+
+	mu         sync.Mutex
+	err        error
+	childTries map[common.Hash]*trie.Trie
+}
+
+func newProofTrieState(proofTrie *trie.Trie, nodes [][]byte) (*proofTrieState, error) {
+	ts, err := rtstorage.NewTrieState(proofTrie)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proofTrieState{
+		TrieState:  ts,
+		nodes:      nodes,
+		childTries: make(map[common.Hash]*trie.Trie),
+	}, nil
+}
+
+// Get returns the value of the key in the proof trie
+func (s *proofTrieState) Get(key []byte) []byte {
+	value, err := s.Trie().GetFromProof(key)
+	s.setErr(err)
+	return value
+}
+
+// Has returns whether the key exists in the proof trie
+func (s *proofTrieState) Has(key []byte) bool {
+	return s.Get(key) != nil
+}
+
+// NextKey returns the key following the given key in the proof trie
+func (s *proofTrieState) NextKey(key []byte) []byte {
+	next, err := s.Trie().NextKeyFromProof(key)
+	s.setErr(err)
+	return next
+}
+
+// GetChildStorage returns the value of the key in the child trie located at the given key in the proof trie
+func (s *proofTrieState) GetChildStorage(keyToChild, key []byte) ([]byte, error) {
+	child, err := s.childTrie(keyToChild)
+	if err != nil || child == nil {
+		return nil, err
+	}
+
+	value, err := child.GetFromProof(key)
+	s.setErr(err)
+	return value, err
+}
+
+// GetChildNextKey returns the key following the given key in the child trie located at the given key
+// in the proof trie
+func (s *proofTrieState) GetChildNextKey(keyToChild, key []byte) ([]byte, error) {
+	child, err := s.childTrie(keyToChild)
+	if err != nil || child == nil {
+		return nil, err
+	}
+
+	next, err := child.NextKeyFromProof(key)
+	s.setErr(err)
+	return next, err
+}
+
+// childTrie returns the child trie located at the given key, loaded from the nodes of the proof,
+// or nil if the proof shows that there is no child trie at this key
+func (s *proofTrieState) childTrie(keyToChild []byte) (*trie.Trie, error) {
+	root, err := s.Trie().GetFromProof(append(append([]byte{}, trie.ChildStorageKeyPrefix...), keyToChild...))
+	if err != nil {
+		s.setErr(err)
+		return nil, err
+	}
+
+	if root == nil {
+		return nil, nil
+	}
+
+	if len(root) != common.HashLength {
+		return nil, fmt.Errorf("%w at key 0x%x", trie.ErrChildTrieDoesNotExist, keyToChild)
+	}
+
+	rootHash := common.BytesToHash(root)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if child, has := s.childTries[rootHash]; has {
+		return child, nil
+	}
+
+	child, err := trie.LoadFromProof(s.nodes, rootHash[:])
+	if err != nil {
+		err = fmt.Errorf("%w: child trie with root %s: %s", trie.ErrIncompleteProof, rootHash, err)
+		if s.err == nil {
+			s.err = err
+		}
+		return nil, err
+	}
+
+	s.childTries[rootHash] = child
+	return child, nil
+}
+
+// Err returns the error of the first read which the proof does not contain the nodes of
+func (s *proofTrieState) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *proofTrieState) setErr(err error) {
+	if err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package light
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/trie"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_proofTrieState_childStorage(t *testing.T) {
+	t.Parallel()
+
+	keyToChild := []byte(":default:child")
+	value := func(b byte) []byte {
+		v := make([]byte, 40)
+		v[0] = b
+		return v
+	}
+
+	child := trie.NewEmptyTrie()
+	child.Put([]byte("a"), value(1))
+	child.Put([]byte("b"), value(2))
+	child.Put([]byte("c"), value(3))
+
+	main := trie.NewEmptyTrie()
+	main.Put([]byte("key"), value(4))
+	err := main.PutChild(keyToChild, child)
+	require.NoError(t, err)
+
+	root, err := main.Hash()
+	require.NoError(t, err)
+	_, err = child.Hash()
+	require.NoError(t, err)
+
+	childKey := append(append([]byte{}, trie.ChildStorageKeyPrefix...), keyToChild...)
+	mainProof, err := main.GenerateProof([][]byte{childKey})
+	require.NoError(t, err)
+	childProof, err := child.GenerateProof([][]byte{[]byte("a")})
+	require.NoError(t, err)
+
+	newState := func(nodes [][]byte) *proofTrieState {
+		proofTrie, err := trie.LoadFromProof(nodes, root[:])
+		require.NoError(t, err)
+
+		ts, err := newProofTrieState(proofTrie, nodes)
+		require.NoError(t, err)
+		return ts
+	}
+
+	t.Run("key in proof", func(t *testing.T) {
+		t.Parallel()
+		ts := newState(append(append([][]byte{}, mainProof...), childProof...))
+
+		v, err := ts.GetChildStorage(keyToChild, []byte("a"))
+		require.NoError(t, err)
+		assert.Equal(t, value(1), v)
+
+		v, err = ts.GetChildStorage([]byte(":default:other"), []byte("a"))
+		require.NoError(t, err)
+		assert.Nil(t, v)
+		assert.NoError(t, ts.Err())
+	})
+
+	t.Run("key missing from proof", func(t *testing.T) {
+		t.Parallel()
+		ts := newState(append(append([][]byte{}, mainProof...), childProof...))
+
+		_, err := ts.GetChildStorage(keyToChild, []byte("c"))
+		assert.ErrorIs(t, err, trie.ErrIncompleteProof)
+		assert.ErrorIs(t, ts.Err(), trie.ErrIncompleteProof)
+	})
+
+	t.Run("next key missing from proof", func(t *testing.T) {
+		t.Parallel()
+		ts := newState(append(append([][]byte{}, mainProof...), childProof...))
+
+		_, err := ts.GetChildNextKey(keyToChild, []byte("a"))
+		assert.ErrorIs(t, err, trie.ErrIncompleteProof)
+		assert.ErrorIs(t, ts.Err(), trie.ErrIncompleteProof)
+	})
+
+	t.Run("child trie missing from proof", func(t *testing.T) {
+		t.Parallel()
+		ts := newState(mainProof)
+
+		_, err := ts.GetChildStorage(keyToChild, []byte("a"))
+		assert.ErrorIs(t, err, trie.ErrIncompleteProof)
+		assert.ErrorIs(t, ts.Err(), trie.ErrIncompleteProof)
+	})
+}
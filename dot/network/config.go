@@ -90,6 +90,12 @@ type Config struct {
 
 	// PersistentPeers is a list of multiaddrs which the node should remain connected to
 	PersistentPeers []string
+	// ReservedOnly only allows connections with the persistent peers, which are the reserved peers
+	ReservedOnly bool
+	// TransactionsPeerSet is the configuration of the peer set of the transactions protocol
+	TransactionsPeerSet PeerSetConfig
+	// GrandpaPeerSet is the configuration of the peer set of the GRANDPA protocol
+	GrandpaPeerSet PeerSetConfig
 
 	// privateKey the private key for the network p2p identity
 	privateKey crypto.PrivKey
@@ -27,9 +27,27 @@ type ConnManager struct {
 	// when we reach the maximum numbers of peers.
 	protectedPeers *sync.Map // map[peer.ID]struct{}
 
-	// persistentPeers contains peers we should remain connected to.
+	// persistentPeers contains peers we should remain connected to, which are the reserved peers
+	// of every peer set.
 	persistentPeers *sync.Map // map[peer.ID]struct{}
 
+	// reservedPeers contains the reserved peers of each peer set.
+	reservedPeers []map[peer.ID]struct{}
+
+	// setPeers contains the peers connected in each peer set.
+	setPeers []map[peer.ID]struct{}
+
+	// pendingIncoming contains, for each peer which connected to us, the peer sets which haven't
+	// accepted or rejected it yet.
+	pendingIncoming map[peer.ID]map[int]struct{}
+
+	// reservedOnly is true if we only remain connected to the persistent peers.
+	reservedOnly bool
+
+	// setsReservedOnly contains whether each peer set is configured to only allow its reserved peers,
+	// regardless of reservedOnly.
+	setsReservedOnly []bool
+
 	peerSetHandler PeerSetHandler
 }
 
@@ -39,12 +57,23 @@ func newConnManager(min, max int, peerSetCfg *peerset.ConfigSet) (*ConnManager,
 		return nil, err
 	}
 
+	reservedPeers := make([]map[peer.ID]struct{}, len(peerSetCfg.Set))
+	setPeers := make([]map[peer.ID]struct{}, len(peerSetCfg.Set))
+	for i := range peerSetCfg.Set {
+		reservedPeers[i] = make(map[peer.ID]struct{})
+		setPeers[i] = make(map[peer.ID]struct{})
+	}
+
 	return &ConnManager{
-		min:             min,
-		max:             max,
-		protectedPeers:  new(sync.Map),
-		persistentPeers: new(sync.Map),
-		peerSetHandler:  psh,
+		min:              min,
+		max:              max,
+		protectedPeers:   new(sync.Map),
+		persistentPeers:  new(sync.Map),
+		reservedPeers:    reservedPeers,
+		setPeers:         setPeers,
+		pendingIncoming:  make(map[peer.ID]map[int]struct{}),
+		setsReservedOnly: make([]bool, len(peerSetCfg.Set)),
+		peerSetHandler:   psh,
 	}, nil
 }
 
@@ -129,6 +158,15 @@ func (cm *ConnManager) Connected(n network.Network, c network.Conn) {
 	logger.Tracef(
 		"Host %s connected to peer %s", n.LocalPeer(), c.RemotePeer())
 
+	if cm.isReservedOnly() && !cm.isPersistent(c.RemotePeer()) {
+		logger.Debugf("closing connection with peer %s, since it is not reserved", c.RemotePeer())
+		err := c.Close()
+		if err != nil {
+			logger.Warnf("failed to close connection with peer %s: %s", c.RemotePeer(), err)
+		}
+		return
+	}
+
 	if cm.connectHandler != nil {
 		cm.connectHandler(c.RemotePeer())
 	}
@@ -139,6 +177,14 @@ func (cm *ConnManager) Disconnected(_ network.Network, c network.Conn) {
 	logger.Tracef("Host %s disconnected from peer %s", c.LocalPeer(), c.RemotePeer())
 
 	cm.Unprotect(c.RemotePeer(), "")
+
+	cm.Lock()
+	delete(cm.pendingIncoming, c.RemotePeer())
+	for _, peers := range cm.setPeers {
+		delete(peers, c.RemotePeer())
+	}
+	cm.Unlock()
+
 	if cm.disconnectHandler != nil {
 		cm.disconnectHandler(c.RemotePeer())
 	}
@@ -160,3 +206,164 @@ func (cm *ConnManager) isPersistent(p peer.ID) bool {
 	_, ok := cm.persistentPeers.Load(p)
 	return ok
 }
+
+// addReservedPeers adds the peers to the persistent peers and to the reserved peers of the peer set.
+func (cm *ConnManager) addReservedPeers(setID int, peers ...peer.ID) {
+	cm.Lock()
+	for _, p := range peers {
+		cm.reservedPeers[setID][p] = struct{}{}
+		cm.persistentPeers.Store(p, struct{}{})
+	}
+	cm.Unlock()
+
+	cm.peerSetHandler.AddReservedPeer(setID, peers...)
+}
+
+// removeReservedPeers removes the peers from the reserved peers of the peer set, and from the
+// persistent peers if they aren't reserved in another peer set.
+func (cm *ConnManager) removeReservedPeers(setID int, peers ...peer.ID) {
+	cm.Lock()
+	for _, p := range peers {
+		delete(cm.reservedPeers[setID], p)
+		cm.deletePersistentPeer(p)
+	}
+	cm.Unlock()
+
+	cm.peerSetHandler.RemoveReservedPeer(setID, peers...)
+}
+
+// setReservedPeers replaces the reserved peers of the peer set with the peers, and updates the
+// persistent peers accordingly.
+func (cm *ConnManager) setReservedPeers(setID int, peers ...peer.ID) {
+	cm.Lock()
+	previous := cm.reservedPeers[setID]
+	cm.reservedPeers[setID] = make(map[peer.ID]struct{}, len(peers))
+	for _, p := range peers {
+		cm.reservedPeers[setID][p] = struct{}{}
+		cm.persistentPeers.Store(p, struct{}{})
+	}
+
+	for p := range previous {
+		cm.deletePersistentPeer(p)
+	}
+	cm.Unlock()
+
+	cm.peerSetHandler.SetReservedPeer(setID, peers...)
+}
+
+// deletePersistentPeer removes the peer from the persistent peers if it isn't reserved in any
+// peer set. The lock must be held.
+func (cm *ConnManager) deletePersistentPeer(p peer.ID) {
+	for _, reserved := range cm.reservedPeers {
+		if _, ok := reserved[p]; ok {
+			return
+		}
+	}
+
+	cm.persistentPeers.Delete(p)
+}
+
+// setReservedOnly sets whether we only remain connected to the reserved peers, in every peer set.
+// The peer sets configured to only allow their reserved peers keep doing so.
+func (cm *ConnManager) setReservedOnly(reservedOnly bool) {
+	cm.Lock()
+	cm.reservedOnly = reservedOnly
+	cm.Unlock()
+
+	for setID, setReservedOnly := range cm.setsReservedOnly {
+		cm.peerSetHandler.SetReservedOnly(setID, reservedOnly || setReservedOnly)
+	}
+}
+
+func (cm *ConnManager) isReservedOnly() bool {
+	cm.Lock()
+	defer cm.Unlock()
+	return cm.reservedOnly
+}
+
+// incoming records that the peer connected to us, and that it waits for an answer of the peer
+// sets it isn't connected in yet.
+func (cm *ConnManager) incoming(p peer.ID) {
+	cm.Lock()
+	defer cm.Unlock()
+
+	pending := make(map[int]struct{})
+	for setID, peers := range cm.setPeers {
+		if _, ok := peers[p]; !ok {
+			pending[setID] = struct{}{}
+		}
+	}
+
+	if len(pending) > 0 {
+		cm.pendingIncoming[p] = pending
+	}
+}
+
+// peerSetConnected records that the peer is connected in the peer set.
+func (cm *ConnManager) peerSetConnected(setID int, p peer.ID) {
+	cm.Lock()
+	defer cm.Unlock()
+
+	cm.setPeers[setID][p] = struct{}{}
+	cm.answerIncoming(setID, p)
+}
+
+// peerSetDisconnected records that the peer is no longer connected in the peer set. It returns
+// true if the connection with the peer should be closed, since it isn't connected in any peer set,
+// nor waits for the answer of one.
+func (cm *ConnManager) peerSetDisconnected(setID int, p peer.ID) bool {
+	cm.Lock()
+	defer cm.Unlock()
+
+	delete(cm.setPeers[setID], p)
+	cm.answerIncoming(setID, p)
+
+	if _, ok := cm.pendingIncoming[p]; ok {
+		return false
+	}
+
+	for _, peers := range cm.setPeers {
+		if _, ok := peers[p]; ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// answerIncoming records that the peer set answered the incoming connection of the peer.
+// The lock must be held.
+func (cm *ConnManager) answerIncoming(setID int, p peer.ID) {
+	pending, ok := cm.pendingIncoming[p]
+	if !ok {
+		return
+	}
+
+	delete(pending, setID)
+	if len(pending) == 0 {
+		delete(cm.pendingIncoming, p)
+	}
+}
+
+// inPeerSet returns whether we exchange the notifications of the peer set with the peer, which is
+// the case if the peer is connected in the peer set, or if it isn't managed by any peer set.
+func (cm *ConnManager) inPeerSet(setID int, p peer.ID) bool {
+	cm.Lock()
+	defer cm.Unlock()
+
+	if setID >= len(cm.setPeers) {
+		return true
+	}
+
+	if _, ok := cm.setPeers[setID][p]; ok {
+		return true
+	}
+
+	for _, peers := range cm.setPeers {
+		if _, ok := peers[p]; ok {
+			return false
+		}
+	}
+
+	return true
+}
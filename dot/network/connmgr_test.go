@@ -7,6 +7,7 @@
 	"testing"
 	"time"
 
+	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/peerstore"
 	"github.com/stretchr/testify/require"
@@ -197,7 +198,10 @@ func TestRemovePeer(t *testing.T) {
 	// nodeB will be connected to nodeA through bootnodes.
 	require.Equal(t, 1, nodeB.host.peerCount())
 
-	nodeB.host.cm.peerSetHandler.RemovePeer(0, nodeA.host.id())
+	// the connection is closed once nodeA is removed from every peer set.
+	for setID := 0; setID < numPeerSets; setID++ {
+		nodeB.host.cm.peerSetHandler.RemovePeer(setID, nodeA.host.id())
+	}
 	time.Sleep(time.Millisecond * 200)
 
 	require.Equal(t, 0, nodeB.host.peerCount())
@@ -231,6 +235,7 @@ func TestSetReservedPeer(t *testing.T) {
 		Port:            availablePort(t),
 		NoMDNS:          true,
 		PersistentPeers: []string{addrA.String(), addrB.String()},
+		ReservedOnly:    true,
 	}
 
 	node3 := createTestService(t, config)
@@ -240,10 +245,169 @@ func TestSetReservedPeer(t *testing.T) {
 	require.Equal(t, 2, node3.host.peerCount())
 
 	node3.host.h.Peerstore().AddAddrs(addrC.ID, addrC.Addrs, peerstore.PermanentAddrTTL)
-	node3.host.cm.peerSetHandler.SetReservedPeer(0, addrC.ID)
+	node3.host.cm.setReservedPeers(blockAnnounceSetID, addrC.ID)
+	time.Sleep(200 * time.Millisecond)
+
+	// in reserved-only mode, nodeA and nodeB are disconnected since they are no longer reserved
+	require.Equal(t, 1, node3.host.peerCount())
+	require.NotEmpty(t, node3.host.h.Network().ConnsToPeer(addrC.ID))
+}
+
+func TestSetReservedOnly(t *testing.T) {
+	if testing.Short() {
+		t.Skip() // this sometimes fails on CI
+	}
+
+	t.Parallel()
+
+	nodes := make([]*Service, 2)
+	for i := range nodes {
+		config := &Config{
+			BasePath:    t.TempDir(),
+			Port:        availablePort(t),
+			NoBootstrap: true,
+			NoMDNS:      true,
+		}
+		node := createTestService(t, config)
+		nodes[i] = node
+	}
+
+	addrA := nodes[0].host.multiaddrs()[0]
+	addrB := nodes[1].host.multiaddrs()[0]
+
+	config := &Config{
+		BasePath:        t.TempDir(),
+		Port:            availablePort(t),
+		NoMDNS:          true,
+		Bootnodes:       []string{addrB.String()},
+		PersistentPeers: []string{addrA.String()},
+	}
+
+	node3 := createTestService(t, config)
+	node3.noGossip = true
+	time.Sleep(time.Millisecond * 600)
+
+	require.Equal(t, 2, node3.host.peerCount())
+
+	// nodeB is not reserved, so it is disconnected
+	node3.SetReservedOnly(true)
 	time.Sleep(200 * time.Millisecond)
 
-	// reservedOnly mode is not yet implemented, so nodeA and nodeB won't be disconnected (#1888).
-	// TODO: once reservedOnly mode is implemented and reservedOnly is set to true, change expected value to 1 (nodeC)
-	require.Equal(t, 3, node3.host.peerCount())
+	require.Equal(t, 1, node3.host.peerCount())
+	require.NotEmpty(t, node3.host.h.Network().ConnsToPeer(nodes[0].host.id()))
+
+	// nodeB can't connect to node3 either
+	err := nodes[1].host.connect(node3.host.addrInfo())
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	require.Equal(t, 1, node3.host.peerCount())
+}
+
+// reservedOnlyPeerSetHandler records the reserved-only mode of the peer sets
+type reservedOnlyPeerSetHandler struct {
+	PeerSetHandler
+	reservedOnly map[int]bool
+}
+
+func (h *reservedOnlyPeerSetHandler) SetReservedOnly(setID int, reservedOnly bool) {
+	h.reservedOnly[setID] = reservedOnly
+}
+
+func TestSetReservedOnly_peerSetConfig(t *testing.T) {
+	t.Parallel()
+
+	cfg := &Config{
+		MinPeers:       1,
+		MaxPeers:       4,
+		GrandpaPeerSet: PeerSetConfig{ReservedOnly: true},
+	}
+	cm, err := newConnManager(cfg.MinPeers, cfg.MaxPeers, newPeerSetConfig(cfg))
+	require.NoError(t, err)
+	copy(cm.setsReservedOnly, peerSetsReservedOnly(cfg))
+
+	handler := &reservedOnlyPeerSetHandler{reservedOnly: make(map[int]bool)}
+	cm.peerSetHandler = handler
+
+	cm.setReservedOnly(true)
+	require.Equal(t, map[int]bool{
+		blockAnnounceSetID: true,
+		transactionsSetID:  true,
+		grandpaSetID:       true,
+	}, handler.reservedOnly)
+
+	// the peer set configured to only allow its reserved peers keeps doing so
+	cm.setReservedOnly(false)
+	require.Equal(t, map[int]bool{
+		blockAnnounceSetID: false,
+		transactionsSetID:  false,
+		grandpaSetID:       true,
+	}, handler.reservedOnly)
+	require.False(t, cm.isReservedOnly())
+}
+
+func TestPeerSetConnections(t *testing.T) {
+	t.Parallel()
+
+	cm, err := newConnManager(1, 4, newPeerSetConfig(&Config{MinPeers: 1, MaxPeers: 4}))
+	require.NoError(t, err)
+
+	p := peer.ID("a")
+
+	// a peer which isn't managed by any peer set receives every notification
+	require.True(t, cm.inPeerSet(grandpaSetID, p))
+
+	cm.incoming(p)
+	cm.peerSetConnected(blockAnnounceSetID, p)
+	require.True(t, cm.inPeerSet(blockAnnounceSetID, p))
+	require.False(t, cm.inPeerSet(grandpaSetID, p))
+
+	// the transactions and GRANDPA peer sets haven't answered yet
+	require.False(t, cm.peerSetDisconnected(transactionsSetID, p))
+
+	cm.peerSetConnected(grandpaSetID, p)
+	require.True(t, cm.inPeerSet(grandpaSetID, p))
+
+	require.False(t, cm.peerSetDisconnected(blockAnnounceSetID, p))
+	require.False(t, cm.inPeerSet(blockAnnounceSetID, p))
+	require.True(t, cm.peerSetDisconnected(grandpaSetID, p))
+	require.True(t, cm.inPeerSet(blockAnnounceSetID, p))
+}
+
+// testConn is a connection with a remote peer, whose other methods aren't implemented
+type testConn struct {
+	network.Conn
+	remote peer.ID
+}
+
+func (testConn) LocalPeer() peer.ID    { return "" }
+func (c testConn) RemotePeer() peer.ID { return c.remote }
+
+func TestPeerSetConnections_reconnect(t *testing.T) {
+	t.Parallel()
+
+	cm, err := newConnManager(1, 4, newPeerSetConfig(&Config{MinPeers: 1, MaxPeers: 4}))
+	require.NoError(t, err)
+
+	p := peer.ID("a")
+
+	cm.incoming(p)
+	cm.peerSetConnected(blockAnnounceSetID, p)
+	cm.peerSetConnected(grandpaSetID, p)
+	require.False(t, cm.inPeerSet(transactionsSetID, p))
+
+	// the remote peer closes the connection
+	cm.Disconnected(nil, testConn{remote: p})
+	require.True(t, cm.inPeerSet(transactionsSetID, p))
+
+	// on reconnection, the peer waits for the answer of every peer set again
+	cm.incoming(p)
+	require.False(t, cm.peerSetDisconnected(blockAnnounceSetID, p))
+	require.False(t, cm.peerSetDisconnected(grandpaSetID, p))
+	require.True(t, cm.peerSetDisconnected(transactionsSetID, p))
+
+	cm.incoming(p)
+	cm.peerSetConnected(transactionsSetID, p)
+	require.True(t, cm.inPeerSet(transactionsSetID, p))
+	require.False(t, cm.inPeerSet(blockAnnounceSetID, p))
 }
@@ -15,4 +15,6 @@
 	errHandshakeTimeout              = errors.New("handshake timeout reached")
 	errBlockRequestFromNumberInvalid = errors.New("block request message From number is not valid")
 	errInvalidStartingBlockType      = errors.New("invalid StartingBlock in messsage")
+	errPeerDisconnected              = errors.New("peer is disconnected")
+	errInvalidStateRequestBlock      = errors.New("state request message Block hash is not valid")
 )
@@ -13,7 +13,6 @@
 	"sync"
 	"time"
 
-	"github.com/ChainSafe/gossamer/dot/peerset"
 	"github.com/chyeh/pubip"
 	"github.com/dgraph-io/ristretto"
 	badger "github.com/ipfs/go-ds-badger2"
@@ -67,17 +66,17 @@ func init() {
 
 // host wraps libp2p host with network host configuration and services
 type host struct {
-	ctx             context.Context
-	h               libp2phost.Host
-	discovery       *discovery
-	bootnodes       []peer.AddrInfo
-	persistentPeers []peer.AddrInfo
-	protocolID      protocol.ID
-	cm              *ConnManager
-	ds              *badger.Datastore
-	messageCache    *messageCache
-	bwc             *metrics.BandwidthCounter
-	closeSync       sync.Once
+	ctx           context.Context
+	h             libp2phost.Host
+	discovery     *discovery
+	bootnodes     []peer.AddrInfo
+	reservedPeers [numPeerSets][]peer.AddrInfo
+	protocolID    protocol.ID
+	cm            *ConnManager
+	ds            *badger.Datastore
+	messageCache  *messageCache
+	bwc           *metrics.BandwidthCounter
+	closeSync     sync.Once
 }
 
 func newHost(ctx context.Context, cfg *Config) (*host, error) {
@@ -125,30 +124,33 @@ func newHost(ctx context.Context, cfg *Config) (*host, error) {
 		return nil, err
 	}
 
-	// format persistent peers
-	pps, err := stringsToAddrInfos(cfg.PersistentPeers)
-	if err != nil {
-		return nil, err
+	// format the reserved peers of each peer set, the persistent peers are the reserved peers
+	// of the block announces peer set
+	var reservedPeers [numPeerSets][]peer.AddrInfo
+	for setID, addrs := range [numPeerSets][]string{
+		blockAnnounceSetID: cfg.PersistentPeers,
+		transactionsSetID:  cfg.TransactionsPeerSet.ReservedPeers,
+		grandpaSetID:       cfg.GrandpaPeerSet.ReservedPeers,
+	} {
+		reservedPeers[setID], err = stringsToAddrInfos(addrs)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// We have tried to set maxInPeers and maxOutPeers such that number of peer
-	// connections remain between min peers and max peers
-	const reservedOnly = false
-	peerCfgSet := peerset.NewConfigSet(
-		uint32(cfg.MaxPeers-cfg.MinPeers),
-		uint32(cfg.MaxPeers/2),
-		reservedOnly,
-		peerSetSlotAllocTime,
-	)
-
 	// create connection manager
-	cm, err := newConnManager(cfg.MinPeers, cfg.MaxPeers, peerCfgSet)
+	cm, err := newConnManager(cfg.MinPeers, cfg.MaxPeers, newPeerSetConfig(cfg))
 	if err != nil {
 		return nil, err
 	}
 
-	for _, pp := range pps {
-		cm.persistentPeers.Store(pp.ID, struct{}{})
+	cm.reservedOnly = cfg.ReservedOnly
+	copy(cm.setsReservedOnly, peerSetsReservedOnly(cfg))
+	for setID, infos := range reservedPeers {
+		for _, info := range infos {
+			cm.reservedPeers[setID][info.ID] = struct{}{}
+			cm.persistentPeers.Store(info.ID, struct{}{})
+		}
 	}
 
 	// format protocol id
@@ -210,16 +212,16 @@ func newHost(ctx context.Context, cfg *Config) (*host, error) {
 	discovery := newDiscovery(ctx, h, bns, ds, pid, cfg.MinPeers, cfg.MaxPeers, cm.peerSetHandler)
 
 	host := &host{
-		ctx:             ctx,
-		h:               h,
-		discovery:       discovery,
-		bootnodes:       bns,
-		protocolID:      pid,
-		cm:              cm,
-		ds:              ds,
-		persistentPeers: pps,
-		messageCache:    msgCache,
-		bwc:             bwc,
+		ctx:           ctx,
+		h:             h,
+		discovery:     discovery,
+		bootnodes:     bns,
+		protocolID:    pid,
+		cm:            cm,
+		ds:            ds,
+		reservedPeers: reservedPeers,
+		messageCache:  msgCache,
+		bwc:           bwc,
 	}
 
 	cm.host = host
@@ -274,15 +276,17 @@ func (h *host) connect(p peer.AddrInfo) (err error) {
 
 // bootstrap connects the host to the configured bootnodes
 func (h *host) bootstrap() {
-	for _, info := range h.persistentPeers {
-		h.h.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.PermanentAddrTTL)
-		h.cm.peerSetHandler.AddReservedPeer(0, info.ID)
+	for setID, infos := range h.reservedPeers {
+		for _, info := range infos {
+			h.h.Peerstore().AddAddrs(info.ID, info.Addrs, peerstore.PermanentAddrTTL)
+			h.cm.addReservedPeers(setID, info.ID)
+		}
 	}
 
 	for _, addrInfo := range h.bootnodes {
 		logger.Debugf("bootstrapping to peer %s", addrInfo.ID)
 		h.h.Peerstore().AddAddrs(addrInfo.ID, addrInfo.Addrs, peerstore.PermanentAddrTTL)
-		h.cm.peerSetHandler.AddPeer(0, addrInfo.ID)
+		h.cm.peerSetHandler.AddPeer(blockAnnounceSetID, addrInfo.ID)
 	}
 }
 
@@ -355,7 +359,7 @@ func (h *host) addReservedPeers(addrs ...string) error {
 			return err
 		}
 		h.h.Peerstore().AddAddrs(addrInfo.ID, addrInfo.Addrs, peerstore.PermanentAddrTTL)
-		h.cm.peerSetHandler.AddReservedPeer(0, addrInfo.ID)
+		h.cm.addReservedPeers(blockAnnounceSetID, addrInfo.ID)
 	}
 
 	return nil
@@ -368,7 +372,7 @@ func (h *host) removeReservedPeers(ids ...string) error {
 		if err != nil {
 			return err
 		}
-		h.cm.peerSetHandler.RemoveReservedPeer(0, peerID)
+		h.cm.removeReservedPeers(blockAnnounceSetID, peerID)
 		h.h.ConnManager().Unprotect(peerID, "")
 	}
 
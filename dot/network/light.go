@@ -4,8 +4,11 @@
 package network
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/ChainSafe/gossamer/dot/peerset"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/pkg/scale"
@@ -43,29 +46,37 @@ func (s *Service) handleLightMsg(stream libp2pnetwork.Stream, msg Message) (err
 		return nil
 	}
 
+	if s.lightHandler == nil {
+		logger.Debugf("ignoring LightRequest from peer %s: light client requests are not served",
+			stream.Conn().RemotePeer())
+		return nil
+	}
+
+	// a decoded LightRequest always contains every request type, so
+	// the request kind is determined by which of them is populated.
 	resp := NewLightResponse()
 	switch {
-	case lr.RemoteCallRequest != nil:
-		resp.RemoteCallResponse, err = remoteCallResp(lr.RemoteCallRequest)
-	case lr.RemoteHeaderRequest != nil:
-		resp.RemoteHeaderResponse, err = remoteHeaderResp(lr.RemoteHeaderRequest)
-	case lr.RemoteChangesRequest != nil:
-		resp.RemoteChangesResponse, err = remoteChangeResp(lr.RemoteChangesRequest)
-	case lr.RemoteReadRequest != nil:
-		resp.RemoteReadResponse, err = remoteReadResp(lr.RemoteReadRequest)
-	case lr.RemoteReadChildRequest != nil:
-		resp.RemoteReadResponse, err = remoteReadChildResp(lr.RemoteReadChildRequest)
+	case lr.RemoteCallRequest != nil && lr.RemoteCallRequest.Method != "":
+		resp.RemoteCallResponse, err = s.lightHandler.CreateRemoteCallResponse(lr.RemoteCallRequest)
+	case lr.RemoteReadRequest != nil && len(lr.RemoteReadRequest.Keys) > 0:
+		resp.RemoteReadResponse, err = s.lightHandler.CreateRemoteReadResponse(lr.RemoteReadRequest)
+	case lr.RemoteReadChildRequest != nil && len(lr.RemoteReadChildRequest.StorageKey) > 0:
+		resp.RemoteReadResponse, err = s.lightHandler.CreateRemoteReadChildResponse(lr.RemoteReadChildRequest)
+	case lr.RemoteChangesRequest != nil && lr.RemoteChangesRequest.FirstBlock != nil:
+		resp.RemoteChangesResponse, err = s.lightHandler.CreateRemoteChangesResponse(lr.RemoteChangesRequest)
+	case lr.RemoteHeaderRequest != nil && len(lr.RemoteHeaderRequest.Block) > 0:
+		resp.RemoteHeaderResponse, err = s.lightHandler.CreateRemoteHeaderResponse(lr.RemoteHeaderRequest)
 	default:
 		logger.Warn("ignoring LightRequest without request data")
 		return nil
 	}
 
 	if err != nil {
+		logger.Debugf("failed to create LightResponse for peer %s: %s", stream.Conn().RemotePeer(), err)
 		return err
 	}
 
-	// TODO(arijit): Remove once we implement the internal APIs. Added to increase code coverage. (#1856)
-	logger.Debugf("LightResponse message: %s", resp)
+	logger.Tracef("sending LightResponse message to peer %s: %s", stream.Conn().RemotePeer(), resp)
 
 	err = s.host.writeToStream(stream, resp)
 	if err != nil {
@@ -74,6 +85,56 @@ func (s *Service) handleLightMsg(stream libp2pnetwork.Stream, msg Message) (err
 	return err
 }
 
+const lightRequestTimeout = time.Second * 10
+
+// MaxLightResponseSize is the maximum encoded size of a light client response. Proofs of runtime
+// calls contain the runtime code, so it needs to be as large as the one of a block response.
+var MaxLightResponseSize = maxBlockResponseSize
+
+// DoLightRequest sends a light client request to the given peer.
+// If a response is received within a certain time period, it is returned,
+// otherwise an error is returned.
+func (s *Service) DoLightRequest(to peer.ID, req *LightRequest) (*LightResponse, error) {
+	fullLightID := s.host.protocolID + lightID
+
+	ctx, cancel := context.WithTimeout(s.ctx, lightRequestTimeout)
+	defer cancel()
+
+	stream, err := s.host.h.NewStream(ctx, to, fullLightID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	if err = s.host.writeToStream(stream, req); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, MaxLightResponseSize)
+	n, err := readStream(stream, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("read stream error: %w", err)
+	}
+
+	if n == 0 {
+		return nil, fmt.Errorf("received empty message")
+	}
+
+	resp, err := newLightResponseFromBytes(buf[:n])
+	if err != nil {
+		s.host.cm.peerSetHandler.ReportPeer(peerset.ReputationChange{
+			Value:  peerset.BadMessageValue,
+			Reason: peerset.BadMessageReason,
+		}, to)
+		return nil, fmt.Errorf("failed to decode light response: %w", err)
+	}
+
+	return resp, nil
+}
+
 // Pair is a pair of arbitrary bytes.
 type Pair struct {
 	First  []byte
@@ -303,7 +364,7 @@ type RemoteChangesRequest struct {
 	Min        []byte
 	Max        []byte
 	StorageKey *[]byte
-	key        []byte
+	Key        []byte
 }
 
 func newRemoteChangesRequest() RemoteChangesRequest {
@@ -313,6 +374,7 @@ func newRemoteChangesRequest() RemoteChangesRequest {
 		Min:        []byte{},
 		Max:        []byte{},
 		StorageKey: nil,
+		Key:        []byte{},
 	}
 }
 
@@ -341,12 +403,13 @@ func newRemoteReadResponse() *RemoteReadResponse {
 // RemoteHeaderResponse ...
 type RemoteHeaderResponse struct {
 	Header []*types.Header
-	proof  []byte
+	Proof  []byte
 }
 
 func newRemoteHeaderResponse() *RemoteHeaderResponse {
 	return &RemoteHeaderResponse{
 		Header: nil,
+		Proof:  []byte{},
 	}
 }
 
@@ -391,7 +454,7 @@ func (rc *RemoteChangesRequest) String() string {
 		string(rc.Min),
 		string(rc.Max),
 		storageKey,
-		string(rc.key),
+		string(rc.Key),
 	)
 }
 
@@ -450,21 +513,5 @@ func (rr *RemoteReadResponse) String() string {
 
 // String formats a RemoteHeaderResponse as a string
 func (rh *RemoteHeaderResponse) String() string {
-	return fmt.Sprintf("Header =%+v Proof =%s", rh.Header, string(rh.proof))
-}
-
-func remoteCallResp(_ *RemoteCallRequest) (*RemoteCallResponse, error) {
-	return &RemoteCallResponse{}, nil
-}
-func remoteChangeResp(_ *RemoteChangesRequest) (*RemoteChangesResponse, error) {
-	return &RemoteChangesResponse{}, nil
-}
-func remoteHeaderResp(_ *RemoteHeaderRequest) (*RemoteHeaderResponse, error) {
-	return &RemoteHeaderResponse{}, nil
-}
-func remoteReadChildResp(_ *RemoteReadChildRequest) (*RemoteReadResponse, error) {
-	return &RemoteReadResponse{}, nil
-}
-func remoteReadResp(_ *RemoteReadRequest) (*RemoteReadResponse, error) {
-	return &RemoteReadResponse{}, nil
+	return fmt.Sprintf("Header =%+v Proof =%s", rh.Header, string(rh.Proof))
 }
@@ -4,19 +4,21 @@
 package network
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 
+	"github.com/golang/mock/gomock"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/stretchr/testify/require"
 )
 
 func TestEncodeLightRequest(t *testing.T) {
 	t.Parallel()
-	exp := common.MustHexToBytes("0x0000000000000000000000000000")
+	exp := common.MustHexToBytes("0x000000000000000000000000000000")
 
 	testLightRequest := NewLightRequest()
 	enc, err := testLightRequest.Encode()
@@ -31,7 +33,7 @@ func TestEncodeLightRequest(t *testing.T) {
 
 func TestEncodeLightResponse(t *testing.T) {
 	t.Parallel()
-	exp := common.MustHexToBytes("0x00000000000000")
+	exp := common.MustHexToBytes("0x0000000000000000")
 
 	testLightResponse := NewLightResponse()
 	enc, err := testLightResponse.Encode()
@@ -85,6 +87,8 @@ func TestDecodeLightMessage(t *testing.T) {
 	require.Equal(t, respEnc, resEnc)
 }
 
+//go:generate mockgen -destination=mock_light_handler_test.go -package $GOPACKAGE . LightHandler
+
 func TestHandleLightMessage_Response(t *testing.T) {
 	t.Parallel()
 
@@ -116,45 +120,103 @@ func TestHandleLightMessage_Response(t *testing.T) {
 	stream, err := s.host.h.NewStream(s.ctx, b.host.id(), s.host.protocolID+lightID)
 	require.NoError(t, err)
 
-	// Testing empty request
-	msg := &LightRequest{}
+	// Testing request without light handler
+	msg := NewLightRequest()
+	msg.RemoteHeaderRequest.Block = []byte{1}
 	err = s.handleLightMsg(stream, msg)
 	require.NoError(t, err)
 
+	ctrl := gomock.NewController(t)
+	lightHandler := NewMockLightHandler(ctrl)
+	s.SetLightHandler(lightHandler)
+
+	// Testing empty request
+	err = s.handleLightMsg(stream, NewLightRequest())
+	require.NoError(t, err)
+
 	expectedErr := "failed to find any peer in table"
 
-	// Testing remoteCallResp()
-	msg = &LightRequest{
-		RemoteCallRequest: &RemoteCallRequest{},
-	}
+	// Testing handler error
+	msg = NewLightRequest()
+	msg.RemoteCallRequest.Method = "Core_version"
+	lightHandler.EXPECT().CreateRemoteCallResponse(msg.RemoteCallRequest).Return(nil, errors.New("call error"))
+	err = s.handleLightMsg(stream, msg)
+	require.EqualError(t, err, "call error")
+
+	// Testing CreateRemoteCallResponse()
+	lightHandler.EXPECT().CreateRemoteCallResponse(msg.RemoteCallRequest).Return(newRemoteCallResponse(), nil)
 	err = s.handleLightMsg(stream, msg)
 	require.Error(t, err, expectedErr, msg.String())
 
-	// Testing remoteHeaderResp()
-	msg = &LightRequest{
-		RemoteHeaderRequest: &RemoteHeaderRequest{},
-	}
+	// Testing CreateRemoteHeaderResponse()
+	msg = NewLightRequest()
+	msg.RemoteHeaderRequest.Block = []byte{1}
+	lightHandler.EXPECT().CreateRemoteHeaderResponse(msg.RemoteHeaderRequest).Return(newRemoteHeaderResponse(), nil)
 	err = s.handleLightMsg(stream, msg)
 	require.Error(t, err, expectedErr, msg.String())
 
-	// Testing remoteChangeResp()
-	msg = &LightRequest{
-		RemoteChangesRequest: &RemoteChangesRequest{},
-	}
+	// Testing CreateRemoteChangesResponse()
+	msg = NewLightRequest()
+	msg.RemoteChangesRequest.FirstBlock = &common.Hash{1}
+	lightHandler.EXPECT().CreateRemoteChangesResponse(msg.RemoteChangesRequest).Return(newRemoteChangesResponse(), nil)
 	err = s.handleLightMsg(stream, msg)
 	require.Error(t, err, expectedErr, msg.String())
 
-	// Testing remoteReadResp()
-	msg = &LightRequest{
-		RemoteReadRequest: &RemoteReadRequest{},
-	}
+	// Testing CreateRemoteReadResponse()
+	msg = NewLightRequest()
+	msg.RemoteReadRequest.Keys = [][]byte{{1}}
+	lightHandler.EXPECT().CreateRemoteReadResponse(msg.RemoteReadRequest).Return(newRemoteReadResponse(), nil)
 	err = s.handleLightMsg(stream, msg)
 	require.Error(t, err, expectedErr, msg.String())
 
-	// Testing remoteReadChildResp()
-	msg = &LightRequest{
-		RemoteReadChildRequest: &RemoteReadChildRequest{},
-	}
+	// Testing CreateRemoteReadChildResponse()
+	msg = NewLightRequest()
+	msg.RemoteReadChildRequest.StorageKey = []byte{1}
+	lightHandler.EXPECT().CreateRemoteReadChildResponse(msg.RemoteReadChildRequest).
+		Return(newRemoteReadResponse(), nil)
 	err = s.handleLightMsg(stream, msg)
 	require.Error(t, err, expectedErr, msg.String())
 }
+
+func TestDoLightRequest(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		BasePath:    t.TempDir(),
+		Port:        availablePort(t),
+		NoBootstrap: true,
+		NoMDNS:      true,
+	}
+	s := createTestService(t, config)
+
+	configB := &Config{
+		BasePath:    t.TempDir(),
+		Port:        availablePort(t),
+		NoBootstrap: true,
+		NoMDNS:      true,
+	}
+	b := createTestService(t, configB)
+
+	addrInfoB := b.host.addrInfo()
+	err := s.host.connect(addrInfoB)
+	// retry connect if "failed to dial" error
+	if failedToDial(err) {
+		time.Sleep(TestBackoffTimeout)
+		err = s.host.connect(addrInfoB)
+	}
+	require.NoError(t, err)
+
+	req := NewLightRequest()
+	req.RemoteReadRequest.Block = common.Hash{1}.ToBytes()
+	req.RemoteReadRequest.Keys = [][]byte{{1}}
+
+	ctrl := gomock.NewController(t)
+	lightHandler := NewMockLightHandler(ctrl)
+	lightHandler.EXPECT().CreateRemoteReadResponse(req.RemoteReadRequest).
+		Return(&RemoteReadResponse{Proof: []byte{1, 2}}, nil)
+	b.SetLightHandler(lightHandler)
+
+	resp, err := s.DoLightRequest(b.host.id(), req)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, resp.RemoteReadResponse.Proof)
+}
@@ -49,6 +49,21 @@ func (mr *MockSyncerMockRecorder) CreateBlockResponse(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlockResponse", reflect.TypeOf((*MockSyncer)(nil).CreateBlockResponse), arg0)
 }
 
+// CreateStateResponse mocks base method.
+func (m *MockSyncer) CreateStateResponse(arg0 *StateRequest) (*StateResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateStateResponse", arg0)
+	ret0, _ := ret[0].(*StateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateStateResponse indicates an expected call of CreateStateResponse.
+func (mr *MockSyncerMockRecorder) CreateStateResponse(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStateResponse", reflect.TypeOf((*MockSyncer)(nil).CreateStateResponse), arg0)
+}
+
 // HandleBlockAnnounce mocks base method.
 func (m *MockSyncer) HandleBlockAnnounce(arg0 peer.ID, arg1 *BlockAnnounceMessage) error {
 	m.ctrl.T.Helper()
@@ -77,6 +92,18 @@ func (mr *MockSyncerMockRecorder) HandleBlockAnnounceHandshake(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleBlockAnnounceHandshake", reflect.TypeOf((*MockSyncer)(nil).HandleBlockAnnounceHandshake), arg0, arg1)
 }
 
+// HandlePeerDisconnected mocks base method.
+func (m *MockSyncer) HandlePeerDisconnected(arg0 peer.ID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandlePeerDisconnected", arg0)
+}
+
+// HandlePeerDisconnected indicates an expected call of HandlePeerDisconnected.
+func (mr *MockSyncerMockRecorder) HandlePeerDisconnected(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandlePeerDisconnected", reflect.TypeOf((*MockSyncer)(nil).HandlePeerDisconnected), arg0)
+}
+
 // IsSynced mocks base method.
 func (m *MockSyncer) IsSynced() bool {
 	m.ctrl.T.Helper()
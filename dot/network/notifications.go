@@ -61,16 +61,18 @@ type handshakeReader struct {
 
 type notificationsProtocol struct {
 	protocolID         protocol.ID
+	setID              int
 	getHandshake       HandshakeGetter
 	handshakeDecoder   HandshakeDecoder
 	handshakeValidator HandshakeValidator
 	peersData          *peersData
 }
 
-func newNotificationsProtocol(protocolID protocol.ID, handshakeGetter HandshakeGetter,
+func newNotificationsProtocol(protocolID protocol.ID, setID int, handshakeGetter HandshakeGetter,
 	handshakeDecoder HandshakeDecoder, handshakeValidator HandshakeValidator) *notificationsProtocol {
 	return &notificationsProtocol{
 		protocolID:         protocolID,
+		setID:              setID,
 		getHandshake:       handshakeGetter,
 		handshakeValidator: handshakeValidator,
 		handshakeDecoder:   handshakeDecoder,
@@ -293,6 +295,10 @@ func (s *Service) sendHandshake(peer peer.ID, hs Handshake, info *notificationsP
 	// so we cannot have a method on peersData to lock and unlock the mutex
 	// from the map
 	peerMutex := info.peersData.getMutex(peer)
+	if peerMutex == nil {
+		// the connection was closed, and its handshake data cleared, before we could send the handshake
+		return nil, errPeerDisconnected
+	}
 	peerMutex.Lock()
 	defer peerMutex.Unlock()
 
@@ -386,6 +392,11 @@ func (s *Service) broadcastExcluding(info *notificationsProtocol, excluding peer
 			continue
 		}
 
+		// only gossip to the peers of the peer set the protocol is bound to
+		if !s.host.cm.inPeerSet(info.setID, peer) {
+			continue
+		}
+
 		info.peersData.setMutex(peer)
 
 		go s.sendData(peer, hs, info, msg)
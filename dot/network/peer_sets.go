@@ -0,0 +1,82 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"github.com/ChainSafe/gossamer/dot/peerset"
+)
+
+// Each notifications protocol is bound to a peer set, which has its own slots and reserved peers.
+// We remain connected to a peer as long as it is connected in one of the peer sets.
+const (
+	// blockAnnounceSetID is the peer set of the block announces protocol. It contains the peers we
+	// sync with, and the peers we discover are added to it.
+	blockAnnounceSetID = iota
+	// transactionsSetID is the peer set of the transactions protocol
+	transactionsSetID
+	// grandpaSetID is the peer set of the GRANDPA protocol
+	grandpaSetID
+
+	numPeerSets
+)
+
+// PeerSetConfig is the configuration of the peer set of a notifications protocol
+type PeerSetConfig struct {
+	// MaxInPeers is the number of slots for incoming peers, the slots of the block announces
+	// peer set are used if zero
+	MaxInPeers int
+	// MaxOutPeers is the number of slots for outgoing peers, the slots of the block announces
+	// peer set are used if zero
+	MaxOutPeers int
+	// ReservedPeers is a list of multiaddrs which the node should remain connected to in the peer set
+	ReservedPeers []string
+	// ReservedOnly only allows the reserved peers in the peer set
+	ReservedOnly bool
+}
+
+// peerSetID returns the peer set of the notifications protocol with the given message type
+func peerSetID(messageID byte) int {
+	switch messageID {
+	case TransactionMsgType:
+		return transactionsSetID
+	case ConsensusMsgType:
+		return grandpaSetID
+	default:
+		return blockAnnounceSetID
+	}
+}
+
+// newPeerSetConfig returns the configuration of the peer sets, ordered by peer set id
+func newPeerSetConfig(cfg *Config) *peerset.ConfigSet {
+	// We have tried to set maxInPeers and maxOutPeers such that number of peer
+	// connections remain between min peers and max peers
+	maxInPeers := uint32(cfg.MaxPeers - cfg.MinPeers)
+	maxOutPeers := uint32(cfg.MaxPeers / 2)
+	peerCfgSet := peerset.NewConfigSet(
+		maxInPeers,
+		maxOutPeers,
+		cfg.ReservedOnly,
+		peerSetSlotAllocTime,
+	)
+
+	for _, setCfg := range []PeerSetConfig{cfg.TransactionsPeerSet, cfg.GrandpaPeerSet} {
+		in, out := maxInPeers, maxOutPeers
+		if setCfg.MaxInPeers != 0 {
+			in = uint32(setCfg.MaxInPeers)
+		}
+		if setCfg.MaxOutPeers != 0 {
+			out = uint32(setCfg.MaxOutPeers)
+		}
+
+		peerCfgSet.AddSet(in, out, cfg.ReservedOnly || setCfg.ReservedOnly)
+	}
+
+	return peerCfgSet
+}
+
+// peerSetsReservedOnly returns whether each peer set is configured to only allow its reserved peers,
+// ordered by peer set id
+func peerSetsReservedOnly(cfg *Config) []bool {
+	return []bool{false, cfg.TransactionsPeerSet.ReservedOnly, cfg.GrandpaPeerSet.ReservedOnly}
+}
@@ -120,6 +120,8 @@ type Service struct {
 	blockState         BlockState
 	syncer             Syncer
 	transactionHandler TransactionHandler
+	lightHandler       LightHandler
+	warpSyncProvider   WarpSyncProvider
 
 	// Configuration options
 	noBootstrap bool
@@ -224,6 +226,16 @@ func (s *Service) SetTransactionHandler(handler TransactionHandler) {
 	s.transactionHandler = handler
 }
 
+// SetLightHandler sets the LightHandler used to answer light client requests
+func (s *Service) SetLightHandler(handler LightHandler) {
+	s.lightHandler = handler
+}
+
+// SetWarpSyncProvider sets the WarpSyncProvider used to answer warp sync requests
+func (s *Service) SetWarpSyncProvider(provider WarpSyncProvider) {
+	s.warpSyncProvider = provider
+}
+
 // Start starts the network service
 func (s *Service) Start() error {
 	if s.syncer == nil {
@@ -240,6 +252,8 @@ func (s *Service) Start() error {
 
 	s.host.registerStreamHandler(s.host.protocolID+syncID, s.handleSyncStream)
 	s.host.registerStreamHandler(s.host.protocolID+lightID, s.handleLightStream)
+	s.host.registerStreamHandler(s.host.protocolID+warpSyncID, s.handleWarpSyncStream)
+	s.host.registerStreamHandler(s.host.protocolID+stateID, s.handleStateStream)
 
 	// register block announce protocol
 	err := s.RegisterNotificationsProtocol(
@@ -294,6 +308,10 @@ func (s *Service) Start() error {
 			prtl.peersData.deleteInboundHandshakeData(peerID)
 			prtl.peersData.deleteOutboundHandshakeData(peerID)
 		}
+
+		if s.syncer != nil {
+			s.syncer.HandlePeerDisconnected(peerID)
+		}
 	}
 
 	// log listening addresses to console
@@ -438,8 +456,17 @@ func (s *Service) sentBlockIntervalTelemetry() {
 }
 
 func (s *Service) handleConn(conn libp2pnetwork.Conn) {
-	// TODO: currently we only have one set so setID is 0, change this once we have more set in peerSet.
-	s.host.cm.peerSetHandler.Incoming(0, conn.RemotePeer())
+	if s.host.cm.isReservedOnly() && !s.host.cm.isPersistent(conn.RemotePeer()) {
+		// the connection manager closes the connections with the peers which are not reserved
+		return
+	}
+
+	// each peer set accepts or rejects the peer on its own, the connection is closed once the peer
+	// isn't connected in any of them.
+	s.host.cm.incoming(conn.RemotePeer())
+	for setID := 0; setID < numPeerSets; setID++ {
+		s.host.cm.peerSetHandler.Incoming(setID, conn.RemotePeer())
+	}
 
 	// exchange BlockAnnounceHandshake with peer so we can start to
 	// sync if necessary.
@@ -524,7 +551,8 @@ func (s *Service) RegisterNotificationsProtocol(
 		return errors.New("notifications protocol with message type already exists")
 	}
 
-	np := newNotificationsProtocol(protocolID, handshakeGetter, handshakeDecoder, handshakeValidator)
+	np := newNotificationsProtocol(protocolID, peerSetID(messageID), handshakeGetter, handshakeDecoder,
+		handshakeValidator)
 	s.notificationsProtocols[messageID] = np
 	decoder := createDecoder(np, handshakeDecoder, messageDecoder)
 	handlerWithValidate := s.createNotificationsMessageHandler(np, messageHandler, batchHandler)
@@ -647,6 +675,28 @@ func (s *Service) RemoveReservedPeers(addrs ...string) error {
 	return s.host.removeReservedPeers(addrs...)
 }
 
+// SetReservedOnly sets whether the node only connects to the reserved peers. When enabled,
+// the connections with the peers which are not reserved are closed. When disabled, the peer sets
+// configured to only allow their reserved peers keep doing so.
+func (s *Service) SetReservedOnly(reservedOnly bool) {
+	s.host.cm.setReservedOnly(reservedOnly)
+	if !reservedOnly {
+		return
+	}
+
+	// the peerSet drops the peers it has connected to, but the connections opened outside of it must be closed too
+	for _, p := range s.host.peers() {
+		if s.host.cm.isPersistent(p) {
+			continue
+		}
+
+		err := s.host.closePeer(p)
+		if err != nil {
+			logger.Warnf("failed to close connection with peer %s: %s", p, err)
+		}
+	}
+}
+
 // NodeRoles Returns the roles the node is running as.
 func (s *Service) NodeRoles() byte {
 	return s.cfg.Roles
@@ -690,6 +740,8 @@ func (s *Service) processMessage(msg peerset.Message) {
 		logger.Errorf("found empty peer id in peerset message")
 		return
 	}
+
+	setID := msg.SetID()
 	switch msg.Status {
 	case peerset.Connect:
 		addrInfo := s.host.h.Peerstore().PeerInfo(peerID)
@@ -707,8 +759,17 @@ func (s *Service) processMessage(msg peerset.Message) {
 			logger.Warnf("failed to open connection for peer %s: %s", peerID, err)
 			return
 		}
-		logger.Debugf("connection successful with peer %s", peerID)
+		s.host.cm.peerSetConnected(setID, peerID)
+		logger.Debugf("connection successful with peer %s in peer set %d", peerID, setID)
+	case peerset.Accept:
+		s.host.cm.peerSetConnected(setID, peerID)
 	case peerset.Drop, peerset.Reject:
+		// we remain connected to the peer as long as another peer set wants it
+		if !s.host.cm.peerSetDisconnected(setID, peerID) {
+			logger.Debugf("peer %s dropped from peer set %d", peerID, setID)
+			return
+		}
+
 		err := s.host.closePeer(peerID)
 		if err != nil {
 			logger.Warnf("failed to close connection with peer %s: %s", peerID, err)
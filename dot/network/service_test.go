@@ -146,6 +146,10 @@ func createTestService(t *testing.T, cfg *Config) (srvc *Service) {
 				gomock.AssignableToTypeOf(peer.ID("")), gomock.Any()).
 			Return(nil).AnyTimes()
 
+		syncer.EXPECT().
+			HandlePeerDisconnected(gomock.AssignableToTypeOf(peer.ID(""))).
+			AnyTimes()
+
 		syncer.EXPECT().
 			CreateBlockResponse(gomock.Any()).
 			Return(newTestBlockResponseMessage(t), nil).AnyTimes()
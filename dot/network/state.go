@@ -31,11 +31,38 @@ type Syncer interface {
 	// If a request needs to be sent to the peer to retrieve the full block, this function will return it.
 	HandleBlockAnnounce(from peer.ID, msg *BlockAnnounceMessage) error
 
+	// HandlePeerDisconnected is called when a peer disconnects from us
+	HandlePeerDisconnected(from peer.ID)
+
 	// IsSynced exposes the internal synced state
 	IsSynced() bool
 
 	// CreateBlockResponse is called upon receipt of a BlockRequestMessage to create the response
 	CreateBlockResponse(*BlockRequestMessage) (*BlockResponseMessage, error)
+
+	// CreateStateResponse is called upon receipt of a StateRequest to create the response
+	CreateStateResponse(*StateRequest) (*StateResponse, error)
+}
+
+// LightHandler is implemented by the service which answers light client requests
+type LightHandler interface {
+	// CreateRemoteCallResponse executes the requested runtime call and returns a proof of its execution
+	CreateRemoteCallResponse(*RemoteCallRequest) (*RemoteCallResponse, error)
+	// CreateRemoteReadResponse returns a read proof of the requested keys
+	CreateRemoteReadResponse(*RemoteReadRequest) (*RemoteReadResponse, error)
+	// CreateRemoteReadChildResponse returns a read proof of the requested child trie keys
+	CreateRemoteReadChildResponse(*RemoteReadChildRequest) (*RemoteReadResponse, error)
+	// CreateRemoteHeaderResponse returns the requested header along with a proof of its finality, if any
+	CreateRemoteHeaderResponse(*RemoteHeaderRequest) (*RemoteHeaderResponse, error)
+	// CreateRemoteChangesResponse returns the blocks of the requested range which modify the requested key,
+	// along with a proof of the key in the state of these blocks
+	CreateRemoteChangesResponse(*RemoteChangesRequest) (*RemoteChangesResponse, error)
+}
+
+// WarpSyncProvider is implemented by the service which answers warp sync requests
+type WarpSyncProvider interface {
+	// CreateWarpSyncProof returns the proof of the authority set changes which happened after the given block
+	CreateWarpSyncProof(begin common.Hash) (*WarpSyncProof, error)
 }
 
 // TransactionHandler is the interface used by the transactions sub-protocol
@@ -49,6 +76,7 @@ type PeerSetHandler interface {
 	Start(context.Context)
 	Stop()
 	ReportPeer(peerset.ReputationChange, ...peer.ID)
+	SetReservedOnly(int, bool)
 	PeerAdd
 	PeerRemove
 	Peer
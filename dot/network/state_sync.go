@@ -0,0 +1,271 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/ChainSafe/gossamer/dot/network/proto"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	stateID             = "/state/2"
+	stateRequestTimeout = time.Second * 20
+)
+
+// MaxStateResponseSize is the maximum encoded size of a state response
+var MaxStateResponseSize = maxBlockResponseSize
+
+// StateRequest is a request for the storage entries of the state of a block.
+// Entries are returned in the order of their keys, starting after the Start key.
+type StateRequest struct {
+	// Block is the hash of the block to get the state of
+	Block common.Hash
+	// Start is empty to start from the first key, it contains the key to start after in the
+	// state trie, or the key of a child trie in the state trie followed by the key to start
+	// after in the child trie.
+	Start [][]byte
+	// NoProof is set to receive the entries, rather than the proof of the entries
+	NoProof bool
+}
+
+// SubProtocol returns the state sub-protocol
+func (*StateRequest) SubProtocol() string {
+	return stateID
+}
+
+// Encode encodes the request using protobuf
+func (r *StateRequest) Encode() ([]byte, error) {
+	msg := &pb.StateRequest{
+		Block:   r.Block[:],
+		Start:   r.Start,
+		NoProof: r.NoProof,
+	}
+
+	return proto.Marshal(msg)
+}
+
+// Decode decodes the protobuf encoded request
+func (r *StateRequest) Decode(in []byte) error {
+	msg := &pb.StateRequest{}
+	err := proto.Unmarshal(in, msg)
+	if err != nil {
+		return err
+	}
+
+	if len(msg.Block) != common.HashLength {
+		return fmt.Errorf("%w: expected %d bytes, got %d bytes",
+			errInvalidStateRequestBlock, common.HashLength, len(msg.Block))
+	}
+
+	r.Block = common.BytesToHash(msg.Block)
+	r.Start = msg.Start
+	r.NoProof = msg.NoProof
+	return nil
+}
+
+// String formats a StateRequest as a string
+func (r *StateRequest) String() string {
+	return fmt.Sprintf("StateRequest Block=%s Start=%x NoProof=%t", r.Block, r.Start, r.NoProof)
+}
+
+// StateEntry is a storage entry
+type StateEntry struct {
+	Key   []byte
+	Value []byte
+}
+
+// KeyValueStateEntry contains the storage entries of a trie
+type KeyValueStateEntry struct {
+	// StateRoot is the root of the child trie, or empty for the state trie
+	StateRoot []byte
+	Entries   []StateEntry
+	// Complete is set if no entry follows the entries of the trie
+	Complete bool
+}
+
+// StateResponse is a response to a StateRequest
+type StateResponse struct {
+	// Entries is only set if the request was sent with NoProof
+	Entries []KeyValueStateEntry
+	// Proof contains the trie nodes proving the entries following the start key,
+	// unless the request was sent with NoProof
+	Proof [][]byte
+}
+
+// SubProtocol returns the state sub-protocol
+func (*StateResponse) SubProtocol() string {
+	return stateID
+}
+
+// Encode encodes the response using protobuf, the proof is SCALE encoded
+func (r *StateResponse) Encode() ([]byte, error) {
+	msg := &pb.StateResponse{
+		Entries: make([]*pb.KeyValueStateEntry, len(r.Entries)),
+	}
+
+	for i, entry := range r.Entries {
+		msg.Entries[i] = &pb.KeyValueStateEntry{
+			StateRoot: entry.StateRoot,
+			Entries:   make([]*pb.StateEntry, len(entry.Entries)),
+			Complete:  entry.Complete,
+		}
+
+		for j, e := range entry.Entries {
+			msg.Entries[i].Entries[j] = &pb.StateEntry{
+				Key:   e.Key,
+				Value: e.Value,
+			}
+		}
+	}
+
+	if r.Proof != nil {
+		proof, err := scale.Marshal(r.Proof)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode proof: %w", err)
+		}
+		msg.Proof = proof
+	}
+
+	return proto.Marshal(msg)
+}
+
+// Decode decodes the protobuf encoded response
+func (r *StateResponse) Decode(in []byte) error {
+	msg := &pb.StateResponse{}
+	err := proto.Unmarshal(in, msg)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]KeyValueStateEntry, len(msg.Entries))
+	for i, entry := range msg.Entries {
+		entries[i] = KeyValueStateEntry{
+			StateRoot: entry.StateRoot,
+			Entries:   make([]StateEntry, len(entry.Entries)),
+			Complete:  entry.Complete,
+		}
+
+		for j, e := range entry.Entries {
+			entries[i].Entries[j] = StateEntry{
+				Key:   e.Key,
+				Value: e.Value,
+			}
+		}
+	}
+
+	var proof [][]byte
+	if len(msg.Proof) != 0 {
+		err = scale.Unmarshal(msg.Proof, &proof)
+		if err != nil {
+			return fmt.Errorf("cannot decode proof: %w", err)
+		}
+	}
+
+	r.Entries = entries
+	r.Proof = proof
+	return nil
+}
+
+// String formats a StateResponse as a string
+func (r *StateResponse) String() string {
+	return fmt.Sprintf("StateResponse Entries=%d Proof=%d nodes", len(r.Entries), len(r.Proof))
+}
+
+// handleStateStream handles streams with the <protocol-id>/state/2 protocol ID
+func (s *Service) handleStateStream(stream libp2pnetwork.Stream) {
+	if stream == nil {
+		return
+	}
+
+	s.readStream(stream, decodeStateRequest, s.handleStateMessage)
+}
+
+func decodeStateRequest(in []byte, _ peer.ID, _ bool) (Message, error) {
+	msg := new(StateRequest)
+	err := msg.Decode(in)
+	return msg, err
+}
+
+// handleStateMessage answers inbound state requests
+func (s *Service) handleStateMessage(stream libp2pnetwork.Stream, msg Message) error {
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	req, ok := msg.(*StateRequest)
+	if !ok {
+		return nil
+	}
+
+	resp, err := s.syncer.CreateStateResponse(req)
+	if err != nil {
+		logger.Debugf("cannot create state response for peer %s: %s", stream.Conn().RemotePeer(), err)
+		return nil
+	}
+
+	err = s.host.writeToStream(stream, resp)
+	if err != nil {
+		logger.Debugf("failed to send StateResponse to peer %s: %s", stream.Conn().RemotePeer(), err)
+	}
+	return err
+}
+
+// DoStateRequest sends a state request to the given peer.
+// If a response is received within a certain time period, it is returned,
+// otherwise an error is returned.
+func (s *Service) DoStateRequest(to peer.ID, req *StateRequest) (*StateResponse, error) {
+	fullStateID := s.host.protocolID + stateID
+
+	s.host.h.ConnManager().Protect(to, "")
+	defer s.host.h.ConnManager().Unprotect(to, "")
+
+	ctx, cancel := context.WithTimeout(s.ctx, stateRequestTimeout)
+	defer cancel()
+
+	stream, err := s.host.h.NewStream(ctx, to, fullStateID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	if err = s.host.writeToStream(stream, req); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, MaxStateResponseSize)
+	n, err := readStream(stream, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("read stream error: %w", err)
+	}
+
+	if n == 0 {
+		return nil, fmt.Errorf("received empty message")
+	}
+
+	resp := new(StateResponse)
+	err = resp.Decode(buf[:n])
+	if err != nil {
+		s.host.cm.peerSetHandler.ReportPeer(peerset.ReputationChange{
+			Value:  peerset.BadMessageValue,
+			Reason: peerset.BadMessageReason,
+		}, to)
+		return nil, fmt.Errorf("failed to decode state response: %w", err)
+	}
+
+	return resp, nil
+}
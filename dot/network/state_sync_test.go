@@ -0,0 +1,62 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeStateRequest(t *testing.T) {
+	t.Parallel()
+
+	req := &StateRequest{
+		Block:   common.Hash{1, 2, 3},
+		Start:   [][]byte{{4, 5}, {6}},
+		NoProof: true,
+	}
+
+	enc, err := req.Encode()
+	require.NoError(t, err)
+
+	res := new(StateRequest)
+	err = res.Decode(enc)
+	require.NoError(t, err)
+	require.Equal(t, req, res)
+
+	invalid, err := (&StateResponse{Proof: [][]byte{{1}}}).Encode()
+	require.NoError(t, err)
+
+	err = res.Decode(invalid)
+	require.ErrorIs(t, err, errInvalidStateRequestBlock)
+}
+
+func TestEncodeStateResponse(t *testing.T) {
+	t.Parallel()
+
+	resp := &StateResponse{
+		Entries: []KeyValueStateEntry{
+			{
+				StateRoot: []byte{1},
+				Entries: []StateEntry{
+					{Key: []byte("cat"), Value: []byte("meow")},
+					{Key: []byte("dog"), Value: []byte("woof")},
+				},
+				Complete: true,
+			},
+		},
+		Proof: [][]byte{{2, 3}, {4}},
+	}
+
+	enc, err := resp.Encode()
+	require.NoError(t, err)
+
+	res := new(StateResponse)
+	err = res.Decode(enc)
+	require.NoError(t, err)
+	require.Equal(t, resp, res)
+}
@@ -0,0 +1,288 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	warpSyncID             = "/sync/warp"
+	warpSyncRequestTimeout = time.Second * 10
+)
+
+// MaxWarpSyncProofSize is the maximum encoded size of a warp sync proof
+var MaxWarpSyncProofSize = maxBlockResponseSize
+
+// WarpSyncRequest is a request for the proof of the GRANDPA authority set changes
+// which happened after the given block
+type WarpSyncRequest struct {
+	// Begin is the hash of the last finalised block known by the requester
+	Begin common.Hash
+}
+
+// SubProtocol returns the warp sync sub-protocol
+func (*WarpSyncRequest) SubProtocol() string {
+	return warpSyncID
+}
+
+// Encode encodes the request using SCALE
+func (r *WarpSyncRequest) Encode() ([]byte, error) {
+	return scale.Marshal(*r)
+}
+
+// Decode decodes the SCALE encoded request
+func (r *WarpSyncRequest) Decode(in []byte) error {
+	return scale.Unmarshal(in, r)
+}
+
+// String formats a WarpSyncRequest as a string
+func (r *WarpSyncRequest) String() string {
+	return fmt.Sprintf("WarpSyncRequest Begin=%s", r.Begin)
+}
+
+// WarpSyncFragment is a finalised block header along with its GRANDPA justification.
+// Every fragment of a proof but the last one contains an authority set change.
+type WarpSyncFragment struct {
+	Header types.Header
+	// Justification is the SCALE encoding of the GRANDPA justification, including the ancestries
+	// of its votes. Unlike other byte slices, it is encoded inline without a length prefix.
+	Justification []byte
+}
+
+// Encode encodes the fragment using SCALE
+func (f *WarpSyncFragment) Encode() ([]byte, error) {
+	enc, err := scale.Marshal(f.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(enc, f.Justification...), nil
+}
+
+// grandpaJustification is a GRANDPA justification without the ancestries of its votes,
+// which cannot be decoded without initialising the digests of their headers
+type grandpaJustification struct {
+	Round  uint64
+	Commit struct {
+		Hash       common.Hash
+		Number     uint32
+		Precommits []types.GrandpaSignedVote
+	}
+}
+
+// decodeGrandpaJustification decodes a GRANDPA justification, along with the ancestries of its votes
+func decodeGrandpaJustification(decoder *scale.Decoder) error {
+	var justification grandpaJustification
+	err := decoder.Decode(&justification)
+	if err != nil {
+		return err
+	}
+
+	var length uint
+	err = decoder.Decode(&length)
+	if err != nil {
+		return err
+	}
+
+	for i := uint(0); i < length; i++ {
+		err = decoder.Decode(types.NewEmptyHeader())
+		if err != nil {
+			return fmt.Errorf("cannot decode votes ancestry %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// WarpSyncProof is a response to a WarpSyncRequest
+type WarpSyncProof struct {
+	Fragments []WarpSyncFragment
+	// IsFinished is false if the proof was cut to respect the maximum proof size, in which
+	// case the proof of the following authority set changes must be requested.
+	IsFinished bool
+}
+
+// SubProtocol returns the warp sync sub-protocol
+func (*WarpSyncProof) SubProtocol() string {
+	return warpSyncID
+}
+
+// Encode encodes the proof using SCALE
+func (p *WarpSyncProof) Encode() ([]byte, error) {
+	enc, err := scale.Marshal(uint(len(p.Fragments)))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range p.Fragments {
+		fragment, err := p.Fragments[i].Encode()
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode fragment %d: %w", i, err)
+		}
+
+		enc = append(enc, fragment...)
+	}
+
+	isFinished, err := scale.Marshal(p.IsFinished)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(enc, isFinished...), nil
+}
+
+// Decode decodes the SCALE encoded proof
+func (p *WarpSyncProof) Decode(in []byte) error {
+	// the digests of the headers need to be initialised before decoding them
+	reader := bytes.NewReader(in)
+	decoder := scale.NewDecoder(reader)
+
+	var length uint
+	err := decoder.Decode(&length)
+	if err != nil {
+		return err
+	}
+
+	fragments := make([]WarpSyncFragment, 0, length)
+	for i := uint(0); i < length; i++ {
+		fragment := WarpSyncFragment{
+			Header: *types.NewEmptyHeader(),
+		}
+
+		err = decoder.Decode(&fragment.Header)
+		if err != nil {
+			return fmt.Errorf("cannot decode header of fragment %d: %w", i, err)
+		}
+
+		// the justification is kept encoded, so its bytes are the ones read while decoding it
+		start := len(in) - reader.Len()
+		err = decodeGrandpaJustification(decoder)
+		if err != nil {
+			return fmt.Errorf("cannot decode justification of fragment %d: %w", i, err)
+		}
+
+		fragment.Justification = append([]byte{}, in[start:len(in)-reader.Len()]...)
+		fragments = append(fragments, fragment)
+	}
+
+	var isFinished bool
+	err = decoder.Decode(&isFinished)
+	if err != nil {
+		return err
+	}
+
+	p.Fragments = fragments
+	p.IsFinished = isFinished
+	return nil
+}
+
+// String formats a WarpSyncProof as a string
+func (p *WarpSyncProof) String() string {
+	return fmt.Sprintf("WarpSyncProof Fragments=%d IsFinished=%t", len(p.Fragments), p.IsFinished)
+}
+
+// handleWarpSyncStream handles streams with the <protocol-id>/sync/warp protocol ID
+func (s *Service) handleWarpSyncStream(stream libp2pnetwork.Stream) {
+	if stream == nil {
+		return
+	}
+
+	s.readStream(stream, decodeWarpSyncRequest, s.handleWarpSyncMessage)
+}
+
+func decodeWarpSyncRequest(in []byte, _ peer.ID, _ bool) (Message, error) {
+	msg := new(WarpSyncRequest)
+	err := msg.Decode(in)
+	return msg, err
+}
+
+// handleWarpSyncMessage answers inbound warp sync requests
+func (s *Service) handleWarpSyncMessage(stream libp2pnetwork.Stream, msg Message) error {
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	req, ok := msg.(*WarpSyncRequest)
+	if !ok {
+		return nil
+	}
+
+	if s.warpSyncProvider == nil {
+		logger.Debugf("ignoring WarpSyncRequest from peer %s: warp sync requests are not served",
+			stream.Conn().RemotePeer())
+		return nil
+	}
+
+	proof, err := s.warpSyncProvider.CreateWarpSyncProof(req.Begin)
+	if err != nil {
+		logger.Debugf("cannot create warp sync proof for peer %s: %s", stream.Conn().RemotePeer(), err)
+		return nil
+	}
+
+	err = s.host.writeToStream(stream, proof)
+	if err != nil {
+		logger.Debugf("failed to send WarpSyncProof to peer %s: %s", stream.Conn().RemotePeer(), err)
+	}
+	return err
+}
+
+// DoWarpSyncRequest sends a warp sync request to the given peer.
+// If a response is received within a certain time period, it is returned,
+// otherwise an error is returned.
+func (s *Service) DoWarpSyncRequest(to peer.ID, req *WarpSyncRequest) (*WarpSyncProof, error) {
+	fullWarpSyncID := s.host.protocolID + warpSyncID
+
+	s.host.h.ConnManager().Protect(to, "")
+	defer s.host.h.ConnManager().Unprotect(to, "")
+
+	ctx, cancel := context.WithTimeout(s.ctx, warpSyncRequestTimeout)
+	defer cancel()
+
+	stream, err := s.host.h.NewStream(ctx, to, fullWarpSyncID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	if err = s.host.writeToStream(stream, req); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, MaxWarpSyncProofSize)
+	n, err := readStream(stream, &buf)
+	if err != nil {
+		return nil, fmt.Errorf("read stream error: %w", err)
+	}
+
+	if n == 0 {
+		return nil, fmt.Errorf("received empty message")
+	}
+
+	proof := new(WarpSyncProof)
+	err = proof.Decode(buf[:n])
+	if err != nil {
+		s.host.cm.peerSetHandler.ReportPeer(peerset.ReputationChange{
+			Value:  peerset.BadMessageValue,
+			Reason: peerset.BadMessageReason,
+		}, to)
+		return nil, fmt.Errorf("failed to decode warp sync proof: %w", err)
+	}
+
+	return proof, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package network
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeWarpSyncRequest(t *testing.T) {
+	t.Parallel()
+
+	req := &WarpSyncRequest{
+		Begin: common.Hash{1, 2, 3},
+	}
+
+	enc, err := req.Encode()
+	require.NoError(t, err)
+	require.Equal(t, req.Begin[:], enc)
+
+	res := new(WarpSyncRequest)
+	err = res.Decode(enc)
+	require.NoError(t, err)
+	require.Equal(t, req, res)
+}
+
+func TestEncodeWarpSyncProof(t *testing.T) {
+	t.Parallel()
+
+	digest := types.NewDigest()
+	err := digest.Add(types.PreRuntimeDigest{
+		ConsensusEngineID: types.BabeEngineID,
+		Data:              []byte{1, 2, 3},
+	})
+	require.NoError(t, err)
+
+	header, err := types.NewHeader(common.Hash{1}, common.Hash{2}, common.Hash{3}, 10, digest)
+	require.NoError(t, err)
+
+	justification := grandpaJustification{Round: 2}
+	justification.Commit.Hash = header.Hash()
+	justification.Commit.Number = 10
+	justification.Commit.Precommits = []types.GrandpaSignedVote{{
+		Vote:        types.GrandpaVote{Hash: header.Hash(), Number: 10},
+		Signature:   [64]byte{1},
+		AuthorityID: [32]byte{2},
+	}}
+	encJustification, err := scale.Marshal(justification)
+	require.NoError(t, err)
+
+	// justification with the votes ancestries, and one without
+	encHeader, err := scale.Marshal(*header)
+	require.NoError(t, err)
+	firstJustification := append(append(append([]byte{}, encJustification...), 4), encHeader...)
+	secondJustification := append(append([]byte{}, encJustification...), 0)
+
+	proof := &WarpSyncProof{
+		Fragments: []WarpSyncFragment{
+			{
+				Header:        *header,
+				Justification: firstJustification,
+			},
+			{
+				Header:        *types.NewEmptyHeader(),
+				Justification: secondJustification,
+			},
+		},
+		IsFinished: true,
+	}
+
+	enc, err := proof.Encode()
+	require.NoError(t, err)
+
+	// substrate encodes the justifications inline, without a length prefix
+	encEmptyHeader, err := scale.Marshal(*types.NewEmptyHeader())
+	require.NoError(t, err)
+	expected := []byte{8}
+	expected = append(expected, encHeader...)
+	expected = append(expected, firstJustification...)
+	expected = append(expected, encEmptyHeader...)
+	expected = append(expected, secondJustification...)
+	expected = append(expected, 1)
+	require.Equal(t, expected, enc)
+
+	res := new(WarpSyncProof)
+	err = res.Decode(enc)
+	require.NoError(t, err)
+	require.Equal(t, proof.IsFinished, res.IsFinished)
+	require.Len(t, res.Fragments, len(proof.Fragments))
+	for i := range proof.Fragments {
+		require.Equal(t, proof.Fragments[i].Header.Hash(), res.Fragments[i].Header.Hash())
+		require.Equal(t, proof.Fragments[i].Justification, res.Fragments[i].Justification)
+	}
+
+	err = res.Decode(enc[:len(enc)-1])
+	require.Error(t, err)
+}
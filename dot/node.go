@@ -14,6 +14,7 @@
 	"syscall"
 	"time"
 
+	"github.com/ChainSafe/gossamer/dot/light"
 	"github.com/ChainSafe/gossamer/dot/network"
 	"github.com/ChainSafe/gossamer/dot/rpc"
 	"github.com/ChainSafe/gossamer/dot/state"
@@ -307,9 +308,24 @@ func NewNode(cfg *Config, ks *keystore.GlobalKeystore) (*Node, error) {
 	if networkSrvc != nil {
 		networkSrvc.SetSyncer(syncer)
 		networkSrvc.SetTransactionHandler(coreSrvc)
+		networkSrvc.SetWarpSyncProvider(fg)
+
+		// light clients do not have the state required to answer light client requests
+		if cfg.Core.Roles != types.LightClientRole {
+			networkSrvc.SetLightHandler(coreSrvc)
+		}
 	}
 	nodeSrvcs = append(nodeSrvcs, syncer)
 
+	var lightClient *light.Client
+	if cfg.Core.Roles == types.LightClientRole {
+		lightClient, err = createLightClient(cfg, stateSrvc, networkSrvc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create light client: %w", err)
+		}
+		nodeSrvcs = append(nodeSrvcs, lightClient)
+	}
+
 	bp, err := createBABEService(cfg, stateSrvc, ks.Babe, coreSrvc, telemetryMailer)
 	if err != nil {
 		return nil, err
@@ -329,6 +345,7 @@ func NewNode(cfg *Config, ks *keystore.GlobalKeystore) (*Node, error) {
 			system:        sysSrvc,
 			blockFinality: fg,
 			syncer:        syncer,
+			lightClient:   lightClient,
 		}
 		rpcSrvc, err = createRPCService(cRPCParams)
 		if err != nil {
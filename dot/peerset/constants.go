@@ -67,4 +67,25 @@
 	GenesisMismatch Reputation = math.MinInt32
 	// GenesisMismatchReason used when a peer has a different genesis
 	GenesisMismatchReason = "Genesis mismatch"
+
+	// BadProofValue is used when a peer sends a light client response with an invalid proof.
+	BadProofValue Reputation = -(1 << 16)
+	// BadProofReason is used when a peer sends a light client response with an invalid proof.
+	BadProofReason = "Bad proof"
+
+	// BadBlockResponseValue is used when a peer sends an empty or incomplete block response,
+	// or one which doesn't form a chain.
+	BadBlockResponseValue Reputation = -(1 << 12)
+	// BadBlockResponseReason is used when a peer sends an invalid block response.
+	BadBlockResponseReason = "Bad block response"
+
+	// BadWarpSyncProofValue is used when a peer sends a warp sync proof which cannot be verified.
+	BadWarpSyncProofValue Reputation = -(1 << 16)
+	// BadWarpSyncProofReason is used when a peer sends an invalid warp sync proof.
+	BadWarpSyncProofReason = "Bad warp sync proof"
+
+	// BadStateResponseValue is used when a peer sends a state response with a proof which cannot be verified.
+	BadStateResponseValue Reputation = -(1 << 12)
+	// BadStateResponseReason is used when a peer sends an invalid state response.
+	BadStateResponseReason = "Bad state response"
 )
@@ -17,4 +17,6 @@
 	ErrOutgoingSlotsUnavailable = errors.New("not enough outgoing slots")
 
 	ErrIncomingSlotsUnavailable = errors.New("not enough incoming slots")
+
+	ErrSetDoesNotExist = errors.New("set doesn't exist")
 )
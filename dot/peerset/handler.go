@@ -57,6 +57,15 @@ func (h *Handler) SetReservedPeer(setID int, peers ...peer.ID) {
 	}
 }
 
+// SetReservedOnly sets whether the peerSet only connects to reserved peers.
+func (h *Handler) SetReservedOnly(setID int, reservedOnly bool) {
+	h.actionQueue <- action{
+		actionCall:   setReservedOnly,
+		setID:        setID,
+		reservedOnly: reservedOnly,
+	}
+}
+
 // AddPeer adds peer to peerSet.
 func (h *Handler) AddPeer(setID int, peers ...peer.ID) {
 	h.actionQueue <- action{
@@ -38,7 +38,7 @@
 	removeReservedPeer
 	// setReservedPeers is for setting peerList in peerSet reserved peers
 	setReservedPeers
-	// setReservedOnly is for setting peerList in peerSet reserved peers only
+	// setReservedOnly is for setting whether the peerSet only connects to reserved peers
 	setReservedOnly
 	// reportPeer is for reporting peers if it misbehaves
 	reportPeer
@@ -86,6 +86,7 @@ type action struct {
 	actionCall    ActionReceiver
 	setID         int
 	reputation    ReputationChange
+	reservedOnly  bool
 	peers         peer.IDSlice
 	resultPeersCh chan peer.IDSlice
 }
@@ -122,6 +123,11 @@ type Message struct {
 	PeerID peer.ID
 }
 
+// SetID returns the id of the set the message is about.
+func (m Message) SetID() int {
+	return int(m.setID)
+}
+
 // Reputation represents reputation value of the node
 type Reputation int32
 
@@ -165,10 +171,10 @@ func newReputationChange(value Reputation, reason string) ReputationChange {
 type PeerSet struct {
 	peerState *PeersState
 
-	reservedNode map[peer.ID]struct{}
-	// TODO: this will be useful for reserved only mode
-	// this is for future purpose if reserved-only flag is enabled (#1888).
-	isReservedOnly bool
+	// reserved nodes of each set.
+	reservedNode []map[peer.ID]struct{}
+	// for each set, if true, only the reserved nodes are connected, and the other nodes are rejected.
+	isReservedOnly []bool
 	resultMsgCh    chan Message
 	// time when the PeerSet was created.
 	created time.Time
@@ -188,8 +194,7 @@ type config struct {
 	// maximum number of slot occupying nodes for outgoing connections.
 	maxOutPeers uint32
 
-	// TODO Use in future for reserved only peers
-	// if true, we only accept reservedNodes (#1888).
+	// if true, we only accept reservedNodes.
 	reservedOnly bool
 
 	// time duration for a peerSet to periodically call allocSlots.
@@ -211,12 +216,22 @@ func NewConfigSet(maxInPeers, maxOutPeers uint32, reservedOnly bool, allocTime t
 	}
 
 	return &ConfigSet{
-		// Why are we using an array of config in the set, when we are
-		// using just one config
 		Set: []*config{set},
 	}
 }
 
+// AddSet adds a set with its own slots to the config set, and returns the id of the set.
+func (c *ConfigSet) AddSet(maxInPeers, maxOutPeers uint32, reservedOnly bool) int {
+	c.Set = append(c.Set, &config{
+		maxInPeers:        maxInPeers,
+		maxOutPeers:       maxOutPeers,
+		reservedOnly:      reservedOnly,
+		periodicAllocTime: c.Set[0].periodicAllocTime,
+	})
+
+	return len(c.Set) - 1
+}
+
 func newPeerSet(cfg *ConfigSet) (*PeerSet, error) {
 	if len(cfg.Set) == 0 {
 		return nil, ErrConfigSetIsEmpty
@@ -227,17 +242,21 @@ func newPeerSet(cfg *ConfigSet) (*PeerSet, error) {
 		return nil, err
 	}
 
-	// TODO: currently we only have one set, change this once we have more (#1886).
-	cfgSet := cfg.Set[0]
-	now := time.Now()
+	reservedNode := make([]map[peer.ID]struct{}, len(cfg.Set))
+	isReservedOnly := make([]bool, len(cfg.Set))
+	for i, cfgSet := range cfg.Set {
+		reservedNode[i] = make(map[peer.ID]struct{})
+		isReservedOnly[i] = cfgSet.reservedOnly
+	}
 
+	now := time.Now()
 	ps := &PeerSet{
 		peerState:              peerState,
-		reservedNode:           make(map[peer.ID]struct{}),
-		isReservedOnly:         cfgSet.reservedOnly,
+		reservedNode:           reservedNode,
+		isReservedOnly:         isReservedOnly,
 		created:                now,
 		latestTimeUpdate:       now,
-		nextPeriodicAllocSlots: cfgSet.periodicAllocTime,
+		nextPeriodicAllocSlots: cfg.Set[0].periodicAllocTime,
 	}
 
 	return ps, nil
@@ -361,7 +380,7 @@ func (ps *PeerSet) allocSlots(setIdx int) error {
 	}
 
 	peerState := ps.peerState
-	for reservePeer := range ps.reservedNode {
+	for reservePeer := range ps.reservedNode[setIdx] {
 		status := peerState.peerStatus(setIdx, reservePeer)
 		switch status {
 		case connectedPeer:
@@ -394,7 +413,7 @@ func (ps *PeerSet) allocSlots(setIdx int) error {
 	}
 
 	// nothing more to do if we're in reserved mode.
-	if ps.isReservedOnly {
+	if ps.isReservedOnly[setIdx] {
 		return nil
 	}
 
@@ -428,14 +447,14 @@ func (ps *PeerSet) allocSlots(setIdx int) error {
 
 func (ps *PeerSet) addReservedPeers(setID int, peers ...peer.ID) error {
 	for _, peerID := range peers {
-		if _, ok := ps.reservedNode[peerID]; ok {
+		if _, ok := ps.reservedNode[setID][peerID]; ok {
 			logger.Debugf("peer %s already exists in peerSet", peerID)
 			return nil
 		}
 
 		ps.peerState.discover(setID, peerID)
 
-		ps.reservedNode[peerID] = struct{}{}
+		ps.reservedNode[setID][peerID] = struct{}{}
 		if err := ps.peerState.addNoSlotNode(setID, peerID); err != nil {
 			return fmt.Errorf("could not add to list of no-slot nodes: %w", err)
 		}
@@ -448,22 +467,21 @@ func (ps *PeerSet) addReservedPeers(setID int, peers ...peer.ID) error {
 
 func (ps *PeerSet) removeReservedPeers(setID int, peers ...peer.ID) error {
 	for _, peerID := range peers {
-		if _, ok := ps.reservedNode[peerID]; !ok {
+		if _, ok := ps.reservedNode[setID][peerID]; !ok {
 			logger.Debugf("peer %s doesn't exist in the peerSet", peerID)
-			return nil
+			continue
 		}
 
-		delete(ps.reservedNode, peerID)
+		delete(ps.reservedNode[setID], peerID)
 		if err := ps.peerState.removeNoSlotNode(setID, peerID); err != nil {
 			return fmt.Errorf("could not remove from the list of no-slot nodes: %w", err)
 		}
 
 		// nothing more to do if not in reservedOnly mode.
-		if !ps.isReservedOnly {
-			return nil
+		if !ps.isReservedOnly[setID] {
+			continue
 		}
 
-		// reservedOnly mode is not yet implemented for future this code will help.
 		// If however the peerSet is in reserved-only mode, then non-reserved node peers needs to be
 		// disconnected.
 		if ps.peerState.peerStatus(setID, peerID) == connectedPeer {
@@ -488,13 +506,13 @@ func (ps *PeerSet) setReservedPeer(setID int, peers ...peer.ID) error {
 	peerIDMap := make(map[peer.ID]struct{}, len(peers))
 	for _, pid := range peers {
 		peerIDMap[pid] = struct{}{}
-		if _, ok := ps.reservedNode[pid]; ok {
+		if _, ok := ps.reservedNode[setID][pid]; ok {
 			continue
 		}
 		toInsert = append(toInsert, pid)
 	}
 
-	for pid := range ps.reservedNode {
+	for pid := range ps.reservedNode[setID] {
 		if _, ok := peerIDMap[pid]; ok {
 			continue
 		}
@@ -508,6 +526,38 @@ func (ps *PeerSet) setReservedPeer(setID int, peers ...peer.ID) error {
 	return ps.removeReservedPeers(setID, toRemove...)
 }
 
+// setReservedOnly sets whether only the reserved nodes are connected. When enabled, the connected
+// nodes which are not reserved are disconnected. When disabled, the free slots are allocated again.
+func (ps *PeerSet) setReservedOnly(setID int, reservedOnly bool) error {
+	ps.isReservedOnly[setID] = reservedOnly
+	if !reservedOnly {
+		return ps.allocSlots(setID)
+	}
+
+	for _, pid := range ps.peerState.peers() {
+		if _, ok := ps.reservedNode[setID][pid]; ok {
+			continue
+		}
+
+		if ps.peerState.peerStatus(setID, pid) != connectedPeer {
+			continue
+		}
+
+		err := ps.peerState.disconnect(setID, pid)
+		if err != nil {
+			return err
+		}
+
+		ps.resultMsgCh <- Message{
+			Status: Drop,
+			setID:  uint64(setID),
+			PeerID: pid,
+		}
+	}
+
+	return nil
+}
+
 func (ps *PeerSet) addPeer(setID int, peers peer.IDSlice) error {
 	for _, pid := range peers {
 		if ps.peerState.peerStatus(setID, pid) != unknownPeer {
@@ -524,7 +574,7 @@ func (ps *PeerSet) addPeer(setID int, peers peer.IDSlice) error {
 
 func (ps *PeerSet) removePeer(setID int, peers ...peer.ID) error {
 	for _, pid := range peers {
-		if _, ok := ps.reservedNode[pid]; ok {
+		if _, ok := ps.reservedNode[setID][pid]; ok {
 			logger.Debugf("peer %s is reserved and cannot be removed", pid)
 			return nil
 		}
@@ -562,10 +612,10 @@ func (ps *PeerSet) incoming(setID int, peers ...peer.ID) error {
 		return err
 	}
 
-	// This is for reserved only mode.
 	for _, pid := range peers {
-		if ps.isReservedOnly {
-			if _, ok := ps.reservedNode[pid]; !ok {
+		// in reserved-only mode, the nodes which are not reserved are rejected.
+		if ps.isReservedOnly[setID] {
+			if _, ok := ps.reservedNode[setID][pid]; !ok {
 				ps.resultMsgCh <- Message{
 					Status: Reject,
 					setID:  uint64(setID),
@@ -697,6 +747,14 @@ func (ps *PeerSet) doWork(ctx context.Context) {
 				return
 			}
 
+			if act.setID < 0 || act.setID >= ps.peerState.getSetLength() {
+				logger.Errorf("failed to do action %s on peerSet: %s", act, ErrSetDoesNotExist)
+				if act.resultPeersCh != nil {
+					close(act.resultPeersCh)
+				}
+				continue
+			}
+
 			var err error
 			switch act.actionCall {
 			case addReservedPeer:
@@ -707,8 +765,7 @@ func (ps *PeerSet) doWork(ctx context.Context) {
 				// TODO: this is not used yet, might required to implement RPC Call for this.
 				err = ps.setReservedPeer(act.setID, act.peers...)
 			case setReservedOnly:
-				// TODO: not yet implemented (#1888)
-				err = fmt.Errorf("not implemented yet")
+				err = ps.setReservedOnly(act.setID, act.reservedOnly)
 			case reportPeer:
 				err = ps.reportPeer(act.reputation, act.peers...)
 			case addToPeerSet:
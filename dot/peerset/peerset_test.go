@@ -4,6 +4,7 @@
 package peerset
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -178,8 +179,70 @@ func TestSetReservePeer(t *testing.T) {
 	handler.SetReservedPeer(0, newRsrPeerSet...)
 	time.Sleep(200 * time.Millisecond)
 
-	require.Equal(t, len(newRsrPeerSet), len(ps.reservedNode))
+	require.Equal(t, len(newRsrPeerSet), len(ps.reservedNode[0]))
 	for _, p := range newRsrPeerSet {
-		require.Contains(t, ps.reservedNode, p)
+		require.Contains(t, ps.reservedNode[0], p)
 	}
 }
+
+func TestSetReservedOnly(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestPeerSet(t, 0, 2, []peer.ID{discovered1}, []peer.ID{reservedPeer}, false)
+	ps := handler.peerSet
+
+	require.Equal(t, 2, len(ps.resultMsgCh))
+	for len(ps.resultMsgCh) != 0 {
+		checkMessageStatus(t, <-ps.resultMsgCh, Connect)
+	}
+
+	// the connected peers which are not reserved are dropped.
+	handler.SetReservedOnly(0, true)
+	time.Sleep(100 * time.Millisecond)
+
+	require.Equal(t, 1, len(ps.resultMsgCh))
+	require.Equal(t, Message{Status: Drop, setID: 0, PeerID: discovered1}, <-ps.resultMsgCh)
+	require.Equal(t, connectedPeer, ps.peerState.peerStatus(0, reservedPeer))
+
+	handler.Incoming(0, incomingPeer)
+	require.Equal(t, Message{Status: Reject, setID: 0, PeerID: incomingPeer}, <-ps.resultMsgCh)
+
+	// the free slots are allocated again.
+	handler.SetReservedOnly(0, false)
+	require.Equal(t, Message{Status: Connect, setID: 0, PeerID: discovered1}, <-ps.resultMsgCh)
+}
+
+func TestMultipleSets(t *testing.T) {
+	t.Parallel()
+
+	cfg := NewConfigSet(0, 0, false, time.Second*2)
+	setID := cfg.AddSet(0, 0, false)
+	require.Equal(t, 1, setID)
+
+	handler, err := NewPeerSetHandler(cfg)
+	require.NoError(t, err)
+	handler.Start(context.Background())
+	ps := handler.peerSet
+
+	// the reserved peer of the second set doesn't need a slot.
+	handler.AddReservedPeer(setID, reservedPeer)
+	require.Equal(t, Message{Status: Connect, setID: 1, PeerID: reservedPeer}, <-ps.resultMsgCh)
+
+	// the slots of the first set are full, but the peer is reserved in the second set.
+	handler.Incoming(0, reservedPeer2)
+	handler.Incoming(setID, reservedPeer2)
+	require.Equal(t, Message{Status: Reject, setID: 0, PeerID: reservedPeer2}, <-ps.resultMsgCh)
+	require.Equal(t, Message{Status: Reject, setID: 1, PeerID: reservedPeer2}, <-ps.resultMsgCh)
+
+	handler.AddReservedPeer(setID, reservedPeer2)
+	require.Equal(t, Message{Status: Connect, setID: 1, PeerID: reservedPeer2}, <-ps.resultMsgCh)
+	require.Equal(t, connectedPeer, ps.peerState.peerStatus(setID, reservedPeer2))
+	require.Equal(t, notConnectedPeer, ps.peerState.peerStatus(0, reservedPeer2))
+
+	// actions on sets which don't exist are ignored.
+	handler.AddReservedPeer(2, peer1)
+	handler.Incoming(0, peer1)
+	msg := <-ps.resultMsgCh
+	require.Equal(t, Message{Status: Reject, setID: 0, PeerID: peer1}, msg)
+	require.Equal(t, 0, msg.SetID())
+}
@@ -0,0 +1,281 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+)
+
+// ReplayDivergence is a replayed block whose execution doesn't result in the state of its header
+type ReplayDivergence struct {
+	Number uint
+	Hash   common.Hash
+	// ExpectedRoot is the state root of the block header, and Root is the state root after the replay
+	ExpectedRoot common.Hash
+	Root         common.Hash
+	// Key is the first storage key with a different value, it is nil if the expected state isn't stored
+	Key           []byte
+	ExpectedValue []byte
+	Value         []byte
+	// Err is the error returned by the execution of the block, if it failed
+	Err error
+}
+
+func (d *ReplayDivergence) String() string {
+	s := fmt.Sprintf("block %d (%s): expected state root %s, got %s", d.Number, d.Hash, d.ExpectedRoot, d.Root)
+	if d.Key != nil {
+		s += fmt.Sprintf(", first differing key 0x%x: expected value 0x%x, got 0x%x", d.Key, d.ExpectedValue, d.Value)
+	}
+	if d.Err != nil {
+		s += fmt.Sprintf(", execution failed: %s", d.Err)
+	}
+	return s
+}
+
+// Replay re-executes the blocks of the best chain from number `from` to number `to` on the state of their
+// parent, stored in the node databases of the configuration, and returns the blocks whose execution doesn't
+// result in the state root of their header. The blocks are executed with the runtime code of their parent
+// state, or with the given code if it isn't nil.
+func Replay(cfg *Config, from, to uint, code []byte) ([]*ReplayDivergence, error) {
+	if from == 0 {
+		return nil, fmt.Errorf("cannot replay the genesis block")
+	}
+	if to < from {
+		return nil, fmt.Errorf("last block %d is before first block %d", to, from)
+	}
+
+	executor, err := newBlockExecutor(cfg, code)
+	if err != nil {
+		return nil, err
+	}
+	defer executor.stop()
+
+	var divergences []*ReplayDivergence
+	for number := from; number <= to; number++ {
+		hash, err := executor.stateSrvc.Block.GetHashByNumber(number)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get hash of block %d: %w", number, err)
+		}
+
+		block, err := executor.stateSrvc.Block.GetBlockByHash(hash)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get block %s: %w", hash, err)
+		}
+
+		divergence, err := executor.replay(block)
+		if err != nil {
+			return nil, err
+		}
+
+		if divergence != nil {
+			logger.Warnf("replayed block %d diverges: %s", number, divergence)
+			divergences = append(divergences, divergence)
+			continue
+		}
+
+		logger.Infof("replayed block %d with hash %s", number, hash)
+	}
+
+	return divergences, nil
+}
+
+// replayBlockState is the block state used to replay blocks
+type replayBlockState interface {
+	GetBlockStateRoot(bhash common.Hash) (common.Hash, error)
+}
+
+// replayStorageState is the storage state used to replay blocks
+type replayStorageState interface {
+	TrieState(root *common.Hash) (*rtstorage.TrieState, error)
+}
+
+// blockExecutor executes the blocks stored in the node databases on the state of their parent
+type blockExecutor struct {
+	cfg          *Config
+	stateSrvc    *state.Service
+	blockState   replayBlockState
+	storageState replayStorageState
+	ns           *runtime.NodeStorage
+	// code overrides the runtime code of the parent states if it isn't nil
+	code []byte
+	// newInstance creates the runtime instances executing the blocks
+	newInstance func(code []byte, instanceCfg runtime.InstanceConfig) (runtime.Instance, error)
+
+	rt       runtime.Instance
+	codeHash common.Hash
+}
+
+func newBlockExecutor(cfg *Config, code []byte) (*blockExecutor, error) {
+	stateSrvc, err := createStateService(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state service: %w", err)
+	}
+
+	err = stateSrvc.Start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start state service: %w", err)
+	}
+
+	ns, err := createRuntimeStorage(stateSrvc)
+	if err != nil {
+		_ = stateSrvc.Stop()
+		return nil, fmt.Errorf("failed to create runtime storage: %w", err)
+	}
+
+	return &blockExecutor{
+		cfg:          cfg,
+		stateSrvc:    stateSrvc,
+		blockState:   stateSrvc.Block,
+		storageState: stateSrvc.Storage,
+		ns:           ns,
+		code:         code,
+		newInstance: func(code []byte, instanceCfg runtime.InstanceConfig) (runtime.Instance, error) {
+			return newRuntimeInstance(cfg, code, instanceCfg)
+		},
+	}, nil
+}
+
+func (e *blockExecutor) stop() {
+	if e.rt != nil {
+		e.rt.Stop()
+	}
+
+	if err := e.stateSrvc.Stop(); err != nil {
+		logger.Errorf("failed to stop state service: %s", err)
+	}
+}
+
+// parentState returns the state of the parent of the block
+func (e *blockExecutor) parentState(block *types.Block) (*rtstorage.TrieState, error) {
+	parentHash := block.Header.ParentHash
+	stateRoot, err := e.blockState.GetBlockStateRoot(parentHash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state root for block %s: %w", parentHash, err)
+	}
+
+	ts, err := e.storageState.TrieState(&stateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get trie state for block %s: %w", parentHash, err)
+	}
+
+	return ts, nil
+}
+
+// runtime returns an instance of the runtime code of the state, or of the override code.
+// The instance is reused as long as the code doesn't change.
+func (e *blockExecutor) runtime(ts *rtstorage.TrieState) (runtime.Instance, error) {
+	code := e.code
+	if code == nil {
+		code = ts.LoadCode()
+	}
+
+	codeHash, err := common.Blake2bHash(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.rt != nil && codeHash == e.codeHash {
+		return e.rt, nil
+	}
+
+	instanceCfg := runtime.InstanceConfig{
+		Storage:     ts,
+		LogLvl:      e.cfg.Log.RuntimeLvl,
+		NodeStorage: *e.ns,
+		CodeHash:    codeHash,
+	}
+
+	rt, err := e.newInstance(code, instanceCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.rt != nil {
+		e.rt.Stop()
+	}
+	e.rt, e.codeHash = rt, codeHash
+	return rt, nil
+}
+
+// replay executes the block on the state of its parent, and returns how the resulting state diverges
+// from the state of the block header, or nil if it doesn't.
+func (e *blockExecutor) replay(block *types.Block) (*ReplayDivergence, error) {
+	ts, err := e.parentState(block)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := e.runtime(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	rt.SetContextStorage(ts)
+	// the runtime checks the state root of the header, so an execution error may be a state divergence,
+	// in which case the state holds the changes made by the block up to the check.
+	_, execErr := rt.ExecuteBlock(block)
+
+	root, err := ts.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	expectedRoot := block.Header.StateRoot
+	if execErr == nil && root == expectedRoot {
+		return nil, nil
+	}
+
+	divergence := &ReplayDivergence{
+		Number:       block.Header.Number,
+		Hash:         block.Header.Hash(),
+		ExpectedRoot: expectedRoot,
+		Root:         root,
+		Err:          execErr,
+	}
+
+	if root == expectedRoot {
+		return divergence, nil
+	}
+
+	expected, err := e.storageState.TrieState(&expectedRoot)
+	if err != nil {
+		logger.Warnf("cannot load the state of block %d to compare it: %s", block.Header.Number, err)
+		return divergence, nil
+	}
+
+	divergence.Key, divergence.ExpectedValue, divergence.Value = firstDifference(expected.Trie(), ts.Trie())
+	return divergence, nil
+}
+
+// firstDifference returns the first key, in lexicographic order, which has a different value
+// in the two tries, with its values. It returns a nil key if the tries hold the same entries.
+// The empty key isn't compared.
+func firstDifference(expected, actual *trie.Trie) (key, expectedValue, value []byte) {
+	expectedKey, actualKey := expected.NextKey(nil), actual.NextKey(nil)
+	for expectedKey != nil || actualKey != nil {
+		switch {
+		case actualKey == nil || expectedKey != nil && bytes.Compare(expectedKey, actualKey) < 0:
+			return expectedKey, expected.Get(expectedKey), nil
+		case expectedKey == nil || bytes.Compare(actualKey, expectedKey) < 0:
+			return actualKey, nil, actual.Get(actualKey)
+		}
+
+		expectedValue, value = expected.Get(expectedKey), actual.Get(actualKey)
+		if !bytes.Equal(expectedValue, value) {
+			return expectedKey, expectedValue, value
+		}
+
+		expectedKey, actualKey = expected.NextKey(expectedKey), actual.NextKey(actualKey)
+	}
+
+	return nil, nil, nil
+}
@@ -0,0 +1,308 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTrie(entries map[string]string) *trie.Trie {
+	tr := trie.NewEmptyTrie()
+	for k, v := range entries {
+		tr.Put([]byte(k), []byte(v))
+	}
+	return tr
+}
+
+// replayTestState maps the block hashes to their state roots, and the state roots to their trie
+type replayTestState struct {
+	roots map[common.Hash]common.Hash
+	tries map[common.Hash]*trie.Trie
+}
+
+func newReplayTestState() *replayTestState {
+	return &replayTestState{
+		roots: make(map[common.Hash]common.Hash),
+		tries: make(map[common.Hash]*trie.Trie),
+	}
+}
+
+// addBlock stores the state of the block with the given hash and returns its root
+func (s *replayTestState) addBlock(hash common.Hash, entries map[string]string) common.Hash {
+	root := s.addState(entries)
+	s.roots[hash] = root
+	return root
+}
+
+// addState stores the state and returns its root
+func (s *replayTestState) addState(entries map[string]string) common.Hash {
+	tr := newTrie(entries)
+	root := tr.MustHash()
+	s.tries[root] = tr
+	return root
+}
+
+func (s *replayTestState) GetBlockStateRoot(bhash common.Hash) (common.Hash, error) {
+	root, has := s.roots[bhash]
+	if !has {
+		return common.Hash{}, errors.New("block not found")
+	}
+	return root, nil
+}
+
+func (s *replayTestState) TrieState(root *common.Hash) (*rtstorage.TrieState, error) {
+	tr, has := s.tries[*root]
+	if !has {
+		return nil, errors.New("state not found")
+	}
+	return rtstorage.NewTrieState(tr.Snapshot())
+}
+
+// newReplayTestExecutor returns a block executor running the blocks with the given instance,
+// along with the codes the instances were created with
+func newReplayTestExecutor(st *replayTestState, rt runtime.Instance, code []byte) (*blockExecutor, *[][]byte) {
+	var codes [][]byte
+	return &blockExecutor{
+		cfg:          &Config{},
+		blockState:   st,
+		storageState: st,
+		ns:           &runtime.NodeStorage{},
+		code:         code,
+		newInstance: func(code []byte, _ runtime.InstanceConfig) (runtime.Instance, error) {
+			codes = append(codes, code)
+			return rt, nil
+		},
+	}, &codes
+}
+
+// newReplayTestInstance returns an instance whose execution of the block sets the key to the value,
+// and returns the given error
+func newReplayTestInstance(block *types.Block, key, value string, execErr error) *mocks.Instance {
+	var ts *rtstorage.TrieState
+	rt := new(mocks.Instance)
+	rt.On("SetContextStorage", mock.AnythingOfType("*storage.TrieState")).Run(func(args mock.Arguments) {
+		ts = args.Get(0).(*rtstorage.TrieState)
+	})
+	rt.On("ExecuteBlock", block).Run(func(mock.Arguments) {
+		ts.Set([]byte(key), []byte(value))
+	}).Return(nil, execErr)
+	return rt
+}
+
+func Test_blockExecutor_replay(t *testing.T) {
+	parentHash := common.Hash{1}
+	parentState := map[string]string{":code": "code", "a": "1"}
+	expectedState := map[string]string{":code": "code", "a": "2"}
+	errTest := errors.New("test error")
+
+	tests := map[string]struct {
+		value         string
+		execErr       error
+		storeExpected bool
+		divergence    func(block *types.Block) *ReplayDivergence
+	}{
+		"same state": {
+			value:         "2",
+			storeExpected: true,
+		},
+		"different value": {
+			value:         "3",
+			storeExpected: true,
+			divergence: func(block *types.Block) *ReplayDivergence {
+				return &ReplayDivergence{
+					Number:        1,
+					Hash:          block.Header.Hash(),
+					ExpectedRoot:  block.Header.StateRoot,
+					Root:          newTrie(map[string]string{":code": "code", "a": "3"}).MustHash(),
+					Key:           []byte("a"),
+					ExpectedValue: []byte("2"),
+					Value:         []byte("3"),
+				}
+			},
+		},
+		"expected state not stored": {
+			value: "3",
+			divergence: func(block *types.Block) *ReplayDivergence {
+				return &ReplayDivergence{
+					Number:       1,
+					Hash:         block.Header.Hash(),
+					ExpectedRoot: block.Header.StateRoot,
+					Root:         newTrie(map[string]string{":code": "code", "a": "3"}).MustHash(),
+				}
+			},
+		},
+		"execution error": {
+			value:         "2",
+			execErr:       errTest,
+			storeExpected: true,
+			divergence: func(block *types.Block) *ReplayDivergence {
+				return &ReplayDivergence{
+					Number:       1,
+					Hash:         block.Header.Hash(),
+					ExpectedRoot: block.Header.StateRoot,
+					Root:         block.Header.StateRoot,
+					Err:          errTest,
+				}
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			st := newReplayTestState()
+			st.addBlock(parentHash, parentState)
+
+			expectedRoot := newTrie(expectedState).MustHash()
+			if test.storeExpected {
+				st.addState(expectedState)
+			}
+
+			block := &types.Block{
+				Header: types.Header{
+					ParentHash: parentHash,
+					Number:     1,
+					StateRoot:  expectedRoot,
+					Digest:     types.NewDigest(),
+				},
+			}
+
+			rt := newReplayTestInstance(block, "a", test.value, test.execErr)
+			executor, _ := newReplayTestExecutor(st, rt, nil)
+
+			divergence, err := executor.replay(block)
+			require.NoError(t, err)
+			rt.AssertExpectations(t)
+
+			if test.divergence == nil {
+				require.Nil(t, divergence)
+				return
+			}
+			require.Equal(t, test.divergence(block), divergence)
+		})
+	}
+}
+
+func Test_blockExecutor_replay_parentStateNotStored(t *testing.T) {
+	block := &types.Block{
+		Header: types.Header{
+			ParentHash: common.Hash{1},
+			Number:     1,
+			Digest:     types.NewDigest(),
+		},
+	}
+
+	executor, codes := newReplayTestExecutor(newReplayTestState(), nil, nil)
+	_, err := executor.replay(block)
+	require.EqualError(t, err, "cannot get state root for block "+common.Hash{1}.String()+": block not found")
+	require.Empty(t, *codes)
+}
+
+func Test_blockExecutor_runtime(t *testing.T) {
+	parentHash := common.Hash{1}
+	state := map[string]string{":code": "code", "a": "1"}
+
+	tests := map[string]struct {
+		code  []byte
+		codes [][]byte
+	}{
+		"code of the parent state": {
+			codes: [][]byte{[]byte("code")},
+		},
+		"runtime override": {
+			code:  []byte("override"),
+			codes: [][]byte{[]byte("override")},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			st := newReplayTestState()
+			root := st.addBlock(parentHash, state)
+
+			block := &types.Block{
+				Header: types.Header{
+					ParentHash: parentHash,
+					Number:     1,
+					StateRoot:  root,
+					Digest:     types.NewDigest(),
+				},
+			}
+
+			rt := newReplayTestInstance(block, "a", "1", nil)
+			executor, codes := newReplayTestExecutor(st, rt, test.code)
+
+			// the instance is reused to replay blocks with the same code
+			for i := 0; i < 2; i++ {
+				divergence, err := executor.replay(block)
+				require.NoError(t, err)
+				require.Nil(t, divergence)
+			}
+
+			require.Equal(t, test.codes, *codes)
+			rt.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_firstDifference(t *testing.T) {
+	tests := map[string]struct {
+		expected      map[string]string
+		actual        map[string]string
+		key           []byte
+		expectedValue []byte
+		value         []byte
+	}{
+		"same entries": {
+			expected: map[string]string{"a": "1", "b": "2"},
+			actual:   map[string]string{"a": "1", "b": "2"},
+		},
+		"different value": {
+			expected:      map[string]string{"a": "1", "b": "2", "c": "3"},
+			actual:        map[string]string{"a": "1", "b": "4", "c": "5"},
+			key:           []byte("b"),
+			expectedValue: []byte("2"),
+			value:         []byte("4"),
+		},
+		"missing key": {
+			expected:      map[string]string{"a": "1", "b": "2", "c": "3"},
+			actual:        map[string]string{"a": "1", "c": "3"},
+			key:           []byte("b"),
+			expectedValue: []byte("2"),
+		},
+		"extra key": {
+			expected: map[string]string{"a": "1"},
+			actual:   map[string]string{"a": "1", "ab": "2"},
+			key:      []byte("ab"),
+			value:    []byte("2"),
+		},
+		"empty trie": {
+			expected:      map[string]string{"a": "1"},
+			actual:        map[string]string{},
+			key:           []byte("a"),
+			expectedValue: []byte("1"),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			key, expectedValue, value := firstDifference(newTrie(test.expected), newTrie(test.actual))
+			require.Equal(t, test.key, key)
+			require.Equal(t, test.expectedValue, expectedValue)
+			require.Equal(t, test.value, value)
+		})
+	}
+}
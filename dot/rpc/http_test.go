@@ -203,6 +203,43 @@ func TestRPCUnsafeExpose(t *testing.T) {
 	require.Equal(t, expected, string(resBody))
 }
 
+func TestSetReservedOnly(t *testing.T) {
+	netmock := new(mocks.NetworkAPI)
+	netmock.On("SetReservedOnly", true).Once()
+	// disabling the reserved-only mode is left to the network service, which keeps the peer sets
+	// configured to only allow their reserved peers in the reserved-only mode
+	netmock.On("SetReservedOnly", false).Once()
+
+	cfg := &HTTPServerConfig{
+		Modules:    []string{"system"},
+		RPCPort:    7881,
+		RPCAPI:     NewService(),
+		RPCUnsafe:  true,
+		NetworkAPI: netmock,
+	}
+
+	s := NewHTTPServer(cfg)
+	err := s.Start()
+	require.NoError(t, err)
+
+	time.Sleep(time.Second)
+	defer s.Stop()
+
+	for _, reservedOnly := range []bool{true, false} {
+		data := []byte(fmt.Sprintf(`{"jsonrpc":"2.0","method":"system_setReservedOnly","params":[%t],"id":1}`,
+			reservedOnly))
+
+		buf := new(bytes.Buffer)
+		_, err = buf.Write(data)
+		require.NoError(t, err)
+
+		_, resBody := PostRequest(t, fmt.Sprintf("http://localhost:%v/", cfg.RPCPort), buf)
+		expected := `{"jsonrpc":"2.0","result":null,"id":1}` + "\n"
+		require.Equal(t, expected, string(resBody))
+	}
+	netmock.AssertExpectations(t)
+}
+
 func TestUnsafeRPCJustToLocalhost(t *testing.T) {
 	unsafeMethod := "system_addReservedPeer"
 	data := []byte(fmt.Sprintf(
@@ -442,6 +479,7 @@ func newCoreServiceTest(t *testing.T) *core.Service {
 	net.EXPECT().
 		GossipMessage(gomock.AssignableToTypeOf(new(network.TransactionMessage))).
 		AnyTimes()
+	net.EXPECT().Peers().AnyTimes()
 	net.EXPECT().IsSynced().Return(true).AnyTimes()
 	net.EXPECT().ReportPeer(
 		gomock.AssignableToTypeOf(new(peerset.ReputationChange)),
@@ -52,6 +52,7 @@ type BlockAPI interface {
 	RegisterRuntimeUpdatedChannel(ch chan<- runtime.Version) (uint32, error)
 	UnregisterRuntimeUpdatedChannel(id uint32) bool
 	GetRuntime(hash *common.Hash) (runtime.Instance, error)
+	GetIndexedTransaction(hash common.Hash) ([]byte, error)
 }
 
 //go:generate mockery --name NetworkAPI --structname NetworkAPI --case underscore --keeptree
@@ -68,6 +69,7 @@ type NetworkAPI interface {
 	StartingBlock() int64
 	AddReservedPeers(addrs ...string) error
 	RemoveReservedPeers(addrs ...string) error
+	SetReservedOnly(reservedOnly bool)
 }
 
 //go:generate mockery --name BlockProducerAPI --structname BlockProducerAPI --case underscore --keeptree
@@ -84,12 +86,13 @@ type BlockProducerAPI interface {
 
 // TransactionStateAPI ...
 type TransactionStateAPI interface {
-	AddToPool(*transaction.ValidTransaction) common.Hash
+	AddToPool(*transaction.ValidTransaction) (common.Hash, error)
 	Pop() *transaction.ValidTransaction
 	Peek() *transaction.ValidTransaction
 	Pending() []*transaction.ValidTransaction
-	GetStatusNotifierChannel(ext types.Extrinsic) chan transaction.Status
-	FreeStatusNotifierChannel(ch chan transaction.Status)
+	RemoveAndBan(hashes []common.Hash) []common.Hash
+	GetStatusNotifierChannel(ext types.Extrinsic) chan transaction.StatusNotification
+	FreeStatusNotifierChannel(ch chan transaction.StatusNotification)
 }
 
 //go:generate mockery --name CoreAPI --structname CoreAPI --case underscore --keeptree
@@ -103,7 +106,10 @@ type CoreAPI interface {
 	GetMetadata(bhash *common.Hash) ([]byte, error)
 	QueryStorage(from, to common.Hash, keys ...string) (map[common.Hash]core.QueryKeyValueChanges, error)
 	DecodeSessionKeys(enc []byte) ([]byte, error)
+	GenerateSessionKeys() ([]byte, error)
 	GetReadProofAt(block common.Hash, keys [][]byte) (common.Hash, [][]byte, error)
+	Call(method string, data []byte, bhash *common.Hash) ([]byte, error)
+	TraceBlock(hash common.Hash) (*runtime.BlockTrace, error)
 }
 
 //go:generate mockery --name RPCAPI --structname RPCAPI --case underscore --keeptree
@@ -4,6 +4,7 @@
 package modules
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strings"
@@ -17,6 +18,9 @@
 
 var ErrProvidedKeyDoesNotMatch = errors.New("generated public key does not equal provide public key")
 
+// ErrInvalidExtrinsicOrHash is returned when an ExtrinsicOrHash has neither a hash nor an extrinsic
+var ErrInvalidExtrinsicOrHash = errors.New("expected either a hash or an extrinsic")
+
 // AuthorModule holds a pointer to the API
 type AuthorModule struct {
 	logger     log.LeveledLogger
@@ -47,6 +51,32 @@ type ExtrinsicOrHash struct {
 	Extrinsic []byte
 }
 
+// UnmarshalJSON decodes either {"hash": "0x..."} or {"extrinsic": "0x..."} into the ExtrinsicOrHash
+func (e *ExtrinsicOrHash) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Hash      *common.Hash `json:"hash"`
+		Extrinsic *string      `json:"extrinsic"`
+	}
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case v.Hash != nil:
+		e.Hash = *v.Hash
+	case v.Extrinsic != nil:
+		e.Extrinsic, err = common.HexToBytes(*v.Extrinsic)
+		if err != nil {
+			return err
+		}
+	default:
+		return ErrInvalidExtrinsicOrHash
+	}
+
+	return nil
+}
+
 // ExtrinsicOrHashRequest is a array of ExtrinsicOrHash
 type ExtrinsicOrHashRequest []ExtrinsicOrHash
 
@@ -59,8 +89,8 @@ type ExtrinsicOrHash struct {
 // RemoveExtrinsicsResponse is a array of hash used to Remove extrinsics
 type RemoveExtrinsicsResponse []common.Hash
 
-// KeyRotateResponse is a byte array used to rotate
-type KeyRotateResponse []byte
+// KeyRotateResponse is the hex encoded public keys of the new session keys
+type KeyRotateResponse string
 
 // HasSessionKeyResponse is the response to the RPC call author_hasSessionKeys
 type HasSessionKeyResponse bool
@@ -193,18 +223,41 @@ func (am *AuthorModule) PendingExtrinsics(r *http.Request, req *EmptyRequest, re
 }
 
 // RemoveExtrinsic Remove given extrinsic from the pool and temporarily ban it to prevent reimporting
-func (am *AuthorModule) RemoveExtrinsic(r *http.Request, _ *ExtrinsicOrHashRequest, _ *RemoveExtrinsicsResponse) error {
+func (am *AuthorModule) RemoveExtrinsic(r *http.Request, req *ExtrinsicOrHashRequest,
+	res *RemoveExtrinsicsResponse) error {
+	hashes := make([]common.Hash, len(*req))
+	for i, extOrHash := range *req {
+		if extOrHash.Extrinsic != nil {
+			hashes[i] = types.Extrinsic(extOrHash.Extrinsic).Hash()
+			continue
+		}
+		hashes[i] = extOrHash.Hash
+	}
+
+	removed := am.txStateAPI.RemoveAndBan(hashes)
+	if removed == nil {
+		removed = []common.Hash{}
+	}
+
+	*res = RemoveExtrinsicsResponse(removed)
 	return nil
 }
 
 // RotateKeys Generate new session keys and returns the corresponding public keys
 func (am *AuthorModule) RotateKeys(r *http.Request, req *EmptyRequest, res *KeyRotateResponse) error {
+	keys, err := am.coreAPI.GenerateSessionKeys()
+	if err != nil {
+		return err
+	}
+
+	*res = KeyRotateResponse(common.BytesToHex(keys))
 	return nil
 }
 
-// SubmitAndWatchExtrinsic Submit and subscribe to watch an extrinsic until unsubscribed
+// SubmitAndWatchExtrinsic handled by websocket handler, but this func should remain
+//  here so it's added to rpc_methods list
 func (am *AuthorModule) SubmitAndWatchExtrinsic(r *http.Request, req *Extrinsic, res *ExtrinsicStatus) error {
-	return nil
+	return ErrSubscriptionTransport
 }
 
 // SubmitExtrinsic Submit a fully formatted extrinsic for block inclusion
@@ -146,6 +146,7 @@ func TestAuthorModule_SubmitExtrinsic_Integration(t *testing.T) {
 
 	net2test := coremocks.NewMockNetwork(ctrl)
 	net2test.EXPECT().GossipMessage(&network.TransactionMessage{Extrinsics: []types.Extrinsic{extBytes}})
+	net2test.EXPECT().Peers()
 	integrationTestController.network = net2test
 
 	// setup auth module
@@ -545,6 +546,7 @@ func TestAuthorModule_SubmitExtrinsic_WithVersion_V0910(t *testing.T) {
 
 	net2test := coremocks.NewMockNetwork(ctrl)
 	net2test.EXPECT().GossipMessage(&network.TransactionMessage{Extrinsics: []types.Extrinsic{extBytes}})
+	net2test.EXPECT().Peers()
 	integrationTestController.network = net2test
 
 	// setup auth module
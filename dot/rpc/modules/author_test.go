@@ -4,6 +4,7 @@
 package modules
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -225,6 +226,52 @@ type args struct {
 	}
 }
 
+func TestAuthorModule_RotateKeys(t *testing.T) {
+	errMockCoreAPI := &mocks.CoreAPI{}
+	errMockCoreAPI.On("GenerateSessionKeys").Return(nil, errors.New("generate error"))
+
+	mockCoreAPI := &mocks.CoreAPI{}
+	mockCoreAPI.On("GenerateSessionKeys").Return([]byte{0x1, 0x2, 0x3}, nil)
+
+	tests := []struct {
+		name    string
+		coreAPI CoreAPI
+		expErr  error
+		wantRes KeyRotateResponse
+	}{
+		{
+			name:    "GenerateSessionKeys error",
+			coreAPI: errMockCoreAPI,
+			expErr:  errors.New("generate error"),
+		},
+		{
+			name:    "happy path",
+			coreAPI: mockCoreAPI,
+			wantRes: KeyRotateResponse("0x010203"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			am := NewAuthorModule(log.New(log.SetWriter(io.Discard)), tt.coreAPI, nil)
+			var res KeyRotateResponse
+			err := am.RotateKeys(nil, nil, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRes, res)
+		})
+	}
+}
+
+func TestAuthorModule_SubmitAndWatchExtrinsic(t *testing.T) {
+	am := NewAuthorModule(log.New(log.SetWriter(io.Discard)), nil, nil)
+
+	err := am.SubmitAndWatchExtrinsic(nil, &Extrinsic{Data: "0x00"}, &ExtrinsicStatus{})
+	require.ErrorIs(t, err, ErrSubscriptionTransport)
+}
+
 func TestAuthorModule_PendingExtrinsics(t *testing.T) {
 	emptyMockTransactionStateAPI := &mocks.TransactionStateAPI{}
 	emptyMockTransactionStateAPI.On("Pending").Return([]*transaction.ValidTransaction{})
@@ -294,6 +341,38 @@ type args struct {
 	}
 }
 
+func TestExtrinsicOrHash_UnmarshalJSON(t *testing.T) {
+	var req ExtrinsicOrHashRequest
+	err := json.Unmarshal([]byte(`[{"hash":"0x0101010101010101010101010101010101010101010101010101010101010101"},`+
+		`{"extrinsic":"0x0102"}]`), &req)
+	require.NoError(t, err)
+
+	expected := ExtrinsicOrHashRequest{
+		{Hash: common.Hash{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+		{Extrinsic: []byte{1, 2}},
+	}
+	assert.Equal(t, expected, req)
+
+	err = json.Unmarshal([]byte(`[{}]`), &req)
+	assert.ErrorIs(t, err, ErrInvalidExtrinsicOrHash)
+}
+
+func TestAuthorModule_RemoveExtrinsic(t *testing.T) {
+	ext := types.Extrinsic{1, 2, 3}
+	unknownHash := common.Hash{9}
+
+	txStateAPI := new(mocks.TransactionStateAPI)
+	txStateAPI.On("RemoveAndBan", []common.Hash{ext.Hash(), unknownHash}).Return([]common.Hash{ext.Hash()})
+
+	am := NewAuthorModule(log.New(log.SetWriter(io.Discard)), nil, txStateAPI)
+	req := ExtrinsicOrHashRequest{{Extrinsic: ext}, {Hash: unknownHash}}
+	var res RemoveExtrinsicsResponse
+	err := am.RemoveExtrinsic(nil, &req, &res)
+	require.NoError(t, err)
+	assert.Equal(t, RemoveExtrinsicsResponse{ext.Hash()}, res)
+	txStateAPI.AssertExpectations(t)
+}
+
 func TestAuthorModule_InsertKey(t *testing.T) {
 	kp1, err := sr25519.NewKeypairFromSeed(
 		common.MustHexToBytes("0x6246ddf254e0b4b4e7dffefc8adf69d212b98ac2b579c362b473fec8c40b4c0a"))
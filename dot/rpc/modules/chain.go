@@ -4,6 +4,7 @@
 package modules
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -12,6 +13,8 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	"github.com/ChainSafe/chaindb"
 )
 
 // ChainHashRequest Hash as a string
@@ -58,6 +61,14 @@ type ChainBlockResponse struct {
 // ChainHashResponse interface to handle response
 type ChainHashResponse interface{}
 
+// ChainIndexedTransactionRequest holds the hash of the indexed transaction
+type ChainIndexedTransactionRequest struct {
+	Hash common.Hash
+}
+
+// ChainIndexedTransactionResponse is the hex encoded indexed transaction, or nil if it isn't stored
+type ChainIndexedTransactionResponse interface{}
+
 // ChainModule is an RPC module providing access to storage API points.
 type ChainModule struct {
 	blockAPI BlockAPI
@@ -151,6 +162,22 @@ func (cm *ChainModule) GetHeader(r *http.Request, req *ChainHashRequest, res *Ch
 	return err
 }
 
+// GetIndexedTransaction returns the data indexed by the runtime under the given hash,
+// or null if it isn't stored.
+func (cm *ChainModule) GetIndexedTransaction(
+	_ *http.Request, req *ChainIndexedTransactionRequest, res *ChainIndexedTransactionResponse) error {
+	data, err := cm.blockAPI.GetIndexedTransaction(req.Hash)
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		*res = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	*res = common.BytesToHex(data)
+	return nil
+}
+
 // SubscribeFinalizedHeads handled by websocket handler, but this func should remain
 //  here so it's added to rpc_methods list
 func (cm *ChainModule) SubscribeFinalizedHeads(_ *http.Request, _ *EmptyRequest, _ *ChainBlockHeaderResponse) error {
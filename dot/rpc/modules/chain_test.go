@@ -12,6 +12,7 @@
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common"
 
+	"github.com/ChainSafe/chaindb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -417,6 +418,54 @@ type args struct {
 	}
 }
 
+func TestChainModule_GetIndexedTransaction(t *testing.T) {
+	storedHash := common.Hash{1}
+	missingHash := common.Hash{2}
+	errHash := common.Hash{3}
+
+	mockBlockAPI := new(mocks.BlockAPI)
+	mockBlockAPI.On("GetIndexedTransaction", storedHash).Return([]byte{0x01, 0x02}, nil)
+	mockBlockAPI.On("GetIndexedTransaction", missingHash).Return(nil, chaindb.ErrKeyNotFound)
+	mockBlockAPI.On("GetIndexedTransaction", errHash).Return(nil, errors.New("GetIndexedTransaction Error"))
+
+	tests := []struct {
+		name   string
+		req    *ChainIndexedTransactionRequest
+		expErr error
+		exp    ChainIndexedTransactionResponse
+	}{
+		{
+			name: "GetIndexedTransaction OK",
+			req:  &ChainIndexedTransactionRequest{Hash: storedHash},
+			exp:  "0x0102",
+		},
+		{
+			name: "GetIndexedTransaction not found",
+			req:  &ChainIndexedTransactionRequest{Hash: missingHash},
+		},
+		{
+			name:   "GetIndexedTransaction ERR",
+			req:    &ChainIndexedTransactionRequest{Hash: errHash},
+			expErr: errors.New("GetIndexedTransaction Error"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &ChainModule{
+				blockAPI: mockBlockAPI,
+			}
+			var res ChainIndexedTransactionResponse
+			err := cm.GetIndexedTransaction(nil, tt.req, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
+
 func TestChainModule_ErrSubscriptionTransport(t *testing.T) {
 	req := &EmptyRequest{}
 	res := &ChainBlockHeaderResponse{}
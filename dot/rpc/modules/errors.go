@@ -3,7 +3,21 @@
 
 package modules
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/gorilla/rpc/v2/json2"
+)
 
 // ErrSubscriptionTransport error sent when trying to access websocket subscriptions via http
 var ErrSubscriptionTransport = errors.New("subscriptions are not available on this transport")
+
+// stateCallErrorCode is the error code returned when a state_call runtime execution fails
+const stateCallErrorCode = 4003
+
+func newStateCallError(msg string) *json2.Error {
+	return &json2.Error{
+		Code:    stateCallErrorCode,
+		Message: "Client error: Execution failed: " + msg,
+	}
+}
@@ -127,6 +127,29 @@ func (_m *BlockAPI) GetHashByNumber(blockNumber uint) (common.Hash, error) {
 	return r0, r1
 }
 
+// GetIndexedTransaction provides a mock function with given fields: hash
+func (_m *BlockAPI) GetIndexedTransaction(hash common.Hash) ([]byte, error) {
+	ret := _m.Called(hash)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(common.Hash) []byte); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(common.Hash) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetHeader provides a mock function with given fields: hash
 func (_m *BlockAPI) GetHeader(hash common.Hash) (*types.Header, error) {
 	ret := _m.Called(hash)
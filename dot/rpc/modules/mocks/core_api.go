@@ -20,6 +20,29 @@ type CoreAPI struct {
 	mock.Mock
 }
 
+// Call provides a mock function with given fields: method, data, bhash
+func (_m *CoreAPI) Call(method string, data []byte, bhash *common.Hash) ([]byte, error) {
+	ret := _m.Called(method, data, bhash)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string, []byte, *common.Hash) []byte); ok {
+		r0 = rf(method, data, bhash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, *common.Hash) error); ok {
+		r1 = rf(method, data, bhash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DecodeSessionKeys provides a mock function with given fields: enc
 func (_m *CoreAPI) DecodeSessionKeys(enc []byte) ([]byte, error) {
 	ret := _m.Called(enc)
@@ -43,6 +66,29 @@ func (_m *CoreAPI) DecodeSessionKeys(enc []byte) ([]byte, error) {
 	return r0, r1
 }
 
+// GenerateSessionKeys provides a mock function with given fields:
+func (_m *CoreAPI) GenerateSessionKeys() ([]byte, error) {
+	ret := _m.Called()
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func() []byte); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetadata provides a mock function with given fields: bhash
 func (_m *CoreAPI) GetMetadata(bhash *common.Hash) ([]byte, error) {
 	ret := _m.Called(bhash)
@@ -199,3 +245,26 @@ func (_m *CoreAPI) QueryStorage(from common.Hash, to common.Hash, keys ...string
 
 	return r0, r1
 }
+
+// TraceBlock provides a mock function with given fields: hash
+func (_m *CoreAPI) TraceBlock(hash common.Hash) (*runtime.BlockTrace, error) {
+	ret := _m.Called(hash)
+
+	var r0 *runtime.BlockTrace
+	if rf, ok := ret.Get(0).(func(common.Hash) *runtime.BlockTrace); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*runtime.BlockTrace)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(common.Hash) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -124,6 +124,11 @@ func (_m *NetworkAPI) RemoveReservedPeers(addrs ...string) error {
 	return r0
 }
 
+// SetReservedOnly provides a mock function with given fields: reservedOnly
+func (_m *NetworkAPI) SetReservedOnly(reservedOnly bool) {
+	_m.Called(reservedOnly)
+}
+
 // Start provides a mock function with given fields:
 func (_m *NetworkAPI) Start() error {
 	ret := _m.Called()
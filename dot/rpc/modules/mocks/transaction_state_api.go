@@ -17,7 +17,7 @@ type TransactionStateAPI struct {
 }
 
 // AddToPool provides a mock function with given fields: _a0
-func (_m *TransactionStateAPI) AddToPool(_a0 *transaction.ValidTransaction) common.Hash {
+func (_m *TransactionStateAPI) AddToPool(_a0 *transaction.ValidTransaction) (common.Hash, error) {
 	ret := _m.Called(_a0)
 
 	var r0 common.Hash
@@ -29,24 +29,31 @@ func (_m *TransactionStateAPI) AddToPool(_a0 *transaction.ValidTransaction) comm
 		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*transaction.ValidTransaction) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // FreeStatusNotifierChannel provides a mock function with given fields: ch
-func (_m *TransactionStateAPI) FreeStatusNotifierChannel(ch chan transaction.Status) {
+func (_m *TransactionStateAPI) FreeStatusNotifierChannel(ch chan transaction.StatusNotification) {
 	_m.Called(ch)
 }
 
 // GetStatusNotifierChannel provides a mock function with given fields: ext
-func (_m *TransactionStateAPI) GetStatusNotifierChannel(ext types.Extrinsic) chan transaction.Status {
+func (_m *TransactionStateAPI) GetStatusNotifierChannel(ext types.Extrinsic) chan transaction.StatusNotification {
 	ret := _m.Called(ext)
 
-	var r0 chan transaction.Status
-	if rf, ok := ret.Get(0).(func(types.Extrinsic) chan transaction.Status); ok {
+	var r0 chan transaction.StatusNotification
+	if rf, ok := ret.Get(0).(func(types.Extrinsic) chan transaction.StatusNotification); ok {
 		r0 = rf(ext)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(chan transaction.Status)
+			r0 = ret.Get(0).(chan transaction.StatusNotification)
 		}
 	}
 
@@ -100,3 +107,19 @@ func (_m *TransactionStateAPI) Pop() *transaction.ValidTransaction {
 
 	return r0
 }
+
+// RemoveAndBan provides a mock function with given fields: hashes
+func (_m *TransactionStateAPI) RemoveAndBan(hashes []common.Hash) []common.Hash {
+	ret := _m.Called(hashes)
+
+	var r0 []common.Hash
+	if rf, ok := ret.Get(0).(func([]common.Hash) []common.Hash); ok {
+		r0 = rf(hashes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]common.Hash)
+		}
+	}
+
+	return r0
+}
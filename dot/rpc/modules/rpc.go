@@ -12,6 +12,7 @@
 	UnsafeMethods = []string{
 		"system_addReservedPeer",
 		"system_removeReservedPeer",
+		"system_setReservedOnly",
 		"author_submitExtrinsic",
 		"author_removeExtrinsic",
 		"author_insertKey",
@@ -19,6 +20,7 @@
 		"state_getPairs",
 		"state_getKeysPaged",
 		"state_queryStorage",
+		"state_traceBlock",
 	}
 
 	// AliasesMethods is a map that links the original methods to their aliases
@@ -10,6 +10,7 @@
 	"net/http"
 	"strings"
 
+	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/pkg/scale"
@@ -24,10 +25,15 @@ type StateGetReadProofRequest struct {
 // StateCallRequest holds json fields
 type StateCallRequest struct {
 	Method string       `json:"method"`
-	Data   []byte       `json:"data"`
+	Data   string       `json:"data"`
 	Block  *common.Hash `json:"block"`
 }
 
+// StateTraceBlockRequest holds json fields
+type StateTraceBlockRequest struct {
+	Block common.Hash `json:"block"`
+}
+
 // StateStorageKeyRequest holds json fields
 type StateStorageKeyRequest struct {
 	Prefix   string       `json:"prefix"`
@@ -80,8 +86,11 @@ type StateStorageQueryRangeRequest struct {
 // StateStorageKeysQuery field to store storage keys
 type StateStorageKeysQuery [][]byte
 
-// StateCallResponse holds json fields
-type StateCallResponse []byte
+// StateCallResponse is the hex encoded SCALE output of the runtime call
+type StateCallResponse string
+
+// StateTraceBlockResponse is the trace of the execution of a block
+type StateTraceBlockResponse runtime.BlockTrace
 
 // StateKeysResponse field to store the state keys
 type StateKeysResponse [][]byte
@@ -211,10 +220,41 @@ func (sm *StateModule) GetPairs(_ *http.Request, req *StatePairRequest, res *Sta
 	return nil
 }
 
-// Call isn't implemented properly yet.
-func (sm *StateModule) Call(_ *http.Request, _ *StateCallRequest, _ *StateCallResponse) error {
-	_ = sm.networkAPI
-	_ = sm.storageAPI
+// Call executes the given runtime API function with the hex encoded SCALE data against the
+// state of the given block. If no block hash is provided, the best block is used.
+func (sm *StateModule) Call(_ *http.Request, req *StateCallRequest, res *StateCallResponse) error {
+	data, err := common.HexToBytes(req.Data)
+	if err != nil {
+		return fmt.Errorf("cannot convert hex data %s to bytes: %w", req.Data, err)
+	}
+
+	ret, err := sm.coreAPI.Call(req.Method, data, req.Block)
+	switch {
+	case errors.Is(err, runtime.ErrExportFunctionNotFound):
+		return newStateCallError(fmt.Sprintf("Exported method %s is not found", req.Method))
+	case errors.Is(err, state.ErrTrieDoesNotExist):
+		block := "best block"
+		if req.Block != nil {
+			block = "block " + req.Block.String()
+		}
+		return newStateCallError("State already discarded for " + block)
+	case err != nil:
+		return newStateCallError(err.Error())
+	}
+
+	*res = StateCallResponse(common.BytesToHex(ret))
+	return nil
+}
+
+// TraceBlock re-executes the block on the state of its parent, and returns the host function calls
+// made by the runtime with the time spent in each of them, and the storage they accessed.
+func (sm *StateModule) TraceBlock(_ *http.Request, req *StateTraceBlockRequest, res *StateTraceBlockResponse) error {
+	trace, err := sm.coreAPI.TraceBlock(req.Block)
+	if err != nil {
+		return err
+	}
+
+	*res = StateTraceBlockResponse(*trace)
 	return nil
 }
 
@@ -403,7 +443,8 @@ func (sm *StateModule) GetStorageSize(
 	return nil
 }
 
-// QueryStorage isn't implemented properly yet.
+// QueryStorage returns the changes of the given keys in each block from the start block to the end block.
+// The blocks without changes of the keys are omitted.
 func (sm *StateModule) QueryStorage(
 	_ *http.Request, req *StateStorageQueryRangeRequest, res *[]StorageChangeSetResponse) error {
 	if req.StartBlock.IsEmpty() {
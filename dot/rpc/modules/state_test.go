@@ -17,12 +17,15 @@
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/ChainSafe/gossamer/dot/core"
 	"github.com/ChainSafe/gossamer/dot/rpc/modules/mocks"
 	testdata "github.com/ChainSafe/gossamer/dot/rpc/modules/test_data"
+	"github.com/ChainSafe/gossamer/dot/state"
 	"github.com/ChainSafe/gossamer/lib/common"
 	"github.com/ChainSafe/gossamer/lib/runtime"
 	"github.com/ChainSafe/gossamer/pkg/scale"
@@ -278,14 +281,97 @@ type args struct {
 	}
 }
 
-// Implement Tests once function is implemented
 func TestCall(t *testing.T) {
-	mockNetworkAPI := new(mocks.NetworkAPI)
-	mockStorageAPI := new(mocks.StorageAPI)
-	sm := NewStateModule(mockNetworkAPI, mockStorageAPI, nil)
+	hash := common.MustHexToHash("0x3aa96b0149b6ca3688878bdbd19464448624136398e3ce45b9e755d3ab61355a")
+
+	mockCoreAPI := new(mocks.CoreAPI)
+	mockCoreAPI.On("Call", "AccountNonceApi_account_nonce", []byte{1, 2}, &hash).Return([]byte{3, 4}, nil)
+	mockCoreAPI.On("Call", "Unknown_method", []byte{1, 2}, &hash).
+		Return(nil, fmt.Errorf("%w: Unknown_method", runtime.ErrExportFunctionNotFound))
+	mockCoreAPI.On("Call", "Core_version", []byte{}, &hash).
+		Return(nil, fmt.Errorf("cannot get trie state: %w", state.ErrTrieDoesNotExist))
+
+	sm := NewStateModule(nil, nil, mockCoreAPI)
+
+	tests := []struct {
+		name   string
+		req    *StateCallRequest
+		exp    StateCallResponse
+		expErr error
+	}{
+		{
+			name: "OK Case",
+			req:  &StateCallRequest{Method: "AccountNonceApi_account_nonce", Data: "0x0102", Block: &hash},
+			exp:  "0x0304",
+		},
+		{
+			name:   "Invalid hex data",
+			req:    &StateCallRequest{Method: "AccountNonceApi_account_nonce", Data: "0102", Block: &hash},
+			expErr: errors.New("cannot convert hex data 0102 to bytes: could not byteify non 0x prefixed string: 0102"),
+		},
+		{
+			name:   "Unknown method",
+			req:    &StateCallRequest{Method: "Unknown_method", Data: "0x0102", Block: &hash},
+			expErr: newStateCallError("Exported method Unknown_method is not found"),
+		},
+		{
+			name:   "Missing state",
+			req:    &StateCallRequest{Method: "Core_version", Data: "0x", Block: &hash},
+			expErr: newStateCallError("State already discarded for block " + hash.String()),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var res StateCallResponse
+			err := sm.Call(nil, tt.req, &res)
+			if tt.expErr != nil {
+				assert.EqualError(t, err, tt.expErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.exp, res)
+		})
+	}
+}
+
+func TestStateModule_TraceBlock(t *testing.T) {
+	hash := common.MustHexToHash("0x3aa96b0149b6ca3688878bdbd19464448624136398e3ce45b9e755d3ab61355a")
+	unknownHash := common.Hash{1}
+
+	trace := &runtime.BlockTrace{
+		Hash:     hash,
+		Duration: time.Millisecond,
+		Trace: runtime.Trace{
+			HostCalls: []*runtime.HostCallTrace{{
+				Name:     "ext_storage_get_version_1",
+				Duration: time.Microsecond,
+				Storage: []*runtime.StorageAccess{{
+					Operation: runtime.StorageGet,
+					Key:       "0x01",
+					ValueSize: 4,
+				}},
+			}},
+			Profile: []*runtime.HostCallProfile{{
+				Name:     "ext_storage_get_version_1",
+				Calls:    1,
+				Duration: time.Microsecond,
+			}},
+		},
+	}
 
-	err := sm.Call(nil, nil, nil)
+	mockCoreAPI := new(mocks.CoreAPI)
+	mockCoreAPI.On("TraceBlock", hash).Return(trace, nil)
+	mockCoreAPI.On("TraceBlock", unknownHash).Return(nil, errors.New("cannot get block"))
+
+	sm := NewStateModule(nil, nil, mockCoreAPI)
+
+	var res StateTraceBlockResponse
+	err := sm.TraceBlock(nil, &StateTraceBlockRequest{Block: hash}, &res)
 	assert.NoError(t, err)
+	assert.Equal(t, StateTraceBlockResponse(*trace), res)
+
+	err = sm.TraceBlock(nil, &StateTraceBlockRequest{Block: unknownHash}, &res)
+	assert.EqualError(t, err, "cannot get block")
 }
 
 func TestStateModuleGetMetadata(t *testing.T) {
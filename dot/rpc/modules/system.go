@@ -59,6 +59,11 @@ type StringRequest struct {
 	String string
 }
 
+// BoolRequest holds bool request
+type BoolRequest struct {
+	Bool bool
+}
+
 // SyncStateResponse is the struct to return on the system_syncState rpc call
 type SyncStateResponse struct {
 	CurrentBlock  uint32 `json:"currentBlock"`
@@ -289,3 +294,11 @@ func (sm *SystemModule) RemoveReservedPeer(r *http.Request, req *StringRequest,
 
 	return sm.networkAPI.RemoveReservedPeers(req.String)
 }
+
+// SetReservedOnly sets whether the node only connects to its reserved peers. Enabling it
+// disconnects the peers which are not reserved. Disabling it keeps the peer sets configured
+// to only allow their reserved peers in the reserved-only mode.
+func (sm *SystemModule) SetReservedOnly(r *http.Request, req *BoolRequest, res *[]byte) error {
+	sm.networkAPI.SetReservedOnly(req.Bool)
+	return nil
+}
@@ -380,6 +380,7 @@ func newCoreService(t *testing.T, srvc *state.Service) *core.Service {
 	mocknet.EXPECT().GossipMessage(
 		gomock.AssignableToTypeOf(new(network.TransactionMessage))).
 		AnyTimes()
+	mocknet.EXPECT().Peers().AnyTimes()
 
 	digestHandlerMock := NewMockDigestHandler(nil)
 
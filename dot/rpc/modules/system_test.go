@@ -605,3 +605,17 @@ type args struct {
 		})
 	}
 }
+
+func TestSystemModule_SetReservedOnly(t *testing.T) {
+	for _, reservedOnly := range []bool{true, false} {
+		mockNetworkAPI := new(mocks.NetworkAPI)
+		mockNetworkAPI.On("SetReservedOnly", reservedOnly).Once()
+
+		sm := NewSystemModule(mockNetworkAPI, nil, nil, nil, nil, nil, nil)
+		res := []byte(nil)
+		err := sm.SetReservedOnly(nil, &BoolRequest{Bool: reservedOnly}, &res)
+		require.NoError(t, err)
+		require.Nil(t, res)
+		mockNetworkAPI.AssertExpectations(t)
+	}
+}
@@ -7,7 +7,6 @@
 	"errors"
 	"fmt"
 	"math/big"
-	"reflect"
 	"time"
 
 	"github.com/ChainSafe/gossamer/dot/rpc/modules"
@@ -28,6 +27,10 @@
 	stateStorageMethod           = "state_storage"
 )
 
+// finalityTimeoutBlocks is the number of blocks imported after the block including
+// an extrinsic, after which the extrinsic watcher gives up waiting for its finality.
+const finalityTimeoutBlocks = 512
+
 var (
 	// ErrCannotCancel when is not possible to cancel a goroutine after `cancelTimeout` seconds
 	ErrCannotCancel = errors.New("cannot cancel listening goroutines")
@@ -296,13 +299,12 @@ type ExtrinsicSubmitListener struct {
 	subID         uint32
 	extrinsic     types.Extrinsic
 	importedChan  chan *types.Block
-	importedHash  common.Hash
 	finalisedChan chan *types.FinalisationInfo
-	// txStatusChan is used to know when transaction/extrinsic becomes part of the
-	// ready queue or future queue.
-	// we are using transaction.PriorityQueue for ready queue and transaction.Pool
-	// for future queue.
-	txStatusChan  chan transaction.Status
+	// txStatusChan is used to receive the status updates of the transaction/extrinsic
+	// from the transaction state, the network broadcast and the chain re-orgs.
+	txStatusChan chan transaction.StatusNotification
+	// inBlock is the header of the block the extrinsic is included in, if any
+	inBlock       *types.Header
 	done          chan struct{}
 	cancel        chan struct{}
 	cancelTimeout time.Duration
@@ -310,7 +312,7 @@ type ExtrinsicSubmitListener struct {
 
 // NewExtrinsicSubmitListener constructor to build new ExtrinsicSubmitListener
 func NewExtrinsicSubmitListener(conn *WSConn, extBytes []byte,
-	importedChan chan *types.Block, txStatusChan chan transaction.Status,
+	importedChan chan *types.Block, txStatusChan chan transaction.StatusNotification,
 	finalisedChan chan *types.FinalisationInfo) *ExtrinsicSubmitListener {
 	return &ExtrinsicSubmitListener{
 		wsconn:        conn,
@@ -324,9 +326,9 @@ func NewExtrinsicSubmitListener(conn *WSConn, extBytes []byte,
 	}
 }
 
-// Listen implementation of Listen interface to listen for importedChan changes
+// Listen implementation of Listen interface to listen for the extrinsic status updates.
+// It stops listening once the extrinsic reaches a final status.
 func (l *ExtrinsicSubmitListener) Listen() {
-	// listen for imported blocks with extrinsic
 	go func() {
 		defer func() {
 			l.wsconn.BlockAPI.FreeImportedBlockNotifierChannel(l.importedChan)
@@ -346,43 +348,113 @@ func (l *ExtrinsicSubmitListener) Listen() {
 					return
 				}
 
-				if block == nil {
+				if block == nil || l.inBlock == nil {
 					continue
 				}
-				bodyHasExtrinsic, err := block.Body.HasExtrinsic(l.extrinsic)
-				if err != nil {
-					fmt.Printf("error %v\n", err)
-				}
 
-				if bodyHasExtrinsic {
-					resM := make(map[string]interface{})
-					resM["inBlock"] = block.Header.Hash().String()
-
-					l.importedHash = block.Header.Hash()
-					l.wsconn.safeSend(newSubscriptionResponse(authorExtrinsicUpdatesMethod, l.subID, resM))
+				if block.Header.Number < l.inBlock.Number+finalityTimeoutBlocks {
+					continue
 				}
 
+				inBlockHash := l.inBlock.Hash()
+				l.sendStatus(transaction.StatusNotification{
+					Status: transaction.FinalityTimeout,
+					Hash:   &inBlockHash,
+				})
+				return
 			case info, ok := <-l.finalisedChan:
 				if !ok {
 					return
 				}
 
-				if reflect.DeepEqual(l.importedHash, info.Header.Hash()) {
-					resM := make(map[string]interface{})
-					resM["finalised"] = info.Header.Hash().String()
-					l.wsconn.safeSend(newSubscriptionResponse(authorExtrinsicUpdatesMethod, l.subID, resM))
+				if l.inBlock == nil || info.Header.Number < l.inBlock.Number {
+					continue
 				}
+
+				// the block is finalised if it is part of the finalised chain
+				inBlockHash := l.inBlock.Hash()
+				hash, err := l.wsconn.BlockAPI.GetHashByNumber(l.inBlock.Number)
+				if err != nil {
+					logger.Warnf("failed to get hash of finalised block number %d: %s", l.inBlock.Number, err)
+					continue
+				}
+
+				if hash != inBlockHash {
+					continue
+				}
+
+				l.sendStatus(transaction.StatusNotification{
+					Status: transaction.Finalized,
+					Hash:   &inBlockHash,
+				})
+				return
 			case txStatus, ok := <-l.txStatusChan:
 				if !ok {
 					return
 				}
 
-				l.wsconn.safeSend(newSubscriptionResponse(authorExtrinsicUpdatesMethod, l.subID, txStatus.String()))
+				switch txStatus.Status {
+				case transaction.InBlock:
+					if txStatus.Hash == nil {
+						logger.Warnf("missing block hash of in block status of extrinsic %s", l.extrinsic)
+						break
+					}
+
+					header, err := l.wsconn.BlockAPI.GetHeader(*txStatus.Hash)
+					if err != nil {
+						logger.Warnf("failed to get header of block %s: %s", txStatus.Hash, err)
+					}
+					l.inBlock = header
+				case transaction.Retracted:
+					l.inBlock = nil
+				}
+
+				l.sendStatus(txStatus)
+				if isFinalStatus(txStatus.Status) {
+					return
+				}
 			}
 		}
 	}()
 }
 
+// sendStatus sends the given status update in the format used by substrate, which is the
+// status name for the statuses without data, and an object mapping the status name to
+// its data otherwise. The status name is sent alone if its block or transaction hash is missing.
+func (l *ExtrinsicSubmitListener) sendStatus(txStatus transaction.StatusNotification) {
+	var result interface{}
+	switch txStatus.Status {
+	case transaction.Future, transaction.Ready, transaction.Dropped, transaction.Invalid:
+		result = txStatus.Status.String()
+	case transaction.Broadcast:
+		peers := txStatus.Peers
+		if peers == nil {
+			peers = []string{}
+		}
+		result = map[string]interface{}{txStatus.Status.String(): peers}
+	default:
+		if txStatus.Hash == nil {
+			result = txStatus.Status.String()
+			break
+		}
+
+		result = map[string]interface{}{txStatus.Status.String(): txStatus.Hash.String()}
+	}
+
+	l.wsconn.safeSend(newSubscriptionResponse(authorExtrinsicUpdatesMethod, l.subID, result))
+}
+
+// isFinalStatus returns true if no more status updates follow the given status
+func isFinalStatus(status transaction.Status) bool {
+	switch status {
+	case transaction.Finalized, transaction.FinalityTimeout, transaction.Usurped,
+		transaction.Dropped, transaction.Invalid:
+		return true
+	}
+
+	return false
+}
+
 // Stop to cancel the running goroutines to this listener
 func (l *ExtrinsicSubmitListener) Stop() error {
 	return cancelWithTimeout(l.cancel, l.done, l.cancelTimeout)
@@ -179,13 +179,19 @@ func TestExtrinsicSubmitListener_Listen(t *testing.T) {
 	wsconn, ws, cancel := setupWSConn(t)
 	defer cancel()
 
-	notifyImportedChan := make(chan *types.Block, 100)
-	notifyFinalizedChan := make(chan *types.FinalisationInfo, 100)
-	txStatusChan := make(chan transaction.Status)
+	notifyImportedChan := make(chan *types.Block)
+	notifyFinalizedChan := make(chan *types.FinalisationInfo)
+	txStatusChan := make(chan transaction.StatusNotification)
+
+	header := types.NewEmptyHeader()
+	header.Number = 1
+	blockHash := header.Hash()
 
 	BlockAPI := new(mocks.BlockAPI)
 	BlockAPI.On("FreeImportedBlockNotifierChannel", mock.AnythingOfType("chan *types.Block"))
 	BlockAPI.On("FreeFinalisedNotifierChannel", mock.AnythingOfType("chan *types.FinalisationInfo"))
+	BlockAPI.On("GetHeader", blockHash).Return(header, nil)
+	BlockAPI.On("GetHashByNumber", uint(1)).Return(blockHash, nil)
 
 	wsconn.BlockAPI = BlockAPI
 
@@ -202,15 +208,6 @@ func TestExtrinsicSubmitListener_Listen(t *testing.T) {
 		done:          make(chan struct{}),
 		cancelTimeout: time.Second * 5,
 	}
-	header := types.NewEmptyHeader()
-	exts := []types.Extrinsic{{1, 2, 3}, {7, 8, 9, 0}, {0xa, 0xb}}
-
-	body := types.NewBody(exts)
-
-	block := &types.Block{
-		Header: *header,
-		Body:   *body,
-	}
 
 	esl.Listen()
 	defer func() {
@@ -221,29 +218,120 @@ func TestExtrinsicSubmitListener_Listen(t *testing.T) {
 		BlockAPI.AssertCalled(t, "FreeFinalisedNotifierChannel", mock.AnythingOfType("chan *types.FinalisationInfo"))
 	}()
 
-	notifyImportedChan <- block
-	time.Sleep(time.Second * 2)
+	expectUpdate := func(result interface{}) {
+		t.Helper()
+
+		_, msg, err := ws.ReadMessage()
+		require.NoError(t, err)
+		expectedBytes, err := json.Marshal(
+			newSubscriptionResponse(authorExtrinsicUpdatesMethod, esl.subID, result))
+		require.NoError(t, err)
+		require.Equal(t, string(expectedBytes)+"\n", string(msg))
+	}
+
+	txStatusChan <- transaction.StatusNotification{Status: transaction.Future}
+	expectUpdate("future")
+
+	txStatusChan <- transaction.StatusNotification{Status: transaction.Ready}
+	expectUpdate("ready")
+
+	txStatusChan <- transaction.StatusNotification{
+		Status: transaction.Broadcast,
+		Peers:  []string{"QmPeer"},
+	}
+	expectUpdate(map[string]interface{}{"broadcast": []string{"QmPeer"}})
+
+	txStatusChan <- transaction.StatusNotification{
+		Status: transaction.InBlock,
+		Hash:   &blockHash,
+	}
+	expectUpdate(map[string]interface{}{"inBlock": blockHash.String()})
+
+	txStatusChan <- transaction.StatusNotification{
+		Status: transaction.Retracted,
+		Hash:   &blockHash,
+	}
+	expectUpdate(map[string]interface{}{"retracted": blockHash.String()})
+
+	// the retracted block being finalised does not finalise the extrinsic
+	notifyFinalizedChan <- &types.FinalisationInfo{
+		Header: *header,
+	}
+
+	txStatusChan <- transaction.StatusNotification{
+		Status: transaction.InBlock,
+		Hash:   &blockHash,
+	}
+	expectUpdate(map[string]interface{}{"inBlock": blockHash.String()})
+
+	notifyFinalizedChan <- &types.FinalisationInfo{
+		Header: *header,
+	}
+	expectUpdate(map[string]interface{}{"finalized": blockHash.String()})
+}
+
+func TestExtrinsicSubmitListener_sendStatus_nilHash(t *testing.T) {
+	wsconn, ws, cancel := setupWSConn(t)
+	defer cancel()
+
+	esl := NewExtrinsicSubmitListener(wsconn, []byte{1, 2, 3}, nil, nil, nil)
+	esl.sendStatus(transaction.StatusNotification{Status: transaction.Usurped})
 
 	_, msg, err := ws.ReadMessage()
 	require.NoError(t, err)
-	resImported := map[string]interface{}{"inBlock": block.Header.Hash().String()}
-	expectedImportedBytes, err := json.Marshal(
-		newSubscriptionResponse(authorExtrinsicUpdatesMethod, esl.subID, resImported))
+	expectedBytes, err := json.Marshal(newSubscriptionResponse(authorExtrinsicUpdatesMethod, esl.subID, "usurped"))
 	require.NoError(t, err)
-	require.Equal(t, string(expectedImportedBytes)+"\n", string(msg))
+	require.Equal(t, string(expectedBytes)+"\n", string(msg))
+}
 
-	notifyFinalizedChan <- &types.FinalisationInfo{
-		Header: *header,
+func TestExtrinsicSubmitListener_Listen_FinalityTimeout(t *testing.T) {
+	wsconn, ws, cancel := setupWSConn(t)
+	defer cancel()
+
+	notifyImportedChan := make(chan *types.Block, 100)
+	txStatusChan := make(chan transaction.StatusNotification)
+
+	header := types.NewEmptyHeader()
+	header.Number = 1
+	blockHash := header.Hash()
+
+	BlockAPI := new(mocks.BlockAPI)
+	BlockAPI.On("FreeImportedBlockNotifierChannel", mock.AnythingOfType("chan *types.Block"))
+	BlockAPI.On("FreeFinalisedNotifierChannel", mock.AnythingOfType("chan *types.FinalisationInfo"))
+	BlockAPI.On("GetHeader", blockHash).Return(header, nil)
+	wsconn.BlockAPI = BlockAPI
+	wsconn.TxStateAPI = modules.NewMockTransactionStateAPI()
+
+	esl := NewExtrinsicSubmitListener(wsconn, []byte{1, 2, 3}, notifyImportedChan, txStatusChan,
+		make(chan *types.FinalisationInfo, 100))
+	esl.Listen()
+
+	txStatusChan <- transaction.StatusNotification{
+		Status: transaction.InBlock,
+		Hash:   &blockHash,
+	}
+	_, _, err := ws.ReadMessage()
+	require.NoError(t, err)
+
+	for _, number := range []uint{finalityTimeoutBlocks, finalityTimeoutBlocks + 1} {
+		block := types.NewBlock(*types.NewEmptyHeader(), nil)
+		block.Header.Number = number
+		notifyImportedChan <- &block
 	}
-	time.Sleep(time.Second * 2)
 
-	_, msg, err = ws.ReadMessage()
+	_, msg, err := ws.ReadMessage()
 	require.NoError(t, err)
-	resFinalised := map[string]interface{}{"finalised": block.Header.Hash().String()}
-	expectedFinalizedBytes, err := json.Marshal(
-		newSubscriptionResponse(authorExtrinsicUpdatesMethod, esl.subID, resFinalised))
+	expectedBytes, err := json.Marshal(newSubscriptionResponse(authorExtrinsicUpdatesMethod, esl.subID,
+		map[string]interface{}{"finalityTimeout": blockHash.String()}))
 	require.NoError(t, err)
-	require.Equal(t, string(expectedFinalizedBytes)+"\n", string(msg))
+	require.Equal(t, string(expectedBytes)+"\n", string(msg))
+
+	// the listener stops after the final status
+	select {
+	case <-esl.done:
+	case <-time.After(time.Second):
+		t.Fatal("listener did not stop")
+	}
 }
 
 func TestGrandpaJustification_Listen(t *testing.T) {
@@ -308,8 +308,6 @@ func (c *WSConn) initExtrinsicWatch(reqID float64, params interface{}) (Listener
 	}
 
 	c.safeSend(NewSubscriptionResponseJSON(extSubmitListener.subID, reqID))
-
-	// todo (ed) determine which peer extrinsic has been broadcast to, and set status (#1535)
 	return extSubmitListener, err
 }
 
@@ -13,6 +13,7 @@
 
 	"github.com/ChainSafe/gossamer/dot/core"
 	"github.com/ChainSafe/gossamer/dot/digest"
+	"github.com/ChainSafe/gossamer/dot/light"
 	"github.com/ChainSafe/gossamer/dot/network"
 	"github.com/ChainSafe/gossamer/dot/rpc"
 	"github.com/ChainSafe/gossamer/dot/rpc/modules"
@@ -47,6 +48,7 @@ type rpcServiceSettings struct {
 	system        *system.Service
 	blockFinality *grandpa.Service
 	syncer        *sync.Service
+	lightClient   *light.Client
 }
 
 func newInMemoryDB(path string) (chaindb.Database, error) {
@@ -61,6 +63,12 @@ func createStateService(cfg *Config) (*state.Service, error) {
 		Path:     cfg.Global.BasePath,
 		LogLevel: cfg.Log.StateLvl,
 		Metrics:  metrics.NewIntervalConfig(cfg.Global.PublishMetrics),
+
+		TransactionBanDuration: cfg.Core.TransactionBanDuration,
+		TransactionLimits:      cfg.Core.TransactionLimits,
+
+		TransactionIndexRetention: cfg.Core.TransactionIndexRetention,
+		KeyChangesIndex:           cfg.Core.KeyChangesIndex,
 	}
 
 	stateSrvc := state.NewService(config)
@@ -134,48 +142,67 @@ func createRuntime(cfg *Config, ns runtime.NodeStorage, st *state.Service,
 		return nil, err
 	}
 
-	var rt runtime.Instance
+	instanceCfg := runtime.InstanceConfig{
+		Storage:     ts,
+		Keystore:    ks,
+		LogLvl:      cfg.Log.RuntimeLvl,
+		NodeStorage: ns,
+		Network:     net,
+		Role:        cfg.Core.Roles,
+		CodeHash:    codeHash,
+	}
+
+	rt, err := newRuntimeInstance(cfg, code, instanceCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	st.Block.StoreRuntime(st.Block.BestBlockHash(), rt)
+	return rt, nil
+}
+
+// newRuntimeInstance creates an instance of the runtime code with the wasm interpreter of the configuration
+func newRuntimeInstance(cfg *Config, code []byte, instanceCfg runtime.InstanceConfig) (runtime.Instance, error) {
+	var (
+		rt  runtime.Instance
+		err error
+	)
+
 	switch cfg.Core.WasmInterpreter {
 	case wasmer.Name:
 		rtCfg := &wasmer.Config{
-			Imports: wasmer.ImportsNodeRuntime,
+			InstanceConfig: instanceCfg,
+			Imports:        wasmer.ImportsNodeRuntime,
+			Executor:       newRuntimeExecutor(cfg),
 		}
-		rtCfg.Storage = ts
-		rtCfg.Keystore = ks
-		rtCfg.LogLvl = cfg.Log.RuntimeLvl
-		rtCfg.NodeStorage = ns
-		rtCfg.Network = net
-		rtCfg.Role = cfg.Core.Roles
-		rtCfg.CodeHash = codeHash
-
-		// create runtime executor
 		rt, err = wasmer.NewInstance(code, rtCfg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create runtime executor: %s", err)
-		}
 	case life.Name:
 		rtCfg := &life.Config{
-			Resolver: new(life.Resolver),
+			InstanceConfig: instanceCfg,
+			Resolver:       new(life.Resolver),
 		}
-		rtCfg.Storage = ts
-		rtCfg.Keystore = ks
-		rtCfg.LogLvl = cfg.Log.RuntimeLvl
-		rtCfg.NodeStorage = ns
-		rtCfg.Network = net
-		rtCfg.Role = cfg.Core.Roles
-		rtCfg.CodeHash = codeHash
-
-		// create runtime executor
 		rt, err = life.NewInstance(code, rtCfg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create runtime executor: %s", err)
-		}
+	default:
+		return nil, fmt.Errorf("unknown wasm interpreter: %s", cfg.Core.WasmInterpreter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create runtime executor: %s", err)
 	}
 
-	st.Block.StoreRuntime(st.Block.BestBlockHash(), rt)
 	return rt, nil
 }
 
+// newRuntimeExecutor returns the executor creating the pooled wasmer runtime instances,
+// which caches the compiled runtimes in the base path if the runtime cache is enabled.
+func newRuntimeExecutor(cfg *Config) *wasmer.Executor {
+	var cacheDir string
+	if cfg.Core.RuntimeCache {
+		cacheDir = filepath.Join(cfg.Global.BasePath, "runtime-cache")
+	}
+
+	return wasmer.NewExecutor(cacheDir, cfg.Core.RuntimePoolSize)
+}
+
 func asAuthority(authority bool) string {
 	if authority {
 		return " as authority"
@@ -257,6 +284,7 @@ func createCoreService(cfg *Config, ks *keystore.GlobalKeystore,
 		DigestHandler:        dh,
 		CodeSubstitutes:      codeSubs,
 		CodeSubstitutedState: st.Base,
+		OffchainWorkerMode:   cfg.Core.OffchainWorker,
 	}
 
 	// create new core service
@@ -298,12 +326,25 @@ func createNetworkService(cfg *Config, stateSrvc *state.Service,
 		MinPeers:          cfg.Network.MinPeers,
 		MaxPeers:          cfg.Network.MaxPeers,
 		PersistentPeers:   cfg.Network.PersistentPeers,
+		ReservedOnly:      cfg.Network.ReservedOnly,
 		DiscoveryInterval: cfg.Network.DiscoveryInterval,
 		SlotDuration:      slotDuration,
 		PublicIP:          cfg.Network.PublicIP,
 		Telemetry:         telemetryMailer,
 		PublicDNS:         cfg.Network.PublicDNS,
 		Metrics:           metrics.NewIntervalConfig(cfg.Global.PublishMetrics),
+		TransactionsPeerSet: network.PeerSetConfig{
+			MaxInPeers:    cfg.Network.TransactionsInPeers,
+			MaxOutPeers:   cfg.Network.TransactionsOutPeers,
+			ReservedPeers: cfg.Network.TransactionsReservedPeers,
+			ReservedOnly:  cfg.Network.TransactionsReservedOnly,
+		},
+		GrandpaPeerSet: network.PeerSetConfig{
+			MaxInPeers:    cfg.Network.GrandpaInPeers,
+			MaxOutPeers:   cfg.Network.GrandpaOutPeers,
+			ReservedPeers: cfg.Network.GrandpaReservedPeers,
+			ReservedOnly:  cfg.Network.GrandpaReservedOnly,
+		},
 	}
 
 	networkSrvc, err := network.NewService(&networkConfig)
@@ -341,12 +382,23 @@ func createRPCService(params rpcServiceSettings) (*rpc.HTTPServer, error) {
 		return nil, fmt.Errorf("failed to create sync state service: %s", err)
 	}
 
+	var (
+		storageAPI modules.StorageAPI = params.state.Storage
+		coreAPI    modules.CoreAPI    = params.core
+	)
+
+	// a light client does not have the state, so state queries are answered by full peers
+	if params.lightClient != nil {
+		storageAPI = light.NewStorageAPI(params.lightClient)
+		coreAPI = light.NewCoreAPI(params.core, params.lightClient)
+	}
+
 	rpcConfig := &rpc.HTTPServerConfig{
 		LogLvl:              params.config.Log.RPCLvl,
 		BlockAPI:            params.state.Block,
-		StorageAPI:          params.state.Storage,
+		StorageAPI:          storageAPI,
 		NetworkAPI:          params.network,
-		CoreAPI:             params.core,
+		CoreAPI:             coreAPI,
 		NodeStorage:         params.nodeStorage,
 		BlockProducerAPI:    params.blockProducer,
 		BlockFinalityAPI:    params.blockFinality,
@@ -455,11 +507,27 @@ func newSyncService(cfg *Config, st *state.Service, fg sync.FinalityGadget,
 		MaxPeers:           cfg.Network.MaxPeers,
 		SlotDuration:       slotDuration,
 		Telemetry:          telemetryMailer,
+		HeadersOnly:        cfg.Core.Roles == types.LightClientRole,
+		WarpSync:           cfg.Core.WarpSync,
 	}
 
 	return sync.NewService(syncCfg)
 }
 
+func createLightClient(cfg *Config, st *state.Service, net *network.Service) (*light.Client, error) {
+	if net == nil {
+		return nil, errors.New("network service is required to run a light client")
+	}
+
+	lightCfg := &light.Config{
+		LogLvl:     cfg.Log.SyncLvl,
+		BlockState: st.Block,
+		Network:    net,
+	}
+
+	return light.NewClient(lightCfg)
+}
+
 func createDigestHandler(lvl log.Level, st *state.Service) (*digest.Handler, error) {
 	return digest.NewHandler(lvl, st.Block, st.Epoch, st.Grandpa)
 }
@@ -21,6 +21,7 @@
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 
+	"github.com/ChainSafe/gossamer/lib/runtime/life"
 	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
 )
@@ -69,6 +70,14 @@ type BlockState struct {
 	runtimeUpdateSubscriptions     map[uint32]chan<- runtime.Version
 
 	telemetry telemetry.Client
+
+	// indexedTransactionsLock protects the indexed transactions and their references
+	indexedTransactionsLock   sync.RWMutex
+	transactionIndexRetention uint
+
+	// keyChangesLock protects whether the storage keys modified by the blocks are indexed
+	keyChangesLock  sync.RWMutex
+	keyChangesIndex bool
 }
 
 // NewBlockState will create a new BlockState backed by the database located at basePath
@@ -308,8 +317,8 @@ func (bs *BlockState) HasBlockBody(hash common.Hash) (bool, error) {
 	bs.RLock()
 	defer bs.RUnlock()
 
-	if bs.unfinalisedBlocks.getBlock(hash) != nil {
-		return true, nil
+	if block := bs.unfinalisedBlocks.getBlock(hash); block != nil {
+		return block.Body != nil, nil
 	}
 
 	return bs.db.Has(blockBodyKey(hash))
@@ -384,6 +393,22 @@ func (bs *BlockState) AddBlockWithArrivalTime(block *types.Block, arrivalTime ti
 	return nil
 }
 
+// AddHeader adds the block of a header to the blocktree and the DB without its body,
+// for the nodes syncing headers only. The block is reported as having no body.
+func (bs *BlockState) AddHeader(header *types.Header) error {
+	bs.Lock()
+	defer bs.Unlock()
+
+	if err := bs.bt.AddBlock(header, time.Now()); err != nil {
+		return err
+	}
+
+	block := &types.Block{Header: *header}
+	bs.unfinalisedBlocks.store(block)
+	go bs.notifyImported(block)
+	return nil
+}
+
 // AddBlockToBlockTree adds the given block to the blocktree. It does not write it to the database.
 // TODO: remove this func and usage from sync (after sync refactor?)
 func (bs *BlockState) AddBlockToBlockTree(block *types.Block) error {
@@ -604,21 +629,39 @@ func (bs *BlockState) HandleRuntimeChanges(newState *rtstorage.TrieState,
 			bHash, codeHash, previousVersion.SpecVersion(), currCodeHash, newVersion.SpecVersion())
 	}
 
-	rtCfg := &wasmer.Config{
-		Imports: wasmer.ImportsNodeRuntime,
+	instanceCfg := runtime.InstanceConfig{
+		Storage:     newState,
+		Keystore:    rt.Keystore(),
+		NodeStorage: rt.NodeStorage(),
+		Network:     rt.NetworkService(),
+		CodeHash:    currCodeHash,
 	}
 
-	rtCfg.Storage = newState
-	rtCfg.Keystore = rt.Keystore()
-	rtCfg.NodeStorage = rt.NodeStorage()
-	rtCfg.Network = rt.NetworkService()
-	rtCfg.CodeHash = currCodeHash
-
 	if rt.Validator() {
-		rtCfg.Role = 4
-	}
+		instanceCfg.Role = 4
+	}
+
+	// the new runtime runs on the wasm interpreter of the previous one
+	var instance runtime.Instance
+	switch rt.(type) {
+	case *life.Instance:
+		instance, err = life.NewInstance(code, &life.Config{
+			InstanceConfig: instanceCfg,
+			Resolver:       new(life.Resolver),
+		})
+	default:
+		rtCfg := &wasmer.Config{
+			InstanceConfig: instanceCfg,
+			Imports:        wasmer.ImportsNodeRuntime,
+		}
 
-	instance, err := wasmer.NewInstance(code, rtCfg)
+		// the new runtime is pooled if the previous one is
+		if previous, ok := rt.(*wasmer.Instance); ok {
+			rtCfg.Executor = previous.Executor()
+		}
+
+		instance, err = wasmer.NewInstance(code, rtCfg)
+	}
 	if err != nil {
 		return err
 	}
@@ -641,14 +684,22 @@ func (bs *BlockState) HandleRuntimeChanges(newState *rtstorage.TrieState,
 // GetRuntime gets the runtime for the corresponding block hash.
 func (bs *BlockState) GetRuntime(hash *common.Hash) (runtime.Instance, error) {
 	if hash == nil {
-		rt, err := bs.bt.GetBlockRuntime(bs.BestBlockHash())
-		if err != nil {
-			return nil, err
-		}
-		return rt, nil
+		best := bs.BestBlockHash()
+		hash = &best
+	}
+
+	rt, err := bs.bt.GetBlockRuntime(*hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// each caller of a pooled runtime gets its own handle, so that the callers
+	// setting the context storage can use the runtime concurrently
+	if pooled, ok := rt.(runtime.PooledInstance); ok {
+		return pooled.Handle(), nil
 	}
 
-	return bs.bt.GetBlockRuntime(*hash)
+	return rt, nil
 }
 
 // StoreRuntime stores the runtime for corresponding block hash.
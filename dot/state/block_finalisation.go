@@ -6,9 +6,11 @@
 import (
 	"encoding/binary"
 	"fmt"
+	"time"
 
 	"github.com/ChainSafe/gossamer/dot/telemetry"
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/blocktree"
 	"github.com/ChainSafe/gossamer/lib/common"
 )
 
@@ -138,6 +140,11 @@ func (bs *BlockState) SetFinalisedHash(hash common.Hash, round, setID uint64) er
 		bs.notifyFinalized(hash, round, setID)
 	}
 
+	previousFinalised, err := bs.GetHeader(bs.lastFinalised)
+	if err != nil {
+		return fmt.Errorf("failed to get last finalised header: %w", err)
+	}
+
 	pruned := bs.bt.Prune(hash)
 	for _, hash := range pruned {
 		blockHeader := bs.unfinalisedBlocks.delete(hash)
@@ -163,6 +170,16 @@ func (bs *BlockState) SetFinalisedHash(hash common.Hash, round, setID uint64) er
 		return fmt.Errorf("failed to get finalised header, hash: %s, error: %s", hash, err)
 	}
 
+	err = bs.pruneIndexedTransactions(pruned, previousFinalised.Number, header.Number)
+	if err != nil {
+		return fmt.Errorf("failed to prune indexed transactions: %w", err)
+	}
+
+	err = bs.deleteKeyChanges(pruned)
+	if err != nil {
+		return fmt.Errorf("failed to delete key changes of pruned blocks: %w", err)
+	}
+
 	bs.telemetry.SendMessage(
 		telemetry.NewNotifyFinalized(
 			header.Hash(),
@@ -233,8 +250,11 @@ func (bs *BlockState) handleFinalisedBlock(curr common.Hash) error {
 			return err
 		}
 
-		if err = bs.SetBlockBody(hash, &block.Body); err != nil {
-			return err
+		// blocks imported from their header only have no body to store
+		if block.Body != nil {
+			if err = bs.SetBlockBody(hash, &block.Body); err != nil {
+				return err
+			}
 		}
 
 		arrivalTime, err := bs.bt.GetArrivalTime(hash)
@@ -276,3 +296,45 @@ func (bs *BlockState) setFirstSlotOnFinalisation() error {
 
 	return bs.baseState.storeFirstSlot(slot)
 }
+
+// SetWarpSyncedHeader sets the finalised header we warp synced to as the latest finalised block.
+// Since its ancestors are unknown, the block tree is replaced with one rooted at the header,
+// so it must be called before importing the blocks following it. The runtime of the previous
+// finalised block is kept for the header, until it is updated with the downloaded state.
+func (bs *BlockState) SetWarpSyncedHeader(header *types.Header, round, setID uint64) error {
+	bs.Lock()
+	defer bs.Unlock()
+
+	hash := header.Hash()
+	if err := bs.SetHeader(header); err != nil {
+		return fmt.Errorf("failed to set header: %w", err)
+	}
+
+	if err := bs.db.Put(headerHashKey(uint64(header.Number)), hash.ToBytes()); err != nil {
+		return fmt.Errorf("failed to set header hash key: %w", err)
+	}
+
+	if err := bs.setArrivalTime(hash, time.Now()); err != nil {
+		return fmt.Errorf("failed to set arrival time: %w", err)
+	}
+
+	if err := bs.db.Put(finalisedHashKey(round, setID), hash[:]); err != nil {
+		return fmt.Errorf("failed to set finalised hash key: %w", err)
+	}
+
+	if err := bs.setHighestRoundAndSetID(round, setID); err != nil {
+		return fmt.Errorf("failed to set highest round and set ID: %w", err)
+	}
+
+	rt, err := bs.bt.GetBlockRuntime(bs.lastFinalised)
+	bs.bt = blocktree.NewBlockTreeFromRoot(header)
+	if err == nil {
+		bs.bt.StoreRuntime(hash, rt)
+	}
+
+	bs.unfinalisedBlocks = newHashToBlockMap()
+	bs.lastFinalised = hash
+
+	bs.notifyFinalized(hash, round, setID)
+	return nil
+}
@@ -152,3 +152,31 @@ func TestSetFinalisedHash_setFirstSlotOnFinalisation(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, firstSlot, res)
 }
+
+func TestBlockState_SetWarpSyncedHeader(t *testing.T) {
+	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+
+	header := &types.Header{
+		ParentHash: common.Hash{1},
+		Number:     100,
+		Digest:     types.NewDigest(),
+		StateRoot:  trie.EmptyHash,
+	}
+
+	err := bs.SetWarpSyncedHeader(header, 5, 2)
+	require.NoError(t, err)
+
+	finalised, err := bs.GetHighestFinalisedHeader()
+	require.NoError(t, err)
+	require.Equal(t, header.Hash(), finalised.Hash())
+
+	round, setID, err := bs.GetHighestRoundAndSetID()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), round)
+	require.Equal(t, uint64(2), setID)
+
+	hash, err := bs.GetHashByNumber(100)
+	require.NoError(t, err)
+	require.Equal(t, header.Hash(), hash)
+	require.Equal(t, header.Hash(), bs.BestBlockHash())
+}
@@ -166,6 +166,39 @@ func() {
 	require.Equal(t, block1.Header.Hash(), bs.BestBlockHash(), "Latest Header Block Check Fail")
 }
 
+func TestAddHeader(t *testing.T) {
+	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+
+	header := &types.Header{
+		Number:     1,
+		Digest:     createPrimaryBABEDigest(t),
+		ParentHash: testGenesisHeader.Hash(),
+	}
+	hash := header.Hash()
+
+	err := bs.AddHeader(header)
+	require.NoError(t, err)
+
+	retHeader, err := bs.GetHeader(hash)
+	require.NoError(t, err)
+	require.Equal(t, header.Hash(), retHeader.Hash())
+	require.Equal(t, hash, bs.BestBlockHash())
+
+	hasBody, err := bs.HasBlockBody(hash)
+	require.NoError(t, err)
+	require.False(t, hasBody)
+
+	err = bs.SetFinalisedHash(hash, 0, 0)
+	require.NoError(t, err)
+
+	hasBody, err = bs.HasBlockBody(hash)
+	require.NoError(t, err)
+	require.False(t, hasBody)
+
+	_, err = bs.GetBlockBody(hash)
+	require.Error(t, err)
+}
+
 func TestGetSlotForBlock(t *testing.T) {
 	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
 	expectedSlot := uint64(77)
@@ -51,7 +51,7 @@ func (h *hashToBlockMap) getBlockHeader(hash common.Hash) (header *types.Header)
 }
 
 // getBlockBody returns a pointer to the body of the block stored at the
-// hash given, or nil if not found.
+// hash given, or nil if not found or if the block was stored without body.
 // Note this returns a pointer to the body of the block so modifying the
 // returned value will modify the body of the block stored in the map,
 // potentially leading to data races or unwanted changes, so be careful.
@@ -59,7 +59,7 @@ func (h *hashToBlockMap) getBlockBody(hash common.Hash) (body *types.Body) {
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
 	block := h.mapping[hash]
-	if block == nil {
+	if block == nil || block.Body == nil {
 		return nil
 	}
 	return &block.Body
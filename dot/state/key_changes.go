@@ -0,0 +1,81 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+var keyChangesPrefix = []byte("kch") // keyChangesPrefix + block hash -> storage keys modified by the block
+
+func keyChangesKey(hash common.Hash) []byte {
+	return append(keyChangesPrefix, hash.ToBytes()...)
+}
+
+// SetKeyChangesIndex sets whether the storage keys modified by the imported blocks are indexed
+func (bs *BlockState) SetKeyChangesIndex(enabled bool) {
+	bs.keyChangesLock.Lock()
+	defer bs.keyChangesLock.Unlock()
+	bs.keyChangesIndex = enabled
+}
+
+// keyChangesIndexed returns whether the storage keys modified by the imported blocks are indexed
+func (bs *BlockState) keyChangesIndexed() bool {
+	bs.keyChangesLock.RLock()
+	defer bs.keyChangesLock.RUnlock()
+	return bs.keyChangesIndex
+}
+
+// StoreKeyChanges stores the storage keys modified by the execution of the block with the given hash,
+// if the key changes index is enabled. The changes of a block are relative to the state of its parent.
+func (bs *BlockState) StoreKeyChanges(hash common.Hash, changes *rtstorage.KeyChanges) error {
+	bs.keyChangesLock.RLock()
+	defer bs.keyChangesLock.RUnlock()
+	if !bs.keyChangesIndex {
+		return nil
+	}
+
+	enc, err := scale.Marshal(*changes)
+	if err != nil {
+		return err
+	}
+
+	return bs.db.Put(keyChangesKey(hash), enc)
+}
+
+// GetKeyChanges returns the storage keys modified by the execution of the block with the given hash.
+// It returns chaindb.ErrKeyNotFound if the changes of the block are not indexed.
+func (bs *BlockState) GetKeyChanges(hash common.Hash) (*rtstorage.KeyChanges, error) {
+	enc, err := bs.db.Get(keyChangesKey(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := new(rtstorage.KeyChanges)
+	err = scale.Unmarshal(enc, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// deleteKeyChanges deletes the indexed key changes of the pruned blocks
+func (bs *BlockState) deleteKeyChanges(pruned []common.Hash) error {
+	if len(pruned) == 0 {
+		return nil
+	}
+
+	batch := bs.db.NewBatch()
+	for _, hash := range pruned {
+		err := batch.Del(keyChangesKey(hash))
+		if err != nil {
+			return err
+		}
+	}
+
+	return batch.Flush()
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockState_StoreKeyChanges(t *testing.T) {
+	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+	hash := common.Hash{1}
+	changes := &rtstorage.KeyChanges{
+		Keys:       [][]byte{[]byte(":child_storage:default:child"), []byte("key")},
+		ChildTries: [][]byte{[]byte("child")},
+	}
+
+	// the changes are not stored while the index is disabled
+	err := bs.StoreKeyChanges(hash, changes)
+	require.NoError(t, err)
+	_, err = bs.GetKeyChanges(hash)
+	require.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+
+	bs.SetKeyChangesIndex(true)
+	err = bs.StoreKeyChanges(hash, changes)
+	require.NoError(t, err)
+
+	res, err := bs.GetKeyChanges(hash)
+	require.NoError(t, err)
+	require.Equal(t, changes, res)
+
+	err = bs.deleteKeyChanges([]common.Hash{hash})
+	require.NoError(t, err)
+	_, err = bs.GetKeyChanges(hash)
+	require.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+}
@@ -6,6 +6,7 @@
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/ChainSafe/gossamer/dot/state/pruner"
 	"github.com/ChainSafe/gossamer/dot/telemetry"
@@ -13,6 +14,7 @@
 	"github.com/ChainSafe/gossamer/internal/log"
 	"github.com/ChainSafe/gossamer/internal/metrics"
 	"github.com/ChainSafe/gossamer/lib/blocktree"
+	"github.com/ChainSafe/gossamer/lib/transaction"
 	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/lib/utils"
 
@@ -40,6 +42,12 @@ type Service struct {
 	PrunerCfg pruner.Config
 	Telemetry telemetry.Client
 
+	transactionBanDuration time.Duration
+	transactionLimits      transaction.Limits
+
+	transactionIndexRetention uint
+	keyChangesIndex           bool
+
 	// Below are for testing only.
 	BabeThresholdNumerator   uint64
 	BabeThresholdDenominator uint64
@@ -52,6 +60,17 @@ type Config struct {
 	PrunerCfg pruner.Config
 	Telemetry telemetry.Client
 	Metrics   metrics.IntervalConfig
+	// TransactionBanDuration is the duration removed transactions stay banned for,
+	// it defaults to transaction.DefaultBanDuration if zero.
+	TransactionBanDuration time.Duration
+	// TransactionLimits are the limits of the transactions held in the pool and queue,
+	// the zero limits default to their transaction package default value.
+	TransactionLimits transaction.Limits
+	// TransactionIndexRetention is the number of finalised blocks the indexed transactions are kept for
+	// after the last block indexing or renewing them, they are kept forever if zero.
+	TransactionIndexRetention uint
+	// KeyChangesIndex determines whether the storage keys modified by the imported blocks are indexed
+	KeyChangesIndex bool
 }
 
 // NewService create a new instance of Service
@@ -68,6 +87,12 @@ func NewService(config Config) *Service {
 		closeCh:   make(chan interface{}),
 		PrunerCfg: config.PrunerCfg,
 		Telemetry: config.Telemetry,
+
+		transactionBanDuration: config.TransactionBanDuration,
+		transactionLimits:      config.TransactionLimits,
+
+		transactionIndexRetention: config.TransactionIndexRetention,
+		keyChangesIndex:           config.KeyChangesIndex,
 	}
 }
 
@@ -123,6 +148,8 @@ func (s *Service) Start() error {
 	if err != nil {
 		return fmt.Errorf("failed to create block state: %w", err)
 	}
+	s.Block.SetTransactionIndexRetention(s.transactionIndexRetention)
+	s.Block.SetKeyChangesIndex(s.keyChangesIndex)
 
 	// retrieve latest header
 	bestHeader, err := s.Block.GetHighestFinalisedHeader()
@@ -151,7 +178,12 @@ func (s *Service) Start() error {
 	}
 
 	// create transaction queue
-	s.Transaction = NewTransactionState(s.Telemetry)
+	s.Transaction = newTransactionState(s.Telemetry, s.transactionBanDuration, s.transactionLimits)
+	bestNumber, err := s.Block.BestBlockNumber()
+	if err != nil {
+		return fmt.Errorf("failed to get best block number: %w", err)
+	}
+	s.Transaction.setBestNumber(bestNumber)
 
 	// create epoch state
 	s.Epoch, err = NewEpochState(s.db, s.Block)
@@ -164,10 +196,9 @@ func (s *Service) Start() error {
 		return fmt.Errorf("failed to create grandpa state: %w", err)
 	}
 
-	num, _ := s.Block.BestBlockNumber()
 	logger.Infof(
 		"created state service with head %s, highest number %d and genesis hash %s",
-		s.Block.BestBlockHash(), num, s.Block.genesisHash.String())
+		s.Block.BestBlockHash(), bestNumber, s.Block.genesisHash.String())
 
 	return nil
 }
@@ -101,7 +101,8 @@ func (s *StorageState) StoreTrie(ts *rtstorage.TrieState, header *types.Header)
 		return err
 	}
 
-	go s.notifyAll(root)
+	changes := ts.KeyChanges()
+	go s.notifyAll(root, changes)
 	return nil
 }
 
@@ -120,7 +121,9 @@ func (s *StorageState) TrieState(root *common.Hash) (*rtstorage.TrieState, error
 	if t == nil {
 		var err error
 		t, err = s.LoadFromDB(*root)
-		if err != nil {
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			return nil, errTrieDoesNotExist(*root)
+		} else if err != nil {
 			return nil, err
 		}
 
@@ -135,6 +138,11 @@ func (s *StorageState) TrieState(root *common.Hash) (*rtstorage.TrieState, error
 		return nil, err
 	}
 
+	// the keys modified in the state are only needed to index them
+	if s.blockState != nil && s.blockState.keyChangesIndexed() {
+		next.RecordKeyChanges()
+	}
+
 	logger.Tracef("returning trie with root %s to be modified", root)
 	return next, nil
 }
@@ -4,11 +4,13 @@
 package state
 
 import (
+	"bytes"
 	"fmt"
 	"reflect"
 	"strings"
 
 	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 )
 
 // KeyValue struct to hold key value pairs
@@ -57,7 +59,7 @@ func (s *StorageState) RegisterStorageObserver(o Observer) {
 		return
 	}
 	go func() {
-		if err := s.notifyObserver(sr, o); err != nil {
+		if err := s.notifyObserver(sr, o, nil); err != nil {
 			logger.Warnf("failed to notify storage subscriptions: %s", err)
 		}
 	}()
@@ -69,18 +71,21 @@ func (s *StorageState) UnregisterStorageObserver(o Observer) {
 	s.observerList = s.removeFromSlice(s.observerList, o)
 }
 
-func (s *StorageState) notifyAll(root common.Hash) {
+// notifyAll notifies the observers of the changes of the state with the given root.
+// The observers without a filter are sent the values of the given modified keys, or of every key
+// if the state has no change set. The filtered keys are compared against the state in any case.
+func (s *StorageState) notifyAll(root common.Hash, changes *rtstorage.KeyChanges) {
 	s.changedLock.RLock()
 	defer s.changedLock.RUnlock()
 	for _, observer := range s.observerList {
-		err := s.notifyObserver(root, observer)
+		err := s.notifyObserver(root, observer, changes)
 		if err != nil {
 			logger.Warnf("failed to notify storage subscriptions: %s", err)
 		}
 	}
 }
 
-func (s *StorageState) notifyObserver(root common.Hash, o Observer) error {
+func (s *StorageState) notifyObserver(root common.Hash, o Observer, changes *rtstorage.KeyChanges) error {
 	t, err := s.TrieState(&root)
 	if err != nil {
 		return err
@@ -93,7 +98,21 @@ func (s *StorageState) notifyObserver(root common.Hash, o Observer) error {
 	subRes := &SubscriptionResult{
 		Hash: root,
 	}
-	if len(o.GetFilter()) == 0 {
+	filter := o.GetFilter()
+	if len(filter) == 0 && changes != nil {
+		// no filter, so send the changes of every modified key
+		for _, key := range changes.Keys {
+			if bytes.Equal(key, common.CodeKey) {
+				// currently we're ignoring :code since this is a lot of data
+				continue
+			}
+
+			subRes.Changes = append(subRes.Changes, KeyValue{
+				Key:   key,
+				Value: t.Get(key),
+			})
+		}
+	} else if len(filter) == 0 {
 		// no filter, so send all changes
 		ent := t.TrieEntries()
 		for k, v := range ent {
@@ -108,15 +127,16 @@ func (s *StorageState) notifyObserver(root common.Hash, o Observer) error {
 		}
 	} else {
 		// filter result to include only interested keys
-		for k, cachedValue := range o.GetFilter() {
-			value := t.Get(common.MustHexToBytes(k))
+		for k, cachedValue := range filter {
+			key := common.MustHexToBytes(k)
+			value := t.Get(key)
 			if !reflect.DeepEqual(cachedValue, value) {
 				kv := &KeyValue{
-					Key:   common.MustHexToBytes(k),
+					Key:   key,
 					Value: value,
 				}
 				subRes.Changes = append(subRes.Changes, *kv)
-				o.GetFilter()[k] = value
+				filter[k] = value
 			}
 		}
 	}
@@ -13,6 +13,8 @@
 
 	"github.com/ChainSafe/chaindb"
 	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -153,6 +155,47 @@ func TestStorageState_RegisterStorageObserver_Multi_Filter(t *testing.T) {
 	}
 }
 
+func TestStorageState_notifyObserver_changes(t *testing.T) {
+	ss := newTestStorageState(t, newTriesEmpty())
+	ts, err := ss.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+	ts.Set([]byte("a"), []byte{1})
+	ts.Set([]byte("b"), []byte{2})
+	err = ss.StoreTrie(ts, nil)
+	require.NoError(t, err)
+	root := ts.MustRoot()
+
+	newObserver := func(filter map[string][]byte) (*MockObserver, chan *SubscriptionResult) {
+		results := make(chan *SubscriptionResult, 1)
+		observer := &MockObserver{}
+		observer.On("GetFilter").Return(filter)
+		observer.On("Update", mock.AnythingOfType("*state.SubscriptionResult")).Run(func(args mock.Arguments) {
+			results <- args.Get(0).(*SubscriptionResult)
+		})
+		return observer, results
+	}
+
+	a := KeyValue{Key: []byte("a"), Value: []byte{1}}
+	b := KeyValue{Key: []byte("b"), Value: []byte{2}}
+	changes := &rtstorage.KeyChanges{Keys: [][]byte{[]byte("a")}}
+
+	// the observers without a filter are sent the modified keys, or every key without a change set
+	observer, results := newObserver(map[string][]byte{})
+	err = ss.notifyObserver(root, observer, changes)
+	require.NoError(t, err)
+	require.Equal(t, []KeyValue{a}, (<-results).Changes)
+
+	err = ss.notifyObserver(root, observer, nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []KeyValue{a, b}, (<-results).Changes)
+
+	// the filtered keys are compared against the state, even if they are not modified
+	observer, results = newObserver(map[string][]byte{common.BytesToHex([]byte("b")): {}})
+	err = ss.notifyObserver(root, observer, changes)
+	require.NoError(t, err)
+	require.Equal(t, []KeyValue{b}, (<-results).Changes)
+}
+
 func Test_Example(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping subscription example")
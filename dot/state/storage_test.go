@@ -105,6 +105,21 @@ func TestStorage_TrieState(t *testing.T) {
 	require.Equal(t, ts.Trie().MustHash(), ts3.Trie().MustHash())
 }
 
+func TestStorage_TrieState_KeyChanges(t *testing.T) {
+	storage := newTestStorageState(t, newTriesEmpty())
+	ts, err := storage.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+	ts.Set([]byte("noot"), []byte("washere"))
+	require.Nil(t, ts.KeyChanges())
+
+	// the modified keys are recorded only when they are indexed
+	storage.blockState.SetKeyChangesIndex(true)
+	ts, err = storage.TrieState(&trie.EmptyHash)
+	require.NoError(t, err)
+	ts.Set([]byte("noot"), []byte("washere"))
+	require.Equal(t, [][]byte{[]byte("noot")}, ts.KeyChanges().Keys)
+}
+
 func TestStorage_LoadFromDB(t *testing.T) {
 	storage := newTestStorageState(t, newTriesEmpty())
 	ts, err := storage.TrieState(&trie.EmptyHash)
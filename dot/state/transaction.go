@@ -4,7 +4,11 @@
 package state
 
 import (
+	"bytes"
+	"errors"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/ChainSafe/gossamer/dot/telemetry"
 
@@ -13,38 +17,309 @@
 	"github.com/ChainSafe/gossamer/lib/transaction"
 )
 
-// TransactionState represents the queue of transactions
+// ErrTooLowPriority is returned when pushing a transaction to the queue which provides the same tags
+// as a transaction of the queue with a higher or equal priority
+var ErrTooLowPriority = errors.New("transaction priority is too low to replace transactions providing the same tags")
+
+// TransactionState represents the queue of transactions.
+// The queue holds the ready transactions, and the pool the future transactions which wait
+// for other transactions to provide the tags they require.
 type TransactionState struct {
 	queue *transaction.PriorityQueue
 	pool  *transaction.Pool
 
+	// validTill maps the hashes of the transactions pushed to the number of the last block
+	// they are valid at, according to their longevity.
+	validTill  map[common.Hash]uint64
+	bestNumber uint64
+	validLock  sync.Mutex
+
 	// notifierChannels are used to notify transaction status. It maps a channel to
 	// hex string of the extrinsic it is supposed to notify about.
-	notifierChannels map[chan transaction.Status]string
+	notifierChannels map[chan transaction.StatusNotification]string
 	notifierLock     sync.RWMutex
 
+	// banned are the transactions which were removed and cannot be re-imported
+	banned *transaction.BanList
+
+	// lock is held while changing the queue and pool, so that the transactions
+	// they hold are checked and counted against the limits of the limiter at once.
+	lock    sync.Mutex
+	limiter *transactionLimiter
+
 	telemetry telemetry.Client
 }
 
 // NewTransactionState returns a new TransactionState
 func NewTransactionState(telemetry telemetry.Client) *TransactionState {
+	return newTransactionState(telemetry, transaction.DefaultBanDuration, transaction.Limits{})
+}
+
+// newTransactionState returns a new TransactionState banning the removed transactions
+// for the given duration, or for transaction.DefaultBanDuration if it is zero,
+// and holding transactions within the given limits.
+func newTransactionState(telemetry telemetry.Client, banDuration time.Duration,
+	limits transaction.Limits) *TransactionState {
+	if banDuration == 0 {
+		banDuration = transaction.DefaultBanDuration
+	}
+
 	return &TransactionState{
 		queue:            transaction.NewPriorityQueue(),
 		pool:             transaction.NewPool(),
-		notifierChannels: make(map[chan transaction.Status]string),
+		validTill:        make(map[common.Hash]uint64),
+		notifierChannels: make(map[chan transaction.StatusNotification]string),
+		banned:           transaction.NewBanList(banDuration),
+		limiter:          newTransactionLimiter(limits),
 		telemetry:        telemetry,
 	}
 }
 
-// Push pushes a transaction to the queue, ordered by priority
+// Push pushes a transaction to the queue, ordered by priority, if all the tags it requires are provided
+// by transactions of the queue. Otherwise the transaction is added to the pool of future transactions,
+// until transactions providing these tags are pushed to the queue.
+// The transactions of the queue which provide any of the tags provided by the transaction are
+// usurped by it if they have a lower priority, otherwise the transaction is rejected.
 func (s *TransactionState) Push(vt *transaction.ValidTransaction) (common.Hash, error) {
-	s.notifyStatus(vt.Extrinsic, transaction.Ready)
-	return s.queue.Push(vt)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.push(vt)
+}
+
+func (s *TransactionState) push(vt *transaction.ValidTransaction) (common.Hash, error) {
+	hash := vt.Extrinsic.Hash()
+	if s.queue.Exists(hash) {
+		return hash, transaction.ErrTransactionExists
+	}
+
+	protected := s.providersOf(vt)
+	if !s.isReady(vt) {
+		evicted, err := s.limiter.check(vt, nil, protected)
+		if err != nil {
+			return hash, err
+		}
+
+		isFuture := s.pool.Get(hash) != nil
+		s.pool.Insert(vt)
+		s.admit(vt, evicted)
+		if !isFuture {
+			s.NotifyStatus(vt.Extrinsic, transaction.StatusNotification{Status: transaction.Future})
+		}
+		return hash, nil
+	}
+
+	usurped, err := s.usurpedBy(vt)
+	if err != nil {
+		return hash, err
+	}
+
+	replaced := make([]common.Hash, len(usurped))
+	for i, tx := range usurped {
+		replaced[i] = tx.Extrinsic.Hash()
+	}
+
+	evicted, err := s.limiter.check(vt, replaced, protected)
+	if err != nil {
+		return hash, err
+	}
+
+	hash, err = s.queue.Push(vt)
+	if err != nil {
+		return hash, err
+	}
+
+	for _, tx := range usurped {
+		s.dropFromQueue(tx.Extrinsic)
+		s.limiter.release(tx.Extrinsic.Hash())
+		s.NotifyStatus(tx.Extrinsic, transaction.StatusNotification{
+			Status: transaction.Usurped,
+			Hash:   &hash,
+		})
+	}
+
+	s.pool.Remove(hash)
+	s.admit(vt, evicted)
+	s.NotifyStatus(vt.Extrinsic, transaction.StatusNotification{Status: transaction.Ready})
+
+	s.promote(vt.Validity.Provides)
+	return hash, nil
+}
+
+// admit counts the transaction added to the queue or pool against the transaction limits,
+// dropping the transactions with a lower priority evicted to make room for it, and tracks
+// its longevity. It must be called with the lock held.
+func (s *TransactionState) admit(vt *transaction.ValidTransaction, evicted []common.Hash) {
+	s.limiter.add(vt, evicted)
+
+	for _, hash := range evicted {
+		tx := s.pool.Get(hash)
+		if tx == nil {
+			tx = s.queue.Get(hash)
+		}
+
+		if tx == nil {
+			continue
+		}
+
+		logger.Debugf("evicting transaction %s", hash)
+		s.dropExtrinsic(tx.Extrinsic)
+		s.NotifyStatus(tx.Extrinsic, transaction.StatusNotification{Status: transaction.Dropped})
+	}
+
+	s.trackLongevity(vt.Extrinsic.Hash(), vt.Validity)
+}
+
+// providersOf returns the hashes of the transactions of the queue the transaction depends on,
+// which must not be evicted to make room for it.
+func (s *TransactionState) providersOf(vt *transaction.ValidTransaction) []common.Hash {
+	if vt.Validity == nil {
+		return nil
+	}
+	return s.queue.Providers(vt.Validity.Requires)
+}
+
+// isReady returns true if all the tags required by the transaction are provided by the queue, false otherwise
+func (s *TransactionState) isReady(vt *transaction.ValidTransaction) bool {
+	if vt.Validity == nil {
+		return true
+	}
+
+	for _, tag := range vt.Validity.Requires {
+		if !s.queue.IsProvided(tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// promote pushes the future transactions which require the given tags to the queue,
+// if all the tags they require are now provided. It must be called with the lock held.
+func (s *TransactionState) promote(tags [][]byte) {
+	for _, tag := range tags {
+		for _, tx := range s.pool.RequiredBy(tag) {
+			if !s.isReady(tx) {
+				continue
+			}
+
+			hash, err := s.push(tx)
+			if err != nil {
+				logger.Debugf("failed to promote transaction %s: %s", hash, err)
+			}
+		}
+	}
+}
+
+// trackLongevity records the number of the last block the transaction is valid at,
+// keeping the earliest one if the transaction was already pushed.
+// A zero longevity is considered to be unbounded.
+func (s *TransactionState) trackLongevity(hash common.Hash, validity *transaction.Validity) {
+	if validity == nil || validity.Longevity == 0 {
+		return
+	}
+
+	s.validLock.Lock()
+	defer s.validLock.Unlock()
+
+	validTill := uint64(math.MaxUint64)
+	if validity.Longevity < math.MaxUint64-s.bestNumber {
+		validTill = s.bestNumber + validity.Longevity
+	}
+
+	if existing, ok := s.validTill[hash]; ok && existing <= validTill {
+		return
+	}
+	s.validTill[hash] = validTill
+}
+
+// setBestNumber sets the block number the longevity of the transactions pushed afterwards is counted from.
+func (s *TransactionState) setBestNumber(number uint) {
+	s.validLock.Lock()
+	defer s.validLock.Unlock()
+	s.bestNumber = uint64(number)
+}
+
+// PruneStale drops the transactions of the queue and pool whose longevity expired at the given block number.
+// The longevity of the transactions pushed afterwards is counted from this block number.
+func (s *TransactionState) PruneStale(number uint) {
+	s.validLock.Lock()
+	s.bestNumber = uint64(number)
+
+	var stale []common.Hash
+	for hash, validTill := range s.validTill {
+		if validTill >= uint64(number) {
+			continue
+		}
+		stale = append(stale, hash)
+		delete(s.validTill, hash)
+	}
+	s.validLock.Unlock()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, hash := range stale {
+		tx := s.pool.Get(hash)
+		if tx == nil {
+			tx = s.queue.Get(hash)
+		}
+
+		if tx == nil {
+			continue
+		}
+
+		logger.Debugf("dropping stale transaction %s", hash)
+		s.dropExtrinsic(tx.Extrinsic)
+		s.NotifyStatus(tx.Extrinsic, transaction.StatusNotification{Status: transaction.Dropped})
+	}
+}
+
+// usurpedBy returns the transactions of the queue which provide any of the tags provided by
+// the given transaction. It returns ErrTooLowPriority if any of them has a higher or equal priority.
+func (s *TransactionState) usurpedBy(vt *transaction.ValidTransaction) ([]*transaction.ValidTransaction, error) {
+	if vt.Validity == nil || len(vt.Validity.Provides) == 0 {
+		return nil, nil
+	}
+
+	hash := vt.Extrinsic.Hash()
+	var usurped []*transaction.ValidTransaction
+	for _, tx := range s.queue.Pending() {
+		if tx.Validity == nil || tx.Extrinsic.Hash() == hash || !providesAny(tx.Validity, vt.Validity.Provides) {
+			continue
+		}
+
+		if tx.Validity.Priority >= vt.Validity.Priority {
+			return nil, ErrTooLowPriority
+		}
+
+		usurped = append(usurped, tx)
+	}
+
+	return usurped, nil
+}
+
+func providesAny(validity *transaction.Validity, tags [][]byte) bool {
+	for _, provided := range validity.Provides {
+		for _, tag := range tags {
+			if bytes.Equal(provided, tag) {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 // Pop removes and returns the head of the queue
 func (s *TransactionState) Pop() *transaction.ValidTransaction {
-	return s.queue.Pop()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	tx := s.queue.Pop()
+	if tx != nil {
+		s.limiter.release(tx.Extrinsic.Hash())
+	}
+	return tx
 }
 
 // Peek returns the head of the queue without removing it
@@ -68,49 +343,131 @@ func (s *TransactionState) Exists(ext types.Extrinsic) bool {
 	return s.pool.Get(hash) != nil || s.queue.Exists(hash)
 }
 
-// RemoveExtrinsic removes an extrinsic from the queue and pool
+// RemoveExtrinsic removes an extrinsic included in a block from the queue and pool.
+// The transactions of the queue requiring the tags it provides are unlocked.
 func (s *TransactionState) RemoveExtrinsic(ext types.Extrinsic) {
-	s.pool.Remove(ext.Hash())
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
 	s.queue.RemoveExtrinsic(ext)
+	s.forget(ext.Hash())
+}
+
+// dropExtrinsic removes an extrinsic which is not included in a block from the queue and pool.
+// It must be called with the lock held.
+func (s *TransactionState) dropExtrinsic(ext types.Extrinsic) {
+	s.dropFromQueue(ext)
+	s.forget(ext.Hash())
+}
+
+// dropFromQueue removes an extrinsic which is not included in a block from the queue. The transactions
+// of the queue requiring tags which are not provided anymore are moved back to the pool, until other
+// transactions provide them. It must be called with the lock held.
+func (s *TransactionState) dropFromQueue(ext types.Extrinsic) {
+	for _, tx := range s.queue.Drop(ext) {
+		s.pool.Insert(tx)
+		s.NotifyStatus(tx.Extrinsic, transaction.StatusNotification{Status: transaction.Future})
+	}
+}
+
+// forget removes the transaction with the given hash from the pool, the limits and the longevity tracking
+func (s *TransactionState) forget(hash common.Hash) {
+	s.pool.Remove(hash)
+	s.limiter.release(hash)
+
+	s.validLock.Lock()
+	delete(s.validTill, hash)
+	s.validLock.Unlock()
+}
+
+// RemoveAndBan removes the transactions with the given hashes from the queue and pool, and bans
+// them so they cannot be re-imported until their ban expires.
+// It returns the hashes of the transactions which were removed.
+func (s *TransactionState) RemoveAndBan(hashes []common.Hash) []common.Hash {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var removed []common.Hash
+	for _, hash := range hashes {
+		s.banned.Ban(hash)
+
+		tx := s.pool.Get(hash)
+		if tx == nil {
+			tx = s.queue.Get(hash)
+		}
+
+		if tx == nil {
+			continue
+		}
+
+		s.dropExtrinsic(tx.Extrinsic)
+		s.NotifyStatus(tx.Extrinsic, transaction.StatusNotification{Status: transaction.Invalid})
+		removed = append(removed, hash)
+	}
+
+	return removed
+}
+
+// IsBanned returns true if the transaction with the given hash is banned, false otherwise
+func (s *TransactionState) IsBanned(hash common.Hash) bool {
+	return s.banned.IsBanned(hash)
 }
 
 // RemoveExtrinsicFromPool removes an extrinsic from the pool
 func (s *TransactionState) RemoveExtrinsicFromPool(ext types.Extrinsic) {
-	s.pool.Remove(ext.Hash())
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	hash := ext.Hash()
+	s.pool.Remove(hash)
+	if !s.queue.Exists(hash) {
+		s.limiter.release(hash)
+	}
 }
 
-// AddToPool adds a transaction to the pool
-func (s *TransactionState) AddToPool(vt *transaction.ValidTransaction) common.Hash {
-	s.notifyStatus(vt.Extrinsic, transaction.Future)
+// AddToPool adds a transaction to the pool.
+// It returns transaction.ErrPoolFull or transaction.ErrSenderLimitReached if the transaction
+// cannot be added within the transaction limits.
+func (s *TransactionState) AddToPool(vt *transaction.ValidTransaction) (common.Hash, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	evicted, err := s.limiter.check(vt, nil, s.providersOf(vt))
+	if err != nil {
+		return vt.Extrinsic.Hash(), err
+	}
 
 	hash := s.pool.Insert(vt)
+	s.admit(vt, evicted)
+	s.NotifyStatus(vt.Extrinsic, transaction.StatusNotification{Status: transaction.Future})
 
 	s.telemetry.SendMessage(
 		telemetry.NewTxpoolImport(uint(s.queue.Len()), uint(s.pool.Len())),
 	)
 
-	return hash
+	return hash, nil
 }
 
 // GetStatusNotifierChannel creates and returns a status notifier channel.
-func (s *TransactionState) GetStatusNotifierChannel(ext types.Extrinsic) chan transaction.Status {
+func (s *TransactionState) GetStatusNotifierChannel(ext types.Extrinsic) chan transaction.StatusNotification {
 	s.notifierLock.Lock()
 	defer s.notifierLock.Unlock()
 
-	ch := make(chan transaction.Status, defaultBufferSize)
+	ch := make(chan transaction.StatusNotification, defaultBufferSize)
 	s.notifierChannels[ch] = ext.String()
 	return ch
 }
 
 // FreeStatusNotifierChannel deletes given status notifier channel from our map.
-func (s *TransactionState) FreeStatusNotifierChannel(ch chan transaction.Status) {
+func (s *TransactionState) FreeStatusNotifierChannel(ch chan transaction.StatusNotification) {
 	s.notifierLock.Lock()
 	defer s.notifierLock.Unlock()
 
 	delete(s.notifierChannels, ch)
 }
 
-func (s *TransactionState) notifyStatus(ext types.Extrinsic, status transaction.Status) {
+// NotifyStatus sends the given status update to the status notifier channels of the extrinsic.
+func (s *TransactionState) NotifyStatus(ext types.Extrinsic, notification transaction.StatusNotification) {
 	s.notifierLock.Lock()
 	defer s.notifierLock.Unlock()
 
@@ -124,11 +481,11 @@ func (s *TransactionState) notifyStatus(ext types.Extrinsic, status transaction.
 			continue
 		}
 		wg.Add(1)
-		go func(ch chan transaction.Status) {
+		go func(ch chan transaction.StatusNotification) {
 			defer wg.Done()
 
 			select {
-			case ch <- status:
+			case ch <- notification:
 			default:
 			}
 		}(ch)
@@ -0,0 +1,251 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	"github.com/ChainSafe/chaindb"
+)
+
+var (
+	indexedTransactionPrefix     = []byte("itx") // indexedTransactionPrefix + data hash -> indexed transaction
+	indexedTransactionRefsPrefix = []byte("itr") // indexedTransactionRefsPrefix + block hash -> referenced hashes
+)
+
+// indexedTransaction is the data indexed by the runtime, along with the number of blocks referencing it
+type indexedTransaction struct {
+	Data       []byte
+	References uint32
+}
+
+func indexedTransactionKey(hash common.Hash) []byte {
+	return append(indexedTransactionPrefix, hash.ToBytes()...)
+}
+
+func indexedTransactionRefsKey(hash common.Hash) []byte {
+	return append(indexedTransactionRefsPrefix, hash.ToBytes()...)
+}
+
+// StoreIndexedTransactions stores the data indexed and renewed by the transaction index operations
+// recorded by the runtime while executing the block. Each block indexing or renewing data holds a
+// reference to it, which is released when the block is pruned, or when it is finalised for more
+// than the transaction index retention period.
+func (bs *BlockState) StoreIndexedTransactions(block *types.Block, ops []rtstorage.IndexOperation) error {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	bs.indexedTransactionsLock.Lock()
+	defer bs.indexedTransactionsLock.Unlock()
+
+	blockHash := block.Header.Hash()
+	has, err := bs.db.Has(indexedTransactionRefsKey(blockHash))
+	if err != nil {
+		return err
+	}
+
+	if has {
+		// the references of the block are already stored
+		return nil
+	}
+
+	// the operations of the block can reference the same hash several times
+	updated := make(map[common.Hash]*indexedTransaction)
+	var refs []common.Hash
+	for _, op := range ops {
+		tx, ok := updated[op.Hash]
+		if !ok {
+			tx, err = bs.getIndexedTransaction(op.Hash)
+			if err != nil && !errors.Is(err, chaindb.ErrKeyNotFound) {
+				return err
+			}
+		}
+
+		switch {
+		case op.Renew && tx == nil:
+			logger.Debugf("cannot renew unknown indexed transaction %s in block %s", op.Hash, blockHash)
+			continue
+		case tx == nil:
+			data, err := indexedData(block, op)
+			if err != nil {
+				return err
+			}
+			tx = &indexedTransaction{Data: data}
+		}
+
+		tx.References++
+		updated[op.Hash] = tx
+		refs = append(refs, op.Hash)
+	}
+
+	batch := bs.db.NewBatch()
+	for hash, tx := range updated {
+		enc, err := scale.Marshal(*tx)
+		if err != nil {
+			return err
+		}
+
+		err = batch.Put(indexedTransactionKey(hash), enc)
+		if err != nil {
+			return err
+		}
+	}
+
+	enc, err := scale.Marshal(refs)
+	if err != nil {
+		return err
+	}
+
+	err = batch.Put(indexedTransactionRefsKey(blockHash), enc)
+	if err != nil {
+		return err
+	}
+
+	return batch.Flush()
+}
+
+// indexedData returns the data of the extrinsic indexed by the operation
+func indexedData(block *types.Block, op rtstorage.IndexOperation) ([]byte, error) {
+	if int(op.Extrinsic) >= len(block.Body) {
+		return nil, fmt.Errorf("cannot index extrinsic %d of block %s: extrinsic not found",
+			op.Extrinsic, block.Header.Hash())
+	}
+
+	ext := block.Body[op.Extrinsic]
+	if int(op.Size) > len(ext) {
+		return nil, fmt.Errorf("cannot index %d bytes of extrinsic %d of block %s with length %d",
+			op.Size, op.Extrinsic, block.Header.Hash(), len(ext))
+	}
+
+	data := make([]byte, op.Size)
+	copy(data, ext[len(ext)-int(op.Size):])
+	return data, nil
+}
+
+// GetIndexedTransaction returns the data indexed under the given hash
+func (bs *BlockState) GetIndexedTransaction(hash common.Hash) ([]byte, error) {
+	bs.indexedTransactionsLock.RLock()
+	defer bs.indexedTransactionsLock.RUnlock()
+
+	tx, err := bs.getIndexedTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.Data, nil
+}
+
+func (bs *BlockState) getIndexedTransaction(hash common.Hash) (*indexedTransaction, error) {
+	enc, err := bs.db.Get(indexedTransactionKey(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	tx := new(indexedTransaction)
+	err = scale.Unmarshal(enc, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// releaseIndexedTransactions releases the references held by the block to the indexed transactions,
+// and deletes the indexed transactions which are no longer referenced.
+func (bs *BlockState) releaseIndexedTransactions(blockHash common.Hash) error {
+	bs.indexedTransactionsLock.Lock()
+	defer bs.indexedTransactionsLock.Unlock()
+
+	enc, err := bs.db.Get(indexedTransactionRefsKey(blockHash))
+	if errors.Is(err, chaindb.ErrKeyNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var refs []common.Hash
+	err = scale.Unmarshal(enc, &refs)
+	if err != nil {
+		return err
+	}
+
+	// the same hash can be referenced several times, so the changes are not batched
+	for _, hash := range refs {
+		tx, err := bs.getIndexedTransaction(hash)
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		tx.References--
+		if tx.References == 0 {
+			logger.Tracef("deleting indexed transaction %s", hash)
+			err = bs.db.Del(indexedTransactionKey(hash))
+		} else {
+			enc, err = scale.Marshal(*tx)
+			if err != nil {
+				return err
+			}
+			err = bs.db.Put(indexedTransactionKey(hash), enc)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return bs.db.Del(indexedTransactionRefsKey(blockHash))
+}
+
+// pruneIndexedTransactions releases the indexed transaction references of the pruned blocks,
+// and of the finalised blocks which are now out of the retention period.
+func (bs *BlockState) pruneIndexedTransactions(pruned []common.Hash, previousFinalised, finalised uint) error {
+	for _, hash := range pruned {
+		err := bs.releaseIndexedTransactions(hash)
+		if err != nil {
+			return fmt.Errorf("cannot release indexed transactions of pruned block %s: %w", hash, err)
+		}
+	}
+
+	bs.indexedTransactionsLock.RLock()
+	retention := bs.transactionIndexRetention
+	bs.indexedTransactionsLock.RUnlock()
+	if retention == 0 || finalised < retention {
+		return nil
+	}
+
+	start := uint(0)
+	if previousFinalised >= retention {
+		start = previousFinalised - retention + 1
+	}
+
+	for number := start; number <= finalised-retention; number++ {
+		hash, err := bs.GetHashByNumber(number)
+		if err != nil {
+			return err
+		}
+
+		err = bs.releaseIndexedTransactions(hash)
+		if err != nil {
+			return fmt.Errorf("cannot release indexed transactions of block %s: %w", hash, err)
+		}
+	}
+
+	return nil
+}
+
+// SetTransactionIndexRetention sets the number of blocks the indexed transactions are kept for
+// after the finalisation of the last block indexing or renewing them. They are kept forever if it is zero.
+func (bs *BlockState) SetTransactionIndexRetention(retention uint) {
+	bs.indexedTransactionsLock.Lock()
+	defer bs.indexedTransactionsLock.Unlock()
+	bs.transactionIndexRetention = retention
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockState_StoreIndexedTransactions(t *testing.T) {
+	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+	bs.SetTransactionIndexRetention(1)
+	chain, _ := AddBlocksToState(t, bs, 3, false)
+
+	data := []byte{2, 3}
+	hash := common.MustBlake2bHash(data)
+	unknown := common.Hash{1}
+
+	block1 := &types.Block{Header: *chain[0], Body: types.Body{{0}, {1, 2, 3}}}
+	err := bs.StoreIndexedTransactions(block1, []rtstorage.IndexOperation{
+		{Extrinsic: 1, Hash: hash, Size: 2},
+	})
+	require.NoError(t, err)
+
+	block2 := &types.Block{Header: *chain[1]}
+	err = bs.StoreIndexedTransactions(block2, []rtstorage.IndexOperation{
+		{Extrinsic: 0, Hash: hash, Renew: true},
+		{Extrinsic: 0, Hash: unknown, Renew: true},
+	})
+	require.NoError(t, err)
+
+	// storing the operations of a block again is a no-op
+	err = bs.StoreIndexedTransactions(block2, []rtstorage.IndexOperation{
+		{Extrinsic: 0, Hash: hash, Renew: true},
+	})
+	require.NoError(t, err)
+
+	res, err := bs.GetIndexedTransaction(hash)
+	require.NoError(t, err)
+	require.Equal(t, data, res)
+
+	_, err = bs.GetIndexedTransaction(unknown)
+	require.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+
+	// the reference of block 1 is released
+	err = bs.SetFinalisedHash(chain[1].Hash(), 1, 1)
+	require.NoError(t, err)
+	res, err = bs.GetIndexedTransaction(hash)
+	require.NoError(t, err)
+	require.Equal(t, data, res)
+
+	// the reference of block 2 is released
+	err = bs.SetFinalisedHash(chain[2].Hash(), 1, 1)
+	require.NoError(t, err)
+	_, err = bs.GetIndexedTransaction(hash)
+	require.ErrorIs(t, err, chaindb.ErrKeyNotFound)
+}
+
+func TestBlockState_StoreIndexedTransactions_InvalidExtrinsic(t *testing.T) {
+	bs := newTestBlockState(t, testGenesisHeader, newTriesEmpty())
+	chain, _ := AddBlocksToState(t, bs, 1, false)
+	block := &types.Block{Header: *chain[0], Body: types.Body{{1, 2}}}
+
+	err := bs.StoreIndexedTransactions(block, []rtstorage.IndexOperation{
+		{Extrinsic: 1, Hash: common.Hash{1}, Size: 1},
+	})
+	require.Error(t, err)
+
+	err = bs.StoreIndexedTransactions(block, []rtstorage.IndexOperation{
+		{Extrinsic: 0, Hash: common.Hash{1}, Size: 3},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,353 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"bytes"
+	"container/heap"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/transaction"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	transactionsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gossamer_state_transaction",
+		Name:      "total",
+		Help:      "total number of transactions in the pool and queue",
+	})
+	transactionsBytesGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "gossamer_state_transaction",
+		Name:      "bytes_total",
+		Help:      "total size in bytes of the transactions in the pool and queue",
+	})
+	evictedTransactionsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "gossamer_state_transaction",
+		Name:      "evicted_total",
+		Help:      "total number of transactions evicted from the pool and queue",
+	})
+)
+
+// limitedTransaction is a transaction counted against the transaction limits
+type limitedTransaction struct {
+	hash     common.Hash
+	size     int
+	priority uint64
+	// sender identifies the signer of the transaction, it is empty if the transaction is not signed.
+	sender string
+	// index is the index of the transaction in the priority heap
+	index int
+}
+
+// priorityHeap is a min-heap of the limited transactions ordered by priority
+type priorityHeap []*limitedTransaction
+
+func (h priorityHeap) Len() int           { return len(h) }
+func (h priorityHeap) Less(i, j int) bool { return h[i].priority < h[j].priority }
+
+func (h priorityHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *priorityHeap) Push(x interface{}) {
+	tx := x.(*limitedTransaction)
+	tx.index = len(*h)
+	*h = append(*h, tx)
+}
+
+func (h *priorityHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	tx := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return tx
+}
+
+// transactionLimiter keeps track of the transactions in the pool and queue,
+// to enforce the transaction limits.
+// It is not safe for concurrent use, the TransactionState lock must be held to call its methods.
+type transactionLimiter struct {
+	limits     transaction.Limits
+	txs        map[common.Hash]*limitedTransaction
+	byPriority priorityHeap
+	bytes      int
+	senders    map[string]int
+
+	countGauge     prometheus.Gauge
+	bytesGauge     prometheus.Gauge
+	evictedCounter prometheus.Counter
+}
+
+func newTransactionLimiter(limits transaction.Limits) *transactionLimiter {
+	return &transactionLimiter{
+		limits:         limits.WithDefaults(),
+		txs:            make(map[common.Hash]*limitedTransaction),
+		senders:        make(map[string]int),
+		countGauge:     transactionsGauge,
+		bytesGauge:     transactionsBytesGauge,
+		evictedCounter: evictedTransactionsCounter,
+	}
+}
+
+// check returns the hashes of the transactions with a lower priority to evict to make room for
+// the transaction, without counting it against the limits.
+// The replaced transactions are released along with the transaction, so they are not counted against
+// the limits nor evicted. The protected transactions, which provide the tags the transaction requires,
+// are not evicted either.
+// It returns transaction.ErrSenderLimitReached if its sender has too many transactions, and
+// transaction.ErrPoolFull if there are not enough transactions with a lower priority to evict.
+func (l *transactionLimiter) check(vt *transaction.ValidTransaction, replaced, protected []common.Hash) (
+	evicted []common.Hash, err error) {
+	if _, ok := l.txs[vt.Extrinsic.Hash()]; ok {
+		return nil, nil
+	}
+
+	senderKey := sender(vt)
+	count, bytes, senderCount := len(l.txs)+1, l.bytes+len(vt.Extrinsic), l.senders[senderKey]
+
+	kept := make(map[common.Hash]struct{}, len(replaced)+len(protected))
+	for _, hash := range replaced {
+		tx, ok := l.txs[hash]
+		if !ok {
+			continue
+		}
+
+		kept[hash] = struct{}{}
+		count--
+		bytes -= tx.size
+		if tx.sender == senderKey {
+			senderCount--
+		}
+	}
+	for _, hash := range protected {
+		kept[hash] = struct{}{}
+	}
+
+	if senderKey != "" && senderCount >= l.limits.MaxPerSender {
+		return nil, transaction.ErrSenderLimitReached
+	}
+
+	priority := validityPriority(vt.Validity)
+
+	// the lowest priority transactions are popped to find the ones to evict, and pushed back afterwards
+	var popped []*limitedTransaction
+	defer func() {
+		for _, tx := range popped {
+			heap.Push(&l.byPriority, tx)
+		}
+	}()
+
+	for count > l.limits.MaxCount || bytes > l.limits.MaxBytes {
+		if len(l.byPriority) == 0 || l.byPriority[0].priority >= priority {
+			return nil, transaction.ErrPoolFull
+		}
+
+		lowest := heap.Pop(&l.byPriority).(*limitedTransaction)
+		popped = append(popped, lowest)
+		if _, ok := kept[lowest.hash]; ok {
+			continue
+		}
+
+		evicted = append(evicted, lowest.hash)
+		count--
+		bytes -= lowest.size
+	}
+
+	return evicted, nil
+}
+
+// add stops counting the evicted transactions against the limits, and counts the transaction instead.
+// The priority of the transaction is updated if it is already counted.
+func (l *transactionLimiter) add(vt *transaction.ValidTransaction, evicted []common.Hash) {
+	for _, hash := range evicted {
+		l.remove(hash)
+	}
+	l.evictedCounter.Add(float64(len(evicted)))
+
+	hash := vt.Extrinsic.Hash()
+	priority := validityPriority(vt.Validity)
+	if tx, ok := l.txs[hash]; ok {
+		tx.priority = priority
+		heap.Fix(&l.byPriority, tx.index)
+		return
+	}
+
+	tx := &limitedTransaction{
+		hash:     hash,
+		size:     len(vt.Extrinsic),
+		priority: priority,
+		sender:   sender(vt),
+	}
+
+	l.txs[hash] = tx
+	heap.Push(&l.byPriority, tx)
+	l.bytes += tx.size
+	if tx.sender != "" {
+		l.senders[tx.sender]++
+	}
+	l.updateMetrics()
+}
+
+// release stops counting the transaction with the given hash against the limits
+func (l *transactionLimiter) release(hash common.Hash) {
+	l.remove(hash)
+	l.updateMetrics()
+}
+
+func (l *transactionLimiter) remove(hash common.Hash) {
+	tx, ok := l.txs[hash]
+	if !ok {
+		return
+	}
+
+	delete(l.txs, hash)
+	heap.Remove(&l.byPriority, tx.index)
+	l.bytes -= tx.size
+	if tx.sender == "" {
+		return
+	}
+
+	l.senders[tx.sender]--
+	if l.senders[tx.sender] == 0 {
+		delete(l.senders, tx.sender)
+	}
+}
+
+func (l *transactionLimiter) updateMetrics() {
+	l.countGauge.Set(float64(len(l.txs)))
+	l.bytesGauge.Set(float64(l.bytes))
+}
+
+func validityPriority(validity *transaction.Validity) uint64 {
+	if validity == nil {
+		return 0
+	}
+	return validity.Priority
+}
+
+const (
+	// accountSize is the size of an account
+	accountSize = 32
+	// nonceSize is the size of the SCALE encoded nonce of an account
+	nonceSize = 4
+	// signedExtrinsicBit is set in the version of the signed extrinsics
+	signedExtrinsicBit = 0x80
+)
+
+// sender returns the account which signed the transaction, or an empty string if it is not signed.
+// The address of the signer follows the version of signed extrinsics, in the format of the runtime.
+// The runtime checking the nonce of signed transactions makes them provide the SCALE encoded account and
+// nonce of their signer, so the sender is this account if the address is the raw account, as in the legacy
+// extrinsic formats, or its MultiAddress. Otherwise the address is decoded as a MultiAddress, and the sender
+// is the account of the first tag the transaction provides if it isn't one.
+func sender(vt *transaction.ValidTransaction) string {
+	address, signed := signer(vt.Extrinsic)
+	if !signed {
+		return ""
+	}
+
+	validity := vt.Validity
+	if account := taggedAccount(validity, address); account != nil {
+		return string(account)
+	}
+
+	if account := multiAddress(address); account != nil {
+		return string(account)
+	}
+
+	if validity == nil || len(validity.Provides) == 0 || len(validity.Provides[0]) <= nonceSize {
+		return ""
+	}
+
+	tag := validity.Provides[0]
+	return string(tag[:len(tag)-nonceSize])
+}
+
+// signer returns whether the extrinsic is signed, along with the bytes following its version,
+// which start with the address of its signer.
+func signer(ext types.Extrinsic) (address []byte, signed bool) {
+	r := bytes.NewReader(ext)
+	var length uint
+	err := scale.NewDecoder(r).Decode(&length)
+	if err != nil {
+		return nil, false
+	}
+
+	version, err := r.ReadByte()
+	if err != nil || version&signedExtrinsicBit == 0 {
+		return nil, false
+	}
+
+	return ext[len(ext)-r.Len():], true
+}
+
+// taggedAccount returns the account of the nonce tags provided by the transaction which the address
+// starts with, either raw or as the Id or Address32 variant of a MultiAddress, or nil if there is none.
+func taggedAccount(validity *transaction.Validity, address []byte) []byte {
+	if validity == nil {
+		return nil
+	}
+
+	for _, tag := range validity.Provides {
+		if len(tag) != accountSize+nonceSize {
+			continue
+		}
+
+		account := tag[:accountSize]
+		if bytes.HasPrefix(address, account) {
+			return account
+		}
+
+		if len(address) > 0 && (address[0] == 0 || address[0] == 3) && bytes.HasPrefix(address[1:], account) {
+			return account
+		}
+	}
+
+	return nil
+}
+
+// multiAddress decodes the MultiAddress the address starts with. It returns the account of the Id and
+// Address32 variants, the SCALE encoded MultiAddress of the others, or nil if it is not a MultiAddress.
+func multiAddress(address []byte) []byte {
+	r := bytes.NewReader(address)
+	decoder := scale.NewDecoder(r)
+
+	variant, err := r.ReadByte()
+	if err != nil {
+		return nil
+	}
+
+	// the Id, Address32 and Address20 variants have a fixed size
+	size := 0
+	switch variant {
+	case 0, 3:
+		if len(address) < 1+accountSize {
+			return nil
+		}
+		return address[1 : 1+accountSize]
+	case 1:
+		var index uint
+		err = decoder.Decode(&index)
+	case 2:
+		var raw []byte
+		err = decoder.Decode(&raw)
+	case 4:
+		size = 20
+	default:
+		return nil
+	}
+
+	if err != nil || r.Len() < size {
+		return nil
+	}
+
+	return address[:len(address)-r.Len()+size]
+}
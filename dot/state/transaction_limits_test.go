@@ -0,0 +1,359 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package state
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/transaction"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// nonceTag returns the tag provided by a transaction of the given signer with the given nonce
+func nonceTag(signer byte, nonce uint32) []byte {
+	tag := make([]byte, 32+nonceSize)
+	tag[0] = signer
+	binary.LittleEndian.PutUint32(tag[32:], nonce)
+	return tag
+}
+
+// newExtrinsic returns the extrinsic with the given version and body, prefixed with its length
+func newExtrinsic(t *testing.T, version byte, body ...byte) types.Extrinsic {
+	ext := append([]byte{version}, body...)
+	length, err := scale.Marshal(uint(len(ext)))
+	require.NoError(t, err)
+	return append(length, ext...)
+}
+
+// newSignedTransaction returns a transaction signed by the MultiAddress::Id of the signer
+func newSignedTransaction(t *testing.T, signer byte, nonce uint32) *transaction.ValidTransaction {
+	address := make([]byte, 33)
+	address[1] = signer
+	ext := newExtrinsic(t, 0x84, append(address, nonceTag(signer, nonce)...)...)
+	return transaction.NewValidTransaction(ext, &transaction.Validity{
+		Provides: [][]byte{nonceTag(signer, nonce)},
+	})
+}
+
+func Test_sender(t *testing.T) {
+	require.NotEmpty(t, sender(newSignedTransaction(t, 1, 0)))
+	require.Equal(t, sender(newSignedTransaction(t, 1, 0)), sender(newSignedTransaction(t, 1, 1)))
+	require.NotEqual(t, sender(newSignedTransaction(t, 1, 0)), sender(newSignedTransaction(t, 2, 0)))
+
+	// the signer is read from the extrinsic, not from the provided tags
+	vt := newSignedTransaction(t, 1, 0)
+	vt.Validity = nil
+	require.Equal(t, sender(newSignedTransaction(t, 1, 0)), sender(vt))
+
+	// unsigned transactions have no sender, even if they provide tags
+	unsigned := transaction.NewValidTransaction(newExtrinsic(t, 0x04, 1, 2, 3), &transaction.Validity{
+		Provides: [][]byte{nonceTag(1, 0)},
+	})
+	require.Empty(t, sender(unsigned))
+	require.Empty(t, sender(transaction.NewValidTransaction(nil, &transaction.Validity{
+		Provides: [][]byte{nonceTag(1, 0)},
+	})))
+
+	// the raw accounts of the legacy extrinsic formats are not decoded as a MultiAddress
+	legacy := func(account []byte, nonce uint32) *transaction.ValidTransaction {
+		tag := make([]byte, 32+nonceSize)
+		copy(tag, account)
+		binary.LittleEndian.PutUint32(tag[32:], nonce)
+		ext := newExtrinsic(t, 0x84, append(append([]byte{}, account...), 0xaa, 0xbb)...)
+		return transaction.NewValidTransaction(ext, &transaction.Validity{Provides: [][]byte{tag}})
+	}
+	first, second := make([]byte, 32), make([]byte, 32)
+	first[0], first[1], first[31] = 1, 0x04, 1
+	second[0], second[1], second[31] = 1, 0x04, 2
+	require.Equal(t, string(first), sender(legacy(first, 0)))
+	require.Equal(t, sender(legacy(first, 0)), sender(legacy(first, 1)))
+	require.NotEqual(t, sender(legacy(first, 0)), sender(legacy(second, 0)))
+
+	// the sender of transactions whose signer isn't a MultiAddress is the account of their first tag
+	other := transaction.NewValidTransaction(newExtrinsic(t, 0x84, 0xff, 1, 2, 3), &transaction.Validity{
+		Provides: [][]byte{nonceTag(1, 0)},
+	})
+	require.Equal(t, string(nonceTag(1, 0)[:32]), sender(other))
+	other.Validity = nil
+	require.Empty(t, sender(other))
+}
+
+func Test_signer(t *testing.T) {
+	address, signed := signer(newExtrinsic(t, 0x84, 0, 1, 2))
+	require.True(t, signed)
+	require.Equal(t, []byte{0, 1, 2}, address)
+
+	_, signed = signer(newExtrinsic(t, 0x04, 0, 1, 2))
+	require.False(t, signed)
+	_, signed = signer(nil)
+	require.False(t, signed)
+}
+
+func Test_multiAddress(t *testing.T) {
+	tests := map[string]struct {
+		address []byte
+		account []byte
+	}{
+		"empty": {},
+		"id": {
+			address: append(append([]byte{0}, nonceTag(1, 0)[:32]...), 9),
+			account: nonceTag(1, 0)[:32],
+		},
+		"index": {
+			address: []byte{1, 0x04, 9},
+			account: []byte{1, 0x04},
+		},
+		"raw": {
+			address: []byte{2, 0x08, 1, 2, 9},
+			account: []byte{2, 0x08, 1, 2},
+		},
+		"address32": {
+			address: append([]byte{3}, make([]byte, 33)...),
+			account: make([]byte, 32),
+		},
+		"address20": {
+			address: append([]byte{4}, make([]byte, 21)...),
+			account: append([]byte{4}, make([]byte, 20)...),
+		},
+		"truncated id": {
+			address: []byte{0, 1, 2},
+		},
+		"truncated address20": {
+			address: []byte{4, 1, 2},
+		},
+		"unknown": {
+			address: []byte{5, 1, 2},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.account, multiAddress(test.address))
+		})
+	}
+}
+
+func Test_transactionLimiter_check(t *testing.T) {
+	l := newTransactionLimiter(transaction.Limits{MaxCount: 3})
+	for i, priority := range []uint64{5, 1, 3} {
+		vt := &transaction.ValidTransaction{Extrinsic: []byte{byte(i)}, Validity: &transaction.Validity{Priority: priority}}
+		evicted, err := l.check(vt, nil, nil)
+		require.NoError(t, err)
+		require.Empty(t, evicted)
+		l.add(vt, evicted)
+	}
+
+	vt := &transaction.ValidTransaction{Extrinsic: []byte{3}, Validity: &transaction.Validity{Priority: 4}}
+	evicted, err := l.check(vt, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{types.Extrinsic{1}.Hash()}, evicted)
+
+	// checking a transaction does not change the counted transactions
+	evicted, err = l.check(vt, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{types.Extrinsic{1}.Hash()}, evicted)
+	require.Len(t, l.txs, 3)
+	require.Len(t, l.byPriority, 3)
+
+	l.add(vt, evicted)
+	require.Len(t, l.txs, 3)
+	require.Equal(t, uint64(3), l.byPriority[0].priority)
+
+	_, err = l.check(&transaction.ValidTransaction{Extrinsic: []byte{4}, Validity: &transaction.Validity{Priority: 3}}, nil, nil)
+	require.ErrorIs(t, err, transaction.ErrPoolFull)
+
+	// the replaced transactions make room for the transaction, and are not evicted
+	replaced := []common.Hash{types.Extrinsic{2}.Hash()}
+	evicted, err = l.check(&transaction.ValidTransaction{Extrinsic: []byte{4}, Validity: &transaction.Validity{Priority: 6}},
+		replaced, nil)
+	require.NoError(t, err)
+	require.Empty(t, evicted)
+
+	// the protected transactions are not evicted
+	protected := []common.Hash{types.Extrinsic{2}.Hash()}
+	evicted, err = l.check(&transaction.ValidTransaction{Extrinsic: []byte{4}, Validity: &transaction.Validity{Priority: 6}},
+		nil, protected)
+	require.NoError(t, err)
+	require.Equal(t, []common.Hash{types.Extrinsic{3}.Hash()}, evicted)
+
+	_, err = l.check(&transaction.ValidTransaction{Extrinsic: []byte{4}, Validity: &transaction.Validity{Priority: 4}},
+		nil, protected)
+	require.ErrorIs(t, err, transaction.ErrPoolFull)
+	require.Len(t, l.byPriority, 3)
+}
+
+func TestTransactionState_Limits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockClient(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	t.Run("max count", func(t *testing.T) {
+		ts := newTransactionState(telemetryMock, 0, transaction.Limits{MaxCount: 2})
+
+		low := &transaction.ValidTransaction{Extrinsic: []byte("a"), Validity: &transaction.Validity{Priority: 1}}
+		high := &transaction.ValidTransaction{Extrinsic: []byte("b"), Validity: &transaction.Validity{Priority: 3}}
+		_, err := ts.AddToPool(low)
+		require.NoError(t, err)
+		_, err = ts.Push(high)
+		require.NoError(t, err)
+
+		ch := ts.GetStatusNotifierChannel(low.Extrinsic)
+		defer ts.FreeStatusNotifierChannel(ch)
+
+		// the lowest priority transaction is evicted
+		medium := &transaction.ValidTransaction{Extrinsic: []byte("c"), Validity: &transaction.Validity{Priority: 2}}
+		_, err = ts.AddToPool(medium)
+		require.NoError(t, err)
+		require.Equal(t, transaction.StatusNotification{Status: transaction.Dropped}, <-ch)
+		require.False(t, ts.Exists(low.Extrinsic))
+
+		_, err = ts.AddToPool(low)
+		require.ErrorIs(t, err, transaction.ErrPoolFull)
+
+		// popping a transaction makes room for another one
+		require.Equal(t, high, ts.Pop())
+		_, err = ts.AddToPool(low)
+		require.NoError(t, err)
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		ts := newTransactionState(telemetryMock, 0, transaction.Limits{MaxBytes: 4})
+
+		first := &transaction.ValidTransaction{Extrinsic: []byte("aaa"), Validity: &transaction.Validity{Priority: 2}}
+		_, err := ts.AddToPool(first)
+		require.NoError(t, err)
+
+		second := &transaction.ValidTransaction{Extrinsic: []byte("bb"), Validity: &transaction.Validity{Priority: 1}}
+		_, err = ts.AddToPool(second)
+		require.ErrorIs(t, err, transaction.ErrPoolFull)
+
+		second.Validity.Priority = 3
+		_, err = ts.AddToPool(second)
+		require.NoError(t, err)
+		require.Equal(t, []*transaction.ValidTransaction{second}, ts.PendingInPool())
+	})
+
+	t.Run("max per sender", func(t *testing.T) {
+		ts := newTransactionState(telemetryMock, 0, transaction.Limits{MaxPerSender: 2})
+
+		for nonce := uint32(0); nonce < 2; nonce++ {
+			_, err := ts.AddToPool(newSignedTransaction(t, 1, nonce))
+			require.NoError(t, err)
+		}
+
+		_, err := ts.AddToPool(newSignedTransaction(t, 1, 2))
+		require.ErrorIs(t, err, transaction.ErrSenderLimitReached)
+
+		_, err = ts.AddToPool(newSignedTransaction(t, 2, 0))
+		require.NoError(t, err)
+
+		ts.RemoveExtrinsic(newSignedTransaction(t, 1, 0).Extrinsic)
+		_, err = ts.AddToPool(newSignedTransaction(t, 1, 2))
+		require.NoError(t, err)
+	})
+
+	t.Run("usurped transaction", func(t *testing.T) {
+		ts := newTransactionState(telemetryMock, 0, transaction.Limits{MaxCount: 2, MaxPerSender: 1})
+
+		other := &transaction.ValidTransaction{Extrinsic: []byte("a"), Validity: &transaction.Validity{Priority: 1}}
+		_, err := ts.Push(other)
+		require.NoError(t, err)
+
+		usurped := newSignedTransaction(t, 1, 0)
+		usurped.Validity.Priority = 1
+		_, err = ts.Push(usurped)
+		require.NoError(t, err)
+
+		// the transaction replacing another one of the same sender makes room for itself
+		address := make([]byte, 33)
+		address[1] = 1
+		usurper := transaction.NewValidTransaction(
+			newExtrinsic(t, 0x84, append(append(address, nonceTag(1, 0)...), 0xff)...),
+			&transaction.Validity{Priority: 2, Provides: [][]byte{nonceTag(1, 0)}},
+		)
+		_, err = ts.Push(usurper)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []*transaction.ValidTransaction{other, usurper}, ts.Pending())
+		require.Len(t, ts.limiter.txs, 2)
+	})
+
+	t.Run("required transaction", func(t *testing.T) {
+		ts := newTransactionState(telemetryMock, 0, transaction.Limits{MaxCount: 2})
+
+		provider := &transaction.ValidTransaction{
+			Extrinsic: []byte("a"),
+			Validity:  &transaction.Validity{Priority: 1, Provides: [][]byte{{1}}},
+		}
+		high := &transaction.ValidTransaction{Extrinsic: []byte("b"), Validity: &transaction.Validity{Priority: 5}}
+		for _, tx := range []*transaction.ValidTransaction{provider, high} {
+			_, err := ts.Push(tx)
+			require.NoError(t, err)
+		}
+
+		// the provider of the tag the transaction requires is not evicted to make room for it
+		dependent := &transaction.ValidTransaction{
+			Extrinsic: []byte("c"),
+			Validity:  &transaction.Validity{Priority: 3, Requires: [][]byte{{1}}},
+		}
+		_, err := ts.Push(dependent)
+		require.ErrorIs(t, err, transaction.ErrPoolFull)
+		require.ElementsMatch(t, []*transaction.ValidTransaction{provider, high}, ts.Pending())
+	})
+
+	t.Run("rejected transaction", func(t *testing.T) {
+		ts := newTransactionState(telemetryMock, 0, transaction.Limits{MaxCount: 2})
+
+		high := &transaction.ValidTransaction{
+			Extrinsic: []byte("a"),
+			Validity:  &transaction.Validity{Priority: 2, Provides: [][]byte{{1}}},
+		}
+		_, err := ts.Push(high)
+		require.NoError(t, err)
+
+		// a transaction rejected by the queue is not counted against the limits
+		low := &transaction.ValidTransaction{
+			Extrinsic: []byte("b"),
+			Validity:  &transaction.Validity{Priority: 1, Provides: [][]byte{{1}}},
+		}
+		_, err = ts.Push(low)
+		require.ErrorIs(t, err, ErrTooLowPriority)
+		_, err = ts.Push(high)
+		require.ErrorIs(t, err, transaction.ErrTransactionExists)
+		require.Len(t, ts.limiter.txs, 1)
+
+		_, err = ts.AddToPool(&transaction.ValidTransaction{Extrinsic: []byte("c"), Validity: &transaction.Validity{}})
+		require.NoError(t, err)
+	})
+
+	t.Run("concurrent pushes", func(t *testing.T) {
+		const maxCount = 8
+		ts := newTransactionState(telemetryMock, 0, transaction.Limits{MaxCount: maxCount})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 4*maxCount; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, _ = ts.Push(&transaction.ValidTransaction{
+					Extrinsic: []byte{byte(i)},
+					Validity:  &transaction.Validity{Priority: uint64(i)},
+				})
+			}(i)
+		}
+		wg.Wait()
+
+		require.Len(t, ts.Pending(), maxCount)
+		require.Len(t, ts.limiter.txs, maxCount)
+		for _, tx := range ts.Pending() {
+			require.Contains(t, ts.limiter.txs, tx.Extrinsic.Hash())
+		}
+	})
+}
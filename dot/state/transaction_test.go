@@ -49,7 +49,8 @@ func TestTransactionState_Pending(t *testing.T) {
 
 	hashes := make([]common.Hash, len(txs))
 	for i, tx := range txs {
-		h := ts.AddToPool(tx)
+		h, err := ts.AddToPool(tx)
+		require.NoError(t, err)
 		hashes[i] = h
 	}
 
@@ -99,25 +100,28 @@ func TestTransactionState_NotifierChannels(t *testing.T) {
 	for i := 0; i < expectedFutureCount; i++ {
 		dummyTransactions[i] = &transaction.ValidTransaction{
 			Extrinsic: ext,
-			Validity:  transaction.NewValidity(0, [][]byte{{}}, [][]byte{{}}, 0, false),
+			Validity:  transaction.NewValidity(0, nil, [][]byte{{}}, 0, false),
 		}
 
-		ts.AddToPool(dummyTransactions[i])
+		_, err := ts.AddToPool(dummyTransactions[i])
+		require.NoError(t, err)
 	}
 
 	for i := 0; i < expectedReadyCount; i++ {
-		ts.Push(dummyTransactions[i])
+		_, err := ts.Push(dummyTransactions[i])
+		require.NoError(t, err)
+		ts.Pop()
 	}
 
 	// it takes time for the status updates to happen
 	time.Sleep(1 * time.Second)
 	close(notifierChannel)
 
-	for status := range notifierChannel {
-		if status.String() == transaction.Future.String() {
+	for notification := range notifierChannel {
+		if notification.Status == transaction.Future {
 			futureCount++
 		}
-		if status.String() == transaction.Ready.String() {
+		if notification.Status == transaction.Ready {
 			readyCount++
 		}
 	}
@@ -125,3 +129,227 @@ func TestTransactionState_NotifierChannels(t *testing.T) {
 	require.Equal(t, expectedFutureCount, futureCount)
 	require.Equal(t, expectedReadyCount, readyCount)
 }
+
+func TestTransactionState_Push_Usurped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockClient(ctrl)
+
+	ts := NewTransactionState(telemetryMock)
+
+	tag := []byte("sender-nonce")
+	low := transaction.NewValidTransaction(types.Extrinsic("low"), &transaction.Validity{
+		Priority: 1,
+		Provides: [][]byte{tag},
+	})
+	high := transaction.NewValidTransaction(types.Extrinsic("high"), &transaction.Validity{
+		Priority: 2,
+		Provides: [][]byte{tag},
+	})
+
+	notifierChannel := ts.GetStatusNotifierChannel(low.Extrinsic)
+	defer ts.FreeStatusNotifierChannel(notifierChannel)
+
+	_, err := ts.Push(low)
+	require.NoError(t, err)
+
+	highHash, err := ts.Push(high)
+	require.NoError(t, err)
+	require.Equal(t, []*transaction.ValidTransaction{high}, ts.Pending())
+
+	// the usurped transaction cannot replace the transaction with a higher priority
+	_, err = ts.Push(low)
+	require.ErrorIs(t, err, ErrTooLowPriority)
+	require.Equal(t, []*transaction.ValidTransaction{high}, ts.Pending())
+
+	require.Equal(t, transaction.StatusNotification{Status: transaction.Ready}, <-notifierChannel)
+	require.Equal(t, transaction.StatusNotification{
+		Status: transaction.Usurped,
+		Hash:   &highHash,
+	}, <-notifierChannel)
+}
+
+func TestTransactionState_RemoveAndBan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockClient(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+
+	inPool := &transaction.ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+	inQueue := &transaction.ValidTransaction{
+		Extrinsic: []byte("b"),
+		Validity:  &transaction.Validity{Priority: 1},
+	}
+	poolHash, err := ts.AddToPool(inPool)
+	require.NoError(t, err)
+	queueHash, err := ts.Push(inQueue)
+	require.NoError(t, err)
+
+	ch := ts.GetStatusNotifierChannel(inQueue.Extrinsic)
+	defer ts.FreeStatusNotifierChannel(ch)
+
+	unknownHash := common.Hash{9}
+	removed := ts.RemoveAndBan([]common.Hash{poolHash, queueHash, unknownHash})
+	require.Equal(t, []common.Hash{poolHash, queueHash}, removed)
+
+	require.Empty(t, ts.PendingInPool())
+	require.Empty(t, ts.Pending())
+	require.Equal(t, transaction.StatusNotification{Status: transaction.Invalid}, <-ch)
+
+	require.True(t, ts.IsBanned(poolHash))
+	require.True(t, ts.IsBanned(queueHash))
+	require.True(t, ts.IsBanned(unknownHash))
+	require.False(t, ts.IsBanned(common.Hash{1}))
+}
+
+func TestTransactionState_RemoveAndBan_Dependents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockClient(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+
+	provider := &transaction.ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &transaction.Validity{Priority: 1, Provides: [][]byte{[]byte("tag")}},
+	}
+	dependent := &transaction.ValidTransaction{
+		Extrinsic: []byte("b"),
+		Validity:  &transaction.Validity{Priority: 2, Requires: [][]byte{[]byte("tag")}},
+	}
+	providerHash, err := ts.Push(provider)
+	require.NoError(t, err)
+	_, err = ts.Push(dependent)
+	require.NoError(t, err)
+
+	ch := ts.GetStatusNotifierChannel(dependent.Extrinsic)
+	defer ts.FreeStatusNotifierChannel(ch)
+
+	// the tag required by the dependent transaction is not provided anymore
+	removed := ts.RemoveAndBan([]common.Hash{providerHash})
+	require.Equal(t, []common.Hash{providerHash}, removed)
+
+	require.Nil(t, ts.Pop())
+	require.Equal(t, []*transaction.ValidTransaction{dependent}, ts.PendingInPool())
+	require.Equal(t, transaction.StatusNotification{Status: transaction.Future}, <-ch)
+
+	// the dependent transaction is ready again once another transaction provides the tag
+	other := &transaction.ValidTransaction{
+		Extrinsic: []byte("c"),
+		Validity:  &transaction.Validity{Priority: 1, Provides: [][]byte{[]byte("tag")}},
+	}
+	_, err = ts.Push(other)
+	require.NoError(t, err)
+	require.Equal(t, other, ts.Pop())
+	require.Equal(t, dependent, ts.Pop())
+}
+
+func TestTransactionState_Push_Future(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockClient(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+
+	first := &transaction.ValidTransaction{
+		Extrinsic: []byte("nonce 0"),
+		Validity:  &transaction.Validity{Priority: 1, Provides: [][]byte{[]byte("nonce 0")}},
+	}
+	second := &transaction.ValidTransaction{
+		Extrinsic: []byte("nonce 1"),
+		Validity: &transaction.Validity{
+			Priority: 2,
+			Requires: [][]byte{[]byte("nonce 0")},
+			Provides: [][]byte{[]byte("nonce 1")},
+		},
+	}
+	third := &transaction.ValidTransaction{
+		Extrinsic: []byte("nonce 2"),
+		Validity:  &transaction.Validity{Priority: 3, Requires: [][]byte{[]byte("nonce 1")}},
+	}
+
+	ch := ts.GetStatusNotifierChannel(third.Extrinsic)
+	defer ts.FreeStatusNotifierChannel(ch)
+
+	// the transactions wait in the pool for the tags they require
+	for _, tx := range []*transaction.ValidTransaction{third, second} {
+		_, err := ts.Push(tx)
+		require.NoError(t, err)
+	}
+	require.Len(t, ts.PendingInPool(), 2)
+	require.Nil(t, ts.Peek())
+	require.Equal(t, transaction.StatusNotification{Status: transaction.Future}, <-ch)
+
+	// pushing the first transaction promotes the others, which are popped in order
+	_, err := ts.Push(first)
+	require.NoError(t, err)
+	require.Empty(t, ts.PendingInPool())
+	require.Equal(t, transaction.StatusNotification{Status: transaction.Ready}, <-ch)
+
+	require.Equal(t, first, ts.Pop())
+	require.Equal(t, second, ts.Pop())
+	require.Equal(t, third, ts.Pop())
+}
+
+func TestTransactionState_PruneStale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockClient(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+	ts.PruneStale(10)
+
+	shortLived := &transaction.ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &transaction.Validity{Priority: 1, Longevity: 2},
+	}
+	longLived := &transaction.ValidTransaction{
+		Extrinsic: []byte("b"),
+		Validity:  &transaction.Validity{Priority: 1, Longevity: 64},
+	}
+	future := &transaction.ValidTransaction{
+		Extrinsic: []byte("c"),
+		Validity:  &transaction.Validity{Requires: [][]byte{[]byte("tag")}, Longevity: 1},
+	}
+	for _, tx := range []*transaction.ValidTransaction{shortLived, longLived, future} {
+		_, err := ts.Push(tx)
+		require.NoError(t, err)
+	}
+
+	ch := ts.GetStatusNotifierChannel(shortLived.Extrinsic)
+	defer ts.FreeStatusNotifierChannel(ch)
+
+	ts.PruneStale(12)
+	require.Len(t, ts.Pending(), 2)
+	require.Empty(t, ts.PendingInPool())
+
+	ts.PruneStale(13)
+	require.Equal(t, []*transaction.ValidTransaction{longLived}, ts.Pending())
+	require.Equal(t, transaction.StatusNotification{Status: transaction.Dropped}, <-ch)
+}
+
+func TestTransactionState_setBestNumber(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	telemetryMock := NewMockClient(ctrl)
+	telemetryMock.EXPECT().SendMessage(gomock.Any()).AnyTimes()
+
+	ts := NewTransactionState(telemetryMock)
+	ts.setBestNumber(100)
+
+	tx := &transaction.ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &transaction.Validity{Priority: 1, Longevity: 2},
+	}
+	_, err := ts.Push(tx)
+	require.NoError(t, err)
+
+	// the longevity is counted from the best block, not from genesis
+	ts.PruneStale(101)
+	require.Equal(t, []*transaction.ValidTransaction{tx}, ts.Pending())
+
+	ts.PruneStale(103)
+	require.Empty(t, ts.Pending())
+}
@@ -40,12 +40,16 @@ type chainProcessor struct {
 	finalityGadget     FinalityGadget
 	blockImportHandler BlockImportHandler
 	telemetry          telemetry.Client
+
+	// set if blocks are imported without their body and without being executed
+	headersOnly bool
 }
 
 func newChainProcessor(readyBlocks *blockQueue, pendingBlocks DisjointBlockSet,
 	blockState BlockState, storageState StorageState,
 	transactionState TransactionState, babeVerifier BabeVerifier,
-	finalityGadget FinalityGadget, blockImportHandler BlockImportHandler, telemetry telemetry.Client) *chainProcessor {
+	finalityGadget FinalityGadget, blockImportHandler BlockImportHandler, telemetry telemetry.Client,
+	headersOnly bool) *chainProcessor {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &chainProcessor{
@@ -60,6 +64,7 @@ func newChainProcessor(readyBlocks *blockQueue, pendingBlocks DisjointBlockSet,
 		finalityGadget:     finalityGadget,
 		blockImportHandler: blockImportHandler,
 		telemetry:          telemetry,
+		headersOnly:        headersOnly,
 	}
 }
 
@@ -94,7 +99,7 @@ func (s *chainProcessor) processReadyBlocks() {
 			logger.Tracef("block data processing for block with hash %s failed: %s", bd.Hash, err)
 			if err := s.pendingBlocks.addBlock(&types.Block{
 				Header: *bd.Header,
-				Body:   *bd.Body,
+				Body:   bodyOrEmpty(bd.Body),
 			}); err != nil {
 				logger.Debugf("failed to re-add block to pending blocks: %s", err)
 			}
@@ -110,6 +115,10 @@ func (s *chainProcessor) processBlockData(bd *types.BlockData) error {
 		return ErrNilBlockData
 	}
 
+	if s.headersOnly {
+		return s.processHeaderData(bd)
+	}
+
 	hasHeader, err := s.blockState.HasHeader(bd.Hash)
 	if err != nil {
 		return fmt.Errorf("failed to check if block state has header for hash %s: %w", bd.Hash, err)
@@ -197,6 +206,55 @@ func (s *chainProcessor) processBlockData(bd *types.BlockData) error {
 	return nil
 }
 
+// processHeaderData processes the BlockData from a BlockResponse when syncing headers only.
+// The header is verified and imported without executing the block, and the justification,
+// if any, is used to finalise the block.
+func (s *chainProcessor) processHeaderData(bd *types.BlockData) error {
+	header := bd.Header
+	if header == nil {
+		if bd.Justification == nil {
+			return nil
+		}
+
+		var err error
+		header, err = s.blockState.GetHeader(bd.Hash)
+		if err != nil {
+			return fmt.Errorf("failed to get header for justification of block %s: %w", bd.Hash, err)
+		}
+
+		s.handleJustification(header, *bd.Justification)
+		return nil
+	}
+
+	hasHeader, err := s.blockState.HasHeader(bd.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to check if block state has header for hash %s: %w", bd.Hash, err)
+	}
+
+	if !hasHeader {
+		if _, err = s.blockState.GetHeader(header.ParentHash); err != nil {
+			return fmt.Errorf("%w: %s", errFailedToGetParent, err)
+		}
+
+		if err = s.handleHeader(header); err != nil {
+			return err
+		}
+
+		if err = s.blockImportHandler.HandleHeaderImport(header); err != nil {
+			return fmt.Errorf("failed to handle header import: %w", err)
+		}
+
+		logger.Debugf("🔗 imported header number %d with hash %s", header.Number, bd.Hash)
+	}
+
+	if bd.Justification != nil {
+		logger.Debugf("handling Justification for block number %d with hash %s...", header.Number, bd.Hash)
+		s.handleJustification(header, *bd.Justification)
+	}
+
+	return nil
+}
+
 // handleHeader handles headers included in BlockResponses
 func (s *chainProcessor) handleHeader(header *types.Header) error {
 	err := s.babeVerifier.VerifyBlock(header)
@@ -285,3 +343,13 @@ func (s *chainProcessor) handleJustification(header *types.Header, justification
 
 	logger.Infof("🔨 finalised block number %d with hash %s", header.Number, header.Hash())
 }
+
+// bodyOrEmpty returns the given block body, or an empty body if it is nil,
+// which is the case for block data received when syncing headers only.
+func bodyOrEmpty(body *types.Body) types.Body {
+	if body == nil {
+		return types.Body{}
+	}
+
+	return *body
+}
@@ -10,6 +10,7 @@
 
 	"github.com/ChainSafe/gossamer/dot/network"
 	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/sync/mocks"
 	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/common/variadic"
 	"github.com/ChainSafe/gossamer/lib/transaction"
@@ -248,3 +249,36 @@ func TestChainProcessor_processReadyBlocks_errFailedToGetParent(t *testing.T) {
 	time.Sleep(time.Millisecond * 100)
 	require.True(t, processor.pendingBlocks.hasBlock(header.Hash()))
 }
+
+func TestChainProcessor_processHeaderData(t *testing.T) {
+	parent := &types.Header{Number: 1}
+	header := &types.Header{ParentHash: parent.Hash(), Number: 2}
+	justification := []byte{1, 2}
+
+	bs := new(mocks.BlockState)
+	bs.On("HasHeader", header.Hash()).Return(false, nil)
+	bs.On("GetHeader", parent.Hash()).Return(parent, nil)
+	bs.On("SetJustification", header.Hash(), justification).Return(nil)
+
+	importHandler := new(mocks.BlockImportHandler)
+	importHandler.On("HandleHeaderImport", header).Return(nil)
+
+	cp := &chainProcessor{
+		blockState:         bs,
+		babeVerifier:       newMockBabeVerifier(),
+		finalityGadget:     newMockFinalityGadget(),
+		blockImportHandler: importHandler,
+		headersOnly:        true,
+	}
+
+	bd := &types.BlockData{
+		Hash:          header.Hash(),
+		Header:        header,
+		Justification: &justification,
+	}
+
+	err := cp.processBlockData(bd)
+	require.NoError(t, err)
+	importHandler.AssertExpectations(t)
+	bs.AssertExpectations(t)
+}
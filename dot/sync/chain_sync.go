@@ -5,7 +5,6 @@
 
 import (
 	"context"
-	"crypto/rand"
 	"errors"
 	"fmt"
 	"math/big"
@@ -101,6 +100,9 @@ type ChainSync interface {
 	// called upon receiving a BlockAnnounceHandshake
 	setPeerHead(p peer.ID, hash common.Hash, number uint) error
 
+	// called when a peer disconnects from us
+	peerDisconnected(p peer.ID)
+
 	// syncState returns the current syncing state
 	syncState() chainSyncState
 
@@ -112,8 +114,10 @@ type chainSync struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	blockState BlockState
-	network    Network
+	blockState     BlockState
+	network        Network
+	finalityGadget FinalityGadget
+	storageState   StorageState
 
 	// queue of work created by setting peer heads
 	workQueue chan *peerState
@@ -124,8 +128,10 @@ type chainSync struct {
 	// tracks the latest state we know of from our peers,
 	// ie. their best block hash and number
 	sync.RWMutex
-	peerState   map[peer.ID]*peerState
-	ignorePeers map[peer.ID]struct{}
+	peerState map[peer.ID]*peerState
+
+	// tracks how well our peers served our block requests, to choose which peer to sync from
+	syncPeers *syncPeers
 
 	// current workers that are attempting to obtain blocks
 	workerState *workerState
@@ -161,15 +167,25 @@ type chainSync struct {
 	minPeers         int
 	maxWorkerRetries uint16
 	slotDuration     time.Duration
+
+	// set if only block headers and justifications are synced
+	headersOnly bool
+
+	// set if the finalised block and its state are downloaded before syncing the following blocks
+	warpSync bool
 }
 
 type chainSyncConfig struct {
 	bs                 BlockState
+	storageState       StorageState
 	net                Network
+	finalityGadget     FinalityGadget
 	readyBlocks        *blockQueue
 	pendingBlocks      DisjointBlockSet
 	minPeers, maxPeers int
 	slotDuration       time.Duration
+	headersOnly        bool
+	warpSync           bool
 }
 
 func newChainSync(cfg *chainSyncConfig) *chainSync {
@@ -180,10 +196,12 @@ func newChainSync(cfg *chainSyncConfig) *chainSync {
 		cancel:           cancel,
 		blockState:       cfg.bs,
 		network:          cfg.net,
+		finalityGadget:   cfg.finalityGadget,
+		storageState:     cfg.storageState,
 		workQueue:        make(chan *peerState, 1024),
 		resultQueue:      make(chan *worker, 1024),
 		peerState:        make(map[peer.ID]*peerState),
-		ignorePeers:      make(map[peer.ID]struct{}),
+		syncPeers:        newSyncPeers(),
 		workerState:      newWorkerState(),
 		readyBlocks:      cfg.readyBlocks,
 		pendingBlocks:    cfg.pendingBlocks,
@@ -194,6 +212,8 @@ func newChainSync(cfg *chainSyncConfig) *chainSync {
 		minPeers:         cfg.minPeers,
 		maxWorkerRetries: uint16(cfg.maxPeers),
 		slotDuration:     cfg.slotDuration,
+		headersOnly:      cfg.headersOnly,
+		warpSync:         cfg.warpSync,
 	}
 }
 
@@ -209,6 +229,27 @@ func (cs *chainSync) start() {
 		time.Sleep(time.Millisecond * 100)
 	}
 
+	if cs.warpSync {
+		if err := cs.warpSyncToFinalised(); err != nil {
+			logger.Warnf("failed to warp sync, syncing all blocks instead: %s", err)
+		}
+
+		// the blocks following the finalised block cannot be imported without its state
+		for {
+			err := cs.syncFinalisedState()
+			if err == nil {
+				break
+			}
+
+			logger.Warnf("failed to download state of finalised block, retrying: %s", err)
+			select {
+			case <-time.After(cs.slotDuration):
+			case <-cs.ctx.Done():
+				return
+			}
+		}
+	}
+
 	isSyncedGauge.Set(float64(cs.state))
 
 	pendingBlockDoneCh := make(chan struct{})
@@ -252,6 +293,15 @@ func (cs *chainSync) setBlockAnnounce(from peer.ID, header *types.Header) error
 	return cs.setPeerHead(from, header.Hash(), header.Number)
 }
 
+// peerDisconnected forgets the best block and the score of a peer which disconnected from us
+func (cs *chainSync) peerDisconnected(p peer.ID) {
+	cs.Lock()
+	delete(cs.peerState, p)
+	cs.Unlock()
+
+	cs.syncPeers.remove(p)
+}
+
 // setPeerHead sets a peer's best known block and potentially adds the peer's state to the workQueue
 func (cs *chainSync) setPeerHead(p peer.ID, hash common.Hash, number uint) error {
 	ps := &peerState{
@@ -294,12 +344,11 @@ func (cs *chainSync) setPeerHead(p peer.ID, hash common.Hash, number uint) error
 		// chain), and also the highest finalised block is higher than that number.
 		// thus the peer is on an invalid chain
 		if fin.Number >= ps.number {
-			// TODO: downscore this peer, or temporarily don't sync from them? (#1399)
-			// perhaps we need another field in `peerState` to mark whether the state is valid or not
 			cs.network.ReportPeer(peerset.ReputationChange{
 				Value:  peerset.BadBlockAnnouncementValue,
 				Reason: peerset.BadBlockAnnouncementReason,
 			}, p)
+			cs.syncPeers.exclude(p)
 			return errPeerOnInvalidFork
 		}
 
@@ -388,16 +437,6 @@ func (cs *chainSync) logSyncSpeed() {
 	}
 }
 
-func (cs *chainSync) ignorePeer(who peer.ID) {
-	if err := who.Validate(); err != nil {
-		return
-	}
-
-	cs.Lock()
-	cs.ignorePeers[who] = struct{}{}
-	cs.Unlock()
-}
-
 func (cs *chainSync) sync() {
 	// set to slot time
 	ticker := time.NewTicker(cs.slotDuration)
@@ -422,8 +461,8 @@ func (cs *chainSync) sync() {
 
 			logger.Debugf("worker id %d failed: %s", res.id, res.err.err)
 
-			// handle errors. in the case that a peer did not respond to us in time,
-			// temporarily add them to the ignore list.
+			// handle errors. the peer which failed the request was already
+			// temporarily excluded from syncing by `doSync`.
 			switch {
 			case errors.Is(res.err.err, context.Canceled):
 				return
@@ -435,16 +474,13 @@ func (cs *chainSync) sync() {
 					Value:  peerset.TimeOutValue,
 					Reason: peerset.TimeOutReason,
 				}, res.err.who)
-				cs.ignorePeer(res.err.who)
 			case strings.Contains(res.err.err.Error(), "dial backoff"):
-				cs.ignorePeer(res.err.who)
 				continue
 			case res.err.err.Error() == "protocol not supported":
 				cs.network.ReportPeer(peerset.ReputationChange{
 					Value:  peerset.BadProtocolValue,
 					Reason: peerset.BadProtocolReason,
 				}, res.err.who)
-				cs.ignorePeer(res.err.who)
 				continue
 			default:
 			}
@@ -540,7 +576,7 @@ func (cs *chainSync) setMode(mode chainSyncState) {
 	case bootstrap:
 		cs.handler = newBootstrapSyncer(cs.blockState)
 	case tip:
-		cs.handler = newTipSyncer(cs.blockState, cs.pendingBlocks, cs.readyBlocks, cs.handleReadyBlock)
+		cs.handler = newTipSyncer(cs.blockState, cs.pendingBlocks, cs.readyBlocks, cs.handleReadyBlock, cs.headersOnly)
 	}
 
 	cs.state = mode
@@ -637,6 +673,11 @@ func (cs *chainSync) dispatchWorker(w *worker) {
 		cs.resultQueue <- w
 	}()
 
+	if cs.headersOnly {
+		// block bodies are neither requested nor stored when syncing headers only
+		w.requestData &^= network.RequestedDataBody
+	}
+
 	reqs, err := workerToRequests(w)
 	if err != nil {
 		// if we are creating valid workers, this should not happen
@@ -648,7 +689,6 @@ func (cs *chainSync) dispatchWorker(w *worker) {
 	}
 
 	for _, req := range reqs {
-		// TODO: if we find a good peer, do sync with them, right now it re-selects a peer each time (#1399)
 		if err := cs.doSync(req, w.peersTried); err != nil {
 			// failed to sync, set worker error and put into result queue
 			w.err = err
@@ -670,11 +710,11 @@ func (cs *chainSync) doSync(req *network.BlockRequestMessage, peersTried map[pee
 	// send out request and potentially receive response, error if timeout
 	logger.Tracef("sending out block request: %s", req)
 
-	// TODO: use scoring to determine what peer to try to sync from first (#1399)
-	idx, _ := rand.Int(rand.Reader, big.NewInt(int64(len(peers))))
-	who := peers[idx.Int64()]
+	who := cs.syncPeers.best(peers)
+	start := time.Now()
 	resp, err := cs.network.DoBlockRequest(who, req)
 	if err != nil {
+		cs.syncPeers.onFailure(who)
 		return &workerError{
 			err: err,
 			who: who,
@@ -682,12 +722,15 @@ func (cs *chainSync) doSync(req *network.BlockRequestMessage, peersTried map[pee
 	}
 
 	if resp == nil {
+		cs.syncPeers.onFailure(who)
 		return &workerError{
 			err: errNilResponse,
 			who: who,
 		}
 	}
 
+	latency := time.Since(start)
+
 	if req.Direction == network.Descending {
 		// reverse blocks before pre-validating and placing in ready queue
 		reverseBlockData(resp.BlockData)
@@ -695,12 +738,15 @@ func (cs *chainSync) doSync(req *network.BlockRequestMessage, peersTried map[pee
 
 	// perform some pre-validation of response, error if failure
 	if err := cs.validateResponse(req, resp, who); err != nil {
+		cs.handleInvalidResponse(who, err)
 		return &workerError{
 			err: err,
 			who: who,
 		}
 	}
 
+	cs.syncPeers.onSuccess(who, latency, responseSize(resp))
+
 	logger.Trace("success! placing block response data in ready queue")
 
 	// response was validated! place into ready block queue
@@ -772,21 +818,23 @@ func (cs *chainSync) determineSyncPeers(req *network.BlockRequestMessage, peersT
 	cs.RLock()
 	defer cs.RUnlock()
 
-	// if we're currently ignoring all our peers, clear out the list.
-	if len(cs.peerState) == len(cs.ignorePeers) {
-		cs.RUnlock()
-		cs.Lock()
-		for p := range cs.ignorePeers {
-			delete(cs.ignorePeers, p)
+	// if we're currently excluding all our peers, allow them again.
+	allExcluded := true
+	for p := range cs.peerState {
+		if !cs.syncPeers.isExcluded(p) {
+			allExcluded = false
+			break
 		}
-		cs.Unlock()
-		cs.RLock()
+	}
+
+	if allExcluded {
+		cs.syncPeers.clearExclusions()
 	}
 
 	peers := make([]peer.ID, 0, len(cs.peerState))
 
 	for p, state := range cs.peerState {
-		if _, has := cs.ignorePeers[p]; has {
+		if cs.syncPeers.isExcluded(p) {
 			continue
 		}
 
@@ -806,6 +854,43 @@ func (cs *chainSync) determineSyncPeers(req *network.BlockRequestMessage, peersT
 	return peers
 }
 
+// handleInvalidResponse downscores the peer which sent us an invalid block response,
+// and temporarily excludes it from syncing.
+func (cs *chainSync) handleInvalidResponse(who peer.ID, err error) {
+	switch {
+	case errors.Is(err, errUnknownParent):
+		// the peer is on a fork we don't know yet, which isn't misbehaviour
+		return
+	case errors.Is(err, errEmptyBlockData),
+		errors.Is(err, errNilBlockData),
+		errors.Is(err, errNilBodyInResponse),
+		errors.Is(err, errResponseIsNotChain):
+		cs.network.ReportPeer(peerset.ReputationChange{
+			Value:  peerset.BadBlockResponseValue,
+			Reason: peerset.BadBlockResponseReason,
+		}, who)
+	}
+
+	cs.syncPeers.onFailure(who)
+}
+
+// responseSize returns the size of the block bodies and justifications in the block response
+func responseSize(resp *network.BlockResponseMessage) (size uint64) {
+	for _, bd := range resp.BlockData {
+		if bd.Body != nil {
+			for _, ext := range *bd.Body {
+				size += uint64(len(ext))
+			}
+		}
+
+		if bd.Justification != nil {
+			size += uint64(len(*bd.Justification))
+		}
+	}
+
+	return size
+}
+
 // validateResponse performs pre-validation of a block response before placing it into either the
 // pendingBlocks or readyBlocks set.
 // It checks the following:
@@ -862,7 +947,7 @@ func (cs *chainSync) validateResponse(req *network.BlockRequestMessage,
 			// parent unknown, add to pending blocks
 			if err := cs.pendingBlocks.addBlock(&types.Block{
 				Header: *curr,
-				Body:   *bd.Body,
+				Body:   bodyOrEmpty(bd.Body),
 			}); err != nil {
 				return err
 			}
@@ -883,7 +968,7 @@ func (cs *chainSync) validateResponse(req *network.BlockRequestMessage,
 			for _, bd := range resp.BlockData[i:] {
 				if err := cs.pendingBlocks.addBlock(&types.Block{
 					Header: *curr,
-					Body:   *bd.Body,
+					Body:   bodyOrEmpty(bd.Body),
 				}); err != nil {
 					return err
 				}
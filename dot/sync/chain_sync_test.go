@@ -783,19 +783,20 @@ func TestChainSync_determineSyncPeers(t *testing.T) {
 	require.Contains(t, peers, testPeerA)
 	require.Contains(t, peers, testPeerB)
 
-	// test peer ignored case
-	cs.ignorePeers[testPeerA] = struct{}{}
+	// test peer excluded case
+	cs.syncPeers.exclude(testPeerA)
 	peers = cs.determineSyncPeers(req, peersTried)
 	require.Equal(t, 1, len(peers))
 	require.Equal(t, []peer.ID{testPeerB}, peers)
 
-	// test all peers ignored case
-	cs.ignorePeers[testPeerB] = struct{}{}
+	// test all peers excluded case
+	cs.syncPeers.onFailure(testPeerB)
 	peers = cs.determineSyncPeers(req, peersTried)
 	require.Equal(t, 2, len(peers))
 	require.Contains(t, peers, testPeerA)
 	require.Contains(t, peers, testPeerB)
-	require.Equal(t, 0, len(cs.ignorePeers))
+	require.False(t, cs.syncPeers.isExcluded(testPeerA))
+	require.False(t, cs.syncPeers.isExcluded(testPeerB))
 
 	// test peer's best block below number case, shouldn't include that peer
 	start, err := variadic.NewUint32OrHash(130)
@@ -813,6 +814,25 @@ func TestChainSync_determineSyncPeers(t *testing.T) {
 	require.Equal(t, []peer.ID{testPeerB}, peers)
 }
 
+func TestChainSync_peerDisconnected(t *testing.T) {
+	cs, _ := newTestChainSync(t)
+
+	testPeerA := peer.ID("a")
+	testPeerB := peer.ID("b")
+	cs.peerState[testPeerA] = &peerState{number: 129}
+	cs.peerState[testPeerB] = &peerState{number: 257}
+	cs.syncPeers.onSuccess(testPeerA, time.Second, 1000)
+	cs.syncPeers.onFailure(testPeerB)
+
+	cs.peerDisconnected(testPeerB)
+	require.NotContains(t, cs.peerState, testPeerB)
+	require.NotContains(t, cs.syncPeers.scores, testPeerB)
+	require.False(t, cs.syncPeers.isExcluded(testPeerB))
+
+	require.Contains(t, cs.peerState, testPeerA)
+	require.Contains(t, cs.syncPeers.scores, testPeerA)
+}
+
 func TestChainSync_highestBlock(t *testing.T) {
 	type input struct {
 		peerState map[peer.ID]*peerState
@@ -47,6 +47,12 @@
 	errFailedToGetParent            = errors.New("failed to get parent header")
 	errStartAndEndMismatch          = errors.New("request start and end hash are not on the same chain")
 	errFailedToGetDescendant        = errors.New("failed to find descendant block")
+	errEmptyUnfinishedWarpSyncProof = errors.New("warp sync proof is empty but not finished")
+	errStateRootMismatch            = errors.New("downloaded state does not match the state root")
+
+	// state response errors
+	errInvalidStateRequestStart = errors.New("state request start must contain at most two keys")
+	errInvalidChildTrieKey      = errors.New("key is not a child trie key")
 )
 
 // ErrNilChannel is returned if a channel is nil
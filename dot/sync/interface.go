@@ -36,11 +36,13 @@ type BlockState interface {
 	GetJustification(common.Hash) ([]byte, error)
 	SetJustification(hash common.Hash, data []byte) error
 	SetFinalisedHash(hash common.Hash, round, setID uint64) error
+	SetWarpSyncedHeader(header *types.Header, round, setID uint64) error
 	AddBlockToBlockTree(block *types.Block) error
 	GetHashByNumber(blockNumber uint) (common.Hash, error)
 	GetBlockByHash(common.Hash) (*types.Block, error)
 	GetRuntime(*common.Hash) (runtime.Instance, error)
 	StoreRuntime(common.Hash, runtime.Instance)
+	HandleRuntimeChanges(newState *rtstorage.TrieState, in runtime.Instance, bHash common.Hash) error
 	GetHighestFinalisedHeader() (*types.Header, error)
 	GetFinalisedNotifierChannel() chan *types.FinalisationInfo
 	GetHeaderByNumber(num uint) (*types.Header, error)
@@ -48,10 +50,13 @@ type BlockState interface {
 	IsDescendantOf(parent, child common.Hash) (bool, error)
 }
 
+//go:generate mockery --name StorageState --structname StorageState --case underscore --keeptree
+
 // StorageState is the interface for the storage state
 type StorageState interface {
 	TrieState(root *common.Hash) (*rtstorage.TrieState, error)
 	LoadCodeHash(*common.Hash) (common.Hash, error)
+	StoreTrie(*rtstorage.TrieState, *types.Header) error
 	sync.Locker
 }
 
@@ -78,6 +83,15 @@ type BabeVerifier interface {
 // FinalityGadget implements justification verification functionality
 type FinalityGadget interface {
 	VerifyBlockJustification(common.Hash, []byte) error
+
+	// VerifyWarpSyncProof verifies the authority set changes of the warp sync proof starting after the block
+	// with the given number, and returns the header of its last fragment along with the round and set ID of
+	// its justification, and the authority set changes it enacts.
+	VerifyWarpSyncProof(begin uint, proof *network.WarpSyncProof) (header *types.Header, round, setID uint64,
+		changes []types.GrandpaAuthoritySetChange, err error)
+
+	// ApplyAuthoritySetChanges records the authority set changes of a verified warp sync proof
+	ApplyAuthoritySetChanges(changes []types.GrandpaAuthoritySetChange) error
 }
 
 //go:generate mockery --name BlockImportHandler --structname BlockImportHandler --case underscore --keeptree
@@ -85,6 +99,7 @@ type FinalityGadget interface {
 // BlockImportHandler is the interface for the handler of newly imported blocks
 type BlockImportHandler interface {
 	HandleBlockImport(block *types.Block, state *rtstorage.TrieState) error
+	HandleHeaderImport(header *types.Header) error
 }
 
 //go:generate mockery --name Network --structname Network --case underscore --keeptree
@@ -96,6 +111,16 @@ type Network interface {
 	// it is returned, otherwise an error is returned.
 	DoBlockRequest(to peer.ID, req *network.BlockRequestMessage) (*network.BlockResponseMessage, error)
 
+	// DoWarpSyncRequest sends a warp sync request to the given peer.
+	// If a response is received within a certain time period,
+	// it is returned, otherwise an error is returned.
+	DoWarpSyncRequest(to peer.ID, req *network.WarpSyncRequest) (*network.WarpSyncProof, error)
+
+	// DoStateRequest sends a state request to the given peer.
+	// If a response is received within a certain time period,
+	// it is returned, otherwise an error is returned.
+	DoStateRequest(to peer.ID, req *network.StateRequest) (*network.StateResponse, error)
+
 	// Peers returns a list of currently connected peers
 	Peers() []common.PeerInfo
 
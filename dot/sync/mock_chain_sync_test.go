@@ -51,6 +51,18 @@ func (mr *MockChainSyncMockRecorder) getHighestBlock() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getHighestBlock", reflect.TypeOf((*MockChainSync)(nil).getHighestBlock))
 }
 
+// peerDisconnected mocks base method.
+func (m *MockChainSync) peerDisconnected(arg0 peer.ID) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "peerDisconnected", arg0)
+}
+
+// peerDisconnected indicates an expected call of peerDisconnected.
+func (mr *MockChainSyncMockRecorder) peerDisconnected(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "peerDisconnected", reflect.TypeOf((*MockChainSync)(nil).peerDisconnected), arg0)
+}
+
 // setBlockAnnounce mocks base method.
 func (m *MockChainSync) setBlockAnnounce(arg0 peer.ID, arg1 *types.Header) error {
 	m.ctrl.T.Helper()
@@ -27,3 +27,17 @@ func (_m *BlockImportHandler) HandleBlockImport(block *types.Block, state *stora
 
 	return r0
 }
+
+// HandleHeaderImport provides a mock function with given fields: header
+func (_m *BlockImportHandler) HandleHeaderImport(header *types.Header) error {
+	ret := _m.Called(header)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*types.Header) error); ok {
+		r0 = rf(header)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -8,6 +8,8 @@
 
 	runtime "github.com/ChainSafe/gossamer/lib/runtime"
 
+	storage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+
 	types "github.com/ChainSafe/gossamer/dot/types"
 )
 
@@ -410,6 +412,20 @@ func (_m *BlockState) GetRuntime(_a0 *common.Hash) (runtime.Instance, error) {
 	return r0, r1
 }
 
+// HandleRuntimeChanges provides a mock function with given fields: newState, in, bHash
+func (_m *BlockState) HandleRuntimeChanges(newState *storage.TrieState, in runtime.Instance, bHash common.Hash) error {
+	ret := _m.Called(newState, in, bHash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*storage.TrieState, runtime.Instance, common.Hash) error); ok {
+		r0 = rf(newState, in, bHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // HasBlockBody provides a mock function with given fields: hash
 func (_m *BlockState) HasBlockBody(hash common.Hash) (bool, error) {
 	ret := _m.Called(hash)
@@ -515,6 +531,20 @@ func (_m *BlockState) SetJustification(hash common.Hash, data []byte) error {
 	return r0
 }
 
+// SetWarpSyncedHeader provides a mock function with given fields: header, round, setID
+func (_m *BlockState) SetWarpSyncedHeader(header *types.Header, round uint64, setID uint64) error {
+	ret := _m.Called(header, round, setID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*types.Header, uint64, uint64) error); ok {
+		r0 = rf(header, round, setID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // StoreRuntime provides a mock function with given fields: _a0, _a1
 func (_m *BlockState) StoreRuntime(_a0 common.Hash, _a1 runtime.Instance) {
 	_m.Called(_a0, _a1)
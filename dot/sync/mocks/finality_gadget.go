@@ -5,6 +5,10 @@
 import (
 	common "github.com/ChainSafe/gossamer/lib/common"
 	mock "github.com/stretchr/testify/mock"
+
+	network "github.com/ChainSafe/gossamer/dot/network"
+
+	types "github.com/ChainSafe/gossamer/dot/types"
 )
 
 // FinalityGadget is an autogenerated mock type for the FinalityGadget type
@@ -12,6 +16,20 @@ type FinalityGadget struct {
 	mock.Mock
 }
 
+// ApplyAuthoritySetChanges provides a mock function with given fields: changes
+func (_m *FinalityGadget) ApplyAuthoritySetChanges(changes []types.GrandpaAuthoritySetChange) error {
+	ret := _m.Called(changes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]types.GrandpaAuthoritySetChange) error); ok {
+		r0 = rf(changes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // VerifyBlockJustification provides a mock function with given fields: _a0, _a1
 func (_m *FinalityGadget) VerifyBlockJustification(_a0 common.Hash, _a1 []byte) error {
 	ret := _m.Called(_a0, _a1)
@@ -25,3 +43,49 @@ func (_m *FinalityGadget) VerifyBlockJustification(_a0 common.Hash, _a1 []byte)
 
 	return r0
 }
+
+// VerifyWarpSyncProof provides a mock function with given fields: begin, proof
+func (_m *FinalityGadget) VerifyWarpSyncProof(begin uint, proof *network.WarpSyncProof) (*types.Header, uint64, uint64, []types.GrandpaAuthoritySetChange, error) {
+	ret := _m.Called(begin, proof)
+
+	var r0 *types.Header
+	if rf, ok := ret.Get(0).(func(uint, *network.WarpSyncProof) *types.Header); ok {
+		r0 = rf(begin, proof)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Header)
+		}
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(uint, *network.WarpSyncProof) uint64); ok {
+		r1 = rf(begin, proof)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 uint64
+	if rf, ok := ret.Get(2).(func(uint, *network.WarpSyncProof) uint64); ok {
+		r2 = rf(begin, proof)
+	} else {
+		r2 = ret.Get(2).(uint64)
+	}
+
+	var r3 []types.GrandpaAuthoritySetChange
+	if rf, ok := ret.Get(3).(func(uint, *network.WarpSyncProof) []types.GrandpaAuthoritySetChange); ok {
+		r3 = rf(begin, proof)
+	} else {
+		if ret.Get(3) != nil {
+			r3 = ret.Get(3).([]types.GrandpaAuthoritySetChange)
+		}
+	}
+
+	var r4 error
+	if rf, ok := ret.Get(4).(func(uint, *network.WarpSyncProof) error); ok {
+		r4 = rf(begin, proof)
+	} else {
+		r4 = ret.Error(4)
+	}
+
+	return r0, r1, r2, r3, r4
+}
@@ -0,0 +1,248 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// maxStateResponseSize is the size of the trie nodes of a StateResponse after which no more entries are added
+const maxStateResponseSize = 2 * 1024 * 1024
+
+// CreateStateResponse creates a state response from a state request, which contains the proof
+// of the storage entries following the start key of the request, or the entries themselves.
+func (s *Service) CreateStateResponse(req *network.StateRequest) (*network.StateResponse, error) {
+	header, err := s.blockState.GetHeader(req.Block)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get header: %w", err)
+	}
+
+	ts, err := s.storageState.TrieState(&header.StateRoot)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state: %w", err)
+	}
+
+	t := ts.Trie()
+	var (
+		stateRoot []byte
+		start     []byte
+	)
+
+	switch len(req.Start) {
+	case 0:
+	case 1:
+		start = req.Start[0]
+	case 2:
+		if !bytes.HasPrefix(req.Start[0], trie.ChildStorageKeyPrefix) {
+			return nil, fmt.Errorf("%w: 0x%x", errInvalidChildTrieKey, req.Start[0])
+		}
+
+		t, err = t.GetChild(req.Start[0][len(trie.ChildStorageKeyPrefix):])
+		if err != nil {
+			return nil, fmt.Errorf("cannot get child trie: %w", err)
+		}
+
+		root, err := t.Hash()
+		if err != nil {
+			return nil, fmt.Errorf("cannot hash child trie: %w", err)
+		}
+
+		stateRoot = root[:]
+		start = req.Start[1]
+	default:
+		return nil, fmt.Errorf("%w: got %d keys", errInvalidStateRequestStart, len(req.Start))
+	}
+
+	proof, pairs, complete, err := t.GenerateRangeProof(start, maxStateResponseSize)
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate range proof: %w", err)
+	}
+
+	if !req.NoProof {
+		return &network.StateResponse{
+			Proof: proof,
+		}, nil
+	}
+
+	entries := make([]network.StateEntry, len(pairs))
+	for i, pair := range pairs {
+		entries[i] = network.StateEntry{
+			Key:   pair.Key,
+			Value: pair.Value,
+		}
+	}
+
+	return &network.StateResponse{
+		Entries: []network.KeyValueStateEntry{{
+			StateRoot: stateRoot,
+			Entries:   entries,
+			Complete:  complete,
+		}},
+	}, nil
+}
+
+// syncFinalisedState downloads the state of our highest finalised block from our peers if we don't have it,
+// which is the case after warp syncing, and stores it so that the blocks following it can be imported.
+func (cs *chainSync) syncFinalisedState() error {
+	header, err := cs.blockState.GetHighestFinalisedHeader()
+	if err != nil {
+		return fmt.Errorf("cannot get highest finalised header: %w", err)
+	}
+
+	if _, err = cs.storageState.TrieState(&header.StateRoot); err == nil {
+		return nil
+	}
+
+	hash := header.Hash()
+	logger.Infof("downloading state of block number %d with hash %s...", header.Number, hash)
+
+	t, err := cs.downloadState(header)
+	if err != nil {
+		return err
+	}
+
+	ts, err := rtstorage.NewTrieState(t)
+	if err != nil {
+		return fmt.Errorf("cannot create trie state: %w", err)
+	}
+
+	cs.storageState.Lock()
+	err = cs.storageState.StoreTrie(ts, header)
+	cs.storageState.Unlock()
+	if err != nil {
+		return fmt.Errorf("cannot store state: %w", err)
+	}
+
+	rt, err := cs.blockState.GetRuntime(&hash)
+	if err != nil {
+		return fmt.Errorf("cannot get runtime: %w", err)
+	}
+
+	err = cs.blockState.HandleRuntimeChanges(ts, rt, hash)
+	if err != nil {
+		return fmt.Errorf("cannot handle runtime changes: %w", err)
+	}
+
+	logger.Infof("downloaded state of block number %d with hash %s", header.Number, hash)
+	return nil
+}
+
+// downloadState downloads the state trie of the block, followed by each of its child tries.
+func (cs *chainSync) downloadState(header *types.Header) (*trie.Trie, error) {
+	hash := header.Hash()
+
+	top := trie.NewEmptyTrie()
+	err := cs.downloadTrie(hash, header.StateRoot[:], nil, top.Put)
+	if err != nil {
+		return nil, fmt.Errorf("cannot download state trie: %w", err)
+	}
+
+	for _, key := range top.GetKeysWithPrefix(trie.ChildStorageKeyPrefix) {
+		child := trie.NewEmptyTrie()
+		err = cs.downloadTrie(hash, top.Get(key), key, child.Put)
+		if err != nil {
+			return nil, fmt.Errorf("cannot download child trie at key 0x%x: %w", key, err)
+		}
+
+		err = top.PutChild(key[len(trie.ChildStorageKeyPrefix):], child)
+		if err != nil {
+			return nil, fmt.Errorf("cannot put child trie at key 0x%x: %w", key, err)
+		}
+	}
+
+	root, err := top.Hash()
+	if err != nil {
+		return nil, fmt.Errorf("cannot hash state trie: %w", err)
+	}
+
+	if root != header.StateRoot {
+		return nil, fmt.Errorf("%w: expected %s, got %s", errStateRootMismatch, header.StateRoot, root)
+	}
+
+	return top, nil
+}
+
+// downloadTrie requests the entries of the trie with the given root in chunks, verifying each of them
+// against the root, until the trie is complete. childKey is the key of the child trie in the state
+// trie, or nil to download the state trie.
+func (cs *chainSync) downloadTrie(block common.Hash, root, childKey []byte, put func(key, value []byte)) error {
+	var start []byte
+	peersTried := make(map[peer.ID]struct{})
+	for {
+		peers := cs.determineUntriedPeers(peersTried)
+		if len(peers) == 0 {
+			return errNoPeers
+		}
+
+		who := cs.syncPeers.best(peers)
+		req := &network.StateRequest{
+			Block: block,
+		}
+
+		switch {
+		case childKey != nil:
+			req.Start = [][]byte{childKey, start}
+		case start != nil:
+			req.Start = [][]byte{start}
+		}
+
+		logger.Debugf("sending state request to peer %s: %s", who, req)
+
+		requestStart := time.Now()
+		resp, err := cs.network.DoStateRequest(who, req)
+		if err != nil {
+			logger.Debugf("failed to get state response from peer %s: %s", who, err)
+			cs.syncPeers.onFailure(who)
+			peersTried[who] = struct{}{}
+			continue
+		}
+
+		latency := time.Since(requestStart)
+
+		pairs, complete, err := trie.VerifyRangeProof(resp.Proof, root, start)
+		if err != nil {
+			cs.handleInvalidStateResponse(who, err)
+			peersTried[who] = struct{}{}
+			continue
+		}
+
+		var bytesServed uint64
+		for _, node := range resp.Proof {
+			bytesServed += uint64(len(node))
+		}
+		cs.syncPeers.onSuccess(who, latency, bytesServed)
+
+		for _, pair := range pairs {
+			put(pair.Key, pair.Value)
+		}
+
+		if complete {
+			return nil
+		}
+
+		start = pairs[len(pairs)-1].Key
+	}
+}
+
+// handleInvalidStateResponse lowers the reputation of a peer which sent a state proof we cannot verify
+func (cs *chainSync) handleInvalidStateResponse(who peer.ID, err error) {
+	logger.Debugf("invalid state response from peer %s: %s", who, err)
+
+	cs.network.ReportPeer(peerset.ReputationChange{
+		Value:  peerset.BadStateResponseValue,
+		Reason: peerset.BadStateResponseReason,
+	}, who)
+	cs.syncPeers.onFailure(who)
+}
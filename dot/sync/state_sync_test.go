@@ -0,0 +1,195 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	syncmocks "github.com/ChainSafe/gossamer/dot/sync/mocks"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStateService returns a Service serving the state of the returned header,
+// which contains a child trie at the returned key.
+func newTestStateService(t *testing.T) (*Service, *types.Header, []byte) {
+	t.Helper()
+
+	child := trie.NewEmptyTrie()
+	child.Put([]byte("ferret"), []byte("dook"))
+	child.Put([]byte("fox"), []byte("yip"))
+
+	state := trie.NewEmptyTrie()
+	state.Put([]byte("cat"), []byte("meow"))
+	state.Put([]byte("dog"), []byte("woof"))
+	err := state.PutChild([]byte("pets"), child)
+	require.NoError(t, err)
+
+	ts, err := rtstorage.NewTrieState(state)
+	require.NoError(t, err)
+
+	header, err := types.NewHeader(common.Hash{1}, state.MustHash(), trie.EmptyHash, 100, types.NewDigest())
+	require.NoError(t, err)
+
+	bs := new(syncmocks.BlockState)
+	bs.On("GetHeader", header.Hash()).Return(header, nil)
+
+	ss := new(syncmocks.StorageState)
+	ss.On("TrieState", &header.StateRoot).Return(ts, nil)
+
+	s := &Service{
+		blockState:   bs,
+		storageState: ss,
+	}
+
+	return s, header, append(append([]byte{}, trie.ChildStorageKeyPrefix...), []byte("pets")...)
+}
+
+func TestService_CreateStateResponse(t *testing.T) {
+	s, header, childKey := newTestStateService(t)
+
+	resp, err := s.CreateStateResponse(&network.StateRequest{
+		Block: header.Hash(),
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Entries)
+
+	pairs, complete, err := trie.VerifyRangeProof(resp.Proof, header.StateRoot[:], nil)
+	require.NoError(t, err)
+	require.True(t, complete)
+	require.Len(t, pairs, 3)
+	require.Equal(t, childKey, pairs[0].Key)
+	require.Equal(t, []byte("cat"), pairs[1].Key)
+	require.Equal(t, []byte("dog"), pairs[2].Key)
+	childRoot := pairs[0].Value
+
+	resp, err = s.CreateStateResponse(&network.StateRequest{
+		Block: header.Hash(),
+		Start: [][]byte{[]byte("cat")},
+	})
+	require.NoError(t, err)
+
+	pairs, complete, err = trie.VerifyRangeProof(resp.Proof, header.StateRoot[:], []byte("cat"))
+	require.NoError(t, err)
+	require.True(t, complete)
+	require.Equal(t, []trie.Pair{{Key: []byte("dog"), Value: []byte("woof")}}, pairs)
+	resp, err = s.CreateStateResponse(&network.StateRequest{
+		Block:   header.Hash(),
+		Start:   [][]byte{childKey, nil},
+		NoProof: true,
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Proof)
+	require.Equal(t, []network.KeyValueStateEntry{{
+		StateRoot: childRoot,
+		Entries: []network.StateEntry{
+			{Key: []byte("ferret"), Value: []byte("dook")},
+			{Key: []byte("fox"), Value: []byte("yip")},
+		},
+		Complete: true,
+	}}, resp.Entries)
+
+	_, err = s.CreateStateResponse(&network.StateRequest{
+		Block: header.Hash(),
+		Start: [][]byte{[]byte("cat"), nil},
+	})
+	require.ErrorIs(t, err, errInvalidChildTrieKey)
+
+	_, err = s.CreateStateResponse(&network.StateRequest{
+		Block: header.Hash(),
+		Start: [][]byte{childKey, nil, nil},
+	})
+	require.ErrorIs(t, err, errInvalidStateRequestStart)
+}
+
+func TestChainSync_syncFinalisedState(t *testing.T) {
+	cs, _ := newTestChainSync(t)
+	server, header, _ := newTestStateService(t)
+	hash := header.Hash()
+
+	bs := new(syncmocks.BlockState)
+	bs.On("GetHighestFinalisedHeader").Return(header, nil)
+	bs.On("GetRuntime", &hash).Return(nil, nil)
+	bs.On("HandleRuntimeChanges", mock.AnythingOfType("*storage.TrieState"), nil, hash).Return(nil)
+	cs.blockState = bs
+
+	var stored *rtstorage.TrieState
+	ss := new(syncmocks.StorageState)
+	ss.On("TrieState", &header.StateRoot).Return(nil, errors.New("not found"))
+	ss.On("Lock")
+	ss.On("Unlock")
+	ss.On("StoreTrie", mock.AnythingOfType("*storage.TrieState"), header).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(0).(*rtstorage.TrieState)
+		}).Return(nil)
+	cs.storageState = ss
+
+	net := new(syncmocks.Network)
+	net.On("DoStateRequest", peer.ID("a"), mock.AnythingOfType("*network.StateRequest")).
+		Return(nil, errors.New("timeout"))
+	net.On("DoStateRequest", peer.ID("b"), mock.AnythingOfType("*network.StateRequest")).
+		Return(func(_ peer.ID, req *network.StateRequest) *network.StateResponse {
+			resp, err := server.CreateStateResponse(req)
+			require.NoError(t, err)
+			return resp
+		}, nil)
+	cs.network = net
+
+	cs.peerState["a"] = &peerState{number: 100}
+	cs.peerState["b"] = &peerState{number: 100}
+
+	err := cs.syncFinalisedState()
+	require.NoError(t, err)
+
+	ss.AssertNumberOfCalls(t, "StoreTrie", 1)
+	require.Equal(t, header.StateRoot, stored.MustRoot())
+	value, err := stored.Trie().GetFromChild([]byte("pets"), []byte("fox"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("yip"), value)
+	bs.AssertCalled(t, "HandleRuntimeChanges", stored, nil, hash)
+}
+
+func TestChainSync_syncFinalisedState_invalidResponse(t *testing.T) {
+	cs, _ := newTestChainSync(t)
+	_, header, _ := newTestStateService(t)
+
+	bs := new(syncmocks.BlockState)
+	bs.On("GetHighestFinalisedHeader").Return(header, nil)
+	cs.blockState = bs
+
+	ss := new(syncmocks.StorageState)
+	ss.On("TrieState", &header.StateRoot).Return(nil, errors.New("not found"))
+	cs.storageState = ss
+
+	other := trie.NewEmptyTrie()
+	other.Put([]byte("cat"), []byte("purr"))
+	proof, _, _, err := other.GenerateRangeProof(nil, maxStateResponseSize)
+	require.NoError(t, err)
+
+	net := new(syncmocks.Network)
+	net.On("DoStateRequest", peer.ID("a"), mock.AnythingOfType("*network.StateRequest")).
+		Return(&network.StateResponse{Proof: proof}, nil)
+	net.On("ReportPeer", peerset.ReputationChange{
+		Value:  peerset.BadStateResponseValue,
+		Reason: peerset.BadStateResponseReason,
+	}, peer.ID("a"))
+	cs.network = net
+
+	cs.peerState["a"] = &peerState{number: 100}
+
+	err = cs.syncFinalisedState()
+	require.ErrorIs(t, err, errNoPeers)
+	net.AssertNumberOfCalls(t, "ReportPeer", 1)
+	require.True(t, cs.syncPeers.isExcluded("a"))
+	ss.AssertNotCalled(t, "StoreTrie", mock.Anything, mock.Anything)
+}
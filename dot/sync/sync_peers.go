@@ -0,0 +1,199 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// latencySmoothing is the weight of the latest response time in the average latency of a peer
+	latencySmoothing = 0.2
+	// minExclusion is the time a peer is excluded from syncing for after its first failure
+	minExclusion = 10 * time.Second
+	// maxExclusion is the longest time a peer is excluded from syncing for
+	maxExclusion = 5 * time.Minute
+	// topSyncPeers is the number of best peers the requests are spread across
+	topSyncPeers = 3
+)
+
+// peerScore tracks how well a peer served our block requests
+type peerScore struct {
+	requests  uint
+	successes uint
+	// bytesServed is the size of the block bodies and justifications the peer sent us
+	bytesServed uint64
+	// latency is the moving average of the response time of the peer
+	latency time.Duration
+
+	// consecutiveFailures is the number of requests the peer failed since its last success
+	consecutiveFailures uint
+	excludedUntil       time.Time
+}
+
+// successRate returns the ratio of successful requests, starting at 1/2 for unknown peers
+func (ps *peerScore) successRate() float64 {
+	return float64(ps.successes+1) / float64(ps.requests+2)
+}
+
+// value returns the score of the peer, higher is better. A peer which served all our requests
+// is preferred over a faster peer which failed some of them.
+func (ps *peerScore) value() float64 {
+	return ps.successRate() / (ps.latency.Seconds() + 1)
+}
+
+// syncPeers tracks the latency, success rate and bytes served of our sync peers,
+// so we request blocks from the best peers and temporarily exclude the bad ones.
+type syncPeers struct {
+	sync.Mutex
+	scores map[peer.ID]*peerScore
+}
+
+func newSyncPeers() *syncPeers {
+	return &syncPeers{
+		scores: make(map[peer.ID]*peerScore),
+	}
+}
+
+// score returns the score of the peer, creating it if needed. The lock must be held.
+func (sp *syncPeers) score(p peer.ID) *peerScore {
+	ps, has := sp.scores[p]
+	if !has {
+		ps = &peerScore{}
+		sp.scores[p] = ps
+	}
+
+	return ps
+}
+
+// onSuccess records that the peer answered a request with a valid response
+func (sp *syncPeers) onSuccess(p peer.ID, latency time.Duration, bytesServed uint64) {
+	sp.Lock()
+	defer sp.Unlock()
+
+	ps := sp.score(p)
+	if ps.requests == 0 {
+		ps.latency = latency
+	} else {
+		ps.latency = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(ps.latency))
+	}
+
+	ps.requests++
+	ps.successes++
+	ps.bytesServed += bytesServed
+	ps.consecutiveFailures = 0
+	ps.excludedUntil = time.Time{}
+}
+
+// onFailure records that the peer failed a request, and excludes it from syncing for a time
+// which doubles with each consecutive failure.
+func (sp *syncPeers) onFailure(p peer.ID) {
+	sp.Lock()
+	defer sp.Unlock()
+
+	ps := sp.score(p)
+	ps.requests++
+	ps.consecutiveFailures++
+
+	exclusion := maxExclusion
+	if ps.consecutiveFailures < 16 {
+		exclusion = minExclusion << (ps.consecutiveFailures - 1)
+	}
+
+	if exclusion > maxExclusion {
+		exclusion = maxExclusion
+	}
+
+	ps.excludedUntil = time.Now().Add(exclusion)
+}
+
+// exclude excludes the peer from syncing for the maximum exclusion time
+func (sp *syncPeers) exclude(p peer.ID) {
+	sp.Lock()
+	defer sp.Unlock()
+
+	sp.score(p).excludedUntil = time.Now().Add(maxExclusion)
+}
+
+// isExcluded returns whether the peer is currently excluded from syncing
+func (sp *syncPeers) isExcluded(p peer.ID) bool {
+	sp.Lock()
+	defer sp.Unlock()
+
+	ps, has := sp.scores[p]
+	return has && time.Now().Before(ps.excludedUntil)
+}
+
+// clearExclusions allows every peer to be synced from again
+func (sp *syncPeers) clearExclusions() {
+	sp.Lock()
+	defer sp.Unlock()
+
+	for _, ps := range sp.scores {
+		ps.excludedUntil = time.Time{}
+	}
+}
+
+// remove forgets the score of the peer, once it disconnected from us
+func (sp *syncPeers) remove(p peer.ID) {
+	sp.Lock()
+	defer sp.Unlock()
+
+	delete(sp.scores, p)
+}
+
+// best returns one of the topSyncPeers peers with the highest scores, chosen randomly with a
+// probability proportional to its score, so the requests are spread across the best peers.
+// The peers which served the most bytes are preferred when the scores are equal.
+// peers must not be empty.
+func (sp *syncPeers) best(peers []peer.ID) peer.ID {
+	sp.Lock()
+	defer sp.Unlock()
+
+	type candidate struct {
+		who   peer.ID
+		score peerScore
+		value float64
+	}
+
+	candidates := make([]candidate, len(peers))
+	for i, p := range peers {
+		candidates[i].who = p
+		if score, has := sp.scores[p]; has {
+			candidates[i].score = *score
+		}
+		candidates[i].value = candidates[i].score.value()
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].value != candidates[j].value {
+			return candidates[i].value > candidates[j].value
+		}
+		return candidates[i].score.bytesServed > candidates[j].score.bytesServed
+	})
+
+	if len(candidates) > topSyncPeers {
+		candidates = candidates[:topSyncPeers]
+	}
+
+	var total float64
+	for _, c := range candidates {
+		total += c.value
+	}
+
+	r := rand.Float64() * total //nolint:gosec
+	for _, c := range candidates {
+		if r < c.value {
+			return c.who
+		}
+		r -= c.value
+	}
+
+	return candidates[0].who
+}
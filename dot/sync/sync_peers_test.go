@@ -0,0 +1,96 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_syncPeers_best(t *testing.T) {
+	t.Parallel()
+
+	const (
+		fast    = peer.ID("fast")
+		slow    = peer.ID("slow")
+		flaky   = peer.ID("flaky")
+		unknown = peer.ID("unknown")
+	)
+
+	sp := newSyncPeers()
+	for i := 0; i < 10; i++ {
+		sp.onSuccess(fast, 100*time.Millisecond, 1000)
+		sp.onSuccess(slow, 3*time.Second, 1000)
+		sp.onSuccess(flaky, 50*time.Millisecond, 1000)
+		sp.onFailure(flaky)
+	}
+
+	require.Equal(t, flaky, sp.best([]peer.ID{flaky}))
+
+	// the requests are spread across the best peers, and never sent to the worst ones
+	chosen := make(map[peer.ID]int)
+	for i := 0; i < 1000; i++ {
+		chosen[sp.best([]peer.ID{slow, flaky, fast, unknown})]++
+	}
+	require.Len(t, chosen, topSyncPeers)
+	require.Zero(t, chosen[slow])
+	require.Greater(t, chosen[fast], chosen[unknown])
+	require.Greater(t, chosen[unknown], 0)
+	require.Greater(t, chosen[flaky], 0)
+
+	// equal scores prefer the peers which served the most bytes
+	many := []peer.ID{"a", "b", "c", "d"}
+	for i, p := range many {
+		sp.onSuccess(p, 100*time.Millisecond, uint64(i))
+	}
+	for i := 0; i < 100; i++ {
+		require.NotEqual(t, peer.ID("a"), sp.best(many))
+	}
+}
+
+func Test_syncPeers_remove(t *testing.T) {
+	t.Parallel()
+
+	p := peer.ID("a")
+	sp := newSyncPeers()
+	sp.onFailure(p)
+	require.True(t, sp.isExcluded(p))
+
+	sp.remove(p)
+	require.NotContains(t, sp.scores, p)
+	require.False(t, sp.isExcluded(p))
+}
+
+func Test_syncPeers_exclusion(t *testing.T) {
+	t.Parallel()
+
+	p := peer.ID("a")
+	sp := newSyncPeers()
+	require.False(t, sp.isExcluded(p))
+
+	sp.onFailure(p)
+	require.True(t, sp.isExcluded(p))
+	require.WithinDuration(t, time.Now().Add(minExclusion), sp.scores[p].excludedUntil, time.Second)
+
+	// the exclusion doubles with each consecutive failure, up to the maximum
+	sp.onFailure(p)
+	require.WithinDuration(t, time.Now().Add(2*minExclusion), sp.scores[p].excludedUntil, time.Second)
+	for i := 0; i < 100; i++ {
+		sp.onFailure(p)
+	}
+	require.WithinDuration(t, time.Now().Add(maxExclusion), sp.scores[p].excludedUntil, time.Second)
+
+	// a success ends the exclusion
+	sp.onSuccess(p, time.Second, 0)
+	require.False(t, sp.isExcluded(p))
+	require.Equal(t, uint(0), sp.scores[p].consecutiveFailures)
+
+	sp.exclude(p)
+	require.True(t, sp.isExcluded(p))
+	sp.clearExclusions()
+	require.False(t, sp.isExcluded(p))
+}
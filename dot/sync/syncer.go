@@ -19,6 +19,7 @@
 // Service deals with chain syncing by sending block request messages and watching for responses.
 type Service struct {
 	blockState     BlockState
+	storageState   StorageState
 	chainSync      ChainSync
 	chainProcessor ChainProcessor
 	network        Network
@@ -37,6 +38,13 @@ type Config struct {
 	MinPeers, MaxPeers int
 	SlotDuration       time.Duration
 	Telemetry          telemetry.Client
+	// HeadersOnly is set when the node only syncs block headers and justifications,
+	// which is the case for light clients.
+	HeadersOnly bool
+	// WarpSync is set when the node downloads the GRANDPA finality proofs of the authority set
+	// changes to jump to the latest finalised block, and then downloads the state of that block
+	// from its peers before syncing the following blocks.
+	WarpSync bool
 }
 
 // NewService returns a new *sync.Service
@@ -75,22 +83,27 @@ func NewService(cfg *Config) (*Service, error) {
 	pendingBlocks := newDisjointBlockSet(pendingBlocksLimit)
 
 	csCfg := &chainSyncConfig{
-		bs:            cfg.BlockState,
-		net:           cfg.Network,
-		readyBlocks:   readyBlocks,
-		pendingBlocks: pendingBlocks,
-		minPeers:      cfg.MinPeers,
-		maxPeers:      cfg.MaxPeers,
-		slotDuration:  cfg.SlotDuration,
+		bs:             cfg.BlockState,
+		storageState:   cfg.StorageState,
+		net:            cfg.Network,
+		finalityGadget: cfg.FinalityGadget,
+		readyBlocks:    readyBlocks,
+		pendingBlocks:  pendingBlocks,
+		minPeers:       cfg.MinPeers,
+		maxPeers:       cfg.MaxPeers,
+		slotDuration:   cfg.SlotDuration,
+		headersOnly:    cfg.HeadersOnly,
+		warpSync:       cfg.WarpSync,
 	}
 
 	chainSync := newChainSync(csCfg)
 	chainProcessor := newChainProcessor(readyBlocks, pendingBlocks,
 		cfg.BlockState, cfg.StorageState, cfg.TransactionState,
-		cfg.BabeVerifier, cfg.FinalityGadget, cfg.BlockImportHandler, cfg.Telemetry)
+		cfg.BabeVerifier, cfg.FinalityGadget, cfg.BlockImportHandler, cfg.Telemetry, cfg.HeadersOnly)
 
 	return &Service{
 		blockState:     cfg.BlockState,
+		storageState:   cfg.StorageState,
 		chainSync:      chainSync,
 		chainProcessor: chainProcessor,
 		network:        cfg.Network,
@@ -117,6 +130,11 @@ func (s *Service) HandleBlockAnnounceHandshake(from peer.ID, msg *network.BlockA
 	return s.chainSync.setPeerHead(from, msg.BestBlockHash, uint(msg.BestBlockNumber))
 }
 
+// HandlePeerDisconnected notifies the `chainSync` module that the given peer disconnected from us.
+func (s *Service) HandlePeerDisconnected(from peer.ID) {
+	s.chainSync.peerDisconnected(from)
+}
+
 // HandleBlockAnnounce notifies the `chainSync` module that we have received a block announcement from the given peer.
 func (s *Service) HandleBlockAnnounce(from peer.ID, msg *network.BlockAnnounceMessage) error {
 	logger.Debug("received BlockAnnounceMessage")
@@ -21,15 +21,17 @@ type tipSyncer struct {
 	pendingBlocks    DisjointBlockSet
 	readyBlocks      *blockQueue
 	handleReadyBlock handleReadyBlockFunc
+	headersOnly      bool
 }
 
 func newTipSyncer(blockState BlockState, pendingBlocks DisjointBlockSet, readyBlocks *blockQueue,
-	handleReadyBlock handleReadyBlockFunc) *tipSyncer {
+	handleReadyBlock handleReadyBlockFunc, headersOnly bool) *tipSyncer {
 	return &tipSyncer{
 		blockState:       blockState,
 		pendingBlocks:    pendingBlocks,
 		readyBlocks:      readyBlocks,
 		handleReadyBlock: handleReadyBlock,
+		headersOnly:      headersOnly,
 	}
 }
 
@@ -151,7 +153,7 @@ func (s *tipSyncer) handleTick() ([]*worker, error) {
 
 	// cases for each block in pending set:
 	// 1. only hash and number are known; in this case, request the full block (and ancestor chain)
-	// 2. only header is known; in this case, request the block body
+	// 2. only header is known; in this case, request the block body, unless we only sync headers
 	// 3. entire block is known; in this case, check if we have become aware of the parent
 	// if we have, move it to the ready blocks queue; otherwise, request the chain of ancestors
 
@@ -180,7 +182,7 @@ func (s *tipSyncer) handleTick() ([]*worker, error) {
 			continue
 		}
 
-		if block.body == nil {
+		if block.body == nil && !s.headersOnly {
 			// case 2
 			workers = append(workers, &worker{
 				startHash:    block.hash,
@@ -0,0 +1,131 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// warpSyncToFinalised requests the proofs of the GRANDPA authority set changes from our peers,
+// starting at our highest finalised block, until the proof received is finished. The block finalised
+// by the last proof becomes our finalised and best block, so that full sync starts from it.
+func (cs *chainSync) warpSyncToFinalised() error {
+	peersTried := make(map[peer.ID]struct{})
+	for {
+		finalised, err := cs.blockState.GetHighestFinalisedHeader()
+		if err != nil {
+			return fmt.Errorf("cannot get highest finalised header: %w", err)
+		}
+
+		peers := cs.determineUntriedPeers(peersTried)
+		if len(peers) == 0 {
+			return errNoPeers
+		}
+
+		who := cs.syncPeers.best(peers)
+		req := &network.WarpSyncRequest{
+			Begin: finalised.Hash(),
+		}
+
+		logger.Debugf("sending warp sync request to peer %s: %s", who, req)
+
+		start := time.Now()
+		proof, err := cs.network.DoWarpSyncRequest(who, req)
+		if err != nil {
+			logger.Debugf("failed to get warp sync proof from peer %s: %s", who, err)
+			cs.syncPeers.onFailure(who)
+			peersTried[who] = struct{}{}
+			continue
+		}
+
+		latency := time.Since(start)
+
+		if len(proof.Fragments) == 0 {
+			if proof.IsFinished {
+				// the peer doesn't know of any block finalised after ours
+				cs.syncPeers.onSuccess(who, latency, 0)
+				return nil
+			}
+
+			cs.handleInvalidWarpSyncProof(who, errEmptyUnfinishedWarpSyncProof)
+			peersTried[who] = struct{}{}
+			continue
+		}
+
+		header, round, setID, changes, err := cs.finalityGadget.VerifyWarpSyncProof(finalised.Number, proof)
+		if err != nil {
+			cs.handleInvalidWarpSyncProof(who, err)
+			peersTried[who] = struct{}{}
+			continue
+		}
+
+		// the authority set changes are recorded after the finalised block is set,
+		// so that the set ID is never ahead of the finalised block
+		err = cs.blockState.SetWarpSyncedHeader(header, round, setID)
+		if err != nil {
+			return fmt.Errorf("cannot set warp synced header: %w", err)
+		}
+
+		err = cs.finalityGadget.ApplyAuthoritySetChanges(changes)
+		if err != nil {
+			return fmt.Errorf("cannot apply authority set changes: %w", err)
+		}
+
+		last := proof.Fragments[len(proof.Fragments)-1]
+		err = cs.blockState.SetJustification(header.Hash(), last.Justification)
+		if err != nil {
+			return fmt.Errorf("cannot set justification of warp synced header: %w", err)
+		}
+
+		var bytesServed uint64
+		for _, fragment := range proof.Fragments {
+			bytesServed += uint64(len(fragment.Justification))
+		}
+		cs.syncPeers.onSuccess(who, latency, bytesServed)
+
+		logger.Infof("warp synced to block number %d with hash %s", header.Number, header.Hash())
+
+		if proof.IsFinished {
+			return nil
+		}
+	}
+}
+
+// determineUntriedPeers returns the peers which weren't tried yet and which aren't excluded from syncing
+func (cs *chainSync) determineUntriedPeers(peersTried map[peer.ID]struct{}) []peer.ID {
+	cs.RLock()
+	defer cs.RUnlock()
+
+	peers := make([]peer.ID, 0, len(cs.peerState))
+	for p := range cs.peerState {
+		if _, has := peersTried[p]; has {
+			continue
+		}
+
+		if cs.syncPeers.isExcluded(p) {
+			continue
+		}
+
+		peers = append(peers, p)
+	}
+
+	return peers
+}
+
+// handleInvalidWarpSyncProof lowers the reputation of a peer which sent a proof we cannot verify
+func (cs *chainSync) handleInvalidWarpSyncProof(who peer.ID, err error) {
+	logger.Debugf("invalid warp sync proof from peer %s: %s", who, err)
+
+	cs.network.ReportPeer(peerset.ReputationChange{
+		Value:  peerset.BadWarpSyncProofValue,
+		Reason: peerset.BadWarpSyncProofReason,
+	}, who)
+	cs.syncPeers.onFailure(who)
+}
@@ -0,0 +1,106 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sync
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/peerset"
+	syncmocks "github.com/ChainSafe/gossamer/dot/sync/mocks"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/trie"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainSync_warpSyncToFinalised(t *testing.T) {
+	cs, _ := newTestChainSync(t)
+
+	genesis, err := types.NewHeader(common.Hash{}, trie.EmptyHash, trie.EmptyHash, 0, types.NewDigest())
+	require.NoError(t, err)
+	target, err := types.NewHeader(genesis.Hash(), trie.EmptyHash, trie.EmptyHash, 100, types.NewDigest())
+	require.NoError(t, err)
+
+	bs := new(syncmocks.BlockState)
+	bs.On("GetHighestFinalisedHeader").Return(genesis, nil)
+	bs.On("SetWarpSyncedHeader", target, uint64(3), uint64(2)).Return(nil)
+	bs.On("SetJustification", target.Hash(), []byte{1}).Return(nil)
+	cs.blockState = bs
+
+	proof := &network.WarpSyncProof{
+		Fragments: []network.WarpSyncFragment{
+			{Header: *target, Justification: []byte{1}},
+		},
+		IsFinished: true,
+	}
+
+	net := new(syncmocks.Network)
+	net.On("DoWarpSyncRequest", peer.ID("a"), mock.AnythingOfType("*network.WarpSyncRequest")).
+		Return(nil, errors.New("timeout"))
+	net.On("DoWarpSyncRequest", peer.ID("b"), &network.WarpSyncRequest{Begin: genesis.Hash()}).
+		Return(proof, nil)
+	cs.network = net
+
+	changes := []types.GrandpaAuthoritySetChange{{Number: 50}}
+	fg := new(syncmocks.FinalityGadget)
+	fg.On("VerifyWarpSyncProof", uint(0), proof).Return(target, uint64(3), uint64(2), changes, nil)
+	// the authority set changes must be recorded after the finalised block is set
+	fg.On("ApplyAuthoritySetChanges", changes).Run(func(mock.Arguments) {
+		bs.AssertCalled(t, "SetWarpSyncedHeader", target, uint64(3), uint64(2))
+	}).Return(nil)
+	cs.finalityGadget = fg
+
+	cs.peerState["a"] = &peerState{number: 100}
+	cs.peerState["b"] = &peerState{number: 100}
+
+	err = cs.warpSyncToFinalised()
+	require.NoError(t, err)
+	bs.AssertCalled(t, "SetWarpSyncedHeader", target, uint64(3), uint64(2))
+	bs.AssertCalled(t, "SetJustification", target.Hash(), []byte{1})
+	fg.AssertCalled(t, "ApplyAuthoritySetChanges", changes)
+	require.False(t, cs.syncPeers.isExcluded("b"))
+}
+
+func TestChainSync_warpSyncToFinalised_invalidProof(t *testing.T) {
+	cs, _ := newTestChainSync(t)
+
+	genesis, err := types.NewHeader(common.Hash{}, trie.EmptyHash, trie.EmptyHash, 0, types.NewDigest())
+	require.NoError(t, err)
+
+	bs := new(syncmocks.BlockState)
+	bs.On("GetHighestFinalisedHeader").Return(genesis, nil)
+	cs.blockState = bs
+
+	proof := &network.WarpSyncProof{
+		Fragments: []network.WarpSyncFragment{
+			{Header: *genesis},
+		},
+	}
+
+	net := new(syncmocks.Network)
+	net.On("DoWarpSyncRequest", peer.ID("a"), mock.AnythingOfType("*network.WarpSyncRequest")).
+		Return(proof, nil)
+	net.On("ReportPeer", peerset.ReputationChange{
+		Value:  peerset.BadWarpSyncProofValue,
+		Reason: peerset.BadWarpSyncProofReason,
+	}, peer.ID("a"))
+	cs.network = net
+
+	fg := new(syncmocks.FinalityGadget)
+	fg.On("VerifyWarpSyncProof", uint(0), proof).Return(nil, uint64(0), uint64(0), nil, errors.New("invalid"))
+	cs.finalityGadget = fg
+
+	cs.peerState["a"] = &peerState{number: 100}
+
+	err = cs.warpSyncToFinalised()
+	require.ErrorIs(t, err, errNoPeers)
+	net.AssertNumberOfCalls(t, "ReportPeer", 1)
+	require.True(t, cs.syncPeers.isExcluded("a"))
+	fg.AssertNotCalled(t, "ApplyAuthoritySetChanges", mock.Anything)
+}
@@ -0,0 +1,44 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package dot
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+)
+
+// TraceBlock re-executes the block with the given hash on the state of its parent, stored in the node
+// databases of the configuration, and returns the trace of its execution. The runtime code is loaded
+// from the state of the parent block.
+func TraceBlock(cfg *Config, hash common.Hash) (*runtime.BlockTrace, error) {
+	executor, err := newBlockExecutor(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer executor.stop()
+
+	block, err := executor.stateSrvc.Block.GetBlockByHash(hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get block %s: %w", hash, err)
+	}
+
+	ts, err := executor.parentState(block)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := executor.runtime(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	trace, err := runtime.TraceBlock(rt, ts, block)
+	if err != nil {
+		return nil, fmt.Errorf("cannot execute block %s: %w", hash, err)
+	}
+
+	return trace, nil
+}
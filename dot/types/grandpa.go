@@ -99,15 +99,16 @@ func NewGrandpaVotersFromAuthorities(ad []Authority) []GrandpaVoter {
 func NewGrandpaVotersFromAuthoritiesRaw(ad []GrandpaAuthoritiesRaw) ([]GrandpaVoter, error) {
 	v := make([]GrandpaVoter, len(ad))
 
-	for i, d := range ad {
-		key, err := ed25519.NewPublicKey(d.Key[:])
+	for i := range ad {
+		// the public key references the bytes of its input, which must not be the loop variable
+		key, err := ed25519.NewPublicKey(ad[i].Key[:])
 		if err != nil {
 			return nil, err
 		}
 
 		v[i] = GrandpaVoter{
 			Key: *key,
-			ID:  d.ID,
+			ID:  ad[i].ID,
 		}
 	}
 
@@ -161,6 +162,12 @@ func DecodeGrandpaVoters(in []byte) (GrandpaVoters, error) {
 	return gv, nil
 }
 
+// GrandpaAuthoritySetChange is a change of the GRANDPA authority set enacted by the block with the given number
+type GrandpaAuthoritySetChange struct {
+	Voters []GrandpaVoter
+	Number uint
+}
+
 // FinalisationInfo represents information about what block was finalised in what round and setID
 type FinalisationInfo struct {
 	Header Header
@@ -5,8 +5,8 @@
 
 import (
 	"errors"
+	"runtime"
 	"sync"
-	"time"
 
 	"github.com/ChainSafe/gossamer/internal/log"
 )
@@ -24,16 +24,17 @@ type SignatureInfo struct {
 	VerifyFunc SigVerifyFunc
 }
 
-// SignatureVerifier ...
+// SignatureVerifier verifies the signatures of a batch in the background, with a pool of workers.
 type SignatureVerifier struct {
-	batch   []*SignatureInfo
 	init    bool // Indicates whether the batch processing is started.
 	invalid bool // Set to true if any signature verification fails.
+	// pending holds the signatures added before the batch processing is started.
+	pending []*SignatureInfo
+	jobs    chan *SignatureInfo
+	workers int
 	logger  log.LeveledLogger
-	closeCh chan struct{}
 	sync.RWMutex
-	sync.Once
-	sync.WaitGroup
+	wg sync.WaitGroup
 }
 
 // NewSignatureVerifier initialises SignatureVerifier which does background verification of signatures.
@@ -42,44 +43,55 @@ type SignatureVerifier struct {
 // Signatures can be added to the batch using Add().
 func NewSignatureVerifier(logger log.LeveledLogger) *SignatureVerifier {
 	return &SignatureVerifier{
-		batch:   make([]*SignatureInfo, 0),
-		init:    false,
-		invalid: false,
+		workers: runtime.NumCPU(),
 		logger:  logger,
-		RWMutex: sync.RWMutex{},
-		closeCh: make(chan struct{}),
 	}
 }
 
-// Start signature verification in batch.
+// Start signature verification in batch, the signatures added to the batch are verified in parallel
+// by the workers until Finish is called.
 func (sv *SignatureVerifier) Start() {
-	// Update the init state.
 	sv.Lock()
+	if sv.init {
+		sv.Unlock()
+		sv.logger.Warn("signature verification batch is already started")
+		return
+	}
+
 	sv.init = true
+	sv.jobs = make(chan *SignatureInfo, 4*sv.workers)
+
+	sv.wg.Add(sv.workers)
+	for i := 0; i < sv.workers; i++ {
+		go sv.work(sv.jobs)
+	}
+
+	jobs, pending := sv.jobs, sv.pending
+	sv.pending = nil
 	sv.Unlock()
 
-	sv.WaitGroup.Add(1)
-
-	go func() {
-		defer sv.Done()
-		for {
-			select {
-			case <-sv.closeCh:
-				return
-			default:
-				signature := sv.Remove()
-				if signature == nil {
-					continue
-				}
-				err := signature.VerifyFunc(signature.PubKey, signature.Sign, signature.Msg)
-				if err != nil {
-					sv.logger.Errorf("[ext_crypto_start_batch_verify_version_1]: %s", err)
-					sv.Invalid()
-					return
-				}
-			}
+	// the lock is not held while sending, as the workers need it
+	for _, signature := range pending {
+		jobs <- signature
+	}
+}
+
+// work verifies the signatures of the batch until the jobs channel is closed.
+func (sv *SignatureVerifier) work(jobs <-chan *SignatureInfo) {
+	defer sv.wg.Done()
+
+	for signature := range jobs {
+		// the remaining signatures are drained without verifying them once the batch is invalid
+		if sv.IsInvalid() {
+			continue
+		}
+
+		err := signature.VerifyFunc(signature.PubKey, signature.Sign, signature.Msg)
+		if err != nil {
+			sv.logger.Debugf("failed to verify signature in batch: %s", err)
+			sv.setInvalid()
 		}
-	}()
+	}
 }
 
 // IsStarted ...
@@ -96,34 +108,36 @@ func (sv *SignatureVerifier) IsInvalid() bool {
 	return sv.invalid
 }
 
-// Invalid ...
-func (sv *SignatureVerifier) Invalid() {
-	sv.RLock()
-	defer sv.RUnlock()
+func (sv *SignatureVerifier) setInvalid() {
+	sv.Lock()
+	defer sv.Unlock()
 	sv.invalid = true
 }
 
-// Add ...
+// Add adds the signature to the batch. The public key, signature and message are copied,
+// since they may point to the memory of a runtime instance. It must not be called concurrently with Finish.
 func (sv *SignatureVerifier) Add(s *SignatureInfo) {
 	if sv.IsInvalid() {
 		return
 	}
 
-	sv.Lock()
-	defer sv.Unlock()
-	sv.batch = append(sv.batch, s)
-}
+	signature := &SignatureInfo{
+		PubKey:     append([]byte{}, s.PubKey...),
+		Sign:       append([]byte{}, s.Sign...),
+		Msg:        append([]byte{}, s.Msg...),
+		VerifyFunc: s.VerifyFunc,
+	}
 
-// Remove returns the first signature from the batch. Returns nil if batch is empty.
-func (sv *SignatureVerifier) Remove() *SignatureInfo {
 	sv.Lock()
-	defer sv.Unlock()
-	if len(sv.batch) == 0 {
-		return nil
+	if !sv.init {
+		sv.pending = append(sv.pending, signature)
+		sv.Unlock()
+		return
 	}
-	sign := sv.batch[0]
-	sv.batch = sv.batch[1:len(sv.batch)]
-	return sign
+	jobs := sv.jobs
+	sv.Unlock()
+
+	jobs <- signature
 }
 
 // Reset reset the signature verifier for reuse.
@@ -131,25 +145,25 @@ func (sv *SignatureVerifier) Reset() {
 	sv.Lock()
 	defer sv.Unlock()
 	sv.init = false
-	sv.batch = make([]*SignatureInfo, 0)
+	sv.pending = nil
+	sv.jobs = nil
 	sv.invalid = false
-	sv.closeCh = make(chan struct{})
 }
 
 // Finish waits till batch is finished. Returns true if all the signatures are valid, Otherwise returns false.
 func (sv *SignatureVerifier) Finish() bool {
-	for {
-		time.Sleep(100 * time.Millisecond)
-		sv.Lock()
-		if sv.invalid || len(sv.batch) == 0 {
-			close(sv.closeCh)
-			sv.Unlock()
-			break
-		}
-		sv.RUnlock()
+	sv.Lock()
+	if !sv.init {
+		sv.Unlock()
+		sv.logger.Warn("signature verification batch is not started")
+		sv.Reset()
+		return false
 	}
-	// Wait till start function to finish and then reset it.
-	sv.Wait()
+	close(sv.jobs)
+	sv.Unlock()
+
+	// Wait till the workers have verified all the signatures and then reset it.
+	sv.wg.Wait()
 	isInvalid := sv.IsInvalid()
 	sv.Reset()
 	return !isInvalid
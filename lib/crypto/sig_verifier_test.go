@@ -124,3 +124,41 @@ func TestVerifySignature(t *testing.T) {
 	}
 
 }
+
+func TestSignatureVerifier_LargeBatch(t *testing.T) {
+	t.Parallel()
+
+	keypair, err := ed25519.GenerateKeypair()
+	require.NoError(t, err)
+
+	signVerify := crypto.NewSignatureVerifier(log.New(log.SetWriter(io.Discard)))
+
+	for _, valid := range []bool{true, false} {
+		require.False(t, signVerify.IsStarted())
+		signVerify.Start()
+
+		for i := 0; i < 256; i++ {
+			message := []byte{byte(i)}
+			sig, err := keypair.Sign(message)
+			require.NoError(t, err)
+
+			if !valid && i == 200 {
+				message = []byte("other")
+			}
+
+			// the message is overwritten after being added, as the memory of a runtime would be
+			signVerify.Add(&crypto.SignatureInfo{
+				PubKey:     keypair.Public().Encode(),
+				Sign:       sig,
+				Msg:        message,
+				VerifyFunc: ed25519.VerifySignature,
+			})
+			message[0]++
+		}
+
+		require.Equal(t, valid, signVerify.Finish())
+	}
+
+	// the batch is not started
+	require.False(t, signVerify.Finish())
+}
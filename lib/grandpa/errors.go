@@ -96,4 +96,11 @@ func errRoundMismatch(got, want uint64) error {
 	errVoteExists              = errors.New("already have vote")
 	errVoteToSignatureMismatch = errors.New("votes and authority count mismatch")
 	errInvalidVoteBlock        = errors.New("block in vote is not descendant of previously finalised block")
+
+	errWarpSyncBeginNotFinalised     = errors.New("block to warp sync from is not finalised")
+	errEmptyWarpSyncProof            = errors.New("warp sync proof has no fragment")
+	errWarpSyncFragmentWithoutChange = errors.New("warp sync fragment does not change the authority set")
+	errWarpSyncFragmentNotIncreasing = errors.New("warp sync fragment is not above the previous block")
+	errWarpSyncChangeWithDelay       = errors.New("authority set change is not enacted by the warp sync fragment")
+	errUnusedVotesAncestry           = errors.New("votes ancestry header is not an ancestor of any precommit target")
 )
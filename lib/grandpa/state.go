@@ -37,6 +37,7 @@ type BlockState interface {
 	GetHashByNumber(num uint) (common.Hash, error)
 	BestBlockNumber() (blockNumber uint, err error)
 	GetHighestRoundAndSetID() (uint64, uint64, error)
+	GetHighestFinalisedHeader() (*types.Header, error)
 }
 
 // GrandpaState is the interface required by grandpa into the grandpa state
@@ -44,6 +45,9 @@ type GrandpaState interface {
 	GetCurrentSetID() (uint64, error)
 	GetAuthorities(setID uint64) ([]types.GrandpaVoter, error)
 	GetSetIDByBlockNumber(num uint) (uint64, error)
+	GetSetIDChange(setID uint64) (blockNumber uint, err error)
+	SetNextChange(authorities []types.GrandpaVoter, number uint) error
+	IncrementSetID() error
 	SetLatestRound(round uint64) error
 	GetLatestRound() (uint64, error)
 	SetPrevotes(round, setID uint64, data []SignedVote) error
@@ -0,0 +1,430 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package grandpa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// warpSyncProofOverhead is the size of the encoded proof which isn't part of its fragments,
+// ie. the length of the fragments, the IsFinished flag and the length of the message
+const warpSyncProofOverhead = 16
+
+// CreateWarpSyncProof returns the proof of the authority set changes which happened after the
+// given finalised block, followed by the justification of the highest finalised block.
+// Each fragment of the proof contains the header enacting an authority set change,
+// along with the justification of the previous authority set finalising it.
+func (s *Service) CreateWarpSyncProof(begin common.Hash) (*network.WarpSyncProof, error) {
+	beginHeader, err := s.blockState.GetHeader(begin)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get header of block to warp sync from: %w", err)
+	}
+
+	finalised, err := s.blockState.GetHighestFinalisedHeader()
+	if err != nil {
+		return nil, fmt.Errorf("cannot get highest finalised header: %w", err)
+	}
+
+	if beginHeader.Number > finalised.Number {
+		return nil, fmt.Errorf("%w: %s", errWarpSyncBeginNotFinalised, begin)
+	}
+
+	canonical, err := s.blockState.GetHashByNumber(beginHeader.Number)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get hash of block number %d: %w", beginHeader.Number, err)
+	}
+
+	if !canonical.Equal(begin) {
+		return nil, fmt.Errorf("%w: %s", errWarpSyncBeginNotFinalised, begin)
+	}
+
+	setID, err := s.grandpaState.GetSetIDByBlockNumber(beginHeader.Number)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get set ID of block number %d: %w", beginHeader.Number, err)
+	}
+
+	proof := &network.WarpSyncProof{}
+	size := warpSyncProofOverhead
+	lastNumber := beginHeader.Number
+	for {
+		changeNumber, err := s.grandpaState.GetSetIDChange(setID + 1)
+		if errors.Is(err, chaindb.ErrKeyNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot get block number of set ID %d change: %w", setID+1, err)
+		}
+
+		setID++
+
+		if changeNumber <= beginHeader.Number {
+			continue
+		}
+
+		// the change is not enacted until its block is finalised
+		if changeNumber > finalised.Number {
+			break
+		}
+
+		fragment, fragmentSize, err := s.warpSyncFragment(changeNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		if size+fragmentSize > int(network.MaxWarpSyncProofSize) {
+			return proof, nil
+		}
+
+		proof.Fragments = append(proof.Fragments, *fragment)
+		size += fragmentSize
+		lastNumber = changeNumber
+	}
+
+	if finalised.Number > lastNumber {
+		fragment, fragmentSize, err := s.warpSyncFragment(finalised.Number)
+		if err != nil {
+			return nil, err
+		}
+
+		if size+fragmentSize > int(network.MaxWarpSyncProofSize) {
+			return proof, nil
+		}
+
+		proof.Fragments = append(proof.Fragments, *fragment)
+	}
+
+	proof.IsFinished = true
+	return proof, nil
+}
+
+// warpSyncFragment returns the warp sync fragment of the finalised block with the given number,
+// along with its encoded size
+func (s *Service) warpSyncFragment(number uint) (fragment *network.WarpSyncFragment, size int, err error) {
+	header, err := s.blockState.GetHeaderByNumber(number)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot get header of block number %d: %w", number, err)
+	}
+
+	justification, err := s.blockState.GetJustification(header.Hash())
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot get justification of block number %d: %w", number, err)
+	}
+
+	justification, err = s.withVotesAncestries(justification)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot decode justification of block number %d: %w", number, err)
+	}
+
+	fragment = &network.WarpSyncFragment{
+		Header:        *header,
+		Justification: justification,
+	}
+
+	enc, err := fragment.Encode()
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot encode fragment of block number %d: %w", number, err)
+	}
+
+	return fragment, len(enc), nil
+}
+
+// withVotesAncestries returns the encoded justification along with the ancestries of its votes,
+// which warp sync fragments contain. They are the headers of the blocks from the targets of the
+// precommits down to the target of the commit, which is excluded.
+// Any ancestries the stored justification already contains are replaced.
+func (s *Service) withVotesAncestries(justification []byte) ([]byte, error) {
+	j := Justification{}
+	err := scale.Unmarshal(justification, &j)
+	if err != nil {
+		return nil, err
+	}
+
+	var ancestries []types.Header
+	seen := make(map[common.Hash]struct{})
+	for _, pc := range j.Commit.Precommits {
+		hash := pc.Vote.Hash
+		for !hash.Equal(j.Commit.Hash) {
+			if _, has := seen[hash]; has {
+				break
+			}
+
+			header, err := s.blockState.GetHeader(hash)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get header of precommit ancestor %s: %w", hash, err)
+			}
+
+			if header.Number <= uint(j.Commit.Number) {
+				return nil, fmt.Errorf("%w: precommit for block %s", ErrPrecommitBlockMismatch, pc.Vote.Hash)
+			}
+
+			seen[hash] = struct{}{}
+			ancestries = append(ancestries, *header)
+			hash = header.ParentHash
+		}
+	}
+
+	enc, err := scale.Marshal(j)
+	if err != nil {
+		return nil, err
+	}
+
+	encAncestries, err := scale.Marshal(ancestries)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(enc, encAncestries...), nil
+}
+
+// decodeWarpSyncJustification decodes the justification of a warp sync fragment, along with
+// the ancestries of its votes mapped by hash
+func decodeWarpSyncJustification(enc []byte) (*Justification, map[common.Hash]*types.Header, error) {
+	reader := bytes.NewReader(enc)
+	decoder := scale.NewDecoder(reader)
+
+	justification := &Justification{}
+	err := decoder.Decode(justification)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var length uint
+	err = decoder.Decode(&length)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decode votes ancestries: %w", err)
+	}
+
+	ancestries := make(map[common.Hash]*types.Header)
+	for i := uint(0); i < length; i++ {
+		header := types.NewEmptyHeader()
+		err = decoder.Decode(header)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot decode votes ancestry %d: %w", i, err)
+		}
+
+		ancestries[header.Hash()] = header
+	}
+
+	return justification, ancestries, nil
+}
+
+// VerifyWarpSyncProof verifies the fragments of the proof against the current authority set. The fragments
+// must be for blocks with increasing numbers above the given number of the block the proof starts from.
+// It returns the header of the last fragment, along with the round and set ID of its justification, and
+// the authority set changes enacted by the fragments, which are recorded by ApplyAuthoritySetChanges.
+func (s *Service) VerifyWarpSyncProof(begin uint, proof *network.WarpSyncProof) (header *types.Header,
+	round, setID uint64, changes []types.GrandpaAuthoritySetChange, err error) {
+	if len(proof.Fragments) == 0 {
+		return nil, 0, 0, nil, errEmptyWarpSyncProof
+	}
+
+	setID, err = s.grandpaState.GetCurrentSetID()
+	if err != nil {
+		return nil, 0, 0, nil, fmt.Errorf("cannot get current set ID: %w", err)
+	}
+
+	authorities, err := s.grandpaState.GetAuthorities(setID)
+	if err != nil {
+		return nil, 0, 0, nil, fmt.Errorf("cannot get authorities of set ID %d: %w", setID, err)
+	}
+
+	lastNumber := begin
+	for i := range proof.Fragments {
+		fragment := &proof.Fragments[i]
+		if fragment.Header.Number <= lastNumber {
+			return nil, 0, 0, nil, fmt.Errorf("%w: block number %d is not above block number %d",
+				errWarpSyncFragmentNotIncreasing, fragment.Header.Number, lastNumber)
+		}
+		lastNumber = fragment.Header.Number
+
+		round, err = verifyWarpSyncJustification(fragment, setID, authorities)
+		if err != nil {
+			return nil, 0, 0, nil, fmt.Errorf("invalid justification of block number %d: %w",
+				fragment.Header.Number, err)
+		}
+
+		next, err := authoritySetChange(&fragment.Header)
+		if err != nil {
+			return nil, 0, 0, nil, fmt.Errorf("invalid authority set change in block number %d: %w",
+				fragment.Header.Number, err)
+		}
+
+		isLast := i == len(proof.Fragments)-1
+		if next == nil && !isLast {
+			return nil, 0, 0, nil, fmt.Errorf("%w: block number %d",
+				errWarpSyncFragmentWithoutChange, fragment.Header.Number)
+		}
+
+		if next == nil {
+			continue
+		}
+
+		changes = append(changes, types.GrandpaAuthoritySetChange{
+			Voters: next,
+			Number: fragment.Header.Number,
+		})
+		if !isLast {
+			setID++
+			authorities = next
+		}
+	}
+
+	return &proof.Fragments[len(proof.Fragments)-1].Header, round, setID, changes, nil
+}
+
+// ApplyAuthoritySetChanges records the authority set changes of a verified warp sync proof,
+// incrementing the set ID for each of them
+func (s *Service) ApplyAuthoritySetChanges(changes []types.GrandpaAuthoritySetChange) error {
+	for _, change := range changes {
+		err := s.grandpaState.SetNextChange(change.Voters, change.Number)
+		if err != nil {
+			return fmt.Errorf("cannot set authority set change: %w", err)
+		}
+
+		err = s.grandpaState.IncrementSetID()
+		if err != nil {
+			return fmt.Errorf("cannot increment set ID: %w", err)
+		}
+
+		logger.Debugf("warp synced to authority set change at block number %d", change.Number)
+	}
+
+	return nil
+}
+
+// verifyWarpSyncJustification verifies that the justification of the fragment finalises its header,
+// and is signed by more than two thirds of the authorities. It returns the round of the justification.
+// The precommits must be for the finalised block or for its descendants, in which case the ancestries
+// of the justification must link their targets to the finalised block. Every ancestry must be used.
+func verifyWarpSyncJustification(fragment *network.WarpSyncFragment, setID uint64,
+	authorities []types.GrandpaVoter) (round uint64, err error) {
+	justification, ancestries, err := decodeWarpSyncJustification(fragment.Justification)
+	if err != nil {
+		return 0, err
+	}
+
+	hash := fragment.Header.Hash()
+	if !justification.Commit.Hash.Equal(hash) || uint(justification.Commit.Number) != fragment.Header.Number {
+		return 0, ErrPrecommitBlockMismatch
+	}
+
+	used := make(map[common.Hash]struct{}, len(ancestries))
+	signers := make(map[ed25519.PublicKeyBytes]struct{}, len(justification.Commit.Precommits))
+	for _, pc := range justification.Commit.Precommits {
+		if !isVoteDescendant(pc.Vote, &fragment.Header, ancestries, used) {
+			return 0, ErrPrecommitBlockMismatch
+		}
+
+		if _, has := signers[pc.AuthorityID]; has {
+			continue
+		}
+
+		pk, err := ed25519.NewPublicKey(pc.AuthorityID[:])
+		if err != nil {
+			return 0, err
+		}
+
+		if !isInAuthSet(pk, authorities) {
+			return 0, ErrAuthorityNotInSet
+		}
+
+		msg, err := scale.Marshal(FullVote{
+			Stage: precommit,
+			Vote:  pc.Vote,
+			Round: justification.Round,
+			SetID: setID,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		ok, err := pk.Verify(msg, pc.Signature[:])
+		if err != nil {
+			return 0, err
+		}
+
+		if !ok {
+			return 0, ErrInvalidSignature
+		}
+
+		signers[pc.AuthorityID] = struct{}{}
+	}
+
+	if len(used) != len(ancestries) {
+		return 0, errUnusedVotesAncestry
+	}
+
+	if len(signers) <= 2*len(authorities)/3 {
+		return 0, ErrMinVotesNotMet
+	}
+
+	return justification.Round, nil
+}
+
+// isVoteDescendant returns whether the vote is for the block of the header or for one of its descendants,
+// following the parents of the vote target through the ancestries. The ancestries followed are added to used.
+func isVoteDescendant(vote Vote, header *types.Header, ancestries map[common.Hash]*types.Header,
+	used map[common.Hash]struct{}) bool {
+	base := header.Hash()
+	hash, number := vote.Hash, uint(vote.Number)
+	for !hash.Equal(base) {
+		ancestor, has := ancestries[hash]
+		if !has || ancestor.Number != number || number <= header.Number {
+			return false
+		}
+
+		used[hash] = struct{}{}
+		hash, number = ancestor.ParentHash, number-1
+	}
+
+	return number == header.Number
+}
+
+// authoritySetChange returns the authorities of the authority set change enacted by the header,
+// or nil if it doesn't enact any. Only the changes without delay are enacted by the header signaling them.
+func authoritySetChange(header *types.Header) ([]types.GrandpaVoter, error) {
+	for _, d := range header.Digest.Types {
+		consensus, ok := d.Value().(types.ConsensusDigest)
+		if !ok || consensus.ConsensusEngineID != types.GrandpaEngineID {
+			continue
+		}
+
+		data := types.NewGrandpaConsensusDigest()
+		err := scale.Unmarshal(consensus.Data, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			auths []types.GrandpaAuthoritiesRaw
+			delay uint32
+		)
+
+		switch change := data.Value().(type) {
+		case types.GrandpaScheduledChange:
+			auths, delay = change.Auths, change.Delay
+		case types.GrandpaForcedChange:
+			auths, delay = change.Auths, change.Delay
+		default:
+			continue
+		}
+
+		if delay != 0 {
+			return nil, fmt.Errorf("%w: delay of %d blocks", errWarpSyncChangeWithDelay, delay)
+		}
+
+		return types.NewGrandpaVotersFromAuthoritiesRaw(auths)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,341 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package grandpa
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/chaindb"
+	"github.com/ChainSafe/gossamer/dot/network"
+	"github.com/ChainSafe/gossamer/dot/state"
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/crypto/ed25519"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/lib/utils"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newWarpSyncTestHeader(t *testing.T, number uint, change *types.GrandpaScheduledChange) *types.Header {
+	return newWarpSyncTestChild(t, testGenesisHeader, number, change)
+}
+
+// newWarpSyncTestChild returns a header with the given number and parent
+func newWarpSyncTestChild(t *testing.T, parent *types.Header, number uint,
+	change *types.GrandpaScheduledChange) *types.Header {
+	digest := types.NewDigest()
+	if change != nil {
+		grandpaDigest := types.NewGrandpaConsensusDigest()
+		err := grandpaDigest.Set(*change)
+		require.NoError(t, err)
+
+		data, err := scale.Marshal(grandpaDigest)
+		require.NoError(t, err)
+
+		err = digest.Add(types.ConsensusDigest{
+			ConsensusEngineID: types.GrandpaEngineID,
+			Data:              data,
+		})
+		require.NoError(t, err)
+	}
+
+	header, err := types.NewHeader(parent.Hash(), trie.EmptyHash, trie.EmptyHash, number, digest)
+	require.NoError(t, err)
+	return header
+}
+
+// newWarpSyncTestJustification returns the encoded justification of the header, signed by the keys
+// for the target block, along with the given votes ancestries
+func newWarpSyncTestJustification(t *testing.T, header, target *types.Header, ancestries []types.Header,
+	round, setID uint64, keys []*ed25519.Keypair) []byte {
+	vote := *NewVote(target.Hash(), uint32(target.Number))
+
+	precommits := make([]SignedVote, len(keys))
+	for i, key := range keys {
+		msg, err := scale.Marshal(FullVote{
+			Stage: precommit,
+			Vote:  vote,
+			Round: round,
+			SetID: setID,
+		})
+		require.NoError(t, err)
+
+		sig, err := key.Sign(msg)
+		require.NoError(t, err)
+
+		precommits[i] = SignedVote{
+			Vote:        vote,
+			AuthorityID: key.Public().(*ed25519.PublicKey).AsBytes(),
+		}
+		copy(precommits[i].Signature[:], sig)
+	}
+
+	justification, err := scale.Marshal(*newJustification(round, header.Hash(), uint32(header.Number), precommits))
+	require.NoError(t, err)
+
+	encAncestries, err := scale.Marshal(ancestries)
+	require.NoError(t, err)
+
+	return append(justification, encAncestries...)
+}
+
+func newWarpSyncTestFragment(t *testing.T, header *types.Header, round, setID uint64,
+	keys []*ed25519.Keypair) network.WarpSyncFragment {
+	return network.WarpSyncFragment{
+		Header:        *header,
+		Justification: newWarpSyncTestJustification(t, header, header, nil, round, setID, keys),
+	}
+}
+
+func TestVerifyWarpSyncJustification(t *testing.T) {
+	header := newWarpSyncTestHeader(t, 1, nil)
+
+	fragment := newWarpSyncTestFragment(t, header, 3, 0, kr.Keys)
+	round, err := verifyWarpSyncJustification(&fragment, 0, voters)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), round)
+
+	// signed for another set ID
+	fragment = newWarpSyncTestFragment(t, header, 3, 1, kr.Keys)
+	_, err = verifyWarpSyncJustification(&fragment, 0, voters)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// not enough signers
+	fragment = newWarpSyncTestFragment(t, header, 3, 0, kr.Keys[:len(kr.Keys)*2/3])
+	_, err = verifyWarpSyncJustification(&fragment, 0, voters)
+	require.ErrorIs(t, err, ErrMinVotesNotMet)
+
+	// justification of another block
+	other := newWarpSyncTestHeader(t, 2, nil)
+	fragment = newWarpSyncTestFragment(t, other, 3, 0, kr.Keys)
+	fragment.Header = *header
+	_, err = verifyWarpSyncJustification(&fragment, 0, voters)
+	require.ErrorIs(t, err, ErrPrecommitBlockMismatch)
+}
+
+func TestAuthoritySetChange(t *testing.T) {
+	next, err := authoritySetChange(newWarpSyncTestHeader(t, 1, nil))
+	require.NoError(t, err)
+	require.Nil(t, next)
+
+	auths := []types.GrandpaAuthoritiesRaw{
+		{Key: kr.Alice().Public().(*ed25519.PublicKey).AsBytes(), ID: 0},
+	}
+
+	next, err = authoritySetChange(newWarpSyncTestHeader(t, 1, &types.GrandpaScheduledChange{
+		Auths: auths,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, []types.GrandpaVoter{
+		{Key: *kr.Alice().Public().(*ed25519.PublicKey), ID: 0},
+	}, next)
+
+	_, err = authoritySetChange(newWarpSyncTestHeader(t, 1, &types.GrandpaScheduledChange{
+		Auths: auths,
+		Delay: 1,
+	}))
+	require.ErrorIs(t, err, errWarpSyncChangeWithDelay)
+}
+
+func TestVerifyWarpSyncProof(t *testing.T) {
+	db, err := utils.SetupDatabase(t.TempDir(), true)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	grandpaState, err := state.NewGrandpaStateFromGenesis(db, voters)
+	require.NoError(t, err)
+
+	gs := &Service{
+		grandpaState: grandpaState,
+	}
+
+	next := []types.GrandpaAuthoritiesRaw{
+		{Key: kr.Alice().Public().(*ed25519.PublicKey).AsBytes(), ID: 0},
+		{Key: kr.Bob().Public().(*ed25519.PublicKey).AsBytes(), ID: 1},
+	}
+
+	change := newWarpSyncTestHeader(t, 10, &types.GrandpaScheduledChange{
+		Auths: next,
+	})
+	finalised := newWarpSyncTestHeader(t, 20, nil)
+
+	nextKeys := []*ed25519.Keypair{kr.Alice().(*ed25519.Keypair), kr.Bob().(*ed25519.Keypair)}
+
+	// the second fragment is signed for the previous set ID
+	invalid := &network.WarpSyncProof{
+		Fragments: []network.WarpSyncFragment{
+			newWarpSyncTestFragment(t, change, 1, 0, kr.Keys),
+			newWarpSyncTestFragment(t, finalised, 2, 0, nextKeys),
+		},
+		IsFinished: true,
+	}
+
+	_, _, _, _, err = gs.VerifyWarpSyncProof(0, invalid)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	proof := &network.WarpSyncProof{
+		Fragments: []network.WarpSyncFragment{
+			newWarpSyncTestFragment(t, change, 1, 0, kr.Keys),
+			newWarpSyncTestFragment(t, finalised, 2, 1, nextKeys),
+		},
+		IsFinished: true,
+	}
+
+	// the fragments must be above the block the proof starts from
+	_, _, _, _, err = gs.VerifyWarpSyncProof(10, proof)
+	require.ErrorIs(t, err, errWarpSyncFragmentNotIncreasing)
+
+	header, round, setID, changes, err := gs.VerifyWarpSyncProof(0, proof)
+	require.NoError(t, err)
+	require.Equal(t, finalised.Hash(), header.Hash())
+	require.Equal(t, uint64(2), round)
+	require.Equal(t, uint64(1), setID)
+
+	nextVoters, err := types.NewGrandpaVotersFromAuthoritiesRaw(next)
+	require.NoError(t, err)
+	require.Equal(t, []types.GrandpaAuthoritySetChange{{Voters: nextVoters, Number: 10}}, changes)
+
+	// verifying the proof doesn't record the authority set changes
+	currentSetID, err := grandpaState.GetCurrentSetID()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), currentSetID)
+
+	err = gs.ApplyAuthoritySetChanges(changes)
+	require.NoError(t, err)
+
+	currentSetID, err = grandpaState.GetCurrentSetID()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), currentSetID)
+
+	changeNumber, err := grandpaState.GetSetIDChange(1)
+	require.NoError(t, err)
+	require.Equal(t, uint(10), changeNumber)
+}
+
+func TestVerifyWarpSyncProof_fragmentsNotIncreasing(t *testing.T) {
+	db, err := utils.SetupDatabase(t.TempDir(), true)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	grandpaState, err := state.NewGrandpaStateFromGenesis(db, voters)
+	require.NoError(t, err)
+
+	gs := &Service{
+		grandpaState: grandpaState,
+	}
+
+	change := newWarpSyncTestHeader(t, 10, &types.GrandpaScheduledChange{
+		Auths: []types.GrandpaAuthoritiesRaw{
+			{Key: kr.Alice().Public().(*ed25519.PublicKey).AsBytes(), ID: 0},
+		},
+	})
+	old := newWarpSyncTestHeader(t, 5, nil)
+
+	// a valid justification of an older block of the set following the change
+	proof := &network.WarpSyncProof{
+		Fragments: []network.WarpSyncFragment{
+			newWarpSyncTestFragment(t, change, 1, 0, kr.Keys),
+			newWarpSyncTestFragment(t, old, 2, 1, []*ed25519.Keypair{kr.Alice().(*ed25519.Keypair)}),
+		},
+		IsFinished: true,
+	}
+
+	_, _, _, _, err = gs.VerifyWarpSyncProof(0, proof)
+	require.ErrorIs(t, err, errWarpSyncFragmentNotIncreasing)
+}
+
+func TestVerifyWarpSyncJustification_votesAncestries(t *testing.T) {
+	h1 := newWarpSyncTestHeader(t, 1, nil)
+	h2 := newWarpSyncTestChild(t, h1, 2, nil)
+	h3 := newWarpSyncTestChild(t, h2, 3, nil)
+
+	newFragment := func(header, target *types.Header, ancestries ...types.Header) *network.WarpSyncFragment {
+		return &network.WarpSyncFragment{
+			Header:        *header,
+			Justification: newWarpSyncTestJustification(t, header, target, ancestries, 3, 0, kr.Keys),
+		}
+	}
+
+	// precommits for a descendant, linked to the finalised block by the ancestries
+	round, err := verifyWarpSyncJustification(newFragment(h1, h3, *h3, *h2), 0, voters)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), round)
+
+	// missing ancestry
+	_, err = verifyWarpSyncJustification(newFragment(h1, h3, *h3), 0, voters)
+	require.ErrorIs(t, err, ErrPrecommitBlockMismatch)
+
+	// unused ancestry
+	_, err = verifyWarpSyncJustification(newFragment(h1, h1, *h2), 0, voters)
+	require.ErrorIs(t, err, errUnusedVotesAncestry)
+
+	// the real precommits for a block are attached to a forged lower header changing the authorities
+	forged := newWarpSyncTestHeader(t, 2, &types.GrandpaScheduledChange{
+		Auths: []types.GrandpaAuthoritiesRaw{
+			{Key: kr.Alice().Public().(*ed25519.PublicKey).AsBytes(), ID: 0},
+		},
+	})
+	_, err = verifyWarpSyncJustification(newFragment(forged, h3), 0, voters)
+	require.ErrorIs(t, err, ErrPrecommitBlockMismatch)
+	_, err = verifyWarpSyncJustification(newFragment(forged, h3, *h3), 0, voters)
+	require.ErrorIs(t, err, ErrPrecommitBlockMismatch)
+}
+
+// warpSyncTestBlockState is a block state holding the given headers
+type warpSyncTestBlockState struct {
+	BlockState
+	headers map[common.Hash]*types.Header
+}
+
+func (bs *warpSyncTestBlockState) GetHeader(hash common.Hash) (*types.Header, error) {
+	header, has := bs.headers[hash]
+	if !has {
+		return nil, chaindb.ErrKeyNotFound
+	}
+	return header, nil
+}
+
+func TestWithVotesAncestries(t *testing.T) {
+	h1 := newWarpSyncTestHeader(t, 1, nil)
+	h2 := newWarpSyncTestChild(t, h1, 2, nil)
+	h3 := newWarpSyncTestChild(t, h2, 3, nil)
+
+	blockState := &warpSyncTestBlockState{
+		headers: make(map[common.Hash]*types.Header),
+	}
+	for _, header := range []*types.Header{testGenesisHeader, h1, h2, h3} {
+		blockState.headers[header.Hash()] = header
+	}
+
+	gs := &Service{
+		blockState: blockState,
+	}
+
+	// the stored justification doesn't contain the ancestries
+	stored := newWarpSyncTestJustification(t, h1, h3, nil, 3, 0, kr.Keys)
+	stored = stored[:len(stored)-1]
+
+	res, err := gs.withVotesAncestries(stored)
+	require.NoError(t, err)
+	require.Equal(t, newWarpSyncTestJustification(t, h1, h3, []types.Header{*h3, *h2}, 3, 0, kr.Keys), res)
+
+	fragment := &network.WarpSyncFragment{
+		Header:        *h1,
+		Justification: res,
+	}
+	_, err = verifyWarpSyncJustification(fragment, 0, voters)
+	require.NoError(t, err)
+
+	// the ancestries of justifications received from other nodes are replaced
+	res, err = gs.withVotesAncestries(newWarpSyncTestJustification(t, h1, h3, []types.Header{*h2}, 3, 0, kr.Keys))
+	require.NoError(t, err)
+	require.Equal(t, newWarpSyncTestJustification(t, h1, h3, []types.Header{*h3, *h2}, 3, 0, kr.Keys), res)
+
+	// precommits for a block which isn't a descendant of the finalised block
+	forged := newWarpSyncTestHeader(t, 2, nil)
+	_, err = gs.withVotesAncestries(newWarpSyncTestJustification(t, forged, h3, nil, 3, 0, kr.Keys))
+	require.ErrorIs(t, err, ErrPrecommitBlockMismatch)
+}
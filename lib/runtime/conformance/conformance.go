@@ -0,0 +1,207 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+// Package conformance runs the host functions of the wasm interpreters through spec vectors,
+// using a conformance runtime exporting a `rtm_<host function>` wrapper for each host function.
+package conformance
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ChainSafe/gossamer/lib/keystore"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/life"
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/runtime/wasmer"
+)
+
+// Vector is a spec vector of a host function
+type Vector struct {
+	// Function is the name of the host function, eg. ext_hashing_twox_64_version_1
+	Function string
+	// Name describes the case covered by the vector
+	Name string
+	// Setup prepares the storage the host function is called with, it is optional
+	Setup func(s runtime.Storage) error
+	// Input is the SCALE encoded arguments of the host function
+	Input []byte
+	// Output is the expected SCALE encoded result of the host function, it isn't checked if it's nil
+	Output []byte
+	// Check checks the result of the host function and the storage after the call, it is optional
+	Check func(output []byte, s runtime.Storage) error
+}
+
+// Backend creates runtime instances with a wasm interpreter
+type Backend struct {
+	Name        string
+	NewInstance func(code []byte, s runtime.Storage) (runtime.Instance, error)
+}
+
+// Backends returns the backends of the wasm interpreters supported by gossamer
+func Backends() []Backend {
+	return []Backend{
+		{
+			Name: wasmer.Name,
+			NewInstance: func(code []byte, s runtime.Storage) (runtime.Instance, error) {
+				cfg := &wasmer.Config{
+					Imports: wasmer.ImportsNodeRuntime,
+				}
+				cfg.Storage = s
+				cfg.Keystore = keystore.NewGlobalKeystore()
+				return wasmer.NewInstance(code, cfg)
+			},
+		},
+		{
+			Name: life.Name,
+			NewInstance: func(code []byte, s runtime.Storage) (runtime.Instance, error) {
+				cfg := &life.Config{
+					Resolver: new(life.Resolver),
+				}
+				cfg.Storage = s
+				cfg.Keystore = keystore.NewGlobalKeystore()
+				return life.NewInstance(code, cfg)
+			},
+		},
+	}
+}
+
+// Result is the result of the vectors of a host function
+type Result struct {
+	Function string
+	Passed   int
+	// Failures describes the vectors which failed
+	Failures []string
+}
+
+// Report is the result of the vectors run with a backend
+type Report struct {
+	Backend string
+	// Results are the results of the host functions, sorted by function name
+	Results []*Result
+	// Untested are the host functions imported by the runtime which have no vector
+	Untested []string
+}
+
+// Failed returns whether any vector failed
+func (r *Report) Failed() bool {
+	for _, result := range r.Results {
+		if len(result.Failures) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "host API conformance of %s:\n", r.Backend)
+	for _, result := range r.Results {
+		status := "PASS"
+		if len(result.Failures) > 0 {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&sb, "%s %s (%d/%d)\n", status, result.Function, result.Passed, result.Passed+len(result.Failures))
+		for _, failure := range result.Failures {
+			fmt.Fprintf(&sb, "    %s\n", failure)
+		}
+	}
+	for _, function := range r.Untested {
+		fmt.Fprintf(&sb, "UNTESTED %s\n", function)
+	}
+	return sb.String()
+}
+
+// Run runs the vectors with an instance of the conformance runtime code created by the backend.
+// Each vector is run on a new empty storage.
+func Run(backend Backend, code []byte, vectors []Vector) (*Report, error) {
+	info, err := runtime.ParseModuleInfo(code)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse runtime: %w", err)
+	}
+
+	s, err := storage.NewTrieState(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := backend.NewInstance(code, s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create %s instance: %w", backend.Name, err)
+	}
+	defer instance.Stop()
+
+	results := make(map[string]*Result)
+	for _, vector := range vectors {
+		result, has := results[vector.Function]
+		if !has {
+			result = &Result{Function: vector.Function}
+			results[vector.Function] = result
+		}
+
+		err := runVector(instance, vector)
+		if err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: %s", vector.Name, err))
+			continue
+		}
+		result.Passed++
+	}
+
+	report := &Report{
+		Backend: backend.Name,
+	}
+	for _, result := range results {
+		report.Results = append(report.Results, result)
+	}
+	sort.Slice(report.Results, func(i, j int) bool {
+		return report.Results[i].Function < report.Results[j].Function
+	})
+
+	for _, function := range info.ImportedFunctions {
+		if _, has := results[function]; !has && strings.HasPrefix(function, "ext_") {
+			report.Untested = append(report.Untested, function)
+		}
+	}
+	sort.Strings(report.Untested)
+
+	return report, nil
+}
+
+// runVector calls the wrapper of the host function with the input of the vector, and checks its result
+func runVector(instance runtime.Instance, vector Vector) (err error) {
+	s, err := storage.NewTrieState(nil)
+	if err != nil {
+		return err
+	}
+
+	if vector.Setup != nil {
+		err = vector.Setup(s)
+		if err != nil {
+			return fmt.Errorf("setup failed: %w", err)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	instance.SetContextStorage(s)
+	output, err := instance.Exec("rtm_"+vector.Function, vector.Input)
+	if err != nil {
+		return err
+	}
+
+	if vector.Output != nil && !bytes.Equal(output, vector.Output) {
+		return fmt.Errorf("expected output 0x%x, got 0x%x", vector.Output, output)
+	}
+
+	if vector.Check != nil {
+		return vector.Check(output, s)
+	}
+
+	return nil
+}
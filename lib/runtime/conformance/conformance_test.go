@@ -0,0 +1,82 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package conformance
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var runtimePath = flag.String("runtime", runtime.GetAbsolutePath(runtime.HOST_API_TEST_RUNTIME_FP),
+	"path of the conformance runtime")
+
+func TestHostAPI(t *testing.T) {
+	code, err := os.ReadFile(*runtimePath)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Skipf("conformance runtime %s not found", *runtimePath)
+	}
+	require.NoError(t, err)
+
+	for _, backend := range Backends() {
+		backend := backend
+		t.Run(backend.Name, func(t *testing.T) {
+			report, err := Run(backend, code, Vectors)
+			require.NoError(t, err)
+			t.Log(report)
+
+			for _, result := range report.Results {
+				result := result
+				t.Run(result.Function, func(t *testing.T) {
+					require.Empty(t, result.Failures)
+				})
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	// empty wasm module
+	code := []byte{0, 'a', 's', 'm', 1, 0, 0, 0}
+
+	instance := new(mocks.Instance)
+	instance.On("SetContextStorage", mock.Anything)
+	instance.On("Stop")
+	instance.On("Exec", "rtm_ext_a", []byte{1}).Return([]byte{1}, nil)
+	instance.On("Exec", "rtm_ext_a", []byte{2}).Return([]byte{3}, nil)
+	instance.On("Exec", "rtm_ext_b", []byte{1}).Return(nil, errors.New("unreachable"))
+
+	backend := Backend{
+		Name: "mock",
+		NewInstance: func(_ []byte, _ runtime.Storage) (runtime.Instance, error) {
+			return instance, nil
+		},
+	}
+
+	vectors := []Vector{
+		{Function: "ext_b", Name: "trap", Input: []byte{1}, Output: []byte{1}},
+		{Function: "ext_a", Name: "one", Input: []byte{1}, Output: []byte{1}},
+		{Function: "ext_a", Name: "two", Input: []byte{2}, Output: []byte{2}},
+	}
+
+	report, err := Run(backend, code, vectors)
+	require.NoError(t, err)
+
+	expected := &Report{
+		Backend: "mock",
+		Results: []*Result{
+			{Function: "ext_a", Passed: 1, Failures: []string{"two: expected output 0x02, got 0x03"}},
+			{Function: "ext_b", Failures: []string{"trap: unreachable"}},
+		},
+	}
+	require.Equal(t, expected, report)
+	require.True(t, report.Failed())
+	instance.AssertExpectations(t)
+}
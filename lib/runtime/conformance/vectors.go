@@ -0,0 +1,351 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package conformance
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+var (
+	childKey = []byte(":child_storage:default:test")
+
+	// ed25519 signature of the empty message, from test 1 of RFC 8032
+	ed25519PublicKey = common.MustHexToBytes("0xd75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	ed25519Signature = common.MustHexToBytes("0xe5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e06522490155" +
+		"5fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+)
+
+// Vectors are the spec vectors of the host functions
+var Vectors = []Vector{
+	// hashing
+	hashVector("ext_hashing_blake2_128_version_1", "0x471ef9f403b2c916d29d3e9179221f03"),
+	hashVector("ext_hashing_blake2_256_version_1",
+		"0x3c228306552177f5a304cb12a5b5e60897f2f486b64671afdccf0f8dd9410cbd"),
+	hashVector("ext_hashing_keccak_256_version_1",
+		"0xfa26db7ca85ead399216e7c6316bc50ed24393c3122b582735e7f3b0f91b93f0"),
+	hashVector("ext_hashing_sha2_256_version_1",
+		"0x936a185caaa266bb9cbe981e9e05cb78cd732b0b3280eb944412bb6f8f8f07af"),
+	hashVector("ext_hashing_twox_64_version_1", "0x4f6a1caa01161180"),
+	hashVector("ext_hashing_twox_128_version_1", "0x4f6a1caa01161180582092ecfac2502b"),
+	hashVector("ext_hashing_twox_256_version_1",
+		"0x4f6a1caa01161180582092ecfac2502b43f81e7e1a02c7d28597873706a6120a"),
+
+	// storage
+	{
+		Function: "ext_storage_get_version_1",
+		Name:     "existing key",
+		Setup:    set("noot", "washere"),
+		Input:    encode([]byte("noot")),
+		Output:   encode(optional("washere")),
+	},
+	{
+		Function: "ext_storage_get_version_1",
+		Name:     "missing key",
+		Input:    encode([]byte("noot")),
+		Output:   encode(optional("")),
+	},
+	{
+		Function: "ext_storage_set_version_1",
+		Name:     "new key",
+		Input:    concat(encode([]byte("noot")), encode([]byte("washere"))),
+		Check:    expectStorage(map[string]string{"noot": "washere"}),
+	},
+	{
+		Function: "ext_storage_set_version_1",
+		Name:     "existing key",
+		Setup:    set("noot", "was"),
+		Input:    concat(encode([]byte("noot")), encode([]byte("washere"))),
+		Check:    expectStorage(map[string]string{"noot": "washere"}),
+	},
+	{
+		Function: "ext_storage_clear_version_1",
+		Name:     "existing key",
+		Setup:    set("noot", "washere"),
+		Input:    encode([]byte("noot")),
+		Check:    expectStorage(map[string]string{"noot": ""}),
+	},
+	{
+		Function: "ext_storage_exists_version_1",
+		Name:     "existing key",
+		Setup:    set("noot", "washere"),
+		Input:    encode([]byte("noot")),
+		Check:    expectBool(true),
+	},
+	{
+		Function: "ext_storage_exists_version_1",
+		Name:     "missing key",
+		Input:    encode([]byte("noot")),
+		Check:    expectBool(false),
+	},
+	{
+		Function: "ext_storage_read_version_1",
+		Name:     "offset in value",
+		Setup:    set("noot", "washere"),
+		Input:    concat(encode([]byte("noot")), encode(uint32(2)), encode(uint32(100))),
+		Check:    expectRead("shere"),
+	},
+	{
+		Function: "ext_storage_read_version_1",
+		Name:     "buffer smaller than value",
+		Setup:    set("noot", "_was_here_"),
+		Input:    concat(encode([]byte("noot")), encode(uint32(8)), encode(uint32(5))),
+		Check:    expectRead("e_"),
+	},
+	{
+		Function: "ext_storage_clear_prefix_version_1",
+		Name:     "matching keys",
+		Setup:    set("noot", "washere", "noodle", "was", "other", "here"),
+		Input:    encode([]byte("noo")),
+		Check:    expectStorage(map[string]string{"noot": "", "noodle": "", "other": "here"}),
+	},
+	{
+		Function: "ext_storage_next_key_version_1",
+		Name:     "next key",
+		Setup:    set("noot", "washere", "oot", "washere"),
+		Input:    encode([]byte("noot")),
+		Output:   encode(optional("oot")),
+	},
+	{
+		Function: "ext_storage_next_key_version_1",
+		Name:     "last key",
+		Setup:    set("noot", "washere"),
+		Input:    encode([]byte("noot")),
+		Output:   encode(optional("")),
+	},
+	{
+		Function: "ext_storage_append_version_1",
+		Name:     "new key",
+		Input:    concat(encode([]byte("noot")), encode(encode([]byte("was")))),
+		Check:    expectStorage(map[string]string{"noot": string(encode([][]byte{[]byte("was")}))}),
+	},
+	{
+		Function: "ext_storage_root_version_1",
+		Name:     "single key",
+		Setup:    set("noot", "washere"),
+		Output:   encode(common.MustHexToBytes("0x0ef1ba8989ebe32f46c4b4d6269db24921050a72505361b37dab51d7a5f92008")),
+	},
+	{
+		Function: "ext_storage_root_version_1",
+		Name:     "empty storage",
+		Output:   encode(common.MustHexToBytes("0x03170a2e7597b7b7e3d84c05391d139a62b157e78786d8c082f29dcf4c111314")),
+	},
+
+	// child storage
+	{
+		Function: "ext_default_child_storage_get_version_1",
+		Name:     "existing key",
+		Setup:    setChild("key", "value"),
+		Input:    concat(encode(childKey), encode([]byte("key"))),
+		Output:   encode(optional("value")),
+	},
+	{
+		Function: "ext_default_child_storage_set_version_1",
+		Name:     "new key",
+		Setup:    setChild(),
+		Input:    concat(encode(childKey), encode([]byte("key")), encode([]byte("value"))),
+		Check:    expectChildStorage(map[string]string{"key": "value"}),
+	},
+	{
+		Function: "ext_default_child_storage_clear_version_1",
+		Name:     "existing key",
+		Setup:    setChild("key", "value"),
+		Input:    concat(encode(childKey), encode([]byte("key"))),
+		Check:    expectChildStorage(map[string]string{"key": ""}),
+	},
+	{
+		Function: "ext_default_child_storage_clear_prefix_version_1",
+		Name:     "matching keys",
+		Setup:    setChild("keyOne", "value1", "keyTwo", "value2", "other", "value3"),
+		Input:    concat(encode(childKey), encode([]byte("key"))),
+		Check:    expectChildStorage(map[string]string{"keyOne": "", "keyTwo": "", "other": "value3"}),
+	},
+	{
+		Function: "ext_default_child_storage_exists_version_1",
+		Name:     "existing key",
+		Setup:    setChild("key", "value"),
+		Input:    concat(encode(childKey), encode([]byte("key"))),
+		Check:    expectBool(true),
+	},
+	{
+		Function: "ext_default_child_storage_next_key_version_1",
+		Name:     "next key",
+		Setup:    setChild("apple", "value1", "key", "value2"),
+		Input:    concat(encode(childKey), encode([]byte("apple"))),
+		Output:   encode(optional("key")),
+	},
+	{
+		Function: "ext_default_child_storage_root_version_1",
+		Name:     "single key",
+		Setup:    setChild("key", "value"),
+		Input:    encode(childKey),
+		Output:   encode(common.MustHexToBytes("0x434590ba666a2d9ed9f2ca8bde0a2e876b1a744878e8522e9bc2b88c91e6c2c0")),
+	},
+	{
+		Function: "ext_default_child_storage_storage_kill_version_1",
+		Name:     "existing child",
+		Setup:    setChild("key", "value"),
+		Input:    encode(childKey),
+		Check: func(_ []byte, s runtime.Storage) error {
+			child, err := s.GetChild(childKey)
+			if err == nil && child != nil {
+				return fmt.Errorf("child storage wasn't deleted")
+			}
+			return nil
+		},
+	},
+
+	// trie
+	{
+		Function: "ext_trie_blake2_256_root_version_1",
+		Name:     "two entries",
+		// a list of (key, value) pairs
+		Input: concat([]byte{2 << 2}, encode([]byte("noot")), encode([]byte("was")),
+			encode([]byte("here")), encode([]byte("??"))),
+		Output: encode(common.MustHexToBytes("0x733eea4008671f422e0d6fe3297f379f8585f407f5ef47db423a8458f16f42e4")),
+	},
+	{
+		Function: "ext_trie_blake2_256_ordered_root_version_1",
+		Name:     "three values",
+		Input:    encode([]string{"static", "even-keeled", "Future-proofed"}),
+		Output:   encode(common.MustHexToBytes("0xd847b86d0219a384d11458e829e9f4f4cce7e3cc2e6dcd0e8a6ad6f12c64a737")),
+	},
+
+	// crypto
+	{
+		Function: "ext_crypto_ed25519_verify_version_1",
+		Name:     "valid signature",
+		Input:    concat(encode(ed25519Signature), encode([]byte{}), encode(ed25519PublicKey)),
+		Check:    expectBool(true),
+	},
+	{
+		Function: "ext_crypto_ed25519_verify_version_1",
+		Name:     "wrong message",
+		Input:    concat(encode(ed25519Signature), encode([]byte("noot")), encode(ed25519PublicKey)),
+		Check:    expectBool(false),
+	},
+}
+
+// hashVector returns the vector of a hashing host function hashing "helloworld"
+func hashVector(function, hash string) Vector {
+	return Vector{
+		Function: function,
+		Name:     "helloworld",
+		Input:    encode([]byte("helloworld")),
+		Output:   encode(common.MustHexToBytes(hash)),
+	}
+}
+
+func encode(value interface{}) []byte {
+	enc, err := scale.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return enc
+}
+
+func concat(encoded ...[]byte) []byte {
+	return bytes.Join(encoded, nil)
+}
+
+// optional returns a Some value, or None if the value is empty
+func optional(value string) *[]byte {
+	if value == "" {
+		return nil
+	}
+	v := []byte(value)
+	return &v
+}
+
+// set returns a setup storing the key value pairs
+func set(keyValues ...string) func(s runtime.Storage) error {
+	return func(s runtime.Storage) error {
+		for i := 0; i < len(keyValues); i += 2 {
+			s.Set([]byte(keyValues[i]), []byte(keyValues[i+1]))
+		}
+		return nil
+	}
+}
+
+// setChild returns a setup creating the child storage and storing the key value pairs in it
+func setChild(keyValues ...string) func(s runtime.Storage) error {
+	return func(s runtime.Storage) error {
+		err := s.SetChild(childKey, trie.NewEmptyTrie())
+		if err != nil {
+			return err
+		}
+
+		for i := 0; i < len(keyValues); i += 2 {
+			err = s.SetChildStorage(childKey, []byte(keyValues[i]), []byte(keyValues[i+1]))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// expectStorage checks the values of the keys in the storage, an empty value is a missing key
+func expectStorage(expected map[string]string) func(output []byte, s runtime.Storage) error {
+	return func(_ []byte, s runtime.Storage) error {
+		for key, value := range expected {
+			if actual := s.Get([]byte(key)); !bytes.Equal(actual, []byte(value)) {
+				return fmt.Errorf("expected value 0x%x for key %q, got 0x%x", value, key, actual)
+			}
+		}
+		return nil
+	}
+}
+
+// expectChildStorage checks the values of the keys in the child storage, an empty value is a missing key
+func expectChildStorage(expected map[string]string) func(output []byte, s runtime.Storage) error {
+	return func(_ []byte, s runtime.Storage) error {
+		for key, value := range expected {
+			actual, err := s.GetChildStorage(childKey, []byte(key))
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(actual, []byte(value)) {
+				return fmt.Errorf("expected value 0x%x for child key %q, got 0x%x", value, key, actual)
+			}
+		}
+		return nil
+	}
+}
+
+// expectBool checks the boolean returned by the host function
+func expectBool(expected bool) func(output []byte, s runtime.Storage) error {
+	return func(output []byte, _ runtime.Storage) error {
+		if len(output) == 0 {
+			return fmt.Errorf("expected %t, got empty output", expected)
+		}
+		if actual := output[0] == 1; actual != expected {
+			return fmt.Errorf("expected %t, got %t", expected, actual)
+		}
+		return nil
+	}
+}
+
+// expectRead checks that the buffer returned by ext_storage_read starts with the value read
+func expectRead(expected string) func(output []byte, s runtime.Storage) error {
+	return func(output []byte, _ runtime.Storage) error {
+		var read *[]byte
+		err := scale.Unmarshal(output, &read)
+		if err != nil {
+			return err
+		}
+		if read == nil {
+			return fmt.Errorf("expected value %q, got none", expected)
+		}
+		if !bytes.HasPrefix(*read, []byte(expected)) {
+			return fmt.Errorf("expected value %q, got 0x%x", expected, *read)
+		}
+		return nil
+	}
+}
@@ -65,8 +65,12 @@
 	BlockBuilderFinalizeBlock = "BlockBuilder_finalize_block"
 	// DecodeSessionKeys is the runtime API call SessionKeys_decode_session_keys
 	DecodeSessionKeys = "SessionKeys_decode_session_keys"
+	// GenerateSessionKeys is the runtime API call SessionKeys_generate_session_keys
+	GenerateSessionKeys = "SessionKeys_generate_session_keys"
 	// TransactionPaymentAPIQueryInfo returns information of a given extrinsic
 	TransactionPaymentAPIQueryInfo = "TransactionPaymentApi_query_info"
+	// OffchainWorkerAPI is the runtime API call OffchainWorkerApi_offchain_worker
+	OffchainWorkerAPI = "OffchainWorkerApi_offchain_worker"
 )
 
 // GrandpaAuthoritiesKey is the location of GRANDPA authority data
@@ -22,3 +22,9 @@
 
 // ErrNilStorage is returned when the runtime context storage isn't set
 var ErrNilStorage = errors.New("runtime context storage is nil")
+
+// ErrMemoryLimitExceeded is returned when the runtime memory would grow beyond its limit
+var ErrMemoryLimitExceeded = errors.New("runtime memory limit exceeded")
+
+// ErrExportFunctionNotFound is returned when the runtime does not export the function being called
+var ErrExportFunctionNotFound = errors.New("export function not found")
@@ -37,14 +37,22 @@ type Instance interface {
 	FinalizeBlock() (*types.Header, error)
 	ExecuteBlock(block *types.Block) ([]byte, error)
 	DecodeSessionKeys(enc []byte) ([]byte, error)
+	GenerateSessionKeys(seed *[]byte) ([]byte, error)
 	PaymentQueryInfo(ext []byte) (*types.TransactionPaymentQueryInfo, error)
+	OffchainWorker(header *types.Header) error
 
 	CheckInherents() // TODO: use this in block verification process (#1873)
 
 	// parameters and return values for these are undefined in the spec
 	RandomSeed()
-	OffchainWorker()
-	GenerateSessionKeys()
+}
+
+// PooledInstance is implemented by the instances running their calls on a pool of runtime instances
+type PooledInstance interface {
+	Instance
+	// Handle returns an instance running its calls on the same pool, with its own context storage,
+	// so that the callers setting their storage don't interfere with each other.
+	Handle() Instance
 }
 
 // Storage interface
@@ -69,6 +77,8 @@ type Storage interface {
 	CommitStorageTransaction()
 	RollbackStorageTransaction()
 	LoadCode() []byte
+	IndexTransaction(extrinsic, size uint32, hash common.Hash)
+	RenewTransactionIndex(extrinsic uint32, hash common.Hash)
 }
 
 // BasicNetwork interface for functions used by runtime network state function
@@ -87,5 +97,5 @@ type BasicStorage interface {
 
 // TransactionState interface for adding transactions to pool
 type TransactionState interface {
-	AddToPool(vt *transaction.ValidTransaction) common.Hash
+	AddToPool(vt *transaction.ValidTransaction) (common.Hash, error)
 }
@@ -4,8 +4,6 @@
 package life
 
 import (
-	"bytes"
-	"errors"
 	"fmt"
 	"strings"
 
@@ -15,7 +13,7 @@
 	"github.com/ChainSafe/gossamer/pkg/scale"
 )
 
-// ValidateTransaction runs the extrinsic through runtime function
+// ValidateTransaction runs the extrinsic through the runtime function
 // TaggedTransactionQueue_validate_transaction and returns *Validity
 func (in *Instance) ValidateTransaction(e types.Extrinsic) (*transaction.Validity, error) {
 	ret, err := in.Exec(runtime.TaggedTransactionQueueValidateTransaction, e)
@@ -29,11 +27,17 @@ func (in *Instance) ValidateTransaction(e types.Extrinsic) (*transaction.Validit
 
 	v := transaction.NewValidity(0, [][]byte{{}}, [][]byte{{}}, 0, false)
 	err = scale.Unmarshal(ret[1:], v)
+
 	return v, err
 }
 
 // Version calls runtime function Core_Version
 func (in *Instance) Version() (runtime.Version, error) {
+	// kusama seems to use the legacy version format
+	if in.version != nil {
+		return in.version, nil
+	}
+
 	res, err := in.Exec(runtime.CoreVersion, []byte{})
 	if err != nil {
 		return nil, err
@@ -44,7 +48,7 @@ func (in *Instance) Version() (runtime.Version, error) {
 	// error comes from scale now, so do a string check
 	if err != nil {
 		if strings.Contains(err.Error(), "EOF") {
-			// kusama seems to use the legacy version format
+			// TODO: kusama seems to use the legacy version format
 			lversion := &runtime.LegacyVersionData{}
 			err = lversion.Decode(res)
 			return lversion, err
@@ -137,16 +141,17 @@ func (in *Instance) ExecuteBlock(block *types.Block) ([]byte, error) {
 		return nil, err
 	}
 
+	if in.version == nil {
+		in.version, err = in.Version()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	b.Header.Digest = types.NewDigest()
 
 	// remove seal digest only
 	for _, d := range block.Header.Digest.Types {
-		// hack since substrate node_runtime can't seem to handle BABE pre-runtime digests
-		// with type prefix (ie Primary, Secondary...)
-		if bytes.Equal(in.version.SpecName(), []byte("node")) {
-			break
-		}
-
 		switch d.Value().(type) {
 		case types.SealDigest:
 			continue
@@ -171,13 +176,57 @@ func (in *Instance) DecodeSessionKeys(enc []byte) ([]byte, error) {
 	return in.Exec(runtime.DecodeSessionKeys, enc)
 }
 
+// GenerateSessionKeys generates the session keys of the runtime from the given seed, or from a random
+// seed if it is nil, and stores them in the keystore. Returns the SCALE encoded public session keys.
+func (in *Instance) GenerateSessionKeys(seed *[]byte) ([]byte, error) {
+	encSeed, err := scale.Marshal(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := in.Exec(runtime.GenerateSessionKeys, encSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []byte
+	if err = scale.Unmarshal(ret, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
 // PaymentQueryInfo returns information of a given extrinsic
-func (*Instance) PaymentQueryInfo([]byte) (*types.TransactionPaymentQueryInfo, error) {
-	// TODO: implement the payment query info (see issue #1892)
-	return nil, errors.New("not implemented yet")
+func (in *Instance) PaymentQueryInfo(ext []byte) (*types.TransactionPaymentQueryInfo, error) {
+	encLen, err := scale.Marshal(uint32(len(ext)))
+	if err != nil {
+		return nil, err
+	}
+
+	resBytes, err := in.Exec(runtime.TransactionPaymentAPIQueryInfo, append(ext, encLen...))
+	if err != nil {
+		return nil, err
+	}
+
+	i := new(types.TransactionPaymentQueryInfo)
+	if err = scale.Unmarshal(resBytes, i); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// OffchainWorker calls runtime API function OffchainWorkerApi_offchain_worker
+func (in *Instance) OffchainWorker(header *types.Header) error {
+	encodedHeader, err := scale.Marshal(*header)
+	if err != nil {
+		return fmt.Errorf("cannot encode header: %w", err)
+	}
+
+	_, err = in.Exec(runtime.OffchainWorkerAPI, encodedHeader)
+	return err
 }
 
-func (in *Instance) CheckInherents()      {} //nolint:revive
-func (in *Instance) RandomSeed()          {} //nolint:revive
-func (in *Instance) OffchainWorker()      {} //nolint:revive
-func (in *Instance) GenerateSessionKeys() {} //nolint:revive
+func (in *Instance) CheckInherents() {} //nolint:revive
+func (in *Instance) RandomSeed()     {} //nolint:revive
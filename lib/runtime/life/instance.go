@@ -15,6 +15,8 @@
 	"github.com/ChainSafe/gossamer/lib/crypto"
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/ChainSafe/gossamer/lib/trie"
 
 	"github.com/perlin-network/life/exec"
 	wasm_validation "github.com/perlin-network/life/wasm-validation"
@@ -26,29 +28,35 @@
 // Check that runtime interfaces are satisfied
 var (
 	_      runtime.Instance = (*Instance)(nil)
+	_      runtime.Memory   = (*Memory)(nil)
 	logger                  = log.NewFromGlobal(
 		log.AddContext("pkg", "runtime"),
 		log.AddContext("component", "perlin/life"),
 	)
-	ctx *runtime.Context
 )
 
 // Config represents a life configuration
 type Config struct {
 	runtime.InstanceConfig
+	// Resolver resolves the imports of the runtime, the host functions of a *Resolver are bound to the
+	// context of the instance. The host functions of gossamer are used if it is nil.
 	Resolver exec.ImportResolver
 }
 
 // Instance represents a v0.8 runtime life instance
 type Instance struct {
-	vm      *exec.VirtualMachine
-	mu      sync.Mutex
-	version runtime.Version
+	vm       *exec.VirtualMachine
+	ctx      *runtime.Context
+	resolver exec.ImportResolver
+	version  runtime.Version
+	codeHash common.Hash
+	isClosed bool
+	mu       sync.Mutex
 }
 
 // GetCodeHash returns code hash of the runtime
-func (*Instance) GetCodeHash() common.Hash {
-	return common.Hash{}
+func (in *Instance) GetCodeHash() common.Hash {
+	return in.codeHash
 }
 
 // NewRuntimeFromGenesis creates a runtime instance from the genesis data
@@ -66,6 +74,17 @@ func NewRuntimeFromGenesis(cfg *Config) (runtime.Instance, error) {
 	return NewInstance(code, cfg)
 }
 
+// NewInstanceFromTrie returns a new runtime instance with the code provided in the given trie
+func NewInstanceFromTrie(t *trie.Trie, cfg *Config) (*Instance, error) {
+	code := t.Get(common.CodeKey)
+	if len(code) == 0 {
+		return nil, fmt.Errorf("cannot find :code in trie")
+	}
+
+	cfg.Resolver = new(Resolver)
+	return NewInstance(code, cfg)
+}
+
 // NewInstanceFromFile instantiates a runtime from a .wasm file
 func NewInstanceFromFile(fp string, cfg *Config) (*Instance, error) {
 	// Reads the WebAssembly module as bytes.
@@ -81,90 +100,164 @@ func NewInstanceFromFile(fp string, cfg *Config) (*Instance, error) {
 	return NewInstance(bytes, cfg)
 }
 
-// NewInstance ...
+// NewInstance instantiates a runtime from raw wasm bytecode
 func NewInstance(code []byte, cfg *Config) (*Instance, error) {
 	if len(code) == 0 {
 		return nil, errors.New("code is empty")
 	}
 
+	code, err := runtime.DecompressWasm(code)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress WASM code: %w", err)
+	}
+
 	logger.Patch(log.SetLevel(cfg.LogLvl))
 
-	vmCfg := exec.VMConfig{
-		DefaultMemoryPages: 23,
+	runtimeCtx := &runtime.Context{
+		Storage:         cfg.Storage,
+		Keystore:        cfg.Keystore,
+		Validator:       cfg.Role == byte(4),
+		NodeStorage:     cfg.NodeStorage,
+		Network:         cfg.Network,
+		Transaction:     cfg.Transaction,
+		SigVerifier:     crypto.NewSignatureVerifier(logger),
+		OffchainHTTPSet: offchain.NewHTTPSet(),
 	}
 
-	instance, err := exec.NewVirtualMachine(code, vmCfg, cfg.Resolver, nil)
+	logger.Debugf("creating new runtime instance with context: %v", runtimeCtx)
+
+	inst := &Instance{
+		ctx:      runtimeCtx,
+		resolver: cfg.Resolver,
+		codeHash: cfg.CodeHash,
+	}
+
+	err = inst.setupVM(code)
 	if err != nil {
 		return nil, err
 	}
 
-	memory := &Memory{
-		memory: instance.Memory,
+	inst.version, err = inst.Version()
+	if err != nil {
+		logger.Errorf("error checking instance version: %s", err)
 	}
+	return inst, nil
+}
 
-	// TODO: use __heap_base (#1874)
-	allocator := runtime.NewAllocator(memory, 0)
+// setupVM creates the virtual machine of the code, with the memory described by the module. The memory
+// can grow by the number of heap pages set in the storage, up to the maximum of the module.
+func (in *Instance) setupVM(code []byte) error {
+	info, err := runtime.ParseModuleInfo(code)
+	if err != nil {
+		return fmt.Errorf("cannot parse WASM module: %w", err)
+	}
 
-	runtimeCtx := &runtime.Context{
-		Storage:     cfg.Storage,
-		Allocator:   allocator,
-		Keystore:    cfg.Keystore,
-		Validator:   cfg.Role == byte(4),
-		NodeStorage: cfg.NodeStorage,
-		Network:     cfg.Network,
-		Transaction: cfg.Transaction,
-		SigVerifier: crypto.NewSignatureVerifier(logger),
+	heapPages, err := runtime.HeapPages(in.ctx.Storage)
+	if err != nil {
+		return err
 	}
 
-	logger.Debugf("creating new runtime instance with context: %v", runtimeCtx)
+	maxPages, err := info.MemoryLimit(heapPages)
+	if err != nil {
+		return err
+	}
 
-	inst := &Instance{
-		vm: instance,
+	vmCfg := exec.VMConfig{
+		DefaultMemoryPages: int(info.MemoryMin),
+		MaxMemoryPages:     int(maxPages),
 	}
 
-	ctx = runtimeCtx
-	inst.version, err = inst.Version()
+	resolver := in.resolver
+	if _, ok := resolver.(*Resolver); ok || resolver == nil {
+		resolver = &Resolver{ctx: in.ctx}
+	}
+
+	vm, err := exec.NewVirtualMachine(code, vmCfg, resolver, nil)
 	if err != nil {
-		logger.Errorf("error checking instance version: %s", err)
+		return err
 	}
-	return inst, nil
+
+	memory := &Memory{
+		vm: vm,
+	}
+
+	in.vm = vm
+	in.ctx.Allocator = runtime.NewAllocator(runtime.NewLimitedMemory(memory, maxPages), info.HeapBase)
+	return nil
 }
 
 // Memory is a thin wrapper around life's memory to support
 // Gossamer runtime.Memory interface
 type Memory struct {
-	memory []byte
+	vm *exec.VirtualMachine
 }
 
 // Data returns the memory's data
 func (m *Memory) Data() []byte {
-	return m.memory
+	return m.vm.Memory
 }
 
 // Length returns the memory's length
 func (m *Memory) Length() uint32 {
-	return uint32(len(m.memory))
+	return uint32(len(m.vm.Memory))
 }
 
-// Grow ...
+// Grow grows the memory of the virtual machine, so that the runtime sees the grown memory
 func (m *Memory) Grow(numPages uint32) error {
-	m.memory = append(m.memory, make([]byte, runtime.PageSize*numPages)...)
+	m.vm.Memory = append(m.vm.Memory, make([]byte, runtime.PageSize*numPages)...)
 	return nil
 }
 
-// UpdateRuntimeCode ...
-func (*Instance) UpdateRuntimeCode(_ []byte) error {
-	return errors.New("unimplemented")
+// UpdateRuntimeCode updates the runtime instance to run the given code
+func (in *Instance) UpdateRuntimeCode(code []byte) error {
+	code, err := runtime.DecompressWasm(code)
+	if err != nil {
+		return fmt.Errorf("cannot decompress WASM code: %w", err)
+	}
+
+	in.mu.Lock()
+	err = in.setupVM(code)
+	in.isClosed = false
+	in.version = nil
+	in.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	in.version, err = in.Version()
+	return err
 }
 
-// CheckRuntimeVersion ...
-func (*Instance) CheckRuntimeVersion(_ []byte) (runtime.Version, error) {
-	return nil, errors.New("unimplemented")
+// CheckRuntimeVersion calculates runtime Version for runtime blob passed in
+func (in *Instance) CheckRuntimeVersion(code []byte) (runtime.Version, error) {
+	code, err := runtime.DecompressWasm(code)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decompress WASM code: %w", err)
+	}
+
+	in.mu.Lock()
+	// the context is copied, as setting up the temporary instance replaces its allocator
+	ctx := *in.ctx
+	in.mu.Unlock()
+
+	tmp := &Instance{
+		ctx:      &ctx,
+		resolver: in.resolver,
+	}
+
+	err = tmp.setupVM(code)
+	if err != nil {
+		return nil, err
+	}
+
+	return tmp.Version()
 }
 
 // SetContextStorage sets the runtime's storage. It should be set before calls to the below functions.
-func (*Instance) SetContextStorage(s runtime.Storage) {
-	ctx.Storage = s
+func (in *Instance) SetContextStorage(s runtime.Storage) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.ctx.Storage = s
 }
 
 // Exec calls the given function with the given data
@@ -172,22 +265,42 @@ func (in *Instance) Exec(function string, data []byte) ([]byte, error) {
 	in.mu.Lock()
 	defer in.mu.Unlock()
 
-	ptr, err := ctx.Allocator.Allocate(uint32(len(data)))
+	if in.ctx.Storage == nil {
+		return nil, runtime.ErrNilStorage
+	}
+
+	if in.isClosed {
+		return nil, errors.New("instance is stopped")
+	}
+
+	ptr, err := in.ctx.Allocator.Allocate(uint32(len(data)))
 	if err != nil {
 		return nil, err
 	}
-	defer ctx.Allocator.Clear()
+	defer in.ctx.Allocator.Clear()
+
+	// a signature verification batch left unfinished by a failed call is discarded
+	defer func() {
+		if in.ctx.SigVerifier.IsStarted() {
+			in.ctx.SigVerifier.Finish()
+		}
+	}()
+
+	// the sandboxed instances and memories only live for the duration of the call
+	in.ctx.Sandbox = newSandbox(in)
+	defer func() { in.ctx.Sandbox = nil }()
 
 	copy(in.vm.Memory[ptr:ptr+uint32(len(data))], data)
 
 	fnc, ok := in.vm.GetFunctionExport(function)
 	if !ok {
-		return nil, fmt.Errorf("could not find exported function %s", function)
+		return nil, fmt.Errorf("%w: %s", runtime.ErrExportFunctionNotFound, function)
 	}
 
 	ret, err := in.vm.Run(fnc, int64(ptr), int64(len(data)))
 	if err != nil {
-		fmt.Println(in.vm.StackTrace)
+		logger.Debugf("stack trace of failed call to %s: %s", function, in.vm.StackTrace)
+		resetVM(in.vm)
 		return nil, err
 	}
 
@@ -195,25 +308,37 @@ func (in *Instance) Exec(function string, data []byte) ([]byte, error) {
 	return in.vm.Memory[offset : offset+length], nil
 }
 
-// Stop ...
-func (*Instance) Stop() {}
+// resetVM clears the call stack left by a failed call, so that the virtual machine can run other calls
+func resetVM(vm *exec.VirtualMachine) {
+	vm.CurrentFrame = -1
+	vm.Delegate = nil
+	vm.ExitError = nil
+	vm.Exited = true
+}
+
+// Stop stops the instance, its calls fail afterwards
+func (in *Instance) Stop() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.isClosed = true
+}
 
 // NodeStorage to get reference to runtime node service
-func (*Instance) NodeStorage() runtime.NodeStorage {
-	return ctx.NodeStorage
+func (in *Instance) NodeStorage() runtime.NodeStorage {
+	return in.ctx.NodeStorage
 }
 
 // NetworkService to get referernce to runtime network service
-func (*Instance) NetworkService() runtime.BasicNetwork {
-	return ctx.Network
+func (in *Instance) NetworkService() runtime.BasicNetwork {
+	return in.ctx.Network
 }
 
 // Validator returns the context's Validator
-func (*Instance) Validator() bool {
-	return ctx.Validator
+func (in *Instance) Validator() bool {
+	return in.ctx.Validator
 }
 
 // Keystore to get reference to runtime keystore
-func (*Instance) Keystore() *keystore.GlobalKeystore {
-	return ctx.Keystore
+func (in *Instance) Keystore() *keystore.GlobalKeystore {
+	return in.ctx.Keystore
 }
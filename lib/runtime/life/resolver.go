@@ -9,6 +9,11 @@
 	"errors"
 	"fmt"
 	"math/big"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/ChainSafe/gossamer/internal/log"
 
 	"github.com/ChainSafe/gossamer/lib/common"
 	rtype "github.com/ChainSafe/gossamer/lib/common/types"
@@ -17,16 +22,37 @@
 	"github.com/ChainSafe/gossamer/lib/crypto/secp256k1"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
+	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/transaction"
 	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/pkg/scale"
 	"github.com/perlin-network/life/exec"
 )
 
-// Resolver resolves the imports for life
-type Resolver struct{} // TODO: move context inside resolver (#1875)
+// hostFunction is a host function, called with the context of the instance calling it
+type hostFunction func(ctx *runtime.Context, vm *exec.VirtualMachine) int64
+
+// Resolver resolves the imports for life. The host functions are called with the context of the resolver,
+// which is set by NewInstance, so a resolver must not be shared by several instances.
+type Resolver struct {
+	ctx *runtime.Context
+}
+
+// ResolveFunc resolves the imported host function, recording its calls if the context storage is traced
+func (r *Resolver) ResolveFunc(module, field string) exec.FunctionImport {
+	f := resolveFunc(module, field)
+	return func(vm *exec.VirtualMachine) int64 {
+		tracer := runtime.TracerOf(r.ctx.Storage)
+		if tracer != nil {
+			defer tracer.HostCall(field)()
+		}
+		return f(r.ctx, vm)
+	}
+}
 
-// ResolveFunc ...
-func (*Resolver) ResolveFunc(module, field string) exec.FunctionImport { //nolint:gocyclo
+func resolveFunc(module, field string) hostFunction { //nolint:gocyclo
 	switch module {
 	case "env":
 		switch field {
@@ -120,6 +146,86 @@ func (*Resolver) ResolveFunc(module, field string) exec.FunctionImport { //nolin
 			return ext_hashing_twox_256_version_1
 		case "ext_trie_blake2_256_root_version_1":
 			return ext_trie_blake2_256_root_version_1
+		case "ext_crypto_ecdsa_verify_version_2":
+			return ext_crypto_ecdsa_verify_version_2
+		case "ext_crypto_sr25519_verify_version_2":
+			return ext_crypto_sr25519_verify_version_2
+		case "ext_crypto_secp256k1_ecdsa_recover_version_2":
+			return ext_crypto_secp256k1_ecdsa_recover_version_2
+		case "ext_crypto_secp256k1_ecdsa_recover_compressed_version_1":
+			return ext_crypto_secp256k1_ecdsa_recover_compressed_version_1
+		case "ext_crypto_secp256k1_ecdsa_recover_compressed_version_2":
+			return ext_crypto_secp256k1_ecdsa_recover_compressed_version_2
+		case "ext_default_child_storage_storage_kill_version_2":
+			return ext_default_child_storage_storage_kill_version_2
+		case "ext_default_child_storage_storage_kill_version_3":
+			return ext_default_child_storage_storage_kill_version_3
+		case "ext_logging_max_level_version_1":
+			return ext_logging_max_level_version_1
+		case "ext_misc_print_num_version_1":
+			return ext_misc_print_num_version_1
+		case "ext_misc_runtime_version_version_1":
+			return ext_misc_runtime_version_version_1
+		case "ext_offchain_is_validator_version_1":
+			return ext_offchain_is_validator_version_1
+		case "ext_offchain_local_storage_clear_version_1":
+			return ext_offchain_local_storage_clear_version_1
+		case "ext_offchain_local_storage_compare_and_set_version_1":
+			return ext_offchain_local_storage_compare_and_set_version_1
+		case "ext_offchain_local_storage_get_version_1":
+			return ext_offchain_local_storage_get_version_1
+		case "ext_offchain_local_storage_set_version_1":
+			return ext_offchain_local_storage_set_version_1
+		case "ext_offchain_network_state_version_1":
+			return ext_offchain_network_state_version_1
+		case "ext_offchain_random_seed_version_1":
+			return ext_offchain_random_seed_version_1
+		case "ext_offchain_sleep_until_version_1":
+			return ext_offchain_sleep_until_version_1
+		case "ext_offchain_submit_transaction_version_1":
+			return ext_offchain_submit_transaction_version_1
+		case "ext_offchain_timestamp_version_1":
+			return ext_offchain_timestamp_version_1
+		case "ext_offchain_http_request_start_version_1":
+			return ext_offchain_http_request_start_version_1
+		case "ext_offchain_http_request_add_header_version_1":
+			return ext_offchain_http_request_add_header_version_1
+		case "ext_offchain_http_request_write_body_version_1":
+			return ext_offchain_http_request_write_body_version_1
+		case "ext_offchain_http_response_wait_version_1":
+			return ext_offchain_http_response_wait_version_1
+		case "ext_offchain_http_response_headers_version_1":
+			return ext_offchain_http_response_headers_version_1
+		case "ext_offchain_http_response_read_body_version_1":
+			return ext_offchain_http_response_read_body_version_1
+		case "ext_sandbox_instantiate_version_1":
+			return ext_sandbox_instantiate_version_1
+		case "ext_sandbox_instance_teardown_version_1":
+			return ext_sandbox_instance_teardown_version_1
+		case "ext_sandbox_invoke_version_1":
+			return ext_sandbox_invoke_version_1
+		case "ext_sandbox_memory_new_version_1":
+			return ext_sandbox_memory_new_version_1
+		case "ext_sandbox_memory_get_version_1":
+			return ext_sandbox_memory_get_version_1
+		case "ext_sandbox_memory_set_version_1":
+			return ext_sandbox_memory_set_version_1
+		case "ext_sandbox_memory_teardown_version_1":
+			return ext_sandbox_memory_teardown_version_1
+		case "ext_storage_clear_prefix_version_2":
+			return ext_storage_clear_prefix_version_2
+		case "ext_storage_start_transaction_version_1":
+			return ext_storage_start_transaction_version_1
+		case "ext_storage_rollback_transaction_version_1":
+			return ext_storage_rollback_transaction_version_1
+		case "ext_storage_commit_transaction_version_1":
+			return ext_storage_commit_transaction_version_1
+		case "ext_transaction_index_index_version_1":
+			return ext_transaction_index_index_version_1
+		case "ext_transaction_index_renew_version_1":
+			return ext_transaction_index_renew_version_1
+		case "ext_trie_blake2_256_verify_proof_version_1":
+			return ext_trie_blake2_256_verify_proof_version_1
 		default:
 			panic(fmt.Errorf("unknown import resolved: %s", field))
 		}
@@ -133,7 +239,7 @@ func (*Resolver) ResolveGlobal(_, _ string) int64 {
 	panic("we're not resolving global variables for now")
 }
 
-func ext_logging_log_version_1(vm *exec.VirtualMachine) int64 {
+func ext_logging_log_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	level := int32(vm.GetCurrentFrame().Locals[0])
 	targetData := vm.GetCurrentFrame().Locals[1]
@@ -160,7 +266,7 @@ func ext_logging_log_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_misc_print_utf8_version_1(vm *exec.VirtualMachine) int64 {
+func ext_misc_print_utf8_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	dataSpan := vm.GetCurrentFrame().Locals[0]
 	data := asMemorySlice(vm.Memory, dataSpan)
@@ -168,7 +274,7 @@ func ext_misc_print_utf8_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_misc_print_hex_version_1(vm *exec.VirtualMachine) int64 {
+func ext_misc_print_hex_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	dataSpan := vm.GetCurrentFrame().Locals[0]
 	data := asMemorySlice(vm.Memory, dataSpan)
@@ -176,7 +282,7 @@ func ext_misc_print_hex_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_allocator_malloc_version_1(vm *exec.VirtualMachine) int64 {
+func ext_allocator_malloc_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	size := uint32(vm.GetCurrentFrame().Locals[0])
 	logger.Tracef("executing with size %d...", size)
 
@@ -190,7 +296,7 @@ func ext_allocator_malloc_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(res)
 }
 
-func ext_allocator_free_version_1(vm *exec.VirtualMachine) int64 {
+func ext_allocator_free_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	addr := uint32(vm.GetCurrentFrame().Locals[0])
 	logger.Tracef("executing at address %d...", addr)
 
@@ -204,7 +310,7 @@ func ext_allocator_free_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_hashing_blake2_256_version_1(vm *exec.VirtualMachine) int64 {
+func ext_hashing_blake2_256_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	dataSpan := vm.GetCurrentFrame().Locals[0]
 
@@ -218,7 +324,7 @@ func ext_hashing_blake2_256_version_1(vm *exec.VirtualMachine) int64 {
 
 	logger.Debugf("data is 0x%x and hash is 0x%x", data, hash)
 
-	out, err := toWasmMemorySized(vm.Memory, hash[:], 32)
+	out, err := toWasmMemorySized(ctx, vm, hash[:], 32)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -227,7 +333,7 @@ func ext_hashing_blake2_256_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(out)
 }
 
-func ext_hashing_twox_128_version_1(vm *exec.VirtualMachine) int64 {
+func ext_hashing_twox_128_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	dataSpan := vm.GetCurrentFrame().Locals[0]
 	data := asMemorySlice(vm.Memory, dataSpan)
@@ -240,7 +346,7 @@ func ext_hashing_twox_128_version_1(vm *exec.VirtualMachine) int64 {
 
 	logger.Debugf("data is 0x%x and hash is 0x%x", data, hash)
 
-	out, err := toWasmMemorySized(vm.Memory, hash, 16)
+	out, err := toWasmMemorySized(ctx, vm, hash, 16)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -249,7 +355,7 @@ func ext_hashing_twox_128_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(out)
 }
 
-func ext_hashing_twox_64_version_1(vm *exec.VirtualMachine) int64 {
+func ext_hashing_twox_64_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	dataSpan := vm.GetCurrentFrame().Locals[0]
 	data := asMemorySlice(vm.Memory, dataSpan)
@@ -262,7 +368,7 @@ func ext_hashing_twox_64_version_1(vm *exec.VirtualMachine) int64 {
 
 	logger.Debugf("data is 0x%x and hash is 0x%x", data, hash)
 
-	out, err := toWasmMemorySized(vm.Memory, hash, 8)
+	out, err := toWasmMemorySized(ctx, vm, hash, 8)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -271,7 +377,7 @@ func ext_hashing_twox_64_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(out)
 }
 
-func ext_storage_get_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_get_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	keySpan := vm.GetCurrentFrame().Locals[0]
 	storage := ctx.Storage
@@ -282,17 +388,17 @@ func ext_storage_get_version_1(vm *exec.VirtualMachine) int64 {
 	value := storage.Get(key)
 	logger.Debugf("value: 0x%x", value)
 
-	valueSpan, err := toWasmMemoryOptional(vm.Memory, value)
+	valueSpan, err := toWasmMemoryOptional(ctx, vm, value)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
-		ptr, _ := toWasmMemoryOptional(vm.Memory, nil)
+		ptr, _ := toWasmMemoryOptional(ctx, vm, nil)
 		return ptr
 	}
 
 	return valueSpan
 }
 
-func ext_storage_set_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_set_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	keySpan := vm.GetCurrentFrame().Locals[0]
 	valueSpan := vm.GetCurrentFrame().Locals[1]
@@ -309,7 +415,7 @@ func ext_storage_set_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_storage_next_key_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_next_key_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	keySpan := vm.GetCurrentFrame().Locals[0]
 	storage := ctx.Storage
@@ -319,7 +425,7 @@ func ext_storage_next_key_version_1(vm *exec.VirtualMachine) int64 {
 	next := storage.NextKey(key)
 	logger.Debugf("key is 0x%x and next is 0x%x", key, next)
 
-	nextSpan, err := toWasmMemoryOptional(vm.Memory, next)
+	nextSpan, err := toWasmMemoryOptional(ctx, vm, next)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -328,7 +434,7 @@ func ext_storage_next_key_version_1(vm *exec.VirtualMachine) int64 {
 	return nextSpan
 }
 
-func ext_storage_clear_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_clear_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	keySpan := vm.GetCurrentFrame().Locals[0]
 	storage := ctx.Storage
@@ -340,7 +446,7 @@ func ext_storage_clear_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_storage_clear_prefix_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_clear_prefix_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	storage := ctx.Storage
 	prefixSpan := vm.GetCurrentFrame().Locals[0]
@@ -362,7 +468,7 @@ func ext_storage_clear_prefix_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_storage_exists_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_exists_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	keySpan := vm.GetCurrentFrame().Locals[0]
 	storage := ctx.Storage
@@ -377,7 +483,7 @@ func ext_storage_exists_version_1(vm *exec.VirtualMachine) int64 {
 	return 1
 }
 
-func ext_storage_read_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_read_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	keySpan := vm.GetCurrentFrame().Locals[0]
 	valueOut := vm.GetCurrentFrame().Locals[1]
@@ -390,7 +496,7 @@ func ext_storage_read_version_1(vm *exec.VirtualMachine) int64 {
 	logger.Debugf("key 0x%x and value 0x%x", key, value)
 
 	if value == nil {
-		ret, _ := toWasmMemoryOptional(memory, nil)
+		ret, _ := toWasmMemoryOptional(ctx, vm, nil)
 		return ret
 	}
 
@@ -404,7 +510,7 @@ func ext_storage_read_version_1(vm *exec.VirtualMachine) int64 {
 		copy(memory[valueBuf:valueBuf+valueLen], value[offset:])
 	}
 
-	sizeSpan, err := toWasmMemoryOptionalUint32(memory, &size)
+	sizeSpan, err := toWasmMemoryOptionalUint32(ctx, vm, &size)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -457,7 +563,7 @@ func storageAppend(storage runtime.Storage, key, valueToAppend []byte) error {
 	return nil
 }
 
-func ext_storage_append_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_append_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	storage := ctx.Storage
 	keySpan := vm.GetCurrentFrame().Locals[0]
@@ -475,7 +581,7 @@ func ext_storage_append_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_trie_blake2_256_ordered_root_version_1(vm *exec.VirtualMachine) int64 {
+func ext_trie_blake2_256_ordered_root_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	dataSpan := vm.GetCurrentFrame().Locals[0]
 	memory := vm.Memory
@@ -518,7 +624,7 @@ func ext_trie_blake2_256_ordered_root_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(ptr)
 }
 
-func ext_storage_root_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_root_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	storage := ctx.Storage
 
@@ -530,7 +636,7 @@ func ext_storage_root_version_1(vm *exec.VirtualMachine) int64 {
 
 	logger.Debugf("root hash: %s", root)
 
-	rootSpan, err := toWasmMemory(vm.Memory, root[:])
+	rootSpan, err := toWasmMemory(ctx, vm, root[:])
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -539,11 +645,11 @@ func ext_storage_root_version_1(vm *exec.VirtualMachine) int64 {
 	return rootSpan
 }
 
-func ext_storage_changes_root_version_1(vm *exec.VirtualMachine) int64 {
+func ext_storage_changes_root_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	logger.Debug("returning None")
 
-	rootSpan, err := toWasmMemoryOptional(vm.Memory, nil)
+	rootSpan, err := toWasmMemoryOptional(ctx, vm, nil)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -552,22 +658,55 @@ func ext_storage_changes_root_version_1(vm *exec.VirtualMachine) int64 {
 	return rootSpan
 }
 
-func ext_crypto_start_batch_verify_version_1(_ *exec.VirtualMachine) int64 {
+func ext_crypto_start_batch_verify_version_1(ctx *runtime.Context, _ *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
+	sigVerifier := ctx.SigVerifier
+
+	if sigVerifier.IsStarted() {
+		logger.Error("signature verification batch is already started")
+		return 0
+	}
+
+	sigVerifier.Start()
 	return 0
 }
 
-func ext_crypto_finish_batch_verify_version_1(_ *exec.VirtualMachine) int64 {
+func ext_crypto_finish_batch_verify_version_1(ctx *runtime.Context, _ *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
+	sigVerifier := ctx.SigVerifier
+
+	if !sigVerifier.IsStarted() {
+		logger.Error("finish_batch_verify called without start_batch_verify")
+		return 0
+	}
+
+	if !sigVerifier.Finish() {
+		logger.Debug("failed to verify signatures of batch")
+		return 0
+	}
+
 	return 1
 }
 
-func ext_offchain_index_set_version_1(_ *exec.VirtualMachine) int64 {
+func ext_offchain_index_set_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
+	keySpan := vm.GetCurrentFrame().Locals[0]
+	valueSpan := vm.GetCurrentFrame().Locals[1]
+
+	storageKey := asMemorySlice(vm.Memory, keySpan)
+	newValue := asMemorySlice(vm.Memory, valueSpan)
+	cp := make([]byte, len(newValue))
+	copy(cp, newValue)
+
+	err := ctx.NodeStorage.BaseDB.Put(storageKey, cp)
+	if err != nil {
+		logger.Errorf("failed to set value in raw storage: %s", err)
+	}
+
 	return 0
 }
 
-func ext_default_child_storage_set_version_1(vm *exec.VirtualMachine) int64 {
+func ext_default_child_storage_set_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 	storage := ctx.Storage
 	memory := vm.Memory
@@ -591,7 +730,7 @@ func ext_default_child_storage_set_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_default_child_storage_get_version_1(vm *exec.VirtualMachine) int64 {
+func ext_default_child_storage_get_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	childStorageKey := vm.GetCurrentFrame().Locals[0]
@@ -605,7 +744,7 @@ func ext_default_child_storage_get_version_1(vm *exec.VirtualMachine) int64 {
 		return 0
 	}
 
-	value, err := toWasmMemoryOptional(memory, child)
+	value, err := toWasmMemoryOptional(ctx, vm, child)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -614,7 +753,7 @@ func ext_default_child_storage_get_version_1(vm *exec.VirtualMachine) int64 {
 	return value
 }
 
-func ext_default_child_storage_read_version_1(vm *exec.VirtualMachine) int64 {
+func ext_default_child_storage_read_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	childStorageKey := vm.GetCurrentFrame().Locals[0]
@@ -637,7 +776,7 @@ func ext_default_child_storage_read_version_1(vm *exec.VirtualMachine) int64 {
 	sizeBuf := make([]byte, 4)
 	binary.LittleEndian.PutUint32(sizeBuf, size)
 
-	sizeSpan, err := toWasmMemoryOptional(memory, sizeBuf)
+	sizeSpan, err := toWasmMemoryOptional(ctx, vm, sizeBuf)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -646,7 +785,7 @@ func ext_default_child_storage_read_version_1(vm *exec.VirtualMachine) int64 {
 	return sizeSpan
 }
 
-func ext_default_child_storage_clear_version_1(vm *exec.VirtualMachine) int64 {
+func ext_default_child_storage_clear_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Debug("executing...")
 
 	childStorageKey := vm.GetCurrentFrame().Locals[0]
@@ -664,7 +803,7 @@ func ext_default_child_storage_clear_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_default_child_storage_storage_kill_version_1(vm *exec.VirtualMachine) int64 {
+func ext_default_child_storage_storage_kill_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	childStorageKeySpan := vm.GetCurrentFrame().Locals[0]
@@ -676,7 +815,7 @@ func ext_default_child_storage_storage_kill_version_1(vm *exec.VirtualMachine) i
 	return 0
 }
 
-func ext_default_child_storage_exists_version_1(vm *exec.VirtualMachine) int64 {
+func ext_default_child_storage_exists_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	childStorageKey := vm.GetCurrentFrame().Locals[0]
@@ -695,7 +834,7 @@ func ext_default_child_storage_exists_version_1(vm *exec.VirtualMachine) int64 {
 	return 0
 }
 
-func ext_default_child_storage_clear_prefix_version_1(vm *exec.VirtualMachine) int64 {
+func ext_default_child_storage_clear_prefix_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	childStorageKey := vm.GetCurrentFrame().Locals[0]
@@ -713,7 +852,7 @@ func ext_default_child_storage_clear_prefix_version_1(vm *exec.VirtualMachine) i
 	return 0
 }
 
-func ext_default_child_storage_root_version_1(vm *exec.VirtualMachine) int64 {
+func ext_default_child_storage_root_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	childStorageKey := vm.GetCurrentFrame().Locals[0]
@@ -732,7 +871,7 @@ func ext_default_child_storage_root_version_1(vm *exec.VirtualMachine) int64 {
 		return 0
 	}
 
-	root, err := toWasmMemoryOptional(memory, childRoot[:])
+	root, err := toWasmMemoryOptional(ctx, vm, childRoot[:])
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -741,7 +880,7 @@ func ext_default_child_storage_root_version_1(vm *exec.VirtualMachine) int64 {
 	return root
 }
 
-func ext_default_child_storage_next_key_version_1(vm *exec.VirtualMachine) int64 {
+func ext_default_child_storage_next_key_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	childStorageKey := vm.GetCurrentFrame().Locals[0]
@@ -755,7 +894,7 @@ func ext_default_child_storage_next_key_version_1(vm *exec.VirtualMachine) int64
 		return 0
 	}
 
-	value, err := toWasmMemoryOptional(memory, child)
+	value, err := toWasmMemoryOptional(ctx, vm, child)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -764,7 +903,7 @@ func ext_default_child_storage_next_key_version_1(vm *exec.VirtualMachine) int64
 	return value
 }
 
-func ext_crypto_ed25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
+func ext_crypto_ed25519_public_keys_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	keyTypeID := vm.GetCurrentFrame().Locals[0]
@@ -775,7 +914,7 @@ func ext_crypto_ed25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
 	ks, err := ctx.Keystore.GetKeystore(id)
 	if err != nil {
 		logger.Warnf("error for id 0x%x: %s", id, err)
-		ret, _ := toWasmMemory(memory, []byte{0})
+		ret, _ := toWasmMemory(ctx, vm, []byte{0})
 		return ret
 	}
 
@@ -783,7 +922,7 @@ func ext_crypto_ed25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
 		logger.Warnf(
 			"keystore type for id 0x%x is %s and not the expected ed25519",
 			id, ks.Type())
-		ret, _ := toWasmMemory(memory, []byte{0})
+		ret, _ := toWasmMemory(ctx, vm, []byte{0})
 		return ret
 	}
 
@@ -797,21 +936,21 @@ func ext_crypto_ed25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
 	prefix, err := scale.Marshal(big.NewInt(int64(len(keys))))
 	if err != nil {
 		logger.Errorf("failed to allocate memory: %s", err)
-		ret, _ := toWasmMemory(memory, []byte{0})
+		ret, _ := toWasmMemory(ctx, vm, []byte{0})
 		return ret
 	}
 
-	ret, err := toWasmMemory(memory, append(prefix, encodedKeys...))
+	ret, err := toWasmMemory(ctx, vm, append(prefix, encodedKeys...))
 	if err != nil {
 		logger.Errorf("failed to allocate memory: %s", err)
-		ret, _ = toWasmMemory(memory, []byte{0})
+		ret, _ = toWasmMemory(ctx, vm, []byte{0})
 		return ret
 	}
 
 	return ret
 }
 
-func ext_crypto_ed25519_generate_version_1(vm *exec.VirtualMachine) int64 {
+func ext_crypto_ed25519_generate_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	keyTypeID := vm.GetCurrentFrame().Locals[0]
@@ -853,7 +992,7 @@ func ext_crypto_ed25519_generate_version_1(vm *exec.VirtualMachine) int64 {
 		return 0
 	}
 
-	ret, err := toWasmMemorySized(memory, kp.Public().Encode(), 32)
+	ret, err := toWasmMemorySized(ctx, vm, kp.Public().Encode(), 32)
 	if err != nil {
 		logger.Warnf("failed to allocate memory: %s", err)
 		return 0
@@ -865,7 +1004,7 @@ func ext_crypto_ed25519_generate_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(ret)
 }
 
-func ext_crypto_ed25519_sign_version_1(vm *exec.VirtualMachine) int64 {
+func ext_crypto_ed25519_sign_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	keyTypeID := vm.GetCurrentFrame().Locals[0]
@@ -885,7 +1024,7 @@ func ext_crypto_ed25519_sign_version_1(vm *exec.VirtualMachine) int64 {
 	ks, err := ctx.Keystore.GetKeystore(id)
 	if err != nil {
 		logger.Warnf("error for id 0x%x: %s", id, err)
-		ret, _ := toWasmMemoryOptional(memory, nil)
+		ret, _ := toWasmMemoryOptional(ctx, vm, nil)
 		return ret
 	}
 
@@ -893,7 +1032,7 @@ func ext_crypto_ed25519_sign_version_1(vm *exec.VirtualMachine) int64 {
 	signingKey := ks.GetKeypair(pubKey)
 	if signingKey == nil {
 		logger.Error("could not find public key " + pubKey.Hex() + " in keystore")
-		ret, err = toWasmMemoryOptional(memory, nil)
+		ret, err = toWasmMemoryOptional(ctx, vm, nil)
 		if err != nil {
 			logger.Errorf("failed to allocate memory: %s", err)
 			return 0
@@ -906,7 +1045,7 @@ func ext_crypto_ed25519_sign_version_1(vm *exec.VirtualMachine) int64 {
 		logger.Error("could not sign message")
 	}
 
-	ret, err = toWasmMemoryFixedSizeOptional(memory, sig)
+	ret, err = toWasmMemoryFixedSizeOptional(ctx, vm, sig)
 	if err != nil {
 		logger.Errorf("failed to allocate memory: %s", err)
 		return 0
@@ -915,7 +1054,7 @@ func ext_crypto_ed25519_sign_version_1(vm *exec.VirtualMachine) int64 {
 	return ret
 }
 
-func ext_crypto_ed25519_verify_version_1(vm *exec.VirtualMachine) int64 {
+func ext_crypto_ed25519_verify_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	sig := vm.GetCurrentFrame().Locals[0]
@@ -954,7 +1093,7 @@ func ext_crypto_ed25519_verify_version_1(vm *exec.VirtualMachine) int64 {
 	return 1
 }
 
-func ext_crypto_sr25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
+func ext_crypto_sr25519_public_keys_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	keyTypeID := vm.GetCurrentFrame().Locals[0]
@@ -965,7 +1104,7 @@ func ext_crypto_sr25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
 	ks, err := ctx.Keystore.GetKeystore(id)
 	if err != nil {
 		logger.Warnf("error for id 0x%x: %s", id, err)
-		ret, _ := toWasmMemory(memory, []byte{0})
+		ret, _ := toWasmMemory(ctx, vm, []byte{0})
 		return ret
 	}
 
@@ -973,7 +1112,7 @@ func ext_crypto_sr25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
 		logger.Warnf(
 			"keystore type for id 0x%x is %s and not the expected sr25519",
 			id, ks.Type())
-		ret, _ := toWasmMemory(memory, []byte{0})
+		ret, _ := toWasmMemory(ctx, vm, []byte{0})
 		return ret
 	}
 
@@ -987,21 +1126,21 @@ func ext_crypto_sr25519_public_keys_version_1(vm *exec.VirtualMachine) int64 {
 	prefix, err := scale.Marshal(big.NewInt(int64(len(keys))))
 	if err != nil {
 		logger.Errorf("failed to allocate memory: %s", err)
-		ret, _ := toWasmMemory(memory, []byte{0})
+		ret, _ := toWasmMemory(ctx, vm, []byte{0})
 		return ret
 	}
 
-	ret, err := toWasmMemory(memory, append(prefix, encodedKeys...))
+	ret, err := toWasmMemory(ctx, vm, append(prefix, encodedKeys...))
 	if err != nil {
 		logger.Errorf("failed to allocate memory: %s", err)
-		ret, _ = toWasmMemory(memory, []byte{0})
+		ret, _ = toWasmMemory(ctx, vm, []byte{0})
 		return ret
 	}
 
 	return ret
 }
 
-func ext_crypto_sr25519_generate_version_1(vm *exec.VirtualMachine) int64 {
+func ext_crypto_sr25519_generate_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	keyTypeID := vm.GetCurrentFrame().Locals[0]
@@ -1042,7 +1181,7 @@ func ext_crypto_sr25519_generate_version_1(vm *exec.VirtualMachine) int64 {
 		return 0
 	}
 
-	ret, err := toWasmMemorySized(memory, kp.Public().Encode(), 32)
+	ret, err := toWasmMemorySized(ctx, vm, kp.Public().Encode(), 32)
 	if err != nil {
 		logger.Errorf("failed to allocate memory: %s", err)
 		return 0
@@ -1054,7 +1193,7 @@ func ext_crypto_sr25519_generate_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(ret)
 }
 
-func ext_crypto_sr25519_sign_version_1(vm *exec.VirtualMachine) int64 {
+func ext_crypto_sr25519_sign_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	keyTypeID := vm.GetCurrentFrame().Locals[0]
@@ -1062,7 +1201,7 @@ func ext_crypto_sr25519_sign_version_1(vm *exec.VirtualMachine) int64 {
 	msg := vm.GetCurrentFrame().Locals[2]
 	memory := vm.Memory
 
-	emptyRet, _ := toWasmMemoryOptional(memory, nil)
+	emptyRet, _ := toWasmMemoryOptional(ctx, vm, nil)
 
 	id := memory[keyTypeID : keyTypeID+4]
 
@@ -1092,7 +1231,7 @@ func ext_crypto_sr25519_sign_version_1(vm *exec.VirtualMachine) int64 {
 		return emptyRet
 	}
 
-	ret, err = toWasmMemoryFixedSizeOptional(memory, sig)
+	ret, err = toWasmMemoryFixedSizeOptional(ctx, vm, sig)
 	if err != nil {
 		logger.Errorf("failed to allocate memory: %s", err)
 		return emptyRet
@@ -1101,14 +1240,13 @@ func ext_crypto_sr25519_sign_version_1(vm *exec.VirtualMachine) int64 {
 	return ret
 }
 
-func ext_crypto_sr25519_verify_version_1(vm *exec.VirtualMachine) int64 {
+func ext_crypto_sr25519_verify_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	sig := vm.GetCurrentFrame().Locals[0]
 	msg := vm.GetCurrentFrame().Locals[1]
 	key := vm.GetCurrentFrame().Locals[2]
 	memory := vm.Memory
-	sigVerifier := ctx.SigVerifier
 
 	message := asMemorySlice(memory, msg)
 	signature := memory[sig : sig+64]
@@ -1123,17 +1261,7 @@ func ext_crypto_sr25519_verify_version_1(vm *exec.VirtualMachine) int64 {
 		"pub=%s; message=0x%x; signature=0x%x",
 		pub.Hex(), message, signature)
 
-	if sigVerifier.IsStarted() {
-		signature := crypto.SignatureInfo{
-			PubKey:     pub.Encode(),
-			Sign:       signature,
-			Msg:        message,
-			VerifyFunc: sr25519.VerifySignature,
-		}
-		sigVerifier.Add(&signature)
-		return 1
-	}
-
+	// the signature is not added to the verification batch, as the deprecated verification never fails
 	if ok, err := pub.VerifyDeprecated(message, signature); err != nil || !ok {
 		logger.Debugf("failed to validate signature: %s", err)
 		// this fails at block 3876, however based on discussions this seems to be expected
@@ -1144,7 +1272,7 @@ func ext_crypto_sr25519_verify_version_1(vm *exec.VirtualMachine) int64 {
 	return 1
 }
 
-func ext_crypto_secp256k1_ecdsa_recover_version_1(vm *exec.VirtualMachine) int64 {
+func ext_crypto_secp256k1_ecdsa_recover_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	sig := vm.GetCurrentFrame().Locals[0]
@@ -1169,7 +1297,7 @@ func ext_crypto_secp256k1_ecdsa_recover_version_1(vm *exec.VirtualMachine) int64
 	if err != nil {
 		logger.Errorf("failed to recover public key: %s", err)
 		var ret int64
-		ret, err = toWasmMemoryResult(memory, nil)
+		ret, err = toWasmMemoryResult(ctx, vm, nil)
 		if err != nil {
 			logger.Errorf("failed to allocate memory: %s", err)
 			return 0
@@ -1181,7 +1309,7 @@ func ext_crypto_secp256k1_ecdsa_recover_version_1(vm *exec.VirtualMachine) int64
 		"recovered public key of length %d: 0x%x",
 		len(pub), pub)
 
-	ret, err := toWasmMemoryResult(memory, pub[1:])
+	ret, err := toWasmMemoryResult(ctx, vm, pub[1:])
 	if err != nil {
 		logger.Errorf("failed to allocate memory: %s", err)
 		return 0
@@ -1190,7 +1318,7 @@ func ext_crypto_secp256k1_ecdsa_recover_version_1(vm *exec.VirtualMachine) int64
 	return ret
 }
 
-func ext_hashing_keccak_256_version_1(vm *exec.VirtualMachine) int64 {
+func ext_hashing_keccak_256_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	dataSpan := vm.GetCurrentFrame().Locals[0]
@@ -1206,7 +1334,7 @@ func ext_hashing_keccak_256_version_1(vm *exec.VirtualMachine) int64 {
 
 	logger.Debugf("data 0x%x has hash %s", data, hash)
 
-	out, err := toWasmMemorySized(memory, hash[:], 32)
+	out, err := toWasmMemorySized(ctx, vm, hash[:], 32)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -1215,7 +1343,7 @@ func ext_hashing_keccak_256_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(out)
 }
 
-func ext_hashing_sha2_256_version_1(vm *exec.VirtualMachine) int64 {
+func ext_hashing_sha2_256_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	dataSpan := vm.GetCurrentFrame().Locals[0]
@@ -1226,7 +1354,7 @@ func ext_hashing_sha2_256_version_1(vm *exec.VirtualMachine) int64 {
 
 	logger.Debugf("data 0x%x hash hash %x", data, hash)
 
-	out, err := toWasmMemorySized(memory, hash[:], 32)
+	out, err := toWasmMemorySized(ctx, vm, hash[:], 32)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -1235,7 +1363,7 @@ func ext_hashing_sha2_256_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(out)
 }
 
-func ext_hashing_blake2_128_version_1(vm *exec.VirtualMachine) int64 {
+func ext_hashing_blake2_128_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	dataSpan := vm.GetCurrentFrame().Locals[0]
@@ -1251,7 +1379,7 @@ func ext_hashing_blake2_128_version_1(vm *exec.VirtualMachine) int64 {
 
 	logger.Debugf("data 0x%x has hash 0x%x", data, hash)
 
-	out, err := toWasmMemorySized(memory, hash, 16)
+	out, err := toWasmMemorySized(ctx, vm, hash, 16)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -1260,7 +1388,7 @@ func ext_hashing_blake2_128_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(out)
 }
 
-func ext_hashing_twox_256_version_1(vm *exec.VirtualMachine) int64 {
+func ext_hashing_twox_256_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Trace("executing...")
 
 	dataSpan := vm.GetCurrentFrame().Locals[0]
@@ -1276,7 +1404,7 @@ func ext_hashing_twox_256_version_1(vm *exec.VirtualMachine) int64 {
 
 	logger.Debugf("data 0x%x has hash %s", data, hash)
 
-	out, err := toWasmMemorySized(memory, hash[:], 32)
+	out, err := toWasmMemorySized(ctx, vm, hash[:], 32)
 	if err != nil {
 		logger.Errorf("failed to allocate: %s", err)
 		return 0
@@ -1285,7 +1413,7 @@ func ext_hashing_twox_256_version_1(vm *exec.VirtualMachine) int64 {
 	return int64(out)
 }
 
-func ext_trie_blake2_256_root_version_1(vm *exec.VirtualMachine) int64 {
+func ext_trie_blake2_256_root_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
 	logger.Debug("executing...")
 
 	dataSpan := vm.GetCurrentFrame().Locals[0]
@@ -1328,6 +1456,959 @@ type kv struct {
 	return int64(ptr)
 }
 
+func ext_crypto_ecdsa_verify_version_2(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+
+	sig := vm.GetCurrentFrame().Locals[0]
+	msg := vm.GetCurrentFrame().Locals[1]
+	key := vm.GetCurrentFrame().Locals[2]
+	memory := vm.Memory
+	sigVerifier := ctx.SigVerifier
+
+	message := asMemorySlice(memory, msg)
+	signature := memory[sig : sig+64]
+	pubKey := memory[key : key+33]
+
+	pub := new(secp256k1.PublicKey)
+	err := pub.Decode(pubKey)
+	if err != nil {
+		logger.Errorf("failed to decode public key: %s", err)
+		return 0
+	}
+
+	logger.Debugf("pub=%s, message=0x%x, signature=0x%x", pub.Hex(), message, signature)
+
+	hash, err := common.Blake2bHash(message)
+	if err != nil {
+		logger.Errorf("failed to hash message: %s", err)
+		return 0
+	}
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pub.Encode(),
+			Sign:       signature,
+			Msg:        hash[:],
+			VerifyFunc: secp256k1.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return 1
+	}
+
+	if ok, err := pub.Verify(hash[:], signature); err != nil || !ok {
+		logger.Errorf("failed to validate signature: %s", err)
+		return 0
+	}
+
+	logger.Debug("validated signature")
+	return 1
+}
+
+func ext_crypto_sr25519_verify_version_2(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+
+	sig := vm.GetCurrentFrame().Locals[0]
+	msg := vm.GetCurrentFrame().Locals[1]
+	key := vm.GetCurrentFrame().Locals[2]
+	memory := vm.Memory
+	sigVerifier := ctx.SigVerifier
+
+	message := asMemorySlice(memory, msg)
+	signature := memory[sig : sig+64]
+
+	pub, err := sr25519.NewPublicKey(memory[key : key+32])
+	if err != nil {
+		logger.Error("invalid sr25519 public key")
+		return 0
+	}
+
+	logger.Debugf(
+		"pub=%s; message=0x%x; signature=0x%x",
+		pub.Hex(), message, signature)
+
+	if sigVerifier.IsStarted() {
+		signature := crypto.SignatureInfo{
+			PubKey:     pub.Encode(),
+			Sign:       signature,
+			Msg:        message,
+			VerifyFunc: sr25519.VerifySignature,
+		}
+		sigVerifier.Add(&signature)
+		return 1
+	}
+
+	if ok, err := pub.Verify(message, signature); err != nil || !ok {
+		logger.Errorf("failed to validate signature: %s", err)
+		return 0
+	}
+
+	logger.Debug("validated signature")
+	return 1
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_version_2(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	return ext_crypto_secp256k1_ecdsa_recover_version_1(ctx, vm)
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+
+	sig := vm.GetCurrentFrame().Locals[0]
+	msg := vm.GetCurrentFrame().Locals[1]
+	memory := vm.Memory
+
+	// msg must be the 32-byte hash of the message to be signed.
+	// sig must be a 65-byte compact ECDSA signature containing the
+	// recovery id as the last element
+	message := memory[msg : msg+32]
+	signature := memory[sig : sig+65]
+
+	cpub, err := secp256k1.RecoverPublicKeyCompressed(message, signature)
+	if err != nil {
+		logger.Errorf("failed to recover public key: %s", err)
+		ret, _ := toWasmMemoryResult(ctx, vm, nil)
+		return ret
+	}
+
+	logger.Debugf("recovered public key of length %d: 0x%x", len(cpub), cpub)
+
+	ret, err := toWasmMemoryResult(ctx, vm, cpub)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return ret
+}
+
+func ext_crypto_secp256k1_ecdsa_recover_compressed_version_2(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	return ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(ctx, vm)
+}
+
+func ext_default_child_storage_storage_kill_version_2(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+
+	childStorageKeySpan := vm.GetCurrentFrame().Locals[0]
+	lim := vm.GetCurrentFrame().Locals[1]
+	storage := ctx.Storage
+
+	childStorageKey := asMemorySlice(vm.Memory, childStorageKeySpan)
+	limitBytes := asMemorySlice(vm.Memory, lim)
+
+	var limit *[]byte
+	err := scale.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		logger.Warnf("cannot generate limit: %s", err)
+		return 0
+	}
+
+	_, all, err := storage.DeleteChildLimit(childStorageKey, limit)
+	if err != nil {
+		logger.Warnf("cannot get child storage: %s", err)
+	}
+
+	if all {
+		return 1
+	}
+
+	return 0
+}
+
+type noneRemain uint32
+type someRemain uint32
+
+func (noneRemain) Index() uint {
+	return 0
+}
+func (someRemain) Index() uint {
+	return 1
+}
+
+func ext_default_child_storage_storage_kill_version_3(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+
+	childStorageKeySpan := vm.GetCurrentFrame().Locals[0]
+	lim := vm.GetCurrentFrame().Locals[1]
+	storage := ctx.Storage
+
+	childStorageKey := asMemorySlice(vm.Memory, childStorageKeySpan)
+	limitBytes := asMemorySlice(vm.Memory, lim)
+
+	var limit *[]byte
+	err := scale.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		logger.Warnf("cannot generate limit: %s", err)
+	}
+
+	deleted, all, err := storage.DeleteChildLimit(childStorageKey, limit)
+	if err != nil {
+		logger.Warnf("cannot get child storage: %s", err)
+		return 0
+	}
+
+	vdt, err := scale.NewVaryingDataType(noneRemain(0), someRemain(0))
+	if err != nil {
+		logger.Warnf("cannot create new varying data type: %s", err)
+	}
+
+	if all {
+		err = vdt.Set(noneRemain(deleted))
+	} else {
+		err = vdt.Set(someRemain(deleted))
+	}
+	if err != nil {
+		logger.Warnf("cannot set varying data type: %s", err)
+		return 0
+	}
+
+	encoded, err := scale.Marshal(vdt)
+	if err != nil {
+		logger.Warnf("problem marshaling varying data type: %s", err)
+		return 0
+	}
+
+	out, err := toWasmMemoryOptional(ctx, vm, encoded)
+	if err != nil {
+		logger.Warnf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return out
+}
+
+func ext_logging_max_level_version_1(_ *runtime.Context, _ *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	return 4
+}
+
+func ext_misc_print_num_version_1(_ *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	data := vm.GetCurrentFrame().Locals[0]
+	logger.Debugf("num: %d", data)
+	return 0
+}
+
+func ext_misc_runtime_version_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	dataSpan := vm.GetCurrentFrame().Locals[0]
+	data := asMemorySlice(vm.Memory, dataSpan)
+
+	cfg := &Config{
+		Resolver: new(Resolver),
+	}
+	cfg.LogLvl = log.DoNotChange
+	cfg.Storage, _ = rtstorage.NewTrieState(nil)
+
+	instance, err := NewInstance(data, cfg)
+	if err != nil {
+		logger.Errorf("failed to create instance: %s", err)
+		return 0
+	}
+
+	// instance version is set and cached in NewInstance
+	version := instance.version
+
+	if version == nil {
+		logger.Error("failed to get runtime version")
+		out, _ := toWasmMemoryOptional(ctx, vm, nil)
+		return out
+	}
+
+	encodedData, err := version.Encode()
+	if err != nil {
+		logger.Errorf("failed to encode result: %s", err)
+		return 0
+	}
+
+	out, err := toWasmMemoryOptional(ctx, vm, encodedData)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		return 0
+	}
+
+	return out
+}
+
+func ext_offchain_is_validator_version_1(ctx *runtime.Context, _ *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	if ctx.Validator {
+		return 1
+	}
+	return 0
+}
+
+func ext_offchain_local_storage_clear_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	kind := vm.GetCurrentFrame().Locals[0]
+	key := vm.GetCurrentFrame().Locals[1]
+
+	storageKey := asMemorySlice(vm.Memory, key)
+
+	var err error
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		err = ctx.NodeStorage.PersistentStorage.Del(storageKey)
+	case runtime.NodeStorageTypeLocal:
+		err = ctx.NodeStorage.LocalStorage.Del(storageKey)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to clear value from storage: %s", err)
+	}
+
+	return 0
+}
+
+func ext_offchain_local_storage_compare_and_set_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	kind := vm.GetCurrentFrame().Locals[0]
+	key := vm.GetCurrentFrame().Locals[1]
+	oldValue := vm.GetCurrentFrame().Locals[2]
+	newValue := vm.GetCurrentFrame().Locals[3]
+
+	storageKey := asMemorySlice(vm.Memory, key)
+
+	var storedValue []byte
+	var err error
+
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		storedValue, err = ctx.NodeStorage.PersistentStorage.Get(storageKey)
+	case runtime.NodeStorageTypeLocal:
+		storedValue, err = ctx.NodeStorage.LocalStorage.Get(storageKey)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to get value from storage: %s", err)
+		return 0
+	}
+
+	oldVal := asMemorySlice(vm.Memory, oldValue)
+	newVal := asMemorySlice(vm.Memory, newValue)
+	if reflect.DeepEqual(storedValue, oldVal) {
+		cp := make([]byte, len(newVal))
+		copy(cp, newVal)
+		err = ctx.NodeStorage.LocalStorage.Put(storageKey, cp)
+		if err != nil {
+			logger.Errorf("failed to set value in storage: %s", err)
+			return 0
+		}
+	}
+
+	return 1
+}
+
+func ext_offchain_local_storage_get_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	kind := vm.GetCurrentFrame().Locals[0]
+	key := vm.GetCurrentFrame().Locals[1]
+
+	storageKey := asMemorySlice(vm.Memory, key)
+
+	var res []byte
+	var err error
+
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		res, err = ctx.NodeStorage.PersistentStorage.Get(storageKey)
+	case runtime.NodeStorageTypeLocal:
+		res, err = ctx.NodeStorage.LocalStorage.Get(storageKey)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to get value from storage: %s", err)
+	}
+
+	// allocate memory for value and copy value to memory
+	ptr, err := toWasmMemoryOptional(ctx, vm, res)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+	return ptr
+}
+
+func ext_offchain_local_storage_set_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	kind := vm.GetCurrentFrame().Locals[0]
+	key := vm.GetCurrentFrame().Locals[1]
+	value := vm.GetCurrentFrame().Locals[2]
+
+	storageKey := asMemorySlice(vm.Memory, key)
+	newValue := asMemorySlice(vm.Memory, value)
+	cp := make([]byte, len(newValue))
+	copy(cp, newValue)
+
+	var err error
+	switch runtime.NodeStorageType(kind) {
+	case runtime.NodeStorageTypePersistent:
+		err = ctx.NodeStorage.PersistentStorage.Put(storageKey, cp)
+	case runtime.NodeStorageTypeLocal:
+		err = ctx.NodeStorage.LocalStorage.Put(storageKey, cp)
+	}
+
+	if err != nil {
+		logger.Errorf("failed to set value in storage: %s", err)
+	}
+
+	return 0
+}
+
+func ext_offchain_network_state_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	if ctx.Network == nil {
+		return 0
+	}
+
+	nsEnc, err := scale.Marshal(ctx.Network.NetworkState())
+	if err != nil {
+		logger.Errorf("failed at encoding network state: %s", err)
+		return 0
+	}
+
+	// allocate memory for value and copy value to memory
+	ptr, err := toWasmMemorySized(ctx, vm, nsEnc, uint32(len(nsEnc)))
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		return 0
+	}
+
+	return int64(ptr)
+}
+
+func ext_offchain_random_seed_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+
+	seed := make([]byte, 32)
+	_, err := rand.Read(seed)
+	if err != nil {
+		logger.Errorf("failed to generate random seed: %s", err)
+	}
+
+	ptr, err := toWasmMemorySized(ctx, vm, seed, 32)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+	}
+	return int64(ptr)
+}
+
+func ext_offchain_sleep_until_version_1(_ *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	deadline := vm.GetCurrentFrame().Locals[0]
+
+	dur := time.Until(time.UnixMilli(deadline))
+	if dur > 0 {
+		time.Sleep(dur)
+	}
+
+	return 0
+}
+
+func ext_offchain_submit_transaction_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	data := vm.GetCurrentFrame().Locals[0]
+	extBytes := asMemorySlice(vm.Memory, data)
+
+	var extrinsic []byte
+	err := scale.Unmarshal(extBytes, &extrinsic)
+	if err != nil {
+		logger.Errorf("failed to decode extrinsic data: %s", err)
+	}
+
+	// validate the transaction
+	txv := transaction.NewValidity(0, [][]byte{{}}, [][]byte{{}}, 0, false)
+	vtx := transaction.NewValidTransaction(extrinsic, txv)
+
+	_, err = ctx.Transaction.AddToPool(vtx)
+	if err != nil {
+		logger.Errorf("failed to add transaction to pool: %s", err)
+	}
+
+	ptr, err := toWasmMemoryOptional(ctx, vm, nil)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+	}
+	return ptr
+}
+
+func ext_offchain_timestamp_version_1(_ *runtime.Context, _ *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	return time.Now().Unix()
+}
+
+func ext_offchain_http_request_start_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	methodSpan := vm.GetCurrentFrame().Locals[0]
+	uriSpan := vm.GetCurrentFrame().Locals[1]
+
+	httpMethod := asMemorySlice(vm.Memory, methodSpan)
+	uri := asMemorySlice(vm.Memory, uriSpan)
+
+	result := scale.NewResult(int16(0), nil)
+
+	reqID, err := ctx.OffchainHTTPSet.StartRequest(string(httpMethod), string(uri))
+	if err != nil {
+		// StartRequest error already was logged
+		logger.Errorf("failed to start request: %s", err)
+		err = result.Set(scale.Err, nil)
+	} else {
+		err = result.Set(scale.OK, reqID)
+	}
+
+	// note: just check if an error occurs while setting the result data
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return 0
+	}
+
+	return toWasmMemoryEncoded(ctx, vm, result)
+}
+
+func ext_offchain_http_request_add_header_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	reqID := int16(vm.GetCurrentFrame().Locals[0])
+	nameSpan := vm.GetCurrentFrame().Locals[1]
+	valueSpan := vm.GetCurrentFrame().Locals[2]
+
+	name := asMemorySlice(vm.Memory, nameSpan)
+	value := asMemorySlice(vm.Memory, valueSpan)
+
+	result := scale.NewResult(nil, nil)
+	resultMode := scale.OK
+
+	offchainReq := ctx.OffchainHTTPSet.Get(reqID)
+	if offchainReq == nil {
+		logger.Errorf("no request with id %d", reqID)
+		resultMode = scale.Err
+	} else if err := offchainReq.AddHeader(string(name), string(value)); err != nil {
+		logger.Errorf("failed to add request header: %s", err)
+		resultMode = scale.Err
+	}
+
+	err := result.Set(resultMode, nil)
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return 0
+	}
+
+	return toWasmMemoryEncoded(ctx, vm, result)
+}
+
+func ext_offchain_http_request_write_body_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	reqID := int16(vm.GetCurrentFrame().Locals[0])
+	chunkSpan := vm.GetCurrentFrame().Locals[1]
+	deadlineSpan := vm.GetCurrentFrame().Locals[2]
+
+	chunk := asMemorySlice(vm.Memory, chunkSpan)
+
+	result := scale.NewResult(nil, offchain.HTTPError(0))
+	resultMode, resultValue := scale.OK, interface{}(nil)
+
+	deadline, err := offchainDeadline(asMemorySlice(vm.Memory, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		return 0
+	}
+
+	offchainReq := ctx.OffchainHTTPSet.Get(reqID)
+	if offchainReq == nil {
+		resultMode, resultValue = scale.Err, offchain.InvalidID
+	} else if err = offchainReq.WriteBody(chunk, deadline); err != nil {
+		logger.Errorf("failed to write request body: %s", err)
+		resultMode, resultValue = scale.Err, httpError(err)
+	}
+
+	err = result.Set(resultMode, resultValue)
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return 0
+	}
+
+	return toWasmMemoryEncoded(ctx, vm, result)
+}
+
+func ext_offchain_http_response_wait_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	idsSpan := vm.GetCurrentFrame().Locals[0]
+	deadlineSpan := vm.GetCurrentFrame().Locals[1]
+
+	var ids []int16
+	err := scale.Unmarshal(asMemorySlice(vm.Memory, idsSpan), &ids)
+	if err != nil {
+		logger.Errorf("failed to decode request ids: %s", err)
+		return 0
+	}
+
+	deadline, err := offchainDeadline(asMemorySlice(vm.Memory, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		return 0
+	}
+
+	statuses := scale.NewVaryingDataTypeSlice(offchain.NewHTTPRequestStatus())
+	err = statuses.Add(ctx.OffchainHTTPSet.ResponseWait(ids, deadline)...)
+	if err != nil {
+		logger.Errorf("failed to add request statuses: %s", err)
+		return 0
+	}
+
+	return toWasmMemoryEncoded(ctx, vm, statuses)
+}
+
+func ext_offchain_http_response_headers_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	reqID := int16(vm.GetCurrentFrame().Locals[0])
+
+	headers := ctx.OffchainHTTPSet.ResponseHeaders(reqID)
+	if headers == nil {
+		headers = [][2][]byte{}
+	}
+
+	return toWasmMemoryEncoded(ctx, vm, headers)
+}
+
+func ext_offchain_http_response_read_body_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	reqID := int16(vm.GetCurrentFrame().Locals[0])
+	bufferSpan := vm.GetCurrentFrame().Locals[1]
+	deadlineSpan := vm.GetCurrentFrame().Locals[2]
+
+	buffer := asMemorySlice(vm.Memory, bufferSpan)
+
+	result := scale.NewResult(uint32(0), offchain.HTTPError(0))
+
+	deadline, err := offchainDeadline(asMemorySlice(vm.Memory, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		return 0
+	}
+
+	n, err := ctx.OffchainHTTPSet.ReadBody(reqID, buffer, deadline)
+	if err != nil {
+		logger.Errorf("failed to read response body: %s", err)
+		err = result.Set(scale.Err, httpError(err))
+	} else {
+		err = result.Set(scale.OK, uint32(n))
+	}
+
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return 0
+	}
+
+	return toWasmMemoryEncoded(ctx, vm, result)
+}
+
+// offchainDeadline decodes the optional deadline of the offchain http functions,
+// a timestamp in milliseconds
+func offchainDeadline(enc []byte) (*time.Time, error) {
+	var timestamp *uint64
+	err := scale.Unmarshal(enc, &timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	if timestamp == nil {
+		return nil, nil
+	}
+
+	deadline := time.UnixMilli(int64(*timestamp))
+	return &deadline, nil
+}
+
+// httpError returns the offchain.HTTPError of the error, or offchain.IOError if there is none
+func httpError(err error) offchain.HTTPError {
+	var httpErr offchain.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	return offchain.IOError
+}
+
+func ext_sandbox_instantiate_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	dispatchThunk := uint32(vm.GetCurrentFrame().Locals[0])
+	wasmCodeSpan := vm.GetCurrentFrame().Locals[1]
+	envDefSpan := vm.GetCurrentFrame().Locals[2]
+	statePtr := uint32(vm.GetCurrentFrame().Locals[3])
+
+	code := make([]byte, len(asMemorySlice(vm.Memory, wasmCodeSpan)))
+	copy(code, asMemorySlice(vm.Memory, wasmCodeSpan))
+	envDef := make([]byte, len(asMemorySlice(vm.Memory, envDefSpan)))
+	copy(envDef, asMemorySlice(vm.Memory, envDefSpan))
+
+	idx, err := ctx.Sandbox.Instantiate(dispatchThunk, code, envDef, statePtr)
+	if err != nil {
+		logger.Debugf("failed to instantiate sandboxed module: %s", err)
+		return sandboxResult(sandbox.ResultCode(err))
+	}
+
+	return int64(idx)
+}
+
+func ext_sandbox_instance_teardown_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	instanceIdx := uint32(vm.GetCurrentFrame().Locals[0])
+
+	err := ctx.Sandbox.TeardownInstance(instanceIdx)
+	if err != nil {
+		logger.Errorf("failed to teardown sandboxed instance: %s", err)
+	}
+
+	return 0
+}
+
+func ext_sandbox_invoke_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	instanceIdx := uint32(vm.GetCurrentFrame().Locals[0])
+	exportNameSpan := vm.GetCurrentFrame().Locals[1]
+	argsSpan := vm.GetCurrentFrame().Locals[2]
+	returnValPtr := uint32(vm.GetCurrentFrame().Locals[3])
+	returnValLen := uint32(vm.GetCurrentFrame().Locals[4])
+	statePtr := uint32(vm.GetCurrentFrame().Locals[5])
+
+	exportName := string(asMemorySlice(vm.Memory, exportNameSpan))
+	args := make([]byte, len(asMemorySlice(vm.Memory, argsSpan)))
+	copy(args, asMemorySlice(vm.Memory, argsSpan))
+
+	ret, err := ctx.Sandbox.Invoke(instanceIdx, exportName, args, statePtr)
+	if err != nil {
+		logger.Debugf("failed to invoke function %s of sandboxed instance: %s", exportName, err)
+		return sandboxResult(sandbox.ResultCode(err))
+	}
+
+	if len(ret) > int(returnValLen) {
+		logger.Debugf("return value of function %s of sandboxed instance does not fit in buffer", exportName)
+		return sandboxResult(sandbox.ResultExecution)
+	}
+
+	// the memory is read after the invocation, which may grow it
+	memory := vm.Memory
+	if uint64(returnValPtr)+uint64(len(ret)) > uint64(len(memory)) {
+		logger.Debugf("return value buffer is out of bounds")
+		return sandboxResult(sandbox.ResultOutOfBounds)
+	}
+
+	copy(memory[returnValPtr:], ret)
+	return sandboxResult(sandbox.ResultOK)
+}
+
+func ext_sandbox_memory_new_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	initial := uint32(vm.GetCurrentFrame().Locals[0])
+	maximum := uint32(vm.GetCurrentFrame().Locals[1])
+
+	idx, err := ctx.Sandbox.NewMemory(initial, maximum)
+	if err != nil {
+		logger.Errorf("failed to create sandboxed memory: %s", err)
+		return sandboxResult(sandbox.ResultCode(err))
+	}
+
+	return int64(idx)
+}
+
+func ext_sandbox_memory_get_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	memoryIdx := uint32(vm.GetCurrentFrame().Locals[0])
+	offset := uint32(vm.GetCurrentFrame().Locals[1])
+	bufPtr := uint32(vm.GetCurrentFrame().Locals[2])
+	bufLen := uint32(vm.GetCurrentFrame().Locals[3])
+
+	err := ctx.Sandbox.MemoryGet(memoryIdx, offset, bufPtr, bufLen)
+	if err != nil {
+		logger.Debugf("failed to get sandboxed memory: %s", err)
+	}
+
+	return sandboxResult(sandbox.ResultCode(err))
+}
+
+func ext_sandbox_memory_set_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	memoryIdx := uint32(vm.GetCurrentFrame().Locals[0])
+	offset := uint32(vm.GetCurrentFrame().Locals[1])
+	valPtr := uint32(vm.GetCurrentFrame().Locals[2])
+	valLen := uint32(vm.GetCurrentFrame().Locals[3])
+
+	err := ctx.Sandbox.MemorySet(memoryIdx, offset, valPtr, valLen)
+	if err != nil {
+		logger.Debugf("failed to set sandboxed memory: %s", err)
+	}
+
+	return sandboxResult(sandbox.ResultCode(err))
+}
+
+func ext_sandbox_memory_teardown_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	memoryIdx := uint32(vm.GetCurrentFrame().Locals[0])
+
+	err := ctx.Sandbox.TeardownMemory(memoryIdx)
+	if err != nil {
+		logger.Errorf("failed to teardown sandboxed memory: %s", err)
+	}
+
+	return 0
+}
+
+// sandboxResult converts a sandbox result code to the returned value
+func sandboxResult(code uint32) int64 {
+	return int64(int32(code))
+}
+
+func ext_storage_clear_prefix_version_2(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	prefixSpan := vm.GetCurrentFrame().Locals[0]
+	lim := vm.GetCurrentFrame().Locals[1]
+	storage := ctx.Storage
+
+	prefix := asMemorySlice(vm.Memory, prefixSpan)
+	logger.Debugf("prefix: 0x%x", prefix)
+
+	limitBytes := asMemorySlice(vm.Memory, lim)
+
+	var limit []byte
+	err := scale.Unmarshal(limitBytes, &limit)
+	if err != nil {
+		logger.Warnf("[ext_storage_clear_prefix_version_2]: cannot generate limit: %s", err)
+		ret, _ := toWasmMemory(ctx, vm, nil)
+		return ret
+	}
+
+	if len(limit) == 0 {
+		// limit is None, set limit to max
+		limit = []byte{0xff, 0xff, 0xff, 0xff}
+	}
+
+	limitUint := binary.LittleEndian.Uint32(limit)
+	numRemoved, all := storage.ClearPrefixLimit(prefix, limitUint)
+	encBytes, err := toKillStorageResultEnum(all, numRemoved)
+	if err != nil {
+		logger.Errorf("failed to allocate memory: %s", err)
+		ret, _ := toWasmMemory(ctx, vm, nil)
+		return ret
+	}
+
+	valueSpan, err := toWasmMemory(ctx, vm, encBytes)
+	if err != nil {
+		logger.Errorf("failed to allocate: %s", err)
+		ptr, _ := toWasmMemory(ctx, vm, nil)
+		return ptr
+	}
+
+	return valueSpan
+}
+
+func ext_storage_start_transaction_version_1(ctx *runtime.Context, _ *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	ctx.Storage.BeginStorageTransaction()
+	return 0
+}
+
+func ext_storage_rollback_transaction_version_1(ctx *runtime.Context, _ *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	ctx.Storage.RollbackStorageTransaction()
+	return 0
+}
+
+func ext_storage_commit_transaction_version_1(ctx *runtime.Context, _ *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	ctx.Storage.CommitStorageTransaction()
+	return 0
+}
+
+func ext_transaction_index_index_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	extrinsic := uint32(vm.GetCurrentFrame().Locals[0])
+	size := uint32(vm.GetCurrentFrame().Locals[1])
+	hashPtr := uint32(vm.GetCurrentFrame().Locals[2])
+
+	hash := common.BytesToHash(vm.Memory[hashPtr : hashPtr+32])
+	ctx.Storage.IndexTransaction(extrinsic, size, hash)
+	return 0
+}
+
+func ext_transaction_index_renew_version_1(ctx *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	extrinsic := uint32(vm.GetCurrentFrame().Locals[0])
+	hashPtr := uint32(vm.GetCurrentFrame().Locals[1])
+
+	hash := common.BytesToHash(vm.Memory[hashPtr : hashPtr+32])
+	ctx.Storage.RenewTransactionIndex(extrinsic, hash)
+	return 0
+}
+
+func ext_trie_blake2_256_verify_proof_version_1(_ *runtime.Context, vm *exec.VirtualMachine) int64 {
+	logger.Trace("executing...")
+	rootSpan := vm.GetCurrentFrame().Locals[0]
+	proofSpan := vm.GetCurrentFrame().Locals[1]
+	keySpan := vm.GetCurrentFrame().Locals[2]
+	valueSpan := vm.GetCurrentFrame().Locals[3]
+
+	toDecProofs := asMemorySlice(vm.Memory, proofSpan)
+	var decProofs [][]byte
+	err := scale.Unmarshal(toDecProofs, &decProofs)
+	if err != nil {
+		logger.Errorf("[ext_trie_blake2_256_verify_proof_version_1]: %s", err)
+		return 0
+	}
+
+	key := asMemorySlice(vm.Memory, keySpan)
+	value := asMemorySlice(vm.Memory, valueSpan)
+	trieRoot := vm.Memory[rootSpan : rootSpan+32]
+
+	exists, err := trie.VerifyProof(decProofs, trieRoot, []trie.Pair{{Key: key, Value: value}})
+	if err != nil {
+		logger.Errorf("[ext_trie_blake2_256_verify_proof_version_1]: %s", err)
+		return 0
+	}
+
+	if exists {
+		return 1
+	}
+	return 0
+}
+
+// toWasmMemoryEncoded scale encodes the value and copies it to wasm memory. Returns the resulting
+// 64bit span descriptor, or 0 if the value cannot be encoded or allocated
+func toWasmMemoryEncoded(ctx *runtime.Context, vm *exec.VirtualMachine, value interface{}) int64 {
+	enc, err := scale.Marshal(value)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the result: %s", err)
+		return 0
+	}
+
+	ptr, err := toWasmMemory(ctx, vm, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return 0
+	}
+
+	return ptr
+}
+
+func toKillStorageResultEnum(allRemoved bool, numRemoved uint32) ([]byte, error) {
+	var b, sbytes []byte
+	sbytes, err := scale.Marshal(numRemoved)
+	if err != nil {
+		return nil, err
+	}
+
+	if allRemoved {
+		// No key remains in the child trie.
+		b = append(b, byte(0))
+	} else {
+		// At least one key still resides in the child trie due to the supplied limit.
+		b = append(b, byte(1))
+	}
+
+	b = append(b, sbytes...)
+
+	return b, err
+}
+
 // Convert 64bit wasm span descriptor to Go memory slice
 func asMemorySlice(memory []byte, span int64) []byte {
 	ptr, size := runtime.Int64ToPointerAndSize(span)
@@ -1335,7 +2416,7 @@ func asMemorySlice(memory []byte, span int64) []byte {
 }
 
 // Copy a byte slice of a fixed size to wasm memory and return resulting pointer
-func toWasmMemorySized(memory, data []byte, size uint32) (uint32, error) {
+func toWasmMemorySized(ctx *runtime.Context, vm *exec.VirtualMachine, data []byte, size uint32) (uint32, error) {
 	if int(size) != len(data) {
 		return 0, errors.New("internal byte array size missmatch")
 	}
@@ -1346,12 +2427,13 @@ func toWasmMemorySized(memory, data []byte, size uint32) (uint32, error) {
 		return 0, err
 	}
 
-	copy(memory[out:out+size], data)
+	// the memory is read after the allocation, which may grow it
+	copy(vm.Memory[out:out+size], data)
 	return out, nil
 }
 
 // Wraps slice in optional.Bytes and copies result to wasm memory. Returns resulting 64bit span descriptor
-func toWasmMemoryOptional(memory, data []byte) (int64, error) {
+func toWasmMemoryOptional(ctx *runtime.Context, vm *exec.VirtualMachine, data []byte) (int64, error) {
 	var opt *[]byte
 	if data != nil {
 		opt = &data
@@ -1362,11 +2444,11 @@ func toWasmMemoryOptional(memory, data []byte) (int64, error) {
 		return 0, err
 	}
 
-	return toWasmMemory(memory, enc)
+	return toWasmMemory(ctx, vm, enc)
 }
 
 // Copy a byte slice to wasm memory and return the resulting 64bit span descriptor
-func toWasmMemory(memory, data []byte) (int64, error) {
+func toWasmMemory(ctx *runtime.Context, vm *exec.VirtualMachine, data []byte) (int64, error) {
 	allocator := ctx.Allocator
 	size := uint32(len(data))
 
@@ -1375,12 +2457,13 @@ func toWasmMemory(memory, data []byte) (int64, error) {
 		return 0, err
 	}
 
-	copy(memory[out:out+size], data)
+	// the memory is read after the allocation, which may grow it
+	copy(vm.Memory[out:out+size], data)
 	return runtime.PointerAndSizeToInt64(int32(out), int32(size)), nil
 }
 
 // Wraps slice in optional and copies result to wasm memory. Returns resulting 64bit span descriptor
-func toWasmMemoryOptionalUint32(memory []byte, data *uint32) (int64, error) {
+func toWasmMemoryOptionalUint32(ctx *runtime.Context, vm *exec.VirtualMachine, data *uint32) (int64, error) {
 	var opt *uint32
 	if data != nil {
 		temp := *data
@@ -1391,22 +2474,22 @@ func toWasmMemoryOptionalUint32(memory []byte, data *uint32) (int64, error) {
 	if err != nil {
 		return int64(0), err
 	}
-	return toWasmMemory(memory, enc)
+	return toWasmMemory(ctx, vm, enc)
 }
 
 // Wraps slice in optional.FixedSizeBytes and copies result to wasm memory. Returns resulting 64bit span descriptor
-func toWasmMemoryFixedSizeOptional(memory, data []byte) (int64, error) {
+func toWasmMemoryFixedSizeOptional(ctx *runtime.Context, vm *exec.VirtualMachine, data []byte) (int64, error) {
 	var opt [64]byte
 	copy(opt[:], data[:])
 	enc, err := scale.Marshal(&opt)
 	if err != nil {
 		return 0, err
 	}
-	return toWasmMemory(memory, enc)
+	return toWasmMemory(ctx, vm, enc)
 }
 
 // Wraps slice in Result type and copies result to wasm memory. Returns resulting 64bit span descriptor
-func toWasmMemoryResult(memory, data []byte) (int64, error) {
+func toWasmMemoryResult(ctx *runtime.Context, vm *exec.VirtualMachine, data []byte) (int64, error) {
 	var res *rtype.Result
 	if len(data) == 0 {
 		res = rtype.NewResult(byte(1), nil)
@@ -1419,5 +2502,5 @@ func toWasmMemoryResult(memory, data []byte) (int64, error) {
 		return 0, err
 	}
 
-	return toWasmMemory(memory, enc)
+	return toWasmMemory(ctx, vm, enc)
 }
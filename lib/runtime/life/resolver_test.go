@@ -87,7 +87,7 @@ func Test_ext_storage_get_version_1(t *testing.T) {
 
 	testkey := []byte("noot")
 	testvalue := []byte{1, 2}
-	ctx.Storage.Set(testkey, testvalue)
+	inst.ctx.Storage.Set(testkey, testvalue)
 
 	enc, err := scale.Marshal(testkey)
 	require.NoError(t, err)
@@ -116,7 +116,7 @@ func Test_ext_storage_set_version_1(t *testing.T) {
 	_, err = inst.Exec("rtm_ext_storage_set_version_1", append(encKey, encValue...))
 	require.NoError(t, err)
 
-	val := ctx.Storage.Get(testkey)
+	val := inst.ctx.Storage.Get(testkey)
 	require.Equal(t, testvalue, val)
 }
 
@@ -124,10 +124,10 @@ func Test_ext_storage_next_key_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
 	testkey := []byte("noot")
-	ctx.Storage.Set(testkey, []byte{1})
+	inst.ctx.Storage.Set(testkey, []byte{1})
 
 	nextkey := []byte("oot")
-	ctx.Storage.Set(nextkey, []byte{1})
+	inst.ctx.Storage.Set(nextkey, []byte{1})
 
 	enc, err := scale.Marshal(testkey)
 	require.NoError(t, err)
@@ -165,7 +165,7 @@ func Test_ext_storage_clear_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
 	testkey := []byte("noot")
-	ctx.Storage.Set(testkey, []byte{1})
+	inst.ctx.Storage.Set(testkey, []byte{1})
 
 	enc, err := scale.Marshal(testkey)
 	require.NoError(t, err)
@@ -173,7 +173,7 @@ func Test_ext_storage_clear_version_1(t *testing.T) {
 	_, err = inst.Exec("rtm_ext_storage_clear_version_1", enc)
 	require.NoError(t, err)
 
-	val := ctx.Storage.Get(testkey)
+	val := inst.ctx.Storage.Get(testkey)
 	require.Nil(t, val)
 }
 
@@ -181,10 +181,10 @@ func Test_ext_storage_clear_prefix_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
 	testkey := []byte("noot")
-	ctx.Storage.Set(testkey, []byte{1})
+	inst.ctx.Storage.Set(testkey, []byte{1})
 
 	testkey2 := []byte("spaghet")
-	ctx.Storage.Set(testkey2, []byte{2})
+	inst.ctx.Storage.Set(testkey2, []byte{2})
 
 	enc, err := scale.Marshal(testkey[:3])
 	require.NoError(t, err)
@@ -192,10 +192,10 @@ func Test_ext_storage_clear_prefix_version_1(t *testing.T) {
 	_, err = inst.Exec("rtm_ext_storage_clear_prefix_version_1", enc)
 	require.NoError(t, err)
 
-	val := ctx.Storage.Get(testkey)
+	val := inst.ctx.Storage.Get(testkey)
 	require.Nil(t, val)
 
-	val = ctx.Storage.Get(testkey2)
+	val = inst.ctx.Storage.Get(testkey2)
 	require.NotNil(t, val)
 }
 
@@ -222,7 +222,7 @@ func Test_ext_storage_append_version_1(t *testing.T) {
 	_, err = inst.Exec("rtm_ext_storage_append_version_1", append(encKey1, doubleEncVal1...))
 	require.NoError(t, err)
 
-	val := ctx.Storage.Get(testkey)
+	val := inst.ctx.Storage.Get(testkey)
 	require.Equal(t, encArr1, val)
 
 	encValueAppend1, err := scale.Marshal(testvalueAppend)
@@ -234,7 +234,7 @@ func Test_ext_storage_append_version_1(t *testing.T) {
 	_, err = inst.Exec("rtm_ext_storage_append_version_1", append(encKey1, doubleEncValueAppend1...))
 	require.NoError(t, err)
 
-	ret := ctx.Storage.Get(testkey)
+	ret := inst.ctx.Storage.Get(testkey)
 	require.NotNil(t, ret)
 
 	var dec1 [][]byte
@@ -296,12 +296,106 @@ func Test_ext_storage_root_version_1(t *testing.T) {
 	require.Equal(t, expected[:], hash)
 }
 
+func Test_ext_storage_clear_prefix_version_2(t *testing.T) {
+	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
+
+	testkey := []byte("noot")
+	inst.ctx.Storage.Set(testkey, []byte{1})
+
+	testkey2 := []byte("noot1")
+	inst.ctx.Storage.Set(testkey2, []byte{1})
+
+	testkey3 := []byte("noot2")
+	inst.ctx.Storage.Set(testkey3, []byte{1})
+
+	testkey4 := []byte("noot3")
+	inst.ctx.Storage.Set(testkey4, []byte{1})
+
+	testkey5 := []byte("spaghet")
+	testValue5 := []byte{2}
+	inst.ctx.Storage.Set(testkey5, testValue5)
+
+	enc, err := scale.Marshal(testkey[:3])
+	require.NoError(t, err)
+
+	testLimit := uint32(2)
+	testLimitBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(testLimitBytes, testLimit)
+
+	optLimit, err := scale.Marshal(&testLimitBytes)
+	require.NoError(t, err)
+
+	// clearing prefix for "noo" prefix with limit 2
+	encValue, err := inst.Exec("rtm_ext_storage_clear_prefix_version_2", append(enc, optLimit...))
+	require.NoError(t, err)
+
+	var decVal []byte
+	scale.Unmarshal(encValue, &decVal)
+
+	var numDeleted uint32
+	// numDeleted represents no. of actual keys deleted
+	scale.Unmarshal(decVal[1:], &numDeleted)
+	require.Equal(t, uint32(2), numDeleted)
+
+	var expectedAllDeleted byte
+	// expectedAllDeleted value 0 represents all keys deleted, 1 represents keys are pending with prefix in trie
+	expectedAllDeleted = 1
+	require.Equal(t, expectedAllDeleted, decVal[0])
+
+	val := inst.ctx.Storage.Get(testkey)
+	require.NotNil(t, val)
+
+	val = inst.ctx.Storage.Get(testkey5)
+	require.NotNil(t, val)
+	require.Equal(t, testValue5, val)
+
+	// clearing prefix again for "noo" prefix with limit 2
+	encValue, err = inst.Exec("rtm_ext_storage_clear_prefix_version_2", append(enc, optLimit...))
+	require.NoError(t, err)
+
+	scale.Unmarshal(encValue, &decVal)
+	scale.Unmarshal(decVal[1:], &numDeleted)
+	require.Equal(t, uint32(2), numDeleted)
+
+	expectedAllDeleted = 0
+	require.Equal(t, expectedAllDeleted, decVal[0])
+
+	val = inst.ctx.Storage.Get(testkey)
+	require.Nil(t, val)
+
+	val = inst.ctx.Storage.Get(testkey5)
+	require.NotNil(t, val)
+	require.Equal(t, testValue5, val)
+}
+
+func Test_ext_offchain_local_storage_clear_version_1_Local(t *testing.T) {
+	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
+
+	testkey := []byte("key1")
+	err := inst.NodeStorage().LocalStorage.Put(testkey, []byte{1})
+	require.NoError(t, err)
+
+	kind := int32(2)
+	encKind, err := scale.Marshal(kind)
+	require.NoError(t, err)
+
+	encKey, err := scale.Marshal(testkey)
+	require.NoError(t, err)
+
+	_, err = inst.Exec("rtm_ext_offchain_local_storage_clear_version_1", append(encKind, encKey...))
+	require.NoError(t, err)
+
+	val, err := inst.NodeStorage().LocalStorage.Get(testkey)
+	require.EqualError(t, err, "Key not found")
+	require.Nil(t, val)
+}
+
 func Test_ext_storage_exists_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
 	testkey := []byte("noot")
 	testvalue := []byte{1, 2}
-	ctx.Storage.Set(testkey, testvalue)
+	inst.ctx.Storage.Set(testkey, testvalue)
 
 	enc, err := scale.Marshal(testkey)
 	require.NoError(t, err)
@@ -322,11 +416,11 @@ func Test_ext_storage_exists_version_1(t *testing.T) {
 func Test_ext_default_child_storage_set_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
-	err := ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
+	err := inst.ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
 	require.NoError(t, err)
 
 	// Check if value is not set
-	val, err := ctx.Storage.GetChildStorage(testChildKey, testKey)
+	val, err := inst.ctx.Storage.GetChildStorage(testChildKey, testKey)
 	require.NoError(t, err)
 	require.Nil(t, val)
 
@@ -342,7 +436,7 @@ func Test_ext_default_child_storage_set_version_1(t *testing.T) {
 	_, err = inst.Exec("rtm_ext_default_child_storage_set_version_1", append(append(encChildKey, encKey...), encVal...))
 	require.NoError(t, err)
 
-	val, err = ctx.Storage.GetChildStorage(testChildKey, testKey)
+	val, err = inst.ctx.Storage.GetChildStorage(testChildKey, testKey)
 	require.NoError(t, err)
 	require.Equal(t, testValue, val)
 }
@@ -350,10 +444,10 @@ func Test_ext_default_child_storage_set_version_1(t *testing.T) {
 func Test_ext_default_child_storage_get_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
-	err := ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
+	err := inst.ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
 	require.NoError(t, err)
 
-	err = ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
+	err = inst.ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
 	require.NoError(t, err)
 
 	encChildKey, err := scale.Marshal(testChildKey)
@@ -374,10 +468,10 @@ func Test_ext_default_child_storage_get_version_1(t *testing.T) {
 func Test_ext_default_child_storage_read_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
-	err := ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
+	err := inst.ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
 	require.NoError(t, err)
 
-	err = ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
+	err = inst.ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
 	require.NoError(t, err)
 
 	testOffset := uint32(2)
@@ -413,14 +507,14 @@ func Test_ext_default_child_storage_read_version_1(t *testing.T) {
 func Test_ext_default_child_storage_clear_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
-	err := ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
+	err := inst.ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
 	require.NoError(t, err)
 
-	err = ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
+	err = inst.ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
 	require.NoError(t, err)
 
 	// Confirm if value is set
-	val, err := ctx.Storage.GetChildStorage(testChildKey, testKey)
+	val, err := inst.ctx.Storage.GetChildStorage(testChildKey, testKey)
 	require.NoError(t, err)
 	require.Equal(t, testValue, val)
 
@@ -433,7 +527,7 @@ func Test_ext_default_child_storage_clear_version_1(t *testing.T) {
 	_, err = inst.Exec("rtm_ext_default_child_storage_clear_version_1", append(encChildKey, encKey...))
 	require.NoError(t, err)
 
-	val, err = ctx.Storage.GetChildStorage(testChildKey, testKey)
+	val, err = inst.ctx.Storage.GetChildStorage(testChildKey, testKey)
 	require.NoError(t, err)
 	require.Nil(t, val)
 }
@@ -441,11 +535,11 @@ func Test_ext_default_child_storage_clear_version_1(t *testing.T) {
 func Test_ext_default_child_storage_storage_kill_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
-	err := ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
+	err := inst.ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
 	require.NoError(t, err)
 
 	// Confirm if value is set
-	child, err := ctx.Storage.GetChild(testChildKey)
+	child, err := inst.ctx.Storage.GetChild(testChildKey)
 	require.NoError(t, err)
 	require.NotNil(t, child)
 
@@ -455,17 +549,17 @@ func Test_ext_default_child_storage_storage_kill_version_1(t *testing.T) {
 	_, err = inst.Exec("rtm_ext_default_child_storage_storage_kill_version_1", encChildKey)
 	require.NoError(t, err)
 
-	child, _ = ctx.Storage.GetChild(testChildKey)
+	child, _ = inst.ctx.Storage.GetChild(testChildKey)
 	require.Nil(t, child)
 }
 
 func Test_ext_default_child_storage_exists_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
-	err := ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
+	err := inst.ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
 	require.NoError(t, err)
 
-	err = ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
+	err = inst.ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
 	require.NoError(t, err)
 
 	encChildKey, err := scale.Marshal(testChildKey)
@@ -497,16 +591,16 @@ func Test_ext_default_child_storage_clear_prefix_version_1(t *testing.T) {
 		{[]byte("keyThree"), []byte("value3")},
 	}
 
-	err := ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
+	err := inst.ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
 	require.NoError(t, err)
 
 	for _, kv := range testKeyValuePair {
-		err = ctx.Storage.SetChildStorage(testChildKey, kv.key, kv.value)
+		err = inst.ctx.Storage.SetChildStorage(testChildKey, kv.key, kv.value)
 		require.NoError(t, err)
 	}
 
 	// Confirm if value is set
-	keys, err := ctx.Storage.(*storage.TrieState).GetKeysWithPrefixFromChild(testChildKey, prefix)
+	keys, err := inst.ctx.Storage.(*storage.TrieState).GetKeysWithPrefixFromChild(testChildKey, prefix)
 	require.NoError(t, err)
 	require.Equal(t, 3, len(keys))
 
@@ -519,7 +613,7 @@ func Test_ext_default_child_storage_clear_prefix_version_1(t *testing.T) {
 	_, err = inst.Exec("rtm_ext_default_child_storage_clear_prefix_version_1", append(encChildKey, encPrefix...))
 	require.NoError(t, err)
 
-	keys, err = ctx.Storage.(*storage.TrieState).GetKeysWithPrefixFromChild(testChildKey, prefix)
+	keys, err = inst.ctx.Storage.(*storage.TrieState).GetKeysWithPrefixFromChild(testChildKey, prefix)
 	require.NoError(t, err)
 	require.Equal(t, 0, len(keys))
 }
@@ -527,13 +621,13 @@ func Test_ext_default_child_storage_clear_prefix_version_1(t *testing.T) {
 func Test_ext_default_child_storage_root_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
-	err := ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
+	err := inst.ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
 	require.NoError(t, err)
 
-	err = ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
+	err = inst.ctx.Storage.SetChildStorage(testChildKey, testKey, testValue)
 	require.NoError(t, err)
 
-	child, err := ctx.Storage.GetChild(testChildKey)
+	child, err := inst.ctx.Storage.GetChild(testChildKey)
 	require.NoError(t, err)
 
 	rootHash, err := child.Hash()
@@ -569,11 +663,11 @@ func Test_ext_default_child_storage_next_key_version_1(t *testing.T) {
 
 	key := testKeyValuePair[0].key
 
-	err := ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
+	err := inst.ctx.Storage.SetChild(testChildKey, trie.NewEmptyTrie())
 	require.NoError(t, err)
 
 	for _, kv := range testKeyValuePair {
-		err = ctx.Storage.SetChildStorage(testChildKey, kv.key, kv.value)
+		err = inst.ctx.Storage.SetChildStorage(testChildKey, kv.key, kv.value)
 		require.NoError(t, err)
 	}
 
@@ -596,7 +690,7 @@ func Test_ext_crypto_ed25519_public_keys_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
 	idData := []byte(keystore.DumyName)
-	ks, _ := ctx.Keystore.GetKeystore(idData)
+	ks, _ := inst.ctx.Keystore.GetKeystore(idData)
 	require.Equal(t, 0, ks.Size())
 
 	size := 5
@@ -630,7 +724,7 @@ func Test_ext_crypto_ed25519_generate_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
 	idData := []byte(keystore.AccoName)
-	ks, _ := ctx.Keystore.GetKeystore(idData)
+	ks, _ := inst.ctx.Keystore.GetKeystore(idData)
 	require.Equal(t, 0, ks.Size())
 
 	mnemonic, err := crypto.NewBIP39Mnemonic()
@@ -664,7 +758,7 @@ func Test_ext_crypto_ed25519_sign_version_1(t *testing.T) {
 	require.NoError(t, err)
 
 	idData := []byte(keystore.AccoName)
-	ks, _ := ctx.Keystore.GetKeystore(idData)
+	ks, _ := inst.ctx.Keystore.GetKeystore(idData)
 	ks.Insert(kp)
 
 	pubKeyData := kp.Public().Encode()
@@ -702,7 +796,7 @@ func Test_ext_crypto_ed25519_verify_version_1(t *testing.T) {
 	require.NoError(t, err)
 
 	idData := []byte(keystore.AccoName)
-	ks, _ := ctx.Keystore.GetKeystore(idData)
+	ks, _ := inst.ctx.Keystore.GetKeystore(idData)
 	ks.Insert(kp)
 
 	pubKeyData := kp.Public().Encode()
@@ -731,7 +825,7 @@ func Test_ext_crypto_sr25519_public_keys_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
 	idData := []byte(keystore.DumyName)
-	ks, _ := ctx.Keystore.GetKeystore(idData)
+	ks, _ := inst.ctx.Keystore.GetKeystore(idData)
 	require.Equal(t, 0, ks.Size())
 
 	size := 5
@@ -765,7 +859,7 @@ func Test_ext_crypto_sr25519_generate_version_1(t *testing.T) {
 	inst := newTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
 
 	idData := []byte(keystore.AccoName)
-	ks, _ := ctx.Keystore.GetKeystore(idData)
+	ks, _ := inst.ctx.Keystore.GetKeystore(idData)
 	require.Equal(t, 0, ks.Size())
 
 	mnemonic, err := crypto.NewBIP39Mnemonic()
@@ -800,7 +894,7 @@ func Test_ext_crypto_sr25519_sign_version_1(t *testing.T) {
 	require.NoError(t, err)
 
 	idData := []byte(keystore.AccoName)
-	ks, _ := ctx.Keystore.GetKeystore(idData)
+	ks, _ := inst.ctx.Keystore.GetKeystore(idData)
 	require.Equal(t, 0, ks.Size())
 
 	ks.Insert(kp)
@@ -840,7 +934,7 @@ func Test_ext_crypto_sr25519_verify_version_1(t *testing.T) {
 	require.NoError(t, err)
 
 	idData := []byte(keystore.AccoName)
-	ks, _ := ctx.Keystore.GetKeystore(idData)
+	ks, _ := inst.ctx.Keystore.GetKeystore(idData)
 	require.Equal(t, 0, ks.Size())
 
 	pubKeyData := kp.Public().Encode()
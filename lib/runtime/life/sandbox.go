@@ -0,0 +1,56 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package life
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
+	"github.com/perlin-network/life/exec"
+)
+
+// supervisor implements sandbox.Supervisor for the runtime instance
+type supervisor struct {
+	instance *Instance
+}
+
+// Memory returns the memory of the runtime instance
+func (s *supervisor) Memory() []byte {
+	return s.instance.vm.Memory
+}
+
+// Allocate allocates memory with the allocator of the runtime instance
+func (s *supervisor) Allocate(size uint32) (uint32, error) {
+	return s.instance.ctx.Allocator.Allocate(size)
+}
+
+// Deallocate frees memory with the allocator of the runtime instance
+func (s *supervisor) Deallocate(pointer uint32) error {
+	return s.instance.ctx.Allocator.Deallocate(pointer)
+}
+
+// DispatchThunk calls the function of the table of the runtime instance at index thunk. The dispatch
+// is called by a host function, so the function is run on a new call stack, as the virtual machine
+// isn't re-entrant, and the call stack of the host function is restored afterwards.
+func (s *supervisor) DispatchThunk(thunk, argsPointer, argsSize, state, function uint32) (int64, error) {
+	vm := s.instance.vm
+	if uint64(thunk) >= uint64(len(vm.Table)) || vm.Table[thunk] == math.MaxUint32 {
+		return 0, fmt.Errorf("no function at index %d of the table", thunk)
+	}
+
+	callStack, currentFrame, delegate := vm.CallStack, vm.CurrentFrame, vm.Delegate
+	vm.CallStack, vm.CurrentFrame, vm.Delegate = make([]exec.Frame, exec.DefaultCallStackSize), -1, nil
+	defer func() {
+		vm.CallStack, vm.CurrentFrame, vm.Delegate = callStack, currentFrame, delegate
+		vm.Exited, vm.ExitError = false, nil
+	}()
+
+	return vm.Run(int(vm.Table[thunk]), int64(argsPointer), int64(argsSize), int64(state), int64(function))
+}
+
+// newSandbox returns a new sandbox for the runtime instance
+func newSandbox(in *Instance) *sandbox.Sandbox {
+	return sandbox.New(&supervisor{instance: in})
+}
@@ -3,6 +3,8 @@
 
 package runtime
 
+import "fmt"
+
 // PageSize is 65kb
 const PageSize = 65536
 
@@ -14,3 +16,28 @@ type Memory interface {
 	Length() uint32
 	Grow(uint32) error
 }
+
+// limitedMemory is a Memory which cannot grow beyond a maximum number of pages
+type limitedMemory struct {
+	Memory
+	maxPages uint32
+}
+
+// NewLimitedMemory returns the memory, which fails to grow beyond the given number of pages
+func NewLimitedMemory(mem Memory, maxPages uint32) Memory {
+	return &limitedMemory{
+		Memory:   mem,
+		maxPages: maxPages,
+	}
+}
+
+// Grow grows the memory by the given number of pages, unless it would exceed its maximum number of pages
+func (m *limitedMemory) Grow(numPages uint32) error {
+	pages := m.Length() / PageSize
+	if uint64(pages)+uint64(numPages) > uint64(m.maxPages) {
+		return fmt.Errorf("%w: cannot grow memory of %d pages by %d pages beyond the limit of %d pages",
+			ErrMemoryLimitExceeded, pages, numPages, m.maxPages)
+	}
+
+	return m.Memory.Grow(numPages)
+}
@@ -15,7 +15,7 @@ type TransactionState struct {
 }
 
 // AddToPool provides a mock function with given fields: vt
-func (_m *TransactionState) AddToPool(vt *transaction.ValidTransaction) common.Hash {
+func (_m *TransactionState) AddToPool(vt *transaction.ValidTransaction) (common.Hash, error) {
 	ret := _m.Called(vt)
 
 	var r0 common.Hash
@@ -27,5 +27,12 @@ func (_m *TransactionState) AddToPool(vt *transaction.ValidTransaction) common.H
 		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*transaction.ValidTransaction) error); ok {
+		r1 = rf(vt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
@@ -0,0 +1,417 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultHeapPages is the number of pages the memory can grow by when the runtime doesn't set :heappages
+const DefaultHeapPages = 2048
+
+// DefaultMemoryPages is the initial number of memory pages used when the module doesn't specify one
+const DefaultMemoryPages = 23
+
+// maxMemoryPages is the maximum number of pages of a 32 bit wasm memory
+const maxMemoryPages = 65536
+
+const (
+	sectionImport = 2
+	sectionMemory = 5
+	sectionGlobal = 6
+	sectionExport = 7
+)
+
+const (
+	externalFunction = 0
+	externalTable    = 1
+	externalMemory   = 2
+	externalGlobal   = 3
+)
+
+const (
+	opEnd       = 0x0b
+	opGetGlobal = 0x23
+	opI32Const  = 0x41
+	opI64Const  = 0x42
+	opF32Const  = 0x43
+	opF64Const  = 0x44
+)
+
+// ModuleInfo holds the properties of a runtime wasm module needed to set up its memory
+type ModuleInfo struct {
+	// HeapBase is the value of the exported __heap_base global, or DefaultHeapBase if it isn't exported
+	HeapBase uint32
+	// MemoryImported is true if the module imports its memory, and false if it defines it
+	MemoryImported bool
+	// MemoryMin is the initial number of pages of the memory
+	MemoryMin uint32
+	// MemoryMax is the maximum number of pages of the memory, it is nil if there is no maximum
+	MemoryMax *uint32
+	// ImportedFunctions are the names of the functions imported by the module, in the order of the imports
+	ImportedFunctions []string
+}
+
+// ParseModuleInfo parses the import, memory, global and export sections of the wasm module
+// to find its heap base and memory limits.
+func ParseModuleInfo(code []byte) (*ModuleInfo, error) {
+	if len(code) < 8 || !bytes.Equal(code[:4], []byte("\x00asm")) {
+		return nil, errors.New("invalid wasm module header")
+	}
+
+	info := &ModuleInfo{
+		HeapBase:  DefaultHeapBase,
+		MemoryMin: DefaultMemoryPages,
+	}
+
+	var (
+		importedGlobals uint32
+		globals         = make(map[uint32]uint32) // index -> value of the i32 constant globals
+		heapBaseGlobal  *uint32
+	)
+
+	r := bytes.NewReader(code[8:])
+	for r.Len() > 0 {
+		id, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := readVarUint32(r)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read size of section %d: %w", id, err)
+		}
+
+		section, err := readBytes(r, size)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read section %d: %w", id, err)
+		}
+
+		sr := bytes.NewReader(section)
+		switch id {
+		case sectionImport:
+			importedGlobals, err = parseImports(sr, info)
+		case sectionMemory:
+			err = parseMemories(sr, info)
+		case sectionGlobal:
+			err = parseGlobals(sr, importedGlobals, globals)
+		case sectionExport:
+			heapBaseGlobal, err = parseExports(sr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse section %d: %w", id, err)
+		}
+	}
+
+	if heapBaseGlobal != nil {
+		heapBase, ok := globals[*heapBaseGlobal]
+		if !ok {
+			return nil, errors.New("__heap_base is not an i32 constant global")
+		}
+		info.HeapBase = heapBase
+	}
+
+	return info, nil
+}
+
+// parseImports finds the imported memory and functions, and returns the number of imported globals
+func parseImports(r *bytes.Reader, info *ModuleInfo) (globals uint32, err error) {
+	count, err := readVarUint32(r)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		_, err = readName(r) // module name
+		if err != nil {
+			return 0, err
+		}
+
+		field, err := readName(r)
+		if err != nil {
+			return 0, err
+		}
+
+		kind, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		switch kind {
+		case externalFunction:
+			info.ImportedFunctions = append(info.ImportedFunctions, field)
+			_, err = readVarUint32(r)
+		case externalTable:
+			_, err = r.ReadByte()
+			if err == nil {
+				_, _, err = readLimits(r)
+			}
+		case externalMemory:
+			info.MemoryImported = true
+			info.MemoryMin, info.MemoryMax, err = readLimits(r)
+		case externalGlobal:
+			globals++
+			_, err = readBytes(r, 2) // value type and mutability
+		default:
+			err = fmt.Errorf("unknown import kind %d", kind)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return globals, nil
+}
+
+func parseMemories(r *bytes.Reader, info *ModuleInfo) (err error) {
+	count, err := readVarUint32(r)
+	if err != nil || count == 0 {
+		return err
+	}
+
+	info.MemoryMin, info.MemoryMax, err = readLimits(r)
+	return err
+}
+
+// parseGlobals stores the value of the i32 constant globals by index
+func parseGlobals(r *bytes.Reader, importedGlobals uint32, globals map[uint32]uint32) error {
+	count, err := readVarUint32(r)
+	if err != nil {
+		return err
+	}
+
+	for i := uint32(0); i < count; i++ {
+		_, err = readBytes(r, 2) // value type and mutability
+		if err != nil {
+			return err
+		}
+
+		op, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch op {
+		case opI32Const:
+			value, err := readVarInt64(r)
+			if err != nil {
+				return err
+			}
+			globals[importedGlobals+i] = uint32(value)
+		case opI64Const:
+			_, err = readVarInt64(r)
+		case opGetGlobal:
+			_, err = readVarUint32(r)
+		case opF32Const:
+			_, err = readBytes(r, 4)
+		case opF64Const:
+			_, err = readBytes(r, 8)
+		default:
+			err = fmt.Errorf("unsupported global initialiser opcode %#x", op)
+		}
+		if err != nil {
+			return err
+		}
+
+		end, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if end != opEnd {
+			return fmt.Errorf("unexpected opcode %#x at the end of global initialiser", end)
+		}
+	}
+
+	return nil
+}
+
+// parseExports returns the index of the global exported as __heap_base, or nil if there is none
+func parseExports(r *bytes.Reader) (*uint32, error) {
+	count, err := readVarUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var heapBase *uint32
+	for i := uint32(0); i < count; i++ {
+		name, err := readName(r)
+		if err != nil {
+			return nil, err
+		}
+
+		kind, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := readVarUint32(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if kind == externalGlobal && name == "__heap_base" {
+			heapBase = &index
+		}
+	}
+
+	return heapBase, nil
+}
+
+func readLimits(r *bytes.Reader) (min uint32, max *uint32, err error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	min, err = readVarUint32(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if flags&1 == 0 {
+		return min, nil, nil
+	}
+
+	maximum, err := readVarUint32(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return min, &maximum, nil
+}
+
+func readName(r *bytes.Reader) (string, error) {
+	length, err := readVarUint32(r)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := readBytes(r, length)
+	return string(name), err
+}
+
+func readBytes(r *bytes.Reader, n uint32) ([]byte, error) {
+	if uint64(n) > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return b, err
+}
+
+// readVarUint32 reads an unsigned LEB128 encoded integer
+func readVarUint32(r *bytes.Reader) (uint32, error) {
+	value, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, err
+	}
+
+	if value > math.MaxUint32 {
+		return 0, fmt.Errorf("value %d overflows uint32", value)
+	}
+
+	return uint32(value), nil
+}
+
+// readVarInt64 reads a signed LEB128 encoded integer
+func readVarInt64(r *bytes.Reader) (int64, error) {
+	var (
+		value int64
+		shift uint
+	)
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		if shift >= 64 {
+			return 0, errors.New("signed LEB128 value overflows int64")
+		}
+
+		value |= int64(b&0x7f) << shift
+		shift += 7
+
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				value |= -1 << shift
+			}
+			return value, nil
+		}
+	}
+}
+
+// MemoryLimit returns the maximum number of pages of the runtime memory. The memory can grow by the
+// number of heap pages, but not beyond the maximum of the module, nor the maximum of a 32 bit memory.
+func (info *ModuleInfo) MemoryLimit(heapPages uint64) (uint32, error) {
+	limit := uint64(info.MemoryMin) + heapPages
+	if limit > maxMemoryPages {
+		limit = maxMemoryPages
+	}
+
+	if info.MemoryMax != nil {
+		if *info.MemoryMax < info.MemoryMin {
+			return 0, fmt.Errorf("maximum memory pages %d are less than the initial memory pages %d",
+				*info.MemoryMax, info.MemoryMin)
+		}
+
+		if uint64(*info.MemoryMax) < limit {
+			limit = uint64(*info.MemoryMax)
+		}
+	}
+
+	heapBasePages := uint64(info.HeapBase)/PageSize + 1
+	if heapBasePages > limit {
+		return 0, fmt.Errorf("%w: heap base %d is beyond the limit of %d pages",
+			ErrMemoryLimitExceeded, info.HeapBase, limit)
+	}
+
+	return uint32(limit), nil
+}
+
+// HeapPages returns the number of heap pages stored under the :heappages key,
+// or DefaultHeapPages if the key isn't set.
+func HeapPages(s Storage) (uint64, error) {
+	if s == nil {
+		return DefaultHeapPages, nil
+	}
+
+	value := s.Get(common.HeapPagesKey)
+	if len(value) == 0 {
+		return DefaultHeapPages, nil
+	}
+
+	if len(value) != 8 {
+		return 0, fmt.Errorf("invalid :heappages value %#x: expected 8 bytes", value)
+	}
+
+	return binary.LittleEndian.Uint64(value), nil
+}
+
+// DecompressWasm decompresses a Wasm blob that may or may not be compressed with zstd
+// ref: https://github.com/paritytech/substrate/blob/master/primitives/maybe-compressed-blob/src/lib.rs
+func DecompressWasm(code []byte) ([]byte, error) {
+	compressionFlag := []byte{82, 188, 83, 118, 70, 219, 142, 5}
+	if !bytes.HasPrefix(code, compressionFlag) {
+		return code, nil
+	}
+
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+
+	return decoder.DecodeAll(code[len(compressionFlag):], nil)
+}
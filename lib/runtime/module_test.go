@@ -0,0 +1,208 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func testModule(sections ...[]byte) []byte {
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	for _, section := range sections {
+		module = append(module, section...)
+	}
+	return module
+}
+
+func testSection(id byte, payload ...byte) []byte {
+	return append([]byte{id, byte(len(payload))}, payload...)
+}
+
+// heapPagesStorage is a Storage only storing the heap pages
+type heapPagesStorage struct {
+	Storage
+	heapPages []byte
+}
+
+func (s *heapPagesStorage) Get(key []byte) []byte {
+	if string(key) != string(common.HeapPagesKey) {
+		return nil
+	}
+	return s.heapPages
+}
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
+
+func TestParseModuleInfo(t *testing.T) {
+	// imports env.memory with 17 initial pages and no maximum, an i32 global and a function
+	imports := testSection(2, 3,
+		3, 'e', 'n', 'v', 6, 'm', 'e', 'm', 'o', 'r', 'y', 2, 0, 17,
+		3, 'e', 'n', 'v', 1, 'g', 3, 0x7f, 0,
+		3, 'e', 'n', 'v', 5, 'e', 'x', 't', '_', 'a', 0, 0)
+	// defines a memory with 2 initial pages and 4 maximum pages
+	memory := testSection(5, 1, 1, 2, 4)
+	// global 1 = i64.const 1, global 2 = i32.const 1300000
+	globals := testSection(6, 2,
+		0x7e, 0, 0x42, 1, 0x0b,
+		0x7f, 0, 0x41, 0xa0, 0xac, 0xcf, 0x00, 0x0b)
+	exports := testSection(7, 1, 11, '_', '_', 'h', 'e', 'a', 'p', '_', 'b', 'a', 's', 'e', 3, 2)
+	mutableExports := testSection(7, 1, 11, '_', '_', 'h', 'e', 'a', 'p', '_', 'b', 'a', 's', 'e', 3, 1)
+
+	tests := map[string]struct {
+		code []byte
+		info *ModuleInfo
+		err  error
+	}{
+		"imported memory": {
+			code: testModule(imports, globals, exports),
+			info: &ModuleInfo{
+				HeapBase:          1300000,
+				MemoryImported:    true,
+				MemoryMin:         17,
+				ImportedFunctions: []string{"ext_a"},
+			},
+		},
+		"defined memory without heap base": {
+			code: testModule(memory),
+			info: &ModuleInfo{
+				HeapBase:  DefaultHeapBase,
+				MemoryMin: 2,
+				MemoryMax: uint32Ptr(4),
+			},
+		},
+		"heap base is not an i32 constant": {
+			code: testModule(imports, globals, mutableExports),
+			err:  errors.New("__heap_base is not an i32 constant global"),
+		},
+		"invalid header": {
+			code: []byte{1, 2, 3},
+			err:  errors.New("invalid wasm module header"),
+		},
+		"truncated section": {
+			code: testModule(memory)[:10],
+			err:  errors.New("cannot read section 5: unexpected EOF"),
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			info, err := ParseModuleInfo(test.code)
+			if test.err != nil {
+				require.EqualError(t, err, test.err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.info, info)
+		})
+	}
+}
+
+func TestModuleInfo_MemoryLimit(t *testing.T) {
+	info := &ModuleInfo{
+		HeapBase:  PageSize * 10,
+		MemoryMin: 20,
+	}
+
+	limit, err := info.MemoryLimit(DefaultHeapPages)
+	require.NoError(t, err)
+	require.Equal(t, uint32(20+DefaultHeapPages), limit)
+
+	limit, err = info.MemoryLimit(1 << 20)
+	require.NoError(t, err)
+	require.Equal(t, uint32(maxMemoryPages), limit)
+
+	info.MemoryMax = uint32Ptr(100)
+	limit, err = info.MemoryLimit(DefaultHeapPages)
+	require.NoError(t, err)
+	require.Equal(t, uint32(100), limit)
+
+	info.MemoryMax = uint32Ptr(10)
+	_, err = info.MemoryLimit(DefaultHeapPages)
+	require.EqualError(t, err, "maximum memory pages 10 are less than the initial memory pages 20")
+
+	info.MemoryMin = 5
+	_, err = info.MemoryLimit(DefaultHeapPages)
+	require.ErrorIs(t, err, ErrMemoryLimitExceeded)
+}
+
+func TestHeapPages(t *testing.T) {
+	heapPages, err := HeapPages(nil)
+	require.NoError(t, err)
+	require.Equal(t, uint64(DefaultHeapPages), heapPages)
+
+	value := make([]byte, 8)
+	binary.LittleEndian.PutUint64(value, 64)
+
+	heapPages, err = HeapPages(&heapPagesStorage{heapPages: value})
+	require.NoError(t, err)
+	require.Equal(t, uint64(64), heapPages)
+
+	heapPages, err = HeapPages(&heapPagesStorage{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(DefaultHeapPages), heapPages)
+
+	_, err = HeapPages(&heapPagesStorage{heapPages: []byte{1}})
+	require.EqualError(t, err, "invalid :heappages value 0x01: expected 8 bytes")
+}
+
+func TestLimitedMemory_Grow(t *testing.T) {
+	mem := NewLimitedMemory(newMemoryMock(PageSize), 2)
+	allocator := NewAllocator(mem, 0)
+
+	_, err := allocator.Allocate(PageSize / 2)
+	require.NoError(t, err)
+
+	_, err = allocator.Allocate(PageSize * 2)
+	require.ErrorIs(t, err, ErrMemoryLimitExceeded)
+	require.Equal(t, uint32(PageSize), mem.Length())
+}
+
+func TestDecompressWasm(t *testing.T) {
+	encoder, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+
+	cases := []struct {
+		in       []byte
+		expected []byte
+		msg      string
+	}{
+		{
+			[]byte{82, 188, 83, 118, 70, 219, 142},
+			[]byte{82, 188, 83, 118, 70, 219, 142},
+			"partial compression flag",
+		},
+		{
+			[]byte{82, 188, 83, 118, 70, 219, 142, 6},
+			[]byte{82, 188, 83, 118, 70, 219, 142, 6},
+			"wrong compression flag",
+		},
+		{
+			[]byte{82, 188, 83, 118, 70, 219, 142, 6, 221},
+			[]byte{82, 188, 83, 118, 70, 219, 142, 6, 221},
+			"wrong compression flag with data",
+		},
+		{
+			append([]byte{82, 188, 83, 118, 70, 219, 142, 5}, encoder.EncodeAll([]byte("compressed"), nil)...),
+			[]byte("compressed"),
+			"compressed data",
+		},
+	}
+
+	for _, test := range cases {
+		actual, err := DecompressWasm(test.in)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, actual)
+	}
+}
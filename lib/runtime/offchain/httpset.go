@@ -7,9 +7,14 @@
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
 )
 
 type contextKey string
@@ -19,7 +24,10 @@
 	invalidKey contextKey = "invalid"
 )
 
-const maxConcurrentRequests = 1000
+const (
+	maxConcurrentRequests = 1000
+	readBufferSize        = 4096
+)
 
 var (
 	errIntBufferEmpty        = errors.New("int buffer exhausted")
@@ -29,6 +37,64 @@
 	errInvalidHeaderKey      = errors.New("invalid header key")
 )
 
+// HTTPError is the error returned by the offchain http functions to the runtime.
+// Its values match the codec indexes of substrate's HttpError, which start at 1.
+type HTTPError byte
+
+const (
+	// DeadlineReached is returned when the deadline was reached before the operation completed
+	DeadlineReached HTTPError = iota + 1
+	// IOError is returned when the request failed, or is not in a state allowing the operation
+	IOError
+	// InvalidID is returned when there is no request with the given id
+	InvalidID
+)
+
+func (e HTTPError) Error() string {
+	switch e {
+	case DeadlineReached:
+		return "deadline reached"
+	case IOError:
+		return "io error"
+	case InvalidID:
+		return "invalid request id"
+	default:
+		return fmt.Sprintf("unknown http error %d", byte(e))
+	}
+}
+
+// HTTPRequestStatus is the status of a request returned by ResponseWait
+type HTTPRequestStatus = scale.VaryingDataTypeValue
+
+// DeadlineReachedStatus is the status of a request whose response was not received before the deadline
+type DeadlineReachedStatus struct{}
+
+// Index returns the VDT index
+func (DeadlineReachedStatus) Index() uint { return 0 }
+
+// IOErrorStatus is the status of a request which failed
+type IOErrorStatus struct{}
+
+// Index returns the VDT index
+func (IOErrorStatus) Index() uint { return 1 }
+
+// InvalidStatus is the status of an unknown request
+type InvalidStatus struct{}
+
+// Index returns the VDT index
+func (InvalidStatus) Index() uint { return 2 }
+
+// FinishedStatus is the status of a request whose response was received, it holds the response status code
+type FinishedStatus uint16
+
+// Index returns the VDT index
+func (FinishedStatus) Index() uint { return 3 }
+
+// NewHTTPRequestStatus returns a new HTTPRequestStatus VaryingDataType
+func NewHTTPRequestStatus() scale.VaryingDataType {
+	return scale.MustNewVaryingDataType(DeadlineReachedStatus{}, IOErrorStatus{}, InvalidStatus{}, FinishedStatus(0))
+}
+
 // requestIDBuffer created to control the amount of available non-duplicated ids
 type requestIDBuffer chan int16
 
@@ -64,6 +130,23 @@ func (b requestIDBuffer) put(i int16) error {
 // the request starts or is waiting to be read
 type Request struct {
 	Request *http.Request
+
+	client *http.Client
+	cancel context.CancelFunc
+
+	mu         sync.Mutex
+	dispatched bool
+	bodyWriter *io.PipeWriter
+
+	// done is closed once the response headers are received or the request failed
+	done     chan struct{}
+	response *http.Response
+	err      error
+
+	// chunks receives the response body, it is closed once the body is read or failed to be read
+	chunks   chan []byte
+	readErr  error
+	leftover []byte
 }
 
 // AddHeader adds a new HTTP header into request property, only if request is valid
@@ -73,6 +156,13 @@ func (r *Request) AddHeader(name, value string) error {
 		return errRequestInvalid
 	}
 
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dispatched {
+		return errRequestInvalid
+	}
+
 	name = strings.TrimSpace(name)
 	if len(name) == 0 {
 		return fmt.Errorf("%w: empty header key", errInvalidHeaderKey)
@@ -82,11 +172,167 @@ func (r *Request) AddHeader(name, value string) error {
 	return nil
 }
 
+// WriteBody writes a chunk of the request body, dispatching the request if it was not yet.
+// An empty chunk finalises the body. It returns DeadlineReached if the chunk could not be
+// written before the deadline, and IOError if the request failed or its body was already finalised.
+func (r *Request) WriteBody(chunk []byte, deadline *time.Time) error {
+	r.mu.Lock()
+	if !r.dispatched {
+		reader, writer := io.Pipe()
+		r.Request.Body = reader
+		r.bodyWriter = writer
+		r.dispatch()
+	}
+
+	writer := r.bodyWriter
+	if len(chunk) == 0 {
+		r.bodyWriter = nil
+	}
+	r.mu.Unlock()
+
+	if writer == nil {
+		return IOError
+	}
+
+	if len(chunk) == 0 {
+		return writer.Close()
+	}
+
+	ctx, cancel := withDeadline(deadline)
+	defer cancel()
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := writer.Write(chunk)
+		written <- err
+	}()
+
+	select {
+	case err := <-written:
+		if err != nil {
+			return IOError
+		}
+		return nil
+	case <-ctx.Done():
+		return DeadlineReached
+	}
+}
+
+// dispatch sends the request in the background, it must be called with the lock held
+func (r *Request) dispatch() {
+	r.dispatched = true
+	r.done = make(chan struct{})
+
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	go func() {
+		resp, err := client.Do(r.Request)
+
+		r.mu.Lock()
+		r.response, r.err = resp, err
+		if err == nil {
+			r.chunks = make(chan []byte)
+			go r.readBody(resp.Body)
+		}
+		r.mu.Unlock()
+
+		close(r.done)
+	}()
+}
+
+// finaliseBody dispatches the request if it was not yet, and finalises its body
+func (r *Request) finaliseBody() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.dispatched {
+		r.dispatch()
+	}
+
+	if r.bodyWriter != nil {
+		_ = r.bodyWriter.Close()
+		r.bodyWriter = nil
+	}
+}
+
+func (r *Request) readBody(body io.ReadCloser) {
+	defer body.Close()
+	defer close(r.chunks)
+
+	ctx := r.Request.Context()
+	for {
+		buf := make([]byte, readBufferSize)
+		n, err := body.Read(buf)
+		if n > 0 {
+			select {
+			case r.chunks <- buf[:n]:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		if err != nil {
+			r.mu.Lock()
+			r.readErr = err
+			r.mu.Unlock()
+			return
+		}
+	}
+}
+
+// wait waits for the response of the dispatched request until the deadline
+func (r *Request) wait(ctx context.Context) HTTPRequestStatus {
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return DeadlineReachedStatus{}
+	}
+
+	if r.err != nil {
+		return IOErrorStatus{}
+	}
+
+	return FinishedStatus(r.response.StatusCode)
+}
+
+// readChunk reads the next part of the response body into buf, it returns zero once
+// the whole body was read.
+func (r *Request) readChunk(ctx context.Context, buf []byte) (int, error) {
+	if len(r.leftover) == 0 {
+		select {
+		case chunk, ok := <-r.chunks:
+			if !ok {
+				r.mu.Lock()
+				defer r.mu.Unlock()
+				if r.readErr != nil {
+					return 0, IOError
+				}
+				return 0, nil
+			}
+			r.leftover = chunk
+		case <-ctx.Done():
+			return 0, DeadlineReached
+		}
+	}
+
+	n := copy(buf, r.leftover)
+	r.leftover = r.leftover[n:]
+	return n, nil
+}
+
 // HTTPSet holds a pool of concurrent http request calls
 type HTTPSet struct {
 	*sync.Mutex
 	reqs   map[int16]*Request
 	idBuff requestIDBuffer
+	client *http.Client
 }
 
 // NewHTTPSet creates a offchain http set that can be used
@@ -96,6 +342,7 @@ func NewHTTPSet() *HTTPSet {
 		new(sync.Mutex),
 		make(map[int16]*Request),
 		newIntBuffer(maxConcurrentRequests),
+		&http.Client{},
 	}
 }
 
@@ -114,20 +361,22 @@ func (p *HTTPSet) StartRequest(method, uri string) (int16, error) {
 		return 0, errRequestIDNotAvailable
 	}
 
-	req, err := http.NewRequest(method, uri, nil)
-	req.Header = make(http.Header)
-
-	ctx := context.WithValue(req.Context(), waitingKey, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = context.WithValue(ctx, waitingKey, false)
 	ctx = context.WithValue(ctx, invalidKey, false)
 
-	req = req.WithContext(ctx)
-
+	req, err := http.NewRequestWithContext(ctx, method, uri, nil)
 	if err != nil {
+		cancel()
+		_ = p.idBuff.put(id)
 		return 0, err
 	}
+	req.Header = make(http.Header)
 
 	p.reqs[id] = &Request{
 		Request: req,
+		client:  p.client,
+		cancel:  cancel,
 	}
 
 	return id, nil
@@ -138,6 +387,9 @@ func (p *HTTPSet) Remove(id int16) error {
 	p.Lock()
 	defer p.Unlock()
 
+	if req, ok := p.reqs[id]; ok && req.cancel != nil {
+		req.cancel()
+	}
 	delete(p.reqs, id)
 
 	return p.idBuff.put(id)
@@ -150,3 +402,112 @@ func (p *HTTPSet) Get(id int16) *Request {
 
 	return p.reqs[id]
 }
+
+// ResponseWait dispatches the given requests and finalises their body if needed, then waits for
+// their response until the deadline. A nil deadline waits without limit.
+func (p *HTTPSet) ResponseWait(ids []int16, deadline *time.Time) []HTTPRequestStatus {
+	reqs := make([]*Request, len(ids))
+	for i, id := range ids {
+		reqs[i] = p.Get(id)
+		if reqs[i] != nil {
+			reqs[i].finaliseBody()
+		}
+	}
+
+	ctx, cancel := withDeadline(deadline)
+	defer cancel()
+
+	statuses := make([]HTTPRequestStatus, len(ids))
+	for i, req := range reqs {
+		if req == nil {
+			statuses[i] = InvalidStatus{}
+			continue
+		}
+
+		statuses[i] = req.wait(ctx)
+	}
+
+	return statuses
+}
+
+// ResponseHeaders returns the headers of the response of the request, sorted by name.
+// It returns no headers if the response was not received yet.
+func (p *HTTPSet) ResponseHeaders(id int16) [][2][]byte {
+	req := p.Get(id)
+	if req == nil {
+		return nil
+	}
+
+	req.mu.Lock()
+	defer req.mu.Unlock()
+
+	if req.response == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(req.response.Header))
+	for name := range req.response.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var headers [][2][]byte
+	for _, name := range names {
+		for _, value := range req.response.Header[name] {
+			headers = append(headers, [2][]byte{[]byte(name), []byte(value)})
+		}
+	}
+
+	return headers
+}
+
+// ReadBody reads the next part of the response body of the request into buf, waiting for
+// the response until the deadline if it was not received yet. It returns zero once the whole
+// body was read, the request is then removed from the set.
+func (p *HTTPSet) ReadBody(id int16, buf []byte, deadline *time.Time) (int, error) {
+	req := p.Get(id)
+	if req == nil {
+		return 0, InvalidID
+	}
+
+	req.mu.Lock()
+	dispatched := req.dispatched
+	req.mu.Unlock()
+
+	if !dispatched {
+		return 0, IOError
+	}
+
+	ctx, cancel := withDeadline(deadline)
+	defer cancel()
+
+	switch req.wait(ctx).(type) {
+	case DeadlineReachedStatus:
+		return 0, DeadlineReached
+	case IOErrorStatus:
+		_ = p.Remove(id)
+		return 0, IOError
+	}
+
+	n, err := req.readChunk(ctx, buf)
+	if err != nil {
+		if errors.Is(err, IOError) {
+			_ = p.Remove(id)
+		}
+		return 0, err
+	}
+
+	if n == 0 {
+		_ = p.Remove(id)
+	}
+
+	return n, nil
+}
+
+func withDeadline(deadline *time.Time) (context.Context, context.CancelFunc) {
+	if deadline == nil {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithDeadline(context.Background(), *deadline)
+}
@@ -6,9 +6,13 @@
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/ChainSafe/gossamer/pkg/scale"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,21 +63,21 @@ func TestOffchainRequest_AddHeader(t *testing.T) {
 	require.NoError(t, err)
 
 	cases := map[string]struct {
-		offReq           Request
+		offReq           *Request
 		err              error
 		headerK, headerV string
 	}{
 		"should return invalid request": {
-			offReq: Request{invalidReq},
+			offReq: &Request{Request: invalidReq},
 			err:    errRequestInvalid,
 		},
 		"should add header": {
-			offReq:  Request{Request: &http.Request{Header: make(http.Header)}},
+			offReq:  &Request{Request: &http.Request{Header: make(http.Header)}},
 			headerK: "key",
 			headerV: "value",
 		},
 		"should return invalid empty header": {
-			offReq:  Request{Request: &http.Request{Header: make(http.Header)}},
+			offReq:  &Request{Request: &http.Request{Header: make(http.Header)}},
 			headerK: "",
 			headerV: "value",
 			err:     fmt.Errorf("%w: %s", errInvalidHeaderKey, "empty header key"),
@@ -101,3 +105,120 @@ func TestOffchainRequest_AddHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPSet_Response(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Test", r.Header.Get("X-Test"))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	set := NewHTTPSet()
+	id, err := set.StartRequest(http.MethodPost, server.URL)
+	require.NoError(t, err)
+
+	req := set.Get(id)
+	require.NoError(t, req.AddHeader("X-Test", "value"))
+	require.NoError(t, req.WriteBody([]byte("hello "), nil))
+	require.NoError(t, req.WriteBody([]byte("world"), nil))
+
+	// headers cannot be added once the request is dispatched
+	require.ErrorIs(t, req.AddHeader("X-Other", "value"), errRequestInvalid)
+
+	statuses := set.ResponseWait([]int16{id, id + 1}, nil)
+	require.Equal(t, []HTTPRequestStatus{FinishedStatus(http.StatusCreated), InvalidStatus{}}, statuses)
+
+	// the body is finalised by ResponseWait
+	require.ErrorIs(t, req.WriteBody([]byte("!"), nil), IOError)
+
+	headers := set.ResponseHeaders(id)
+	require.Contains(t, headers, [2][]byte{[]byte("X-Method"), []byte(http.MethodPost)})
+	require.Contains(t, headers, [2][]byte{[]byte("X-Test"), []byte("value")})
+
+	var body []byte
+	buf := make([]byte, 4)
+	for {
+		n, err := set.ReadBody(id, buf, nil)
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+		body = append(body, buf[:n]...)
+	}
+
+	require.Equal(t, "hello world", string(body))
+
+	// the request is removed once its body is read
+	require.Nil(t, set.Get(id))
+	_, err = set.ReadBody(id, buf, nil)
+	require.ErrorIs(t, err, InvalidID)
+}
+
+func TestHTTPSet_ResponseWait_DeadlineReached(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	set := NewHTTPSet()
+	id, err := set.StartRequest(http.MethodGet, server.URL)
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(50 * time.Millisecond)
+	statuses := set.ResponseWait([]int16{id}, &deadline)
+	require.Equal(t, []HTTPRequestStatus{DeadlineReachedStatus{}}, statuses)
+
+	_, err = set.ReadBody(id, make([]byte, 1), &deadline)
+	require.ErrorIs(t, err, DeadlineReached)
+	require.Empty(t, set.ResponseHeaders(id))
+}
+
+func TestHTTPSet_ResponseWait_IOError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	set := NewHTTPSet()
+	id, err := set.StartRequest(http.MethodGet, server.URL)
+	require.NoError(t, err)
+
+	_, err = set.ReadBody(id, make([]byte, 1), nil)
+	require.ErrorIs(t, err, IOError)
+
+	statuses := set.ResponseWait([]int16{id}, nil)
+	require.Equal(t, []HTTPRequestStatus{IOErrorStatus{}}, statuses)
+}
+
+func TestHTTPError_Encode(t *testing.T) {
+	for _, tt := range []struct {
+		err      HTTPError
+		expected []byte
+	}{
+		{err: DeadlineReached, expected: []byte{1}},
+		{err: IOError, expected: []byte{2}},
+		{err: InvalidID, expected: []byte{3}},
+	} {
+		enc, err := scale.Marshal(tt.err)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, enc, tt.err.Error())
+
+		result := scale.NewResult(nil, HTTPError(0))
+		err = result.Set(scale.Err, tt.err)
+		require.NoError(t, err)
+		enc, err = scale.Marshal(result)
+		require.NoError(t, err)
+		require.Equal(t, append([]byte{1}, tt.expected...), enc, tt.err.Error())
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// kinds of the entities of an environment definition
+const (
+	externFunction byte = 1
+	externMemory   byte = 2
+)
+
+// environmentEntry is an entity given by the supervisor to be imported by a sandboxed instance
+type environmentEntry struct {
+	ModuleName []byte
+	FieldName  []byte
+	// Kind is either externFunction or externMemory
+	Kind byte
+	// Index is the index of the supervisor function to dispatch the calls to, or the index of the memory
+	Index uint32
+}
+
+// environment maps the module and field names of the imports to the entities given by the supervisor
+type environment map[[2]string]environmentEntry
+
+func decodeEnvironmentDefinition(enc []byte) (environment, error) {
+	var entries []environmentEntry
+	err := scale.Unmarshal(enc, &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(environment, len(entries))
+	for _, entry := range entries {
+		if entry.Kind != externFunction && entry.Kind != externMemory {
+			return nil, fmt.Errorf("unknown entity kind %d for %s:%s", entry.Kind, entry.ModuleName, entry.FieldName)
+		}
+
+		env[[2]string{string(entry.ModuleName), string(entry.FieldName)}] = entry
+	}
+
+	return env, nil
+}
+
+// lookup returns the entity of the given kind for the import
+func (e environment) lookup(module, field string, kind byte) (uint32, error) {
+	entry, ok := e[[2]string{module, field}]
+	if !ok {
+		return 0, fmt.Errorf("%w: import %s:%s not found in environment", ErrModule, module, field)
+	}
+
+	if entry.Kind != kind {
+		return 0, fmt.Errorf("%w: import %s:%s has an unexpected kind", ErrModule, module, field)
+	}
+
+	return entry.Index, nil
+}
@@ -0,0 +1,296 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/go-interpreter/wagon/wasm"
+	"github.com/go-interpreter/wagon/wasm/leb128"
+	"github.com/perlin-network/life/exec"
+)
+
+// opcodes of the constant expressions of the data segment offsets
+const (
+	opI32Const byte = 0x41
+	opEnd      byte = 0x0b
+)
+
+// Instance is a sandboxed wasm instance, run by the life interpreter
+type Instance struct {
+	sandbox *Sandbox
+	thunk   uint32
+	vm      *exec.VirtualMachine
+	// memory is the supervisor memory imported by the instance, if any
+	memory *Memory
+	// functions maps the module and field names of the imported functions to the supervisor functions
+	functions map[[2]string]importedFunction
+	// state is the opaque value given by the supervisor for the current invocation
+	state uint32
+}
+
+type importedFunction struct {
+	index     uint32
+	signature *wasm.FunctionSig
+}
+
+func newInstance(s *Sandbox, thunk uint32, code []byte, env environment) (*Instance, error) {
+	module, err := wasm.DecodeModule(bytes.NewReader(code))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrModule, err)
+	}
+
+	instance := &Instance{
+		sandbox:   s,
+		thunk:     thunk,
+		functions: make(map[[2]string]importedFunction),
+	}
+
+	if module.Import != nil {
+		for _, entry := range module.Import.Entries {
+			err = instance.resolveImport(module, entry, env)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	cfg := exec.VMConfig{}
+	if instance.memory != nil {
+		cfg.DefaultMemoryPages = int(instance.memory.pages())
+		cfg.MaxMemoryPages = int(instance.memory.maximum)
+	}
+
+	instance.vm, err = exec.NewVirtualMachine(code, cfg, instance, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrModule, err)
+	}
+
+	if instance.memory != nil {
+		// the data segments are copied again, as the interpreter creates its own memory for the imported memory
+		err = instance.initMemory()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return instance, nil
+}
+
+// resolveImport checks the import is given by the environment definition
+func (in *Instance) resolveImport(module *wasm.Module, entry wasm.ImportEntry, env environment) error {
+	switch imp := entry.Type.(type) {
+	case wasm.FuncImport:
+		index, err := env.lookup(entry.ModuleName, entry.FieldName, externFunction)
+		if err != nil {
+			return err
+		}
+
+		if module.Types == nil || int(imp.Type) >= len(module.Types.Entries) {
+			return fmt.Errorf("%w: invalid type of import %s:%s", ErrModule, entry.ModuleName, entry.FieldName)
+		}
+
+		in.functions[[2]string{entry.ModuleName, entry.FieldName}] = importedFunction{
+			index:     index,
+			signature: &module.Types.Entries[imp.Type],
+		}
+	case wasm.MemoryImport:
+		index, err := env.lookup(entry.ModuleName, entry.FieldName, externMemory)
+		if err != nil {
+			return err
+		}
+
+		memory, ok := in.sandbox.memories[index]
+		if !ok {
+			return fmt.Errorf("%w: %s %d", ErrModule, ErrInvalidMemoryIndex, index)
+		}
+
+		if memory.pages() < imp.Type.Limits.Initial {
+			return fmt.Errorf("%w: memory %d is smaller than the imported memory", ErrModule, index)
+		}
+
+		in.memory = memory
+	default:
+		return fmt.Errorf("%w: unsupported import %s:%s", ErrModule, entry.ModuleName, entry.FieldName)
+	}
+
+	return nil
+}
+
+// initMemory copies the data segments of the module into the imported memory
+func (in *Instance) initMemory() error {
+	data := in.vm.Module.Base.Data
+	if data == nil {
+		return nil
+	}
+
+	for _, segment := range data.Entries {
+		offset, err := constOffset(segment.Offset)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrModule, err)
+		}
+
+		dst, err := slice(in.memory.data, offset, uint32(len(segment.Data)))
+		if err != nil {
+			return fmt.Errorf("%w: data segment: %s", ErrModule, err)
+		}
+
+		copy(dst, segment.Data)
+	}
+
+	return nil
+}
+
+// constOffset returns the value of an i32.const offset expression
+func constOffset(expr []byte) (uint32, error) {
+	if len(expr) < 2 || expr[0] != opI32Const || expr[len(expr)-1] != opEnd {
+		return 0, errors.New("unsupported offset expression")
+	}
+
+	offset, err := leb128.ReadVarint32(bytes.NewReader(expr[1 : len(expr)-1]))
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(offset), nil
+}
+
+// ResolveFunc returns the function calling the supervisor function through the dispatch thunk,
+// it implements exec.ImportResolver
+func (in *Instance) ResolveFunc(module, field string) exec.FunctionImport {
+	function, ok := in.functions[[2]string{module, field}]
+	if !ok {
+		// the imports are checked when the instance is created
+		panic(fmt.Sprintf("unknown import %s:%s", module, field))
+	}
+
+	return func(vm *exec.VirtualMachine) int64 {
+		locals := vm.GetCurrentFrame().Locals
+		args := make([]scale.VaryingDataTypeValue, len(function.signature.ParamTypes))
+		for i, paramType := range function.signature.ParamTypes {
+			arg, err := newValue(paramType, locals[i])
+			if err != nil {
+				panic(err)
+			}
+			args[i] = arg
+		}
+
+		// the memory may have been grown by the instance
+		in.syncMemory()
+
+		ret, err := in.sandbox.dispatch(in.thunk, in.state, function.index, args)
+		if err != nil {
+			panic(err)
+		}
+
+		if len(function.signature.ReturnTypes) == 0 {
+			return 0
+		}
+
+		raw, err := rawValue(function.signature.ReturnTypes[0], ret)
+		if err != nil {
+			panic(err)
+		}
+		return raw
+	}
+}
+
+// ResolveGlobal implements exec.ImportResolver, global imports are not supported
+func (*Instance) ResolveGlobal(module, field string) int64 {
+	panic(fmt.Sprintf("unsupported global import %s:%s", module, field))
+}
+
+// start runs the start function of the module, if any
+func (in *Instance) start(state uint32) error {
+	if in.vm.Module.Base.Start == nil {
+		return nil
+	}
+
+	_, err := in.run(int(in.vm.Module.Base.Start.Index), nil, state)
+	return err
+}
+
+// invoke calls the exported function with the arguments, and returns its return value or nil
+func (in *Instance) invoke(name string, args []scale.VaryingDataTypeValue, state uint32) (
+	scale.VaryingDataTypeValue, error) {
+	index, ok := in.vm.GetFunctionExport(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: function %s not found", ErrExecution, name)
+	}
+
+	signature := in.signature(index)
+	if signature == nil || len(signature.ParamTypes) != len(args) {
+		return nil, fmt.Errorf("%w: invalid arguments for function %s", ErrExecution, name)
+	}
+
+	params := make([]int64, len(args))
+	for i, arg := range args {
+		param, err := rawValue(signature.ParamTypes[i], arg)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrExecution, err)
+		}
+		params[i] = param
+	}
+
+	ret, err := in.run(index, params, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signature.ReturnTypes) == 0 {
+		return nil, nil
+	}
+
+	return newValue(signature.ReturnTypes[0], ret)
+}
+
+// signature returns the signature of the function of the module, which is not imported
+func (in *Instance) signature(index int) *wasm.FunctionSig {
+	index -= len(in.vm.FunctionImports)
+	functions := in.vm.Module.Base.FunctionIndexSpace
+	if index < 0 || index >= len(functions) {
+		return nil
+	}
+
+	return functions[index].Sig
+}
+
+// run runs the function of the module with the state given by the supervisor
+func (in *Instance) run(index int, params []int64, state uint32) (ret int64, err error) {
+	if in.memory != nil {
+		in.vm.Memory = in.memory.data
+	}
+
+	previousState := in.state
+	in.state = state
+	defer func() {
+		in.state = previousState
+		in.syncMemory()
+
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrExecution, r)
+		}
+	}()
+
+	// the interpreter cannot be ignited again after a trap
+	in.vm.ExitError = nil
+	in.vm.CurrentFrame = -1
+
+	ret, err = in.vm.Run(index, params...)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrExecution, err)
+	}
+
+	return ret, nil
+}
+
+// syncMemory updates the imported memory with the memory of the interpreter, which is replaced when grown
+func (in *Instance) syncMemory() {
+	if in.memory != nil {
+		in.memory.data = in.vm.Memory
+	}
+}
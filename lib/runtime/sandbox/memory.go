@@ -0,0 +1,41 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/perlin-network/life/exec"
+)
+
+// maxPages is the maximum number of pages of a 32 bit wasm memory
+const maxPages = 1 << 16
+
+// Memory is a linear memory created by the supervisor, which can be imported by sandboxed instances
+type Memory struct {
+	data []byte
+	// maximum is the maximum number of pages of the memory, 0 if there is no maximum
+	maximum uint32
+}
+
+func newMemory(initial, maximum uint32) (*Memory, error) {
+	if maximum == math.MaxUint32 {
+		maximum = 0
+	}
+
+	if initial > maxPages || maximum > maxPages || (maximum != 0 && initial > maximum) {
+		return nil, fmt.Errorf("%w: invalid memory limits, initial %d and maximum %d", ErrModule, initial, maximum)
+	}
+
+	return &Memory{
+		data:    make([]byte, int(initial)*exec.DefaultPageSize),
+		maximum: maximum,
+	}, nil
+}
+
+// pages returns the current number of pages of the memory
+func (m *Memory) pages() uint32 {
+	return uint32(len(m.data) / exec.DefaultPageSize)
+}
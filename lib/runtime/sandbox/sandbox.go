@@ -0,0 +1,267 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+)
+
+// Result codes returned to the supervisor by the sandbox host functions
+const (
+	// ResultOK is returned when the operation succeeded
+	ResultOK uint32 = 0
+	// ResultExecution is returned when the execution of the sandboxed instance trapped
+	ResultExecution uint32 = math.MaxUint32
+	// ResultModule is returned when the module could not be instantiated
+	ResultModule uint32 = math.MaxUint32 - 1
+	// ResultOutOfBounds is returned when a memory access is out of bounds
+	ResultOutOfBounds uint32 = math.MaxUint32 - 2
+)
+
+var (
+	// ErrExecution is returned when the execution of a sandboxed instance traps
+	ErrExecution = errors.New("sandboxed execution trapped")
+	// ErrModule is returned when a sandboxed module cannot be instantiated
+	ErrModule = errors.New("cannot instantiate sandboxed module")
+	// ErrOutOfBounds is returned when an access to a sandboxed memory is out of bounds
+	ErrOutOfBounds = errors.New("memory access out of bounds")
+	// ErrInvalidMemoryIndex is returned when there is no sandboxed memory with the given index
+	ErrInvalidMemoryIndex = errors.New("invalid sandboxed memory index")
+	// ErrInvalidInstanceIndex is returned when there is no sandboxed instance with the given index
+	ErrInvalidInstanceIndex = errors.New("invalid sandboxed instance index")
+)
+
+// ResultCode returns the result code to return to the supervisor for the error
+func ResultCode(err error) uint32 {
+	switch {
+	case err == nil:
+		return ResultOK
+	case errors.Is(err, ErrModule):
+		return ResultModule
+	case errors.Is(err, ErrOutOfBounds), errors.Is(err, ErrInvalidMemoryIndex):
+		return ResultOutOfBounds
+	default:
+		return ResultExecution
+	}
+}
+
+// Supervisor is the runtime instance creating and running the sandboxed instances
+type Supervisor interface {
+	// Memory returns the linear memory of the supervisor
+	Memory() []byte
+	// Allocate allocates size bytes in the memory of the supervisor
+	Allocate(size uint32) (uint32, error)
+	// Deallocate frees memory allocated in the memory of the supervisor
+	Deallocate(pointer uint32) error
+	// DispatchThunk calls the function at index thunk of the table of the supervisor, passing the
+	// serialised arguments, the state and the index of the supervisor function to dispatch the call to.
+	// It returns the pointer and size of the serialised result, packed as (pointer << 32) | size.
+	DispatchThunk(thunk, argsPointer, argsSize, state, function uint32) (int64, error)
+}
+
+// Sandbox holds the memories and instances sandboxed by a supervisor
+type Sandbox struct {
+	supervisor Supervisor
+
+	memories     map[uint32]*Memory
+	nextMemory   uint32
+	instances    map[uint32]*Instance
+	nextInstance uint32
+}
+
+// New creates a new sandbox for the supervisor
+func New(supervisor Supervisor) *Sandbox {
+	return &Sandbox{
+		supervisor: supervisor,
+		memories:   make(map[uint32]*Memory),
+		instances:  make(map[uint32]*Instance),
+	}
+}
+
+// NewMemory creates a new sandboxed memory with the given initial and maximum number of pages,
+// and returns its index. There is no maximum if it is math.MaxUint32.
+func (s *Sandbox) NewMemory(initial, maximum uint32) (uint32, error) {
+	memory, err := newMemory(initial, maximum)
+	if err != nil {
+		return 0, err
+	}
+
+	index := s.nextMemory
+	s.memories[index] = memory
+	s.nextMemory++
+	return index, nil
+}
+
+// MemoryGet copies size bytes at offset of the sandboxed memory into the supervisor memory at pointer
+func (s *Sandbox) MemoryGet(index, offset, pointer, size uint32) error {
+	memory, ok := s.memories[index]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrInvalidMemoryIndex, index)
+	}
+
+	src, err := slice(memory.data, offset, size)
+	if err != nil {
+		return err
+	}
+
+	dst, err := slice(s.supervisor.Memory(), pointer, size)
+	if err != nil {
+		return err
+	}
+
+	copy(dst, src)
+	return nil
+}
+
+// MemorySet copies size bytes at pointer of the supervisor memory into the sandboxed memory at offset
+func (s *Sandbox) MemorySet(index, offset, pointer, size uint32) error {
+	memory, ok := s.memories[index]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrInvalidMemoryIndex, index)
+	}
+
+	src, err := slice(s.supervisor.Memory(), pointer, size)
+	if err != nil {
+		return err
+	}
+
+	dst, err := slice(memory.data, offset, size)
+	if err != nil {
+		return err
+	}
+
+	copy(dst, src)
+	return nil
+}
+
+// TeardownMemory removes the sandboxed memory
+func (s *Sandbox) TeardownMemory(index uint32) error {
+	if _, ok := s.memories[index]; !ok {
+		return fmt.Errorf("%w: %d", ErrInvalidMemoryIndex, index)
+	}
+
+	delete(s.memories, index)
+	return nil
+}
+
+// Instantiate instantiates the wasm module with the imports given by the encoded environment definition,
+// runs its start function and returns the index of the instance. The imported functions are called through
+// the dispatch thunk of the supervisor.
+func (s *Sandbox) Instantiate(thunk uint32, code, envDef []byte, state uint32) (uint32, error) {
+	env, err := decodeEnvironmentDefinition(envDef)
+	if err != nil {
+		return 0, fmt.Errorf("%w: cannot decode environment definition: %s", ErrModule, err)
+	}
+
+	instance, err := newInstance(s, thunk, code, env)
+	if err != nil {
+		return 0, err
+	}
+
+	err = instance.start(state)
+	if err != nil {
+		return 0, err
+	}
+
+	index := s.nextInstance
+	s.instances[index] = instance
+	s.nextInstance++
+	return index, nil
+}
+
+// Invoke calls the exported function of the sandboxed instance with the encoded arguments,
+// and returns the encoded ReturnValue
+func (s *Sandbox) Invoke(index uint32, function string, args []byte, state uint32) ([]byte, error) {
+	instance, ok := s.instances[index]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidInstanceIndex, index)
+	}
+
+	values, err := decodeValues(args)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode arguments: %w", err)
+	}
+
+	ret, err := instance.invoke(function, values, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return EncodeReturnValue(ret)
+}
+
+// TeardownInstance removes the sandboxed instance
+func (s *Sandbox) TeardownInstance(index uint32) error {
+	if _, ok := s.instances[index]; !ok {
+		return fmt.Errorf("%w: %d", ErrInvalidInstanceIndex, index)
+	}
+
+	delete(s.instances, index)
+	return nil
+}
+
+// dispatch calls the supervisor function through the dispatch thunk, with the arguments given
+// by a sandboxed instance
+func (s *Sandbox) dispatch(thunk, state, function uint32, args []scale.VaryingDataTypeValue) (
+	scale.VaryingDataTypeValue, error) {
+	enc, err := encodeValues(args)
+	if err != nil {
+		return nil, err
+	}
+
+	argsPointer, err := s.supervisor.Allocate(uint32(len(enc)))
+	if err != nil {
+		return nil, fmt.Errorf("cannot allocate arguments: %w", err)
+	}
+
+	dst, err := slice(s.supervisor.Memory(), argsPointer, uint32(len(enc)))
+	if err != nil {
+		return nil, err
+	}
+	copy(dst, enc)
+
+	packed, err := s.supervisor.DispatchThunk(thunk, argsPointer, uint32(len(enc)), state, function)
+	if err != nil {
+		return nil, fmt.Errorf("cannot call dispatch thunk: %w", err)
+	}
+
+	err = s.supervisor.Deallocate(argsPointer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot deallocate arguments: %w", err)
+	}
+
+	resultPointer, resultSize := uint32(uint64(packed)>>32), uint32(packed)
+	src, err := slice(s.supervisor.Memory(), resultPointer, resultSize)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, len(src))
+	copy(result, src)
+
+	err = s.supervisor.Deallocate(resultPointer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot deallocate result: %w", err)
+	}
+
+	// the result is a Result<ReturnValue, HostError>, where HostError is empty
+	if len(result) == 0 || result[0] != 0 {
+		return nil, errors.New("supervisor function returned an error")
+	}
+
+	return decodeReturnValue(result[1:])
+}
+
+// slice returns the size bytes of the memory at offset
+func slice(memory []byte, offset, size uint32) ([]byte, error) {
+	end := uint64(offset) + uint64(size)
+	if end > uint64(len(memory)) {
+		return nil, fmt.Errorf("%w: [%d, %d) of memory of size %d", ErrOutOfBounds, offset, end, len(memory))
+	}
+
+	return memory[offset:end], nil
+}
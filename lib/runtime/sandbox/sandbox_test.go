@@ -0,0 +1,288 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/stretchr/testify/require"
+)
+
+// wasm encoding helpers used to assemble the test modules
+
+func leb(v uint32) []byte {
+	var enc []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(enc, b)
+		}
+		enc = append(enc, b|0x80)
+	}
+}
+
+func vec(items ...[]byte) []byte {
+	enc := leb(uint32(len(items)))
+	for _, item := range items {
+		enc = append(enc, item...)
+	}
+	return enc
+}
+
+func name(s string) []byte {
+	return append(leb(uint32(len(s))), s...)
+}
+
+func section(id byte, items ...[]byte) []byte {
+	payload := vec(items...)
+	return append(append([]byte{id}, leb(uint32(len(payload)))...), payload...)
+}
+
+func body(code ...byte) []byte {
+	// no locals
+	fn := append([]byte{0}, code...)
+	fn = append(fn, opEnd)
+	return append(leb(uint32(len(fn))), fn...)
+}
+
+func module(sections ...[]byte) []byte {
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	for _, s := range sections {
+		code = append(code, s...)
+	}
+	return code
+}
+
+const i32 = 0x7f
+
+var (
+	// (i32, i32) -> i32
+	typeBinary = []byte{0x60, 2, i32, i32, 1, i32}
+	// (i32) -> i32
+	typeUnary = []byte{0x60, 1, i32, 1, i32}
+	// () -> ()
+	typeEmpty = []byte{0x60, 0, 0}
+)
+
+// exportsModule exports add(i32, i32) -> i32 and trap()
+var exportsModule = module(
+	section(1, typeBinary, typeEmpty),
+	section(3, leb(0), leb(1)),
+	section(7,
+		append(name("add"), 0, 0),
+		append(name("trap"), 0, 1),
+	),
+	section(10,
+		body(0x20, 0, 0x20, 1, 0x6a), // local.get 0, local.get 1, i32.add
+		body(0x00),                   // unreachable
+	),
+)
+
+// importsModule imports env:double(i32) -> i32 and env:memory, exports call(i32) -> i32 calling double,
+// and initialises the memory with "hello" at offset 8
+var importsModule = module(
+	section(1, typeUnary),
+	section(2,
+		append(append(name("env"), name("double")...), 0, 0),
+		append(append(name("env"), name("memory")...), 2, 0, 1),
+	),
+	section(3, leb(0)),
+	section(7, append(name("call"), 0, 1)),
+	section(10, body(0x20, 0, 0x10, 0)), // local.get 0, call 0
+	section(11, append([]byte{0, opI32Const, 8, opEnd}, name("hello")...)),
+)
+
+func environmentDefinition(t *testing.T, entries ...environmentEntry) []byte {
+	t.Helper()
+	enc, err := scale.Marshal(entries)
+	require.NoError(t, err)
+	return enc
+}
+
+// testSupervisor dispatches the calls of the sandboxed instances to Go functions
+type testSupervisor struct {
+	memory    []byte
+	next      uint32
+	functions map[uint32]func(args []scale.VaryingDataTypeValue) (scale.VaryingDataTypeValue, error)
+	states    []uint32
+}
+
+func newTestSupervisor() *testSupervisor {
+	return &testSupervisor{
+		memory:    make([]byte, 1<<16),
+		next:      1024,
+		functions: make(map[uint32]func(args []scale.VaryingDataTypeValue) (scale.VaryingDataTypeValue, error)),
+	}
+}
+
+func (s *testSupervisor) Memory() []byte { return s.memory }
+
+func (s *testSupervisor) Allocate(size uint32) (uint32, error) {
+	pointer := s.next
+	s.next += size
+	return pointer, nil
+}
+
+func (*testSupervisor) Deallocate(uint32) error { return nil }
+
+func (s *testSupervisor) DispatchThunk(_, argsPointer, argsSize, state, function uint32) (int64, error) {
+	s.states = append(s.states, state)
+
+	args, err := decodeValues(s.memory[argsPointer : argsPointer+argsSize])
+	if err != nil {
+		return 0, err
+	}
+
+	result := []byte{1}
+	ret, err := s.functions[function](args)
+	if err == nil {
+		enc, err := EncodeReturnValue(ret)
+		if err != nil {
+			return 0, err
+		}
+		result = append([]byte{0}, enc...)
+	}
+
+	pointer, _ := s.Allocate(uint32(len(result)))
+	copy(s.memory[pointer:], result)
+	return int64(pointer)<<32 | int64(len(result)), nil
+}
+
+func encodeArgs(t *testing.T, values ...scale.VaryingDataTypeValue) []byte {
+	t.Helper()
+	enc, err := encodeValues(values)
+	require.NoError(t, err)
+	return enc
+}
+
+func TestResultCode(t *testing.T) {
+	require.Equal(t, ResultOK, ResultCode(nil))
+	require.Equal(t, ResultModule, ResultCode(ErrModule))
+	require.Equal(t, ResultOutOfBounds, ResultCode(ErrOutOfBounds))
+	require.Equal(t, ResultOutOfBounds, ResultCode(ErrInvalidMemoryIndex))
+	require.Equal(t, ResultExecution, ResultCode(ErrExecution))
+	require.Equal(t, ResultExecution, ResultCode(errors.New("other")))
+}
+
+func TestSandbox_Memory(t *testing.T) {
+	supervisor := newTestSupervisor()
+	s := New(supervisor)
+
+	_, err := s.NewMemory(2, 1)
+	require.ErrorIs(t, err, ErrModule)
+
+	index, err := s.NewMemory(1, math.MaxUint32)
+	require.NoError(t, err)
+
+	copy(supervisor.memory[100:], "hello")
+	err = s.MemorySet(index, 10, 100, 5)
+	require.NoError(t, err)
+
+	err = s.MemoryGet(index, 10, 200, 5)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), supervisor.memory[200:205])
+
+	err = s.MemoryGet(index, 1<<16-2, 200, 5)
+	require.ErrorIs(t, err, ErrOutOfBounds)
+
+	err = s.MemorySet(index+1, 0, 100, 5)
+	require.ErrorIs(t, err, ErrInvalidMemoryIndex)
+
+	require.NoError(t, s.TeardownMemory(index))
+	require.ErrorIs(t, s.TeardownMemory(index), ErrInvalidMemoryIndex)
+}
+
+func TestSandbox_Invoke(t *testing.T) {
+	s := New(newTestSupervisor())
+
+	index, err := s.Instantiate(0, exportsModule, environmentDefinition(t), 0)
+	require.NoError(t, err)
+
+	ret, err := s.Invoke(index, "add", encodeArgs(t, I32(2), I32(3)), 0)
+	require.NoError(t, err)
+	expected, err := EncodeReturnValue(I32(5))
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+
+	_, err = s.Invoke(index, "add", encodeArgs(t, I32(2)), 0)
+	require.ErrorIs(t, err, ErrExecution)
+
+	_, err = s.Invoke(index, "missing", encodeArgs(t), 0)
+	require.ErrorIs(t, err, ErrExecution)
+
+	_, err = s.Invoke(index, "trap", encodeArgs(t), 0)
+	require.ErrorIs(t, err, ErrExecution)
+
+	// the instance can still be invoked after a trap
+	ret, err = s.Invoke(index, "add", encodeArgs(t, I32(-1), I32(3)), 0)
+	require.NoError(t, err)
+	expected, err = EncodeReturnValue(I32(2))
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+
+	require.NoError(t, s.TeardownInstance(index))
+	_, err = s.Invoke(index, "add", encodeArgs(t, I32(2), I32(3)), 0)
+	require.ErrorIs(t, err, ErrInvalidInstanceIndex)
+}
+
+func TestSandbox_Imports(t *testing.T) {
+	supervisor := newTestSupervisor()
+	supervisor.functions[7] = func(args []scale.VaryingDataTypeValue) (scale.VaryingDataTypeValue, error) {
+		if args[0] == I32(0) {
+			return nil, errors.New("zero")
+		}
+		return args[0].(I32) * 2, nil
+	}
+	s := New(supervisor)
+
+	memory, err := s.NewMemory(1, math.MaxUint32)
+	require.NoError(t, err)
+
+	env := environmentDefinition(t,
+		environmentEntry{ModuleName: []byte("env"), FieldName: []byte("double"), Kind: externFunction, Index: 7},
+		environmentEntry{ModuleName: []byte("env"), FieldName: []byte("memory"), Kind: externMemory, Index: memory},
+	)
+
+	index, err := s.Instantiate(0, importsModule, env, 42)
+	require.NoError(t, err)
+
+	// the data segment is written to the memory of the supervisor
+	err = s.MemoryGet(memory, 8, 300, 5)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), supervisor.memory[300:305])
+
+	ret, err := s.Invoke(index, "call", encodeArgs(t, I32(21)), 43)
+	require.NoError(t, err)
+	expected, err := EncodeReturnValue(I32(42))
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+	require.Equal(t, []uint32{43}, supervisor.states)
+
+	// an error of the supervisor function traps the instance
+	_, err = s.Invoke(index, "call", encodeArgs(t, I32(0)), 43)
+	require.ErrorIs(t, err, ErrExecution)
+}
+
+func TestSandbox_Instantiate_errors(t *testing.T) {
+	s := New(newTestSupervisor())
+
+	_, err := s.Instantiate(0, []byte{1, 2, 3}, environmentDefinition(t), 0)
+	require.ErrorIs(t, err, ErrModule)
+
+	// the imports are missing from the environment
+	_, err = s.Instantiate(0, importsModule, environmentDefinition(t), 0)
+	require.ErrorIs(t, err, ErrModule)
+
+	// the memory does not exist
+	env := environmentDefinition(t,
+		environmentEntry{ModuleName: []byte("env"), FieldName: []byte("double"), Kind: externFunction},
+		environmentEntry{ModuleName: []byte("env"), FieldName: []byte("memory"), Kind: externMemory, Index: 1},
+	)
+	_, err = s.Instantiate(0, importsModule, env, 0)
+	require.ErrorIs(t, err, ErrModule)
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/pkg/scale"
+	"github.com/go-interpreter/wagon/wasm"
+)
+
+var errInvalidReturnValue = errors.New("invalid return value")
+
+// I32 is a 32 bit integer value
+type I32 int32
+
+// Index returns the VDT index
+func (I32) Index() uint { return 0 }
+
+// I64 is a 64 bit integer value
+type I64 int64
+
+// Index returns the VDT index
+func (I64) Index() uint { return 1 }
+
+// F32 is a 32 bit float value, represented by its bits
+type F32 uint32
+
+// Index returns the VDT index
+func (F32) Index() uint { return 2 }
+
+// F64 is a 64 bit float value, represented by its bits
+type F64 uint64
+
+// Index returns the VDT index
+func (F64) Index() uint { return 3 }
+
+// NewValue returns a new Value VaryingDataType
+func NewValue() scale.VaryingDataType {
+	return scale.MustNewVaryingDataType(I32(0), I64(0), F32(0), F64(0))
+}
+
+// newValue returns the value of the given wasm type, from its representation in the interpreter
+func newValue(valueType wasm.ValueType, raw int64) (scale.VaryingDataTypeValue, error) {
+	switch valueType {
+	case wasm.ValueTypeI32:
+		return I32(raw), nil
+	case wasm.ValueTypeI64:
+		return I64(raw), nil
+	case wasm.ValueTypeF32:
+		return F32(raw), nil
+	case wasm.ValueTypeF64:
+		return F64(raw), nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %s", valueType)
+	}
+}
+
+// rawValue returns the representation in the interpreter of the value, if it is of the given wasm type
+func rawValue(valueType wasm.ValueType, value scale.VaryingDataTypeValue) (int64, error) {
+	switch v := value.(type) {
+	case I32:
+		if valueType == wasm.ValueTypeI32 {
+			return int64(uint32(v)), nil
+		}
+	case I64:
+		if valueType == wasm.ValueTypeI64 {
+			return int64(v), nil
+		}
+	case F32:
+		if valueType == wasm.ValueTypeF32 {
+			return int64(v), nil
+		}
+	case F64:
+		if valueType == wasm.ValueTypeF64 {
+			return int64(v), nil
+		}
+	}
+
+	return 0, fmt.Errorf("value %v is not of type %s", value, valueType)
+}
+
+// encodeValues encodes the values as a Vec<Value>
+func encodeValues(values []scale.VaryingDataTypeValue) ([]byte, error) {
+	vdts := scale.NewVaryingDataTypeSlice(NewValue())
+	err := vdts.Add(values...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scale.Marshal(vdts)
+}
+
+// decodeValues decodes a Vec<Value>
+func decodeValues(enc []byte) ([]scale.VaryingDataTypeValue, error) {
+	vdts := scale.NewVaryingDataTypeSlice(NewValue())
+	err := scale.Unmarshal(enc, &vdts)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]scale.VaryingDataTypeValue, len(vdts.Types))
+	for i, vdt := range vdts.Types {
+		values[i] = vdt.Value()
+	}
+
+	return values, nil
+}
+
+// EncodeReturnValue encodes the ReturnValue of an invocation, which is either Unit if value is nil,
+// or Value(value)
+func EncodeReturnValue(value scale.VaryingDataTypeValue) ([]byte, error) {
+	if value == nil {
+		return []byte{0}, nil
+	}
+
+	vdt := NewValue()
+	err := vdt.Set(value)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := scale.Marshal(vdt)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{1}, enc...), nil
+}
+
+// decodeReturnValue decodes a ReturnValue, returning nil if it is Unit
+func decodeReturnValue(enc []byte) (scale.VaryingDataTypeValue, error) {
+	if len(enc) == 0 {
+		return nil, errInvalidReturnValue
+	}
+
+	switch enc[0] {
+	case 0:
+		if len(enc) != 1 {
+			return nil, errInvalidReturnValue
+		}
+		return nil, nil
+	case 1:
+		vdt := NewValue()
+		err := scale.Unmarshal(enc[1:], &vdt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidReturnValue, err)
+		}
+		return vdt.Value(), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown variant %d", errInvalidReturnValue, enc[0])
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ChainSafe/gossamer/lib/trie"
+)
+
+// KeyChanges are the storage keys modified by the runtime, in lexicographic order
+type KeyChanges struct {
+	// Keys are the modified keys of the main trie. The key of a modified child trie is included.
+	Keys [][]byte
+	// ChildTries are the child storage keys of the modified child tries
+	ChildTries [][]byte
+}
+
+// Has returns whether the key of the main trie is modified
+func (c *KeyChanges) Has(key []byte) bool {
+	return containsKey(c.Keys, key)
+}
+
+// HasChildTrie returns whether the child trie with the given child storage key is modified
+func (c *KeyChanges) HasChildTrie(keyToChild []byte) bool {
+	return containsKey(c.ChildTries, keyToChild)
+}
+
+func containsKey(sorted [][]byte, key []byte) bool {
+	i := sort.Search(len(sorted), func(i int) bool {
+		return bytes.Compare(sorted[i], key) >= 0
+	})
+	return i < len(sorted) && bytes.Equal(sorted[i], key)
+}
+
+// keyChange is the modification of a key of the main trie, or of the child trie at the key if child is true
+type keyChange struct {
+	key   []byte
+	child bool
+}
+
+// RecordKeyChanges starts recording the storage keys modified in the TrieState, which are returned by KeyChanges
+func (s *TrieState) RecordKeyChanges() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.recordChanges = true
+}
+
+// recordChange records the modification of the key of the main trie, if the changes are recorded.
+// The lock must be held.
+func (s *TrieState) recordChange(key []byte) {
+	if !s.recordChanges {
+		return
+	}
+	s.changes = append(s.changes, keyChange{key: append([]byte{}, key...)})
+}
+
+// recordChildChange records the modification of the child trie at the given child storage key,
+// which modifies its key in the main trie, if the changes are recorded. The lock must be held.
+func (s *TrieState) recordChildChange(keyToChild []byte) {
+	if !s.recordChanges {
+		return
+	}
+	s.changes = append(s.changes, keyChange{key: append([]byte{}, keyToChild...), child: true})
+	s.recordChange(append(append([]byte{}, trie.ChildStorageKeyPrefix...), keyToChild...))
+}
+
+// KeyChanges returns the storage keys modified by the runtime, or nil if they are not recorded
+func (s *TrieState) KeyChanges() *KeyChanges {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if !s.recordChanges {
+		return nil
+	}
+
+	keys := make(map[string]struct{})
+	childTries := make(map[string]struct{})
+	for _, change := range s.changes {
+		if change.child {
+			childTries[string(change.key)] = struct{}{}
+		} else {
+			keys[string(change.key)] = struct{}{}
+		}
+	}
+
+	return &KeyChanges{
+		Keys:       sortedKeys(keys),
+		ChildTries: sortedKeys(childTries),
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrieState_KeyChanges(t *testing.T) {
+	tr := trie.NewEmptyTrie()
+	tr.Put([]byte("prefix1"), []byte{1})
+	tr.Put([]byte("prefix2"), []byte{2})
+	tr.Put([]byte("other"), []byte{3})
+
+	ts, err := NewTrieState(tr)
+	require.NoError(t, err)
+	ts.RecordKeyChanges()
+
+	ts.Set([]byte("b"), []byte{1})
+	ts.Set([]byte("a"), []byte{1})
+	ts.Set([]byte("b"), []byte{2})
+	ts.Delete([]byte("missing"))
+
+	ts.BeginStorageTransaction()
+	ts.Set([]byte("rolledback"), []byte{1})
+	ts.RollbackStorageTransaction()
+
+	ts.BeginStorageTransaction()
+	err = ts.ClearPrefix([]byte("prefix"))
+	require.NoError(t, err)
+	ts.CommitStorageTransaction()
+
+	err = ts.SetChild([]byte("child"), trie.NewEmptyTrie())
+	require.NoError(t, err)
+	err = ts.SetChildStorage([]byte("child"), []byte("key"), []byte{1})
+	require.NoError(t, err)
+
+	changes := ts.KeyChanges()
+	expected := &KeyChanges{
+		Keys: [][]byte{
+			[]byte(":child_storage:default:child"),
+			[]byte("a"),
+			[]byte("b"),
+			[]byte("prefix1"),
+			[]byte("prefix2"),
+		},
+		ChildTries: [][]byte{[]byte("child")},
+	}
+	require.Equal(t, expected, changes)
+
+	require.True(t, changes.Has([]byte("b")))
+	require.False(t, changes.Has([]byte("other")))
+	require.True(t, changes.HasChildTrie([]byte("child")))
+	require.False(t, changes.HasChildTrie([]byte("a")))
+}
+
+func TestTrieState_KeyChanges_ClearPrefixLimit(t *testing.T) {
+	tr := trie.NewEmptyTrie()
+	tr.Put([]byte("prefix1"), []byte{1})
+	tr.Put([]byte("prefix2"), []byte{2})
+	tr.Put([]byte("prefix3"), []byte{3})
+
+	ts, err := NewTrieState(tr)
+	require.NoError(t, err)
+	ts.RecordKeyChanges()
+
+	deleted, all := ts.ClearPrefixLimit([]byte("prefix"), 2)
+	require.Equal(t, uint32(2), deleted)
+	require.False(t, all)
+
+	changes := ts.KeyChanges()
+	require.Len(t, changes.Keys, 2)
+	for _, key := range changes.Keys {
+		require.Nil(t, ts.Get(key))
+	}
+}
+
+func TestTrieState_KeyChanges_notRecorded(t *testing.T) {
+	tr := trie.NewEmptyTrie()
+	tr.Put([]byte("prefix1"), []byte{1})
+	tr.Put([]byte("prefix2"), []byte{2})
+
+	ts, err := NewTrieState(tr)
+	require.NoError(t, err)
+
+	ts.Set([]byte("a"), []byte{1})
+	deleted, all := ts.ClearPrefixLimit([]byte("prefix"), 1)
+	require.Equal(t, uint32(1), deleted)
+	require.False(t, all)
+	err = ts.ClearPrefix([]byte("prefix"))
+	require.NoError(t, err)
+
+	require.Nil(t, ts.KeyChanges())
+	require.Empty(t, ts.changes)
+}
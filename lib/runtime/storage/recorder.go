@@ -0,0 +1,136 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/trie"
+)
+
+// RecordingTrieState is a TrieState which records every key read during the course of
+// a runtime call, so that a proof of the storage accessed by the call can be generated.
+type RecordingTrieState struct {
+	*TrieState
+
+	mu        sync.Mutex
+	keys      map[string]struct{}
+	childKeys map[string]map[string]struct{}
+}
+
+// NewRecordingTrieState returns a new RecordingTrieState wrapping the given TrieState.
+// The runtime code and heap pages keys are always recorded, since they are needed to
+// instantiate the runtime which executes the call.
+func NewRecordingTrieState(ts *TrieState) *RecordingTrieState {
+	s := &RecordingTrieState{
+		TrieState: ts,
+		keys:      make(map[string]struct{}),
+		childKeys: make(map[string]map[string]struct{}),
+	}
+
+	s.record(common.CodeKey)
+	s.record(common.HeapPagesKey)
+	return s
+}
+
+func (s *RecordingTrieState) record(key []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[string(key)] = struct{}{}
+}
+
+func (s *RecordingTrieState) recordChild(keyToChild, key []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, ok := s.childKeys[string(keyToChild)]
+	if !ok {
+		keys = make(map[string]struct{})
+		s.childKeys[string(keyToChild)] = keys
+	}
+
+	if key != nil {
+		keys[string(key)] = struct{}{}
+	}
+}
+
+// Get gets a value from the trie and records the key
+func (s *RecordingTrieState) Get(key []byte) []byte {
+	s.record(key)
+	return s.TrieState.Get(key)
+}
+
+// NextKey returns the next key in the trie in lexicographical order and records
+// both the given key and the returned key.
+func (s *RecordingTrieState) NextKey(key []byte) []byte {
+	next := s.TrieState.NextKey(key)
+	s.record(key)
+	if next != nil {
+		s.record(next)
+	}
+	return next
+}
+
+// GetChild returns the child trie at the given key and records the key
+func (s *RecordingTrieState) GetChild(keyToChild []byte) (*trie.Trie, error) {
+	s.recordChild(keyToChild, nil)
+	return s.TrieState.GetChild(keyToChild)
+}
+
+// GetChildStorage returns a value from a child trie and records the key
+func (s *RecordingTrieState) GetChildStorage(keyToChild, key []byte) ([]byte, error) {
+	s.recordChild(keyToChild, key)
+	return s.TrieState.GetChildStorage(keyToChild, key)
+}
+
+// GetChildNextKey returns the next lexicographical larger key from child storage and records
+// both the given key and the returned key.
+func (s *RecordingTrieState) GetChildNextKey(keyToChild, key []byte) ([]byte, error) {
+	next, err := s.TrieState.GetChildNextKey(keyToChild, key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordChild(keyToChild, key)
+	if next != nil {
+		s.recordChild(keyToChild, next)
+	}
+	return next, nil
+}
+
+// RecordedKeys returns the sorted list of keys read from the main trie
+func (s *RecordingTrieState) RecordedKeys() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedKeys(s.keys)
+}
+
+// RecordedChildKeys returns the sorted list of keys read from each child trie,
+// keyed by the child trie key.
+func (s *RecordingTrieState) RecordedChildKeys() map[string][][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	childKeys := make(map[string][][]byte, len(s.childKeys))
+	for keyToChild, keys := range s.childKeys {
+		childKeys[keyToChild] = sortedKeys(keys)
+	}
+	return childKeys
+}
+
+func sortedKeys(set map[string]struct{}) [][]byte {
+	strKeys := make([]string, 0, len(set))
+	for k := range set {
+		strKeys = append(strKeys, k)
+	}
+	sort.Strings(strKeys)
+
+	keys := make([][]byte, len(strKeys))
+	for i, k := range strKeys {
+		keys[i] = []byte(k)
+	}
+	return keys
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordingTrieState(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestTrieState(t)
+	ts.Set([]byte("a"), []byte{1})
+	ts.Set([]byte("c"), []byte{2})
+	err := ts.SetChild([]byte("child"), trie.NewEmptyTrie())
+	require.NoError(t, err)
+	err = ts.SetChildStorage([]byte("child"), []byte("x"), []byte{3})
+	require.NoError(t, err)
+
+	rs := NewRecordingTrieState(ts)
+	require.Equal(t, []byte{1}, rs.Get([]byte("a")))
+	require.Equal(t, []byte("c"), rs.NextKey([]byte("b")))
+
+	// writes must not be recorded
+	rs.Set([]byte("d"), []byte{4})
+
+	value, err := rs.GetChildStorage([]byte("child"), []byte("x"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{3}, value)
+
+	expectedKeys := [][]byte{[]byte(":code"), []byte(":heappages"), []byte("a"), []byte("b"), []byte("c")}
+	require.Equal(t, expectedKeys, rs.RecordedKeys())
+
+	expectedChildKeys := map[string][][]byte{
+		"child": {[]byte("x")},
+	}
+	require.Equal(t, expectedChildKeys, rs.RecordedChildKeys())
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// IndexOperation is a transaction index operation recorded by the runtime while executing a block
+type IndexOperation struct {
+	// Extrinsic is the index of the extrinsic in the block
+	Extrinsic uint32
+	// Hash is the hash of the indexed data
+	Hash common.Hash
+	// Size is the size of the indexed data, which is at the end of the extrinsic.
+	// It is zero for a renewal.
+	Size uint32
+	// Renew is true if the operation renews data indexed by a previous block
+	Renew bool
+}
+
+// IndexTransaction records that the last size bytes of the extrinsic are indexed under the given hash
+func (s *TrieState) IndexTransaction(extrinsic, size uint32, hash common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.indexOps = append(s.indexOps, IndexOperation{
+		Extrinsic: extrinsic,
+		Hash:      hash,
+		Size:      size,
+	})
+}
+
+// RenewTransactionIndex records that the data indexed under the given hash is renewed by the extrinsic
+func (s *TrieState) RenewTransactionIndex(extrinsic uint32, hash common.Hash) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.indexOps = append(s.indexOps, IndexOperation{
+		Extrinsic: extrinsic,
+		Hash:      hash,
+		Renew:     true,
+	})
+}
+
+// IndexOperations returns the transaction index operations recorded by the runtime
+func (s *TrieState) IndexOperations() []IndexOperation {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	ops := make([]IndexOperation, len(s.indexOps))
+	copy(ops, s.indexOps)
+	return ops
+}
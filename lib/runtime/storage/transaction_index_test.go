@@ -0,0 +1,30 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrieState_IndexTransaction(t *testing.T) {
+	ts := newTestTrieState(t)
+	ts.IndexTransaction(0, 2, common.Hash{1})
+
+	ts.BeginStorageTransaction()
+	ts.RenewTransactionIndex(1, common.Hash{1})
+	ts.RollbackStorageTransaction()
+
+	ts.BeginStorageTransaction()
+	ts.RenewTransactionIndex(2, common.Hash{2})
+	ts.CommitStorageTransaction()
+
+	expected := []IndexOperation{
+		{Extrinsic: 0, Hash: common.Hash{1}, Size: 2},
+		{Extrinsic: 2, Hash: common.Hash{2}, Renew: true},
+	}
+	require.Equal(t, expected, ts.IndexOperations())
+}
@@ -17,7 +17,15 @@
 type TrieState struct {
 	t       *trie.Trie
 	oldTrie *trie.Trie // this is the trie before BeginStorageTransaction is called. set to nil if it isn't called
-	lock    sync.RWMutex
+	// indexOps are the transaction index operations recorded by the runtime
+	indexOps    []IndexOperation
+	oldIndexOps []IndexOperation // this is indexOps before BeginStorageTransaction is called
+	// recordChanges determines whether the modifications of the storage keys are recorded
+	recordChanges bool
+	// changes are the modifications of the storage keys
+	changes    []keyChange
+	oldChanges []keyChange // this is changes before BeginStorageTransaction is called
+	lock       sync.RWMutex
 }
 
 // NewTrieState returns a new TrieState with the given trie
@@ -52,6 +60,8 @@ func (s *TrieState) BeginStorageTransaction() {
 	defer s.lock.Unlock()
 	s.oldTrie = s.t
 	s.t = s.t.Snapshot()
+	s.oldIndexOps = s.indexOps[:len(s.indexOps):len(s.indexOps)]
+	s.oldChanges = s.changes[:len(s.changes):len(s.changes)]
 }
 
 // CommitStorageTransaction commits all storage changes made since BeginStorageTransaction was called.
@@ -59,6 +69,8 @@ func (s *TrieState) CommitStorageTransaction() {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.oldTrie = nil
+	s.oldIndexOps = nil
+	s.oldChanges = nil
 }
 
 // RollbackStorageTransaction rolls back all storage changes made since BeginStorageTransaction was called.
@@ -67,6 +79,10 @@ func (s *TrieState) RollbackStorageTransaction() {
 	defer s.lock.Unlock()
 	s.t = s.oldTrie
 	s.oldTrie = nil
+	s.indexOps = s.oldIndexOps
+	s.oldIndexOps = nil
+	s.changes = s.oldChanges
+	s.oldChanges = nil
 }
 
 // Set sets a key-value pair in the trie
@@ -74,6 +90,7 @@ func (s *TrieState) Set(key, value []byte) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.t.Put(key, value)
+	s.recordChange(key)
 }
 
 // Get gets a value from the trie
@@ -108,6 +125,7 @@ func (s *TrieState) Delete(key []byte) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.t.Delete(key)
+	s.recordChange(key)
 }
 
 // NextKey returns the next key in the trie in lexicographical order. If it does not exist, it returns nil.
@@ -121,6 +139,11 @@ func (s *TrieState) NextKey(key []byte) []byte {
 func (s *TrieState) ClearPrefix(prefix []byte) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
+	if s.recordChanges {
+		for _, key := range s.t.GetKeysWithPrefix(prefix) {
+			s.recordChange(key)
+		}
+	}
 	s.t.ClearPrefix(prefix)
 	return nil
 }
@@ -130,8 +153,15 @@ func (s *TrieState) ClearPrefixLimit(prefix []byte, limit uint32) (uint32, bool)
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	num, del := s.t.ClearPrefixLimit(prefix, limit)
-	return num, del
+	if !s.recordChanges {
+		return s.t.ClearPrefixLimit(prefix, limit)
+	}
+
+	keys, del := s.t.ClearPrefixLimitKeys(prefix, limit)
+	for _, key := range keys {
+		s.recordChange(key)
+	}
+	return uint32(len(keys)), del
 }
 
 // TrieEntries returns every key-value pair in the trie
@@ -145,14 +175,26 @@ func (s *TrieState) TrieEntries() map[string][]byte {
 func (s *TrieState) SetChild(keyToChild []byte, child *trie.Trie) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	return s.t.PutChild(keyToChild, child)
+	err := s.t.PutChild(keyToChild, child)
+	if err != nil {
+		return err
+	}
+
+	s.recordChildChange(keyToChild)
+	return nil
 }
 
 // SetChildStorage sets a key-value pair in a child trie
 func (s *TrieState) SetChildStorage(keyToChild, key, value []byte) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	return s.t.PutIntoChild(keyToChild, key, value)
+	err := s.t.PutIntoChild(keyToChild, key, value)
+	if err != nil {
+		return err
+	}
+
+	s.recordChildChange(keyToChild)
+	return nil
 }
 
 // GetChild returns the child trie at the given key
@@ -174,6 +216,7 @@ func (s *TrieState) DeleteChild(key []byte) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.t.DeleteChild(key)
+	s.recordChildChange(key)
 }
 
 // DeleteChildLimit deletes up to limit of database entries by lexicographic order, return number
@@ -185,6 +228,7 @@ func (s *TrieState) DeleteChildLimit(key []byte, limit *[]byte) (uint32, bool, e
 	if err != nil {
 		return 0, false, err
 	}
+	s.recordChildChange(key)
 	qtyEntries := uint32(len(tr.Entries()))
 	if limit == nil {
 		s.t.DeleteChild(key)
@@ -217,7 +261,13 @@ func (s *TrieState) DeleteChildLimit(key []byte, limit *[]byte) (uint32, bool, e
 func (s *TrieState) ClearChildStorage(keyToChild, key []byte) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
-	return s.t.ClearFromChild(keyToChild, key)
+	err := s.t.ClearFromChild(keyToChild, key)
+	if err != nil {
+		return err
+	}
+
+	s.recordChildChange(keyToChild)
+	return nil
 }
 
 // ClearPrefixInChild clears all the keys from the child trie that have the given prefix
@@ -233,6 +283,7 @@ func (s *TrieState) ClearPrefixInChild(keyToChild, prefix []byte) error {
 		return nil
 	}
 
+	s.recordChildChange(keyToChild)
 	child.ClearPrefix(prefix)
 	return nil
 }
@@ -0,0 +1,328 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/trie"
+)
+
+// StorageOperation is the kind of a traced storage access
+type StorageOperation string
+
+// The storage operations recorded by the Tracer
+const (
+	StorageGet                StorageOperation = "get"
+	StorageSet                StorageOperation = "set"
+	StorageDelete             StorageOperation = "delete"
+	StorageNextKey            StorageOperation = "next_key"
+	StorageClearPrefix        StorageOperation = "clear_prefix"
+	StorageGetChild           StorageOperation = "get_child"
+	StorageSetChild           StorageOperation = "set_child"
+	StorageDeleteChild        StorageOperation = "delete_child"
+	StorageGetChildStorage    StorageOperation = "get_child_storage"
+	StorageSetChildStorage    StorageOperation = "set_child_storage"
+	StorageClearChildStorage  StorageOperation = "clear_child_storage"
+	StorageClearPrefixInChild StorageOperation = "clear_prefix_in_child"
+	StorageGetChildNextKey    StorageOperation = "get_child_next_key"
+)
+
+// StorageAccess is a storage read or write made during a traced runtime call
+type StorageAccess struct {
+	Operation StorageOperation `json:"op"`
+	// ChildKey is the hex encoded key of the child trie, it is empty for the main trie
+	ChildKey string `json:"childKey,omitempty"`
+	// Key is the hex encoded key, or prefix, accessed
+	Key string `json:"key"`
+	// ValueSize is the size of the value read or written, or of the next key for the next key operations
+	ValueSize int `json:"valueSize"`
+	// OverlayHit is true if the key was read after being written during the traced call,
+	// so that the value comes from the changes of the call rather than from the trie it started from.
+	OverlayHit bool `json:"overlayHit,omitempty"`
+}
+
+// HostCallTrace is a call to a host function made during a traced runtime call
+type HostCallTrace struct {
+	Name string `json:"name"`
+	// Depth is the number of host calls the call is nested in, which happens with sandboxed instances
+	Depth int `json:"depth,omitempty"`
+	// Duration is the time spent in the host function, in nanoseconds
+	Duration time.Duration `json:"duration"`
+	// Storage is the storage accessed by the host function
+	Storage []*StorageAccess `json:"storage,omitempty"`
+}
+
+// HostCallProfile is the number of calls and total time spent in a host function
+type HostCallProfile struct {
+	Name  string `json:"name"`
+	Calls int    `json:"calls"`
+	// Duration is the total time spent in the host function, in nanoseconds
+	Duration time.Duration `json:"duration"`
+}
+
+// Trace is the trace of a runtime call
+type Trace struct {
+	// HostCalls are the host function calls, in the order they were made
+	HostCalls []*HostCallTrace `json:"hostCalls"`
+	// Storage is the storage accessed outside of host function calls
+	Storage []*StorageAccess `json:"storage,omitempty"`
+	// Profile is the time spent in each host function, sorted by decreasing duration
+	Profile []*HostCallProfile `json:"profile"`
+}
+
+// BlockTrace is the trace of the execution of a block
+type BlockTrace struct {
+	Hash common.Hash `json:"hash"`
+	// Duration is the time spent executing the block, in nanoseconds
+	Duration time.Duration `json:"duration"`
+	Trace
+}
+
+type writtenKey struct {
+	keyToChild string
+	key        string
+}
+
+// Tracer records the host function calls and storage accesses of runtime calls. Tracing is
+// enabled by setting the storage returned by Storage as the context storage of the instance.
+// A Tracer should only trace one runtime call at a time.
+type Tracer struct {
+	mu        sync.Mutex
+	hostCalls []*HostCallTrace
+	// stack holds the host calls in progress, the innermost one last
+	stack   []*HostCallTrace
+	storage []*StorageAccess
+	written map[writtenKey]struct{}
+}
+
+// NewTracer returns a new Tracer
+func NewTracer() *Tracer {
+	return &Tracer{
+		written: make(map[writtenKey]struct{}),
+	}
+}
+
+// Storage returns the storage recording its accesses in the tracer
+func (t *Tracer) Storage(s Storage) Storage {
+	return &tracingStorage{
+		Storage: s,
+		tracer:  t,
+	}
+}
+
+// TracerOf returns the tracer of the storage, or nil if the storage isn't traced
+func TracerOf(s Storage) *Tracer {
+	ts, ok := s.(*tracingStorage)
+	if !ok {
+		return nil
+	}
+	return ts.tracer
+}
+
+// HostCall records the start of a call to the host function, and returns the function
+// recording its end, which must be called once the host function returns.
+func (t *Tracer) HostCall(name string) (end func()) {
+	t.mu.Lock()
+	call := &HostCallTrace{
+		Name:  name,
+		Depth: len(t.stack),
+	}
+	t.hostCalls = append(t.hostCalls, call)
+	t.stack = append(t.stack, call)
+	t.mu.Unlock()
+
+	start := time.Now()
+	return func() {
+		duration := time.Since(start)
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		call.Duration = duration
+		for i := len(t.stack) - 1; i >= 0; i-- {
+			if t.stack[i] == call {
+				t.stack = t.stack[:i]
+				break
+			}
+		}
+	}
+}
+
+func (t *Tracer) record(op StorageOperation, keyToChild, key []byte, valueSize int, write bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	access := &StorageAccess{
+		Operation: op,
+		Key:       common.BytesToHex(key),
+		ValueSize: valueSize,
+	}
+	if keyToChild != nil {
+		access.ChildKey = common.BytesToHex(keyToChild)
+	}
+
+	wk := writtenKey{keyToChild: string(keyToChild), key: string(key)}
+	if write {
+		t.written[wk] = struct{}{}
+	} else {
+		_, access.OverlayHit = t.written[wk]
+	}
+
+	if len(t.stack) == 0 {
+		t.storage = append(t.storage, access)
+		return
+	}
+
+	call := t.stack[len(t.stack)-1]
+	call.Storage = append(call.Storage, access)
+}
+
+// Trace returns the trace of the runtime calls recorded so far
+func (t *Tracer) Trace() *Trace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	profiles := make(map[string]*HostCallProfile)
+	for _, call := range t.hostCalls {
+		profile, ok := profiles[call.Name]
+		if !ok {
+			profile = &HostCallProfile{Name: call.Name}
+			profiles[call.Name] = profile
+		}
+		profile.Calls++
+		profile.Duration += call.Duration
+	}
+
+	profile := make([]*HostCallProfile, 0, len(profiles))
+	for _, p := range profiles {
+		profile = append(profile, p)
+	}
+	sort.Slice(profile, func(i, j int) bool {
+		if profile[i].Duration != profile[j].Duration {
+			return profile[i].Duration > profile[j].Duration
+		}
+		return profile[i].Name < profile[j].Name
+	})
+
+	return &Trace{
+		HostCalls: append([]*HostCallTrace{}, t.hostCalls...),
+		Storage:   append([]*StorageAccess(nil), t.storage...),
+		Profile:   profile,
+	}
+}
+
+// TraceBlock executes the block with the instance on the given storage, which holds the state of
+// its parent, and returns the trace of the execution. The changes made by the block are rolled back.
+func TraceBlock(instance Instance, s Storage, block *types.Block) (*BlockTrace, error) {
+	tracer := NewTracer()
+
+	s.BeginStorageTransaction()
+	defer s.RollbackStorageTransaction()
+
+	instance.SetContextStorage(tracer.Storage(s))
+	defer instance.SetContextStorage(s)
+
+	start := time.Now()
+	_, err := instance.ExecuteBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	duration := time.Since(start)
+
+	return &BlockTrace{
+		Hash:     block.Header.Hash(),
+		Duration: duration,
+		Trace:    *tracer.Trace(),
+	}, nil
+}
+
+// tracingStorage is a Storage recording its accesses in a Tracer.
+// The overlay hits are only detected for the keys written one by one.
+type tracingStorage struct {
+	Storage
+	tracer *Tracer
+}
+
+func (s *tracingStorage) Set(key, value []byte) {
+	s.tracer.record(StorageSet, nil, key, len(value), true)
+	s.Storage.Set(key, value)
+}
+
+func (s *tracingStorage) Get(key []byte) []byte {
+	value := s.Storage.Get(key)
+	s.tracer.record(StorageGet, nil, key, len(value), false)
+	return value
+}
+
+func (s *tracingStorage) Delete(key []byte) {
+	s.tracer.record(StorageDelete, nil, key, 0, true)
+	s.Storage.Delete(key)
+}
+
+func (s *tracingStorage) NextKey(key []byte) []byte {
+	next := s.Storage.NextKey(key)
+	s.tracer.record(StorageNextKey, nil, key, len(next), false)
+	return next
+}
+
+func (s *tracingStorage) ClearPrefix(prefix []byte) error {
+	s.tracer.record(StorageClearPrefix, nil, prefix, 0, true)
+	return s.Storage.ClearPrefix(prefix)
+}
+
+func (s *tracingStorage) ClearPrefixLimit(prefix []byte, limit uint32) (uint32, bool) {
+	s.tracer.record(StorageClearPrefix, nil, prefix, 0, true)
+	return s.Storage.ClearPrefixLimit(prefix, limit)
+}
+
+func (s *tracingStorage) GetChild(keyToChild []byte) (*trie.Trie, error) {
+	s.tracer.record(StorageGetChild, keyToChild, nil, 0, false)
+	return s.Storage.GetChild(keyToChild)
+}
+
+func (s *tracingStorage) SetChild(keyToChild []byte, child *trie.Trie) error {
+	s.tracer.record(StorageSetChild, keyToChild, nil, 0, true)
+	return s.Storage.SetChild(keyToChild, child)
+}
+
+func (s *tracingStorage) DeleteChild(keyToChild []byte) {
+	s.tracer.record(StorageDeleteChild, keyToChild, nil, 0, true)
+	s.Storage.DeleteChild(keyToChild)
+}
+
+func (s *tracingStorage) DeleteChildLimit(keyToChild []byte, limit *[]byte) (uint32, bool, error) {
+	s.tracer.record(StorageDeleteChild, keyToChild, nil, 0, true)
+	return s.Storage.DeleteChildLimit(keyToChild, limit)
+}
+
+func (s *tracingStorage) GetChildStorage(keyToChild, key []byte) ([]byte, error) {
+	value, err := s.Storage.GetChildStorage(keyToChild, key)
+	s.tracer.record(StorageGetChildStorage, keyToChild, key, len(value), false)
+	return value, err
+}
+
+func (s *tracingStorage) SetChildStorage(keyToChild, key, value []byte) error {
+	s.tracer.record(StorageSetChildStorage, keyToChild, key, len(value), true)
+	return s.Storage.SetChildStorage(keyToChild, key, value)
+}
+
+func (s *tracingStorage) ClearChildStorage(keyToChild, key []byte) error {
+	s.tracer.record(StorageClearChildStorage, keyToChild, key, 0, true)
+	return s.Storage.ClearChildStorage(keyToChild, key)
+}
+
+func (s *tracingStorage) ClearPrefixInChild(keyToChild, prefix []byte) error {
+	s.tracer.record(StorageClearPrefixInChild, keyToChild, prefix, 0, true)
+	return s.Storage.ClearPrefixInChild(keyToChild, prefix)
+}
+
+func (s *tracingStorage) GetChildNextKey(keyToChild, key []byte) ([]byte, error) {
+	next, err := s.Storage.GetChildNextKey(keyToChild, key)
+	s.tracer.record(StorageGetChildNextKey, keyToChild, key, len(next), false)
+	return next, err
+}
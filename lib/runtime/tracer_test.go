@@ -0,0 +1,108 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
+	"github.com/ChainSafe/gossamer/lib/trie"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer(t *testing.T) {
+	ts, err := storage.NewTrieState(nil)
+	require.NoError(t, err)
+	ts.Set([]byte("a"), []byte{1, 2})
+
+	tracer := NewTracer()
+	s := tracer.Storage(ts)
+	require.Equal(t, tracer, TracerOf(s))
+	require.Nil(t, TracerOf(ts))
+
+	// an access outside of host calls
+	s.Get([]byte("a"))
+
+	end := tracer.HostCall("ext_storage_set_version_1")
+	s.Set([]byte("b"), []byte{1, 2, 3})
+	end()
+
+	end = tracer.HostCall("ext_storage_get_version_1")
+	require.Equal(t, []byte{1, 2, 3}, s.Get([]byte("b")))
+	s.Get([]byte("a"))
+	end()
+
+	end = tracer.HostCall("ext_sandbox_invoke_version_1")
+	endNested := tracer.HostCall("ext_storage_get_version_1")
+	s.Get([]byte("c"))
+	endNested()
+	end()
+
+	// the storage itself is updated
+	require.Equal(t, []byte{1, 2, 3}, ts.Get([]byte("b")))
+
+	trace := tracer.Trace()
+	require.Equal(t, []*StorageAccess{
+		{Operation: StorageGet, Key: "0x61", ValueSize: 2},
+	}, trace.Storage)
+
+	require.Len(t, trace.HostCalls, 4)
+	require.Equal(t, "ext_storage_set_version_1", trace.HostCalls[0].Name)
+	require.Equal(t, []*StorageAccess{
+		{Operation: StorageSet, Key: "0x62", ValueSize: 3},
+	}, trace.HostCalls[0].Storage)
+
+	require.Equal(t, []*StorageAccess{
+		{Operation: StorageGet, Key: "0x62", ValueSize: 3, OverlayHit: true},
+		{Operation: StorageGet, Key: "0x61", ValueSize: 2},
+	}, trace.HostCalls[1].Storage)
+
+	require.Equal(t, "ext_sandbox_invoke_version_1", trace.HostCalls[2].Name)
+	require.Equal(t, 0, trace.HostCalls[2].Depth)
+	require.Empty(t, trace.HostCalls[2].Storage)
+	require.Equal(t, 1, trace.HostCalls[3].Depth)
+	require.Equal(t, []*StorageAccess{
+		{Operation: StorageGet, Key: "0x63"},
+	}, trace.HostCalls[3].Storage)
+
+	calls := make(map[string]int)
+	for i, profile := range trace.Profile {
+		calls[profile.Name] = profile.Calls
+		if i > 0 {
+			require.GreaterOrEqual(t, trace.Profile[i-1].Duration, profile.Duration)
+		}
+	}
+	require.Equal(t, map[string]int{
+		"ext_storage_set_version_1":    1,
+		"ext_storage_get_version_1":    2,
+		"ext_sandbox_invoke_version_1": 1,
+	}, calls)
+}
+
+func TestTracer_childStorage(t *testing.T) {
+	ts, err := storage.NewTrieState(nil)
+	require.NoError(t, err)
+	err = ts.SetChild([]byte("child"), trie.NewEmptyTrie())
+	require.NoError(t, err)
+
+	tracer := NewTracer()
+	s := tracer.Storage(ts)
+
+	err = s.SetChildStorage([]byte("child"), []byte("a"), []byte{1})
+	require.NoError(t, err)
+
+	value, err := s.GetChildStorage([]byte("child"), []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, value)
+
+	// the same key of the main trie isn't in the overlay
+	s.Get([]byte("a"))
+
+	require.Equal(t, []*StorageAccess{
+		{Operation: StorageSetChildStorage, ChildKey: "0x6368696c64", Key: "0x61", ValueSize: 1},
+		{Operation: StorageGetChildStorage, ChildKey: "0x6368696c64", Key: "0x61", ValueSize: 1, OverlayHit: true},
+		{Operation: StorageGet, Key: "0x61"},
+	}, tracer.Trace().Storage)
+}
@@ -0,0 +1,282 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"sync"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+)
+
+var errPoolStopped = errors.New("instance pool is stopped")
+
+// Executor compiles the runtime code and creates the instances running it. The compiled modules
+// are cached by code hash in memory, and on disk if a cache directory is set. The instances it
+// creates run their calls on a bounded pool of instances, so they can be called concurrently.
+type Executor struct {
+	cacheDir string
+	poolSize int
+
+	sync.Mutex
+	modules map[common.Hash]wasm.Module
+}
+
+// NewExecutor returns a new Executor caching the compiled modules in the given directory, or only
+// in memory if it is empty. The pools hold up to poolSize instances, or one per CPU if it isn't positive.
+func NewExecutor(cacheDir string, poolSize int) *Executor {
+	if poolSize <= 0 {
+		poolSize = goruntime.NumCPU()
+	}
+
+	return &Executor{
+		cacheDir: cacheDir,
+		poolSize: poolSize,
+		modules:  make(map[common.Hash]wasm.Module),
+	}
+}
+
+// newInstance returns an instance running its calls on a new pool of instances of the code
+func (e *Executor) newInstance(code []byte, cfg *Config) (*Instance, error) {
+	p, err := e.newPool(code, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	inst := &Instance{
+		ctx: &runtime.Context{
+			Storage:     cfg.Storage,
+			Keystore:    cfg.Keystore,
+			Validator:   cfg.Role == byte(4),
+			NodeStorage: cfg.NodeStorage,
+			Network:     cfg.Network,
+			Transaction: cfg.Transaction,
+		},
+		imports:  cfg.Imports,
+		codeHash: cfg.CodeHash,
+		pool:     p,
+	}
+
+	// an instance is created upfront, so that the code failing to instantiate is reported here
+	in, err := p.get(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+	p.put(in)
+
+	inst.version, _ = inst.Version()
+	return inst, nil
+}
+
+func (e *Executor) newPool(code []byte, cfg *Config) (*pool, error) {
+	info, err := runtime.ParseModuleInfo(code)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse WASM module: %w", err)
+	}
+
+	module, err := e.module(code)
+	if err != nil {
+		return nil, err
+	}
+
+	// the instances of the pool are created with the storage of the calls they run
+	poolCfg := *cfg
+	poolCfg.Executor = nil
+	poolCfg.Storage = nil
+	return &pool{
+		executor: e,
+		module:   module,
+		info:     info,
+		cfg:      poolCfg,
+		slots:    make(chan struct{}, e.poolSize),
+		idle:     make(chan *Instance, e.poolSize),
+	}, nil
+}
+
+// module returns the compiled module of the code, which is compiled if it isn't cached
+func (e *Executor) module(code []byte) (wasm.Module, error) {
+	hash, err := common.Blake2bHash(code)
+	if err != nil {
+		return wasm.Module{}, err
+	}
+
+	// the lock is held while compiling, so that the same code isn't compiled several times
+	e.Lock()
+	defer e.Unlock()
+
+	if module, ok := e.modules[hash]; ok {
+		return module, nil
+	}
+
+	module, err := e.loadModule(hash)
+	if err != nil {
+		logger.Debugf("cannot load compiled module for code hash %s from cache: %s", hash, err)
+
+		module, err = wasm.Compile(code)
+		if err != nil {
+			return wasm.Module{}, err
+		}
+
+		err = e.storeModule(hash, module)
+		if err != nil {
+			logger.Warnf("cannot store compiled module for code hash %s in cache: %s", hash, err)
+		}
+	}
+
+	e.modules[hash] = module
+	return module, nil
+}
+
+func (e *Executor) modulePath(hash common.Hash) string {
+	return filepath.Join(e.cacheDir, fmt.Sprintf("%s-%s.module", Name, hash))
+}
+
+func (e *Executor) loadModule(hash common.Hash) (wasm.Module, error) {
+	if e.cacheDir == "" {
+		return wasm.Module{}, errors.New("no cache directory")
+	}
+
+	serialized, err := os.ReadFile(e.modulePath(hash))
+	if err != nil {
+		return wasm.Module{}, err
+	}
+
+	return wasm.DeserializeModule(serialized)
+}
+
+func (e *Executor) storeModule(hash common.Hash, module wasm.Module) error {
+	if e.cacheDir == "" {
+		return nil
+	}
+
+	serialized, err := module.Serialize()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(e.cacheDir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(e.modulePath(hash), serialized, 0600)
+}
+
+// pool is a bounded pool of instances of a compiled module, which are created when needed
+type pool struct {
+	executor *Executor
+	module   wasm.Module
+	info     *runtime.ModuleInfo
+	cfg      Config
+	// slots holds a value for each instance in use, so that there are at most poolSize of them
+	slots chan struct{}
+	idle  chan *Instance
+
+	mutex   sync.Mutex
+	stopped bool
+}
+
+// get returns an idle instance of the pool created with the heap pages of the given storage, or a new one
+// if there are none, waiting for an instance to be put back if all of them are in use.
+func (p *pool) get(storage runtime.Storage) (*Instance, error) {
+	heapPages, err := runtime.HeapPages(storage)
+	if err != nil {
+		return nil, err
+	}
+
+	p.slots <- struct{}{}
+
+	p.mutex.Lock()
+	stopped := p.stopped
+	p.mutex.Unlock()
+	if stopped {
+		<-p.slots
+		return nil, errPoolStopped
+	}
+
+	select {
+	case in := <-p.idle:
+		if in.heapPages == heapPages {
+			return in, nil
+		}
+
+		// the memory of the instance is limited by the heap pages it was created with
+		in.Stop()
+	default:
+	}
+
+	cfg := p.cfg
+	cfg.Storage = storage
+	in, err := newInstance(p.module.InstantiateWithImports, p.info, &cfg)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+
+	in.heapPages = heapPages
+	return in, nil
+}
+
+// put puts the instance back into the pool, or stops it if the pool is stopped
+func (p *pool) put(in *Instance) {
+	in.SetContextStorage(nil)
+
+	p.mutex.Lock()
+	if p.stopped {
+		in.Stop()
+	} else {
+		// there are never more instances than the capacity of idle
+		p.idle <- in
+	}
+	p.mutex.Unlock()
+
+	<-p.slots
+}
+
+// exec calls the function with the given storage on an instance of the pool
+func (p *pool) exec(storage runtime.Storage, function string, data []byte) ([]byte, error) {
+	in, err := p.get(storage)
+	if err != nil {
+		return nil, err
+	}
+	defer p.put(in)
+
+	in.SetContextStorage(storage)
+	res, err := in.exec(function, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// the result is in the memory of the instance, which is reused once it is put back into the pool
+	out := make([]byte, len(res))
+	copy(out, res)
+	return out, nil
+}
+
+// stop stops the idle instances of the pool, and the instances in use once they are put back
+func (p *pool) stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.stopped {
+		return
+	}
+	p.stopped = true
+
+	for {
+		select {
+		case in := <-p.idle:
+			in.Stop()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"encoding/binary"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_NewInstance(t *testing.T) {
+	fp, cfg := setupConfig(t, runtime.NODE_RUNTIME, nil, DefaultTestLogLvl, 0)
+	code, err := os.ReadFile(fp)
+	require.NoError(t, err)
+
+	expected, err := NewInstance(code, cfg)
+	require.NoError(t, err)
+	expectedMetadata, err := expected.Metadata()
+	require.NoError(t, err)
+
+	cfg.Executor = NewExecutor(t.TempDir(), 2)
+	instance, err := NewInstance(code, cfg)
+	require.NoError(t, err)
+	require.Equal(t, expected.version, instance.version)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ts, err := storage.NewTrieState(nil)
+			require.NoError(t, err)
+
+			handle := instance.Handle()
+			handle.SetContextStorage(ts)
+			metadata, err := handle.Metadata()
+			require.NoError(t, err)
+			require.Equal(t, expectedMetadata, metadata)
+		}()
+	}
+	wg.Wait()
+
+	version, err := instance.CheckRuntimeVersion(code)
+	require.NoError(t, err)
+	require.Equal(t, expected.version, version)
+
+	instance.Stop()
+	_, err = instance.Metadata()
+	require.Error(t, err)
+}
+
+func TestExecutor_Handle_Stop(t *testing.T) {
+	fp, cfg := setupConfig(t, runtime.NODE_RUNTIME, nil, DefaultTestLogLvl, 0)
+	code, err := os.ReadFile(fp)
+	require.NoError(t, err)
+
+	cfg.Executor = NewExecutor("", 1)
+	instance, err := NewInstance(code, cfg)
+	require.NoError(t, err)
+
+	// stopping a handle leaves the pool running for the instance and its other handles
+	handle := instance.Handle()
+	handle.Stop()
+	_, err = handle.Metadata()
+	require.Error(t, err)
+
+	_, err = instance.Metadata()
+	require.NoError(t, err)
+	_, err = instance.Handle().Metadata()
+	require.NoError(t, err)
+
+	instance.Stop()
+	_, err = instance.Handle().Metadata()
+	require.ErrorIs(t, err, errPoolStopped)
+}
+
+func TestExecutor_heapPages(t *testing.T) {
+	fp, cfg := setupConfig(t, runtime.NODE_RUNTIME, nil, DefaultTestLogLvl, 0)
+	code, err := os.ReadFile(fp)
+	require.NoError(t, err)
+
+	cfg.Executor = NewExecutor("", 1)
+	instance, err := NewInstance(code, cfg)
+	require.NoError(t, err)
+
+	// the instances of the pool are created with the heap pages of the storage of each call
+	ts, err := storage.NewTrieState(nil)
+	require.NoError(t, err)
+	heapPages := make([]byte, 8)
+	binary.LittleEndian.PutUint64(heapPages, runtime.DefaultHeapPages*2)
+	ts.Set(common.HeapPagesKey, heapPages)
+
+	handle := instance.Handle()
+	handle.SetContextStorage(ts)
+	_, err = handle.Metadata()
+	require.NoError(t, err)
+
+	in := <-instance.pool.idle
+	require.Equal(t, uint64(runtime.DefaultHeapPages*2), in.heapPages)
+	instance.pool.idle <- in
+}
+
+func TestExecutor_module(t *testing.T) {
+	fp, _ := setupConfig(t, runtime.NODE_RUNTIME, nil, DefaultTestLogLvl, 0)
+	code, err := os.ReadFile(fp)
+	require.NoError(t, err)
+
+	cacheDir := t.TempDir()
+	executor := NewExecutor(cacheDir, 1)
+
+	module, err := executor.module(code)
+	require.NoError(t, err)
+
+	cached, err := executor.module(code)
+	require.NoError(t, err)
+	require.Equal(t, module, cached)
+
+	// the module compiled by the first executor is loaded from disk
+	hash, err := common.Blake2bHash(code)
+	require.NoError(t, err)
+	loaded, err := NewExecutor(cacheDir, 1).loadModule(hash)
+	require.NoError(t, err)
+	require.Equal(t, module.Exports, loaded.Exports)
+
+	// modules are only cached in memory without a cache directory
+	_, err = NewExecutor("", 1).loadModule(hash)
+	require.Error(t, err)
+}
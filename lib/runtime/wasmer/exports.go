@@ -176,6 +176,27 @@ func (in *Instance) DecodeSessionKeys(enc []byte) ([]byte, error) {
 	return in.exec(runtime.DecodeSessionKeys, enc)
 }
 
+// GenerateSessionKeys generates the session keys of the runtime from the given seed, or from a random
+// seed if it is nil, and stores them in the keystore. Returns the SCALE encoded public session keys.
+func (in *Instance) GenerateSessionKeys(seed *[]byte) ([]byte, error) {
+	encSeed, err := scale.Marshal(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := in.exec(runtime.GenerateSessionKeys, encSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []byte
+	if err = scale.Unmarshal(ret, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
 // PaymentQueryInfo returns information of a given extrinsic
 func (in *Instance) PaymentQueryInfo(ext []byte) (*types.TransactionPaymentQueryInfo, error) {
 	encLen, err := scale.Marshal(uint32(len(ext)))
@@ -196,7 +217,16 @@ func (in *Instance) PaymentQueryInfo(ext []byte) (*types.TransactionPaymentQuery
 	return i, nil
 }
 
-func (in *Instance) CheckInherents()      {} //nolint:revive
-func (in *Instance) RandomSeed()          {} //nolint:revive
-func (in *Instance) OffchainWorker()      {} //nolint:revive
-func (in *Instance) GenerateSessionKeys() {} //nolint:revive
+// OffchainWorker calls runtime API function OffchainWorkerApi_offchain_worker
+func (in *Instance) OffchainWorker(header *types.Header) error {
+	encodedHeader, err := scale.Marshal(*header)
+	if err != nil {
+		return fmt.Errorf("cannot encode header: %w", err)
+	}
+
+	_, err = in.exec(runtime.OffchainWorkerAPI, encodedHeader)
+	return err
+}
+
+func (in *Instance) CheckInherents() {} //nolint:revive
+func (in *Instance) RandomSeed()     {} //nolint:revive
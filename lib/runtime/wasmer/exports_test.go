@@ -971,6 +971,36 @@ func TestInstance_DecodeSessionKeys(t *testing.T) {
 	require.Len(t, *decodedKeys, 4)
 }
 
+func TestInstance_GenerateSessionKeys(t *testing.T) {
+	instance := NewTestInstance(t, runtime.NODE_RUNTIME_v098)
+
+	keys, err := instance.GenerateSessionKeys(nil)
+	require.NoError(t, err)
+
+	encKeys, err := scale.Marshal(keys)
+	require.NoError(t, err)
+
+	decoded, err := instance.DecodeSessionKeys(encKeys)
+	require.NoError(t, err)
+
+	var decodedKeys *[]struct {
+		Data []uint8
+		Type [4]uint8
+	}
+
+	err = scale.Unmarshal(decoded, &decodedKeys)
+	require.NoError(t, err)
+	require.Len(t, *decodedKeys, 4)
+
+	// each generated key is stored in the keystore of its key type
+	for _, key := range *decodedKeys {
+		ks, err := instance.ctx.Keystore.GetKeystore(key.Type[:])
+		require.NoError(t, err)
+		require.Equal(t, 1, ks.Size())
+		require.Equal(t, key.Data, ks.PublicKeys()[0].Encode())
+	}
+}
+
 func TestInstance_PaymentQueryInfo(t *testing.T) {
 	tests := []struct {
 		extB   []byte
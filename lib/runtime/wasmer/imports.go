@@ -78,6 +78,10 @@
 // extern void ext_offchain_sleep_until_version_1(void *context, int64_t a);
 // extern int64_t ext_offchain_http_request_start_version_1(void *context, int64_t a, int64_t b, int64_t c);
 // extern int64_t ext_offchain_http_request_add_header_version_1(void *context, int32_t a, int64_t k, int64_t v);
+// extern int64_t ext_offchain_http_request_write_body_version_1(void *context, int32_t a, int64_t b, int64_t c);
+// extern int64_t ext_offchain_http_response_wait_version_1(void *context, int64_t a, int64_t b);
+// extern int64_t ext_offchain_http_response_headers_version_1(void *context, int32_t a);
+// extern int64_t ext_offchain_http_response_read_body_version_1(void *context, int32_t a, int64_t b, int64_t c);
 //
 // extern void ext_storage_append_version_1(void *context, int64_t a, int64_t b);
 // extern int64_t ext_storage_changes_root_version_1(void *context, int64_t a);
@@ -116,6 +120,8 @@
 	"github.com/ChainSafe/gossamer/lib/crypto/secp256k1"
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
 	rtstorage "github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/transaction"
 	"github.com/ChainSafe/gossamer/lib/trie"
@@ -124,9 +130,23 @@
 	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
 )
 
+func noopTraceEnd() {}
+
+// traceHostCall records the call to the host function if the storage of the context is traced,
+// and returns the function to call once the host function returns.
+func traceHostCall(context unsafe.Pointer, name string) func() {
+	instanceContext := wasm.IntoInstanceContext(context)
+	tracer := runtime.TracerOf(instanceContext.Data().(*runtime.Context).Storage)
+	if tracer == nil {
+		return noopTraceEnd
+	}
+	return tracer.HostCall(name)
+}
+
 //export ext_logging_log_version_1
 func ext_logging_log_version_1(context unsafe.Pointer, level C.int32_t, targetData, msgData C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_logging_log_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	target := string(asMemorySlice(instanceContext, targetData))
@@ -151,71 +171,179 @@ func ext_logging_log_version_1(context unsafe.Pointer, level C.int32_t, targetDa
 //export ext_logging_max_level_version_1
 func ext_logging_max_level_version_1(context unsafe.Pointer) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_logging_max_level_version_1")()
 	return 4
 }
 
 //export ext_transaction_index_index_version_1
-func ext_transaction_index_index_version_1(context unsafe.Pointer, a, b, c C.int32_t) {
+func ext_transaction_index_index_version_1(context unsafe.Pointer, extrinsic, size, hashPtr C.int32_t) {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	defer traceHostCall(context, "ext_transaction_index_index_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	hash := common.BytesToHash(memory[hashPtr : hashPtr+32])
+	storage.IndexTransaction(uint32(extrinsic), uint32(size), hash)
 }
 
 //export ext_transaction_index_renew_version_1
-func ext_transaction_index_renew_version_1(context unsafe.Pointer, a, b C.int32_t) {
+func ext_transaction_index_renew_version_1(context unsafe.Pointer, extrinsic, hashPtr C.int32_t) {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	defer traceHostCall(context, "ext_transaction_index_renew_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	memory := instanceContext.Memory().Data()
+	storage := instanceContext.Data().(*runtime.Context).Storage
+
+	hash := common.BytesToHash(memory[hashPtr : hashPtr+32])
+	storage.RenewTransactionIndex(uint32(extrinsic), hash)
+}
+
+// sandboxResult converts a sandbox result code to the returned value
+func sandboxResult(code uint32) C.int32_t {
+	return C.int32_t(int32(code))
 }
 
 //export ext_sandbox_instance_teardown_version_1
-func ext_sandbox_instance_teardown_version_1(context unsafe.Pointer, a C.int32_t) {
+func ext_sandbox_instance_teardown_version_1(context unsafe.Pointer, instanceIdx C.int32_t) {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	defer traceHostCall(context, "ext_sandbox_instance_teardown_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	err := runtimeCtx.Sandbox.TeardownInstance(uint32(instanceIdx))
+	if err != nil {
+		logger.Errorf("failed to teardown sandboxed instance: %s", err)
+	}
 }
 
 //export ext_sandbox_instantiate_version_1
-func ext_sandbox_instantiate_version_1(context unsafe.Pointer, a C.int32_t, x, y C.int64_t, z C.int32_t) C.int32_t {
+func ext_sandbox_instantiate_version_1(context unsafe.Pointer, dispatchThunk C.int32_t,
+	wasmCodeSpan, envDefSpan C.int64_t, statePtr C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	defer traceHostCall(context, "ext_sandbox_instantiate_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	code := make([]byte, len(asMemorySlice(instanceContext, wasmCodeSpan)))
+	copy(code, asMemorySlice(instanceContext, wasmCodeSpan))
+	envDef := make([]byte, len(asMemorySlice(instanceContext, envDefSpan)))
+	copy(envDef, asMemorySlice(instanceContext, envDefSpan))
+
+	idx, err := runtimeCtx.Sandbox.Instantiate(uint32(dispatchThunk), code, envDef, uint32(statePtr))
+	if err != nil {
+		logger.Debugf("failed to instantiate sandboxed module: %s", err)
+		return sandboxResult(sandbox.ResultCode(err))
+	}
+
+	return C.int32_t(idx)
 }
 
 //export ext_sandbox_invoke_version_1
-func ext_sandbox_invoke_version_1(context unsafe.Pointer, a C.int32_t, x, y C.int64_t, z, d, e C.int32_t) C.int32_t {
+func ext_sandbox_invoke_version_1(context unsafe.Pointer, instanceIdx C.int32_t, exportNameSpan, argsSpan C.int64_t,
+	returnValPtr, returnValLen, statePtr C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	defer traceHostCall(context, "ext_sandbox_invoke_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	exportName := string(asMemorySlice(instanceContext, exportNameSpan))
+	args := make([]byte, len(asMemorySlice(instanceContext, argsSpan)))
+	copy(args, asMemorySlice(instanceContext, argsSpan))
+
+	ret, err := runtimeCtx.Sandbox.Invoke(uint32(instanceIdx), exportName, args, uint32(statePtr))
+	if err != nil {
+		logger.Debugf("failed to invoke function %s of sandboxed instance: %s", exportName, err)
+		return sandboxResult(sandbox.ResultCode(err))
+	}
+
+	if len(ret) > int(uint32(returnValLen)) {
+		logger.Debugf("return value of function %s of sandboxed instance does not fit in buffer", exportName)
+		return sandboxResult(sandbox.ResultExecution)
+	}
+
+	memory := instanceContext.Memory().Data()
+	if uint64(uint32(returnValPtr))+uint64(len(ret)) > uint64(len(memory)) {
+		logger.Debugf("return value buffer is out of bounds")
+		return sandboxResult(sandbox.ResultOutOfBounds)
+	}
+
+	copy(memory[uint32(returnValPtr):], ret)
+	return sandboxResult(sandbox.ResultOK)
 }
 
 //export ext_sandbox_memory_get_version_1
-func ext_sandbox_memory_get_version_1(context unsafe.Pointer, a, z, d, e C.int32_t) C.int32_t {
+func ext_sandbox_memory_get_version_1(context unsafe.Pointer, memoryIdx, offset, bufPtr, bufLen C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	defer traceHostCall(context, "ext_sandbox_memory_get_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	err := runtimeCtx.Sandbox.MemoryGet(uint32(memoryIdx), uint32(offset), uint32(bufPtr), uint32(bufLen))
+	if err != nil {
+		logger.Debugf("failed to get sandboxed memory: %s", err)
+	}
+
+	return sandboxResult(sandbox.ResultCode(err))
 }
 
 //export ext_sandbox_memory_new_version_1
-func ext_sandbox_memory_new_version_1(context unsafe.Pointer, a, z C.int32_t) C.int32_t {
+func ext_sandbox_memory_new_version_1(context unsafe.Pointer, initial, maximum C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	defer traceHostCall(context, "ext_sandbox_memory_new_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	idx, err := runtimeCtx.Sandbox.NewMemory(uint32(initial), uint32(maximum))
+	if err != nil {
+		logger.Errorf("failed to create sandboxed memory: %s", err)
+		return sandboxResult(sandbox.ResultCode(err))
+	}
+
+	return C.int32_t(idx)
 }
 
 //export ext_sandbox_memory_set_version_1
-func ext_sandbox_memory_set_version_1(context unsafe.Pointer, a, z, d, e C.int32_t) C.int32_t {
+func ext_sandbox_memory_set_version_1(context unsafe.Pointer, memoryIdx, offset, valPtr, valLen C.int32_t) C.int32_t {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
-	return 0
+	defer traceHostCall(context, "ext_sandbox_memory_set_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	err := runtimeCtx.Sandbox.MemorySet(uint32(memoryIdx), uint32(offset), uint32(valPtr), uint32(valLen))
+	if err != nil {
+		logger.Debugf("failed to set sandboxed memory: %s", err)
+	}
+
+	return sandboxResult(sandbox.ResultCode(err))
 }
 
 //export ext_sandbox_memory_teardown_version_1
-func ext_sandbox_memory_teardown_version_1(context unsafe.Pointer, a C.int32_t) {
+func ext_sandbox_memory_teardown_version_1(context unsafe.Pointer, memoryIdx C.int32_t) {
 	logger.Trace("executing...")
-	logger.Warn("unimplemented")
+	defer traceHostCall(context, "ext_sandbox_memory_teardown_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	err := runtimeCtx.Sandbox.TeardownMemory(uint32(memoryIdx))
+	if err != nil {
+		logger.Errorf("failed to teardown sandboxed memory: %s", err)
+	}
 }
 
 //export ext_crypto_ed25519_generate_version_1
 func ext_crypto_ed25519_generate_version_1(context unsafe.Pointer, keyTypeID C.int32_t, seedSpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_crypto_ed25519_generate_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -269,6 +397,7 @@ func ext_crypto_ed25519_generate_version_1(context unsafe.Pointer, keyTypeID C.i
 //export ext_crypto_ed25519_public_keys_version_1
 func ext_crypto_ed25519_public_keys_version_1(context unsafe.Pointer, keyTypeID C.int32_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_crypto_ed25519_public_keys_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -318,6 +447,7 @@ func ext_crypto_ed25519_public_keys_version_1(context unsafe.Pointer, keyTypeID
 //export ext_crypto_ed25519_sign_version_1
 func ext_crypto_ed25519_sign_version_1(context unsafe.Pointer, keyTypeID, key C.int32_t, msg C.int64_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_crypto_ed25519_sign_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -368,6 +498,7 @@ func ext_crypto_ed25519_sign_version_1(context unsafe.Pointer, keyTypeID, key C.
 //export ext_crypto_ed25519_verify_version_1
 func ext_crypto_ed25519_verify_version_1(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, key C.int32_t) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_crypto_ed25519_verify_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
@@ -406,6 +537,7 @@ func ext_crypto_ed25519_verify_version_1(context unsafe.Pointer, sig C.int32_t,
 //export ext_crypto_secp256k1_ecdsa_recover_version_1
 func ext_crypto_secp256k1_ecdsa_recover_version_1(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_crypto_secp256k1_ecdsa_recover_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
 
@@ -443,12 +575,14 @@ func ext_crypto_secp256k1_ecdsa_recover_version_1(context unsafe.Pointer, sig, m
 //export ext_crypto_secp256k1_ecdsa_recover_version_2
 func ext_crypto_secp256k1_ecdsa_recover_version_2(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_crypto_secp256k1_ecdsa_recover_version_2")()
 	return ext_crypto_secp256k1_ecdsa_recover_version_1(context, sig, msg)
 }
 
 //export ext_crypto_ecdsa_verify_version_2
 func ext_crypto_ecdsa_verify_version_2(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, key C.int32_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_crypto_ecdsa_verify_version_2")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
@@ -497,6 +631,7 @@ func ext_crypto_ecdsa_verify_version_2(context unsafe.Pointer, sig C.int32_t, ms
 //export ext_crypto_secp256k1_ecdsa_recover_compressed_version_1
 func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_crypto_secp256k1_ecdsa_recover_compressed_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
 
@@ -529,12 +664,14 @@ func ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(context unsafe.Poin
 //export ext_crypto_secp256k1_ecdsa_recover_compressed_version_2
 func ext_crypto_secp256k1_ecdsa_recover_compressed_version_2(context unsafe.Pointer, sig, msg C.int32_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_crypto_secp256k1_ecdsa_recover_compressed_version_2")()
 	return ext_crypto_secp256k1_ecdsa_recover_compressed_version_1(context, sig, msg)
 }
 
 //export ext_crypto_sr25519_generate_version_1
 func ext_crypto_sr25519_generate_version_1(context unsafe.Pointer, keyTypeID C.int32_t, seedSpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_crypto_sr25519_generate_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -587,6 +724,7 @@ func ext_crypto_sr25519_generate_version_1(context unsafe.Pointer, keyTypeID C.i
 //export ext_crypto_sr25519_public_keys_version_1
 func ext_crypto_sr25519_public_keys_version_1(context unsafe.Pointer, keyTypeID C.int32_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_crypto_sr25519_public_keys_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -636,6 +774,7 @@ func ext_crypto_sr25519_public_keys_version_1(context unsafe.Pointer, keyTypeID
 //export ext_crypto_sr25519_sign_version_1
 func ext_crypto_sr25519_sign_version_1(context unsafe.Pointer, keyTypeID, key C.int32_t, msg C.int64_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_crypto_sr25519_sign_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
 	memory := instanceContext.Memory().Data()
@@ -682,10 +821,10 @@ func ext_crypto_sr25519_sign_version_1(context unsafe.Pointer, keyTypeID, key C.
 //export ext_crypto_sr25519_verify_version_1
 func ext_crypto_sr25519_verify_version_1(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, key C.int32_t) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_crypto_sr25519_verify_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
-	sigVerifier := instanceContext.Data().(*runtime.Context).SigVerifier
 
 	message := asMemorySlice(instanceContext, msg)
 	signature := memory[sig : sig+64]
@@ -700,17 +839,7 @@ func ext_crypto_sr25519_verify_version_1(context unsafe.Pointer, sig C.int32_t,
 		"pub=%s message=0x%x signature=0x%x",
 		pub.Hex(), message, signature)
 
-	if sigVerifier.IsStarted() {
-		signature := crypto.SignatureInfo{
-			PubKey:     pub.Encode(),
-			Sign:       signature,
-			Msg:        message,
-			VerifyFunc: sr25519.VerifySignature,
-		}
-		sigVerifier.Add(&signature)
-		return 1
-	}
-
+	// the signature is not added to the verification batch, as the deprecated verification never fails
 	if ok, err := pub.VerifyDeprecated(message, signature); err != nil || !ok {
 		logger.Debugf("failed to validate signature: %s", err)
 		// this fails at block 3876, which seems to be expected, based on discussions
@@ -724,6 +853,7 @@ func ext_crypto_sr25519_verify_version_1(context unsafe.Pointer, sig C.int32_t,
 //export ext_crypto_sr25519_verify_version_2
 func ext_crypto_sr25519_verify_version_2(context unsafe.Pointer, sig C.int32_t, msg C.int64_t, key C.int32_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_crypto_sr25519_verify_version_2")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
@@ -765,23 +895,44 @@ func ext_crypto_sr25519_verify_version_2(context unsafe.Pointer, sig C.int32_t,
 //export ext_crypto_start_batch_verify_version_1
 func ext_crypto_start_batch_verify_version_1(context unsafe.Pointer) {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_crypto_start_batch_verify_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	sigVerifier := instanceContext.Data().(*runtime.Context).SigVerifier
+
+	if sigVerifier.IsStarted() {
+		logger.Error("signature verification batch is already started")
+		return
+	}
 
-	// TODO: fix and re-enable signature verification (#1405)
-	// beginBatchVerify(context)
+	sigVerifier.Start()
 }
 
 //export ext_crypto_finish_batch_verify_version_1
 func ext_crypto_finish_batch_verify_version_1(context unsafe.Pointer) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_crypto_finish_batch_verify_version_1")()
+
+	instanceContext := wasm.IntoInstanceContext(context)
+	sigVerifier := instanceContext.Data().(*runtime.Context).SigVerifier
+
+	if !sigVerifier.IsStarted() {
+		logger.Error("finish_batch_verify called without start_batch_verify")
+		return 0
+	}
+
+	if !sigVerifier.Finish() {
+		logger.Debug("failed to verify signatures of batch")
+		return 0
+	}
 
-	// TODO: fix and re-enable signature verification (#1405)
-	// return finishBatchVerify(context)
 	return 1
 }
 
 //export ext_trie_blake2_256_root_version_1
 func ext_trie_blake2_256_root_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_trie_blake2_256_root_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
@@ -826,6 +977,7 @@ type kv struct {
 //export ext_trie_blake2_256_ordered_root_version_1
 func ext_trie_blake2_256_ordered_root_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_trie_blake2_256_ordered_root_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	memory := instanceContext.Memory().Data()
@@ -874,6 +1026,7 @@ func ext_trie_blake2_256_ordered_root_version_1(context unsafe.Pointer, dataSpan
 //export ext_trie_blake2_256_verify_proof_version_1
 func ext_trie_blake2_256_verify_proof_version_1(context unsafe.Pointer, rootSpan C.int32_t, proofSpan, keySpan, valueSpan C.int64_t) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_trie_blake2_256_verify_proof_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 
@@ -908,6 +1061,7 @@ func ext_trie_blake2_256_verify_proof_version_1(context unsafe.Pointer, rootSpan
 //export ext_misc_print_hex_version_1
 func ext_misc_print_hex_version_1(context unsafe.Pointer, dataSpan C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_misc_print_hex_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -915,8 +1069,9 @@ func ext_misc_print_hex_version_1(context unsafe.Pointer, dataSpan C.int64_t) {
 }
 
 //export ext_misc_print_num_version_1
-func ext_misc_print_num_version_1(_ unsafe.Pointer, data C.int64_t) {
+func ext_misc_print_num_version_1(context unsafe.Pointer, data C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_misc_print_num_version_1")()
 
 	logger.Debugf("num: %d", int64(data))
 }
@@ -924,6 +1079,7 @@ func ext_misc_print_num_version_1(_ unsafe.Pointer, data C.int64_t) {
 //export ext_misc_print_utf8_version_1
 func ext_misc_print_utf8_version_1(context unsafe.Pointer, dataSpan C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_misc_print_utf8_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -933,6 +1089,7 @@ func ext_misc_print_utf8_version_1(context unsafe.Pointer, dataSpan C.int64_t) {
 //export ext_misc_runtime_version_version_1
 func ext_misc_runtime_version_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_misc_runtime_version_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -976,6 +1133,7 @@ func ext_misc_runtime_version_version_1(context unsafe.Pointer, dataSpan C.int64
 //export ext_default_child_storage_read_version_1
 func ext_default_child_storage_read_version_1(context unsafe.Pointer, childStorageKey, key, valueOut C.int64_t, offset C.int32_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_read_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -1006,6 +1164,7 @@ func ext_default_child_storage_read_version_1(context unsafe.Pointer, childStora
 //export ext_default_child_storage_clear_version_1
 func ext_default_child_storage_clear_version_1(context unsafe.Pointer, childStorageKey, keySpan C.int64_t) {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_clear_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1023,6 +1182,7 @@ func ext_default_child_storage_clear_version_1(context unsafe.Pointer, childStor
 //export ext_default_child_storage_clear_prefix_version_1
 func ext_default_child_storage_clear_prefix_version_1(context unsafe.Pointer, childStorageKey, prefixSpan C.int64_t) {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_clear_prefix_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1040,6 +1200,7 @@ func ext_default_child_storage_clear_prefix_version_1(context unsafe.Pointer, ch
 //export ext_default_child_storage_exists_version_1
 func ext_default_child_storage_exists_version_1(context unsafe.Pointer, childStorageKey, key C.int64_t) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_exists_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -1058,6 +1219,7 @@ func ext_default_child_storage_exists_version_1(context unsafe.Pointer, childSto
 //export ext_default_child_storage_get_version_1
 func ext_default_child_storage_get_version_1(context unsafe.Pointer, childStorageKey, key C.int64_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_get_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -1080,6 +1242,7 @@ func ext_default_child_storage_get_version_1(context unsafe.Pointer, childStorag
 //export ext_default_child_storage_next_key_version_1
 func ext_default_child_storage_next_key_version_1(context unsafe.Pointer, childStorageKey, key C.int64_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_next_key_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -1102,6 +1265,7 @@ func ext_default_child_storage_next_key_version_1(context unsafe.Pointer, childS
 //export ext_default_child_storage_root_version_1
 func ext_default_child_storage_root_version_1(context unsafe.Pointer, childStorageKey C.int64_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_root_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -1130,6 +1294,7 @@ func ext_default_child_storage_root_version_1(context unsafe.Pointer, childStora
 //export ext_default_child_storage_set_version_1
 func ext_default_child_storage_set_version_1(context unsafe.Pointer, childStorageKeySpan, keySpan, valueSpan C.int64_t) {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_set_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1152,6 +1317,7 @@ func ext_default_child_storage_set_version_1(context unsafe.Pointer, childStorag
 //export ext_default_child_storage_storage_kill_version_1
 func ext_default_child_storage_storage_kill_version_1(context unsafe.Pointer, childStorageKeySpan C.int64_t) {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_storage_kill_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1164,6 +1330,7 @@ func ext_default_child_storage_storage_kill_version_1(context unsafe.Pointer, ch
 //export ext_default_child_storage_storage_kill_version_2
 func ext_default_child_storage_storage_kill_version_2(context unsafe.Pointer, childStorageKeySpan, lim C.int64_t) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_storage_kill_version_2")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1204,6 +1371,7 @@ func (someRemain) Index() uint {
 //export ext_default_child_storage_storage_kill_version_3
 func ext_default_child_storage_storage_kill_version_3(context unsafe.Pointer, childStorageKeySpan, lim C.int64_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_default_child_storage_storage_kill_version_3")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
 	storage := ctx.Storage
@@ -1256,6 +1424,7 @@ func ext_default_child_storage_storage_kill_version_3(context unsafe.Pointer, ch
 //export ext_allocator_free_version_1
 func ext_allocator_free_version_1(context unsafe.Pointer, addr C.int32_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_allocator_free_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
 
@@ -1268,6 +1437,7 @@ func ext_allocator_free_version_1(context unsafe.Pointer, addr C.int32_t) {
 
 //export ext_allocator_malloc_version_1
 func ext_allocator_malloc_version_1(context unsafe.Pointer, size C.int32_t) C.int32_t {
+	defer traceHostCall(context, "ext_allocator_malloc_version_1")()
 	logger.Tracef("executing with size %d...", int64(size))
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1286,6 +1456,7 @@ func ext_allocator_malloc_version_1(context unsafe.Pointer, size C.int32_t) C.in
 //export ext_hashing_blake2_128_version_1
 func ext_hashing_blake2_128_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_hashing_blake2_128_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -1312,6 +1483,7 @@ func ext_hashing_blake2_128_version_1(context unsafe.Pointer, dataSpan C.int64_t
 //export ext_hashing_blake2_256_version_1
 func ext_hashing_blake2_256_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_hashing_blake2_256_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -1336,6 +1508,7 @@ func ext_hashing_blake2_256_version_1(context unsafe.Pointer, dataSpan C.int64_t
 //export ext_hashing_keccak_256_version_1
 func ext_hashing_keccak_256_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_hashing_keccak_256_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -1360,6 +1533,7 @@ func ext_hashing_keccak_256_version_1(context unsafe.Pointer, dataSpan C.int64_t
 //export ext_hashing_sha2_256_version_1
 func ext_hashing_sha2_256_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_hashing_sha2_256_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -1379,6 +1553,7 @@ func ext_hashing_sha2_256_version_1(context unsafe.Pointer, dataSpan C.int64_t)
 //export ext_hashing_twox_256_version_1
 func ext_hashing_twox_256_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_hashing_twox_256_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -1403,6 +1578,7 @@ func ext_hashing_twox_256_version_1(context unsafe.Pointer, dataSpan C.int64_t)
 //export ext_hashing_twox_128_version_1
 func ext_hashing_twox_128_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_hashing_twox_128_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	data := asMemorySlice(instanceContext, dataSpan)
 
@@ -1428,6 +1604,7 @@ func ext_hashing_twox_128_version_1(context unsafe.Pointer, dataSpan C.int64_t)
 //export ext_hashing_twox_64_version_1
 func ext_hashing_twox_64_version_1(context unsafe.Pointer, dataSpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_hashing_twox_64_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	data := asMemorySlice(instanceContext, dataSpan)
@@ -1454,6 +1631,7 @@ func ext_hashing_twox_64_version_1(context unsafe.Pointer, dataSpan C.int64_t) C
 //export ext_offchain_index_set_version_1
 func ext_offchain_index_set_version_1(context unsafe.Pointer, keySpan, valueSpan C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_offchain_index_set_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
 
@@ -1471,6 +1649,7 @@ func ext_offchain_index_set_version_1(context unsafe.Pointer, keySpan, valueSpan
 //export ext_offchain_local_storage_clear_version_1
 func ext_offchain_local_storage_clear_version_1(context unsafe.Pointer, kind C.int32_t, key C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_offchain_local_storage_clear_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
 
@@ -1496,6 +1675,7 @@ func ext_offchain_local_storage_clear_version_1(context unsafe.Pointer, kind C.i
 //export ext_offchain_is_validator_version_1
 func ext_offchain_is_validator_version_1(context unsafe.Pointer) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_is_validator_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -1508,6 +1688,7 @@ func ext_offchain_is_validator_version_1(context unsafe.Pointer) C.int32_t {
 //export ext_offchain_local_storage_compare_and_set_version_1
 func ext_offchain_local_storage_compare_and_set_version_1(context unsafe.Pointer, kind C.int32_t, key, oldValue, newValue C.int64_t) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_local_storage_compare_and_set_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -1547,6 +1728,7 @@ func ext_offchain_local_storage_compare_and_set_version_1(context unsafe.Pointer
 //export ext_offchain_local_storage_get_version_1
 func ext_offchain_local_storage_get_version_1(context unsafe.Pointer, kind C.int32_t, key C.int64_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_local_storage_get_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -1577,6 +1759,7 @@ func ext_offchain_local_storage_get_version_1(context unsafe.Pointer, kind C.int
 //export ext_offchain_local_storage_set_version_1
 func ext_offchain_local_storage_set_version_1(context unsafe.Pointer, kind C.int32_t, key, value C.int64_t) {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_local_storage_set_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -1601,6 +1784,7 @@ func ext_offchain_local_storage_set_version_1(context unsafe.Pointer, kind C.int
 //export ext_offchain_network_state_version_1
 func ext_offchain_network_state_version_1(context unsafe.Pointer) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_network_state_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
 	if runtimeCtx.Network == nil {
@@ -1631,6 +1815,7 @@ func ext_offchain_network_state_version_1(context unsafe.Pointer) C.int64_t {
 //export ext_offchain_random_seed_version_1
 func ext_offchain_random_seed_version_1(context unsafe.Pointer) C.int32_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_random_seed_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	seed := make([]byte, 32)
@@ -1648,6 +1833,7 @@ func ext_offchain_random_seed_version_1(context unsafe.Pointer) C.int32_t {
 //export ext_offchain_submit_transaction_version_1
 func ext_offchain_submit_transaction_version_1(context unsafe.Pointer, data C.int64_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_submit_transaction_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	extBytes := asMemorySlice(instanceContext, data)
@@ -1663,7 +1849,10 @@ func ext_offchain_submit_transaction_version_1(context unsafe.Pointer, data C.in
 	vtx := transaction.NewValidTransaction(extrinsic, txv)
 
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
-	runtimeCtx.Transaction.AddToPool(vtx)
+	_, err = runtimeCtx.Transaction.AddToPool(vtx)
+	if err != nil {
+		logger.Errorf("failed to add transaction to pool: %s", err)
+	}
 
 	ptr, err := toWasmMemoryOptional(instanceContext, nil)
 	if err != nil {
@@ -1673,16 +1862,18 @@ func ext_offchain_submit_transaction_version_1(context unsafe.Pointer, data C.in
 }
 
 //export ext_offchain_timestamp_version_1
-func ext_offchain_timestamp_version_1(_ unsafe.Pointer) C.int64_t {
+func ext_offchain_timestamp_version_1(context unsafe.Pointer) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_offchain_timestamp_version_1")()
 
 	now := time.Now().Unix()
 	return C.int64_t(now)
 }
 
 //export ext_offchain_sleep_until_version_1
-func ext_offchain_sleep_until_version_1(_ unsafe.Pointer, deadline C.int64_t) {
+func ext_offchain_sleep_until_version_1(context unsafe.Pointer, deadline C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_offchain_sleep_until_version_1")()
 
 	dur := time.Until(time.UnixMilli(int64(deadline)))
 	if dur > 0 {
@@ -1693,6 +1884,7 @@ func ext_offchain_sleep_until_version_1(_ unsafe.Pointer, deadline C.int64_t) {
 //export ext_offchain_http_request_start_version_1
 func ext_offchain_http_request_start_version_1(context unsafe.Pointer, methodSpan, uriSpan, metaSpan C.int64_t) C.int64_t { // skipcq: RVV-B0012
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_http_request_start_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	runtimeCtx := instanceContext.Data().(*runtime.Context)
@@ -1735,6 +1927,7 @@ func ext_offchain_http_request_start_version_1(context unsafe.Pointer, methodSpa
 //export ext_offchain_http_request_add_header_version_1
 func ext_offchain_http_request_add_header_version_1(context unsafe.Pointer, reqID C.int32_t, nameSpan, valueSpan C.int64_t) C.int64_t {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_http_request_add_header_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 
 	name := asMemorySlice(instanceContext, nameSpan)
@@ -1746,13 +1939,15 @@ func ext_offchain_http_request_add_header_version_1(context unsafe.Pointer, reqI
 	result := scale.NewResult(nil, nil)
 	resultMode := scale.OK
 
-	err := offchainReq.AddHeader(string(name), string(value))
-	if err != nil {
+	if offchainReq == nil {
+		logger.Errorf("failed to add request header: invalid request id %d", reqID)
+		resultMode = scale.Err
+	} else if err := offchainReq.AddHeader(string(name), string(value)); err != nil {
 		logger.Errorf("failed to add request header: %s", err)
 		resultMode = scale.Err
 	}
 
-	err = result.Set(resultMode, nil)
+	err := result.Set(resultMode, nil)
 	if err != nil {
 		logger.Errorf("failed to set the result data: %s", err)
 		return C.int64_t(0)
@@ -1773,6 +1968,194 @@ func ext_offchain_http_request_add_header_version_1(context unsafe.Pointer, reqI
 	return C.int64_t(ptr)
 }
 
+//export ext_offchain_http_request_write_body_version_1
+func ext_offchain_http_request_write_body_version_1(context unsafe.Pointer, reqID C.int32_t, chunkSpan, deadlineSpan C.int64_t) C.int64_t {
+	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_http_request_write_body_version_1")()
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	chunk := asMemorySlice(instanceContext, chunkSpan)
+
+	result := scale.NewResult(nil, offchain.HTTPError(0))
+	resultMode, resultValue := scale.OK, interface{}(nil)
+
+	deadline, err := offchainDeadline(asMemorySlice(instanceContext, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		return C.int64_t(0)
+	}
+
+	offchainReq := runtimeCtx.OffchainHTTPSet.Get(int16(reqID))
+	if offchainReq == nil {
+		resultMode, resultValue = scale.Err, offchain.InvalidID
+	} else if err = offchainReq.WriteBody(chunk, deadline); err != nil {
+		logger.Errorf("failed to write request body: %s", err)
+		resultMode, resultValue = scale.Err, httpError(err)
+	}
+
+	err = result.Set(resultMode, resultValue)
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return C.int64_t(0)
+	}
+
+	enc, err := scale.Marshal(result)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the result: %s", err)
+		return C.int64_t(0)
+	}
+
+	ptr, err := toWasmMemory(instanceContext, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return C.int64_t(0)
+	}
+
+	return C.int64_t(ptr)
+}
+
+//export ext_offchain_http_response_wait_version_1
+func ext_offchain_http_response_wait_version_1(context unsafe.Pointer, idsSpan, deadlineSpan C.int64_t) C.int64_t {
+	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_http_response_wait_version_1")()
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	var ids []int16
+	err := scale.Unmarshal(asMemorySlice(instanceContext, idsSpan), &ids)
+	if err != nil {
+		logger.Errorf("failed to decode request ids: %s", err)
+		return C.int64_t(0)
+	}
+
+	deadline, err := offchainDeadline(asMemorySlice(instanceContext, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		return C.int64_t(0)
+	}
+
+	statuses := scale.NewVaryingDataTypeSlice(offchain.NewHTTPRequestStatus())
+	err = statuses.Add(runtimeCtx.OffchainHTTPSet.ResponseWait(ids, deadline)...)
+	if err != nil {
+		logger.Errorf("failed to add request statuses: %s", err)
+		return C.int64_t(0)
+	}
+
+	enc, err := scale.Marshal(statuses)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the request statuses: %s", err)
+		return C.int64_t(0)
+	}
+
+	ptr, err := toWasmMemory(instanceContext, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return C.int64_t(0)
+	}
+
+	return C.int64_t(ptr)
+}
+
+//export ext_offchain_http_response_headers_version_1
+func ext_offchain_http_response_headers_version_1(context unsafe.Pointer, reqID C.int32_t) C.int64_t {
+	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_http_response_headers_version_1")()
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	headers := runtimeCtx.OffchainHTTPSet.ResponseHeaders(int16(reqID))
+	if headers == nil {
+		headers = [][2][]byte{}
+	}
+
+	enc, err := scale.Marshal(headers)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the response headers: %s", err)
+		return C.int64_t(0)
+	}
+
+	ptr, err := toWasmMemory(instanceContext, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return C.int64_t(0)
+	}
+
+	return C.int64_t(ptr)
+}
+
+//export ext_offchain_http_response_read_body_version_1
+func ext_offchain_http_response_read_body_version_1(context unsafe.Pointer, reqID C.int32_t, bufferSpan, deadlineSpan C.int64_t) C.int64_t {
+	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_offchain_http_response_read_body_version_1")()
+	instanceContext := wasm.IntoInstanceContext(context)
+	runtimeCtx := instanceContext.Data().(*runtime.Context)
+
+	buffer := asMemorySlice(instanceContext, bufferSpan)
+
+	result := scale.NewResult(uint32(0), offchain.HTTPError(0))
+
+	deadline, err := offchainDeadline(asMemorySlice(instanceContext, deadlineSpan))
+	if err != nil {
+		logger.Errorf("failed to decode deadline: %s", err)
+		return C.int64_t(0)
+	}
+
+	n, err := runtimeCtx.OffchainHTTPSet.ReadBody(int16(reqID), buffer, deadline)
+	if err != nil {
+		logger.Errorf("failed to read response body: %s", err)
+		err = result.Set(scale.Err, httpError(err))
+	} else {
+		err = result.Set(scale.OK, uint32(n))
+	}
+
+	if err != nil {
+		logger.Errorf("failed to set the result data: %s", err)
+		return C.int64_t(0)
+	}
+
+	enc, err := scale.Marshal(result)
+	if err != nil {
+		logger.Errorf("failed to scale marshal the result: %s", err)
+		return C.int64_t(0)
+	}
+
+	ptr, err := toWasmMemory(instanceContext, enc)
+	if err != nil {
+		logger.Errorf("failed to allocate result on memory: %s", err)
+		return C.int64_t(0)
+	}
+
+	return C.int64_t(ptr)
+}
+
+// offchainDeadline decodes the optional deadline of the offchain http functions,
+// a timestamp in milliseconds
+func offchainDeadline(enc []byte) (*time.Time, error) {
+	var timestamp *uint64
+	err := scale.Unmarshal(enc, &timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	if timestamp == nil {
+		return nil, nil
+	}
+
+	deadline := time.UnixMilli(int64(*timestamp))
+	return &deadline, nil
+}
+
+// httpError returns the offchain.HTTPError of the error, or offchain.IOError if there is none
+func httpError(err error) offchain.HTTPError {
+	var httpErr offchain.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+
+	return offchain.IOError
+}
+
 func storageAppend(storage runtime.Storage, key, valueToAppend []byte) error {
 	nextLength := big.NewInt(1)
 	var valueRes []byte
@@ -1821,6 +2204,7 @@ func storageAppend(storage runtime.Storage, key, valueToAppend []byte) error {
 //export ext_storage_append_version_1
 func ext_storage_append_version_1(context unsafe.Pointer, keySpan, valueSpan C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_append_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
 	storage := ctx.Storage
@@ -1843,6 +2227,7 @@ func ext_storage_append_version_1(context unsafe.Pointer, keySpan, valueSpan C.i
 //export ext_storage_changes_root_version_1
 func ext_storage_changes_root_version_1(context unsafe.Pointer, parentHashSpan C.int64_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_changes_root_version_1")()
 	logger.Debug("returning None")
 
 	instanceContext := wasm.IntoInstanceContext(context)
@@ -1859,6 +2244,7 @@ func ext_storage_changes_root_version_1(context unsafe.Pointer, parentHashSpan C
 //export ext_storage_clear_version_1
 func ext_storage_clear_version_1(context unsafe.Pointer, keySpan C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_clear_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
 	storage := ctx.Storage
@@ -1872,6 +2258,7 @@ func ext_storage_clear_version_1(context unsafe.Pointer, keySpan C.int64_t) {
 //export ext_storage_clear_prefix_version_1
 func ext_storage_clear_prefix_version_1(context unsafe.Pointer, prefixSpan C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_clear_prefix_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
 	storage := ctx.Storage
@@ -1888,6 +2275,7 @@ func ext_storage_clear_prefix_version_1(context unsafe.Pointer, prefixSpan C.int
 //export ext_storage_clear_prefix_version_2
 func ext_storage_clear_prefix_version_2(context unsafe.Pointer, prefixSpan, lim C.int64_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_clear_prefix_version_2")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -1933,6 +2321,7 @@ func ext_storage_clear_prefix_version_2(context unsafe.Pointer, prefixSpan, lim
 //export ext_storage_exists_version_1
 func ext_storage_exists_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int32_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_exists_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
 
@@ -1950,6 +2339,7 @@ func ext_storage_exists_version_1(context unsafe.Pointer, keySpan C.int64_t) C.i
 //export ext_storage_get_version_1
 func ext_storage_get_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_get_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -1973,6 +2363,7 @@ func ext_storage_get_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int6
 //export ext_storage_next_key_version_1
 func ext_storage_next_key_version_1(context unsafe.Pointer, keySpan C.int64_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_next_key_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -1996,6 +2387,7 @@ func ext_storage_next_key_version_1(context unsafe.Pointer, keySpan C.int64_t) C
 //export ext_storage_read_version_1
 func ext_storage_read_version_1(context unsafe.Pointer, keySpan, valueOut C.int64_t, offset C.int32_t) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_read_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -2034,6 +2426,7 @@ func ext_storage_read_version_1(context unsafe.Pointer, keySpan, valueOut C.int6
 //export ext_storage_root_version_1
 func ext_storage_root_version_1(context unsafe.Pointer) C.int64_t {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_root_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	storage := instanceContext.Data().(*runtime.Context).Storage
@@ -2058,6 +2451,7 @@ func ext_storage_root_version_1(context unsafe.Pointer) C.int64_t {
 //export ext_storage_set_version_1
 func ext_storage_set_version_1(context unsafe.Pointer, keySpan, valueSpan C.int64_t) {
 	logger.Trace("executing...")
+	defer traceHostCall(context, "ext_storage_set_version_1")()
 
 	instanceContext := wasm.IntoInstanceContext(context)
 	ctx := instanceContext.Data().(*runtime.Context)
@@ -2078,6 +2472,7 @@ func ext_storage_set_version_1(context unsafe.Pointer, keySpan, valueSpan C.int6
 //export ext_storage_start_transaction_version_1
 func ext_storage_start_transaction_version_1(context unsafe.Pointer) {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_storage_start_transaction_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	instanceContext.Data().(*runtime.Context).Storage.BeginStorageTransaction()
 }
@@ -2085,12 +2480,14 @@ func ext_storage_start_transaction_version_1(context unsafe.Pointer) {
 //export ext_storage_rollback_transaction_version_1
 func ext_storage_rollback_transaction_version_1(context unsafe.Pointer) {
 	logger.Debug("executing...")
+	defer traceHostCall(context, "ext_storage_rollback_transaction_version_1")()
 	instanceContext := wasm.IntoInstanceContext(context)
 	instanceContext.Data().(*runtime.Context).Storage.RollbackStorageTransaction()
 }
 
 //export ext_storage_commit_transaction_version_1
 func ext_storage_commit_transaction_version_1(context unsafe.Pointer) {
+	defer traceHostCall(context, "ext_storage_commit_transaction_version_1")()
 	logger.Debug("[ext_storage_commit_transaction_version_1] executing...")
 	instanceContext := wasm.IntoInstanceContext(context)
 	instanceContext.Data().(*runtime.Context).Storage.CommitStorageTransaction()
@@ -2453,6 +2850,22 @@ func ImportsNodeRuntime() (*wasm.Imports, error) { //nolint:gocyclo
 	if err != nil {
 		return nil, err
 	}
+	_, err = imports.Append("ext_offchain_http_request_write_body_version_1", ext_offchain_http_request_write_body_version_1, C.ext_offchain_http_request_write_body_version_1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = imports.Append("ext_offchain_http_response_wait_version_1", ext_offchain_http_response_wait_version_1, C.ext_offchain_http_response_wait_version_1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = imports.Append("ext_offchain_http_response_headers_version_1", ext_offchain_http_response_headers_version_1, C.ext_offchain_http_response_headers_version_1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = imports.Append("ext_offchain_http_response_read_body_version_1", ext_offchain_http_response_read_body_version_1, C.ext_offchain_http_response_read_body_version_1)
+	if err != nil {
+		return nil, err
+	}
 	_, err = imports.Append("ext_sandbox_instance_teardown_version_1", ext_sandbox_instance_teardown_version_1, C.ext_sandbox_instance_teardown_version_1)
 	if err != nil {
 		return nil, err
@@ -6,7 +6,9 @@
 import (
 	"bytes"
 	"encoding/binary"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"sort"
 	"testing"
@@ -22,6 +24,7 @@
 	"github.com/ChainSafe/gossamer/lib/crypto/sr25519"
 	"github.com/ChainSafe/gossamer/lib/keystore"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/offchain"
 	"github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/ChainSafe/gossamer/lib/trie"
 	"github.com/ChainSafe/gossamer/pkg/scale"
@@ -381,6 +384,287 @@ func Test_ext_offchain_http_request_add_header(t *testing.T) {
 	}
 }
 
+func scaleEncode(t *testing.T, value interface{}) []byte {
+	t.Helper()
+
+	enc, err := scale.Marshal(value)
+	require.NoError(t, err)
+	return enc
+}
+
+// encodeOffchainDeadline returns the encoded optional deadline of the offchain http functions
+func encodeOffchainDeadline(t *testing.T, deadline *time.Time) []byte {
+	t.Helper()
+
+	var timestamp *uint64
+	if deadline != nil {
+		ms := uint64(deadline.UnixMilli())
+		timestamp = &ms
+	}
+
+	enc, err := scale.Marshal(timestamp)
+	require.NoError(t, err)
+	return enc
+}
+
+func Test_ext_offchain_http_request_add_header_version_1_InvalidID(t *testing.T) {
+	t.Parallel()
+	inst := NewTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
+
+	params := append([]byte{}, scaleEncode(t, uint32(99))...)
+	params = append(params, scaleEncode(t, "SOME_HEADER_KEY")...)
+	params = append(params, scaleEncode(t, "SOME_HEADER_VALUE")...)
+
+	ret, err := inst.Exec("rtm_ext_offchain_http_request_add_header_version_1", params)
+	require.NoError(t, err)
+
+	result := scale.NewResult(nil, nil)
+	err = scale.Unmarshal(ret, &result)
+	require.NoError(t, err)
+
+	_, err = result.Unwrap()
+	require.Error(t, err)
+}
+
+func Test_ext_offchain_http_request_write_body_version_1(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received <- body
+	}))
+	defer server.Close()
+
+	// the handler of this server does not read the request body
+	blocked := make(chan struct{})
+	blockingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+	}))
+	defer blockingServer.Close()
+	defer close(blocked)
+
+	inst := NewTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
+
+	writeBody := func(reqID int16, chunk []byte, deadline *time.Time) error {
+		params := append([]byte{}, scaleEncode(t, uint32(reqID))...)
+		params = append(params, scaleEncode(t, chunk)...)
+		params = append(params, encodeOffchainDeadline(t, deadline)...)
+
+		ret, err := inst.Exec("rtm_ext_offchain_http_request_write_body_version_1", params)
+		require.NoError(t, err)
+
+		result := scale.NewResult(nil, offchain.HTTPError(0))
+		err = scale.Unmarshal(ret, &result)
+		require.NoError(t, err)
+
+		_, err = result.Unwrap()
+		return err
+	}
+
+	reqID, err := inst.ctx.OffchainHTTPSet.StartRequest(http.MethodPost, server.URL)
+	require.NoError(t, err)
+
+	err = writeBody(reqID, []byte("hello "), nil)
+	require.NoError(t, err)
+	err = writeBody(reqID, []byte("world"), nil)
+	require.NoError(t, err)
+
+	// an empty chunk finalises the body
+	err = writeBody(reqID, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello world"), <-received)
+
+	err = writeBody(reqID, []byte("!"), nil)
+	require.Equal(t, scale.WrappedErr{Err: offchain.IOError}, err)
+
+	err = writeBody(reqID+1, []byte("hello"), nil)
+	require.Equal(t, scale.WrappedErr{Err: offchain.InvalidID}, err)
+
+	// the chunk cannot be written before the deadline as the server does not read it
+	reqID, err = inst.ctx.OffchainHTTPSet.StartRequest(http.MethodPost, blockingServer.URL)
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	err = writeBody(reqID, make([]byte, 16*1024*1024), &deadline)
+	require.Equal(t, scale.WrappedErr{Err: offchain.DeadlineReached}, err)
+}
+
+func Test_ext_offchain_http_response_wait_version_1(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	blocked := make(chan struct{})
+	blockingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-blocked
+	}))
+	defer blockingServer.Close()
+	defer close(blocked)
+
+	inst := NewTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
+
+	responseWait := func(ids []int16, deadline *time.Time) []interface{} {
+		params := append([]byte{}, scaleEncode(t, ids)...)
+		params = append(params, encodeOffchainDeadline(t, deadline)...)
+
+		ret, err := inst.Exec("rtm_ext_offchain_http_response_wait_version_1", params)
+		require.NoError(t, err)
+
+		var encStatuses []byte
+		err = scale.Unmarshal(ret, &encStatuses)
+		require.NoError(t, err)
+
+		statuses := scale.NewVaryingDataTypeSlice(offchain.NewHTTPRequestStatus())
+		err = scale.Unmarshal(encStatuses, &statuses)
+		require.NoError(t, err)
+
+		values := make([]interface{}, len(statuses.Types))
+		for i, status := range statuses.Types {
+			values[i] = status.Value()
+		}
+		return values
+	}
+
+	reqID, err := inst.ctx.OffchainHTTPSet.StartRequest(http.MethodGet, server.URL)
+	require.NoError(t, err)
+
+	statuses := responseWait([]int16{reqID, reqID + 1}, nil)
+	require.Equal(t, []interface{}{
+		offchain.FinishedStatus(http.StatusTeapot),
+		offchain.InvalidStatus{},
+	}, statuses)
+
+	reqID, err = inst.ctx.OffchainHTTPSet.StartRequest(http.MethodGet, blockingServer.URL)
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	statuses = responseWait([]int16{reqID}, &deadline)
+	require.Equal(t, []interface{}{offchain.DeadlineReachedStatus{}}, statuses)
+}
+
+func Test_ext_offchain_http_response_headers_version_1(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["X-Test"] = []string{"first", "second"}
+		w.Header().Set("Content-Length", "0")
+		w.Header().Set("Date", "today")
+	}))
+	defer server.Close()
+
+	inst := NewTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
+
+	responseHeaders := func(reqID int16) [][2][]byte {
+		ret, err := inst.Exec("rtm_ext_offchain_http_response_headers_version_1", scaleEncode(t, uint32(reqID)))
+		require.NoError(t, err)
+
+		var encHeaders []byte
+		err = scale.Unmarshal(ret, &encHeaders)
+		require.NoError(t, err)
+
+		var headers [][2][]byte
+		err = scale.Unmarshal(encHeaders, &headers)
+		require.NoError(t, err)
+		return headers
+	}
+
+	reqID, err := inst.ctx.OffchainHTTPSet.StartRequest(http.MethodGet, server.URL)
+	require.NoError(t, err)
+
+	// there are no headers until the response is received
+	require.Empty(t, responseHeaders(reqID))
+
+	inst.ctx.OffchainHTTPSet.ResponseWait([]int16{reqID}, nil)
+	require.Equal(t, [][2][]byte{
+		{[]byte("Content-Length"), []byte("0")},
+		{[]byte("Date"), []byte("today")},
+		{[]byte("X-Test"), []byte("first")},
+		{[]byte("X-Test"), []byte("second")},
+	}, responseHeaders(reqID))
+
+	require.Empty(t, responseHeaders(reqID+1))
+}
+
+func Test_ext_offchain_http_response_read_body_version_1(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("hello world"))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	// the handler of this server sends the response headers, but not the body
+	blocked := make(chan struct{})
+	blockingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-blocked
+	}))
+	defer blockingServer.Close()
+	defer close(blocked)
+
+	inst := NewTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
+
+	readBody := func(reqID int16, deadline *time.Time) (uint32, error) {
+		params := append([]byte{}, scaleEncode(t, uint32(reqID))...)
+		params = append(params, scaleEncode(t, make([]byte, 8))...)
+		params = append(params, encodeOffchainDeadline(t, deadline)...)
+
+		ret, err := inst.Exec("rtm_ext_offchain_http_response_read_body_version_1", params)
+		require.NoError(t, err)
+
+		result := scale.NewResult(uint32(0), offchain.HTTPError(0))
+		err = scale.Unmarshal(ret, &result)
+		require.NoError(t, err)
+
+		n, err := result.Unwrap()
+		if err != nil {
+			return 0, err
+		}
+		return n.(uint32), nil
+	}
+
+	reqID, err := inst.ctx.OffchainHTTPSet.StartRequest(http.MethodGet, server.URL)
+	require.NoError(t, err)
+
+	// the body cannot be read before the request is dispatched
+	_, err = readBody(reqID, nil)
+	require.Equal(t, scale.WrappedErr{Err: offchain.IOError}, err)
+
+	inst.ctx.OffchainHTTPSet.ResponseWait([]int16{reqID}, nil)
+
+	// the body is read in chunks of the size of the buffer, until zero bytes are read
+	var total uint32
+	for {
+		n, err := readBody(reqID, nil)
+		require.NoError(t, err)
+		require.LessOrEqual(t, n, uint32(8))
+		if n == 0 {
+			break
+		}
+		total += n
+	}
+	require.Equal(t, uint32(len("hello world")), total)
+
+	// the request is removed once its body was read
+	_, err = readBody(reqID, nil)
+	require.Equal(t, scale.WrappedErr{Err: offchain.InvalidID}, err)
+
+	reqID, err = inst.ctx.OffchainHTTPSet.StartRequest(http.MethodGet, blockingServer.URL)
+	require.NoError(t, err)
+	inst.ctx.OffchainHTTPSet.ResponseWait([]int16{reqID}, nil)
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	_, err = readBody(reqID, &deadline)
+	require.Equal(t, scale.WrappedErr{Err: offchain.DeadlineReached}, err)
+}
+
 func Test_ext_storage_clear_prefix_version_1_hostAPI(t *testing.T) {
 	t.Parallel()
 	inst := NewTestInstance(t, runtime.HOST_API_TEST_RUNTIME)
@@ -4,7 +4,6 @@
 package wasmer
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"sync"
@@ -19,8 +18,6 @@
 	"github.com/ChainSafe/gossamer/lib/crypto"
 
 	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
-
-	"github.com/klauspost/compress/zstd"
 )
 
 // Name represents the name of the interpreter
@@ -28,8 +25,9 @@
 
 // Check that runtime interfaces are satisfied
 var (
-	_ runtime.Instance = (*Instance)(nil)
-	_ runtime.Memory   = (*wasm.Memory)(nil)
+	_ runtime.Instance       = (*Instance)(nil)
+	_ runtime.PooledInstance = (*Instance)(nil)
+	_ runtime.Memory         = (*wasm.Memory)(nil)
 
 	logger = log.NewFromGlobal(
 		log.AddContext("pkg", "runtime"),
@@ -41,6 +39,8 @@
 type Config struct {
 	runtime.InstanceConfig
 	Imports func() (*wasm.Imports, error)
+	// Executor creates the instance running its calls on a pool of instances if it is set
+	Executor *Executor
 }
 
 // Instance represents a v0.8 runtime go-wasmer instance
@@ -51,6 +51,12 @@ type Instance struct {
 	imports  func() (*wasm.Imports, error)
 	isClosed bool
 	codeHash common.Hash
+	// pool is set if the calls are run on a pool of instances, in which case vm isn't used
+	pool *pool
+	// handle is true if the instance is a handle of a pooled instance, sharing its pool without owning it
+	handle bool
+	// heapPages is the number of heap pages of the storage the instance was created with
+	heapPages uint64
 	sync.Mutex
 }
 
@@ -98,48 +104,42 @@ func NewInstance(code []byte, cfg *Config) (*Instance, error) {
 	}
 
 	var err error
-	code, err = decompressWasm(code)
+	code, err = runtime.DecompressWasm(code)
 	if err != nil {
 		return nil, fmt.Errorf("cannot decompress WASM code: %w", err)
 	}
+	code = exportTableFunctions(code)
 
 	logger.Patch(log.SetLevel(cfg.LogLvl), log.SetCallerFunc(true))
 
-	imports, err := cfg.Imports()
-	if err != nil {
-		return nil, err
+	if cfg.Executor != nil {
+		return cfg.Executor.newInstance(code, cfg)
 	}
 
-	// Provide importable memory for newer runtimes
-	// TODO: determine memory descriptor size that the runtime wants from the wasm. (#1268)
-	// should be doable w/ wasmer 1.0.0.
-	memory, err := wasm.NewMemory(23, 0)
+	info, err := runtime.ParseModuleInfo(code)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("cannot parse WASM module: %w", err)
 	}
 
-	_, err = imports.AppendMemory("memory", memory)
+	inst, err := newInstance(func(imports *wasm.Imports) (wasm.Instance, error) {
+		return wasm.NewInstanceWithImports(code, imports)
+	}, info, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// Instantiates the WebAssembly module.
-	instance, err := wasm.NewInstanceWithImports(code, imports)
+	inst.version, _ = inst.Version()
+	return inst, nil
+}
+
+// newInstance creates an instance of the runtime with the given instantiation function
+func newInstance(instantiate func(*wasm.Imports) (wasm.Instance, error), info *runtime.ModuleInfo,
+	cfg *Config) (*Instance, error) {
+	instance, allocator, err := instantiateVM(instantiate, info, cfg.Imports, cfg.Storage)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: get __heap_base exported value from runtime.
-	// wasmer 0.3.x does not support this, but wasmer 1.0.0 does (#1268)
-	heapBase := runtime.DefaultHeapBase
-
-	// Assume imported memory is used if runtime does not export any
-	if !instance.HasMemory() {
-		instance.Memory = memory
-	}
-
-	allocator := runtime.NewAllocator(instance.Memory, heapBase)
-
 	runtimeCtx := &runtime.Context{
 		Storage:         cfg.Storage,
 		Allocator:       allocator,
@@ -155,31 +155,12 @@ func NewInstance(code []byte, cfg *Config) (*Instance, error) {
 	logger.Debugf("NewInstance called with runtimeCtx: %v", runtimeCtx)
 	instance.SetContextData(runtimeCtx)
 
-	inst := &Instance{
+	return &Instance{
 		vm:       instance,
 		ctx:      runtimeCtx,
 		imports:  cfg.Imports,
 		codeHash: cfg.CodeHash,
-	}
-
-	inst.version, _ = inst.Version()
-	return inst, nil
-}
-
-// decompressWasm decompresses a Wasm blob that may or may not be compressed with zstd
-// ref: https://github.com/paritytech/substrate/blob/master/primitives/maybe-compressed-blob/src/lib.rs
-func decompressWasm(code []byte) ([]byte, error) {
-	compressionFlag := []byte{82, 188, 83, 118, 70, 219, 142, 5}
-	if !bytes.HasPrefix(code, compressionFlag) {
-		return code, nil
-	}
-
-	decoder, err := zstd.NewReader(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
-	}
-
-	return decoder.DecodeAll(code[len(compressionFlag):], nil)
+	}, nil
 }
 
 // GetCodeHash returns the code of the instance
@@ -189,6 +170,10 @@ func (in *Instance) GetCodeHash() common.Hash {
 
 // UpdateRuntimeCode updates the runtime instance to run the given code
 func (in *Instance) UpdateRuntimeCode(code []byte) error {
+	if in.pool != nil {
+		return in.updatePool(code)
+	}
+
 	in.Stop()
 
 	err := in.setupInstanceVM(code)
@@ -205,60 +190,124 @@ func (in *Instance) UpdateRuntimeCode(code []byte) error {
 	return nil
 }
 
+// updatePool replaces the pool of the instance with a pool of instances of the given code
+func (in *Instance) updatePool(code []byte) error {
+	code, err := runtime.DecompressWasm(code)
+	if err != nil {
+		return fmt.Errorf("cannot decompress WASM code: %w", err)
+	}
+
+	in.Lock()
+	defer in.Unlock()
+
+	p, err := in.pool.executor.newPool(exportTableFunctions(code), &in.pool.cfg)
+	if err != nil {
+		return err
+	}
+
+	in.pool.stop()
+	in.pool = p
+	in.isClosed = false
+	in.version = nil
+	return nil
+}
+
 // CheckRuntimeVersion calculates runtime Version for runtime blob passed in
 func (in *Instance) CheckRuntimeVersion(code []byte) (runtime.Version, error) {
-	tmp := &Instance{
-		imports: in.imports,
-		ctx:     in.ctx,
+	if in.pool != nil {
+		storage := in.storage()
+		vm, err := in.pool.get(storage)
+		if err != nil {
+			return nil, err
+		}
+		defer in.pool.put(vm)
+
+		vm.SetContextStorage(storage)
+		return vm.CheckRuntimeVersion(code)
 	}
 
 	in.Lock()
 	defer in.Unlock()
 
+	// the context is copied, as setting up the temporary instance replaces its allocator
+	ctx := *in.ctx
+	tmp := &Instance{
+		imports: in.imports,
+		ctx:     &ctx,
+	}
+
 	err := tmp.setupInstanceVM(code)
 	if err != nil {
 		return nil, err
 	}
+	defer tmp.Stop()
 
 	return tmp.Version()
 }
 
 func (in *Instance) setupInstanceVM(code []byte) error {
-	imports, err := in.imports()
+	code = exportTableFunctions(code)
+	info, err := runtime.ParseModuleInfo(code)
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot parse WASM module: %w", err)
 	}
 
-	// TODO: determine memory descriptor size that the runtime wants from the wasm.
-	// should be doable w/ wasmer 1.0.0. (#1268)
-	memory, err := wasm.NewMemory(23, 0)
+	in.vm, in.ctx.Allocator, err = instantiateVM(func(imports *wasm.Imports) (wasm.Instance, error) {
+		return wasm.NewInstanceWithImports(code, imports)
+	}, info, in.imports, in.ctx.Storage)
 	if err != nil {
 		return err
 	}
 
+	in.vm.SetContextData(in.ctx)
+	return nil
+}
+
+// instantiateVM instantiates the module with the given imports and the memory described by the module,
+// and returns the instance along with the allocator of its heap. The memory can grow by the number of
+// heap pages set in the storage, up to the maximum of the module.
+func instantiateVM(instantiate func(*wasm.Imports) (wasm.Instance, error), info *runtime.ModuleInfo,
+	importsFunc func() (*wasm.Imports, error), storage runtime.Storage) (
+	wasm.Instance, *runtime.FreeingBumpHeapAllocator, error) {
+	heapPages, err := runtime.HeapPages(storage)
+	if err != nil {
+		return wasm.Instance{}, nil, err
+	}
+
+	maxPages, err := info.MemoryLimit(heapPages)
+	if err != nil {
+		return wasm.Instance{}, nil, err
+	}
+
+	imports, err := importsFunc()
+	if err != nil {
+		return wasm.Instance{}, nil, err
+	}
+
+	// Provide importable memory for newer runtimes
+	memory, err := wasm.NewMemory(info.MemoryMin, maxPages)
+	if err != nil {
+		return wasm.Instance{}, nil, err
+	}
+
 	_, err = imports.AppendMemory("memory", memory)
 	if err != nil {
-		return err
+		return wasm.Instance{}, nil, err
 	}
 
 	// Instantiates the WebAssembly module.
-	in.vm, err = wasm.NewInstanceWithImports(code, imports)
+	instance, err := instantiate(imports)
 	if err != nil {
-		return err
+		return wasm.Instance{}, nil, err
 	}
 
 	// Assume imported memory is used if runtime does not export any
-	if !in.vm.HasMemory() {
-		in.vm.Memory = memory
+	if !instance.HasMemory() {
+		instance.Memory = memory
 	}
 
-	// TODO: get __heap_base exported value from runtime.
-	// wasmer 0.3.x does not support this, but wasmer 1.0.0 does (#1268)
-	heapBase := runtime.DefaultHeapBase
-
-	in.ctx.Allocator = runtime.NewAllocator(in.vm.Memory, heapBase)
-	in.vm.SetContextData(in.ctx)
-	return nil
+	allocator := runtime.NewAllocator(runtime.NewLimitedMemory(instance.Memory, maxPages), info.HeapBase)
+	return instance, allocator, nil
 }
 
 // SetContextStorage sets the runtime's storage. It should be set before calls to the below functions.
@@ -268,14 +317,61 @@ func (in *Instance) SetContextStorage(s runtime.Storage) {
 	in.ctx.Storage = s
 }
 
+// storage returns the storage of the instance's context
+func (in *Instance) storage() runtime.Storage {
+	in.Lock()
+	defer in.Unlock()
+	return in.ctx.Storage
+}
+
+// Handle returns an instance running its calls on the pool of the instance, with its own context storage,
+// so that it can be used concurrently with the instance. It returns the instance itself if it isn't pooled.
+func (in *Instance) Handle() runtime.Instance {
+	if in.pool == nil {
+		return in
+	}
+
+	in.Lock()
+	defer in.Unlock()
+
+	ctx := *in.ctx
+	return &Instance{
+		ctx:      &ctx,
+		version:  in.version,
+		imports:  in.imports,
+		codeHash: in.codeHash,
+		pool:     in.pool,
+		handle:   true,
+	}
+}
+
+// Executor returns the executor which created the instance, or nil if it isn't pooled
+func (in *Instance) Executor() *Executor {
+	if in.pool == nil {
+		return nil
+	}
+
+	return in.pool.executor
+}
+
 // Stop func
 func (in *Instance) Stop() {
 	in.Lock()
 	defer in.Unlock()
-	if !in.isClosed {
+	if in.isClosed {
+		return
+	}
+
+	// stopping a pooled instance stops the pool it shares with its handles,
+	// while stopping a handle leaves the pool to the instance owning it
+	if in.pool != nil {
+		if !in.handle {
+			in.pool.stop()
+		}
+	} else {
 		in.vm.Close()
-		in.isClosed = true
 	}
+	in.isClosed = true
 }
 
 // Store func
@@ -297,6 +393,22 @@ func (in *Instance) Exec(function string, data []byte) ([]byte, error) {
 
 // Exec func
 func (in *Instance) exec(function string, data []byte) ([]byte, error) {
+	if in.pool != nil {
+		storage := in.storage()
+		if storage == nil {
+			return nil, runtime.ErrNilStorage
+		}
+
+		in.Lock()
+		p, isClosed := in.pool, in.isClosed
+		in.Unlock()
+		if isClosed {
+			return nil, errors.New("instance is stopped")
+		}
+
+		return p.exec(storage, function, data)
+	}
+
 	if in.ctx.Storage == nil {
 		return nil, runtime.ErrNilStorage
 	}
@@ -315,13 +427,24 @@ func (in *Instance) exec(function string, data []byte) ([]byte, error) {
 
 	defer in.clear()
 
+	// a signature verification batch left unfinished by a failed call is discarded
+	defer func() {
+		if in.ctx.SigVerifier.IsStarted() {
+			in.ctx.SigVerifier.Finish()
+		}
+	}()
+
+	// the sandboxed instances and memories only live for the duration of the call
+	in.ctx.Sandbox = newSandbox(in)
+	defer func() { in.ctx.Sandbox = nil }()
+
 	// Store the data into memory
 	in.store(data, int32(ptr))
 	datalen := int32(len(data))
 
 	runtimeFunc, ok := in.vm.Exports[function]
 	if !ok {
-		return nil, fmt.Errorf("could not find exported function %s", function)
+		return nil, fmt.Errorf("%w: %s", runtime.ErrExportFunctionNotFound, function)
 	}
 
 	res, err := runtimeFunc(int32(ptr), datalen)
@@ -8,10 +8,10 @@
 	"path/filepath"
 	"testing"
 
+	"github.com/ChainSafe/gossamer/dot/types"
 	"github.com/ChainSafe/gossamer/lib/runtime"
+	"github.com/ChainSafe/gossamer/lib/runtime/storage"
 	"github.com/stretchr/testify/require"
-
-	"github.com/klauspost/compress/zstd"
 )
 
 // test used for ensuring runtime exec calls can me made concurrently
@@ -66,40 +66,33 @@ func TestInstance_CheckRuntimeVersion(t *testing.T) {
 	require.Equal(t, expected.TransactionVersion(), version.TransactionVersion())
 }
 
-func TestDecompressWasm(t *testing.T) {
-	encoder, err := zstd.NewWriter(nil)
+func TestInstance_Trace(t *testing.T) {
+	instance := NewTestInstance(t, runtime.NODE_RUNTIME)
+
+	ts, err := storage.NewTrieState(nil)
 	require.NoError(t, err)
 
-	cases := []struct {
-		in       []byte
-		expected []byte
-		msg      string
-	}{
-		{
-			[]byte{82, 188, 83, 118, 70, 219, 142},
-			[]byte{82, 188, 83, 118, 70, 219, 142},
-			"partial compression flag",
-		},
-		{
-			[]byte{82, 188, 83, 118, 70, 219, 142, 6},
-			[]byte{82, 188, 83, 118, 70, 219, 142, 6},
-			"wrong compression flag",
-		},
-		{
-			[]byte{82, 188, 83, 118, 70, 219, 142, 6, 221},
-			[]byte{82, 188, 83, 118, 70, 219, 142, 6, 221},
-			"wrong compression flag with data",
-		},
-		{
-			append([]byte{82, 188, 83, 118, 70, 219, 142, 5}, encoder.EncodeAll([]byte("compressed"), nil)...),
-			[]byte("compressed"),
-			"compressed data",
-		},
+	tracer := runtime.NewTracer()
+	instance.SetContextStorage(tracer.Storage(ts))
+
+	header := &types.Header{
+		Number: 1,
+		Digest: types.NewDigest(),
 	}
+	err = instance.InitializeBlock(header)
+	require.NoError(t, err)
+
+	trace := tracer.Trace()
+	require.NotEmpty(t, trace.HostCalls)
+	require.NotEmpty(t, trace.Profile)
 
-	for _, test := range cases {
-		actual, err := decompressWasm(test.in)
-		require.NoError(t, err)
-		require.Equal(t, test.expected, actual)
+	var storageAccesses int
+	for _, call := range trace.HostCalls {
+		require.Contains(t, call.Name, "ext_")
+		storageAccesses += len(call.Storage)
 	}
+	require.NotZero(t, storageAccesses)
+
+	// the block number is set by the runtime
+	require.NotEmpty(t, ts.TrieEntries())
 }
@@ -0,0 +1,279 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ChainSafe/gossamer/lib/runtime/sandbox"
+)
+
+// tableFunctionPrefix is the prefix of the names of the exports added for the functions of the table,
+// so the dispatch thunks given to the sandbox host functions can be called.
+const tableFunctionPrefix = "__gossamer_table_function_"
+
+// wasm section ids
+const (
+	sectionExport  byte = 7
+	sectionElement byte = 9
+	sectionCustom  byte = 0
+)
+
+var errUnsupportedElements = errors.New("unsupported element section")
+
+// supervisor implements sandbox.Supervisor for the runtime instance
+type supervisor struct {
+	instance *Instance
+}
+
+// Memory returns the memory of the runtime instance
+func (s *supervisor) Memory() []byte {
+	return s.instance.vm.Memory.Data()
+}
+
+// Allocate allocates memory with the allocator of the runtime instance
+func (s *supervisor) Allocate(size uint32) (uint32, error) {
+	return s.instance.ctx.Allocator.Allocate(size)
+}
+
+// Deallocate frees memory with the allocator of the runtime instance
+func (s *supervisor) Deallocate(pointer uint32) error {
+	return s.instance.ctx.Allocator.Deallocate(pointer)
+}
+
+// DispatchThunk calls the function of the table of the runtime instance at index thunk
+func (s *supervisor) DispatchThunk(thunk, argsPointer, argsSize, state, function uint32) (int64, error) {
+	name := fmt.Sprintf("%s%d", tableFunctionPrefix, thunk)
+	dispatch, ok := s.instance.vm.Exports[name]
+	if !ok {
+		return 0, fmt.Errorf("no function at index %d of the table", thunk)
+	}
+
+	res, err := dispatch(int32(argsPointer), int32(argsSize), int32(state), int32(function))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.ToI64(), nil
+}
+
+// exportTableFunctions adds an export for each function of the element section of the wasm code,
+// as the table cannot be accessed with wasmer 0.3.x. The code is returned unchanged if the element
+// section cannot be parsed.
+func exportTableFunctions(code []byte) []byte {
+	exported, err := appendTableExports(code)
+	if err != nil {
+		logger.Debugf("cannot export functions of the table: %s", err)
+		return code
+	}
+
+	return exported
+}
+
+// wasmSection is a section of a wasm module
+type wasmSection struct {
+	id      byte
+	payload []byte
+}
+
+func appendTableExports(code []byte) ([]byte, error) {
+	const headerSize = 8
+	if len(code) < headerSize {
+		return nil, errors.New("code is too short")
+	}
+
+	r := bytes.NewReader(code[headerSize:])
+	var sections []wasmSection
+	for r.Len() > 0 {
+		id, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := readVarUint32(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if uint32(r.Len()) < size {
+			return nil, fmt.Errorf("section %d is truncated", id)
+		}
+
+		offset := len(code) - r.Len()
+		sections = append(sections, wasmSection{id: id, payload: code[offset : offset+int(size)]})
+		_, _ = r.Seek(int64(size), io.SeekCurrent)
+	}
+
+	var elements []byte
+	for _, s := range sections {
+		if s.id == sectionElement {
+			elements = s.payload
+		}
+	}
+
+	if elements == nil {
+		return code, nil
+	}
+
+	table, err := tableFunctions(elements)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]uint32, 0, len(table))
+	for index := range table {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	var exports []byte
+	for _, index := range indices {
+		function := table[index]
+		name := fmt.Sprintf("%s%d", tableFunctionPrefix, index)
+		exports = append(exports, encodeVarUint32(uint32(len(name)))...)
+		exports = append(exports, name...)
+		// function export
+		exports = append(exports, 0)
+		exports = append(exports, encodeVarUint32(function)...)
+	}
+
+	out := append([]byte{}, code[:headerSize]...)
+	inserted := false
+	for _, s := range sections {
+		switch {
+		case inserted:
+		case s.id == sectionExport:
+			r := bytes.NewReader(s.payload)
+			count, err := readVarUint32(r)
+			if err != nil {
+				return nil, err
+			}
+
+			payload := encodeVarUint32(count + uint32(len(table)))
+			payload = append(payload, s.payload[len(s.payload)-r.Len():]...)
+			payload = append(payload, exports...)
+			out = appendSection(out, sectionExport, payload)
+			inserted = true
+			continue
+		case s.id != sectionCustom && s.id > sectionExport:
+			payload := append(encodeVarUint32(uint32(len(table))), exports...)
+			out = appendSection(out, sectionExport, payload)
+			inserted = true
+		}
+
+		out = appendSection(out, s.id, s.payload)
+	}
+
+	if !inserted {
+		payload := append(encodeVarUint32(uint32(len(table))), exports...)
+		out = appendSection(out, sectionExport, payload)
+	}
+
+	return out, nil
+}
+
+// tableFunctions returns the function indices of the table, given the payload of the element section
+func tableFunctions(elements []byte) (map[uint32]uint32, error) {
+	r := bytes.NewReader(elements)
+	count, err := readVarUint32(r)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make(map[uint32]uint32)
+	for i := uint32(0); i < count; i++ {
+		flags, err := readVarUint32(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if flags != 0 {
+			return nil, fmt.Errorf("%w: element segment with flags %d", errUnsupportedElements, flags)
+		}
+
+		// the offset must be an i32.const expression
+		op, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		if op != 0x41 {
+			return nil, fmt.Errorf("%w: offset expression with opcode 0x%x", errUnsupportedElements, op)
+		}
+
+		offset, err := readVarUint32(r)
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		if end != 0x0b {
+			return nil, fmt.Errorf("%w: offset expression is not constant", errUnsupportedElements)
+		}
+
+		size, err := readVarUint32(r)
+		if err != nil {
+			return nil, err
+		}
+
+		for j := uint32(0); j < size; j++ {
+			function, err := readVarUint32(r)
+			if err != nil {
+				return nil, err
+			}
+
+			table[offset+j] = function
+		}
+	}
+
+	return table, nil
+}
+
+func appendSection(out []byte, id byte, payload []byte) []byte {
+	out = append(out, id)
+	out = append(out, encodeVarUint32(uint32(len(payload)))...)
+	return append(out, payload...)
+}
+
+func readVarUint32(r *bytes.Reader) (uint32, error) {
+	var value uint32
+	for shift := uint(0); shift < 35; shift += 7 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		value |= uint32(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+
+	return 0, errors.New("invalid LEB128 integer")
+}
+
+func encodeVarUint32(value uint32) []byte {
+	var enc []byte
+	for {
+		b := byte(value & 0x7f)
+		value >>= 7
+		if value == 0 {
+			return append(enc, b)
+		}
+		enc = append(enc, b|0x80)
+	}
+}
+
+// newSandbox returns a new sandbox for the runtime instance
+func newSandbox(in *Instance) *sandbox.Sandbox {
+	return sandbox.New(&supervisor{instance: in})
+}
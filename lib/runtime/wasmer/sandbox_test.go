@@ -0,0 +1,67 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package wasmer
+
+import (
+	"testing"
+
+	wasm "github.com/wasmerio/go-ext-wasm/wasmer"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSection(id byte, payload ...byte) []byte {
+	return appendSection(nil, id, payload)
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+func Test_exportTableFunctions(t *testing.T) {
+	header := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	// () -> i32
+	types := testSection(1, 1, 0x60, 0, 1, 0x7f)
+	functions := testSection(3, 2, 0, 0)
+	// funcref table with 3 elements
+	table := testSection(4, 1, 0x70, 0, 3)
+	exports := testSection(7, 1, 1, 'f', 0, 0)
+	// table[1] = function 1, table[2] = function 0
+	elements := testSection(9, 1, 0, 0x41, 1, 0x0b, 2, 1, 0)
+	// i32.const 7 and i32.const 9
+	code := testSection(10, 2, 4, 0, 0x41, 7, 0x0b, 4, 0, 0x41, 9, 0x0b)
+
+	modules := map[string][]byte{
+		"with exports":    concat(header, types, functions, table, exports, elements, code),
+		"without exports": concat(header, types, functions, table, elements, code),
+	}
+
+	for name, module := range modules {
+		module := module
+		t.Run(name, func(t *testing.T) {
+			instance, err := wasm.NewInstance(exportTableFunctions(module))
+			require.NoError(t, err)
+			defer instance.Close()
+
+			res, err := instance.Exports[tableFunctionPrefix+"1"]()
+			require.NoError(t, err)
+			require.Equal(t, int32(9), res.ToI32())
+
+			res, err = instance.Exports[tableFunctionPrefix+"2"]()
+			require.NoError(t, err)
+			require.Equal(t, int32(7), res.ToI32())
+
+			_, ok := instance.Exports[tableFunctionPrefix+"0"]
+			require.False(t, ok)
+		})
+	}
+
+	// the code without elements is unchanged
+	module := concat(header, types, functions, code)
+	require.Equal(t, module, exportTableFunctions(module))
+}
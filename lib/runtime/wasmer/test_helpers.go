@@ -68,6 +68,6 @@ func setupConfig(t *testing.T, targetRuntime string, tt *trie.Trie, lvl log.Leve
 // NewTransactionStateMock create and return an runtime Transaction State interface mock
 func newTransactionStateMock() *mocks.TransactionState {
 	m := new(mocks.TransactionState)
-	m.On("AddToPool", mock.AnythingOfType("*transaction.ValidTransaction")).Return(common.BytesToHash([]byte("test")))
+	m.On("AddToPool", mock.AnythingOfType("*transaction.ValidTransaction")).Return(common.BytesToHash([]byte("test")), nil)
 	return m
 }
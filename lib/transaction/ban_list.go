@@ -0,0 +1,76 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package transaction
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// DefaultBanDuration is the default duration a transaction stays banned for
+const DefaultBanDuration = time.Minute * 30
+
+// ErrTransactionBanned is returned when importing a transaction which is temporarily banned
+var ErrTransactionBanned = errors.New("transaction is temporarily banned")
+
+// BanList is a list of temporarily banned transaction hashes
+type BanList struct {
+	duration time.Duration
+	// banned maps the hash of a banned transaction to the time its ban expires
+	banned map[common.Hash]time.Time
+	mu     sync.Mutex
+}
+
+// NewBanList returns a new empty BanList banning transactions for the given duration
+func NewBanList(duration time.Duration) *BanList {
+	return &BanList{
+		duration: duration,
+		banned:   make(map[common.Hash]time.Time),
+	}
+}
+
+// Ban bans the transaction with the given hash until the ban duration has elapsed
+func (b *BanList) Ban(hash common.Hash) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for h, expiry := range b.banned {
+		if !now.Before(expiry) {
+			delete(b.banned, h)
+		}
+	}
+
+	b.banned[hash] = now.Add(b.duration)
+}
+
+// IsBanned returns true if the transaction with the given hash is currently banned, false otherwise
+func (b *BanList) IsBanned(hash common.Hash) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	expiry, ok := b.banned[hash]
+	if !ok {
+		return false
+	}
+
+	if !time.Now().Before(expiry) {
+		delete(b.banned, hash)
+		return false
+	}
+
+	return true
+}
+
+// Len returns the number of banned transactions, including the ones whose ban has expired
+// but which have not been removed from the list yet
+func (b *BanList) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.banned)
+}
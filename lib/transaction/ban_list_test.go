@@ -0,0 +1,34 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBanList(t *testing.T) {
+	b := NewBanList(time.Millisecond * 50)
+
+	hashA := common.Hash{1}
+	hashB := common.Hash{2}
+
+	b.Ban(hashA)
+	require.True(t, b.IsBanned(hashA))
+	require.False(t, b.IsBanned(hashB))
+
+	time.Sleep(time.Millisecond * 100)
+	require.False(t, b.IsBanned(hashA))
+	require.Equal(t, 0, b.Len())
+
+	// expired bans are removed when banning another transaction
+	b.Ban(hashA)
+	time.Sleep(time.Millisecond * 100)
+	b.Ban(hashB)
+	require.Equal(t, 1, b.Len())
+	require.True(t, b.IsBanned(hashB))
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package transaction
+
+import "errors"
+
+const (
+	// DefaultMaxCount is the default maximum number of transactions in the pool and queue
+	DefaultMaxCount = 8192
+	// DefaultMaxBytes is the default maximum total size of the transactions in the pool and queue
+	DefaultMaxBytes = 20 * 1024 * 1024
+	// DefaultMaxPerSender is the default maximum number of transactions of a single sender
+	// in the pool and queue
+	DefaultMaxPerSender = 64
+)
+
+var (
+	// ErrPoolFull is returned when the pool and queue are full, and the transaction does not have
+	// a higher priority than the transactions which would have to be evicted to make room for it
+	ErrPoolFull = errors.New("transaction pool is full")
+
+	// ErrSenderLimitReached is returned when the sender of the transaction already has
+	// the maximum number of transactions in the pool and queue
+	ErrSenderLimitReached = errors.New("too many transactions from the same sender")
+)
+
+// Limits are the limits of the transactions held in the pool and queue
+type Limits struct {
+	// MaxCount is the maximum number of transactions
+	MaxCount int
+	// MaxBytes is the maximum total size of the transactions
+	MaxBytes int
+	// MaxPerSender is the maximum number of transactions of a single sender
+	MaxPerSender int
+}
+
+// WithDefaults returns the limits, replacing the zero ones with their default value
+func (l Limits) WithDefaults() Limits {
+	if l.MaxCount == 0 {
+		l.MaxCount = DefaultMaxCount
+	}
+
+	if l.MaxBytes == 0 {
+		l.MaxBytes = DefaultMaxBytes
+	}
+
+	if l.MaxPerSender == 0 {
+		l.MaxPerSender = DefaultMaxPerSender
+	}
+
+	return l
+}
@@ -17,16 +17,19 @@
 	Help:      "total number of transactions in ready pool",
 })
 
-// Pool represents the transaction pool
+// Pool represents the transaction pool, holding the transactions which are not ready yet
 type Pool struct {
 	transactions map[common.Hash]*ValidTransaction
-	mu           sync.RWMutex
+	// requiredBy maps the tags required by the transactions of the pool to their hashes
+	requiredBy map[string]map[common.Hash]struct{}
+	mu         sync.RWMutex
 }
 
 // NewPool returns a new empty Pool
 func NewPool() *Pool {
 	return &Pool{
 		transactions: make(map[common.Hash]*ValidTransaction),
+		requiredBy:   make(map[string]map[common.Hash]struct{}),
 	}
 }
 
@@ -53,12 +56,38 @@ func (p *Pool) Transactions() []*ValidTransaction {
 	return txs
 }
 
-// Insert inserts a transaction into the pool
+// RequiredBy returns the transactions of the pool requiring the given tag
+func (p *Pool) RequiredBy(tag []byte) []*ValidTransaction {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	hashes := p.requiredBy[string(tag)]
+	txs := make([]*ValidTransaction, 0, len(hashes))
+	for hash := range hashes {
+		txs = append(txs, p.transactions[hash])
+	}
+	return txs
+}
+
+// Insert inserts a transaction into the pool, replacing it if it is already in the pool
 func (p *Pool) Insert(tx *ValidTransaction) common.Hash {
 	hash := tx.Extrinsic.Hash()
 	p.mu.Lock()
 	defer p.mu.Unlock()
+
+	p.remove(hash)
 	p.transactions[hash] = tx
+	if tx.Validity != nil {
+		for _, tag := range tx.Validity.Requires {
+			hashes, ok := p.requiredBy[string(tag)]
+			if !ok {
+				hashes = make(map[common.Hash]struct{})
+				p.requiredBy[string(tag)] = hashes
+			}
+			hashes[hash] = struct{}{}
+		}
+	}
+
 	transactionPoolGauge.Set(float64(len(p.transactions)))
 	return hash
 }
@@ -67,10 +96,30 @@ func (p *Pool) Insert(tx *ValidTransaction) common.Hash {
 func (p *Pool) Remove(hash common.Hash) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	delete(p.transactions, hash)
+	p.remove(hash)
 	transactionPoolGauge.Set(float64(len(p.transactions)))
 }
 
+func (p *Pool) remove(hash common.Hash) {
+	tx, ok := p.transactions[hash]
+	if !ok {
+		return
+	}
+
+	delete(p.transactions, hash)
+	if tx.Validity == nil {
+		return
+	}
+
+	for _, tag := range tx.Validity.Requires {
+		hashes := p.requiredBy[string(tag)]
+		delete(hashes, hash)
+		if len(hashes) == 0 {
+			delete(p.requiredBy, string(tag))
+		}
+	}
+}
+
 // Len return the current length of the pool
 func (p *Pool) Len() int {
 	p.mu.Lock()
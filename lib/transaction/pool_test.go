@@ -53,3 +53,18 @@ func TestPool(t *testing.T) {
 	}
 	require.Equal(t, 0, len(p.Transactions()))
 }
+
+func TestPool_RequiredBy(t *testing.T) {
+	tx := &ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &Validity{Requires: [][]byte{[]byte("tag")}},
+	}
+
+	p := NewPool()
+	hash := p.Insert(tx)
+	require.Equal(t, []*ValidTransaction{tx}, p.RequiredBy([]byte("tag")))
+	require.Empty(t, p.RequiredBy([]byte("other")))
+
+	p.Remove(hash)
+	require.Empty(t, p.RequiredBy([]byte("tag")))
+}
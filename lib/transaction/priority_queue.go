@@ -4,8 +4,10 @@
 package transaction
 
 import (
+	"bytes"
 	"container/heap"
 	"errors"
+	"sort"
 	"sync"
 
 	"github.com/ChainSafe/gossamer/dot/types"
@@ -35,7 +37,13 @@ type Item struct {
 	order uint64
 
 	// The index is needed by update and is maintained by the heap.Interface methods.
-	index int // The index of the item in the heap.
+	index int // The index of the item in the heap, -1 if the item is waiting for its dependencies.
+
+	// waiting is the number of tags required by the item which are provided by items of the queue
+	// not yet popped, the item can only be popped once it is zero.
+	waiting int
+	// unlocks are the items of the queue requiring the tags provided by the item.
+	unlocks []*Item
 }
 
 // A PriorityQueue implements heap.Interface and holds Items.
@@ -75,42 +83,223 @@ func (pq *priorityQueue) Pop() interface{} {
 	return item
 }
 
-// PriorityQueue is a thread safe wrapper over `priorityQueue`
+// PriorityQueue is a thread safe wrapper over `priorityQueue`.
+// It is the queue of ready transactions: a transaction requiring tags provided by other transactions
+// of the queue is only popped after them, the tags which are not provided by any transaction
+// of the queue are considered to be satisfied.
 type PriorityQueue struct {
 	pq        priorityQueue
 	currOrder uint64
 	txs       map[common.Hash]*Item
+	// provided maps the tags provided by the transactions of the queue to the transaction providing them
+	provided map[string]*Item
 	sync.Mutex
 }
 
 // NewPriorityQueue creates new instance of PriorityQueue
 func NewPriorityQueue() *PriorityQueue {
 	spq := &PriorityQueue{
-		pq:  make(priorityQueue, 0),
-		txs: make(map[common.Hash]*Item),
+		pq:       make(priorityQueue, 0),
+		txs:      make(map[common.Hash]*Item),
+		provided: make(map[string]*Item),
 	}
 
 	heap.Init(&spq.pq)
 	return spq
 }
 
-// RemoveExtrinsic removes an extrinsic from the queue
+// RemoveExtrinsic removes an extrinsic included in a block from the queue.
+// The transactions waiting for the tags it provides are unlocked.
 func (spq *PriorityQueue) RemoveExtrinsic(ext types.Extrinsic) {
 	spq.Lock()
 	defer spq.Unlock()
 
-	hash := ext.Hash()
-	item, ok := spq.txs[hash]
+	item, ok := spq.txs[ext.Hash()]
 	if !ok {
 		return
 	}
 
-	heap.Remove(&spq.pq, item.index)
-	delete(spq.txs, hash)
+	spq.unlink(item)
+	spq.release(item)
+	transactionQueueGauge.Set(float64(len(spq.txs)))
+}
+
+// Drop removes an extrinsic which is not included in a block from the queue, so the tags it provides
+// are not satisfied anymore. The transactions waiting for these tags keep waiting for the other
+// transactions of the queue providing them, if any. Otherwise they are removed from the queue along
+// with the transactions depending on them, and returned so they can wait for the tags again.
+func (spq *PriorityQueue) Drop(ext types.Extrinsic) (orphans []*ValidTransaction) {
+	spq.Lock()
+	defer spq.Unlock()
+
+	item, ok := spq.txs[ext.Hash()]
+	if !ok {
+		return nil
+	}
+
+	orphans = spq.drop(item)
+	transactionQueueGauge.Set(float64(len(spq.txs)))
+	return orphans
+}
+
+func (spq *PriorityQueue) drop(item *Item) (orphans []*ValidTransaction) {
+	spq.unlink(item)
+
+	dependents := item.unlocks
+	spq.release(item)
+
+	seen := make(map[*Item]struct{}, len(dependents))
+	for _, dependent := range dependents {
+		if _, ok := seen[dependent]; ok {
+			continue
+		}
+		seen[dependent] = struct{}{}
+
+		if _, ok := spq.txs[dependent.hash]; !ok {
+			// already dropped as the dependent of another transaction
+			continue
+		}
+
+		// release unlocked the dependent for each of the tags it requires from the item
+		if dependent.index >= 0 {
+			heap.Remove(&spq.pq, dependent.index)
+		}
+
+		orphaned := false
+		for _, tag := range dependent.data.Validity.Requires {
+			if !providesTag(item.data.Validity, tag) {
+				continue
+			}
+
+			provider, ok := spq.provided[string(tag)]
+			if !ok {
+				orphaned = true
+				break
+			}
+			provider.unlocks = append(provider.unlocks, dependent)
+			dependent.waiting++
+		}
+
+		if orphaned {
+			orphans = append(orphans, dependent.data)
+			orphans = append(orphans, spq.drop(dependent)...)
+			continue
+		}
+
+		if dependent.waiting == 0 {
+			heap.Push(&spq.pq, dependent)
+		}
+	}
+
+	return orphans
+}
+
+// unlink removes the item from the heap and from the items unlocked by the transactions providing
+// the tags it requires
+func (spq *PriorityQueue) unlink(item *Item) {
+	if item.index >= 0 {
+		heap.Remove(&spq.pq, item.index)
+	}
+
+	for _, tag := range item.data.Validity.Requires {
+		provider, ok := spq.provided[string(tag)]
+		if !ok {
+			continue
+		}
+
+		provider.unlocks = removeItem(provider.unlocks, item)
+	}
+}
+
+// release removes the item from the transactions of the queue and unlocks the items waiting for it.
+func (spq *PriorityQueue) release(item *Item) {
+	delete(spq.txs, item.hash)
+
+	for _, tag := range item.data.Validity.Provides {
+		if spq.provided[string(tag)] == item {
+			delete(spq.provided, string(tag))
+		}
+	}
+
+	for _, unlocked := range item.unlocks {
+		unlocked.waiting--
+		if unlocked.waiting == 0 {
+			heap.Push(&spq.pq, unlocked)
+		}
+	}
+	item.unlocks = nil
+}
+
+func providesTag(validity *Validity, tag []byte) bool {
+	for _, provided := range validity.Provides {
+		if bytes.Equal(provided, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func removeItem(items []*Item, item *Item) []*Item {
+	for i, it := range items {
+		if it == item {
+			return append(items[:i], items[i+1:]...)
+		}
+	}
+	return items
+}
+
+// IsProvided returns true if the given tag is provided by a transaction of the queue, false otherwise
+func (spq *PriorityQueue) IsProvided(tag []byte) bool {
+	spq.Lock()
+	defer spq.Unlock()
+
+	_, ok := spq.provided[string(tag)]
+	return ok
+}
+
+// Providers returns the hashes of the transactions of the queue providing the given tags,
+// along with the ones providing the tags these transactions require, recursively.
+func (spq *PriorityQueue) Providers(tags [][]byte) (hashes []common.Hash) {
+	spq.Lock()
+	defer spq.Unlock()
+
+	seen := make(map[common.Hash]struct{})
+	for len(tags) > 0 {
+		provider, ok := spq.provided[string(tags[0])]
+		tags = tags[1:]
+		if !ok {
+			continue
+		}
+
+		if _, ok := seen[provider.hash]; ok {
+			continue
+		}
+		seen[provider.hash] = struct{}{}
+		hashes = append(hashes, provider.hash)
+		tags = append(tags, provider.data.Validity.Requires...)
+	}
+
+	return hashes
+}
+
+// Get returns the transaction with the given hash, or nil if it is not in the queue
+func (spq *PriorityQueue) Get(extHash common.Hash) *ValidTransaction {
+	spq.Lock()
+	defer spq.Unlock()
+
+	item, ok := spq.txs[extHash]
+	if !ok {
+		return nil
+	}
+
+	return item.data
 }
 
 // Exists returns true if a hash is in the txs map, false otherwise
 func (spq *PriorityQueue) Exists(extHash common.Hash) bool {
+	spq.Lock()
+	defer spq.Unlock()
+
 	_, ok := spq.txs[extHash]
 	return ok
 }
@@ -130,17 +319,36 @@ func (spq *PriorityQueue) Push(txn *ValidTransaction) (common.Hash, error) {
 		hash:     hash,
 		order:    spq.currOrder,
 		priority: txn.Validity.Priority,
+		index:    -1,
 	}
 	spq.currOrder++
-	heap.Push(&spq.pq, item)
+
+	for _, tag := range txn.Validity.Requires {
+		provider, ok := spq.provided[string(tag)]
+		if !ok {
+			continue
+		}
+		provider.unlocks = append(provider.unlocks, item)
+		item.waiting++
+	}
+
+	for _, tag := range txn.Validity.Provides {
+		spq.provided[string(tag)] = item
+	}
+
+	if item.waiting == 0 {
+		heap.Push(&spq.pq, item)
+	}
 	spq.txs[hash] = item
 
-	transactionQueueGauge.Set(float64(spq.pq.Len()))
+	transactionQueueGauge.Set(float64(len(spq.txs)))
 	return hash, nil
 }
 
 // Pop removes the transaction with has the highest priority value from the queue and returns it.
 // If there are multiple transaction with same priority value then it return them in FIFO order.
+// Transactions waiting for the tags provided by other transactions of the queue are not popped,
+// until these transactions are popped.
 func (spq *PriorityQueue) Pop() *ValidTransaction {
 	spq.Lock()
 	defer spq.Unlock()
@@ -149,9 +357,9 @@ func (spq *PriorityQueue) Pop() *ValidTransaction {
 	}
 
 	item := heap.Pop(&spq.pq).(*Item)
-	delete(spq.txs, item.hash)
+	spq.release(item)
 
-	transactionQueueGauge.Set(float64(spq.pq.Len()))
+	transactionQueueGauge.Set(float64(len(spq.txs)))
 	return item.data
 }
 
@@ -165,7 +373,8 @@ func (spq *PriorityQueue) Peek() *ValidTransaction {
 	return spq.pq[0].data
 }
 
-// Pending returns all the transactions currently in the queue
+// Pending returns all the transactions currently in the queue,
+// the ones waiting for their dependencies coming last in insertion order.
 func (spq *PriorityQueue) Pending() []*ValidTransaction {
 	spq.Lock()
 	defer spq.Unlock()
@@ -174,6 +383,20 @@ func (spq *PriorityQueue) Pending() []*ValidTransaction {
 	for idx := 0; idx < spq.pq.Len(); idx++ {
 		txns = append(txns, spq.pq[idx].data)
 	}
+
+	var waiting []*Item
+	for _, item := range spq.txs {
+		if item.index < 0 {
+			waiting = append(waiting, item)
+		}
+	}
+	sort.Slice(waiting, func(i, j int) bool {
+		return waiting[i].order < waiting[j].order
+	})
+	for _, item := range waiting {
+		txns = append(txns, item.data)
+	}
+
 	return txns
 }
 
@@ -182,5 +405,5 @@ func (spq *PriorityQueue) Len() int {
 	spq.Lock()
 	defer spq.Unlock()
 
-	return spq.pq.Len()
+	return len(spq.txs)
 }
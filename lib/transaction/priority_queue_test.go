@@ -6,6 +6,9 @@
 import (
 	"reflect"
 	"testing"
+
+	"github.com/ChainSafe/gossamer/lib/common"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPriorityQueue(t *testing.T) {
@@ -246,3 +249,154 @@ func TestRemoveExtrinsic(t *testing.T) {
 		t.Fatalf("Fail: got %v expected %v", res, tests[1])
 	}
 }
+
+func TestPriorityQueue_Get(t *testing.T) {
+	tx := &ValidTransaction{
+		Extrinsic: []byte("rats"),
+		Validity:  &Validity{Priority: 5},
+	}
+
+	pq := NewPriorityQueue()
+	hash, err := pq.Push(tx)
+	require.NoError(t, err)
+
+	require.Equal(t, tx, pq.Get(hash))
+	require.Nil(t, pq.Get(common.Hash{}))
+}
+
+func TestPriorityQueue_Dependencies(t *testing.T) {
+	provider := &ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{[]byte("tag")}},
+	}
+	dependent := &ValidTransaction{
+		Extrinsic: []byte("b"),
+		Validity:  &Validity{Priority: 10, Requires: [][]byte{[]byte("tag")}},
+	}
+	other := &ValidTransaction{
+		Extrinsic: []byte("c"),
+		Validity:  &Validity{Priority: 5},
+	}
+
+	pq := NewPriorityQueue()
+	for _, tx := range []*ValidTransaction{provider, dependent, other} {
+		_, err := pq.Push(tx)
+		require.NoError(t, err)
+	}
+
+	require.True(t, pq.IsProvided([]byte("tag")))
+	require.Equal(t, 3, pq.Len())
+	require.Equal(t, dependent, pq.Pending()[2])
+
+	// the dependent transaction is only popped after the transaction providing the tag it requires
+	require.Equal(t, other, pq.Pop())
+	require.Equal(t, provider, pq.Pop())
+	require.False(t, pq.IsProvided([]byte("tag")))
+	require.Equal(t, dependent, pq.Pop())
+	require.Nil(t, pq.Pop())
+}
+
+func TestPriorityQueue_RemoveExtrinsic_Dependencies(t *testing.T) {
+	provider := &ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{[]byte("tag")}},
+	}
+	dependent := &ValidTransaction{
+		Extrinsic: []byte("b"),
+		Validity:  &Validity{Priority: 10, Requires: [][]byte{[]byte("tag")}},
+	}
+
+	pq := NewPriorityQueue()
+	for _, tx := range []*ValidTransaction{provider, dependent} {
+		_, err := pq.Push(tx)
+		require.NoError(t, err)
+	}
+
+	// removing the provider, when it is included in a block, unlocks the dependent transaction
+	pq.RemoveExtrinsic(provider.Extrinsic)
+	require.Equal(t, dependent, pq.Peek())
+	require.Equal(t, dependent, pq.Pop())
+	require.Equal(t, 0, pq.Len())
+}
+
+func TestPriorityQueue_Drop_Dependencies(t *testing.T) {
+	provider := &ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{[]byte("tag")}},
+	}
+	dependent := &ValidTransaction{
+		Extrinsic: []byte("b"),
+		Validity:  &Validity{Priority: 10, Provides: [][]byte{[]byte("other")}, Requires: [][]byte{[]byte("tag")}},
+	}
+	indirect := &ValidTransaction{
+		Extrinsic: []byte("c"),
+		Validity:  &Validity{Priority: 10, Requires: [][]byte{[]byte("other")}},
+	}
+
+	pq := NewPriorityQueue()
+	for _, tx := range []*ValidTransaction{provider, dependent, indirect} {
+		_, err := pq.Push(tx)
+		require.NoError(t, err)
+	}
+
+	// dropping the provider, when it is not included in a block, removes the transactions depending on it
+	orphans := pq.Drop(provider.Extrinsic)
+	require.Equal(t, []*ValidTransaction{dependent, indirect}, orphans)
+	require.Nil(t, pq.Pop())
+	require.Equal(t, 0, pq.Len())
+	require.False(t, pq.IsProvided([]byte("other")))
+	require.Nil(t, pq.Drop(provider.Extrinsic))
+}
+
+func TestPriorityQueue_Drop_OtherProvider(t *testing.T) {
+	provider := &ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{[]byte("tag")}},
+	}
+	dependent := &ValidTransaction{
+		Extrinsic: []byte("b"),
+		Validity:  &Validity{Priority: 10, Requires: [][]byte{[]byte("tag")}},
+	}
+	usurper := &ValidTransaction{
+		Extrinsic: []byte("c"),
+		Validity:  &Validity{Priority: 2, Provides: [][]byte{[]byte("tag")}},
+	}
+
+	pq := NewPriorityQueue()
+	for _, tx := range []*ValidTransaction{provider, dependent, usurper} {
+		_, err := pq.Push(tx)
+		require.NoError(t, err)
+	}
+
+	// the dependent transaction waits for the other transaction providing the tag
+	require.Empty(t, pq.Drop(provider.Extrinsic))
+	require.Equal(t, 2, pq.Len())
+	require.Equal(t, usurper, pq.Pop())
+	require.Equal(t, dependent, pq.Pop())
+	require.Nil(t, pq.Pop())
+}
+
+func TestPriorityQueue_Providers(t *testing.T) {
+	provider := &ValidTransaction{
+		Extrinsic: []byte("a"),
+		Validity:  &Validity{Priority: 1, Provides: [][]byte{[]byte("tag")}},
+	}
+	dependent := &ValidTransaction{
+		Extrinsic: []byte("b"),
+		Validity:  &Validity{Priority: 10, Provides: [][]byte{[]byte("other")}, Requires: [][]byte{[]byte("tag")}},
+	}
+	unrelated := &ValidTransaction{
+		Extrinsic: []byte("c"),
+		Validity:  &Validity{Priority: 10, Provides: [][]byte{[]byte("unrelated")}},
+	}
+
+	pq := NewPriorityQueue()
+	for _, tx := range []*ValidTransaction{provider, dependent, unrelated} {
+		_, err := pq.Push(tx)
+		require.NoError(t, err)
+	}
+
+	providers := pq.Providers([][]byte{[]byte("other"), []byte("tag"), []byte("missing")})
+	require.Equal(t, []common.Hash{dependent.Extrinsic.Hash(), provider.Extrinsic.Hash()}, providers)
+	require.Empty(t, pq.Providers([][]byte{[]byte("missing")}))
+}
@@ -5,6 +5,7 @@
 
 import (
 	"github.com/ChainSafe/gossamer/dot/types"
+	"github.com/ChainSafe/gossamer/lib/common"
 )
 
 // Validity struct see
@@ -42,13 +43,17 @@ func NewValidTransaction(extrinsic types.Extrinsic, validity *Validity) *ValidTr
 	}
 }
 
-// // StatusNotification represents information about a transaction status update.
-// type StatusNotification struct {
-// 	Ext                types.Extrinsic
-// 	Status             string
-// 	PeersBroadcastedTo []string
-// 	BlockHash          *common.Hash
-// }
+// StatusNotification represents information about a transaction status update.
+type StatusNotification struct {
+	Status Status
+	// Peers is the list of peers the transaction has been broadcast to,
+	// it is only set for the Broadcast status.
+	Peers []string
+	// Hash is the hash of the block for the InBlock, Retracted, FinalityTimeout
+	// and Finalized statuses, and the hash of the replacing transaction for the
+	// Usurped status.
+	Hash *common.Hash
+}
 
 // Status represents possible transaction statuses.
 //
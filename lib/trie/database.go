@@ -16,8 +16,9 @@
 )
 
 var (
-	ErrEmptyProof = errors.New("proof slice empty")
-	ErrDecodeNode = errors.New("cannot decode node")
+	ErrEmptyProof       = errors.New("proof slice empty")
+	ErrDecodeNode       = errors.New("cannot decode node")
+	ErrRootNodeNotFound = errors.New("root node not found in proof")
 )
 
 // Store stores each trie node in the database,
@@ -111,6 +112,10 @@ func (t *Trie) loadFromProof(rawProof [][]byte, rootHash []byte) error {
 		}
 	}
 
+	if t.root == nil {
+		return fmt.Errorf("%w: 0x%x", ErrRootNodeNotFound, rootHash)
+	}
+
 	t.loadProof(proofHashToNode, t.root)
 
 	return nil
@@ -49,5 +49,15 @@ func find(parent Node, key []byte, recorder recorder) error {
 		return nil
 	}
 
-	return find(b.Children[key[length]], key[length+1:], recorder)
+	// the key diverges from the branch key, so it is not in the trie
+	if length < len(b.Key) {
+		return nil
+	}
+
+	child := b.Children[key[length]]
+	if child == nil {
+		return nil
+	}
+
+	return find(child, key[length+1:], recorder)
 }
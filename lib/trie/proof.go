@@ -11,6 +11,7 @@
 
 	"github.com/ChainSafe/chaindb"
 	"github.com/ChainSafe/gossamer/internal/trie/codec"
+	"github.com/ChainSafe/gossamer/internal/trie/node"
 	"github.com/ChainSafe/gossamer/internal/trie/record"
 	"github.com/ChainSafe/gossamer/lib/common"
 )
@@ -30,22 +31,30 @@
 
 	// ErrLoadFromProof ...
 	ErrLoadFromProof = errors.New("failed to build the proof trie")
+
+	// ErrIncompleteProof is returned when a node needed to look up a key is missing from a proof
+	ErrIncompleteProof = errors.New("proof does not contain the nodes of the key")
 )
 
 // GenerateProof receive the keys to proof, the trie root and a reference to database
 func GenerateProof(root []byte, keys [][]byte, db chaindb.Database) ([][]byte, error) {
-	trackedProofs := make(map[string][]byte)
-
 	proofTrie := NewEmptyTrie()
 	if err := proofTrie.Load(db, common.BytesToHash(root)); err != nil {
 		return nil, err
 	}
 
+	return proofTrie.GenerateProof(keys)
+}
+
+// GenerateProof returns the proof of the keys in the trie, whose nodes must all be loaded in memory
+func (t *Trie) GenerateProof(keys [][]byte) ([][]byte, error) {
+	trackedProofs := make(map[string][]byte)
+
 	for _, k := range keys {
 		nk := codec.KeyLEToNibbles(k)
 
 		recorder := record.NewRecorder()
-		err := findAndRecord(proofTrie, nk, recorder)
+		err := findAndRecord(t, nk, recorder)
 		if err != nil {
 			return nil, err
 		}
@@ -83,9 +92,9 @@ func VerifyProof(proof [][]byte, root []byte, items []Pair) (bool, error) {
 		set[hexKey] = struct{}{}
 	}
 
-	proofTrie := NewEmptyTrie()
-	if err := proofTrie.loadFromProof(proof, root); err != nil {
-		return false, fmt.Errorf("%w: %s", ErrLoadFromProof, err)
+	proofTrie, err := LoadFromProof(proof, root)
+	if err != nil {
+		return false, err
 	}
 
 	for _, item := range items {
@@ -101,3 +110,96 @@ func VerifyProof(proof [][]byte, root []byte, items []Pair) (bool, error) {
 
 	return true, nil
 }
+
+// LoadFromProof returns the partial trie with the given root built from the proof nodes.
+// Values of keys which are not covered by the proof cannot be retrieved from the returned trie.
+func LoadFromProof(proof [][]byte, root []byte) (*Trie, error) {
+	proofTrie := NewEmptyTrie()
+	if err := proofTrie.loadFromProof(proof, root); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrLoadFromProof, err)
+	}
+
+	return proofTrie, nil
+}
+
+// GetFromProof returns the value of the key in a trie loaded from a proof, or nil if the proof shows
+// that the key is absent. ErrIncompleteProof is returned if a node on the path of the key is missing
+// from the proof, in which case the key cannot be told apart from an absent key using Get.
+func (t *Trie) GetFromProof(keyLE []byte) (value []byte, err error) {
+	key := codec.KeyLEToNibbles(keyLE)
+	n := t.root
+	for {
+		n, err = resolveProofNode(n)
+		if err != nil {
+			return nil, fmt.Errorf("key 0x%x: %w", keyLE, err)
+		}
+
+		if n == nil {
+			return nil, nil
+		}
+
+		if n.Type() == node.LeafType {
+			if bytes.Equal(n.GetKey(), key) {
+				return n.GetValue(), nil
+			}
+			return nil, nil
+		}
+
+		branch := n.(*node.Branch)
+		if bytes.Equal(branch.Key, key) {
+			return branch.Value, nil
+		}
+
+		if len(key) <= len(branch.Key) || !bytes.HasPrefix(key, branch.Key) {
+			return nil, nil
+		}
+
+		n = branch.Children[key[len(branch.Key)]]
+		key = key[len(branch.Key)+1:]
+	}
+}
+
+// NextKeyFromProof returns the key following the given key in a trie loaded from a proof, or nil if
+// the proof shows that no key follows it. ErrIncompleteProof is returned if a node which may contain
+// the next key is missing from the proof.
+func (t *Trie) NextKeyFromProof(keyLE []byte) (next []byte, err error) {
+	if t.root == nil {
+		return nil, nil
+	}
+
+	it := &rangeIterator{
+		start:   codec.KeyLEToNibbles(keyLE),
+		resolve: resolveProofNode,
+		onPair: func(key, _ []byte) bool {
+			next = key
+			return true
+		},
+	}
+
+	_, err = it.walk(t.root, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("next key of 0x%x: %w", keyLE, err)
+	}
+
+	return next, nil
+}
+
+// resolveProofNode returns the node of a trie loaded from a proof, decoding it if it is inlined
+// in its parent. ErrIncompleteProof is returned if only the hash of the node is known.
+func resolveProofNode(n Node) (Node, error) {
+	leaf, ok := n.(*node.Leaf)
+	if !ok || leaf.Key != nil || leaf.Value != nil || leaf.Encoding != nil || leaf.HashDigest == nil {
+		return n, nil
+	}
+
+	// nodes whose encoding is shorter than a hash are inlined in their parent
+	if len(leaf.HashDigest) < common.HashLength {
+		decoded, err := node.Decode(bytes.NewReader(leaf.HashDigest))
+		if err != nil {
+			return nil, fmt.Errorf("%w: 0x%x", ErrDecodeNode, leaf.HashDigest)
+		}
+		return decoded, nil
+	}
+
+	return nil, fmt.Errorf("%w: missing node with hash 0x%x", ErrIncompleteProof, leaf.HashDigest)
+}
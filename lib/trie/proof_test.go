@@ -53,6 +53,37 @@ func TestProofGeneration(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestProofGeneration_AbsentKeys(t *testing.T) {
+	t.Parallel()
+
+	memdb, err := chaindb.NewBadgerDB(&chaindb.Config{
+		InMemory: true,
+		DataDir:  t.TempDir(),
+	})
+	require.NoError(t, err)
+
+	trie := NewEmptyTrie()
+	trie.Put([]byte("cat"), []byte("meow"))
+	trie.Put([]byte("catapulta"), []byte("launch"))
+	trie.Put([]byte("dog"), []byte("woof"))
+
+	err = trie.Store(memdb)
+	require.NoError(t, err)
+
+	hash, err := trie.Hash()
+	require.NoError(t, err)
+
+	// keys diverging from a branch key or ending at an empty child slot
+	// must not make the proof generation fail
+	keys := [][]byte{[]byte("bird"), []byte("cow"), []byte("catz"), []byte("dogs")}
+	proof, err := GenerateProof(hash.ToBytes(), keys, memdb)
+	require.NoError(t, err)
+	require.NotEmpty(t, proof)
+
+	_, err = VerifyProof(proof, hash.ToBytes(), []Pair{{Key: []byte("cow")}})
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
 func testGenerateProof(t *testing.T, entries []Pair, keys [][]byte) ([]byte, [][]byte, []Pair) {
 	t.Helper()
 
@@ -225,3 +256,77 @@ func TestLeafNodes_SameHash_DifferentPaths_GenerateAndVerifyProof(t *testing.T)
 	require.NoError(t, err)
 	require.True(t, ok)
 }
+
+func TestLoadFromProof(t *testing.T) {
+	t.Parallel()
+
+	entries := []Pair{
+		{Key: []byte("alpha"), Value: make([]byte, 32)},
+		{Key: []byte("bravo"), Value: []byte("bravo")},
+		{Key: []byte("do"), Value: []byte("verb")},
+		{Key: []byte("dog"), Value: []byte("puppy")},
+		{Key: []byte("horse"), Value: []byte("stallion")},
+	}
+
+	keys := [][]byte{
+		[]byte("bravo"),
+		[]byte("dog"),
+	}
+
+	root, proof, _ := testGenerateProof(t, entries, keys)
+
+	proofTrie, err := LoadFromProof(proof, root)
+	require.NoError(t, err)
+	require.Equal(t, []byte("bravo"), proofTrie.Get([]byte("bravo")))
+	require.Equal(t, []byte("puppy"), proofTrie.Get([]byte("dog")))
+
+	_, err = LoadFromProof(proof, []byte{1, 2, 3})
+	require.ErrorIs(t, err, ErrLoadFromProof)
+}
+
+func TestGetFromProof(t *testing.T) {
+	t.Parallel()
+
+	const size = 32
+	generator := newGenerator()
+
+	trie := NewEmptyTrie()
+	trie.Put([]byte("cat"), generateRandBytes(t, size, generator))
+	trie.Put([]byte("catapulta"), generateRandBytes(t, size, generator))
+	trie.Put([]byte("catapora"), []byte("itch"))
+	trie.Put([]byte("dog"), generateRandBytes(t, size, generator))
+	trie.Put([]byte("doguinho"), generateRandBytes(t, size, generator))
+	root := trie.MustHash()
+
+	proof, err := trie.GenerateProof([][]byte{[]byte("catapora")})
+	require.NoError(t, err)
+
+	proofTrie, err := LoadFromProof(proof, root[:])
+	require.NoError(t, err)
+
+	value, err := proofTrie.GetFromProof([]byte("catapora"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("itch"), value)
+
+	// the proof shows that no key diverges from the path of the proven key here
+	value, err = proofTrie.GetFromProof([]byte("catapa"))
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	_, err = proofTrie.GetFromProof([]byte("dog"))
+	require.ErrorIs(t, err, ErrIncompleteProof)
+	require.Nil(t, proofTrie.Get([]byte("dog")))
+
+	_, err = proofTrie.NextKeyFromProof([]byte("catapora"))
+	require.ErrorIs(t, err, ErrIncompleteProof)
+
+	proof, err = trie.GenerateProof([][]byte{[]byte("catapora"), []byte("catapulta")})
+	require.NoError(t, err)
+
+	proofTrie, err = LoadFromProof(proof, root[:])
+	require.NoError(t, err)
+
+	next, err := proofTrie.NextKeyFromProof([]byte("catapora"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("catapulta"), next)
+}
@@ -0,0 +1,220 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ChainSafe/gossamer/internal/trie/codec"
+	"github.com/ChainSafe/gossamer/internal/trie/node"
+	"github.com/ChainSafe/gossamer/lib/common"
+)
+
+// ErrEmptyRangeProof is returned when a range proof doesn't prove any key following its start key
+var ErrEmptyRangeProof = errors.New("range proof does not contain any key")
+
+// rangeIterator walks the nodes of a trie in the order of their keys, skipping the nodes
+// which only contain keys lower than or equal to the start key.
+type rangeIterator struct {
+	// start is the start key in nibbles, it is excluded from the range
+	start []byte
+	// resolve returns the node for the child of a branch, or nil if it is unknown
+	resolve func(child Node) (Node, error)
+	// onPair is called for each key-value pair following the start key,
+	// and returns true if the iteration must stop
+	onPair func(key, value []byte) (stop bool)
+}
+
+// walk iterates over the node and its descendants, and returns true if the iteration
+// was stopped, either by onPair or because a node couldn't be resolved.
+// prefix is the full key of the node without its partial key, and after is
+// set if every key with this prefix is known to follow the start key.
+func (it *rangeIterator) walk(n Node, prefix []byte, after bool) (stop bool, err error) {
+	n, err = it.resolve(n)
+	if err != nil {
+		return true, err
+	}
+
+	if n == nil {
+		return true, nil
+	}
+
+	key := append(append([]byte{}, prefix...), n.GetKey()...)
+	if !after {
+		cmp := compareToStart(key, it.start)
+		if cmp < 0 {
+			return false, nil
+		}
+		after = cmp > 0
+	}
+
+	switch n.Type() {
+	case node.LeafType:
+		if !after {
+			return false, nil
+		}
+		return it.onPair(codec.NibblesToKeyLE(key), n.GetValue()), nil
+	case node.BranchType, node.BranchWithValueType:
+	default:
+		return false, nil
+	}
+
+	branch := n.(*node.Branch)
+	if after && branch.Value != nil {
+		if it.onPair(codec.NibblesToKeyLE(key), branch.Value) {
+			return true, nil
+		}
+	}
+
+	for i, child := range branch.Children {
+		if child == nil {
+			continue
+		}
+
+		childPrefix := append(append([]byte{}, key...), byte(i))
+		// don't resolve the children which only contain keys before the start key
+		if !after && compareToStart(childPrefix, it.start) < 0 {
+			continue
+		}
+
+		stop, err = it.walk(child, childPrefix, after)
+		if stop || err != nil {
+			return stop, err
+		}
+	}
+
+	return false, nil
+}
+
+// compareToStart returns a negative number if every key starting with the given prefix is lower than
+// the start key, zero if the prefix is a prefix of the start key, and a positive number if every key
+// starting with the prefix follows the start key.
+func compareToStart(prefix, start []byte) int {
+	length := len(prefix)
+	if len(start) < length {
+		length = len(start)
+	}
+
+	cmp := bytes.Compare(prefix[:length], start[:length])
+	if cmp != 0 {
+		return cmp
+	}
+
+	if len(prefix) > len(start) {
+		return 1
+	}
+
+	return 0
+}
+
+// GenerateRangeProof returns the proof of the key-value pairs of the trie following the start key,
+// which is excluded, or of all its pairs if the start key is empty. Pairs are added in the order of
+// their keys until the size of the proof reaches the limit, so the proof contains at least one pair
+// unless no key follows the start key. complete is true if no key follows the last pair returned.
+func (t *Trie) GenerateRangeProof(start []byte, limit int) (proof [][]byte, pairs []Pair, complete bool, err error) {
+	if t.root == nil {
+		return nil, nil, true, nil
+	}
+
+	recorded := make(map[string]struct{})
+	size := 0
+	record := func(n Node) error {
+		encoding, hash, err := n.EncodeAndHash()
+		if err != nil {
+			return err
+		}
+
+		if _, has := recorded[string(hash)]; has {
+			return nil
+		}
+
+		recorded[string(hash)] = struct{}{}
+		proof = append(proof, encoding)
+		size += len(encoding)
+		return nil
+	}
+
+	it := &rangeIterator{
+		start: codec.KeyLEToNibbles(start),
+		resolve: func(child Node) (Node, error) {
+			return child, record(child)
+		},
+		onPair: func(key, value []byte) bool {
+			pairs = append(pairs, Pair{Key: key, Value: value})
+			return size >= limit
+		},
+	}
+
+	stop, err := it.walk(t.root, nil, len(start) == 0)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return proof, pairs, !stop, nil
+}
+
+// VerifyRangeProof returns the key-value pairs following the start key which are proven by the
+// range proof of the trie with the given root, in the order of their keys. complete is true
+// if no key follows the last pair returned.
+func VerifyRangeProof(proof [][]byte, root []byte, start []byte) (pairs []Pair, complete bool, err error) {
+	if len(proof) == 0 {
+		return nil, false, ErrEmptyProof
+	}
+
+	var rootNode Node
+	nodes := make(map[string]Node, len(proof))
+	for i, encoding := range proof {
+		decoded, err := node.Decode(bytes.NewReader(encoding))
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: at index %d: 0x%x", ErrDecodeNode, i, encoding)
+		}
+
+		decoded.SetDirty(false)
+		decoded.SetEncodingAndHash(encoding, nil)
+		_, hash, err := decoded.EncodeAndHash()
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot encode and hash node at index %d: %w", i, err)
+		}
+		nodes[string(hash)] = decoded
+
+		// the root node is hashed even if its encoding is under 32 bytes
+		digest, err := common.Blake2bHash(encoding)
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot hash node at index %d: %w", i, err)
+		}
+
+		if bytes.Equal(digest[:], root) {
+			rootNode = decoded
+		}
+	}
+
+	if rootNode == nil {
+		return nil, false, fmt.Errorf("%w: 0x%x", ErrRootNodeNotFound, root)
+	}
+
+	it := &rangeIterator{
+		start: codec.KeyLEToNibbles(start),
+		resolve: func(child Node) (Node, error) {
+			// the children of decoded branches only hold their hash
+			return nodes[string(child.GetHash())], nil
+		},
+		onPair: func(key, value []byte) bool {
+			pairs = append(pairs, Pair{Key: key, Value: value})
+			return false
+		},
+	}
+
+	stop, err := it.walk(rootNode, nil, len(start) == 0)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if stop && len(pairs) == 0 {
+		return nil, false, ErrEmptyRangeProof
+	}
+
+	return pairs, !stop, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 ChainSafe Systems (ON)
+// SPDX-License-Identifier: LGPL-3.0-only
+
+package trie
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// downloadWithRangeProofs returns the pairs of the trie by verifying range proofs
+// generated with the given limit, along with the number of proofs needed.
+func downloadWithRangeProofs(t *testing.T, trie *Trie, limit int) (pairs []Pair, proofs int) {
+	root := trie.MustHash()
+
+	var start []byte
+	for {
+		proof, generated, _, err := trie.GenerateRangeProof(start, limit)
+		require.NoError(t, err)
+
+		verified, complete, err := VerifyRangeProof(proof, root[:], start)
+		require.NoError(t, err)
+		require.Equal(t, generated, verified[:len(generated)])
+
+		pairs = append(pairs, verified...)
+		proofs++
+
+		if complete {
+			return pairs, proofs
+		}
+
+		start = verified[len(verified)-1].Key
+	}
+}
+
+func sortedPairs(kv map[string][]byte) []Pair {
+	pairs := make([]Pair, 0, len(kv))
+	for k, v := range kv {
+		pairs = append(pairs, Pair{Key: []byte(k), Value: v})
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return bytes.Compare(pairs[i].Key, pairs[j].Key) < 0
+	})
+
+	return pairs
+}
+
+func TestRangeProof(t *testing.T) {
+	t.Parallel()
+
+	kv := map[string][]byte{
+		"cat":       []byte("meow"),
+		"catapulta": []byte("boom"),
+		"catapora":  []byte("itch"),
+		"dog":       []byte("woof"),
+		"doguinho":  []byte("wuf"),
+		"z":         []byte("last"),
+	}
+
+	trie := NewEmptyTrie()
+	for k, v := range kv {
+		trie.Put([]byte(k), v)
+	}
+
+	pairs, proofs := downloadWithRangeProofs(t, trie, 1)
+	require.Equal(t, sortedPairs(kv), pairs)
+	require.Greater(t, proofs, 1)
+
+	pairs, proofs = downloadWithRangeProofs(t, trie, 1<<20)
+	require.Equal(t, sortedPairs(kv), pairs)
+	require.Equal(t, 1, proofs)
+
+	root := trie.MustHash()
+	proof, _, complete, err := trie.GenerateRangeProof([]byte("dog"), 1<<20)
+	require.NoError(t, err)
+	require.True(t, complete)
+
+	pairs, complete, err = VerifyRangeProof(proof, root[:], []byte("dog"))
+	require.NoError(t, err)
+	require.True(t, complete)
+	require.Equal(t, []Pair{
+		{Key: []byte("doguinho"), Value: []byte("wuf")},
+		{Key: []byte("z"), Value: []byte("last")},
+	}, pairs)
+}
+
+func TestRangeProof_random(t *testing.T) {
+	t.Parallel()
+
+	generator := newGenerator()
+	kv := generateKeyValues(t, generator, 1000)
+
+	trie := NewEmptyTrie()
+	for k, v := range kv {
+		trie.Put([]byte(k), v)
+	}
+
+	pairs, proofs := downloadWithRangeProofs(t, trie, 4096)
+	require.Equal(t, sortedPairs(kv), pairs)
+	require.Greater(t, proofs, 1)
+}
+
+func TestVerifyRangeProof_invalid(t *testing.T) {
+	t.Parallel()
+
+	trie := NewEmptyTrie()
+	trie.Put([]byte("cat"), []byte("meow"))
+	trie.Put([]byte("catapulta"), []byte("boom"))
+	trie.Put([]byte("dog"), []byte("woof"))
+	root := trie.MustHash()
+
+	proof, _, _, err := trie.GenerateRangeProof(nil, 1<<20)
+	require.NoError(t, err)
+
+	_, _, err = VerifyRangeProof(proof, []byte{1}, nil)
+	require.ErrorIs(t, err, ErrRootNodeNotFound)
+
+	_, _, err = VerifyRangeProof(nil, root[:], nil)
+	require.ErrorIs(t, err, ErrEmptyProof)
+
+	// a proof only containing the root node doesn't prove any key
+	_, _, err = VerifyRangeProof(proof[:1], root[:], nil)
+	require.ErrorIs(t, err, ErrEmptyRangeProof)
+
+	// the keys which aren't proven are not returned
+	pairs, complete, err := VerifyRangeProof(proof[:len(proof)-1], root[:], nil)
+	require.NoError(t, err)
+	require.False(t, complete)
+	require.Equal(t, []Pair{
+		{Key: []byte("cat"), Value: []byte("meow")},
+		{Key: []byte("catapulta"), Value: []byte("boom")},
+	}, pairs)
+}
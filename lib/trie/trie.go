@@ -633,6 +633,21 @@ func retrieveFromBranch(branch *node.Branch, key []byte) (value []byte) {
 // keys and a boolean indicating if all keys with the prefix were deleted
 // within the limit.
 func (t *Trie) ClearPrefixLimit(prefixLE []byte, limit uint32) (deleted uint32, allDeleted bool) {
+	return t.clearPrefixLimitRoot(prefixLE, limit, nil)
+}
+
+// ClearPrefixLimitKeys deletes the keys having the prefix given in little
+// Endian format for up to `limit` keys, as ClearPrefixLimit does. It returns
+// the deleted keys in little Endian format and a boolean indicating if all
+// keys with the prefix were deleted within the limit.
+func (t *Trie) ClearPrefixLimitKeys(prefixLE []byte, limit uint32) (deletedKeysLE [][]byte, allDeleted bool) {
+	deletedKeysLE = [][]byte{}
+	_, allDeleted = t.clearPrefixLimitRoot(prefixLE, limit, &deletedKeysLE)
+	return deletedKeysLE, allDeleted
+}
+
+func (t *Trie) clearPrefixLimitRoot(prefixLE []byte, limit uint32, deletedKeysLE *[][]byte) (
+	deleted uint32, allDeleted bool) {
 	if limit == 0 {
 		return 0, false
 	}
@@ -640,14 +655,16 @@ func (t *Trie) ClearPrefixLimit(prefixLE []byte, limit uint32) (deleted uint32,
 	prefix := codec.KeyLEToNibbles(prefixLE)
 	prefix = bytes.TrimSuffix(prefix, []byte{0})
 
-	t.root, deleted, allDeleted = t.clearPrefixLimit(t.root, prefix, limit)
+	t.root, deleted, allDeleted = t.clearPrefixLimit(t.root, nil, prefix, limit, deletedKeysLE)
 	return deleted, allDeleted
 }
 
 // clearPrefixLimit deletes the keys having the prefix until the value deletion limit is reached.
 // It returns the updated node newParent, the number of deleted values valuesDeleted and the
 // allDeleted boolean indicating if there is no key left with the prefix.
-func (t *Trie) clearPrefixLimit(parent Node, prefix []byte, limit uint32) (
+// The path is the key in nibbles of the parent node without its partial key. If deletedKeysLE
+// is not nil, the deleted keys are appended to it in little Endian format.
+func (t *Trie) clearPrefixLimit(parent Node, path, prefix []byte, limit uint32, deletedKeysLE *[][]byte) (
 	newParent Node, valuesDeleted uint32, allDeleted bool) {
 	if parent == nil {
 		return nil, 0, true
@@ -665,6 +682,7 @@ func (t *Trie) clearPrefixLimit(parent Node, prefix []byte, limit uint32) (
 		const allDeleted = true
 		if bytes.HasPrefix(leaf.Key, prefix) {
 			valuesDeleted = 1
+			appendDeletedKey(deletedKeysLE, path, leaf.Key)
 			return nil, valuesDeleted, allDeleted
 		}
 		// not modified so return the leaf of the original
@@ -674,7 +692,7 @@ func (t *Trie) clearPrefixLimit(parent Node, prefix []byte, limit uint32) (
 	}
 
 	branch := newParent.(*node.Branch)
-	newParent, valuesDeleted, allDeleted = t.clearPrefixLimitBranch(branch, prefix, limit)
+	newParent, valuesDeleted, allDeleted = t.clearPrefixLimitBranch(branch, path, prefix, limit, deletedKeysLE)
 	if valuesDeleted == 0 {
 		// not modified so return the node of the original
 		// trie generation. The copied newParent will be
@@ -685,13 +703,13 @@ func (t *Trie) clearPrefixLimit(parent Node, prefix []byte, limit uint32) (
 	return newParent, valuesDeleted, allDeleted
 }
 
-func (t *Trie) clearPrefixLimitBranch(branch *node.Branch, prefix []byte, limit uint32) (
-	newParent Node, valuesDeleted uint32, allDeleted bool) {
+func (t *Trie) clearPrefixLimitBranch(branch *node.Branch, path, prefix []byte, limit uint32,
+	deletedKeysLE *[][]byte) (newParent Node, valuesDeleted uint32, allDeleted bool) {
 	newParent = branch
 
 	if bytes.HasPrefix(branch.Key, prefix) {
 		nilPrefix := ([]byte)(nil)
-		newParent, valuesDeleted = t.deleteNodesLimit(branch, nilPrefix, limit)
+		newParent, valuesDeleted = t.deleteNodesLimit(branch, path, nilPrefix, limit, deletedKeysLE)
 		allDeleted = newParent == nil
 		return newParent, valuesDeleted, allDeleted
 	}
@@ -699,7 +717,7 @@ func (t *Trie) clearPrefixLimitBranch(branch *node.Branch, prefix []byte, limit
 	if len(prefix) == len(branch.Key)+1 &&
 		bytes.HasPrefix(branch.Key, prefix[:len(prefix)-1]) {
 		// Prefix is one the children of the branch
-		return t.clearPrefixLimitChild(branch, prefix, limit)
+		return t.clearPrefixLimitChild(branch, path, prefix, limit, deletedKeysLE)
 	}
 
 	noPrefixForNode := len(prefix) <= len(branch.Key) ||
@@ -715,7 +733,9 @@ func (t *Trie) clearPrefixLimitBranch(branch *node.Branch, prefix []byte, limit
 	child := branch.Children[childIndex]
 
 	newParent = branch // mostly just a reminder for the reader
-	branch.Children[childIndex], valuesDeleted, allDeleted = t.clearPrefixLimit(child, childPrefix, limit)
+	childPath := makeChildPrefix(path, branch.Key, int(childIndex))
+	branch.Children[childIndex], valuesDeleted, allDeleted = t.clearPrefixLimit(child, childPath, childPrefix,
+		limit, deletedKeysLE)
 	if valuesDeleted > 0 {
 		branch.SetDirty(true)
 		newParent = handleDeletion(branch, prefix)
@@ -724,8 +744,8 @@ func (t *Trie) clearPrefixLimitBranch(branch *node.Branch, prefix []byte, limit
 	return newParent, valuesDeleted, allDeleted
 }
 
-func (t *Trie) clearPrefixLimitChild(branch *node.Branch, prefix []byte, limit uint32) (
-	newParent Node, valuesDeleted uint32, allDeleted bool) {
+func (t *Trie) clearPrefixLimitChild(branch *node.Branch, path, prefix []byte, limit uint32,
+	deletedKeysLE *[][]byte) (newParent Node, valuesDeleted uint32, allDeleted bool) {
 	newParent = branch
 
 	childIndex := prefix[len(branch.Key)]
@@ -738,7 +758,8 @@ func (t *Trie) clearPrefixLimitChild(branch *node.Branch, prefix []byte, limit u
 	}
 
 	nilPrefix := ([]byte)(nil)
-	branch.Children[childIndex], valuesDeleted = t.deleteNodesLimit(child, nilPrefix, limit)
+	childPath := makeChildPrefix(path, branch.Key, int(childIndex))
+	branch.Children[childIndex], valuesDeleted = t.deleteNodesLimit(child, childPath, nilPrefix, limit, deletedKeysLE)
 	branch.SetDirty(true)
 
 	newParent = handleDeletion(branch, prefix)
@@ -747,7 +768,10 @@ func (t *Trie) clearPrefixLimitChild(branch *node.Branch, prefix []byte, limit u
 	return newParent, valuesDeleted, allDeleted
 }
 
-func (t *Trie) deleteNodesLimit(parent Node, prefix []byte, limit uint32) (
+// deleteNodesLimit deletes the values of the parent node and its descendants until the value deletion
+// limit is reached. The path is the key in nibbles of the parent node without its partial key.
+// If deletedKeysLE is not nil, the deleted keys are appended to it in little Endian format.
+func (t *Trie) deleteNodesLimit(parent Node, path, prefix []byte, limit uint32, deletedKeysLE *[][]byte) (
 	newParent Node, valuesDeleted uint32) {
 	if limit == 0 {
 		return parent, 0
@@ -764,6 +788,7 @@ func (t *Trie) deleteNodesLimit(parent Node, prefix []byte, limit uint32) (
 
 	if newParent.Type() == node.LeafType {
 		valuesDeleted = 1
+		appendDeletedKey(deletedKeysLE, path, newParent.GetKey())
 		return nil, valuesDeleted
 	}
 
@@ -779,7 +804,8 @@ func (t *Trie) deleteNodesLimit(parent Node, prefix []byte, limit uint32) (
 			continue
 		}
 
-		branch.Children[i], newDeleted = t.deleteNodesLimit(child, fullKey, limit)
+		childPath := makeChildPrefix(path, branch.Key, i)
+		branch.Children[i], newDeleted = t.deleteNodesLimit(child, childPath, fullKey, limit, deletedKeysLE)
 		if branch.Children[i] == nil {
 			nilChildren++
 		}
@@ -800,11 +826,21 @@ func (t *Trie) deleteNodesLimit(parent Node, prefix []byte, limit uint32) (
 
 	if branch.Value != nil {
 		valuesDeleted++
+		appendDeletedKey(deletedKeysLE, path, branch.Key)
 	}
 
 	return nil, valuesDeleted
 }
 
+// appendDeletedKey appends the key of the deleted node with the given path and partial key
+// to the deleted keys, if they are collected.
+func appendDeletedKey(deletedKeysLE *[][]byte, path, nodeKey []byte) {
+	if deletedKeysLE == nil {
+		return
+	}
+	*deletedKeysLE = append(*deletedKeysLE, makeFullKeyLE(path, nodeKey))
+}
+
 // ClearPrefix deletes all nodes in the trie for which the key contains the
 // prefix given in little Endian format.
 func (t *Trie) ClearPrefix(prefixLE []byte) {
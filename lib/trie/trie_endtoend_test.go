@@ -949,6 +949,44 @@ func TestTrie_ClearPrefixLimit(t *testing.T) {
 	}
 }
 
+func TestTrie_ClearPrefixLimitKeys(t *testing.T) {
+	entries := []Test{
+		{key: []byte{0x01, 0x35}, value: []byte("pen")},
+		{key: []byte{0x01, 0x35, 0x79}, value: []byte("penguin")},
+		{key: []byte{0x01, 0x35, 0x7}, value: []byte("g")},
+		{key: []byte{0x01, 0x35, 0x99}, value: []byte("h")},
+		{key: []byte{0xf2}, value: []byte("feather")},
+		{key: []byte{0xf2, 0x3}, value: []byte("f")},
+		{key: []byte{0x09, 0xd3}, value: []byte("noot")},
+		{key: []byte{0x07}, value: []byte("ramen")},
+	}
+	prefixes := [][]byte{{}, {0x01}, {0x01, 0x35}, {0x01, 0x35, 0x70}, {0xf2}, {0x07}, {0x08}}
+
+	for _, prefix := range prefixes {
+		for limit := 0; limit <= len(entries)+1; limit++ {
+			expected, trie := NewEmptyTrie(), NewEmptyTrie()
+			for _, entry := range entries {
+				expected.Put(entry.key, entry.value)
+				trie.Put(entry.key, entry.value)
+			}
+
+			expectedDeleted, expectedAllDeleted := expected.ClearPrefixLimit(prefix, uint32(limit))
+			deletedKeys, allDeleted := trie.ClearPrefixLimitKeys(prefix, uint32(limit))
+			require.Equal(t, expectedAllDeleted, allDeleted)
+			require.Equal(t, expected.MustHash(), trie.MustHash())
+			require.Len(t, deletedKeys, int(expectedDeleted))
+
+			var missingKeys [][]byte
+			for _, entry := range entries {
+				if trie.Get(entry.key) == nil {
+					missingKeys = append(missingKeys, entry.key)
+				}
+			}
+			require.ElementsMatch(t, missingKeys, deletedKeys)
+		}
+	}
+}
+
 func TestTrie_ClearPrefixLimitSnapshot(t *testing.T) {
 	prefixes := [][]byte{
 		{},
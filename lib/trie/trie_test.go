@@ -2529,7 +2529,7 @@ func Test_Trie_clearPrefixLimit(t *testing.T) {
 			expectedTrie := *trie.DeepCopy()
 
 			newParent, valuesDeleted, allDeleted := trie.clearPrefixLimit(testCase.parent,
-				testCase.prefix, testCase.limit)
+				nil, testCase.prefix, testCase.limit, nil)
 
 			assert.Equal(t, testCase.newParent, newParent)
 			assert.Equal(t, testCase.valuesDeleted, valuesDeleted)
@@ -2681,7 +2681,7 @@ func Test_Trie_deleteNodesLimit(t *testing.T) {
 			trie := testCase.trie
 			expectedTrie := *trie.DeepCopy()
 
-			newNode, valuesDeleted := trie.deleteNodesLimit(testCase.parent, testCase.prefix, testCase.limit)
+			newNode, valuesDeleted := trie.deleteNodesLimit(testCase.parent, nil, testCase.prefix, testCase.limit, nil)
 
 			assert.Equal(t, testCase.newNode, newNode)
 			assert.Equal(t, testCase.valuesDeleted, valuesDeleted)
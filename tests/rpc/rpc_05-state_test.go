@@ -40,8 +40,8 @@ func TestStateRPCResponseValidation(t *testing.T) {
 		{
 			description: "Test state_call",
 			method:      "state_call",
-			params:      `["", "","0x580d77a9136035a0bc3c3cd86286172f7f81291164c5914266073a30466fba21"]`,
-			expected:    modules.StateCallResponse{},
+			params:      fmt.Sprintf(`["Core_version", "0x", "%s"]`, blockHash),
+			expected:    modules.StateCallResponse(""),
 		},
 		{ //TODO disable skip when implemented
 			description: "Test state_getKeysPaged",